@@ -0,0 +1,21 @@
+-- +migrate Up
+-- 用户角色（MySQL 版本）
+ALTER TABLE users
+    ADD COLUMN role VARCHAR(32) NOT NULL DEFAULT 'user' COMMENT '角色: guest/user/moderator/admin/super_admin';
+
+CREATE INDEX idx_users_role ON users(role);
+
+-- 用户额外权限（在角色隐式权限之外单独授予）
+CREATE TABLE IF NOT EXISTS user_permissions (
+    user_id    BIGINT NOT NULL,
+    permission VARCHAR(64) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    PRIMARY KEY (user_id, permission),
+    CONSTRAINT fk_user_permissions_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户额外权限表';
+
+-- +migrate Down
+-- 回滚
+DROP TABLE IF EXISTS user_permissions;
+DROP INDEX idx_users_role ON users;
+ALTER TABLE users DROP COLUMN role;
@@ -0,0 +1,9 @@
+-- +migrate Up
+-- Token 版本号（MySQL 版本）
+-- 签发的 Token 携带当前版本号；版本号递增后，所有旧 Token 立即失效
+ALTER TABLE users
+    ADD COLUMN token_version BIGINT NOT NULL DEFAULT 0 COMMENT 'Token 版本号（递增即吊销所有已签发 Token）';
+
+-- +migrate Down
+-- 回滚
+ALTER TABLE users DROP COLUMN token_version;
@@ -0,0 +1,28 @@
+-- +migrate Up
+-- 两步验证（MySQL 版本）
+CREATE TABLE IF NOT EXISTS user_mfa (
+    user_id        BIGINT PRIMARY KEY,
+    totp_secret    VARCHAR(64) NOT NULL COMMENT 'TOTP 密钥（Base32）',
+    enabled        TINYINT(1) NOT NULL DEFAULT 0 COMMENT '是否已启用（绑定时验证通过后启用）',
+    last_used_step BIGINT NOT NULL DEFAULT 0 COMMENT '最近一次使用的 TOTP 时间步（防止验证码重放）',
+    enabled_at     TIMESTAMP NULL,
+    created_at     TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at     TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    CONSTRAINT fk_user_mfa_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户两步验证表';
+
+-- 恢复码（只保存哈希，每个只能使用一次）
+CREATE TABLE IF NOT EXISTS user_recovery_codes (
+    id         BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id    BIGINT NOT NULL,
+    code_hash  CHAR(64) NOT NULL COMMENT '恢复码 SHA-256 哈希',
+    used_at    TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uk_user_recovery_codes (user_id, code_hash),
+    CONSTRAINT fk_user_recovery_codes_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户恢复码表';
+
+-- +migrate Down
+-- 回滚
+DROP TABLE IF EXISTS user_recovery_codes;
+DROP TABLE IF EXISTS user_mfa;
@@ -0,0 +1,27 @@
+-- +migrate Up
+-- 邮箱验证与密码重置（MySQL 版本）
+-- 新注册用户的状态为 3（邮箱未验证），验证通过后变为 1
+ALTER TABLE users
+    MODIFY COLUMN status SMALLINT NOT NULL DEFAULT 1 COMMENT '1:正常 2:禁用 3:邮箱未验证';
+
+-- 一次性令牌（邮件链接中的令牌，只保存哈希）
+CREATE TABLE IF NOT EXISTS user_tokens (
+    id         BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id    BIGINT NOT NULL,
+    purpose    VARCHAR(32) NOT NULL COMMENT '用途：verify_email / reset_password',
+    token_hash CHAR(64) NOT NULL COMMENT '令牌 SHA-256 哈希',
+    expires_at TIMESTAMP NOT NULL,
+    used_at    TIMESTAMP NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uk_user_tokens_hash (token_hash),
+    KEY idx_user_tokens_user (user_id, purpose),
+    KEY idx_user_tokens_expires_at (expires_at),
+    CONSTRAINT fk_user_tokens_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='用户一次性令牌表';
+
+-- +migrate Down
+-- 回滚（未验证的用户恢复为正常状态）
+DROP TABLE IF EXISTS user_tokens;
+UPDATE users SET status = 1 WHERE status = 3;
+ALTER TABLE users
+    MODIFY COLUMN status SMALLINT NOT NULL DEFAULT 1 COMMENT '1:正常 2:禁用';
@@ -0,0 +1,29 @@
+-- +migrate Up
+-- API Key（MySQL 版本，用于服务间调用，只保存密钥哈希）
+CREATE TABLE IF NOT EXISTS api_keys (
+    id           BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id      BIGINT NOT NULL,
+    name         VARCHAR(100) NOT NULL COMMENT '名称（便于用户区分用途）',
+    prefix       CHAR(12) NOT NULL COMMENT '公开标识（Key 中 gdk_ 之后的部分）',
+    secret_hash  CHAR(64) NOT NULL COMMENT '密钥 SHA-256 哈希',
+    expires_at   TIMESTAMP NULL COMMENT '过期时间（NULL 表示永不过期）',
+    last_used_at TIMESTAMP NULL,
+    revoked_at   TIMESTAMP NULL,
+    created_at   TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uk_api_keys_prefix (prefix),
+    KEY idx_api_keys_user (user_id),
+    CONSTRAINT fk_api_keys_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='API Key 表';
+
+-- API Key 权限范围
+CREATE TABLE IF NOT EXISTS api_key_scopes (
+    api_key_id BIGINT NOT NULL,
+    permission VARCHAR(50) NOT NULL COMMENT '权限（如 user:read）',
+    PRIMARY KEY (api_key_id, permission),
+    CONSTRAINT fk_api_key_scopes_key FOREIGN KEY (api_key_id) REFERENCES api_keys(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='API Key 权限范围表';
+
+-- +migrate Down
+-- 回滚
+DROP TABLE IF EXISTS api_key_scopes;
+DROP TABLE IF EXISTS api_keys;
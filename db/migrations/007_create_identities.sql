@@ -0,0 +1,18 @@
+-- +migrate Up
+-- 第三方账户关联（MySQL 版本，OAuth2/OIDC 登录）
+CREATE TABLE IF NOT EXISTS identities (
+    id            BIGINT AUTO_INCREMENT PRIMARY KEY,
+    user_id       BIGINT NOT NULL,
+    provider      VARCHAR(50) NOT NULL COMMENT '提供方名称（配置中的 name，如 google）',
+    subject       VARCHAR(255) NOT NULL COMMENT '提供方内的用户唯一标识（OIDC sub）',
+    email         VARCHAR(255) NOT NULL DEFAULT '' COMMENT '关联时提供方返回的邮箱（仅用于展示）',
+    last_login_at TIMESTAMP NULL,
+    created_at    TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uk_identities_provider_subject (provider, subject),
+    UNIQUE KEY uk_identities_user_provider (user_id, provider),
+    CONSTRAINT fk_identities_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='第三方账户关联表';
+
+-- +migrate Down
+-- 回滚
+DROP TABLE IF EXISTS identities;
@@ -0,0 +1,61 @@
+-- +migrate Up
+-- 角色与权限（MySQL 版本，替代代码中写死的角色权限映射，修改后热更新生效）
+CREATE TABLE IF NOT EXISTS roles (
+    id          BIGINT AUTO_INCREMENT PRIMARY KEY,
+    name        VARCHAR(32) NOT NULL COMMENT '角色名称（与 users.role 对应）',
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    level       INT NOT NULL DEFAULT 0 COMMENT '角色级别（用于角色高低比较，super_admin 为 100）',
+    is_system   BOOLEAN NOT NULL DEFAULT FALSE COMMENT '内置角色（不能删除）',
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY uk_roles_name (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='角色表';
+
+CREATE TABLE IF NOT EXISTS permissions (
+    id          BIGINT AUTO_INCREMENT PRIMARY KEY,
+    name        VARCHAR(64) NOT NULL COMMENT '权限名称（如 user:read）',
+    description VARCHAR(255) NOT NULL DEFAULT '',
+    is_system   BOOLEAN NOT NULL DEFAULT FALSE COMMENT '内置权限（代码中使用，不能删除）',
+    created_at  TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    UNIQUE KEY uk_permissions_name (name)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='权限表';
+
+CREATE TABLE IF NOT EXISTS role_permissions (
+    role_id       BIGINT NOT NULL,
+    permission_id BIGINT NOT NULL,
+    PRIMARY KEY (role_id, permission_id),
+    CONSTRAINT fk_role_permissions_role FOREIGN KEY (role_id) REFERENCES roles(id) ON DELETE CASCADE,
+    CONSTRAINT fk_role_permissions_permission FOREIGN KEY (permission_id) REFERENCES permissions(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='角色权限关联表';
+
+-- 内置角色和权限（与原先代码中的映射一致，super_admin 隐式拥有所有权限）
+INSERT INTO roles (name, description, level, is_system) VALUES
+    ('super_admin', '超级管理员', 100, TRUE),
+    ('admin', '管理员', 80, TRUE),
+    ('moderator', '版主/审核员', 60, TRUE),
+    ('user', '普通用户', 40, TRUE),
+    ('guest', '游客（未登录）', 0, TRUE);
+
+INSERT INTO permissions (name, description, is_system) VALUES
+    ('user:read', '查看用户', TRUE),
+    ('user:write', '修改用户', TRUE),
+    ('user:delete', '删除用户', TRUE),
+    ('content:read', '查看内容', TRUE),
+    ('content:write', '发布内容', TRUE),
+    ('content:delete', '删除内容', TRUE),
+    ('content:audit', '审核内容', TRUE),
+    ('system:config', '系统配置', TRUE),
+    ('system:monitor', '系统监控', TRUE);
+
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT r.id, p.id FROM roles r JOIN permissions p
+WHERE (r.name = 'admin' AND p.name <> 'system:config')
+   OR (r.name = 'moderator' AND p.name IN ('content:read', 'content:audit', 'user:read'))
+   OR (r.name = 'user' AND p.name IN ('content:read', 'content:write', 'user:read'))
+   OR (r.name = 'guest' AND p.name = 'content:read');
+
+-- +migrate Down
+-- 回滚
+DROP TABLE IF EXISTS role_permissions;
+DROP TABLE IF EXISTS permissions;
+DROP TABLE IF EXISTS roles;
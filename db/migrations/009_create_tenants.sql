@@ -0,0 +1,57 @@
+-- +migrate Up
+-- 租户（MySQL 版本，一个部署托管多个客户组织，业务数据按 tenant_id 隔离）
+CREATE TABLE IF NOT EXISTS tenants (
+    id         BIGINT AUTO_INCREMENT PRIMARY KEY,
+    slug       VARCHAR(63) NOT NULL COMMENT '租户标识（子域名或 X-Tenant-ID 请求头中使用）',
+    name       VARCHAR(100) NOT NULL,
+    status     SMALLINT NOT NULL DEFAULT 1 COMMENT '1:正常 2:停用',
+    created_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    updated_at TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+    UNIQUE KEY uk_tenants_slug (slug)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='租户表';
+
+-- 默认租户（ID 固定为 1，已有数据和未指定租户的请求都属于该租户）
+INSERT INTO tenants (id, slug, name) VALUES (1, 'default', '默认租户');
+
+-- 用户归属租户：邮箱和用户名只在租户内唯一
+-- 之后新增的业务表同样需要 tenant_id 列
+ALTER TABLE users
+    ADD COLUMN tenant_id BIGINT NOT NULL DEFAULT 1 COMMENT '所属租户',
+    DROP INDEX email,
+    DROP INDEX username,
+    ADD UNIQUE KEY uk_users_tenant_email (tenant_id, email),
+    ADD UNIQUE KEY uk_users_tenant_username (tenant_id, username),
+    ADD CONSTRAINT fk_users_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id);
+
+-- 第三方账户关联归属租户：同一个第三方账户可以分别关联不同租户的用户
+ALTER TABLE identities
+    ADD COLUMN tenant_id BIGINT NOT NULL DEFAULT 1 COMMENT '所属租户（与用户所属租户相同）',
+    DROP INDEX uk_identities_provider_subject,
+    ADD UNIQUE KEY uk_identities_tenant_provider_subject (tenant_id, provider, subject),
+    ADD CONSTRAINT fk_identities_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id);
+
+-- API Key 归属租户：认证时按 Key 所属的租户加载用户
+ALTER TABLE api_keys
+    ADD COLUMN tenant_id BIGINT NOT NULL DEFAULT 1 COMMENT '所属租户（与用户所属租户相同）',
+    ADD KEY idx_api_keys_tenant_user (tenant_id, user_id),
+    ADD CONSTRAINT fk_api_keys_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id);
+
+-- +migrate Down
+-- 回滚（只保留默认租户的数据才能恢复全局唯一约束）
+ALTER TABLE api_keys
+    DROP FOREIGN KEY fk_api_keys_tenant,
+    DROP INDEX idx_api_keys_tenant_user,
+    DROP COLUMN tenant_id;
+ALTER TABLE identities
+    DROP FOREIGN KEY fk_identities_tenant,
+    DROP INDEX uk_identities_tenant_provider_subject,
+    ADD UNIQUE KEY uk_identities_provider_subject (provider, subject),
+    DROP COLUMN tenant_id;
+ALTER TABLE users
+    DROP FOREIGN KEY fk_users_tenant,
+    DROP INDEX uk_users_tenant_email,
+    DROP INDEX uk_users_tenant_username,
+    ADD UNIQUE KEY email (email),
+    ADD UNIQUE KEY username (username),
+    DROP COLUMN tenant_id;
+DROP TABLE IF EXISTS tenants;
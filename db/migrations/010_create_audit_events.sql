@@ -0,0 +1,24 @@
+-- +migrate Up
+-- 审计日志（MySQL 版本，只追加不修改：记录谁在什么时候对什么做了什么）
+-- 不设置外键：用户删除后审计记录仍需保留
+CREATE TABLE IF NOT EXISTS audit_events (
+    id              BIGINT AUTO_INCREMENT PRIMARY KEY,
+    tenant_id       BIGINT NOT NULL COMMENT '所属租户',
+    actor_id        BIGINT NULL COMMENT '操作者用户 ID（匿名操作如注册为 NULL）',
+    impersonator_id BIGINT NULL COMMENT '模拟登录时实际操作的管理员 ID',
+    action          VARCHAR(64) NOT NULL COMMENT '操作（如 user.register、user.update）',
+    target_type     VARCHAR(32) NOT NULL COMMENT '目标类型（如 user）',
+    target_id       BIGINT NULL COMMENT '目标 ID',
+    changes         JSON NULL COMMENT '变更内容 {"字段":{"from":旧值,"to":新值}}',
+    ip              VARCHAR(45) NOT NULL DEFAULT '' COMMENT '客户端 IP',
+    request_id      VARCHAR(64) NOT NULL DEFAULT '' COMMENT '请求 ID',
+    user_agent      VARCHAR(255) NOT NULL DEFAULT '' COMMENT '客户端 User-Agent',
+    created_at      TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_audit_events_tenant (tenant_id, id),
+    INDEX idx_audit_events_actor (tenant_id, actor_id, id),
+    INDEX idx_audit_events_target (tenant_id, target_type, target_id, id),
+    INDEX idx_audit_events_created_at (created_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='审计日志表';
+
+-- +migrate Down
+DROP TABLE IF EXISTS audit_events;
@@ -0,0 +1,15 @@
+-- +migrate Up
+-- 历史密码（MySQL 版本，只保存哈希，用于禁止重复使用最近的密码）
+CREATE TABLE IF NOT EXISTS password_history (
+    id            BIGINT AUTO_INCREMENT PRIMARY KEY,
+    tenant_id     BIGINT NOT NULL COMMENT '所属租户（与用户所属租户相同）',
+    user_id       BIGINT NOT NULL,
+    password_hash VARCHAR(255) NOT NULL COMMENT '密码哈希（与 users.password 格式相同）',
+    created_at    TIMESTAMP NOT NULL DEFAULT CURRENT_TIMESTAMP,
+    INDEX idx_password_history_user (tenant_id, user_id, id),
+    CONSTRAINT fk_password_history_tenant FOREIGN KEY (tenant_id) REFERENCES tenants(id),
+    CONSTRAINT fk_password_history_user FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_unicode_ci COMMENT='历史密码表';
+
+-- +migrate Down
+DROP TABLE IF EXISTS password_history;
@@ -0,0 +1,20 @@
+-- +migrate Up
+-- 用户生命周期（MySQL 版本）
+-- 状态：1 正常、2 停用（管理员停用，可恢复）、3 待激活（邮箱未验证）、4 已删除（软删除，保留期后永久删除）
+-- 此前删除用户使用状态 2，已有的状态 2 都是删除的用户，迁移为状态 4
+ALTER TABLE users
+    MODIFY COLUMN status SMALLINT NOT NULL DEFAULT 1 COMMENT '1:正常 2:停用 3:待激活 4:已删除',
+    ADD COLUMN status_reason VARCHAR(255) NULL COMMENT '停用原因',
+    ADD COLUMN deleted_at TIMESTAMP NULL COMMENT '删除时间（软删除）',
+    ADD INDEX idx_users_status_deleted_at (status, deleted_at);
+
+UPDATE users SET status = 4, deleted_at = updated_at WHERE status = 2;
+
+-- +migrate Down
+-- 回滚（已删除的用户恢复为状态 2；停用的用户同样变为状态 2）
+UPDATE users SET status = 2 WHERE status = 4;
+ALTER TABLE users
+    DROP INDEX idx_users_status_deleted_at,
+    DROP COLUMN deleted_at,
+    DROP COLUMN status_reason,
+    MODIFY COLUMN status SMALLINT NOT NULL DEFAULT 1 COMMENT '1:正常 2:禁用 3:邮箱未验证';
@@ -0,0 +1,11 @@
+-- +migrate Up
+-- 用户数据删除权（MySQL 版本）
+-- 新增状态 5 已匿名：个人信息已清除，保留用户记录以维持审计日志等关联数据的完整性，不能恢复，也不会被清理任务删除
+ALTER TABLE users
+    MODIFY COLUMN status SMALLINT NOT NULL DEFAULT 1 COMMENT '1:正常 2:停用 3:待激活 4:已删除 5:已匿名';
+
+-- +migrate Down
+-- 回滚（已匿名的用户视为已删除，由清理任务删除）
+UPDATE users SET status = 4 WHERE status = 5;
+ALTER TABLE users
+    MODIFY COLUMN status SMALLINT NOT NULL DEFAULT 1 COMMENT '1:正常 2:停用 3:待激活 4:已删除';
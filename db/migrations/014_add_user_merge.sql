@@ -0,0 +1,17 @@
+-- +migrate Up
+-- 账户合并（MySQL 版本）
+-- 新增状态 6 已合并：数据已转移到 merged_into 指向的用户，保留记录以维持审计日志等关联数据的完整性，不能恢复，也不会被清理任务删除
+ALTER TABLE users
+    MODIFY COLUMN status SMALLINT NOT NULL DEFAULT 1 COMMENT '1:正常 2:停用 3:待激活 4:已删除 5:已匿名 6:已合并',
+    ADD COLUMN merged_into BIGINT NULL COMMENT '合并到的用户（状态为已合并时）',
+    ADD INDEX idx_users_merged_into (merged_into),
+    ADD CONSTRAINT fk_users_merged_into FOREIGN KEY (merged_into) REFERENCES users(id) ON DELETE SET NULL;
+
+-- +migrate Down
+-- 回滚（已合并的用户视为已删除，由清理任务删除）
+UPDATE users SET status = 4, deleted_at = COALESCE(deleted_at, updated_at) WHERE status = 6;
+ALTER TABLE users
+    DROP FOREIGN KEY fk_users_merged_into,
+    DROP INDEX idx_users_merged_into,
+    DROP COLUMN merged_into,
+    MODIFY COLUMN status SMALLINT NOT NULL DEFAULT 1 COMMENT '1:正常 2:停用 3:待激活 4:已删除 5:已匿名';
@@ -0,0 +1,9 @@
+-- +migrate Up
+-- 用户列表索引（MySQL 版本）
+-- 管理后台按状态过滤、按创建时间排序（同一时间按 ID 排序）
+ALTER TABLE users
+    ADD INDEX idx_users_tenant_status_created (tenant_id, status, created_at, id);
+
+-- +migrate Down
+ALTER TABLE users
+    DROP INDEX idx_users_tenant_status_created;
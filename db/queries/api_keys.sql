@@ -0,0 +1,70 @@
+-- 按用户查询时同时按 tenant_id 过滤；认证时通过全局唯一的 prefix 查询，再使用 Key 所属的租户
+
+-- name: CreateAPIKey :execresult
+-- 创建 API Key（MySQL 使用 execresult 获取 LastInsertId）
+INSERT INTO api_keys (tenant_id, user_id, name, prefix, secret_hash, expires_at)
+VALUES (?, ?, ?, ?, ?, ?);
+
+-- name: AddAPIKeyScope :exec
+-- 授予 API Key 权限范围
+INSERT INTO api_key_scopes (api_key_id, permission)
+VALUES (?, ?);
+
+-- name: GetAPIKeyByPrefix :one
+-- 通过公开标识查询 API Key
+SELECT id, user_id, name, prefix, secret_hash, expires_at, last_used_at, revoked_at, created_at, tenant_id
+FROM api_keys
+WHERE prefix = ?
+LIMIT 1;
+
+-- name: ListAPIKeyScopes :many
+-- 列出 API Key 的权限范围
+SELECT permission
+FROM api_key_scopes
+WHERE api_key_id = ?
+ORDER BY permission;
+
+-- name: ListAPIKeysByUser :many
+-- 列出用户未吊销的 API Key（包含已过期的）
+SELECT id, user_id, name, prefix, secret_hash, expires_at, last_used_at, revoked_at, created_at, tenant_id
+FROM api_keys
+WHERE tenant_id = ? AND user_id = ? AND revoked_at IS NULL
+ORDER BY id DESC;
+
+-- name: ListAPIKeyScopesByUser :many
+-- 列出用户所有未吊销 API Key 的权限范围
+SELECT s.api_key_id, s.permission
+FROM api_key_scopes s
+JOIN api_keys k ON k.id = s.api_key_id
+WHERE k.tenant_id = ? AND k.user_id = ? AND k.revoked_at IS NULL
+ORDER BY s.api_key_id, s.permission;
+
+-- name: CountActiveAPIKeys :one
+-- 统计用户可用的 API Key 数量（未吊销且未过期）
+SELECT COUNT(*) FROM api_keys
+WHERE tenant_id = sqlc.arg(tenant_id) AND user_id = sqlc.arg(user_id)
+  AND revoked_at IS NULL
+  AND (expires_at IS NULL OR expires_at > sqlc.arg(now));
+
+-- name: RevokeAPIKey :execrows
+-- 吊销 API Key（只能吊销自己的 Key，影响行数为 0 表示不存在或已吊销）
+UPDATE api_keys
+SET revoked_at = CURRENT_TIMESTAMP
+WHERE id = ? AND tenant_id = ? AND user_id = ? AND revoked_at IS NULL;
+
+-- name: TouchAPIKey :execrows
+-- 记录最近使用时间（距上次记录不足 before 时不更新，避免每个请求都写库）
+UPDATE api_keys
+SET last_used_at = sqlc.arg(now)
+WHERE id = sqlc.arg(id) AND (last_used_at IS NULL OR last_used_at < sqlc.arg(before));
+
+-- name: DeleteUserAPIKeys :exec
+-- 删除用户的所有 API Key（权限范围通过外键级联删除）
+DELETE FROM api_keys
+WHERE tenant_id = ? AND user_id = ?;
+
+-- name: TransferAPIKeys :execrows
+-- 将用户未吊销的 API Key 转移给另一个用户（合并账户；已吊销的 Key 留在原用户）
+UPDATE api_keys
+SET user_id = sqlc.arg(to_user_id)
+WHERE tenant_id = sqlc.arg(tenant_id) AND user_id = sqlc.arg(from_user_id) AND revoked_at IS NULL;
@@ -0,0 +1,28 @@
+-- name: CreateAuditEvent :exec
+-- 写入审计事件（只追加）
+INSERT INTO audit_events (
+    tenant_id, actor_id, impersonator_id, action, target_type, target_id,
+    changes, ip, request_id, user_agent, created_at
+) VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?);
+
+-- name: ListAuditEvents :many
+-- 查询审计事件（按 ID 倒序，before_id 为游标，过滤条件为零值时不过滤）
+SELECT id, tenant_id, actor_id, impersonator_id, action, target_type, target_id,
+       changes, ip, request_id, user_agent, created_at
+FROM audit_events
+WHERE tenant_id = sqlc.arg(tenant_id)
+  AND (sqlc.arg(before_id) = 0 OR id < sqlc.arg(before_id))
+  AND (sqlc.arg(actor_id) = 0 OR actor_id = sqlc.arg(actor_id))
+  AND (sqlc.arg(action) = '' OR action = sqlc.arg(action))
+  AND (sqlc.arg(target_type) = '' OR target_type = sqlc.arg(target_type))
+  AND (sqlc.arg(target_id) = 0 OR target_id = sqlc.arg(target_id))
+  AND (sqlc.narg(since) IS NULL OR created_at >= sqlc.narg(since))
+  AND (sqlc.narg(until) IS NULL OR created_at < sqlc.narg(until))
+ORDER BY id DESC
+LIMIT ?;
+
+-- name: DeleteAuditEventsBefore :execrows
+-- 删除保留期之前的审计事件（分批删除，避免长时间锁表）
+DELETE FROM audit_events
+WHERE created_at < ?
+LIMIT ?;
@@ -0,0 +1,47 @@
+-- 所有查询都按 tenant_id 过滤（同一个第三方账户可以分别关联不同租户的用户）
+
+-- name: CreateIdentity :execresult
+-- 关联第三方账户（MySQL 使用 execresult 获取 LastInsertId）
+INSERT INTO identities (tenant_id, user_id, provider, subject, email)
+VALUES (?, ?, ?, ?, ?);
+
+-- name: GetIdentity :one
+-- 通过提供方和提供方用户标识查询关联
+SELECT id, user_id, provider, subject, email, last_login_at, created_at, tenant_id
+FROM identities
+WHERE tenant_id = ? AND provider = ? AND subject = ?
+LIMIT 1;
+
+-- name: ListIdentitiesByUser :many
+-- 列出用户关联的第三方账户
+SELECT id, user_id, provider, subject, email, last_login_at, created_at, tenant_id
+FROM identities
+WHERE tenant_id = ? AND user_id = ?
+ORDER BY provider;
+
+-- name: CountIdentitiesByUser :one
+-- 统计用户关联的第三方账户数量
+SELECT COUNT(*) FROM identities
+WHERE tenant_id = ? AND user_id = ?;
+
+-- name: TouchIdentity :exec
+-- 记录通过第三方账户登录的时间
+UPDATE identities
+SET last_login_at = ?
+WHERE id = ? AND tenant_id = ?;
+
+-- name: DeleteIdentity :execrows
+-- 解除用户与第三方账户的关联
+DELETE FROM identities
+WHERE tenant_id = ? AND user_id = ? AND provider = ?;
+
+-- name: DeleteUserIdentities :exec
+-- 解除用户与所有第三方账户的关联
+DELETE FROM identities
+WHERE tenant_id = ? AND user_id = ?;
+
+-- name: TransferIdentities :execrows
+-- 将用户关联的第三方账户转移给另一个用户（合并账户）
+UPDATE identities
+SET user_id = sqlc.arg(to_user_id)
+WHERE tenant_id = sqlc.arg(tenant_id) AND user_id = sqlc.arg(from_user_id);
@@ -0,0 +1,31 @@
+-- name: ListPasswordHistory :many
+-- 列出用户最近的密码哈希（最新的在前）
+SELECT password_hash
+FROM password_history
+WHERE tenant_id = ? AND user_id = ?
+ORDER BY id DESC
+LIMIT ?;
+
+-- name: CreatePasswordHistory :exec
+-- 记录密码哈希
+INSERT INTO password_history (tenant_id, user_id, password_hash)
+VALUES (?, ?, ?);
+
+-- name: PrunePasswordHistory :exec
+-- 只保留用户最近的 limit 条记录（MySQL 不支持 IN 子查询中使用 LIMIT，外层再包一层派生表）
+DELETE FROM password_history
+WHERE tenant_id = sqlc.arg(tenant_id) AND user_id = sqlc.arg(user_id)
+  AND id NOT IN (
+    SELECT id FROM (
+      SELECT id
+      FROM password_history
+      WHERE tenant_id = sqlc.arg(tenant_id) AND user_id = sqlc.arg(user_id)
+      ORDER BY id DESC
+      LIMIT ?
+    ) AS recent
+  );
+
+-- name: DeletePasswordHistory :exec
+-- 删除用户的所有历史密码
+DELETE FROM password_history
+WHERE tenant_id = ? AND user_id = ?;
@@ -0,0 +1,68 @@
+-- name: ListRoles :many
+-- 列出所有角色
+SELECT id, name, description, level, is_system, created_at, updated_at
+FROM roles
+ORDER BY level DESC, name;
+
+-- name: GetRoleByName :one
+-- 通过名称查询角色
+SELECT id, name, description, level, is_system, created_at, updated_at
+FROM roles
+WHERE name = ?
+LIMIT 1;
+
+-- name: CreateRole :execresult
+-- 创建角色（MySQL 使用 execresult 获取 LastInsertId）
+INSERT INTO roles (name, description, level)
+VALUES (?, ?, ?);
+
+-- name: UpdateRole :exec
+-- 更新角色描述和级别
+UPDATE roles
+SET description = ?, level = ?
+WHERE id = ?;
+
+-- name: DeleteRole :execrows
+-- 删除角色（内置角色不能删除）
+DELETE FROM roles
+WHERE id = ? AND is_system = FALSE;
+
+-- name: CountUsersByRole :one
+-- 统计使用指定角色的用户数量
+SELECT COUNT(*) FROM users
+WHERE role = ?;
+
+-- name: ListPermissions :many
+-- 列出所有权限
+SELECT id, name, description, is_system, created_at
+FROM permissions
+ORDER BY name;
+
+-- name: CreatePermission :execresult
+-- 创建权限
+INSERT INTO permissions (name, description)
+VALUES (?, ?);
+
+-- name: DeletePermission :execrows
+-- 删除权限（内置权限不能删除，角色关联随之删除）
+DELETE FROM permissions
+WHERE name = ? AND is_system = FALSE;
+
+-- name: ListRolePermissions :many
+-- 列出所有角色的权限（加载策略时一次查出）
+SELECT r.name AS role_name, p.name AS permission_name
+FROM role_permissions rp
+JOIN roles r ON r.id = rp.role_id
+JOIN permissions p ON p.id = rp.permission_id
+ORDER BY r.name, p.name;
+
+-- name: AddRolePermission :exec
+-- 为角色添加权限
+INSERT INTO role_permissions (role_id, permission_id)
+SELECT ?, id FROM permissions
+WHERE name = ?;
+
+-- name: DeleteRolePermissions :exec
+-- 清空角色的权限
+DELETE FROM role_permissions
+WHERE role_id = ?;
@@ -0,0 +1,13 @@
+-- name: GetTenantByID :one
+-- 通过 ID 获取租户
+SELECT id, slug, name, status, created_at, updated_at
+FROM tenants
+WHERE id = ?
+LIMIT 1;
+
+-- name: GetTenantBySlug :one
+-- 通过标识获取租户（子域名或请求头）
+SELECT id, slug, name, status, created_at, updated_at
+FROM tenants
+WHERE slug = ?
+LIMIT 1;
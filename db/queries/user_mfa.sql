@@ -0,0 +1,57 @@
+-- name: GetUserMFA :one
+-- 获取用户两步验证配置
+SELECT user_id, totp_secret, enabled, last_used_step, enabled_at, created_at, updated_at
+FROM user_mfa
+WHERE user_id = ?
+LIMIT 1;
+
+-- name: UpsertUserMFASecret :exec
+-- 保存待绑定的 TOTP 密钥（重新绑定时重置为未启用）
+INSERT INTO user_mfa (user_id, totp_secret)
+VALUES (?, ?)
+ON DUPLICATE KEY UPDATE
+    totp_secret = VALUES(totp_secret),
+    enabled = 0,
+    last_used_step = 0,
+    enabled_at = NULL;
+
+-- name: EnableUserMFA :exec
+-- 启用两步验证（记录绑定时使用的时间步）
+UPDATE user_mfa
+SET enabled = 1,
+    last_used_step = ?,
+    enabled_at = CURRENT_TIMESTAMP
+WHERE user_id = ?;
+
+-- name: UpdateUserMFALastUsedStep :execrows
+-- 记录已使用的 TOTP 时间步（只允许递增，影响行数为 0 表示验证码已被使用）
+UPDATE user_mfa
+SET last_used_step = sqlc.arg(step)
+WHERE user_id = sqlc.arg(user_id) AND last_used_step < sqlc.arg(step);
+
+-- name: DeleteUserMFA :exec
+-- 关闭两步验证
+DELETE FROM user_mfa
+WHERE user_id = ?;
+
+-- name: CreateUserRecoveryCode :exec
+-- 保存恢复码哈希
+INSERT INTO user_recovery_codes (user_id, code_hash)
+VALUES (?, ?);
+
+-- name: UseUserRecoveryCode :execrows
+-- 使用恢复码（影响行数为 0 表示恢复码无效或已使用）
+UPDATE user_recovery_codes
+SET used_at = CURRENT_TIMESTAMP
+WHERE user_id = ? AND code_hash = ? AND used_at IS NULL;
+
+-- name: CountUnusedRecoveryCodes :one
+-- 统计剩余可用的恢复码
+SELECT COUNT(*)
+FROM user_recovery_codes
+WHERE user_id = ? AND used_at IS NULL;
+
+-- name: DeleteUserRecoveryCodes :exec
+-- 清空用户的恢复码
+DELETE FROM user_recovery_codes
+WHERE user_id = ?;
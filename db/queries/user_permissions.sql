@@ -0,0 +1,16 @@
+-- name: ListUserPermissions :many
+-- 列出用户的额外权限
+SELECT permission
+FROM user_permissions
+WHERE user_id = ?
+ORDER BY permission;
+
+-- name: AddUserPermission :exec
+-- 授予用户额外权限
+INSERT INTO user_permissions (user_id, permission)
+VALUES (?, ?);
+
+-- name: DeleteUserPermissions :exec
+-- 清空用户的额外权限
+DELETE FROM user_permissions
+WHERE user_id = ?;
@@ -0,0 +1,32 @@
+-- name: CreateUserToken :exec
+-- 保存一次性令牌哈希
+INSERT INTO user_tokens (user_id, purpose, token_hash, expires_at)
+VALUES (?, ?, ?, ?);
+
+-- name: GetUserToken :one
+-- 通过令牌哈希查询一次性令牌
+SELECT id, user_id, purpose, token_hash, expires_at, used_at, created_at
+FROM user_tokens
+WHERE token_hash = ? AND purpose = ?
+LIMIT 1;
+
+-- name: UseUserToken :execrows
+-- 使用一次性令牌（影响行数为 0 表示令牌已使用或已过期）
+UPDATE user_tokens
+SET used_at = CURRENT_TIMESTAMP
+WHERE id = ? AND used_at IS NULL AND expires_at > CURRENT_TIMESTAMP;
+
+-- name: DeleteUserTokens :exec
+-- 删除用户某种用途的所有令牌（签发新令牌或使用后作废旧令牌）
+DELETE FROM user_tokens
+WHERE user_id = ? AND purpose = ?;
+
+-- name: DeleteAllUserTokens :exec
+-- 删除用户所有用途的令牌
+DELETE FROM user_tokens
+WHERE user_id = ?;
+
+-- name: DeleteExpiredUserTokens :execrows
+-- 清理过期或已使用的令牌
+DELETE FROM user_tokens
+WHERE expires_at < sqlc.arg(before) OR used_at < sqlc.arg(before);
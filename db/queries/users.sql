@@ -1,36 +1,46 @@
+-- 所有查询都按 tenant_id 过滤（租户由 context 决定，见 pkg/tenant）
+
 -- name: GetUserByID :one
--- 通过 ID 获取用户
-SELECT id, username, email, avatar, status, created_at, updated_at
+-- 通过 ID 获取用户（包含邮箱未验证的用户）
+SELECT id, username, email, avatar, status, created_at, updated_at, role, token_version, tenant_id
 FROM users
-WHERE id = ? AND status = 1
+WHERE id = ? AND tenant_id = ? AND status IN (1, 3)
 LIMIT 1;
 
 -- name: GetUserByEmail :one
--- 通过 Email 获取用户（包含密码，用于登录验证）
-SELECT id, username, email, password, avatar, status, created_at, updated_at
+-- 通过 Email 获取用户（包含密码，用于登录验证；包含邮箱未验证的用户）
+SELECT id, username, email, password, avatar, status, created_at, updated_at, role, token_version, tenant_id, status_reason, deleted_at
+FROM users
+WHERE email = ? AND tenant_id = ? AND status IN (1, 3)
+LIMIT 1;
+
+-- name: GetUserByIDAnyStatus :one
+-- 通过 ID 获取用户（包含停用和已删除的用户，用于管理员变更用户状态）
+SELECT id, username, email, avatar, status, created_at, updated_at, role, token_version, tenant_id, status_reason, deleted_at, merged_into
+FROM users
+WHERE id = ? AND tenant_id = ?
+LIMIT 1;
+
+-- name: GetUserPasswordByID :one
+-- 通过 ID 获取用户密码哈希（用于修改密码时校验旧密码）
+SELECT password
 FROM users
-WHERE email = ? AND status = 1
+WHERE id = ? AND tenant_id = ? AND status IN (1, 3)
 LIMIT 1;
 
 -- name: GetUserByUsername :one
 -- 通过 Username 获取用户
-SELECT id, username, email, avatar, status, created_at, updated_at
+SELECT id, username, email, avatar, status, created_at, updated_at, role, tenant_id
 FROM users
-WHERE username = ? AND status = 1
+WHERE username = ? AND tenant_id = ? AND status IN (1, 3)
 LIMIT 1;
 
--- name: ListUsers :many
--- 列出用户（分页）
-SELECT id, username, email, avatar, status, created_at, updated_at
-FROM users
-WHERE status = 1
-ORDER BY created_at DESC
-LIMIT ? OFFSET ?;
+-- 用户列表和总数按过滤条件动态构建（见 internal/repository/user_filter.go）
 
 -- name: CreateUser :execresult
--- 创建用户（MySQL 使用 execresult 获取 LastInsertId）
-INSERT INTO users (username, email, password, avatar)
-VALUES (?, ?, ?, ?);
+-- 创建用户（MySQL 使用 execresult 获取 LastInsertId；status 1:正常 3:邮箱未验证）
+INSERT INTO users (tenant_id, username, email, password, avatar, status)
+VALUES (?, ?, ?, ?, ?, ?);
 
 -- name: UpdateUser :exec
 -- 更新用户信息
@@ -38,36 +48,94 @@ UPDATE users
 SET username = ?,
     email = ?,
     avatar = ?
-WHERE id = ?;
+WHERE id = ? AND tenant_id = ?;
 
 -- name: UpdateUserPassword :exec
--- 更新用户密码
+-- 更新用户密码（同时递增 Token 版本号，使旧 Token 失效）
 UPDATE users
-SET password = ?
-WHERE id = ?;
+SET password = ?,
+    token_version = token_version + 1
+WHERE id = ? AND tenant_id = ?;
 
--- name: DeleteUser :exec
--- 软删除用户（设置状态为禁用）
+-- name: RehashUserPassword :execrows
+-- 更新密码哈希（算法或参数升级，密码未变，不吊销 Token；旧哈希不匹配时不更新，避免覆盖并发修改的密码）
 UPDATE users
-SET status = 2
-WHERE id = ?;
+SET password = sqlc.arg(new_password)
+WHERE id = sqlc.arg(id) AND tenant_id = sqlc.arg(tenant_id) AND password = sqlc.arg(old_password);
 
--- name: CountUsers :one
--- 统计用户总数
-SELECT COUNT(*) as total
-FROM users
-WHERE status = 1;
+-- name: UpdateUserRole :exec
+-- 更新用户角色（同时递增 Token 版本号，已签发的 Token 中的旧角色立即失效）
+UPDATE users
+SET role = ?,
+    token_version = token_version + 1
+WHERE id = ? AND tenant_id = ?;
+
+-- name: UpdateUserStatus :execrows
+-- 变更用户状态（状态 1:正常 2:停用 3:待激活 4:已删除；只在当前状态与预期一致时更新，同时递增 Token 版本号）
+UPDATE users
+SET status = sqlc.arg(status),
+    status_reason = sqlc.narg(status_reason),
+    deleted_at = sqlc.narg(deleted_at),
+    token_version = token_version + 1
+WHERE id = sqlc.arg(id) AND tenant_id = sqlc.arg(tenant_id) AND status = sqlc.arg(from_status);
+
+-- name: PurgeDeletedUsers :execrows
+-- 永久删除超过保留期的已删除用户（所有租户，分批执行；关联数据通过外键级联删除）
+DELETE FROM users
+WHERE status = 4 AND deleted_at < ?
+LIMIT ?;
+
+-- name: AnonymizeUser :execrows
+-- 匿名化用户（清除个人信息并设为已匿名状态，保留记录；同时递增 Token 版本号）
+UPDATE users
+SET username = ?,
+    email = ?,
+    password = '',
+    avatar = NULL,
+    status = 5,
+    status_reason = NULL,
+    deleted_at = COALESCE(deleted_at, CURRENT_TIMESTAMP),
+    token_version = token_version + 1
+WHERE id = ? AND tenant_id = ? AND status <> 5;
+
+-- name: MergeUser :execrows
+-- 标记用户已合并到另一个用户（只在当前状态与预期一致时更新，同时递增 Token 版本号）
+UPDATE users
+SET status = 6,
+    merged_into = sqlc.arg(merged_into),
+    status_reason = NULL,
+    token_version = token_version + 1
+WHERE id = sqlc.arg(id) AND tenant_id = sqlc.arg(tenant_id) AND status = sqlc.arg(from_status);
 
 -- name: GetUserIDByEmail :one
 -- 通过 Email 获取用户 ID（用于缓存索引）
 SELECT id
 FROM users
-WHERE email = ? AND status = 1
+WHERE email = ? AND tenant_id = ? AND status IN (1, 3)
 LIMIT 1;
 
 -- name: GetUserIDByUsername :one
 -- 通过 Username 获取用户 ID（用于缓存索引）
 SELECT id
 FROM users
-WHERE username = ? AND status = 1
+WHERE username = ? AND tenant_id = ? AND status IN (1, 3)
 LIMIT 1;
+
+-- name: GetUserTokenVersion :one
+-- 获取用户 Token 版本号（用于校验 Token 是否已被吊销）
+SELECT token_version
+FROM users
+WHERE id = ? AND tenant_id = ? AND status = 1
+LIMIT 1;
+
+-- name: IncrementUserTokenVersion :exec
+-- 递增 Token 版本号（吊销所有已签发 Token）
+UPDATE users
+SET token_version = token_version + 1
+WHERE id = ? AND tenant_id = ?;
+
+-- name: VerifyUserEmail :execrows
+-- 标记邮箱已验证（仅对未验证状态生效）
+UPDATE users
+SET status = 1
+WHERE id = ? AND tenant_id = ? AND status = 3;
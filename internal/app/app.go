@@ -17,7 +17,9 @@ type Application struct {
 	DB          *sql.DB
 	Redis       redis.UniversalClient
 	TaskManager TaskManager
-	Handlers    *Handlers // HTTP 处理器
+	Policy      PolicyWatcher // 角色权限策略定期加载
+	Audit       AuditWriter   // 审计日志异步写入
+	Handlers    *Handlers     // HTTP 处理器
 }
 
 // TaskManager 任务管理器接口
@@ -27,6 +29,18 @@ type TaskManager interface {
 	ListTasks() []string
 }
 
+// PolicyWatcher 角色权限策略定期加载接口（每个实例各自运行，不经过任务调度的分布式锁）
+type PolicyWatcher interface {
+	Start()
+	Stop()
+}
+
+// AuditWriter 审计日志异步写入接口（Stop 时写完缓冲区中的事件）
+type AuditWriter interface {
+	Start()
+	Stop()
+}
+
 // New 创建应用程序实例
 func New(
 	cfg *config.Config,
@@ -34,6 +48,8 @@ func New(
 	redis redis.UniversalClient,
 	handlers *Handlers,
 	taskManager TaskManager,
+	policy PolicyWatcher,
+	auditWriter AuditWriter,
 ) *Application {
 	server := NewServer(cfg)
 	server.handlers = handlers // 注入 handlers
@@ -44,6 +60,8 @@ func New(
 		DB:          db,
 		Redis:       redis,
 		TaskManager: taskManager,
+		Policy:      policy,
+		Audit:       auditWriter,
 		Handlers:    handlers,
 	}
 }
@@ -79,6 +97,12 @@ func (app *Application) Start() error {
 	app.TaskManager.Start()
 	slog.Info("Task scheduler started", "tasks", app.TaskManager.ListTasks())
 
+	// 启动角色权限策略定期加载
+	app.Policy.Start()
+
+	// 启动审计日志写入
+	app.Audit.Start()
+
 	// 启动 HTTP 服务器
 	if err := app.Server.Start(); err != nil {
 		return err
@@ -91,10 +115,14 @@ func (app *Application) Start() error {
 func (app *Application) Shutdown() {
 	// 停止定时任务
 	app.TaskManager.Stop()
+	app.Policy.Stop()
 
 	// 关闭 HTTP 服务器
 	app.Server.Shutdown()
 
+	// 写完剩余的审计日志（在请求处理完之后、关闭数据库之前）
+	app.Audit.Stop()
+
 	// 清理资源
 	app.Cleanup()
 }
@@ -0,0 +1,40 @@
+package apikey
+
+import "time"
+
+// ========================================
+// 请求 DTO
+// ========================================
+
+// IDRequest API Key ID 路径参数
+type IDRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// CreateRequest 创建 API Key 请求
+type CreateRequest struct {
+	Name          string   `json:"name" binding:"required,max=100"`
+	Scopes        []string `json:"scopes" binding:"required,min=1,max=20,dive,required"` // 权限范围（如 user:read）
+	ExpiresInDays int      `json:"expires_in_days" binding:"omitempty,min=1,max=3650"`   // 有效期（天），不填使用配置的最长有效期
+}
+
+// ========================================
+// 响应 DTO
+// ========================================
+
+// Response API Key 响应（不包含密钥）
+type Response struct {
+	ID         int64      `json:"id"`
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"` // 公开标识（Key 中 gdk_ 之后的部分）
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at"` // null 表示永不过期
+	LastUsedAt *time.Time `json:"last_used_at"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// CreateResponse 创建 API Key 响应（Key 明文只返回这一次）
+type CreateResponse struct {
+	Response
+	Key string `json:"key"`
+}
@@ -0,0 +1,163 @@
+package apikey
+
+import (
+	"log/slog"
+	"time"
+
+	"gin_demo/internal/app/middleware"
+	"gin_demo/internal/domain/service"
+	"gin_demo/internal/response"
+	"gin_demo/pkg/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler API Key 处理器
+type Handler struct {
+	apiKeyService service.APIKeyService
+}
+
+// NewHandler 创建 API Key 处理器
+func NewHandler(apiKeyService service.APIKeyService) *Handler {
+	return &Handler{
+		apiKeyService: apiKeyService,
+	}
+}
+
+// Create 创建 API Key
+//
+// @Summary 创建 API Key
+// @Description 为当前用户创建 API Key（用于服务间调用），权限范围不能超出用户自身的权限；完整 Key 只在响应中返回一次
+// @Tags API Key
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateRequest true "名称、权限范围和有效期"
+// @Success 200 {object} response.Response{data=CreateResponse} "创建成功"
+// @Failure 400 {object} response.Response "参数错误或数量已达上限"
+// @Failure 401 {object} response.Response "未认证"
+// @Failure 403 {object} response.Response "权限范围超出用户自身权限"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /api-keys [post]
+func (h *Handler) Create(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Error(c, response.New(response.CodeUnauthorized, "未认证"))
+		return
+	}
+
+	var req CreateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "参数错误", err))
+		return
+	}
+
+	scopes := make([]auth.Permission, len(req.Scopes))
+	for i, scope := range req.Scopes {
+		scopes[i] = auth.Permission(scope)
+	}
+
+	created, err := h.apiKeyService.Create(c.Request.Context(), service.CreateAPIKeyInput{
+		UserID: userID,
+		Name:   req.Name,
+		Scopes: scopes,
+		TTL:    time.Duration(req.ExpiresInDays) * 24 * time.Hour,
+	})
+	if err != nil {
+		slog.WarnContext(c.Request.Context(), "Create api key failed", "user_id", userID, "error", err)
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, CreateResponse{
+		Response: toResponse(created.APIKey),
+		Key:      created.Key,
+	})
+}
+
+// List 列出 API Key
+//
+// @Summary API Key 列表
+// @Description 列出当前用户未吊销的 API Key（不包含密钥）
+// @Tags API Key
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]Response} "获取成功"
+// @Failure 401 {object} response.Response "未认证"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /api-keys [get]
+func (h *Handler) List(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Error(c, response.New(response.CodeUnauthorized, "未认证"))
+		return
+	}
+
+	keys, err := h.apiKeyService.List(c.Request.Context(), userID)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "List api keys failed", "user_id", userID, "error", err)
+		response.Error(c, err)
+		return
+	}
+
+	result := make([]Response, len(keys))
+	for i, key := range keys {
+		result[i] = toResponse(key)
+	}
+	response.Success(c, result)
+}
+
+// Revoke 吊销 API Key
+//
+// @Summary 吊销 API Key
+// @Description 吊销当前用户的 API Key，立即生效
+// @Tags API Key
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "API Key ID"
+// @Success 200 {object} response.Response "吊销成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未认证"
+// @Failure 404 {object} response.Response "API Key 不存在"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /api-keys/{id} [delete]
+func (h *Handler) Revoke(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Error(c, response.New(response.CodeUnauthorized, "未认证"))
+		return
+	}
+
+	var req IDRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "无效的 API Key ID", err))
+		return
+	}
+
+	if err := h.apiKeyService.Revoke(c.Request.Context(), userID, req.ID); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// toResponse 转换为响应 DTO
+func toResponse(key service.APIKey) Response {
+	scopes := make([]string, len(key.Scopes))
+	for i, scope := range key.Scopes {
+		scopes[i] = string(scope)
+	}
+
+	return Response{
+		ID:         key.ID,
+		Name:       key.Name,
+		Prefix:     key.Prefix,
+		Scopes:     scopes,
+		ExpiresAt:  key.ExpiresAt,
+		LastUsedAt: key.LastUsedAt,
+		CreatedAt:  key.CreatedAt,
+	}
+}
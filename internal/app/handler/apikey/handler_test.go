@@ -0,0 +1,169 @@
+package apikey
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gin_demo/internal/app/middleware"
+	"gin_demo/internal/domain/service"
+	"gin_demo/pkg/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockAPIKeyService 是 APIKeyService 的 mock 实现
+type MockAPIKeyService struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyService) Create(ctx context.Context, input service.CreateAPIKeyInput) (service.CreatedAPIKey, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(service.CreatedAPIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyService) List(ctx context.Context, userID int64) ([]service.APIKey, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]service.APIKey), args.Error(1)
+}
+
+func (m *MockAPIKeyService) Revoke(ctx context.Context, userID, keyID int64) error {
+	args := m.Called(ctx, userID, keyID)
+	return args.Error(0)
+}
+
+func (m *MockAPIKeyService) Authenticate(ctx context.Context, key string) (*auth.RBACClaims, error) {
+	args := m.Called(ctx, key)
+	claims, _ := args.Get(0).(*auth.RBACClaims)
+	return claims, args.Error(1)
+}
+
+// setupTestRouter 设置测试路由（模拟认证中间件设置当前用户）
+func setupTestRouter(userID int64) (*gin.Engine, *MockAPIKeyService) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockAPIKeyService)
+	handler := NewHandler(mockService)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		if userID != 0 {
+			c.Set(middleware.UserIDKey, userID)
+		}
+		c.Next()
+	})
+	router.POST("/api-keys", handler.Create)
+	router.GET("/api-keys", handler.List)
+	router.DELETE("/api-keys/:id", handler.Revoke)
+	return router, mockService
+}
+
+// TestHandler_Create 测试创建 API Key
+func TestHandler_Create(t *testing.T) {
+	t.Run("创建成功并返回明文 Key", func(t *testing.T) {
+		router, mockService := setupTestRouter(1)
+		mockService.On("Create", mock.Anything, service.CreateAPIKeyInput{
+			UserID: 1,
+			Name:   "batch job",
+			Scopes: []auth.Permission{auth.PermissionUserRead},
+			TTL:    30 * 24 * time.Hour,
+		}).Return(service.CreatedAPIKey{
+			APIKey: service.APIKey{ID: 7, Name: "batch job", Prefix: "0123456789ab", Scopes: []auth.Permission{auth.PermissionUserRead}},
+			Key:    "gdk_0123456789ab_secret",
+		}, nil)
+
+		body, _ := json.Marshal(CreateRequest{Name: "batch job", Scopes: []string{"user:read"}, ExpiresInDays: 30})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api-keys", bytes.NewBuffer(body)))
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp struct {
+			Data CreateResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "gdk_0123456789ab_secret", resp.Data.Key)
+		assert.Equal(t, []string{"user:read"}, resp.Data.Scopes)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("缺少权限范围", func(t *testing.T) {
+		router, mockService := setupTestRouter(1)
+
+		body, _ := json.Marshal(CreateRequest{Name: "batch job"})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api-keys", bytes.NewBuffer(body)))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "Create", mock.Anything, mock.Anything)
+	})
+
+	t.Run("权限范围超出用户权限", func(t *testing.T) {
+		router, mockService := setupTestRouter(1)
+		mockService.On("Create", mock.Anything, mock.Anything).Return(service.CreatedAPIKey{}, service.ErrAPIKeyScopeNotAllowed)
+
+		body, _ := json.Marshal(CreateRequest{Name: "batch job", Scopes: []string{"system:config"}})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api-keys", bytes.NewBuffer(body)))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("未认证", func(t *testing.T) {
+		router, _ := setupTestRouter(0)
+
+		body, _ := json.Marshal(CreateRequest{Name: "batch job", Scopes: []string{"user:read"}})
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api-keys", bytes.NewBuffer(body)))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+// TestHandler_List 测试 API Key 列表
+func TestHandler_List(t *testing.T) {
+	router, mockService := setupTestRouter(1)
+	mockService.On("List", mock.Anything, int64(1)).Return([]service.APIKey{
+		{ID: 7, Name: "batch job", Prefix: "0123456789ab", Scopes: []auth.Permission{auth.PermissionUserRead}},
+	}, nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api-keys", nil))
+	require.Equal(t, http.StatusOK, w.Code)
+
+	// 列表不返回 Key 明文
+	assert.NotContains(t, w.Body.String(), `"key"`)
+	assert.Contains(t, w.Body.String(), `"prefix":"0123456789ab"`)
+}
+
+// TestHandler_Revoke 测试吊销 API Key
+func TestHandler_Revoke(t *testing.T) {
+	t.Run("吊销成功", func(t *testing.T) {
+		router, mockService := setupTestRouter(1)
+		mockService.On("Revoke", mock.Anything, int64(1), int64(7)).Return(nil)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/api-keys/7", nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("不存在", func(t *testing.T) {
+		router, mockService := setupTestRouter(1)
+		mockService.On("Revoke", mock.Anything, int64(1), int64(8)).Return(service.ErrAPIKeyNotFound)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/api-keys/8", nil))
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("无效的 ID", func(t *testing.T) {
+		router, _ := setupTestRouter(1)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/api-keys/abc", nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
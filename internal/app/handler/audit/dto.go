@@ -0,0 +1,48 @@
+package audit
+
+import (
+	"time"
+
+	"gin_demo/pkg/audit"
+)
+
+// ========================================
+// 请求 DTO
+// ========================================
+
+// ListRequest 审计日志查询参数（均为可选）
+type ListRequest struct {
+	ActorID    int64     `form:"actor_id" binding:"min=0"`
+	Action     string    `form:"action" binding:"max=64"`
+	TargetType string    `form:"target_type" binding:"max=32"`
+	TargetID   int64     `form:"target_id" binding:"min=0"`
+	Since      time.Time `form:"since" time_format:"2006-01-02T15:04:05Z07:00"` // RFC 3339，包含
+	Until      time.Time `form:"until" time_format:"2006-01-02T15:04:05Z07:00"` // RFC 3339，不包含
+	Cursor     string    `form:"cursor" binding:"max=64"`                       // 上一页返回的 next_cursor
+	Limit      int       `form:"limit" binding:"min=0,max=200"`                 // 默认 50
+}
+
+// ========================================
+// 响应 DTO
+// ========================================
+
+// EventResponse 审计事件响应
+type EventResponse struct {
+	ID             int64         `json:"id"`
+	ActorID        int64         `json:"actor_id,omitempty"`        // 为空表示匿名操作（如注册）
+	ImpersonatorID int64         `json:"impersonator_id,omitempty"` // 模拟登录时实际操作的管理员
+	Action         string        `json:"action"`
+	TargetType     string        `json:"target_type"`
+	TargetID       int64         `json:"target_id,omitempty"`
+	Changes        audit.Changes `json:"changes,omitempty"`
+	IP             string        `json:"ip"`
+	RequestID      string        `json:"request_id"`
+	UserAgent      string        `json:"user_agent"`
+	CreatedAt      time.Time     `json:"created_at"`
+}
+
+// ListResponse 审计日志分页响应
+type ListResponse struct {
+	Items      []EventResponse `json:"items"`
+	NextCursor string          `json:"next_cursor,omitempty"` // 为空表示没有更多数据
+}
@@ -0,0 +1,83 @@
+package audit
+
+import (
+	"gin_demo/internal/domain/service"
+	"gin_demo/internal/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler 审计日志处理器（仅超级管理员）
+type Handler struct {
+	auditService service.AuditService
+}
+
+// NewHandler 创建审计日志处理器
+func NewHandler(auditService service.AuditService) *Handler {
+	return &Handler{
+		auditService: auditService,
+	}
+}
+
+// List 查询审计日志
+//
+// @Summary 查询审计日志
+// @Description 按时间倒序查询当前租户的审计日志（游标分页）
+// @Tags 审计日志
+// @Produce json
+// @Security BearerAuth
+// @Param actor_id query int false "操作者用户 ID"
+// @Param action query string false "操作（如 user.update）"
+// @Param target_type query string false "目标类型（如 user）"
+// @Param target_id query int false "目标 ID"
+// @Param since query string false "起始时间（RFC 3339，包含）"
+// @Param until query string false "结束时间（RFC 3339，不包含）"
+// @Param cursor query string false "上一页返回的 next_cursor"
+// @Param limit query int false "每页数量" default(50)
+// @Success 200 {object} response.Response{data=ListResponse} "获取成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未认证"
+// @Failure 403 {object} response.Response "需要超级管理员"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /admin/audit [get]
+func (h *Handler) List(c *gin.Context) {
+	var req ListRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "参数错误", err))
+		return
+	}
+
+	page, err := h.auditService.List(c.Request.Context(), service.AuditFilter{
+		ActorID:    req.ActorID,
+		Action:     req.Action,
+		TargetType: req.TargetType,
+		TargetID:   req.TargetID,
+		Since:      req.Since,
+		Until:      req.Until,
+		Cursor:     req.Cursor,
+		Limit:      req.Limit,
+	})
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	items := make([]EventResponse, 0, len(page.Events))
+	for _, event := range page.Events {
+		items = append(items, EventResponse{
+			ID:             event.ID,
+			ActorID:        event.ActorID,
+			ImpersonatorID: event.ImpersonatorID,
+			Action:         event.Action,
+			TargetType:     event.TargetType,
+			TargetID:       event.TargetID,
+			Changes:        event.Changes,
+			IP:             event.IP,
+			RequestID:      event.RequestID,
+			UserAgent:      event.UserAgent,
+			CreatedAt:      event.CreatedAt,
+		})
+	}
+
+	response.Success(c, ListResponse{Items: items, NextCursor: page.NextCursor})
+}
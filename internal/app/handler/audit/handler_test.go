@@ -0,0 +1,122 @@
+package audit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gin_demo/internal/app/middleware"
+	"gin_demo/internal/domain/service"
+	"gin_demo/pkg/audit"
+	"gin_demo/pkg/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockAuditService 是 AuditService 的 mock 实现
+type MockAuditService struct {
+	mock.Mock
+}
+
+func (m *MockAuditService) List(ctx context.Context, filter service.AuditFilter) (service.AuditPage, error) {
+	args := m.Called(ctx, filter)
+	return args.Get(0).(service.AuditPage), args.Error(1)
+}
+
+func (m *MockAuditService) WriteEvents(ctx context.Context, events []audit.Event) error {
+	args := m.Called(ctx, events)
+	return args.Error(0)
+}
+
+// setupTestRouter 设置测试路由（模拟 RBAC 认证中间件设置当前用户的角色）
+func setupTestRouter(role auth.Role) (*gin.Engine, *MockAuditService) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockAuditService)
+	handler := NewHandler(mockService)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(middleware.UserIDKey, int64(1))
+		c.Set(middleware.RBACClaimsKey, &auth.RBACClaims{UserID: 1, Role: role})
+		c.Next()
+	})
+	router.Use(middleware.RequireSuperAdmin())
+	router.GET("/admin/audit", handler.List)
+	return router, mockService
+}
+
+// TestHandler_List 测试查询审计日志
+func TestHandler_List(t *testing.T) {
+	t.Run("按条件查询", func(t *testing.T) {
+		router, mockService := setupTestRouter(auth.RoleSuperAdmin)
+		since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		mockService.On("List", mock.Anything, service.AuditFilter{
+			TargetType: "user",
+			TargetID:   5,
+			Since:      since,
+			Cursor:     "Nw",
+			Limit:      20,
+		}).Return(service.AuditPage{
+			Events: []service.AuditEvent{{
+				ID:         6,
+				ActorID:    1,
+				Action:     service.AuditActionUserUpdate,
+				TargetType: "user",
+				TargetID:   5,
+				Changes:    audit.Changes{"email": {From: "a@example.com", To: "b@example.com"}},
+			}},
+			NextCursor: "Ng",
+		}, nil)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodGet,
+			"/admin/audit?target_type=user&target_id=5&since=2026-01-01T00:00:00Z&cursor=Nw&limit=20", nil)
+		router.ServeHTTP(w, req)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var resp struct {
+			Data ListResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		require.Len(t, resp.Data.Items, 1)
+		assert.Equal(t, service.AuditActionUserUpdate, resp.Data.Items[0].Action)
+		assert.Equal(t, "b@example.com", resp.Data.Items[0].Changes["email"].To)
+		assert.Equal(t, "Ng", resp.Data.NextCursor)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("参数错误", func(t *testing.T) {
+		router, mockService := setupTestRouter(auth.RoleSuperAdmin)
+
+		for _, query := range []string{"limit=500", "since=yesterday", "actor_id=-1"} {
+			w := httptest.NewRecorder()
+			router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/audit?"+query, nil))
+			assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		}
+		mockService.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+	})
+
+	t.Run("无效游标", func(t *testing.T) {
+		router, mockService := setupTestRouter(auth.RoleSuperAdmin)
+		mockService.On("List", mock.Anything, mock.Anything).Return(service.AuditPage{}, service.ErrInvalidAuditCursor)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/audit?cursor=bad", nil))
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("管理员不能查看", func(t *testing.T) {
+		router, mockService := setupTestRouter(auth.RoleAdmin)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/admin/audit", nil))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		mockService.AssertNotCalled(t, "List", mock.Anything, mock.Anything)
+	})
+}
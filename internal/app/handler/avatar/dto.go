@@ -0,0 +1,11 @@
+package avatar
+
+// ========================================
+// 响应 DTO
+// ========================================
+
+// AvatarResponse 上传头像响应
+type AvatarResponse struct {
+	Avatar string            `json:"avatar"` // 用户资料中的头像地址（256×256）
+	Sizes  map[string]string `json:"sizes"`  // 各尺寸的地址，键为边长（如 "256"、"64"）
+}
@@ -0,0 +1,123 @@
+package avatar
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"strconv"
+
+	"gin_demo/internal/app/middleware"
+	"gin_demo/internal/domain/service"
+	"gin_demo/internal/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// formField 上传文件的表单字段
+const formField = "file"
+
+// Handler 头像处理器
+type Handler struct {
+	avatarService service.AvatarService
+}
+
+// NewHandler 创建头像处理器
+func NewHandler(avatarService service.AvatarService) *Handler {
+	return &Handler{
+		avatarService: avatarService,
+	}
+}
+
+// Upload 上传当前用户的头像
+//
+// @Summary 上传头像
+// @Description 以 multipart/form-data 上传图片（字段 file），按内容识别格式（支持 JPEG、PNG、GIF），
+// @Description 从中间裁剪为正方形并缩放为 256×256 和 64×64 的 JPEG，保存后更新用户头像并删除原来上传的头像文件。
+// @Description 文件大小受 user.avatar_max_size 和 server.max_request_body_size 限制
+// @Tags 用户管理
+// @Accept multipart/form-data
+// @Produce json
+// @Security BearerAuth
+// @Param file formData file true "头像图片"
+// @Success 200 {object} response.Response{data=AvatarResponse} "上传成功"
+// @Failure 400 {object} response.Response "参数错误、缺少文件或不支持的图片格式"
+// @Failure 401 {object} response.Response "未认证"
+// @Failure 413 {object} response.Response "文件过大"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /users/me/avatar [post]
+func (h *Handler) Upload(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Error(c, response.NewWithError(response.CodeUnauthorized, "未认证", nil))
+		return
+	}
+
+	// 逐个读取表单部分，文件直接交给服务读取（不先保存到内存或临时文件）
+	reader, err := c.Request.MultipartReader()
+	if err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "参数错误", err))
+		return
+	}
+	for {
+		part, err := reader.NextPart()
+		if err != nil {
+			switch {
+			case middleware.IsBodyTooLarge(err):
+				response.Error(c, response.ErrRequestTooLarge)
+			case errors.Is(err, io.EOF):
+				response.Error(c, response.New(response.CodeInvalidParams, "缺少头像文件"))
+			default:
+				response.Error(c, response.NewWithError(response.CodeInvalidParams, "参数错误", err))
+			}
+			return
+		}
+		if part.FormName() != formField || part.FileName() == "" {
+			continue
+		}
+
+		avatar, err := h.avatarService.UploadAvatar(c.Request.Context(), userID, part)
+		if err != nil {
+			if middleware.IsBodyTooLarge(err) {
+				response.Error(c, response.ErrRequestTooLarge)
+				return
+			}
+			slog.WarnContext(c.Request.Context(), "Upload avatar failed", "user_id", userID, "error", err)
+			response.Error(c, err)
+			return
+		}
+
+		sizes := make(map[string]string, len(avatar.Sizes))
+		for size, url := range avatar.Sizes {
+			sizes[strconv.Itoa(size)] = url
+		}
+		response.Success(c, AvatarResponse{Avatar: avatar.URL, Sizes: sizes})
+		return
+	}
+}
+
+// Delete 清除当前用户的头像
+//
+// @Summary 清除头像
+// @Description 清除用户头像，并删除上传的头像文件（头像为外部地址时只清除地址）
+// @Tags 用户管理
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response "清除成功"
+// @Failure 401 {object} response.Response "未认证"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /users/me/avatar [delete]
+func (h *Handler) Delete(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Error(c, response.NewWithError(response.CodeUnauthorized, "未认证", nil))
+		return
+	}
+
+	if err := h.avatarService.DeleteAvatar(c.Request.Context(), userID); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Delete avatar failed", "user_id", userID, "error", err)
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
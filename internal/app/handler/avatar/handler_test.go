@@ -0,0 +1,170 @@
+package avatar
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gin_demo/internal/app/middleware"
+	"gin_demo/internal/domain/service"
+	"gin_demo/internal/repository"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockAvatarService 是 AvatarService 的 mock 实现（UploadAvatar 读取完文件内容后再记录调用）
+type MockAvatarService struct {
+	mock.Mock
+	uploaded string
+}
+
+func (m *MockAvatarService) UploadAvatar(ctx context.Context, userID int64, r io.Reader) (service.Avatar, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return service.Avatar{}, err
+	}
+	m.uploaded = string(data)
+	args := m.Called(ctx, userID)
+	return args.Get(0).(service.Avatar), args.Error(1)
+}
+
+func (m *MockAvatarService) DeleteAvatar(ctx context.Context, userID int64) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+func (m *MockAvatarService) RemoveAvatarFiles(ctx context.Context, user repository.User) {
+	m.Called(ctx, user)
+}
+
+// setupTestRouter 设置测试路由（模拟认证中间件设置当前用户，请求体限制为 1024 字节）
+func setupTestRouter() (*gin.Engine, *MockAvatarService) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockAvatarService)
+	handler := NewHandler(mockService)
+
+	router := gin.New()
+	router.Use(middleware.BodyLimit(1024))
+	router.Use(func(c *gin.Context) {
+		c.Set(middleware.UserIDKey, int64(1))
+		c.Next()
+	})
+	router.POST("/api/v1/users/me/avatar", handler.Upload)
+	router.DELETE("/api/v1/users/me/avatar", handler.Delete)
+	return router, mockService
+}
+
+// multipartBody 构造表单请求体（fields 在文件之前）
+func multipartBody(t *testing.T, field, filename, content string, fields map[string]string) (io.Reader, string) {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	for name, value := range fields {
+		require.NoError(t, writer.WriteField(name, value))
+	}
+	part, err := writer.CreateFormFile(field, filename)
+	require.NoError(t, err)
+	_, err = io.WriteString(part, content)
+	require.NoError(t, err)
+	require.NoError(t, writer.Close())
+	return &buf, writer.FormDataContentType()
+}
+
+// TestHandler_Upload 测试上传头像
+func TestHandler_Upload(t *testing.T) {
+	t.Run("上传成功", func(t *testing.T) {
+		router, mockService := setupTestRouter()
+		mockService.On("UploadAvatar", mock.Anything, int64(1)).Return(service.Avatar{
+			URL:   "/uploads/avatars/0/1/abc/256.jpg",
+			Sizes: map[int]string{256: "/uploads/avatars/0/1/abc/256.jpg", 64: "/uploads/avatars/0/1/abc/64.jpg"},
+		}, nil)
+
+		body, contentType := multipartBody(t, "file", "me.png", "image data", map[string]string{"note": "ignored"})
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/users/me/avatar", body)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "image data", mockService.uploaded)
+		var resp struct {
+			Data AvatarResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "/uploads/avatars/0/1/abc/256.jpg", resp.Data.Avatar)
+		assert.Equal(t, "/uploads/avatars/0/1/abc/64.jpg", resp.Data.Sizes["64"])
+	})
+
+	t.Run("不是表单请求", func(t *testing.T) {
+		router, mockService := setupTestRouter()
+
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/users/me/avatar", strings.NewReader("image data"))
+		req.Header.Set("Content-Type", "image/png")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "UploadAvatar", mock.Anything, mock.Anything)
+	})
+
+	t.Run("缺少文件字段", func(t *testing.T) {
+		router, mockService := setupTestRouter()
+
+		body, contentType := multipartBody(t, "avatar", "me.png", "image data", nil)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/users/me/avatar", body)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "UploadAvatar", mock.Anything, mock.Anything)
+	})
+
+	t.Run("不支持的图片格式", func(t *testing.T) {
+		router, mockService := setupTestRouter()
+		mockService.On("UploadAvatar", mock.Anything, int64(1)).Return(service.Avatar{}, service.ErrAvatarFormat)
+
+		body, contentType := multipartBody(t, "file", "me.svg", "<svg></svg>", nil)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/users/me/avatar", body)
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("未声明长度的请求体超过限制", func(t *testing.T) {
+		router, mockService := setupTestRouter()
+
+		// io.MultiReader 隐藏了长度，请求按分块传输处理，服务读取文件时才发现超过限制
+		body, contentType := multipartBody(t, "file", "me.png", strings.Repeat("a", 2048), nil)
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/users/me/avatar", io.MultiReader(body))
+		req.Header.Set("Content-Type", contentType)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		mockService.AssertNotCalled(t, "UploadAvatar", mock.Anything, mock.Anything)
+	})
+}
+
+// TestHandler_Delete 测试清除头像
+func TestHandler_Delete(t *testing.T) {
+	router, mockService := setupTestRouter()
+	mockService.On("DeleteAvatar", mock.Anything, int64(1)).Return(nil)
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodDelete, "/api/v1/users/me/avatar", nil))
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	mockService.AssertExpectations(t)
+}
@@ -0,0 +1,35 @@
+package jwks
+
+import (
+	"net/http"
+
+	"gin_demo/pkg/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler JWKS 处理器（公开 JWT 验证公钥）
+type Handler struct {
+	keys *auth.KeySet
+}
+
+// NewHandler 创建 JWKS 处理器
+func NewHandler(keys *auth.KeySet) *Handler {
+	return &Handler{
+		keys: keys,
+	}
+}
+
+// JWKS 获取 JWT 验证公钥
+//
+// @Summary JWKS
+// @Description 返回用于验证 Access Token 的公钥集合（RFC 7517），其他服务按 Token 的 kid 头选择公钥
+// @Tags 系统监控
+// @Produce json
+// @Success 200 {object} auth.JWKS "公钥集合"
+// @Router /.well-known/jwks.json [get]
+func (h *Handler) JWKS(c *gin.Context) {
+	// 允许客户端短期缓存，密钥轮换时旧公钥会保留一段时间
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.keys.JWKS())
+}
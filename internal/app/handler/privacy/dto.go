@@ -0,0 +1,50 @@
+package privacy
+
+import (
+	"encoding/json"
+	"time"
+
+	"gin_demo/pkg/task"
+)
+
+// ========================================
+// 请求 DTO
+// ========================================
+
+// IDRequest 用户 ID 路径参数
+type IDRequest struct {
+	ID int64 `uri:"id" binding:"required,min=1"`
+}
+
+// JobIDRequest 作业 ID 路径参数
+type JobIDRequest struct {
+	ID string `uri:"id" binding:"required,hexadecimal,len=32"`
+}
+
+// ExportRequest 导出用户数据请求
+type ExportRequest struct {
+	Format string `form:"format" binding:"omitempty,oneof=json zip"` // 默认 json
+}
+
+// ErasureRequest 本人申请删除数据请求
+type ErasureRequest struct {
+	Password string `json:"password" binding:"max=128"` // 当前密码（没有密码的第三方登录账户不需要）
+}
+
+// ========================================
+// 响应 DTO
+// ========================================
+
+// JobResponse 后台作业响应
+type JobResponse struct {
+	ID          string           `json:"id"`
+	Type        string           `json:"type"` // user.export / user.erase
+	Status      task.JobStatus   `json:"status"`
+	Progress    task.JobProgress `json:"progress"`
+	Result      json.RawMessage  `json:"result,omitempty"`       // 导出作业：{"format":"json","size":1024}
+	Error       string           `json:"error,omitempty"`        // 失败原因
+	DownloadURL string           `json:"download_url,omitempty"` // 导出作业成功后的下载地址
+	CreatedAt   time.Time        `json:"created_at"`
+	StartedAt   *time.Time       `json:"started_at,omitempty"`
+	FinishedAt  *time.Time       `json:"finished_at,omitempty"`
+}
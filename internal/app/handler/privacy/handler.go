@@ -0,0 +1,234 @@
+package privacy
+
+import (
+	"log/slog"
+	"path"
+
+	"gin_demo/internal/app/middleware"
+	"gin_demo/internal/domain/service"
+	"gin_demo/internal/response"
+	"gin_demo/pkg/task"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler 个人数据导出与删除处理器
+type Handler struct {
+	privacyService service.PrivacyService
+}
+
+// NewHandler 创建个人数据导出与删除处理器
+func NewHandler(privacyService service.PrivacyService) *Handler {
+	return &Handler{
+		privacyService: privacyService,
+	}
+}
+
+// Export 导出当前用户的数据
+//
+// @Summary 导出个人数据
+// @Description 提交导出当前用户所有数据的后台作业（资料、第三方账户、API Key、两步验证状态、会话和审计事件），通过作业查询接口获取进度和下载地址；已有未完成的导出作业时返回该作业
+// @Tags 个人数据
+// @Produce json
+// @Security BearerAuth
+// @Param format query string false "导出格式（json 或 zip）" default(json)
+// @Success 202 {object} response.Response{data=JobResponse} "已提交"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未认证"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /users/me/export [get]
+func (h *Handler) Export(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Error(c, response.New(response.CodeUnauthorized, "未认证"))
+		return
+	}
+
+	var req ExportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "参数错误", err))
+		return
+	}
+
+	job, err := h.privacyService.RequestExport(c.Request.Context(), userID, req.Format)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Request export failed", "user_id", userID, "error", err)
+		response.Error(c, err)
+		return
+	}
+
+	response.Accepted(c, toJobResponse(job))
+}
+
+// RequestErasure 申请删除当前用户的数据
+//
+// @Summary 删除个人数据
+// @Description 提交匿名化当前用户的后台作业：用户名、邮箱、密码和头像被清除，第三方账户关联、API Key、两步验证和会话被删除，用户 ID 保留（审计日志等引用不受影响）。操作不可撤销，需要确认当前密码
+// @Tags 个人数据
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body ErasureRequest true "当前密码"
+// @Success 202 {object} response.Response{data=JobResponse} "已提交"
+// @Failure 400 {object} response.Response "参数错误或密码错误"
+// @Failure 401 {object} response.Response "未认证"
+// @Failure 403 {object} response.Response "模拟登录期间禁止"
+// @Failure 409 {object} response.Response "用户已匿名"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /users/me/erasure [post]
+func (h *Handler) RequestErasure(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Error(c, response.New(response.CodeUnauthorized, "未认证"))
+		return
+	}
+
+	var req ErasureRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "参数错误", err))
+		return
+	}
+
+	job, err := h.privacyService.RequestErasure(c.Request.Context(), service.ErasureInput{
+		UserID:   userID,
+		ActorID:  userID,
+		Password: req.Password,
+	})
+	if err != nil {
+		slog.WarnContext(c.Request.Context(), "Request erasure failed", "user_id", userID, "error", err)
+		response.Error(c, err)
+		return
+	}
+
+	response.Accepted(c, toJobResponse(job))
+}
+
+// EraseUser 删除指定用户的数据
+//
+// @Summary 删除用户数据
+// @Description 超级管理员提交匿名化指定用户的后台作业（处理用户的删除请求），作业归属于管理员，通过 /users/me/jobs/{id} 查询
+// @Tags 用户管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Success 202 {object} response.Response{data=JobResponse} "已提交"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未认证"
+// @Failure 403 {object} response.Response "需要超级管理员，不能删除自己和其他超级管理员"
+// @Failure 404 {object} response.Response "用户不存在"
+// @Failure 409 {object} response.Response "用户已匿名"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /users/{id}/erasure [post]
+func (h *Handler) EraseUser(c *gin.Context) {
+	var req IDRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "无效的用户ID", err))
+		return
+	}
+
+	actorID := middleware.GetUserID(c)
+	job, err := h.privacyService.RequestErasure(c.Request.Context(), service.ErasureInput{
+		UserID:  req.ID,
+		ActorID: actorID,
+	})
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Request erasure failed", "user_id", req.ID, "actor_id", actorID, "error", err)
+		response.Error(c, err)
+		return
+	}
+
+	response.Accepted(c, toJobResponse(job))
+}
+
+// GetJob 查询作业状态
+//
+// @Summary 查询作业状态
+// @Description 查询当前用户提交的导出或删除作业（作业记录保留 24 小时）
+// @Tags 个人数据
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "作业ID"
+// @Success 200 {object} response.Response{data=JobResponse} "获取成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未认证"
+// @Failure 404 {object} response.Response "作业不存在"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /users/me/jobs/{id} [get]
+func (h *Handler) GetJob(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Error(c, response.New(response.CodeUnauthorized, "未认证"))
+		return
+	}
+
+	var req JobIDRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "无效的作业ID", err))
+		return
+	}
+
+	job, err := h.privacyService.GetJob(c.Request.Context(), userID, req.ID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	resp := toJobResponse(job)
+	if job.Type == service.JobTypeUserExport && job.Status == task.JobSucceeded {
+		resp.DownloadURL = path.Join(c.Request.URL.Path, "download")
+	}
+	response.Success(c, resp)
+}
+
+// Download 下载导出文件
+//
+// @Summary 下载导出文件
+// @Description 下载已完成的导出作业生成的文件
+// @Tags 个人数据
+// @Produce application/json,application/zip
+// @Security BearerAuth
+// @Param id path string true "作业ID"
+// @Success 200 {file} file "导出文件"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未认证"
+// @Failure 404 {object} response.Response "作业不存在或文件已过期"
+// @Failure 409 {object} response.Response "导出尚未完成"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /users/me/jobs/{id}/download [get]
+func (h *Handler) Download(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Error(c, response.New(response.CodeUnauthorized, "未认证"))
+		return
+	}
+
+	var req JobIDRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "无效的作业ID", err))
+		return
+	}
+
+	file, err := h.privacyService.GetExportFile(c.Request.Context(), userID, req.ID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.FileAttachment(file.Path, file.Name)
+}
+
+// toJobResponse 转换作业响应
+func toJobResponse(job task.Job) JobResponse {
+	return JobResponse{
+		ID:         job.ID,
+		Type:       job.Type,
+		Status:     job.Status,
+		Progress:   job.Progress,
+		Result:     job.Result,
+		Error:      job.Error,
+		CreatedAt:  job.CreatedAt,
+		StartedAt:  job.StartedAt,
+		FinishedAt: job.FinishedAt,
+	}
+}
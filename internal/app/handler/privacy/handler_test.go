@@ -0,0 +1,204 @@
+package privacy
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gin_demo/internal/app/middleware"
+	"gin_demo/internal/domain/service"
+	"gin_demo/pkg/task"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testJobID = "0123456789abcdef0123456789abcdef"
+
+// MockPrivacyService 是 PrivacyService 的 mock 实现
+type MockPrivacyService struct {
+	mock.Mock
+}
+
+func (m *MockPrivacyService) RequestExport(ctx context.Context, userID int64, format string) (task.Job, error) {
+	args := m.Called(ctx, userID, format)
+	return args.Get(0).(task.Job), args.Error(1)
+}
+
+func (m *MockPrivacyService) RequestErasure(ctx context.Context, input service.ErasureInput) (task.Job, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(task.Job), args.Error(1)
+}
+
+func (m *MockPrivacyService) GetJob(ctx context.Context, userID int64, jobID string) (task.Job, error) {
+	args := m.Called(ctx, userID, jobID)
+	return args.Get(0).(task.Job), args.Error(1)
+}
+
+func (m *MockPrivacyService) GetExportFile(ctx context.Context, userID int64, jobID string) (service.ExportFile, error) {
+	args := m.Called(ctx, userID, jobID)
+	return args.Get(0).(service.ExportFile), args.Error(1)
+}
+
+// setupTestRouter 设置测试路由（模拟认证中间件设置当前用户）
+func setupTestRouter() (*gin.Engine, *MockPrivacyService) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockPrivacyService)
+	handler := NewHandler(mockService)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(middleware.UserIDKey, int64(1))
+		c.Next()
+	})
+	router.GET("/api/v1/users/me/export", handler.Export)
+	router.POST("/api/v1/users/me/erasure", handler.RequestErasure)
+	router.GET("/api/v1/users/me/jobs/:id", handler.GetJob)
+	router.GET("/api/v1/users/me/jobs/:id/download", handler.Download)
+	router.POST("/api/v1/users/:id/erasure", handler.EraseUser)
+	return router, mockService
+}
+
+// decodeJob 解析响应中的作业
+func decodeJob(t *testing.T, w *httptest.ResponseRecorder) JobResponse {
+	t.Helper()
+
+	var resp struct {
+		Data JobResponse `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+	return resp.Data
+}
+
+// TestHandler_Export 测试提交导出作业
+func TestHandler_Export(t *testing.T) {
+	t.Run("返回 202", func(t *testing.T) {
+		router, mockService := setupTestRouter()
+		mockService.On("RequestExport", mock.Anything, int64(1), "zip").
+			Return(task.Job{ID: testJobID, Type: service.JobTypeUserExport, Status: task.JobPending}, nil)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/users/me/export?format=zip", nil))
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		job := decodeJob(t, w)
+		assert.Equal(t, testJobID, job.ID)
+		assert.Equal(t, task.JobPending, job.Status)
+		assert.Empty(t, job.DownloadURL)
+	})
+
+	t.Run("不支持的格式", func(t *testing.T) {
+		router, mockService := setupTestRouter()
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/users/me/export?format=xml", nil))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "RequestExport", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// TestHandler_Erasure 测试提交删除作业
+func TestHandler_Erasure(t *testing.T) {
+	t.Run("本人申请", func(t *testing.T) {
+		router, mockService := setupTestRouter()
+		mockService.On("RequestErasure", mock.Anything, service.ErasureInput{UserID: 1, ActorID: 1, Password: "secret"}).
+			Return(task.Job{ID: testJobID, Type: service.JobTypeUserErase, Status: task.JobPending}, nil)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/users/me/erasure", strings.NewReader(`{"password":"secret"}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("密码错误", func(t *testing.T) {
+		router, mockService := setupTestRouter()
+		mockService.On("RequestErasure", mock.Anything, mock.Anything).Return(task.Job{}, service.ErrInvalidPassword)
+
+		w := httptest.NewRecorder()
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/users/me/erasure", strings.NewReader(`{"password":"wrong"}`))
+		req.Header.Set("Content-Type", "application/json")
+		router.ServeHTTP(w, req)
+
+		assert.NotEqual(t, http.StatusAccepted, w.Code)
+	})
+
+	t.Run("管理员申请", func(t *testing.T) {
+		router, mockService := setupTestRouter()
+		mockService.On("RequestErasure", mock.Anything, service.ErasureInput{UserID: 5, ActorID: 1}).
+			Return(task.Job{ID: testJobID, Type: service.JobTypeUserErase, Status: task.JobPending}, nil)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/users/5/erasure", nil))
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
+
+// TestHandler_GetJob 测试查询作业和下载导出文件
+func TestHandler_GetJob(t *testing.T) {
+	t.Run("导出完成后返回下载地址", func(t *testing.T) {
+		router, mockService := setupTestRouter()
+		mockService.On("GetJob", mock.Anything, int64(1), testJobID).Return(task.Job{
+			ID:       testJobID,
+			Type:     service.JobTypeUserExport,
+			Status:   task.JobSucceeded,
+			Progress: task.JobProgress{Done: 6, Total: 6},
+			Result:   json.RawMessage(`{"format":"json","size":42}`),
+		}, nil)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/users/me/jobs/"+testJobID, nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		job := decodeJob(t, w)
+		assert.Equal(t, "/api/v1/users/me/jobs/"+testJobID+"/download", job.DownloadURL)
+		assert.JSONEq(t, `{"format":"json","size":42}`, string(job.Result))
+	})
+
+	t.Run("作业不存在", func(t *testing.T) {
+		router, mockService := setupTestRouter()
+		mockService.On("GetJob", mock.Anything, int64(1), testJobID).Return(task.Job{}, service.ErrJobNotFound)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/users/me/jobs/"+testJobID, nil))
+
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("无效的作业ID", func(t *testing.T) {
+		router, _ := setupTestRouter()
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/users/me/jobs/../etc", nil))
+
+		assert.NotEqual(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("下载", func(t *testing.T) {
+		router, mockService := setupTestRouter()
+		path := filepath.Join(t.TempDir(), "export.json")
+		require.NoError(t, os.WriteFile(path, []byte(`{"profile":{}}`), 0o600))
+		mockService.On("GetExportFile", mock.Anything, int64(1), testJobID).
+			Return(service.ExportFile{Path: path, Name: "user-1-export-20260101.json"}, nil)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/users/me/jobs/"+testJobID+"/download", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "user-1-export-20260101.json")
+		assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+		assert.Equal(t, `{"profile":{}}`, w.Body.String())
+	})
+}
@@ -0,0 +1,56 @@
+package role
+
+import "time"
+
+// ========================================
+// 请求 DTO
+// ========================================
+
+// NameRequest 角色或权限名称路径参数
+type NameRequest struct {
+	Name string `uri:"name" binding:"required,max=64"`
+}
+
+// CreateRoleRequest 创建角色请求
+type CreateRoleRequest struct {
+	Name        string   `json:"name" binding:"required,min=2,max=32"` // 小写字母、数字和下划线，以字母开头
+	Description string   `json:"description" binding:"max=255"`
+	Level       int      `json:"level" binding:"min=0,max=99"`                       // 角色级别（super_admin 为 100）
+	Permissions []string `json:"permissions" binding:"max=100,dive,required,max=64"` // 权限名称（如 user:read）
+}
+
+// UpdateRoleRequest 更新角色请求（权限整体替换）
+type UpdateRoleRequest struct {
+	Description string   `json:"description" binding:"max=255"`
+	Level       int      `json:"level" binding:"min=0,max=99"`
+	Permissions []string `json:"permissions" binding:"max=100,dive,required,max=64"`
+}
+
+// CreatePermissionRequest 创建权限请求
+type CreatePermissionRequest struct {
+	Name        string `json:"name" binding:"required,max=64"` // 资源:操作（如 report:export）
+	Description string `json:"description" binding:"max=255"`
+}
+
+// ========================================
+// 响应 DTO
+// ========================================
+
+// RoleResponse 角色响应
+type RoleResponse struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	Level       int       `json:"level"`
+	System      bool      `json:"system"`      // 内置角色（不能删除）
+	Permissions []string  `json:"permissions"` // super_admin 隐式拥有所有权限，列表为空
+	CreatedAt   time.Time `json:"created_at"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+// PermissionResponse 权限响应
+type PermissionResponse struct {
+	Name        string    `json:"name"`
+	Description string    `json:"description"`
+	System      bool      `json:"system"` // 内置权限（代码中使用，不能删除）
+	CreatedAt   time.Time `json:"created_at"`
+}
@@ -0,0 +1,318 @@
+package role
+
+import (
+	"log/slog"
+
+	"gin_demo/internal/domain/service"
+	"gin_demo/internal/response"
+	"gin_demo/pkg/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler 角色权限管理处理器（仅超级管理员）
+type Handler struct {
+	roleService service.RoleService
+}
+
+// NewHandler 创建角色权限管理处理器
+func NewHandler(roleService service.RoleService) *Handler {
+	return &Handler{
+		roleService: roleService,
+	}
+}
+
+// ListRoles 角色列表
+//
+// @Summary 角色列表
+// @Description 列出所有角色及其权限（按级别从高到低）
+// @Tags 角色管理
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]RoleResponse} "获取成功"
+// @Failure 401 {object} response.Response "未认证"
+// @Failure 403 {object} response.Response "需要超级管理员"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /admin/roles [get]
+func (h *Handler) ListRoles(c *gin.Context) {
+	roles, err := h.roleService.ListRoles(c.Request.Context())
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "List roles failed", "error", err)
+		response.Error(c, err)
+		return
+	}
+
+	result := make([]RoleResponse, len(roles))
+	for i, role := range roles {
+		result[i] = toRoleResponse(role)
+	}
+	response.Success(c, result)
+}
+
+// GetRole 获取角色
+//
+// @Summary 获取角色
+// @Tags 角色管理
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "角色名称"
+// @Success 200 {object} response.Response{data=RoleResponse} "获取成功"
+// @Failure 401 {object} response.Response "未认证"
+// @Failure 403 {object} response.Response "需要超级管理员"
+// @Failure 404 {object} response.Response "角色不存在"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /admin/roles/{name} [get]
+func (h *Handler) GetRole(c *gin.Context) {
+	var uri NameRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "参数错误", err))
+		return
+	}
+
+	role, err := h.roleService.GetRole(c.Request.Context(), auth.Role(uri.Name))
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, toRoleResponse(role))
+}
+
+// CreateRole 创建角色
+//
+// @Summary 创建角色
+// @Description 创建自定义角色，立即生效（其他实例在 security.rbac.policy_reload_interval 内生效）
+// @Tags 角色管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreateRoleRequest true "角色信息"
+// @Success 200 {object} response.Response{data=RoleResponse} "创建成功"
+// @Failure 400 {object} response.Response "参数错误或权限不存在"
+// @Failure 401 {object} response.Response "未认证"
+// @Failure 403 {object} response.Response "需要超级管理员"
+// @Failure 409 {object} response.Response "角色已存在"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /admin/roles [post]
+func (h *Handler) CreateRole(c *gin.Context) {
+	var req CreateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "参数错误", err))
+		return
+	}
+
+	role, err := h.roleService.CreateRole(c.Request.Context(), service.RoleInput{
+		Name:        auth.Role(req.Name),
+		Description: req.Description,
+		Level:       req.Level,
+		Permissions: toPermissions(req.Permissions),
+	})
+	if err != nil {
+		slog.WarnContext(c.Request.Context(), "Create role failed", "role", req.Name, "error", err)
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, toRoleResponse(role))
+}
+
+// UpdateRole 更新角色
+//
+// @Summary 更新角色
+// @Description 更新角色的描述、级别和权限（权限整体替换），立即生效；超级管理员角色不能修改
+// @Tags 角色管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "角色名称"
+// @Param request body UpdateRoleRequest true "角色信息"
+// @Success 200 {object} response.Response{data=RoleResponse} "更新成功"
+// @Failure 400 {object} response.Response "参数错误或权限不存在"
+// @Failure 401 {object} response.Response "未认证"
+// @Failure 403 {object} response.Response "需要超级管理员，或修改超级管理员角色"
+// @Failure 404 {object} response.Response "角色不存在"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /admin/roles/{name} [put]
+func (h *Handler) UpdateRole(c *gin.Context) {
+	var uri NameRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "参数错误", err))
+		return
+	}
+
+	var req UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "参数错误", err))
+		return
+	}
+
+	role, err := h.roleService.UpdateRole(c.Request.Context(), auth.Role(uri.Name), service.RoleInput{
+		Description: req.Description,
+		Level:       req.Level,
+		Permissions: toPermissions(req.Permissions),
+	})
+	if err != nil {
+		slog.WarnContext(c.Request.Context(), "Update role failed", "role", uri.Name, "error", err)
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, toRoleResponse(role))
+}
+
+// DeleteRole 删除角色
+//
+// @Summary 删除角色
+// @Description 删除自定义角色；内置角色不能删除，仍有用户使用的角色需要先修改这些用户的角色
+// @Tags 角色管理
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "角色名称"
+// @Success 200 {object} response.Response "删除成功"
+// @Failure 401 {object} response.Response "未认证"
+// @Failure 403 {object} response.Response "需要超级管理员，或删除内置角色"
+// @Failure 404 {object} response.Response "角色不存在"
+// @Failure 409 {object} response.Response "仍有用户使用该角色"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /admin/roles/{name} [delete]
+func (h *Handler) DeleteRole(c *gin.Context) {
+	var uri NameRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "参数错误", err))
+		return
+	}
+
+	if err := h.roleService.DeleteRole(c.Request.Context(), auth.Role(uri.Name)); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// ListPermissions 权限列表
+//
+// @Summary 权限列表
+// @Tags 角色管理
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]PermissionResponse} "获取成功"
+// @Failure 401 {object} response.Response "未认证"
+// @Failure 403 {object} response.Response "需要超级管理员"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /admin/permissions [get]
+func (h *Handler) ListPermissions(c *gin.Context) {
+	permissions, err := h.roleService.ListPermissions(c.Request.Context())
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "List permissions failed", "error", err)
+		response.Error(c, err)
+		return
+	}
+
+	result := make([]PermissionResponse, len(permissions))
+	for i, p := range permissions {
+		result[i] = toPermissionResponse(p)
+	}
+	response.Success(c, result)
+}
+
+// CreatePermission 创建权限
+//
+// @Summary 创建权限
+// @Description 创建自定义权限（供 ABAC 策略或新功能使用），之后可以分配给角色
+// @Tags 角色管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body CreatePermissionRequest true "权限信息"
+// @Success 200 {object} response.Response{data=PermissionResponse} "创建成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未认证"
+// @Failure 403 {object} response.Response "需要超级管理员"
+// @Failure 409 {object} response.Response "权限已存在"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /admin/permissions [post]
+func (h *Handler) CreatePermission(c *gin.Context) {
+	var req CreatePermissionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "参数错误", err))
+		return
+	}
+
+	permission, err := h.roleService.CreatePermission(c.Request.Context(), service.PermissionInput{
+		Name:        auth.Permission(req.Name),
+		Description: req.Description,
+	})
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, toPermissionResponse(permission))
+}
+
+// DeletePermission 删除权限
+//
+// @Summary 删除权限
+// @Description 删除自定义权限并从所有角色中移除；内置权限不能删除
+// @Tags 角色管理
+// @Produce json
+// @Security BearerAuth
+// @Param name path string true "权限名称"
+// @Success 200 {object} response.Response "删除成功"
+// @Failure 401 {object} response.Response "未认证"
+// @Failure 403 {object} response.Response "需要超级管理员，或删除内置权限"
+// @Failure 404 {object} response.Response "权限不存在"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /admin/permissions/{name} [delete]
+func (h *Handler) DeletePermission(c *gin.Context) {
+	var uri NameRequest
+	if err := c.ShouldBindUri(&uri); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "参数错误", err))
+		return
+	}
+
+	if err := h.roleService.DeletePermission(c.Request.Context(), auth.Permission(uri.Name)); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// toPermissions 字符串转换为权限
+func toPermissions(values []string) []auth.Permission {
+	permissions := make([]auth.Permission, len(values))
+	for i, v := range values {
+		permissions[i] = auth.Permission(v)
+	}
+	return permissions
+}
+
+// toRoleResponse 转换为角色响应 DTO
+func toRoleResponse(role service.RoleInfo) RoleResponse {
+	permissions := make([]string, len(role.Permissions))
+	for i, p := range role.Permissions {
+		permissions[i] = string(p)
+	}
+
+	return RoleResponse{
+		Name:        string(role.Name),
+		Description: role.Description,
+		Level:       role.Level,
+		System:      role.System,
+		Permissions: permissions,
+		CreatedAt:   role.CreatedAt,
+		UpdatedAt:   role.UpdatedAt,
+	}
+}
+
+// toPermissionResponse 转换为权限响应 DTO
+func toPermissionResponse(p service.PermissionInfo) PermissionResponse {
+	return PermissionResponse{
+		Name:        string(p.Name),
+		Description: p.Description,
+		System:      p.System,
+		CreatedAt:   p.CreatedAt,
+	}
+}
@@ -0,0 +1,183 @@
+package role
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gin_demo/internal/app/middleware"
+	"gin_demo/internal/domain/service"
+	"gin_demo/pkg/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockRoleService 是 RoleService 的 mock 实现
+type MockRoleService struct {
+	mock.Mock
+}
+
+func (m *MockRoleService) ListRoles(ctx context.Context) ([]service.RoleInfo, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]service.RoleInfo), args.Error(1)
+}
+
+func (m *MockRoleService) GetRole(ctx context.Context, name auth.Role) (service.RoleInfo, error) {
+	args := m.Called(ctx, name)
+	return args.Get(0).(service.RoleInfo), args.Error(1)
+}
+
+func (m *MockRoleService) CreateRole(ctx context.Context, input service.RoleInput) (service.RoleInfo, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(service.RoleInfo), args.Error(1)
+}
+
+func (m *MockRoleService) UpdateRole(ctx context.Context, name auth.Role, input service.RoleInput) (service.RoleInfo, error) {
+	args := m.Called(ctx, name, input)
+	return args.Get(0).(service.RoleInfo), args.Error(1)
+}
+
+func (m *MockRoleService) DeleteRole(ctx context.Context, name auth.Role) error {
+	args := m.Called(ctx, name)
+	return args.Error(0)
+}
+
+func (m *MockRoleService) ListPermissions(ctx context.Context) ([]service.PermissionInfo, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]service.PermissionInfo), args.Error(1)
+}
+
+func (m *MockRoleService) CreatePermission(ctx context.Context, input service.PermissionInput) (service.PermissionInfo, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(service.PermissionInfo), args.Error(1)
+}
+
+func (m *MockRoleService) DeletePermission(ctx context.Context, name auth.Permission) error {
+	args := m.Called(ctx, name)
+	return args.Error(0)
+}
+
+func (m *MockRoleService) LoadPolicy(ctx context.Context) (*auth.Policy, error) {
+	args := m.Called(ctx)
+	policy, _ := args.Get(0).(*auth.Policy)
+	return policy, args.Error(1)
+}
+
+// setupTestRouter 设置测试路由（模拟 RBAC 认证中间件设置当前用户的角色）
+func setupTestRouter(role auth.Role) (*gin.Engine, *MockRoleService) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockRoleService)
+	handler := NewHandler(mockService)
+
+	router := gin.New()
+	router.Use(func(c *gin.Context) {
+		c.Set(middleware.UserIDKey, int64(1))
+		c.Set(middleware.RBACClaimsKey, &auth.RBACClaims{UserID: 1, Role: role})
+		c.Next()
+	})
+	router.Use(middleware.RequireSuperAdmin())
+	router.GET("/admin/roles", handler.ListRoles)
+	router.POST("/admin/roles", handler.CreateRole)
+	router.PUT("/admin/roles/:name", handler.UpdateRole)
+	router.DELETE("/admin/roles/:name", handler.DeleteRole)
+	return router, mockService
+}
+
+// doJSON 发送 JSON 请求
+func doJSON(router *gin.Engine, method, path string, body interface{}) *httptest.ResponseRecorder {
+	var data []byte
+	if body != nil {
+		data, _ = json.Marshal(body)
+	}
+	w := httptest.NewRecorder()
+	req := httptest.NewRequest(method, path, bytes.NewReader(data))
+	req.Header.Set("Content-Type", "application/json")
+	router.ServeHTTP(w, req)
+	return w
+}
+
+// TestHandler_CreateRole 测试创建角色
+func TestHandler_CreateRole(t *testing.T) {
+	t.Run("创建成功", func(t *testing.T) {
+		router, mockService := setupTestRouter(auth.RoleSuperAdmin)
+		input := service.RoleInput{
+			Name:        "auditor",
+			Description: "审计",
+			Level:       50,
+			Permissions: []auth.Permission{"report:export"},
+		}
+		mockService.On("CreateRole", mock.Anything, input).Return(service.RoleInfo{
+			Name:        "auditor",
+			Description: "审计",
+			Level:       50,
+			Permissions: []auth.Permission{"report:export"},
+		}, nil)
+
+		w := doJSON(router, http.MethodPost, "/admin/roles", CreateRoleRequest{
+			Name:        "auditor",
+			Description: "审计",
+			Level:       50,
+			Permissions: []string{"report:export"},
+		})
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp struct {
+			Data RoleResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "auditor", resp.Data.Name)
+		assert.Equal(t, []string{"report:export"}, resp.Data.Permissions)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("级别超出范围", func(t *testing.T) {
+		router, mockService := setupTestRouter(auth.RoleSuperAdmin)
+
+		w := doJSON(router, http.MethodPost, "/admin/roles", CreateRoleRequest{Name: "auditor", Level: 100})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "CreateRole", mock.Anything, mock.Anything)
+	})
+
+	t.Run("管理员不能管理角色", func(t *testing.T) {
+		router, mockService := setupTestRouter(auth.RoleAdmin)
+
+		w := doJSON(router, http.MethodPost, "/admin/roles", CreateRoleRequest{Name: "auditor", Level: 50})
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		mockService.AssertNotCalled(t, "CreateRole", mock.Anything, mock.Anything)
+	})
+}
+
+// TestHandler_UpdateDeleteRole 测试更新和删除角色
+func TestHandler_UpdateDeleteRole(t *testing.T) {
+	t.Run("修改超级管理员角色", func(t *testing.T) {
+		router, mockService := setupTestRouter(auth.RoleSuperAdmin)
+		mockService.On("UpdateRole", mock.Anything, auth.RoleSuperAdmin, mock.Anything).
+			Return(service.RoleInfo{}, service.ErrSystemRole)
+
+		w := doJSON(router, http.MethodPut, "/admin/roles/super_admin", UpdateRoleRequest{Level: 10})
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("删除仍在使用的角色", func(t *testing.T) {
+		router, mockService := setupTestRouter(auth.RoleSuperAdmin)
+		mockService.On("DeleteRole", mock.Anything, auth.Role("auditor")).Return(service.ErrRoleInUse)
+
+		w := doJSON(router, http.MethodDelete, "/admin/roles/auditor", nil)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("删除成功", func(t *testing.T) {
+		router, mockService := setupTestRouter(auth.RoleSuperAdmin)
+		mockService.On("DeleteRole", mock.Anything, auth.Role("auditor")).Return(nil)
+
+		w := doJSON(router, http.MethodDelete, "/admin/roles/auditor", nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+}
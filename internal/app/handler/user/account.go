@@ -0,0 +1,121 @@
+package user
+
+import (
+	"log/slog"
+
+	"gin_demo/internal/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// VerifyEmail 验证邮箱
+//
+// @Summary 验证邮箱
+// @Description 使用验证邮件中的令牌完成邮箱验证，令牌只能使用一次
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Param request body TokenRequest true "邮件中的令牌"
+// @Success 200 {object} response.Response "验证成功"
+// @Failure 400 {object} response.Response "链接无效或已过期"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /users/verify-email [post]
+func (h *Handler) VerifyEmail(c *gin.Context) {
+	var req TokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "参数错误", err))
+		return
+	}
+
+	if err := h.accountService.VerifyEmail(c.Request.Context(), req.Token); err != nil {
+		slog.WarnContext(c.Request.Context(), "Verify email failed", "error", err)
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// ResendVerification 重新发送验证邮件
+//
+// @Summary 重新发送验证邮件
+// @Description 向未验证的邮箱重新发送验证邮件，之前的链接作废；无论邮箱是否存在都返回成功
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Param request body EmailRequest true "注册邮箱"
+// @Success 200 {object} response.Response "已受理"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /users/verify-email/resend [post]
+func (h *Handler) ResendVerification(c *gin.Context) {
+	var req EmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "参数错误", err))
+		return
+	}
+
+	if err := h.accountService.ResendVerificationEmail(c.Request.Context(), req.Email); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Resend verification email failed", "email", req.Email, "error", err)
+		response.Error(c, response.NewWithError(response.CodeInternalError, "发送邮件失败，请稍后重试", err))
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// ForgotPassword 忘记密码
+//
+// @Summary 忘记密码
+// @Description 发送密码重置邮件；无论邮箱是否存在都返回成功，避免探测账户
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Param request body EmailRequest true "注册邮箱"
+// @Success 200 {object} response.Response "已受理"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /users/password/forgot [post]
+func (h *Handler) ForgotPassword(c *gin.Context) {
+	var req EmailRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "参数错误", err))
+		return
+	}
+
+	if err := h.accountService.RequestPasswordReset(c.Request.Context(), req.Email); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Request password reset failed", "email", req.Email, "error", err)
+		response.Error(c, response.NewWithError(response.CodeInternalError, "发送邮件失败，请稍后重试", err))
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// ResetPassword 重置密码
+//
+// @Summary 重置密码
+// @Description 使用重置邮件中的令牌设置新密码，令牌只能使用一次；成功后所有设备上的登录状态失效
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Param request body ResetPasswordRequest true "令牌和新密码"
+// @Success 200 {object} response.Response "重置成功"
+// @Failure 400 {object} response.Response "参数错误或链接无效、已过期"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /users/password/reset [post]
+func (h *Handler) ResetPassword(c *gin.Context) {
+	var req ResetPasswordRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "参数错误", err))
+		return
+	}
+
+	if err := h.accountService.ResetPassword(c.Request.Context(), req.Token, req.NewPassword); err != nil {
+		slog.WarnContext(c.Request.Context(), "Reset password failed", "error", err)
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
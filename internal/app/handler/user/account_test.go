@@ -0,0 +1,213 @@
+package user
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gin_demo/internal/domain/service"
+	"gin_demo/internal/repository"
+	"gin_demo/internal/response"
+	"gin_demo/pkg/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockAccountService 是 AccountService 的 mock 实现
+type MockAccountService struct {
+	mock.Mock
+}
+
+func (m *MockAccountService) SendVerificationEmail(ctx context.Context, user repository.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *MockAccountService) ResendVerificationEmail(ctx context.Context, email string) error {
+	args := m.Called(ctx, email)
+	return args.Error(0)
+}
+
+func (m *MockAccountService) VerifyEmail(ctx context.Context, token string) error {
+	args := m.Called(ctx, token)
+	return args.Error(0)
+}
+
+func (m *MockAccountService) RequestPasswordReset(ctx context.Context, email string) error {
+	args := m.Called(ctx, email)
+	return args.Error(0)
+}
+
+func (m *MockAccountService) SendInvitation(ctx context.Context, user repository.User) error {
+	args := m.Called(ctx, user)
+	return args.Error(0)
+}
+
+func (m *MockAccountService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	args := m.Called(ctx, token, newPassword)
+	return args.Error(0)
+}
+
+// setupAccountTestHandler 设置测试 Handler（邮箱验证与密码重置服务由调用方 mock）
+func setupAccountTestHandler() (*Handler, *MockUserService, *MockAccountService) {
+	handler, mockService, _ := setupTestHandler()
+	mockAccount := new(MockAccountService)
+	handler.accountService = mockAccount
+	return handler, mockService, mockAccount
+}
+
+// postJSON 以 JSON 请求体调用 Handler
+func postJSON(handlerFunc gin.HandlerFunc, path string, body interface{}) *httptest.ResponseRecorder {
+	data, _ := json.Marshal(body)
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(http.MethodPost, path, bytes.NewBuffer(data))
+	c.Request.Header.Set("Content-Type", "application/json")
+	handlerFunc(c)
+	return w
+}
+
+// TestHandler_Register_SendsVerificationEmail 测试注册后发送验证邮件
+func TestHandler_Register_SendsVerificationEmail(t *testing.T) {
+	user := repository.User{ID: 1, Username: "testuser", Email: "test@example.com", Status: repository.UserStatusPending}
+	req := RegisterRequest{Username: "testuser", Email: "test@example.com", Password: "password123"}
+
+	t.Run("注册成功后发送验证邮件", func(t *testing.T) {
+		handler, mockService, mockAccount := setupAccountTestHandler()
+		mockService.On("Register", mock.Anything, mock.AnythingOfType("service.RegisterInput")).Return(user, nil)
+		mockAccount.On("SendVerificationEmail", mock.Anything, user).Return(nil)
+
+		w := postJSON(handler.Register, "/users/register", req)
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockAccount.AssertExpectations(t)
+	})
+
+	t.Run("邮件发送失败不影响注册", func(t *testing.T) {
+		handler, mockService, mockAccount := setupAccountTestHandler()
+		mockService.On("Register", mock.Anything, mock.AnythingOfType("service.RegisterInput")).Return(user, nil)
+		mockAccount.On("SendVerificationEmail", mock.Anything, user).Return(errors.New("smtp down"))
+
+		w := postJSON(handler.Register, "/users/register", req)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+}
+
+// TestHandler_Login_EmailNotVerified 测试未验证邮箱的用户登录
+func TestHandler_Login_EmailNotVerified(t *testing.T) {
+	handler, mockService, _ := setupTestHandler()
+	mockService.On("Login", mock.Anything, mock.AnythingOfType("service.LoginInput")).
+		Return(repository.User{}, service.ErrEmailNotVerified)
+
+	w := postJSON(handler.Login, "/users/login", LoginRequest{Email: "test@example.com", Password: "password123"})
+	assert.Equal(t, http.StatusForbidden, w.Code)
+}
+
+// TestHandler_VerifyEmail 测试验证邮箱
+func TestHandler_VerifyEmail(t *testing.T) {
+	t.Run("验证成功", func(t *testing.T) {
+		handler, _, mockAccount := setupAccountTestHandler()
+		mockAccount.On("VerifyEmail", mock.Anything, "good-token").Return(nil)
+
+		w := postJSON(handler.VerifyEmail, "/users/verify-email", TokenRequest{Token: "good-token"})
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockAccount.AssertExpectations(t)
+	})
+
+	t.Run("令牌无效", func(t *testing.T) {
+		handler, _, mockAccount := setupAccountTestHandler()
+		mockAccount.On("VerifyEmail", mock.Anything, "bad-token").Return(service.ErrInvalidAccountToken)
+
+		w := postJSON(handler.VerifyEmail, "/users/verify-email", TokenRequest{Token: "bad-token"})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("缺少令牌", func(t *testing.T) {
+		handler, _, mockAccount := setupAccountTestHandler()
+
+		w := postJSON(handler.VerifyEmail, "/users/verify-email", TokenRequest{})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockAccount.AssertNotCalled(t, "VerifyEmail", mock.Anything, mock.Anything)
+	})
+}
+
+// TestHandler_ForgotPassword 测试忘记密码
+func TestHandler_ForgotPassword(t *testing.T) {
+	t.Run("已受理", func(t *testing.T) {
+		handler, _, mockAccount := setupAccountTestHandler()
+		mockAccount.On("RequestPasswordReset", mock.Anything, "test@example.com").Return(nil)
+
+		w := postJSON(handler.ForgotPassword, "/users/password/forgot", EmailRequest{Email: "test@example.com"})
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockAccount.AssertExpectations(t)
+	})
+
+	t.Run("邮箱格式错误", func(t *testing.T) {
+		handler, _, _ := setupAccountTestHandler()
+
+		w := postJSON(handler.ForgotPassword, "/users/password/forgot", EmailRequest{Email: "not-an-email"})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("邮件发送失败", func(t *testing.T) {
+		handler, _, mockAccount := setupAccountTestHandler()
+		mockAccount.On("RequestPasswordReset", mock.Anything, "test@example.com").Return(errors.New("smtp down"))
+
+		w := postJSON(handler.ForgotPassword, "/users/password/forgot", EmailRequest{Email: "test@example.com"})
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+	})
+}
+
+// TestHandler_ResetPassword 测试重置密码
+func TestHandler_ResetPassword(t *testing.T) {
+	t.Run("重置成功", func(t *testing.T) {
+		handler, _, mockAccount := setupAccountTestHandler()
+		mockAccount.On("ResetPassword", mock.Anything, "good-token", "newpassword").Return(nil)
+
+		w := postJSON(handler.ResetPassword, "/users/password/reset", ResetPasswordRequest{Token: "good-token", NewPassword: "newpassword"})
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockAccount.AssertExpectations(t)
+	})
+
+	t.Run("新密码不符合策略", func(t *testing.T) {
+		handler, _, mockAccount := setupAccountTestHandler()
+		mockAccount.On("ResetPassword", mock.Anything, "good-token", "123").Return(service.ErrPasswordPolicy.WithFields(
+			response.FieldError{Field: "new_password", Code: auth.PasswordTooShort, Message: "密码长度不能少于 8 个字符"},
+		))
+
+		w := postJSON(handler.ResetPassword, "/users/password/reset", ResetPasswordRequest{Token: "good-token", NewPassword: "123"})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var body struct {
+			Code   int                   `json:"code"`
+			Fields []response.FieldError `json:"fields"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &body))
+		assert.Equal(t, int(response.CodeInvalidParams), body.Code)
+		assert.Equal(t, []response.FieldError{
+			{Field: "new_password", Code: auth.PasswordTooShort, Message: "密码长度不能少于 8 个字符"},
+		}, body.Fields)
+	})
+
+	t.Run("新密码为空", func(t *testing.T) {
+		handler, _, mockAccount := setupAccountTestHandler()
+
+		w := postJSON(handler.ResetPassword, "/users/password/reset", ResetPasswordRequest{Token: "good-token"})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockAccount.AssertNotCalled(t, "ResetPassword", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("令牌无效", func(t *testing.T) {
+		handler, _, mockAccount := setupAccountTestHandler()
+		mockAccount.On("ResetPassword", mock.Anything, "used-token", "newpassword").Return(service.ErrInvalidAccountToken)
+
+		w := postJSON(handler.ResetPassword, "/users/password/reset", ResetPasswordRequest{Token: "used-token", NewPassword: "newpassword"})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
@@ -0,0 +1,94 @@
+package user
+
+import (
+	"errors"
+	"log/slog"
+
+	"gin_demo/internal/app/middleware"
+	"gin_demo/internal/domain/service"
+	"gin_demo/internal/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BatchUsers 批量操作用户
+//
+// @Summary 批量操作用户
+// @Description 批量更新、停用、删除用户（最多 100 条，同一用户只能出现一次），每一条都按单个用户接口的规则校验（只能操作级别更低的用户，删除只允许超级管理员）。
+// @Description mode=atomic（默认）在一个事务中执行，任一条失败时全部回滚；mode=best_effort 逐条执行，失败的操作不影响其他操作。
+// @Description 请求有效时总是返回 200，每一条操作的结果见 results
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body BatchUsersRequest true "批量操作"
+// @Success 200 {object} response.Response{data=BatchUsersResponse} "执行完成"
+// @Failure 400 {object} response.Response "参数错误（包括同一用户出现多次、停用缺少原因）"
+// @Failure 401 {object} response.Response "未认证"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 413 {object} response.Response "请求体或操作条数超过上限"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /users/batch [post]
+func (h *Handler) BatchUsers(c *gin.Context) {
+	var req BatchUsersRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		if middleware.IsBodyTooLarge(err) {
+			response.Error(c, response.ErrRequestTooLarge)
+			return
+		}
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "参数错误", err))
+		return
+	}
+
+	input := service.BatchInput{
+		Mode:       service.BatchMode(req.Mode),
+		Operations: make([]service.BatchOperation, 0, len(req.Operations)),
+	}
+	if input.Mode == "" {
+		input.Mode = service.BatchModeAtomic
+	}
+	for _, op := range req.Operations {
+		input.Operations = append(input.Operations, service.BatchOperation{
+			Action:   service.BatchAction(op.Action),
+			UserID:   op.UserID,
+			Username: op.Username,
+			Email:    op.Email,
+			Avatar:   op.Avatar,
+			Reason:   op.Reason,
+		})
+	}
+
+	results, err := h.userService.BatchUsers(c.Request.Context(), input)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidInput) {
+			response.Error(c, response.New(response.CodeInvalidParams, "无效的批量操作（同一用户只能出现一次，停用需要说明原因）"))
+			return
+		}
+		slog.ErrorContext(c.Request.Context(), "Batch users failed", "mode", input.Mode, "error", err)
+		response.Error(c, err)
+		return
+	}
+
+	resp := BatchUsersResponse{
+		Mode:    string(input.Mode),
+		Results: make([]BatchResultResponse, 0, len(results)),
+	}
+	for i, result := range results {
+		item := BatchResultResponse{
+			Index:   i,
+			UserID:  result.UserID,
+			Action:  string(result.Action),
+			Success: result.Err == nil,
+		}
+		if result.Err != nil {
+			code, message := response.CodeOf(result.Err)
+			item.Code, item.Message = int(code), message
+			resp.Failed++
+		} else {
+			resp.Succeeded++
+		}
+		resp.Results = append(resp.Results, item)
+	}
+
+	response.Success(c, resp)
+}
@@ -0,0 +1,123 @@
+package user
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"gin_demo/internal/app/middleware"
+	"gin_demo/internal/domain/service"
+	"gin_demo/internal/response"
+	"gin_demo/pkg/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHandler_BatchUsers 测试批量操作用户
+func TestHandler_BatchUsers(t *testing.T) {
+	handler, mockService, jwtManager := setupTestHandler()
+
+	// 与实际路由一致：请求体大小限制 + 权限校验（每一条的资源级校验在 service 中）
+	rbac := middleware.NewRBACMiddleware(jwtManager, nil)
+	router := gin.New()
+	router.POST("/users/batch", middleware.BodyLimit(4096), rbac.Handle(),
+		middleware.RequirePermission(auth.PermissionUserWrite), handler.BatchUsers)
+
+	adminToken, err := jwtManager.GenerateToken(1, auth.RoleAdmin)
+	require.NoError(t, err)
+
+	do := func(token string, body []byte) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodPost, "/users/batch", bytes.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("尽力模式返回每一条的结果", func(t *testing.T) {
+		email := "new@example.com"
+		mockService.On("BatchUsers", mock.Anything, service.BatchInput{
+			Mode: service.BatchModeBestEffort,
+			Operations: []service.BatchOperation{
+				{Action: service.BatchActionUpdate, UserID: 2, Email: &email},
+				{Action: service.BatchActionSuspend, UserID: 3, Reason: "spam"},
+				{Action: service.BatchActionDelete, UserID: 4},
+			},
+		}).Return([]service.BatchResult{
+			{UserID: 2, Action: service.BatchActionUpdate},
+			{UserID: 3, Action: service.BatchActionSuspend, Err: service.ErrBatchForbidden},
+			{UserID: 4, Action: service.BatchActionDelete, Err: service.ErrUserNotFound},
+		}, nil).Once()
+
+		w := do(adminToken, []byte(`{"mode":"best_effort","operations":[
+			{"action":"update","user_id":2,"email":"new@example.com"},
+			{"action":"suspend","user_id":3,"reason":"spam"},
+			{"action":"delete","user_id":4}]}`))
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data BatchUsersResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "best_effort", resp.Data.Mode)
+		assert.Equal(t, 1, resp.Data.Succeeded)
+		assert.Equal(t, 2, resp.Data.Failed)
+		assert.Equal(t, BatchResultResponse{Index: 0, UserID: 2, Action: "update", Success: true}, resp.Data.Results[0])
+		assert.Equal(t, int(response.CodeForbidden), resp.Data.Results[1].Code)
+		assert.Equal(t, int(response.CodeNotFound), resp.Data.Results[2].Code)
+		assert.NotEmpty(t, resp.Data.Results[2].Message)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("默认原子模式", func(t *testing.T) {
+		mockService.On("BatchUsers", mock.Anything, service.BatchInput{
+			Mode:       service.BatchModeAtomic,
+			Operations: []service.BatchOperation{{Action: service.BatchActionDelete, UserID: 5}},
+		}).Return([]service.BatchResult{{UserID: 5, Action: service.BatchActionDelete}}, nil).Once()
+
+		w := do(adminToken, []byte(`{"operations":[{"action":"delete","user_id":5}]}`))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("无效的请求", func(t *testing.T) {
+		for _, body := range []string{
+			`{"operations":[]}`,
+			`{"mode":"parallel","operations":[{"action":"delete","user_id":5}]}`,
+			`{"operations":[{"action":"purge","user_id":5}]}`,
+			`{"operations":[{"action":"suspend","user_id":5}]}`, // 停用缺少原因
+		} {
+			w := do(adminToken, []byte(body))
+			assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		}
+	})
+
+	t.Run("条数超过上限", func(t *testing.T) {
+		mockService.On("BatchUsers", mock.Anything, mock.Anything).Return(nil, service.ErrBatchTooLarge).Once()
+
+		w := do(adminToken, []byte(`{"operations":[{"action":"delete","user_id":5}]}`))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+
+	t.Run("请求体超过上限", func(t *testing.T) {
+		body := `{"operations":[{"action":"delete","user_id":5,"reason":"` + strings.Repeat("x", 5000) + `"}]}`
+		w := do(adminToken, []byte(body))
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+	})
+
+	t.Run("没有 user:write 权限", func(t *testing.T) {
+		userToken, err := jwtManager.GenerateToken(7, auth.RoleUser)
+		require.NoError(t, err)
+
+		w := do(userToken, []byte(`{"operations":[{"action":"delete","user_id":5}]}`))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
@@ -2,6 +2,11 @@ package user
 
 import "time"
 
+// SuspendUserRequest 停用用户请求
+type SuspendUserRequest struct {
+	Reason string `json:"reason" binding:"required,max=255"` // 停用原因（保存在用户记录和审计日志中）
+}
+
 // ========================================
 // 请求 DTO
 // ========================================
@@ -15,7 +20,7 @@ type IDRequest struct {
 type RegisterRequest struct {
 	Username string `json:"username" binding:"required,min=3,max=50"`
 	Email    string `json:"email" binding:"required,email"`
-	Password string `json:"password" binding:"required,min=6,max=50"`
+	Password string `json:"password" binding:"required,max=1024"` // 长度和复杂度由密码策略校验
 }
 
 // LoginRequest 登录请求
@@ -34,7 +39,89 @@ type UpdateUserRequest struct {
 // ChangePasswordRequest 修改密码请求
 type ChangePasswordRequest struct {
 	OldPassword string `json:"old_password" binding:"required"`
-	NewPassword string `json:"new_password" binding:"required,min=6,max=50"`
+	NewPassword string `json:"new_password" binding:"required,max=1024"` // 长度和复杂度由密码策略校验
+}
+
+// UpdateRoleRequest 更新角色请求
+type UpdateRoleRequest struct {
+	Role        string   `json:"role" binding:"required,oneof=user moderator admin super_admin"`
+	Permissions []string `json:"permissions"`
+}
+
+// RefreshTokenRequest 刷新/登出请求
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+// LoginMFARequest 登录第二步（两步验证）请求
+type LoginMFARequest struct {
+	MFAToken string `json:"mfa_token" binding:"required"`
+	Code     string `json:"code" binding:"required,max=32"` // TOTP 验证码或恢复码
+}
+
+// MFACodeRequest 两步验证码请求（确认绑定、重新生成恢复码、关闭两步验证）
+type MFACodeRequest struct {
+	Code string `json:"code" binding:"required,max=32"`
+}
+
+// TokenRequest 邮件链接令牌请求（验证邮箱）
+type TokenRequest struct {
+	Token string `json:"token" binding:"required,max=256"`
+}
+
+// EmailRequest 邮箱请求（重新发送验证邮件、忘记密码）
+type EmailRequest struct {
+	Email string `json:"email" binding:"required,email"`
+}
+
+// ResetPasswordRequest 重置密码请求
+type ResetPasswordRequest struct {
+	Token       string `json:"token" binding:"required,max=256"`
+	NewPassword string `json:"new_password" binding:"required,max=1024"` // 长度和复杂度由密码策略校验
+}
+
+// OAuthCallbackRequest 第三方登录回调请求（前端从回调地址的查询参数中取出后提交）
+type OAuthCallbackRequest struct {
+	Code  string `json:"code" binding:"required,max=2048"`
+	State string `json:"state" binding:"required,max=256"`
+}
+
+// ImpersonateRequest 模拟登录请求
+type ImpersonateRequest struct {
+	Reason string `json:"reason" binding:"required,max=255"` // 模拟登录原因（记录在审计日志中，如工单号）
+}
+
+// ListUsersRequest 用户列表过滤和排序请求（分页参数由 response.GetPagination 解析）
+type ListUsersRequest struct {
+	Status      []int16   `form:"status" binding:"omitempty,max=6,dive,oneof=1 2 3 4 5 6"` // 状态（可重复，默认只列出正常用户）
+	Role        []string  `form:"role" binding:"omitempty,max=10,dive,min=1,max=50"`       // 角色（可重复）
+	CreatedFrom time.Time `form:"created_from"`                                            // 创建时间不早于（RFC 3339）
+	CreatedTo   time.Time `form:"created_to"`                                              // 创建时间早于（RFC 3339）
+	Q           string    `form:"q" binding:"max=100"`                                     // 用户名或邮箱包含的文本
+	Sort        string    `form:"sort" binding:"omitempty,oneof=created_at updated_at username email id"`
+	Order       string    `form:"order" binding:"omitempty,oneof=asc desc"`
+}
+
+// MergeUserRequest 合并账户请求
+type MergeUserRequest struct {
+	Into   int64 `json:"into" binding:"required,min=1"` // 目标用户 ID（接收数据的用户）
+	DryRun bool  `json:"dry_run"`                       // 只返回将要转移的数据，不执行合并
+}
+
+// BatchUsersRequest 批量操作请求（条数上限由 service 校验，超过时返回 413）
+type BatchUsersRequest struct {
+	Mode       string                  `json:"mode" binding:"omitempty,oneof=atomic best_effort"` // 执行方式（默认 atomic）
+	Operations []BatchOperationRequest `json:"operations" binding:"required,min=1,dive"`
+}
+
+// BatchOperationRequest 批量操作中的一条
+type BatchOperationRequest struct {
+	Action   string  `json:"action" binding:"required,oneof=update suspend delete"`
+	UserID   int64   `json:"user_id" binding:"required,min=1"`
+	Username *string `json:"username" binding:"omitempty,min=3,max=50"`           // 更新时使用（不传表示不修改）
+	Email    *string `json:"email" binding:"omitempty,email"`                     // 同上
+	Avatar   *string `json:"avatar" binding:"omitempty,max=255"`                  // 同上（空字符串表示清除头像）
+	Reason   string  `json:"reason" binding:"required_if=Action suspend,max=255"` // 停用原因
 }
 
 // ========================================
@@ -47,13 +134,106 @@ type Response struct {
 	Username  string    `json:"username"`
 	Email     string    `json:"email"`
 	Avatar    string    `json:"avatar"`
-	Status    int16     `json:"status"`
+	Role      string    `json:"role"`
+	Status    int16     `json:"status"` // 1:正常 2:停用 3:待激活 4:已删除 5:已匿名 6:已合并
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	StatusReason string     `json:"status_reason,omitempty"` // 停用原因
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`    // 删除时间
+	MergedInto   int64      `json:"merged_into,omitempty"`   // 合并到的用户 ID（已合并时）
 }
 
 // LoginResponse 登录响应（包含 Token）
 type LoginResponse struct {
-	User  Response `json:"user"`
-	Token string   `json:"token"`
+	User         Response `json:"user"`
+	Token        string   `json:"token"`         // Access Token
+	RefreshToken string   `json:"refresh_token"` // Refresh Token（一次性，使用后轮换）
+	ExpiresIn    int64    `json:"expires_in"`    // Access Token 有效期（秒）
+}
+
+// TokenResponse 刷新 Token 响应
+type TokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+}
+
+// ImpersonationResponse 模拟登录响应（不包含 Refresh Token，过期后需要重新发起）
+type ImpersonationResponse struct {
+	User      Response `json:"user"`       // 被模拟的用户
+	Token     string   `json:"token"`      // 模拟登录 Access Token
+	ExpiresIn int64    `json:"expires_in"` // 有效期（秒）
+	ActorID   int64    `json:"actor_id"`   // 实际操作的管理员 ID
+}
+
+// MFAChallengeResponse 登录需要两步验证时的响应
+type MFAChallengeResponse struct {
+	MFARequired bool   `json:"mfa_required"`
+	MFAToken    string `json:"mfa_token"`  // 待验证令牌（只能用于 /users/login/mfa）
+	ExpiresIn   int64  `json:"expires_in"` // 待验证令牌有效期（秒）
+}
+
+// MFAStatusResponse 两步验证状态响应
+type MFAStatusResponse struct {
+	Enabled                bool       `json:"enabled"`
+	EnabledAt              *time.Time `json:"enabled_at,omitempty"`
+	RecoveryCodesRemaining int64      `json:"recovery_codes_remaining"`
+}
+
+// TOTPEnrollmentResponse TOTP 绑定响应
+type TOTPEnrollmentResponse struct {
+	Secret string `json:"secret"` // Base32 密钥（手动输入）
+	URI    string `json:"uri"`    // otpauth:// URI（生成二维码）
+}
+
+// RecoveryCodesResponse 恢复码响应（明文只返回这一次）
+type RecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+// OAuthProvidersResponse 第三方登录提供方列表响应
+type OAuthProvidersResponse struct {
+	Providers []string `json:"providers"`
+}
+
+// OAuthAuthorizeResponse 第三方授权地址响应
+type OAuthAuthorizeResponse struct {
+	AuthorizationURL string `json:"authorization_url"` // 前端跳转到该地址，授权后提供方重定向回 redirect_url
+}
+
+// IdentityResponse 已关联的第三方账户响应
+type IdentityResponse struct {
+	Provider    string     `json:"provider"`
+	Email       string     `json:"email"`
+	LastLoginAt *time.Time `json:"last_login_at"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// MergeReportResponse 合并账户响应（预演时为将要转移的数据）
+type MergeReportResponse struct {
+	FromUserID            int64    `json:"from_user_id"`
+	IntoUserID            int64    `json:"into_user_id"`
+	DryRun                bool     `json:"dry_run"`
+	Identities            []string `json:"identities"`             // 转移的第三方账户（提供方）
+	ConflictingIdentities []string `json:"conflicting_identities"` // 两个用户都关联了的提供方（存在时不能合并）
+	APIKeys               []string `json:"api_keys"`               // 转移的 API Key（公开标识）
+}
+
+// BatchUsersResponse 批量操作响应
+type BatchUsersResponse struct {
+	Mode      string                `json:"mode"`
+	Succeeded int                   `json:"succeeded"`
+	Failed    int                   `json:"failed"`
+	Results   []BatchResultResponse `json:"results"` // 与请求中的 operations 按顺序对应
+}
+
+// BatchResultResponse 批量操作中一条操作的结果
+type BatchResultResponse struct {
+	Index   int    `json:"index"` // 在请求 operations 中的序号（从 0 开始）
+	UserID  int64  `json:"user_id"`
+	Action  string `json:"action"`
+	Success bool   `json:"success"`
+	Code    int    `json:"code"`              // 0 表示成功，其余与接口错误码相同
+	Message string `json:"message,omitempty"` // 失败原因
 }
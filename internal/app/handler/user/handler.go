@@ -1,35 +1,70 @@
 package user
 
 import (
+	"context"
+	"errors"
+	"fmt"
 	"log/slog"
+	"math"
+	"strconv"
+	"time"
 
 	"gin_demo/internal/app/middleware"
 	"gin_demo/internal/domain/service"
 	"gin_demo/internal/repository"
 	"gin_demo/internal/response"
 	"gin_demo/pkg/auth"
+	"gin_demo/pkg/metrics"
+	"gin_demo/pkg/tenant"
 
 	"github.com/gin-gonic/gin"
 )
 
 // Handler 用户处理器
 type Handler struct {
-	userService service.UserService
-	jwtManager  *auth.DefaultJWTManager
+	userService    service.UserService
+	mfaService     service.MFAService
+	accountService service.AccountService
+	oauthService   service.OAuthService
+	jwtManager     *auth.RBACJWTManager
+	refreshManager *auth.RefreshTokenManager
+	mfaTokens      *auth.MFATokenManager
+	impersonation  *auth.ImpersonationTokenManager
+	loginGuard     *auth.LoginGuard
+	cursors        *response.CursorCodec
 }
 
 // NewHandler 创建用户处理器
-func NewHandler(userService service.UserService, jwtManager *auth.DefaultJWTManager) *Handler {
+func NewHandler(
+	userService service.UserService,
+	mfaService service.MFAService,
+	accountService service.AccountService,
+	oauthService service.OAuthService,
+	jwtManager *auth.RBACJWTManager,
+	refreshManager *auth.RefreshTokenManager,
+	mfaTokens *auth.MFATokenManager,
+	impersonation *auth.ImpersonationTokenManager,
+	loginGuard *auth.LoginGuard,
+	cursors *response.CursorCodec,
+) *Handler {
 	return &Handler{
-		userService: userService,
-		jwtManager:  jwtManager,
+		userService:    userService,
+		mfaService:     mfaService,
+		accountService: accountService,
+		oauthService:   oauthService,
+		jwtManager:     jwtManager,
+		refreshManager: refreshManager,
+		mfaTokens:      mfaTokens,
+		impersonation:  impersonation,
+		loginGuard:     loginGuard,
+		cursors:        cursors,
 	}
 }
 
 // Register 用户注册
 //
 // @Summary 用户注册
-// @Description 创建新用户账户
+// @Description 创建新用户账户（状态为邮箱未验证），并发送邮箱验证邮件
 // @Tags 用户管理
 // @Accept json
 // @Produce json
@@ -57,20 +92,27 @@ func (h *Handler) Register(c *gin.Context) {
 		return
 	}
 
+	// 验证邮件发送失败不影响注册结果，用户可以通过 /users/verify-email/resend 重新发送
+	if err := h.accountService.SendVerificationEmail(c.Request.Context(), user); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Send verification email failed", "user_id", user.ID, "error", err)
+	}
+
 	response.Success(c, toResponse(user))
 }
 
 // Login 用户登录
 //
 // @Summary 用户登录
-// @Description 验证用户凭证并返回 JWT Token
+// @Description 验证用户凭证并返回 JWT Token；已启用两步验证的用户返回 MFAChallengeResponse（mfa_required=true），需要再调用 /users/login/mfa
 // @Tags 用户管理
 // @Accept json
 // @Produce json
 // @Param request body LoginRequest true "登录信息"
 // @Success 200 {object} response.Response{data=LoginResponse} "登录成功"
-// @Failure 400 {object} response.Response "参数错误"
+// @Failure 400 {object} response.Response "参数错误或邮箱、密码错误"
 // @Failure 401 {object} response.Response "认证失败"
+// @Failure 403 {object} response.Response "邮箱未验证"
+// @Failure 429 {object} response.Response "失败次数过多，暂时锁定"
 // @Failure 500 {object} response.Response "服务器错误"
 // @Router /users/login [post]
 func (h *Handler) Login(c *gin.Context) {
@@ -80,30 +122,169 @@ func (h *Handler) Login(c *gin.Context) {
 		return
 	}
 
-	user, err := h.userService.Login(c.Request.Context(), service.LoginInput{
+	ctx := c.Request.Context()
+	clientIP := c.ClientIP()
+
+	// 锁定期间直接拒绝，不校验密码（避免锁定期间继续猜测）
+	if err := h.loginGuard.Check(ctx, req.Email, clientIP); err != nil {
+		if !errors.Is(err, auth.ErrLoginLocked) {
+			// 存储故障时放行，避免 Redis 不可用导致所有用户无法登录
+			slog.ErrorContext(ctx, "Check login lockout failed", "email", req.Email, "ip", clientIP, "error", err)
+		} else {
+			slog.WarnContext(ctx, "Login rejected: locked", "email", req.Email, "ip", clientIP, "error", err)
+			metrics.AuthFailures.WithLabelValues("locked").Inc()
+			h.lockoutResponse(c, err)
+			return
+		}
+	}
+
+	user, err := h.userService.Login(ctx, service.LoginInput{
 		Email:    req.Email,
 		Password: req.Password,
 	})
 	if err != nil {
-		slog.WarnContext(c.Request.Context(), "Login failed", "email", req.Email, "error", err)
+		slog.WarnContext(ctx, "Login failed", "email", req.Email, "error", err)
+
+		// 用户不存在同样计入失败次数并返回相同的错误，避免通过响应或锁定行为探测账户是否存在
+		if errors.Is(err, service.ErrInvalidPassword) || errors.Is(err, service.ErrUserNotFound) {
+			metrics.AuthFailures.WithLabelValues("invalid_credentials").Inc()
+			if lockErr := h.loginGuard.RecordFailure(ctx, req.Email, clientIP); lockErr != nil && !errors.Is(lockErr, auth.ErrLoginLocked) {
+				slog.ErrorContext(ctx, "Record login failure failed", "email", req.Email, "ip", clientIP, "error", lockErr)
+			}
+			response.Error(c, response.New(response.CodeInvalidPassword, "邮箱或密码错误"))
+			return
+		}
+
 		response.Error(c, err)
 		return
 	}
 
-	// 生成 JWT Token（只包含 UserID）
-	token, err := h.jwtManager.GenerateToken(user.ID)
+	h.beginSession(c, user)
+}
+
+// RefreshToken 刷新 Token
+//
+// @Summary 刷新 Token
+// @Description 使用 Refresh Token 换取新的 Access Token 和 Refresh Token（旧 Refresh Token 立即失效）
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body RefreshTokenRequest true "Refresh Token"
+// @Success 200 {object} response.Response{data=TokenResponse} "刷新成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "Refresh Token 无效或已被重复使用"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /auth/refresh [post]
+func (h *Handler) RefreshToken(c *gin.Context) {
+	var req RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "参数错误", err))
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	// 1. 校验签发时的租户（在轮换之前，租户不一致时拒绝但不消耗令牌）
+	// 请求指定了其他租户（子域名或请求头）时拒绝，与认证中间件校验 Access Token 的规则一致
+	issued, err := h.refreshManager.Lookup(ctx, req.RefreshToken)
 	if err != nil {
-		slog.ErrorContext(c.Request.Context(), "Generate token failed", "user_id", user.ID, "error", err)
-		response.Error(c, response.NewWithError(response.CodeInternalError, "生成 Token 失败", err))
+		response.Error(c, refreshTokenError(err))
+		return
+	}
+	tenantID := issued.TenantID
+	if tenantID == 0 {
+		tenantID = tenant.DefaultID
+	}
+	if current, ok := tenant.FromContext(ctx); ok && current != tenantID {
+		metrics.AuthFailures.WithLabelValues("tenant_mismatch").Inc()
+		response.Error(c, response.New(response.CodeUnauthorized, "Refresh Token 不属于当前租户"))
 		return
 	}
+	ctx = tenant.WithID(ctx, tenantID)
 
-	response.Success(c, LoginResponse{
-		User:  toResponse(user),
-		Token: token,
+	// 2. 轮换 Refresh Token
+	refreshToken, record, err := h.refreshManager.Rotate(ctx, req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, auth.ErrRefreshTokenReused) {
+			slog.WarnContext(ctx, "Refresh token reuse detected, token family revoked", "user_id", record.UserID)
+			metrics.AuthFailures.WithLabelValues("refresh_token_reused").Inc()
+		}
+		response.Error(c, refreshTokenError(err))
+		return
+	}
+
+	// 3. 重新加载用户（角色变更、用户被删除都会在这里生效）
+	user, err := h.userService.GetUserByID(ctx, record.UserID)
+	if err != nil {
+		// 只有用户不存在或已禁用时吊销会话，数据库暂时故障不应让用户被迫重新登录
+		if errors.Is(err, service.ErrUserNotFound) {
+			_, _ = h.refreshManager.Revoke(ctx, refreshToken)
+			response.Error(c, response.New(response.CodeUnauthorized, "用户不存在或已禁用"))
+			return
+		}
+		response.Error(c, err)
+		return
+	}
+
+	// 修改密码或登出所有设备后，之前签发的 Refresh Token 一律作废
+	if user.TokenVersion != record.TokenVersion {
+		_, _ = h.refreshManager.Revoke(ctx, refreshToken)
+		metrics.AuthFailures.WithLabelValues("token_revoked").Inc()
+		response.Error(c, response.New(response.CodeUnauthorized, "Refresh Token 已失效，请重新登录"))
+		return
+	}
+
+	// 4. 生成新的 Access Token
+	token, err := h.generateAccessToken(ctx, user)
+	if err != nil {
+		slog.ErrorContext(ctx, "Generate token failed", "user_id", user.ID, "error", err)
+		response.Error(c, err)
+		return
+	}
+
+	metrics.TokenRefreshes.Inc()
+
+	response.Success(c, TokenResponse{
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(h.jwtManager.Expiration().Seconds()),
 	})
 }
 
+// Logout 登出
+//
+// @Summary 登出
+// @Description 吊销 Refresh Token 及同一次登录产生的所有后续 Refresh Token
+// @Tags 认证
+// @Accept json
+// @Produce json
+// @Param request body RefreshTokenRequest true "Refresh Token"
+// @Success 200 {object} response.Response "登出成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /auth/logout [post]
+func (h *Handler) Logout(c *gin.Context) {
+	var req RefreshTokenRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "参数错误", err))
+		return
+	}
+
+	userID, err := h.refreshManager.Revoke(c.Request.Context(), req.RefreshToken)
+	if err != nil && !errors.Is(err, auth.ErrRefreshTokenInvalid) {
+		slog.ErrorContext(c.Request.Context(), "Logout failed", "error", err)
+		response.Error(c, response.NewWithError(response.CodeInternalError, "登出失败", err))
+		return
+	}
+
+	// 已失效的令牌同样视为登出成功（幂等）
+	if err == nil {
+		slog.InfoContext(c.Request.Context(), "User logged out", "user_id", userID)
+	}
+
+	response.Success(c, nil)
+}
+
 // GetProfile 获取当前用户信息
 //
 // @Summary 获取当前用户信息
@@ -223,7 +404,7 @@ func (h *Handler) UpdateProfile(c *gin.Context) {
 // @Success 200 {object} response.Response "更新成功"
 // @Failure 400 {object} response.Response "参数错误"
 // @Failure 401 {object} response.Response "未认证"
-// @Failure 403 {object} response.Response "权限不足"
+// @Failure 403 {object} response.Response "权限不足或目标用户级别不低于当前用户"
 // @Failure 404 {object} response.Response "用户不存在"
 // @Failure 409 {object} response.Response "邮箱/用户名已存在"
 // @Failure 500 {object} response.Response "服务器错误"
@@ -295,13 +476,42 @@ func (h *Handler) ChangePassword(c *gin.Context) {
 		return
 	}
 
-	response.Success(c, gin.H{"message": "密码修改成功"})
+	response.Success(c, gin.H{"message": "密码修改成功，请重新登录"})
+}
+
+// RevokeSessions 登出所有设备
+//
+// @Summary 登出所有设备
+// @Description 吊销当前用户所有已签发的 Access Token 和 Refresh Token（包括当前请求使用的 Token）
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response "吊销成功"
+// @Failure 401 {object} response.Response "未认证"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /users/me/sessions [delete]
+func (h *Handler) RevokeSessions(c *gin.Context) {
+	// 从认证中间件获取当前用户 ID
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Error(c, response.NewWithError(response.CodeUnauthorized, "未认证", nil))
+		return
+	}
+
+	if err := h.userService.RevokeAllSessions(c.Request.Context(), userID); err != nil {
+		slog.ErrorContext(c.Request.Context(), "Revoke sessions failed", "user_id", userID, "error", err)
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, gin.H{"message": "已登出所有设备"})
 }
 
 // DeleteUser 删除用户
 //
 // @Summary 删除用户
-// @Description 管理员删除指定用户（软删除）
+// @Description 超级管理员删除指定用户（软删除，保留期内可以恢复，之后永久删除）
 // @Tags 用户管理
 // @Accept json
 // @Produce json
@@ -310,7 +520,7 @@ func (h *Handler) ChangePassword(c *gin.Context) {
 // @Success 200 {object} response.Response "删除成功"
 // @Failure 400 {object} response.Response "参数错误"
 // @Failure 401 {object} response.Response "未认证"
-// @Failure 403 {object} response.Response "权限不足"
+// @Failure 403 {object} response.Response "权限不足或目标用户级别不低于当前用户"
 // @Failure 404 {object} response.Response "用户不存在"
 // @Failure 500 {object} response.Response "服务器错误"
 // @Router /users/{id} [delete]
@@ -331,17 +541,132 @@ func (h *Handler) DeleteUser(c *gin.Context) {
 	response.Success(c, nil)
 }
 
+// UnlockLogin 解除登录锁定（管理员）
+//
+// @Summary 解除登录锁定
+// @Description 清除指定用户的登录失败记录并解除锁定，可选同时解除某个 IP 的锁定
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Param ip query string false "同时解锁的客户端 IP"
+// @Success 200 {object} response.Response "解锁成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未认证"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 404 {object} response.Response "用户不存在"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /users/{id}/lockout [delete]
+func (h *Handler) UnlockLogin(c *gin.Context) {
+	var req IDRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "无效的用户ID", err))
+		return
+	}
+
+	ctx := c.Request.Context()
+
+	user, err := h.userService.GetUserByID(ctx, req.ID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	if err := h.loginGuard.UnlockAccount(ctx, user.Email); err != nil {
+		slog.ErrorContext(ctx, "Unlock account failed", "user_id", user.ID, "error", err)
+		response.Error(c, response.NewWithError(response.CodeInternalError, "解除锁定失败", err))
+		return
+	}
+
+	if ip := c.Query("ip"); ip != "" {
+		if err := h.loginGuard.UnlockIP(ctx, ip); err != nil {
+			slog.ErrorContext(ctx, "Unlock ip failed", "ip", ip, "error", err)
+			response.Error(c, response.NewWithError(response.CodeInternalError, "解除锁定失败", err))
+			return
+		}
+	}
+
+	slog.InfoContext(ctx, "Login lockout cleared",
+		"user_id", user.ID,
+		"ip", c.Query("ip"),
+		"operator_id", middleware.GetUserID(c),
+	)
+
+	response.Success(c, nil)
+}
+
+// UpdateUserRole 更新指定用户角色（超级管理员）
+//
+// @Summary 更新用户角色
+// @Description 超级管理员修改用户角色及额外权限（新 Token 生效）
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Param request body UpdateRoleRequest true "角色信息"
+// @Success 200 {object} response.Response "更新成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未认证"
+// @Failure 403 {object} response.Response "权限不足或目标用户级别不低于当前用户"
+// @Failure 404 {object} response.Response "用户不存在"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /users/{id}/role [put]
+func (h *Handler) UpdateUserRole(c *gin.Context) {
+	var idReq IDRequest
+	if err := c.ShouldBindUri(&idReq); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "无效的用户ID", err))
+		return
+	}
+
+	var req UpdateRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "参数错误", err))
+		return
+	}
+
+	permissions := make([]auth.Permission, 0, len(req.Permissions))
+	for _, p := range req.Permissions {
+		permissions = append(permissions, auth.Permission(p))
+	}
+
+	err := h.userService.UpdateUserRole(c.Request.Context(), service.UpdateRoleInput{
+		UserID:      idReq.ID,
+		Role:        auth.Role(req.Role),
+		Permissions: permissions,
+	})
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Update user role failed", "user_id", idReq.ID, "error", err)
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
 // ListUsers 用户列表
 //
 // @Summary 获取用户列表
-// @Description 管理员获取用户列表（分页）
+// @Description 管理员获取用户列表（分页），支持按状态、角色、创建时间和用户名/邮箱过滤，按白名单中的字段排序；total 为符合过滤条件的总数。
+// @Description 带 cursor 参数（第一页传空值）时使用游标分页：只能按创建时间排序，不返回总数，通过 next_cursor / prev_cursor 翻页
 // @Tags 用户管理
 // @Accept json
 // @Produce json
 // @Security BearerAuth
 // @Param page query int false "页码" default(1)
 // @Param page_size query int false "每页数量" default(10)
+// @Param status query []int false "状态（可重复，默认只列出正常用户）" collectionFormat(multi)
+// @Param role query []string false "角色（可重复）" collectionFormat(multi)
+// @Param created_from query string false "创建时间不早于（RFC 3339）"
+// @Param created_to query string false "创建时间早于（RFC 3339）"
+// @Param q query string false "用户名或邮箱包含的文本"
+// @Param sort query string false "排序字段（created_at、updated_at、username、email、id）" default(created_at)
+// @Param order query string false "排序方向（asc、desc）" default(desc)
+// @Param cursor query string false "分页游标（上一页返回的 next_cursor 或 prev_cursor，第一页传空值）"
 // @Success 200 {object} response.Response{data=response.ListResponse} "获取成功"
+// @Success 200 {object} response.Response{data=response.CursorListResponse} "获取成功（游标分页）"
+// @Failure 400 {object} response.Response "参数错误"
 // @Failure 401 {object} response.Response "未认证"
 // @Failure 403 {object} response.Response "权限不足"
 // @Failure 500 {object} response.Response "服务器错误"
@@ -350,8 +675,34 @@ func (h *Handler) ListUsers(c *gin.Context) {
 	// 获取分页参数
 	pagination := response.GetPagination(c)
 
-	users, total, err := h.userService.ListUsers(c.Request.Context(), pagination.GetLimit(), pagination.GetOffset())
+	// 获取过滤和排序参数
+	var req ListUsersRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "参数错误", err))
+		return
+	}
+
+	filter := repository.UserFilter{
+		Statuses:    req.Status,
+		Roles:       req.Role,
+		CreatedFrom: req.CreatedFrom,
+		CreatedTo:   req.CreatedTo,
+		Query:       req.Q,
+		Sort:        repository.UserSortField(req.Sort),
+		Order:       repository.SortOrder(req.Order),
+	}
+
+	if cursorPage, ok := response.GetCursorPagination(c); ok {
+		h.listUsersByCursor(c, filter, cursorPage)
+		return
+	}
+
+	users, total, err := h.userService.ListUsers(c.Request.Context(), filter, pagination.GetLimit(), pagination.GetOffset())
 	if err != nil {
+		if errors.Is(err, service.ErrInvalidInput) {
+			response.Error(c, response.New(response.CodeInvalidParams, "无效的过滤条件"))
+			return
+		}
 		slog.ErrorContext(c.Request.Context(), "List users failed", "error", err)
 		response.Error(c, response.Wrap(err, response.CodeInternalError, "获取用户列表失败"))
 		return
@@ -369,6 +720,58 @@ func (h *Handler) ListUsers(c *gin.Context) {
 	response.Success(c, response.NewListResponse(responses, paginationResp))
 }
 
+// listUsersByCursor 用户列表（游标分页）
+//
+// 游标绑定排序方向，切换 order 后旧游标失效。
+func (h *Handler) listUsersByCursor(c *gin.Context, filter repository.UserFilter, pagination response.CursorPaginationRequest) {
+	scope := "users:" + string(repository.SortDesc)
+	if filter.Order == repository.SortAsc {
+		scope = "users:" + string(repository.SortAsc)
+	}
+	page := repository.KeysetPage{Limit: pagination.GetLimit()}
+	if pagination.Cursor != "" {
+		cursor, err := h.cursors.Decode(scope, pagination.Cursor)
+		if err != nil {
+			response.Error(c, err)
+			return
+		}
+		page.HasCursor = true
+		page.CreatedAt = cursor.CreatedAt
+		page.ID = cursor.ID
+		page.Backward = cursor.Backward
+	}
+
+	result, err := h.userService.ListUsersByCursor(c.Request.Context(), filter, page)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidInput) {
+			response.Error(c, response.New(response.CodeInvalidParams, "无效的过滤条件"))
+			return
+		}
+		slog.ErrorContext(c.Request.Context(), "List users by cursor failed", "error", err)
+		response.Error(c, response.Wrap(err, response.CodeInternalError, "获取用户列表失败"))
+		return
+	}
+
+	responses := make([]Response, 0, len(result.Items))
+	for _, user := range result.Items {
+		responses = append(responses, toResponse(user))
+	}
+
+	paginationResp := response.CursorPaginationResponse{PageSize: pagination.PageSize}
+	if n := len(result.Items); n > 0 {
+		if result.HasNext {
+			last := result.Items[n-1]
+			paginationResp.NextCursor = h.cursors.Encode(scope, response.Cursor{CreatedAt: last.CreatedAt, ID: last.ID})
+		}
+		if result.HasPrev {
+			first := result.Items[0]
+			paginationResp.PrevCursor = h.cursors.Encode(scope, response.Cursor{CreatedAt: first.CreatedAt, ID: first.ID, Backward: true})
+		}
+	}
+
+	response.Success(c, response.NewCursorListResponse(responses, paginationResp))
+}
+
 // toResponse 转换为响应 DTO
 func toResponse(user repository.User) Response {
 	avatar := ""
@@ -376,13 +779,151 @@ func toResponse(user repository.User) Response {
 		avatar = user.Avatar.String
 	}
 
-	return Response{
-		ID:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		Avatar:    avatar,
-		Status:    user.Status,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
+	resp := Response{
+		ID:           user.ID,
+		Username:     user.Username,
+		Email:        user.Email,
+		Avatar:       avatar,
+		Role:         string(userRole(user)),
+		Status:       user.Status,
+		CreatedAt:    user.CreatedAt,
+		UpdatedAt:    user.UpdatedAt,
+		StatusReason: user.StatusReason.String,
+		MergedInto:   user.MergedInto.Int64,
 	}
+	if user.DeletedAt.Valid {
+		resp.DeletedAt = &user.DeletedAt.Time
+	}
+	return resp
+}
+
+// generateAccessToken 生成 RBAC Access Token（包含最新的角色、额外权限和 Token 版本号）
+func (h *Handler) generateAccessToken(ctx context.Context, user repository.User) (string, error) {
+	// 查询额外权限（角色隐式权限之外单独授予的）
+	permissions, err := h.userService.GetUserPermissions(ctx, user.ID)
+	if err != nil {
+		return "", err
+	}
+
+	token, err := h.jwtManager.GenerateTenantToken(user.TenantID, user.ID, user.TokenVersion, userRole(user), permissions...)
+	if err != nil {
+		return "", response.NewWithError(response.CodeInternalError, "生成 Token 失败", err)
+	}
+	return token, nil
+}
+
+// beginSession 身份验证通过后开始会话（密码登录、第三方登录共用）
+//
+// 已启用两步验证时只签发短期的待验证令牌，登录失败计数在第二步通过后才清除。
+func (h *Handler) beginSession(c *gin.Context, user repository.User) {
+	ctx := c.Request.Context()
+
+	mfaEnabled, err := h.mfaService.IsEnabled(ctx, user.ID)
+	if err != nil {
+		slog.ErrorContext(ctx, "Check mfa status failed", "user_id", user.ID, "error", err)
+		response.Error(c, err)
+		return
+	}
+	if mfaEnabled {
+		mfaToken, err := h.mfaTokens.GenerateToken(user.TenantID, user.ID, user.TokenVersion)
+		if err != nil {
+			slog.ErrorContext(ctx, "Generate mfa token failed", "user_id", user.ID, "error", err)
+			response.Error(c, response.NewWithError(response.CodeInternalError, "生成 Token 失败", err))
+			return
+		}
+
+		response.Success(c, MFAChallengeResponse{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+			ExpiresIn:   int64(h.mfaTokens.Expiration().Seconds()),
+		})
+		return
+	}
+
+	h.completeLogin(c, user)
+}
+
+// completeLogin 登录成功：清除失败计数，签发 Access Token 和 Refresh Token
+func (h *Handler) completeLogin(c *gin.Context, user repository.User) {
+	ctx := c.Request.Context()
+
+	if err := h.loginGuard.RecordSuccess(ctx, user.Email); err != nil {
+		slog.ErrorContext(ctx, "Reset login failures failed", "user_id", user.ID, "error", err)
+	}
+
+	// 生成 Access Token（包含 UserID、角色和额外权限）
+	token, err := h.generateAccessToken(ctx, user)
+	if err != nil {
+		slog.ErrorContext(ctx, "Generate token failed", "user_id", user.ID, "error", err)
+		response.Error(c, err)
+		return
+	}
+
+	// 签发 Refresh Token（开启新的令牌家族）
+	refreshToken, err := h.refreshManager.Issue(ctx, user.ID, user.TokenVersion)
+	if err != nil {
+		slog.ErrorContext(ctx, "Issue refresh token failed", "user_id", user.ID, "error", err)
+		response.Error(c, response.NewWithError(response.CodeInternalError, "生成 Token 失败", err))
+		return
+	}
+
+	response.Success(c, LoginResponse{
+		User:         toResponse(user),
+		Token:        token,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(h.jwtManager.Expiration().Seconds()),
+	})
+}
+
+// refreshTokenError 将 Refresh Token 错误转换为业务错误
+func refreshTokenError(err error) error {
+	switch {
+	case errors.Is(err, auth.ErrRefreshTokenReused):
+		return response.New(response.CodeUnauthorized, "Refresh Token 已被使用，请重新登录")
+	case errors.Is(err, auth.ErrRefreshTokenInvalid):
+		return response.New(response.CodeUnauthorized, "Refresh Token 无效或已过期")
+	default:
+		return response.NewWithError(response.CodeInternalError, "刷新 Token 失败", err)
+	}
+}
+
+// lockoutResponse 返回登录锁定响应（带 Retry-After 响应头）
+func (h *Handler) lockoutResponse(c *gin.Context, err error) {
+	var lockErr *auth.LockoutError
+	if !errors.As(err, &lockErr) {
+		response.Error(c, response.ErrAccountLocked)
+		return
+	}
+
+	retryAfter := time.Duration(math.Ceil(lockErr.RetryAfter.Seconds())) * time.Second
+	c.Header("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+	response.Error(c, response.New(response.CodeAccountLocked, fmt.Sprintf("登录失败次数过多，请 %s 后重试", retryAfter)))
+}
+
+// userRole 获取用户角色（缺省为普通用户）
+func userRole(user repository.User) auth.Role {
+	if user.Role == "" {
+		return auth.RoleUser
+	}
+	return auth.Role(user.Role)
+}
+
+// ResolveUser 按路径参数加载目标用户（RequirePolicy 的资源解析器）
+func (h *Handler) ResolveUser(c *gin.Context) (*auth.Resource, error) {
+	var req IDRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		return nil, response.NewWithError(response.CodeInvalidParams, "无效的用户ID", err)
+	}
+
+	user, err := h.userService.GetUserByID(c.Request.Context(), req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &auth.Resource{
+		Type:       auth.ResourceUser,
+		ID:         user.ID,
+		OwnerID:    user.ID,
+		Attributes: map[string]any{auth.AttrRole: userRole(user)},
+	}, nil
 }
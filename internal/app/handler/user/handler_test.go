@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"encoding/json"
+	"errors"
 	"net/http"
 	"net/http/httptest"
 	"testing"
@@ -11,11 +12,14 @@ import (
 
 	"gin_demo/internal/domain/service"
 	"gin_demo/internal/repository"
+	"gin_demo/internal/response"
 	"gin_demo/pkg/auth"
+	"gin_demo/pkg/tenant"
 
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockUserService 是 UserService 的 mock 实现
@@ -58,30 +62,96 @@ func (m *MockUserService) DeleteUser(ctx context.Context, userID int64) error {
 	return args.Error(0)
 }
 
-func (m *MockUserService) ListUsers(ctx context.Context, limit, offset int32) ([]repository.User, int64, error) {
-	args := m.Called(ctx, limit, offset)
+func (m *MockUserService) GetUserByIDAnyStatus(ctx context.Context, userID int64) (repository.User, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(repository.User), args.Error(1)
+}
+
+func (m *MockUserService) SuspendUser(ctx context.Context, userID int64, reason string) (repository.User, error) {
+	args := m.Called(ctx, userID, reason)
+	return args.Get(0).(repository.User), args.Error(1)
+}
+
+func (m *MockUserService) ReactivateUser(ctx context.Context, userID int64) (repository.User, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(repository.User), args.Error(1)
+}
+
+func (m *MockUserService) RestoreUser(ctx context.Context, userID int64) (repository.User, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(repository.User), args.Error(1)
+}
+
+func (m *MockUserService) ListUsers(ctx context.Context, filter repository.UserFilter, limit, offset int32) ([]repository.User, int64, error) {
+	args := m.Called(ctx, filter, limit, offset)
 	return args.Get(0).([]repository.User), args.Get(1).(int64), args.Error(2)
 }
 
-func (m *MockUserService) TransferUserData(ctx context.Context, fromUserID, toUserID int64) error {
-	args := m.Called(ctx, fromUserID, toUserID)
+func (m *MockUserService) ListUsersByCursor(ctx context.Context, filter repository.UserFilter, page repository.KeysetPage) (repository.KeysetResult[repository.User], error) {
+	args := m.Called(ctx, filter, page)
+	return args.Get(0).(repository.KeysetResult[repository.User]), args.Error(1)
+}
+
+func (m *MockUserService) GetUserPermissions(ctx context.Context, userID int64) ([]auth.Permission, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]auth.Permission), args.Error(1)
+}
+
+func (m *MockUserService) UpdateUserRole(ctx context.Context, input service.UpdateRoleInput) error {
+	args := m.Called(ctx, input)
 	return args.Error(0)
 }
 
-func (m *MockUserService) BatchUpdateUsers(ctx context.Context, updates []service.BatchUpdateInput) error {
-	args := m.Called(ctx, updates)
+func (m *MockUserService) GetTokenVersion(ctx context.Context, userID int64) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockUserService) RevokeAllSessions(ctx context.Context, userID int64) error {
+	args := m.Called(ctx, userID)
 	return args.Error(0)
 }
 
-// setupTestHandler 设置测试 Handler
-func setupTestHandler() (*Handler, *MockUserService, *auth.DefaultJWTManager) {
+func (m *MockUserService) TransferUserData(ctx context.Context, input service.TransferInput) (service.TransferReport, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(service.TransferReport), args.Error(1)
+}
+
+func (m *MockUserService) BatchUsers(ctx context.Context, input service.BatchInput) ([]service.BatchResult, error) {
+	args := m.Called(ctx, input)
+	results, _ := args.Get(0).([]service.BatchResult)
+	return results, args.Error(1)
+}
+
+// setupTestHandler 设置测试 Handler（所有用户均未启用两步验证）
+func setupTestHandler() (*Handler, *MockUserService, *auth.RBACJWTManager) {
+	handler, mockService, mockMFA, jwtManager := setupMFATestHandler()
+	mockMFA.On("IsEnabled", mock.Anything, mock.Anything).Return(false, nil).Maybe()
+
+	return handler, mockService, jwtManager
+}
+
+// setupMFATestHandler 设置测试 Handler（两步验证服务由调用方 mock）
+func setupMFATestHandler() (*Handler, *MockUserService, *MockMFAService, *auth.RBACJWTManager) {
 	mockService := new(MockUserService)
-	jwtManager := auth.NewDefaultJWTManager("test-secret", 1*time.Hour)
-	handler := NewHandler(mockService, jwtManager)
+	mockMFA := new(MockMFAService)
+	jwtManager := auth.NewRBACJWTManager("test-secret", 1*time.Hour)
+	refreshManager := auth.NewRefreshTokenManager(auth.NewMemoryRefreshTokenStore(), 24*time.Hour)
+	mfaTokens := auth.NewMFATokenManager(auth.NewHMACKeySet("test-secret"), 5*time.Minute)
+	loginGuard := auth.NewLoginGuard(
+		auth.NewMemoryLoginAttemptStore(),
+		auth.LockoutPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour, Window: time.Hour},
+		auth.LockoutPolicy{MaxAttempts: 10, BaseDelay: time.Minute, MaxDelay: time.Hour, Window: time.Hour},
+	)
+	// 注册成功后会发送验证邮件，邮件相关用例见 account_test.go
+	mockAccount := new(MockAccountService)
+	mockAccount.On("SendVerificationEmail", mock.Anything, mock.Anything).Return(nil).Maybe()
+	impersonation := auth.NewImpersonationTokenManager(auth.NewHMACKeySet("test-secret"), 15*time.Minute)
+	handler := NewHandler(mockService, mockMFA, mockAccount, new(MockOAuthService), jwtManager, refreshManager, mfaTokens, impersonation, loginGuard, response.NewCursorCodec([]byte("test-secret")))
 	
 	gin.SetMode(gin.TestMode)
 	
-	return handler, mockService, jwtManager
+	return handler, mockService, mockMFA, jwtManager
 }
 
 // TestHandler_Register 测试用户注册
@@ -200,12 +270,15 @@ func TestHandler_Login(t *testing.T) {
 			ID:        1,
 			Username:  "testuser",
 			Email:     "test@example.com",
+			Role:      "admin",
 			Status:    1,
 			CreatedAt: time.Now(),
 			UpdatedAt: time.Now(),
 		}
 		mockService.On("Login", mock.Anything, mock.AnythingOfType("service.LoginInput")).
 			Return(expectedUser, nil)
+		mockService.On("GetUserPermissions", mock.Anything, expectedUser.ID).
+			Return([]auth.Permission{auth.PermissionSystemMonitor}, nil)
 
 		// 执行 Handler
 		handler.Login(c)
@@ -221,12 +294,15 @@ func TestHandler_Login(t *testing.T) {
 		// 验证返回了 token
 		data := response["data"].(map[string]interface{})
 		assert.NotEmpty(t, data["token"])
+		assert.NotEmpty(t, data["refresh_token"])
 		
 		// 验证 token 有效性
 		token := data["token"].(string)
 		claims, err := jwtManager.ValidateToken(token)
 		assert.NoError(t, err)
 		assert.Equal(t, expectedUser.ID, claims.UserID)
+		assert.Equal(t, auth.RoleAdmin, claims.Role)
+		assert.Equal(t, []auth.Permission{auth.PermissionSystemMonitor}, claims.Permissions)
 		
 		mockService.AssertExpectations(t)
 	})
@@ -250,7 +326,8 @@ func TestHandler_Login(t *testing.T) {
 
 		handler.Login(c)
 
-		assert.Equal(t, http.StatusNotFound, w.Code)
+		// 与密码错误返回相同的错误，不暴露账户是否存在
+		assert.Equal(t, http.StatusBadRequest, w.Code)
 		mockService.AssertExpectations(t)
 	})
 
@@ -276,6 +353,85 @@ func TestHandler_Login(t *testing.T) {
 		assert.Equal(t, http.StatusBadRequest, w.Code)
 		mockService.AssertExpectations(t)
 	})
+
+	t.Run("用户不存在与密码错误的响应相同", func(t *testing.T) {
+		login := func(err error) *httptest.ResponseRecorder {
+			handler, mockService, _ := setupTestHandler()
+			mockService.On("Login", mock.Anything, mock.AnythingOfType("service.LoginInput")).
+				Return(repository.User{}, err)
+
+			body, _ := json.Marshal(LoginRequest{Email: "test@example.com", Password: "wrongpassword"})
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/users/login", bytes.NewBuffer(body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			handler.Login(c)
+			return w
+		}
+
+		notFound := login(service.ErrUserNotFound)
+		wrongPassword := login(service.ErrInvalidPassword)
+		assert.Equal(t, wrongPassword.Code, notFound.Code)
+		assert.JSONEq(t, wrongPassword.Body.String(), notFound.Body.String())
+	})
+
+	t.Run("连续失败后锁定账户", func(t *testing.T) {
+		handler, mockService, _ := setupTestHandler()
+
+		login := func() *httptest.ResponseRecorder {
+			body, _ := json.Marshal(LoginRequest{Email: "test@example.com", Password: "wrongpassword"})
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("POST", "/users/login", bytes.NewBuffer(body))
+			c.Request.Header.Set("Content-Type", "application/json")
+			handler.Login(c)
+			return w
+		}
+
+		mockService.On("Login", mock.Anything, mock.AnythingOfType("service.LoginInput")).
+			Return(repository.User{}, service.ErrInvalidPassword).Times(3)
+
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, http.StatusBadRequest, login().Code)
+		}
+
+		// 锁定期间不再校验密码
+		w := login()
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+		assert.Equal(t, "60", w.Header().Get("Retry-After"))
+
+		var resp map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, float64(response.CodeAccountLocked), resp["code"])
+
+		mockService.AssertExpectations(t)
+	})
+}
+
+// TestHandler_UnlockLogin 测试管理员解除登录锁定
+func TestHandler_UnlockLogin(t *testing.T) {
+	handler, mockService, _ := setupTestHandler()
+	ctx := context.Background()
+
+	// 先让账户进入锁定状态
+	for i := 0; i < 3; i++ {
+		_ = handler.loginGuard.RecordFailure(ctx, "test@example.com", "")
+	}
+	require.ErrorIs(t, handler.loginGuard.Check(ctx, "test@example.com", ""), auth.ErrLoginLocked)
+
+	mockService.On("GetUserByID", mock.Anything, int64(1)).
+		Return(repository.User{ID: 1, Email: "test@example.com"}, nil)
+
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("DELETE", "/users/1/lockout", nil)
+	c.Params = gin.Params{{Key: "id", Value: "1"}}
+
+	handler.UnlockLogin(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, handler.loginGuard.Check(ctx, "test@example.com", ""))
+	mockService.AssertExpectations(t)
 }
 
 // TestHandler_GetProfile 测试获取当前用户信息
@@ -285,7 +441,7 @@ func TestHandler_GetProfile(t *testing.T) {
 	t.Run("成功获取个人信息", func(t *testing.T) {
 		// 生成测试 Token
 		userID := int64(1)
-		token, _ := jwtManager.GenerateToken(userID)
+		token, _ := jwtManager.GenerateToken(userID, auth.RoleUser)
 
 		w := httptest.NewRecorder()
 		c, _ := gin.CreateTestContext(w)
@@ -329,7 +485,7 @@ func TestHandler_UpdateProfile(t *testing.T) {
 
 	t.Run("成功更新个人信息", func(t *testing.T) {
 		userID := int64(1)
-		token, _ := jwtManager.GenerateToken(userID)
+		token, _ := jwtManager.GenerateToken(userID, auth.RoleUser)
 
 		reqBody := UpdateUserRequest{
 			Username: "newusername",
@@ -361,7 +517,7 @@ func TestHandler_ChangePassword(t *testing.T) {
 
 	t.Run("成功修改密码", func(t *testing.T) {
 		userID := int64(1)
-		token, _ := jwtManager.GenerateToken(userID)
+		token, _ := jwtManager.GenerateToken(userID, auth.RoleUser)
 
 		reqBody := ChangePasswordRequest{
 			OldPassword: "oldpassword",
@@ -389,7 +545,7 @@ func TestHandler_ChangePassword(t *testing.T) {
 		handler, mockService, jwtManager := setupTestHandler()
 		
 		userID := int64(1)
-		token, _ := jwtManager.GenerateToken(userID)
+		token, _ := jwtManager.GenerateToken(userID, auth.RoleUser)
 
 		reqBody := ChangePasswordRequest{
 			OldPassword: "wrongpassword",
@@ -481,7 +637,7 @@ func TestHandler_ListUsers(t *testing.T) {
 			{ID: 2, Username: "user2", Email: "user2@example.com"},
 			{ID: 3, Username: "user3", Email: "user3@example.com"},
 		}
-		mockService.On("ListUsers", mock.Anything, int32(10), int32(0)).
+		mockService.On("ListUsers", mock.Anything, repository.UserFilter{}, int32(10), int32(0)).
 			Return(expectedUsers, int64(100), nil)
 
 		handler.ListUsers(c)
@@ -501,7 +657,7 @@ func TestHandler_ListUsers(t *testing.T) {
 		c, _ := gin.CreateTestContext(w)
 		c.Request = httptest.NewRequest("GET", "/users?page=2&size=20", nil)
 
-		mockService.On("ListUsers", mock.Anything, int32(20), int32(20)).
+		mockService.On("ListUsers", mock.Anything, repository.UserFilter{}, int32(20), int32(20)).
 			Return([]repository.User{}, int64(100), nil)
 
 		handler.ListUsers(c)
@@ -509,6 +665,119 @@ func TestHandler_ListUsers(t *testing.T) {
 		assert.Equal(t, http.StatusOK, w.Code)
 		mockService.AssertExpectations(t)
 	})
+
+	t.Run("过滤和排序参数", func(t *testing.T) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET",
+			"/users?status=2&status=4&role=admin&created_from=2026-01-01T00:00:00Z&q=alice&sort=username&order=asc", nil)
+
+		mockService.On("ListUsers", mock.Anything, repository.UserFilter{
+			Statuses:    []int16{2, 4},
+			Roles:       []string{"admin"},
+			CreatedFrom: time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC),
+			Query:       "alice",
+			Sort:        repository.UserSortUsername,
+			Order:       repository.SortAsc,
+		}, int32(10), int32(0)).Return([]repository.User{}, int64(0), nil).Once()
+
+		handler.ListUsers(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("不支持的排序字段", func(t *testing.T) {
+		for _, query := range []string{"sort=password", "order=random", "status=9", "created_from=yesterday"} {
+			w := httptest.NewRecorder()
+			c, _ := gin.CreateTestContext(w)
+			c.Request = httptest.NewRequest("GET", "/users?"+query, nil)
+
+			handler.ListUsers(c)
+
+			assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		}
+	})
+}
+
+// TestHandler_ListUsers_Cursor 测试用户列表的游标分页
+func TestHandler_ListUsers_Cursor(t *testing.T) {
+	handler, mockService, _ := setupTestHandler()
+	cursors := response.NewCursorCodec([]byte("test-secret"))
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	listUsers := func(query string) (*httptest.ResponseRecorder, response.CursorPaginationResponse) {
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("GET", "/users?"+query, nil)
+		handler.ListUsers(c)
+
+		var resp struct {
+			Data struct {
+				Items      []Response                        `json:"items"`
+				Pagination response.CursorPaginationResponse `json:"pagination"`
+			} `json:"data"`
+		}
+		_ = json.Unmarshal(w.Body.Bytes(), &resp)
+		return w, resp.Data.Pagination
+	}
+
+	t.Run("第一页返回下一页游标", func(t *testing.T) {
+		mockService.On("ListUsersByCursor", mock.Anything, repository.UserFilter{}, repository.KeysetPage{Limit: 2}).
+			Return(repository.KeysetResult[repository.User]{
+				Items:   []repository.User{{ID: 9, CreatedAt: at.Add(time.Hour)}, {ID: 8, CreatedAt: at}},
+				HasNext: true,
+			}, nil).Once()
+
+		w, pagination := listUsers("cursor=&size=2")
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, 2, pagination.PageSize)
+		assert.Empty(t, pagination.PrevCursor)
+		cursor, err := cursors.Decode("users:desc", pagination.NextCursor)
+		require.NoError(t, err)
+		assert.Equal(t, response.Cursor{CreatedAt: at, ID: 8}, cursor)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("使用上一页游标", func(t *testing.T) {
+		token := cursors.Encode("users:desc", response.Cursor{CreatedAt: at, ID: 8, Backward: true})
+		mockService.On("ListUsersByCursor", mock.Anything, repository.UserFilter{},
+			repository.KeysetPage{HasCursor: true, CreatedAt: at, ID: 8, Backward: true, Limit: 10}).
+			Return(repository.KeysetResult[repository.User]{
+				Items:   []repository.User{{ID: 10, CreatedAt: at.Add(time.Hour)}},
+				HasNext: true,
+			}, nil).Once()
+
+		w, pagination := listUsers("cursor=" + token)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.NotEmpty(t, pagination.NextCursor)
+		assert.Empty(t, pagination.PrevCursor)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("无效的游标", func(t *testing.T) {
+		token := cursors.Encode("users:desc", response.Cursor{CreatedAt: at, ID: 8})
+		forged := response.NewCursorCodec([]byte("other-secret")).Encode("users:desc", response.Cursor{CreatedAt: at, ID: 8})
+
+		for _, query := range []string{
+			"cursor=not-a-cursor",
+			"cursor=" + forged,
+			"cursor=" + token + "&order=asc", // 游标绑定排序方向
+		} {
+			w, _ := listUsers(query)
+			assert.Equal(t, http.StatusBadRequest, w.Code, query)
+		}
+	})
+
+	t.Run("不支持按其他字段排序", func(t *testing.T) {
+		mockService.On("ListUsersByCursor", mock.Anything, repository.UserFilter{Sort: repository.UserSortUsername}, mock.Anything).
+			Return(repository.KeysetResult[repository.User]{}, service.ErrInvalidInput).Once()
+
+		w, _ := listUsers("cursor=&sort=username")
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
 }
 
 // TestHandler_DeleteUser 测试删除用户
@@ -572,3 +841,238 @@ func TestHandler_UpdateUser(t *testing.T) {
 		mockService.AssertExpectations(t)
 	})
 }
+
+// TestHandler_UpdateUserRole 测试更新用户角色
+func TestHandler_UpdateUserRole(t *testing.T) {
+	t.Run("成功更新角色", func(t *testing.T) {
+		handler, mockService, _ := setupTestHandler()
+
+		body, _ := json.Marshal(UpdateRoleRequest{
+			Role:        "admin",
+			Permissions: []string{"system:monitor"},
+		})
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("PUT", "/users/2/role", bytes.NewBuffer(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{{Key: "id", Value: "2"}}
+
+		mockService.On("UpdateUserRole", mock.Anything, service.UpdateRoleInput{
+			UserID:      2,
+			Role:        auth.RoleAdmin,
+			Permissions: []auth.Permission{auth.PermissionSystemMonitor},
+		}).Return(nil)
+
+		handler.UpdateUserRole(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("无效角色", func(t *testing.T) {
+		handler, _, _ := setupTestHandler()
+
+		body, _ := json.Marshal(UpdateRoleRequest{Role: "root"})
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("PUT", "/users/2/role", bytes.NewBuffer(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Params = gin.Params{{Key: "id", Value: "2"}}
+
+		handler.UpdateUserRole(c)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
+
+// TestHandler_RefreshToken 测试刷新 Token
+func TestHandler_RefreshToken(t *testing.T) {
+	refresh := func(handler *Handler, refreshToken string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(RefreshTokenRequest{RefreshToken: refreshToken})
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/auth/refresh", bytes.NewBuffer(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		handler.RefreshToken(c)
+		return w
+	}
+
+	t.Run("成功刷新并轮换", func(t *testing.T) {
+		handler, mockService, jwtManager := setupTestHandler()
+
+		refreshToken, err := handler.refreshManager.Issue(context.Background(), 1, 0)
+		assert.NoError(t, err)
+
+		mockService.On("GetUserByID", mock.Anything, int64(1)).
+			Return(repository.User{ID: 1, Role: "moderator", Status: 1}, nil)
+		mockService.On("GetUserPermissions", mock.Anything, int64(1)).
+			Return([]auth.Permission{}, nil)
+
+		w := refresh(handler, refreshToken)
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var resp struct {
+			Data TokenResponse `json:"data"`
+		}
+		assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.NotEmpty(t, resp.Data.RefreshToken)
+		assert.NotEqual(t, refreshToken, resp.Data.RefreshToken)
+
+		claims, err := jwtManager.ValidateToken(resp.Data.Token)
+		assert.NoError(t, err)
+		assert.Equal(t, auth.RoleModerator, claims.Role)
+		assert.NotEmpty(t, claims.ID)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("重复使用被拒绝", func(t *testing.T) {
+		handler, mockService, _ := setupTestHandler()
+
+		refreshToken, err := handler.refreshManager.Issue(context.Background(), 1, 0)
+		assert.NoError(t, err)
+
+		mockService.On("GetUserByID", mock.Anything, int64(1)).
+			Return(repository.User{ID: 1, Status: 1}, nil)
+		mockService.On("GetUserPermissions", mock.Anything, int64(1)).
+			Return([]auth.Permission{}, nil)
+
+		assert.Equal(t, http.StatusOK, refresh(handler, refreshToken).Code)
+		assert.Equal(t, http.StatusUnauthorized, refresh(handler, refreshToken).Code)
+	})
+
+	t.Run("Token 版本号变化后拒绝", func(t *testing.T) {
+		handler, mockService, _ := setupTestHandler()
+
+		refreshToken, err := handler.refreshManager.Issue(context.Background(), 1, 0)
+		assert.NoError(t, err)
+
+		// 用户已修改密码或登出所有设备
+		mockService.On("GetUserByID", mock.Anything, int64(1)).
+			Return(repository.User{ID: 1, Status: 1, TokenVersion: 1}, nil)
+
+		w := refresh(handler, refreshToken)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		mockService.AssertNotCalled(t, "GetUserPermissions", mock.Anything, mock.Anything)
+	})
+
+	t.Run("使用签发时的租户加载用户", func(t *testing.T) {
+		handler, mockService, _ := setupTestHandler()
+
+		refreshToken, err := handler.refreshManager.Issue(tenant.WithID(context.Background(), 2), 1, 0)
+		assert.NoError(t, err)
+
+		inTenant := mock.MatchedBy(func(ctx context.Context) bool { return tenant.ID(ctx) == 2 })
+		mockService.On("GetUserByID", inTenant, int64(1)).
+			Return(repository.User{ID: 1, TenantID: 2, Status: 1}, nil)
+		mockService.On("GetUserPermissions", inTenant, int64(1)).
+			Return([]auth.Permission{}, nil)
+
+		assert.Equal(t, http.StatusOK, refresh(handler, refreshToken).Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("请求的租户与签发时不一致时拒绝", func(t *testing.T) {
+		handler, mockService, _ := setupTestHandler()
+
+		refreshToken, err := handler.refreshManager.Issue(tenant.WithID(context.Background(), 2), 1, 0)
+		assert.NoError(t, err)
+
+		body, _ := json.Marshal(RefreshTokenRequest{RefreshToken: refreshToken})
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/auth/refresh", bytes.NewBuffer(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Request = c.Request.WithContext(tenant.WithID(c.Request.Context(), 3))
+		handler.RefreshToken(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		mockService.AssertNotCalled(t, "GetUserByID", mock.Anything, mock.Anything)
+
+		// 令牌没有被消耗，在所属租户内仍然可以刷新
+		inTenant := mock.MatchedBy(func(ctx context.Context) bool { return tenant.ID(ctx) == 2 })
+		mockService.On("GetUserByID", inTenant, int64(1)).
+			Return(repository.User{ID: 1, TenantID: 2, Status: 1}, nil)
+		mockService.On("GetUserPermissions", inTenant, int64(1)).
+			Return([]auth.Permission{}, nil)
+		assert.Equal(t, http.StatusOK, refresh(handler, refreshToken).Code)
+	})
+
+	t.Run("加载用户失败时不吊销会话", func(t *testing.T) {
+		handler, mockService, _ := setupTestHandler()
+
+		refreshToken, err := handler.refreshManager.Issue(context.Background(), 1, 0)
+		assert.NoError(t, err)
+
+		mockService.On("GetUserByID", mock.Anything, int64(1)).
+			Return(repository.User{}, errors.New("connection refused"))
+
+		w := refresh(handler, refreshToken)
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+		// 令牌家族没有被吊销
+		_, err = handler.refreshManager.Lookup(context.Background(), refreshToken)
+		assert.NoError(t, err)
+	})
+
+	t.Run("无效 Token", func(t *testing.T) {
+		handler, _, _ := setupTestHandler()
+
+		w := refresh(handler, "invalid")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+// TestHandler_Logout 测试登出
+func TestHandler_Logout(t *testing.T) {
+	handler, _, _ := setupTestHandler()
+
+	refreshToken, err := handler.refreshManager.Issue(context.Background(), 1, 0)
+	assert.NoError(t, err)
+
+	body, _ := json.Marshal(RefreshTokenRequest{RefreshToken: refreshToken})
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest("POST", "/auth/logout", bytes.NewBuffer(body))
+	c.Request.Header.Set("Content-Type", "application/json")
+
+	handler.Logout(c)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// 登出后 Refresh Token 不可再用
+	_, _, err = handler.refreshManager.Rotate(context.Background(), refreshToken)
+	assert.ErrorIs(t, err, auth.ErrRefreshTokenInvalid)
+}
+
+// TestHandler_RevokeSessions 测试登出所有设备
+func TestHandler_RevokeSessions(t *testing.T) {
+	t.Run("成功吊销", func(t *testing.T) {
+		handler, mockService, _ := setupTestHandler()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("DELETE", "/users/me/sessions", nil)
+		c.Set("user_id", int64(1))
+
+		mockService.On("RevokeAllSessions", mock.Anything, int64(1)).Return(nil)
+
+		handler.RevokeSessions(c)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("未认证", func(t *testing.T) {
+		handler, _, _ := setupTestHandler()
+
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("DELETE", "/users/me/sessions", nil)
+
+		handler.RevokeSessions(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
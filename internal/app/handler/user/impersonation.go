@@ -0,0 +1,85 @@
+package user
+
+import (
+	"log/slog"
+
+	"gin_demo/internal/app/middleware"
+	"gin_demo/internal/repository"
+	"gin_demo/internal/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Impersonate 模拟登录
+//
+// @Summary 模拟登录（超级管理员）
+// @Description 签发以目标用户身份访问的短期 Token（不签发 Refresh Token），用于排查用户问题。Token 中同时记录实际操作的管理员，期间的所有请求记录审计日志，修改密码、删除用户等破坏性操作被禁止
+// @Tags 管理后台
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Param request body ImpersonateRequest true "模拟登录原因"
+// @Success 200 {object} response.Response{data=ImpersonationResponse} "签发成功"
+// @Failure 400 {object} response.Response "参数错误或用户状态不正常"
+// @Failure 401 {object} response.Response "未认证"
+// @Failure 403 {object} response.Response "权限不足或目标用户级别不低于当前用户"
+// @Failure 404 {object} response.Response "用户不存在"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /admin/users/{id}/impersonate [post]
+func (h *Handler) Impersonate(c *gin.Context) {
+	ctx := c.Request.Context()
+	actorID := middleware.GetUserID(c)
+
+	var req ImpersonateRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "参数错误", err))
+		return
+	}
+
+	// 目标用户已由 RequirePolicy 加载并校验（级别必须低于当前用户）
+	resource := middleware.GetResource(c)
+	if resource == nil {
+		response.Error(c, response.ErrNotFound)
+		return
+	}
+
+	user, err := h.userService.GetUserByID(ctx, resource.ID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+	if user.Status != repository.UserStatusActive {
+		response.Error(c, response.New(response.CodeInvalidParams, "只能模拟正常状态的用户"))
+		return
+	}
+
+	permissions, err := h.userService.GetUserPermissions(ctx, user.ID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	token, err := h.impersonation.GenerateToken(actorID, user.TenantID, user.ID, user.TokenVersion, userRole(user), permissions...)
+	if err != nil {
+		slog.ErrorContext(ctx, "Generate impersonation token failed", "actor_id", actorID, "user_id", user.ID, "error", err)
+		response.Error(c, response.NewWithError(response.CodeInternalError, "生成 Token 失败", err))
+		return
+	}
+
+	slog.InfoContext(ctx, "Impersonation started",
+		"audit", "impersonation",
+		"actor_id", actorID,
+		"user_id", user.ID,
+		"tenant_id", user.TenantID,
+		"reason", req.Reason,
+		"ip", c.ClientIP(),
+	)
+
+	response.Success(c, ImpersonationResponse{
+		User:      toResponse(user),
+		Token:     token,
+		ExpiresIn: int64(h.impersonation.Expiration().Seconds()),
+		ActorID:   actorID,
+	})
+}
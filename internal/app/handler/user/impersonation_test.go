@@ -0,0 +1,109 @@
+package user
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gin_demo/internal/app/middleware"
+	"gin_demo/internal/repository"
+	"gin_demo/pkg/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHandler_Impersonate 测试模拟登录
+func TestHandler_Impersonate(t *testing.T) {
+	handler, mockService, jwtManager := setupTestHandler()
+	mockService.On("GetUserByID", mock.Anything, int64(2)).Return(repository.User{
+		ID: 2, TenantID: 1, Username: "alice", Role: string(auth.RoleUser), Status: repository.UserStatusActive, TokenVersion: 3,
+	}, nil)
+	mockService.On("GetUserByID", mock.Anything, int64(3)).Return(repository.User{
+		ID: 3, Username: "root", Role: string(auth.RoleSuperAdmin), Status: repository.UserStatusActive,
+	}, nil)
+	mockService.On("GetUserPermissions", mock.Anything, int64(2)).Return([]auth.Permission{auth.PermissionContentAudit}, nil)
+
+	// 与实际路由一致：超级管理员 + 资源级校验
+	rbac := middleware.NewRBACMiddleware(jwtManager, nil)
+	router := gin.New()
+	router.POST("/admin/users/:id/impersonate", rbac.Handle(), middleware.RequireSuperAdmin(), middleware.DenyImpersonation(),
+		middleware.RequirePolicy(auth.ActionImpersonate, handler.ResolveUser), handler.Impersonate)
+	router.PUT("/users/me/password", rbac.Handle(), middleware.DenyImpersonation(), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+	router.GET("/users/me", rbac.Handle(), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{
+			"user_id":  middleware.GetUserID(c),
+			"actor_id": middleware.GetActorID(c),
+			"role":     middleware.GetUserRole(c),
+		})
+	})
+
+	do := func(method, path, token string, body interface{}) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(body)
+		req := httptest.NewRequest(method, path, bytes.NewReader(data))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	adminToken, err := jwtManager.GenerateToken(1, auth.RoleSuperAdmin)
+	require.NoError(t, err)
+
+	t.Run("签发模拟登录 Token", func(t *testing.T) {
+		w := do(http.MethodPost, "/admin/users/2/impersonate", adminToken, ImpersonateRequest{Reason: "TICKET-42"})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var resp struct {
+			Data ImpersonationResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, int64(2), resp.Data.User.ID)
+		assert.Equal(t, int64(1), resp.Data.ActorID)
+		assert.Equal(t, int64((15 * time.Minute).Seconds()), resp.Data.ExpiresIn)
+
+		claims, err := jwtManager.ValidateToken(resp.Data.Token)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), claims.UserID)
+		assert.Equal(t, int64(1), claims.ActorID)
+		assert.Equal(t, int64(3), claims.Version)
+		assert.Equal(t, auth.RoleUser, claims.Role)
+		assert.Equal(t, []auth.Permission{auth.PermissionContentAudit}, claims.Permissions)
+
+		// 以目标用户身份访问，同时可以获取实际操作者
+		w = do(http.MethodGet, "/users/me", resp.Data.Token, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"user_id": 2, "actor_id": 1, "role": "user"}`, w.Body.String())
+
+		// 破坏性操作被禁止
+		w = do(http.MethodPut, "/users/me/password", resp.Data.Token, nil)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		w = do(http.MethodPut, "/users/me/password", adminToken, nil)
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("缺少原因", func(t *testing.T) {
+		w := do(http.MethodPost, "/admin/users/2/impersonate", adminToken, ImpersonateRequest{})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("不能模拟同级用户", func(t *testing.T) {
+		w := do(http.MethodPost, "/admin/users/3/impersonate", adminToken, ImpersonateRequest{Reason: "TICKET-42"})
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("管理员不能模拟登录", func(t *testing.T) {
+		token, err := jwtManager.GenerateToken(4, auth.RoleAdmin)
+		require.NoError(t, err)
+		w := do(http.MethodPost, "/admin/users/2/impersonate", token, ImpersonateRequest{Reason: "TICKET-42"})
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
@@ -0,0 +1,200 @@
+package user
+
+import (
+	"log/slog"
+
+	"gin_demo/internal/domain/service"
+	"gin_demo/internal/response"
+	"gin_demo/pkg/auth"
+
+	"github.com/gin-gonic/gin"
+)
+
+// SuspendUser 停用用户
+//
+// @Summary 停用用户
+// @Description 管理员停用指定用户（需要说明原因），已签发的 Token 立即失效，停用期间不能登录
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Param request body SuspendUserRequest true "停用原因"
+// @Success 200 {object} response.Response{data=Response} "停用成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未认证"
+// @Failure 403 {object} response.Response "权限不足或目标用户级别不低于当前用户"
+// @Failure 404 {object} response.Response "用户不存在"
+// @Failure 409 {object} response.Response "用户当前状态不允许停用（如已删除）"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /users/{id}/suspend [post]
+func (h *Handler) SuspendUser(c *gin.Context) {
+	var idReq IDRequest
+	if err := c.ShouldBindUri(&idReq); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "无效的用户ID", err))
+		return
+	}
+
+	var req SuspendUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "参数错误", err))
+		return
+	}
+
+	user, err := h.userService.SuspendUser(c.Request.Context(), idReq.ID, req.Reason)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Suspend user failed", "user_id", idReq.ID, "error", err)
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, toResponse(user))
+}
+
+// ReactivateUser 重新启用用户
+//
+// @Summary 重新启用用户
+// @Description 管理员重新启用已停用的用户（已删除的用户使用恢复接口）
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} response.Response{data=Response} "启用成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未认证"
+// @Failure 403 {object} response.Response "权限不足或目标用户级别不低于当前用户"
+// @Failure 404 {object} response.Response "用户不存在"
+// @Failure 409 {object} response.Response "用户未被停用"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /users/{id}/reactivate [post]
+func (h *Handler) ReactivateUser(c *gin.Context) {
+	var req IDRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "无效的用户ID", err))
+		return
+	}
+
+	user, err := h.userService.ReactivateUser(c.Request.Context(), req.ID)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Reactivate user failed", "user_id", req.ID, "error", err)
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, toResponse(user))
+}
+
+// RestoreUser 恢复已删除的用户
+//
+// @Summary 恢复已删除的用户
+// @Description 超级管理员恢复保留期内的已删除用户（恢复为正常状态，用户需要重新登录）
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "用户ID"
+// @Success 200 {object} response.Response{data=Response} "恢复成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未认证"
+// @Failure 403 {object} response.Response "权限不足或目标用户级别不低于当前用户"
+// @Failure 404 {object} response.Response "用户不存在（或已被永久删除）"
+// @Failure 409 {object} response.Response "用户未被删除"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /users/{id}/restore [post]
+func (h *Handler) RestoreUser(c *gin.Context) {
+	var req IDRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "无效的用户ID", err))
+		return
+	}
+
+	user, err := h.userService.RestoreUser(c.Request.Context(), req.ID)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Restore user failed", "user_id", req.ID, "error", err)
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, toResponse(user))
+}
+
+// MergeUser 合并账户
+//
+// @Summary 合并账户
+// @Description 超级管理员把用户（路径参数）合并到另一个用户：第三方账户关联和未吊销的 API Key 转移给目标用户，源用户标记为已合并并吊销所有 Token，两步验证和历史密码被删除。目标用户的角色和权限不变。两个用户关联了同一提供方的第三方账户时拒绝合并；dry_run 为 true 时只返回将要转移的数据
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param id path int true "源用户ID"
+// @Param request body MergeUserRequest true "目标用户和是否预演"
+// @Success 200 {object} response.Response{data=MergeReportResponse} "合并成功（或预演结果）"
+// @Failure 400 {object} response.Response "参数错误（如合并到自己）"
+// @Failure 401 {object} response.Response "未认证"
+// @Failure 403 {object} response.Response "需要超级管理员，不能合并其他超级管理员"
+// @Failure 404 {object} response.Response "用户不存在"
+// @Failure 409 {object} response.Response "用户当前状态不允许合并或第三方账户冲突"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /users/{id}/merge [post]
+func (h *Handler) MergeUser(c *gin.Context) {
+	var idReq IDRequest
+	if err := c.ShouldBindUri(&idReq); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "无效的用户ID", err))
+		return
+	}
+
+	var req MergeUserRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "参数错误", err))
+		return
+	}
+
+	report, err := h.userService.TransferUserData(c.Request.Context(), service.TransferInput{
+		FromUserID: idReq.ID,
+		ToUserID:   req.Into,
+		DryRun:     req.DryRun,
+	})
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Merge user failed", "user_id", idReq.ID, "into", req.Into, "error", err)
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, MergeReportResponse{
+		FromUserID:            report.FromUserID,
+		IntoUserID:            report.ToUserID,
+		DryRun:                report.DryRun,
+		Identities:            nonNil(report.Identities),
+		ConflictingIdentities: nonNil(report.ConflictingIdentities),
+		APIKeys:               nonNil(report.APIKeys),
+	})
+}
+
+// nonNil 空列表序列化为 [] 而不是 null
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// ResolveUserAnyStatus 按路径参数加载目标用户，包含停用和已删除的用户（变更用户状态时 RequirePolicy 的资源解析器）
+func (h *Handler) ResolveUserAnyStatus(c *gin.Context) (*auth.Resource, error) {
+	var req IDRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		return nil, response.NewWithError(response.CodeInvalidParams, "无效的用户ID", err)
+	}
+
+	user, err := h.userService.GetUserByIDAnyStatus(c.Request.Context(), req.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &auth.Resource{
+		Type:       auth.ResourceUser,
+		ID:         user.ID,
+		OwnerID:    user.ID,
+		Attributes: map[string]any{auth.AttrRole: userRole(user)},
+	}, nil
+}
@@ -0,0 +1,145 @@
+package user
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gin_demo/internal/app/middleware"
+	"gin_demo/internal/domain/service"
+	"gin_demo/internal/repository"
+	"gin_demo/pkg/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// TestHandler_Lifecycle 测试停用、重新启用和恢复用户
+func TestHandler_Lifecycle(t *testing.T) {
+	handler, mockService, jwtManager := setupTestHandler()
+	suspended := repository.User{ID: 2, Username: "alice", Role: string(auth.RoleUser), Status: repository.UserStatusSuspended}
+	deleted := repository.User{ID: 4, Username: "bob", Role: string(auth.RoleUser), Status: repository.UserStatusDeleted}
+	mockService.On("GetUserByIDAnyStatus", mock.Anything, int64(2)).Return(suspended, nil)
+	mockService.On("GetUserByIDAnyStatus", mock.Anything, int64(3)).Return(repository.User{
+		ID: 3, Username: "other-admin", Role: string(auth.RoleAdmin), Status: repository.UserStatusActive,
+	}, nil)
+	mockService.On("GetUserByIDAnyStatus", mock.Anything, int64(4)).Return(deleted, nil)
+
+	// 与实际路由一致：权限 + 资源级校验（包含停用和已删除的用户）
+	rbac := middleware.NewRBACMiddleware(jwtManager, nil)
+	router := gin.New()
+	router.POST("/users/:id/suspend", rbac.Handle(), middleware.RequirePermission(auth.PermissionUserWrite),
+		middleware.RequirePolicy(auth.ActionSuspend, handler.ResolveUserAnyStatus), handler.SuspendUser)
+	router.POST("/users/:id/reactivate", rbac.Handle(), middleware.RequirePermission(auth.PermissionUserWrite),
+		middleware.RequirePolicy(auth.ActionSuspend, handler.ResolveUserAnyStatus), handler.ReactivateUser)
+	router.POST("/users/:id/restore", rbac.Handle(), middleware.RequireSuperAdmin(),
+		middleware.RequirePolicy(auth.ActionDelete, handler.ResolveUserAnyStatus), handler.RestoreUser)
+	router.POST("/users/:id/merge", rbac.Handle(), middleware.RequireSuperAdmin(),
+		middleware.RequirePolicy(auth.ActionDelete, handler.ResolveUserAnyStatus), handler.MergeUser)
+
+	do := func(path, token string, body interface{}) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(body)
+		req := httptest.NewRequest(http.MethodPost, path, bytes.NewReader(data))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	adminToken, err := jwtManager.GenerateToken(1, auth.RoleAdmin)
+	require.NoError(t, err)
+	superAdminToken, err := jwtManager.GenerateToken(9, auth.RoleSuperAdmin)
+	require.NoError(t, err)
+
+	t.Run("停用用户", func(t *testing.T) {
+		mockService.On("SuspendUser", mock.Anything, int64(2), "spam").Return(repository.User{
+			ID: 2, Username: "alice", Status: repository.UserStatusSuspended,
+		}, nil).Once()
+
+		w := do("/users/2/suspend", adminToken, SuspendUserRequest{Reason: "spam"})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	})
+
+	t.Run("停用需要原因", func(t *testing.T) {
+		w := do("/users/2/suspend", adminToken, SuspendUserRequest{})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("不能停用同级管理员", func(t *testing.T) {
+		w := do("/users/3/suspend", adminToken, SuspendUserRequest{Reason: "spam"})
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		mockService.AssertNotCalled(t, "SuspendUser", mock.Anything, int64(3), mock.Anything)
+	})
+
+	t.Run("重新启用", func(t *testing.T) {
+		mockService.On("ReactivateUser", mock.Anything, int64(2)).Return(repository.User{
+			ID: 2, Username: "alice", Status: repository.UserStatusActive,
+		}, nil).Once()
+
+		w := do("/users/2/reactivate", adminToken, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var resp struct {
+			Data Response `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, repository.UserStatusActive, resp.Data.Status)
+	})
+
+	t.Run("状态不允许时返回 409", func(t *testing.T) {
+		mockService.On("ReactivateUser", mock.Anything, int64(4)).Return(repository.User{}, service.ErrInvalidUserTransition).Once()
+
+		w := do("/users/4/reactivate", adminToken, nil)
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("恢复已删除的用户（仅超级管理员）", func(t *testing.T) {
+		w := do("/users/4/restore", adminToken, nil)
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		mockService.On("RestoreUser", mock.Anything, int64(4)).Return(repository.User{
+			ID: 4, Username: "bob", Status: repository.UserStatusActive,
+		}, nil).Once()
+
+		w = do("/users/4/restore", superAdminToken, nil)
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+	})
+
+	t.Run("预演合并账户", func(t *testing.T) {
+		mockService.On("TransferUserData", mock.Anything, service.TransferInput{FromUserID: 2, ToUserID: 5, DryRun: true}).
+			Return(service.TransferReport{FromUserID: 2, ToUserID: 5, DryRun: true, Identities: []string{"github"}}, nil).Once()
+
+		w := do("/users/2/merge", superAdminToken, MergeUserRequest{Into: 5, DryRun: true})
+		require.Equal(t, http.StatusOK, w.Code, w.Body.String())
+
+		var resp struct {
+			Data MergeReportResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.True(t, resp.Data.DryRun)
+		assert.Equal(t, int64(5), resp.Data.IntoUserID)
+		assert.Equal(t, []string{"github"}, resp.Data.Identities)
+		assert.Equal(t, []string{}, resp.Data.APIKeys)
+	})
+
+	t.Run("合并账户冲突时返回 409", func(t *testing.T) {
+		mockService.On("TransferUserData", mock.Anything, service.TransferInput{FromUserID: 2, ToUserID: 5}).
+			Return(service.TransferReport{}, service.ErrMergeConflict).Once()
+
+		w := do("/users/2/merge", superAdminToken, MergeUserRequest{Into: 5})
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("合并账户需要目标用户（仅超级管理员）", func(t *testing.T) {
+		w := do("/users/2/merge", adminToken, MergeUserRequest{Into: 5})
+		assert.Equal(t, http.StatusForbidden, w.Code)
+
+		w = do("/users/2/merge", superAdminToken, MergeUserRequest{})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
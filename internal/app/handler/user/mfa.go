@@ -0,0 +1,337 @@
+package user
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+
+	"gin_demo/internal/app/middleware"
+	"gin_demo/internal/domain/service"
+	"gin_demo/internal/response"
+	"gin_demo/pkg/auth"
+	"gin_demo/pkg/metrics"
+	"gin_demo/pkg/tenant"
+
+	"github.com/gin-gonic/gin"
+)
+
+// LoginMFA 登录第二步（两步验证）
+//
+// @Summary 登录两步验证
+// @Description 使用登录返回的 mfa_token 和 TOTP 验证码（或恢复码）完成登录
+// @Tags 用户管理
+// @Accept json
+// @Produce json
+// @Param request body LoginMFARequest true "两步验证信息"
+// @Success 200 {object} response.Response{data=LoginResponse} "登录成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "mfa_token 无效或验证码错误"
+// @Failure 429 {object} response.Response "失败次数过多，暂时锁定"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /users/login/mfa [post]
+func (h *Handler) LoginMFA(c *gin.Context) {
+	var req LoginMFARequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "参数错误", err))
+		return
+	}
+
+	ctx := c.Request.Context()
+	clientIP := c.ClientIP()
+
+	// 1. 校验待验证令牌
+	claims, err := h.mfaTokens.ValidateToken(req.MFAToken)
+	if err != nil {
+		metrics.AuthFailures.WithLabelValues("invalid_mfa_token").Inc()
+		response.Error(c, response.New(response.CodeUnauthorized, "登录已过期，请重新登录"))
+		return
+	}
+
+	// 按第一步所属的租户加载用户（请求指定了其他租户时拒绝，与 RefreshToken 一致）
+	// 租户同时写回请求，登录锁定计数和之后签发的 Refresh Token 都使用该租户
+	tenantID := claims.TenantID
+	if tenantID == 0 {
+		tenantID = tenant.DefaultID
+	}
+	if current, ok := tenant.FromContext(ctx); ok && current != tenantID {
+		metrics.AuthFailures.WithLabelValues("tenant_mismatch").Inc()
+		response.Error(c, response.New(response.CodeUnauthorized, "登录已过期，请重新登录"))
+		return
+	}
+	ctx = tenant.WithID(ctx, tenantID)
+	c.Request = c.Request.WithContext(ctx)
+
+	user, err := h.userService.GetUserByID(ctx, claims.UserID)
+	if err != nil {
+		if errors.Is(err, service.ErrUserNotFound) {
+			response.Error(c, response.New(response.CodeUnauthorized, "用户不存在或已禁用"))
+			return
+		}
+		response.Error(c, err)
+		return
+	}
+
+	// 签发后修改过密码或登出过所有设备，待验证令牌同样作废
+	if user.TokenVersion != claims.Version {
+		metrics.AuthFailures.WithLabelValues("token_revoked").Inc()
+		response.Error(c, response.New(response.CodeUnauthorized, "登录已过期，请重新登录"))
+		return
+	}
+
+	// 2. 第二步同样受登录锁定保护（防止暴力猜测 6 位验证码）
+	if err := h.loginGuard.Check(ctx, user.Email, clientIP); err != nil {
+		if !errors.Is(err, auth.ErrLoginLocked) {
+			slog.ErrorContext(ctx, "Check login lockout failed", "user_id", user.ID, "ip", clientIP, "error", err)
+		} else {
+			slog.WarnContext(ctx, "MFA login rejected: locked", "user_id", user.ID, "ip", clientIP, "error", err)
+			metrics.AuthFailures.WithLabelValues("locked").Inc()
+			h.lockoutResponse(c, err)
+			return
+		}
+	}
+
+	// 3. 校验验证码
+	if err := h.mfaService.Verify(ctx, user.ID, req.Code); err != nil {
+		slog.WarnContext(ctx, "MFA login failed", "user_id", user.ID, "error", err)
+
+		if errors.Is(err, service.ErrInvalidMFACode) {
+			metrics.AuthFailures.WithLabelValues("invalid_mfa_code").Inc()
+			if lockErr := h.loginGuard.RecordFailure(ctx, user.Email, clientIP); lockErr != nil && !errors.Is(lockErr, auth.ErrLoginLocked) {
+				slog.ErrorContext(ctx, "Record login failure failed", "user_id", user.ID, "ip", clientIP, "error", lockErr)
+			}
+		}
+
+		response.Error(c, err)
+		return
+	}
+
+	h.completeLogin(c, user)
+}
+
+// GetMFAStatus 获取当前用户两步验证状态
+//
+// @Summary 获取两步验证状态
+// @Description 查询当前用户是否已启用两步验证及剩余恢复码数量
+// @Tags 两步验证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=MFAStatusResponse} "获取成功"
+// @Failure 401 {object} response.Response "未认证"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /users/me/mfa [get]
+func (h *Handler) GetMFAStatus(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Error(c, response.NewWithError(response.CodeUnauthorized, "未认证", nil))
+		return
+	}
+
+	status, err := h.mfaService.GetStatus(c.Request.Context(), userID)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "Get mfa status failed", "user_id", userID, "error", err)
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, MFAStatusResponse{
+		Enabled:                status.Enabled,
+		EnabledAt:              status.EnabledAt,
+		RecoveryCodesRemaining: status.RecoveryCodesRemaining,
+	})
+}
+
+// EnrollTOTP 开始绑定 TOTP
+//
+// @Summary 开始绑定 TOTP
+// @Description 生成新的 TOTP 密钥和 otpauth URI（需要调用确认接口后才生效）
+// @Tags 两步验证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=TOTPEnrollmentResponse} "生成成功"
+// @Failure 401 {object} response.Response "未认证"
+// @Failure 409 {object} response.Response "已启用两步验证"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /users/me/mfa/totp [post]
+func (h *Handler) EnrollTOTP(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Error(c, response.NewWithError(response.CodeUnauthorized, "未认证", nil))
+		return
+	}
+
+	enrollment, err := h.mfaService.BeginTOTPEnrollment(c.Request.Context(), userID)
+	if err != nil {
+		slog.WarnContext(c.Request.Context(), "Begin totp enrollment failed", "user_id", userID, "error", err)
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, TOTPEnrollmentResponse{
+		Secret: enrollment.Secret,
+		URI:    enrollment.URI,
+	})
+}
+
+// ConfirmTOTP 确认绑定 TOTP
+//
+// @Summary 确认绑定 TOTP
+// @Description 提交验证器 App 生成的验证码启用两步验证，返回恢复码（只显示一次）
+// @Tags 两步验证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body MFACodeRequest true "验证码"
+// @Success 200 {object} response.Response{data=RecoveryCodesResponse} "启用成功"
+// @Failure 400 {object} response.Response "参数错误或尚未开始绑定"
+// @Failure 401 {object} response.Response "未认证或验证码错误"
+// @Failure 409 {object} response.Response "已启用两步验证"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /users/me/mfa/totp/confirm [post]
+func (h *Handler) ConfirmTOTP(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Error(c, response.NewWithError(response.CodeUnauthorized, "未认证", nil))
+		return
+	}
+
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "参数错误", err))
+		return
+	}
+
+	codes, err := h.mfaService.ConfirmTOTPEnrollment(c.Request.Context(), userID, req.Code)
+	if err != nil {
+		slog.WarnContext(c.Request.Context(), "Confirm totp enrollment failed", "user_id", userID, "error", err)
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码
+//
+// @Summary 重新生成恢复码
+// @Description 验证 TOTP 验证码（或恢复码）后重新生成恢复码，旧恢复码全部作废
+// @Tags 两步验证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body MFACodeRequest true "验证码"
+// @Success 200 {object} response.Response{data=RecoveryCodesResponse} "生成成功"
+// @Failure 400 {object} response.Response "参数错误或未启用两步验证"
+// @Failure 401 {object} response.Response "未认证或验证码错误"
+// @Failure 429 {object} response.Response "失败次数过多，暂时锁定"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /users/me/mfa/recovery-codes [post]
+func (h *Handler) RegenerateRecoveryCodes(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Error(c, response.NewWithError(response.CodeUnauthorized, "未认证", nil))
+		return
+	}
+
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "参数错误", err))
+		return
+	}
+
+	var codes []string
+	err := h.withMFALockout(c, userID, func(ctx context.Context) error {
+		var err error
+		codes, err = h.mfaService.RegenerateRecoveryCodes(ctx, userID, req.Code)
+		return err
+	})
+	if err != nil {
+		slog.WarnContext(c.Request.Context(), "Regenerate recovery codes failed", "user_id", userID, "error", err)
+		h.mfaLockoutError(c, err)
+		return
+	}
+
+	response.Success(c, RecoveryCodesResponse{RecoveryCodes: codes})
+}
+
+// DisableMFA 关闭两步验证
+//
+// @Summary 关闭两步验证
+// @Description 验证 TOTP 验证码（或恢复码）后关闭两步验证，密钥和恢复码一并删除
+// @Tags 两步验证
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param request body MFACodeRequest true "验证码"
+// @Success 200 {object} response.Response "关闭成功"
+// @Failure 400 {object} response.Response "参数错误或未启用两步验证"
+// @Failure 401 {object} response.Response "未认证或验证码错误"
+// @Failure 429 {object} response.Response "失败次数过多，暂时锁定"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /users/me/mfa [delete]
+func (h *Handler) DisableMFA(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Error(c, response.NewWithError(response.CodeUnauthorized, "未认证", nil))
+		return
+	}
+
+	var req MFACodeRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "参数错误", err))
+		return
+	}
+
+	err := h.withMFALockout(c, userID, func(ctx context.Context) error {
+		return h.mfaService.Disable(ctx, userID, req.Code)
+	})
+	if err != nil {
+		slog.WarnContext(c.Request.Context(), "Disable mfa failed", "user_id", userID, "error", err)
+		h.mfaLockoutError(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
+
+// withMFALockout 执行需要验证码的两步验证管理操作，与登录第二步使用同一个锁定计数
+//
+// 持有访问令牌（如被盗用）的人不能通过反复猜测 6 位验证码来关闭两步验证或重新生成恢复码。
+func (h *Handler) withMFALockout(c *gin.Context, userID int64, op func(ctx context.Context) error) error {
+	ctx := c.Request.Context()
+	clientIP := c.ClientIP()
+
+	user, err := h.userService.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	if err := h.loginGuard.Check(ctx, user.Email, clientIP); err != nil {
+		if errors.Is(err, auth.ErrLoginLocked) {
+			metrics.AuthFailures.WithLabelValues("locked").Inc()
+			return err
+		}
+		slog.ErrorContext(ctx, "Check login lockout failed", "user_id", user.ID, "ip", clientIP, "error", err)
+	}
+
+	if err := op(ctx); err != nil {
+		if errors.Is(err, service.ErrInvalidMFACode) {
+			metrics.AuthFailures.WithLabelValues("invalid_mfa_code").Inc()
+			if lockErr := h.loginGuard.RecordFailure(ctx, user.Email, clientIP); lockErr != nil && !errors.Is(lockErr, auth.ErrLoginLocked) {
+				slog.ErrorContext(ctx, "Record login failure failed", "user_id", user.ID, "ip", clientIP, "error", lockErr)
+			}
+		}
+		return err
+	}
+	return nil
+}
+
+// mfaLockoutError 输出 withMFALockout 返回的错误（锁定时返回 429 和剩余时间）
+func (h *Handler) mfaLockoutError(c *gin.Context, err error) {
+	if errors.Is(err, auth.ErrLoginLocked) {
+		h.lockoutResponse(c, err)
+		return
+	}
+	response.Error(c, err)
+}
@@ -0,0 +1,321 @@
+package user
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gin_demo/internal/domain/service"
+	"gin_demo/internal/repository"
+	"gin_demo/internal/response"
+	"gin_demo/pkg/auth"
+	"gin_demo/pkg/tenant"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockMFAService 是 MFAService 的 mock 实现
+type MockMFAService struct {
+	mock.Mock
+}
+
+func (m *MockMFAService) GetStatus(ctx context.Context, userID int64) (service.MFAStatus, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(service.MFAStatus), args.Error(1)
+}
+
+func (m *MockMFAService) IsEnabled(ctx context.Context, userID int64) (bool, error) {
+	args := m.Called(ctx, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMFAService) BeginTOTPEnrollment(ctx context.Context, userID int64) (service.TOTPEnrollment, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(service.TOTPEnrollment), args.Error(1)
+}
+
+func (m *MockMFAService) ConfirmTOTPEnrollment(ctx context.Context, userID int64, code string) ([]string, error) {
+	args := m.Called(ctx, userID, code)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockMFAService) Verify(ctx context.Context, userID int64, code string) error {
+	args := m.Called(ctx, userID, code)
+	return args.Error(0)
+}
+
+func (m *MockMFAService) RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) ([]string, error) {
+	args := m.Called(ctx, userID, code)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockMFAService) Disable(ctx context.Context, userID int64, code string) error {
+	args := m.Called(ctx, userID, code)
+	return args.Error(0)
+}
+
+// TestHandler_LoginMFA 测试启用两步验证后的登录流程
+func TestHandler_LoginMFA(t *testing.T) {
+	user := repository.User{ID: 1, Username: "testuser", Email: "test@example.com", Role: "user", TokenVersion: 2}
+
+	// loginInTenant 在指定租户内执行第一步（0 表示请求未指定租户），返回 mfa_token
+	loginInTenant := func(t *testing.T, handler *Handler, tenantID int64) string {
+		body, _ := json.Marshal(LoginRequest{Email: user.Email, Password: "password123"})
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/users/login", bytes.NewBuffer(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		if tenantID != 0 {
+			c.Request = c.Request.WithContext(tenant.WithID(c.Request.Context(), tenantID))
+		}
+
+		handler.Login(c)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp struct {
+			Data map[string]interface{} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, true, resp.Data["mfa_required"])
+		assert.Nil(t, resp.Data["token"], "第一步不能返回访问令牌")
+		return resp.Data["mfa_token"].(string)
+	}
+	login := func(t *testing.T, handler *Handler) string {
+		return loginInTenant(t, handler, 0)
+	}
+
+	// loginMFAInTenant 在指定租户内执行第二步（0 表示请求未指定租户）
+	loginMFAInTenant := func(handler *Handler, mfaToken, code string, tenantID int64) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(LoginMFARequest{MFAToken: mfaToken, Code: code})
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/users/login/mfa", bytes.NewBuffer(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		if tenantID != 0 {
+			c.Request = c.Request.WithContext(tenant.WithID(c.Request.Context(), tenantID))
+		}
+		handler.LoginMFA(c)
+		return w
+	}
+	loginMFA := func(handler *Handler, mfaToken, code string) *httptest.ResponseRecorder {
+		return loginMFAInTenant(handler, mfaToken, code, 0)
+	}
+
+	t.Run("验证码正确后签发令牌", func(t *testing.T) {
+		handler, mockService, mockMFA, jwtManager := setupMFATestHandler()
+
+		mockService.On("Login", mock.Anything, mock.AnythingOfType("service.LoginInput")).Return(user, nil)
+		mockService.On("GetUserByID", mock.Anything, user.ID).Return(user, nil)
+		mockService.On("GetUserPermissions", mock.Anything, user.ID).Return([]auth.Permission{}, nil)
+		mockMFA.On("IsEnabled", mock.Anything, user.ID).Return(true, nil)
+		mockMFA.On("Verify", mock.Anything, user.ID, "123456").Return(nil)
+
+		mfaToken := login(t, handler)
+
+		// 待验证令牌不能当作访问令牌使用
+		_, err := jwtManager.ValidateToken(mfaToken)
+		assert.Error(t, err)
+
+		w := loginMFA(handler, mfaToken, "123456")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp struct {
+			Data LoginResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		claims, err := jwtManager.ValidateToken(resp.Data.Token)
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, claims.UserID)
+		assert.NotEmpty(t, resp.Data.RefreshToken)
+
+		mockService.AssertExpectations(t)
+		mockMFA.AssertExpectations(t)
+	})
+
+	t.Run("第二步使用第一步所属的租户", func(t *testing.T) {
+		handler, mockService, mockMFA, jwtManager := setupMFATestHandler()
+
+		tenantUser := user
+		tenantUser.TenantID = 2
+		inTenant := mock.MatchedBy(func(ctx context.Context) bool { return tenant.ID(ctx) == 2 })
+		mockService.On("Login", mock.Anything, mock.AnythingOfType("service.LoginInput")).Return(tenantUser, nil)
+		mockService.On("GetUserByID", inTenant, user.ID).Return(tenantUser, nil)
+		mockService.On("GetUserPermissions", inTenant, user.ID).Return([]auth.Permission{}, nil)
+		mockMFA.On("IsEnabled", mock.Anything, user.ID).Return(true, nil)
+		mockMFA.On("Verify", inTenant, user.ID, "123456").Return(nil)
+
+		mfaToken := loginInTenant(t, handler, 2)
+
+		// 客户端第二步没有再携带租户请求头
+		w := loginMFA(handler, mfaToken, "123456")
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp struct {
+			Data LoginResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		claims, err := jwtManager.ValidateToken(resp.Data.Token)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), claims.TenantID)
+
+		// Refresh Token 同样属于第一步的租户
+		_, record, err := handler.refreshManager.Rotate(context.Background(), resp.Data.RefreshToken)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), record.TenantID)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("第二步指定了其他租户时拒绝", func(t *testing.T) {
+		handler, mockService, mockMFA, _ := setupMFATestHandler()
+
+		tenantUser := user
+		tenantUser.TenantID = 2
+		mockService.On("Login", mock.Anything, mock.AnythingOfType("service.LoginInput")).Return(tenantUser, nil)
+		mockMFA.On("IsEnabled", mock.Anything, user.ID).Return(true, nil)
+
+		mfaToken := loginInTenant(t, handler, 2)
+
+		w := loginMFAInTenant(handler, mfaToken, "123456", 3)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		mockService.AssertNotCalled(t, "GetUserByID", mock.Anything, mock.Anything)
+		mockMFA.AssertNotCalled(t, "Verify", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("访问令牌不能当作待验证令牌", func(t *testing.T) {
+		handler, _, _, jwtManager := setupMFATestHandler()
+
+		token, err := jwtManager.GenerateTokenWithVersion(user.ID, user.TokenVersion, auth.RoleUser)
+		require.NoError(t, err)
+
+		w := loginMFA(handler, token, "123456")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("Token 版本号变化后拒绝", func(t *testing.T) {
+		handler, mockService, mockMFA, _ := setupMFATestHandler()
+
+		mockService.On("Login", mock.Anything, mock.AnythingOfType("service.LoginInput")).Return(user, nil)
+		mockMFA.On("IsEnabled", mock.Anything, user.ID).Return(true, nil)
+		mfaToken := login(t, handler)
+
+		// 第一步之后修改了密码
+		changed := user
+		changed.TokenVersion++
+		mockService.On("GetUserByID", mock.Anything, user.ID).Return(changed, nil)
+
+		w := loginMFA(handler, mfaToken, "123456")
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		mockMFA.AssertNotCalled(t, "Verify", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("连续输错验证码后锁定", func(t *testing.T) {
+		handler, mockService, mockMFA, _ := setupMFATestHandler()
+
+		mockService.On("Login", mock.Anything, mock.AnythingOfType("service.LoginInput")).Return(user, nil)
+		mockService.On("GetUserByID", mock.Anything, user.ID).Return(user, nil)
+		mockMFA.On("IsEnabled", mock.Anything, user.ID).Return(true, nil)
+		mockMFA.On("Verify", mock.Anything, user.ID, "000000").Return(service.ErrInvalidMFACode).Times(3)
+
+		mfaToken := login(t, handler)
+		for i := 0; i < 3; i++ {
+			assert.Equal(t, http.StatusUnauthorized, loginMFA(handler, mfaToken, "000000").Code)
+		}
+
+		// 锁定期间不再校验验证码
+		w := loginMFA(handler, mfaToken, "000000")
+		assert.Equal(t, http.StatusTooManyRequests, w.Code)
+
+		var resp map[string]interface{}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, float64(response.CodeAccountLocked), resp["code"])
+
+		mockMFA.AssertExpectations(t)
+	})
+}
+
+// TestHandler_ConfirmTOTP 测试确认绑定 TOTP
+func TestHandler_ConfirmTOTP(t *testing.T) {
+	t.Run("返回恢复码", func(t *testing.T) {
+		handler, _, mockMFA, _ := setupMFATestHandler()
+
+		codes := []string{"aaaaa-bbbbb", "ccccc-ddddd"}
+		mockMFA.On("ConfirmTOTPEnrollment", mock.Anything, int64(1), "123456").Return(codes, nil)
+
+		body, _ := json.Marshal(MFACodeRequest{Code: "123456"})
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/users/me/mfa/totp/confirm", bytes.NewBuffer(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Set("user_id", int64(1))
+
+		handler.ConfirmTOTP(c)
+
+		require.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data RecoveryCodesResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, codes, resp.Data.RecoveryCodes)
+		mockMFA.AssertExpectations(t)
+	})
+
+	t.Run("验证码错误", func(t *testing.T) {
+		handler, _, mockMFA, _ := setupMFATestHandler()
+
+		mockMFA.On("ConfirmTOTPEnrollment", mock.Anything, int64(1), "000000").
+			Return([]string(nil), service.ErrInvalidMFACode)
+
+		body, _ := json.Marshal(MFACodeRequest{Code: "000000"})
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest("POST", "/users/me/mfa/totp/confirm", bytes.NewBuffer(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Set("user_id", int64(1))
+
+		handler.ConfirmTOTP(c)
+
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
+
+// TestHandler_MFAManagementLockout 测试关闭两步验证和重新生成恢复码受登录锁定保护
+func TestHandler_MFAManagementLockout(t *testing.T) {
+	user := repository.User{ID: 1, Username: "testuser", Email: "test@example.com", Role: "user"}
+
+	do := func(handle gin.HandlerFunc, method, path, code string) *httptest.ResponseRecorder {
+		body, _ := json.Marshal(MFACodeRequest{Code: code})
+		w := httptest.NewRecorder()
+		c, _ := gin.CreateTestContext(w)
+		c.Request = httptest.NewRequest(method, path, bytes.NewBuffer(body))
+		c.Request.Header.Set("Content-Type", "application/json")
+		c.Set("user_id", user.ID)
+		handle(c)
+		return w
+	}
+
+	handler, mockService, mockMFA, _ := setupMFATestHandler()
+	mockService.On("GetUserByID", mock.Anything, user.ID).Return(user, nil)
+	mockMFA.On("Disable", mock.Anything, user.ID, "000000").Return(service.ErrInvalidMFACode).Times(2)
+	mockMFA.On("RegenerateRecoveryCodes", mock.Anything, user.ID, "000000").Return([]string(nil), service.ErrInvalidMFACode).Once()
+
+	// 两个接口共用失败计数
+	assert.Equal(t, http.StatusUnauthorized, do(handler.DisableMFA, "DELETE", "/users/me/mfa", "000000").Code)
+	assert.Equal(t, http.StatusUnauthorized, do(handler.RegenerateRecoveryCodes, "POST", "/users/me/mfa/recovery-codes", "000000").Code)
+	assert.Equal(t, http.StatusUnauthorized, do(handler.DisableMFA, "DELETE", "/users/me/mfa", "000000").Code)
+
+	// 锁定期间不再校验验证码（正确的验证码也被拒绝）
+	w := do(handler.DisableMFA, "DELETE", "/users/me/mfa", "123456")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+	assert.NotEmpty(t, w.Header().Get("Retry-After"))
+	w = do(handler.RegenerateRecoveryCodes, "POST", "/users/me/mfa/recovery-codes", "123456")
+	assert.Equal(t, http.StatusTooManyRequests, w.Code)
+
+	mockMFA.AssertExpectations(t)
+	mockMFA.AssertNotCalled(t, "Disable", mock.Anything, mock.Anything, "123456")
+}
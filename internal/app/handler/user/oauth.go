@@ -0,0 +1,217 @@
+package user
+
+import (
+	"log/slog"
+
+	"gin_demo/internal/app/middleware"
+	"gin_demo/internal/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// OAuthProviders 第三方登录提供方列表
+//
+// @Summary 第三方登录提供方
+// @Description 返回已配置的第三方登录提供方名称
+// @Tags 第三方登录
+// @Produce json
+// @Success 200 {object} response.Response{data=OAuthProvidersResponse} "获取成功"
+// @Router /users/oauth/providers [get]
+func (h *Handler) OAuthProviders(c *gin.Context) {
+	response.Success(c, OAuthProvidersResponse{Providers: h.oauthService.Providers()})
+}
+
+// OAuthAuthorize 开始第三方登录
+//
+// @Summary 开始第三方登录
+// @Description 生成提供方授权地址（授权码 + PKCE），前端跳转到该地址，授权后提供方携带 code 和 state 重定向回前端
+// @Tags 第三方登录
+// @Produce json
+// @Param provider path string true "提供方名称"
+// @Success 200 {object} response.Response{data=OAuthAuthorizeResponse} "获取成功"
+// @Failure 404 {object} response.Response "提供方不存在"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /users/oauth/{provider}/authorize [get]
+func (h *Handler) OAuthAuthorize(c *gin.Context) {
+	authURL, err := h.oauthService.AuthorizationURL(c.Request.Context(), c.Param("provider"), 0)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, OAuthAuthorizeResponse{AuthorizationURL: authURL})
+}
+
+// OAuthCallback 完成第三方登录
+//
+// @Summary 完成第三方登录
+// @Description 提交提供方回调的 code 和 state 完成登录；未关联的第三方账户使用其已验证邮箱自动注册（无密码）。已启用两步验证的用户返回 MFAChallengeResponse
+// @Tags 第三方登录
+// @Accept json
+// @Produce json
+// @Param provider path string true "提供方名称"
+// @Param request body OAuthCallbackRequest true "回调参数"
+// @Success 200 {object} response.Response{data=LoginResponse} "登录成功"
+// @Failure 400 {object} response.Response "参数错误或第三方账户没有已验证的邮箱"
+// @Failure 401 {object} response.Response "state 无效或授权失败"
+// @Failure 409 {object} response.Response "邮箱已注册"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /users/oauth/{provider}/callback [post]
+func (h *Handler) OAuthCallback(c *gin.Context) {
+	var req OAuthCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "参数错误", err))
+		return
+	}
+
+	ctx := c.Request.Context()
+	provider := c.Param("provider")
+
+	user, err := h.oauthService.Login(ctx, provider, req.State, req.Code)
+	if err != nil {
+		slog.WarnContext(ctx, "OAuth login failed", "provider", provider, "error", err)
+		response.Error(c, err)
+		return
+	}
+
+	h.beginSession(c, user)
+}
+
+// ListIdentities 已关联的第三方账户
+//
+// @Summary 已关联的第三方账户
+// @Description 列出当前用户关联的第三方账户
+// @Tags 第三方登录
+// @Produce json
+// @Security BearerAuth
+// @Success 200 {object} response.Response{data=[]IdentityResponse} "获取成功"
+// @Failure 401 {object} response.Response "未认证"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /users/me/identities [get]
+func (h *Handler) ListIdentities(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Error(c, response.NewWithError(response.CodeUnauthorized, "未认证", nil))
+		return
+	}
+
+	identities, err := h.oauthService.ListIdentities(c.Request.Context(), userID)
+	if err != nil {
+		slog.ErrorContext(c.Request.Context(), "List identities failed", "user_id", userID, "error", err)
+		response.Error(c, err)
+		return
+	}
+
+	result := make([]IdentityResponse, 0, len(identities))
+	for _, identity := range identities {
+		result = append(result, IdentityResponse{
+			Provider:    identity.Provider,
+			Email:       identity.Email,
+			LastLoginAt: identity.LastLoginAt,
+			CreatedAt:   identity.CreatedAt,
+		})
+	}
+	response.Success(c, result)
+}
+
+// LinkIdentityAuthorize 开始关联第三方账户
+//
+// @Summary 开始关联第三方账户
+// @Description 生成提供方授权地址，授权后提交到 /users/me/identities/{provider}/callback 完成关联
+// @Tags 第三方登录
+// @Produce json
+// @Security BearerAuth
+// @Param provider path string true "提供方名称"
+// @Success 200 {object} response.Response{data=OAuthAuthorizeResponse} "获取成功"
+// @Failure 401 {object} response.Response "未认证"
+// @Failure 404 {object} response.Response "提供方不存在"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /users/me/identities/{provider} [post]
+func (h *Handler) LinkIdentityAuthorize(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Error(c, response.NewWithError(response.CodeUnauthorized, "未认证", nil))
+		return
+	}
+
+	authURL, err := h.oauthService.AuthorizationURL(c.Request.Context(), c.Param("provider"), userID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, OAuthAuthorizeResponse{AuthorizationURL: authURL})
+}
+
+// LinkIdentityCallback 完成关联第三方账户
+//
+// @Summary 完成关联第三方账户
+// @Description 提交提供方回调的 code 和 state，将第三方账户关联到当前用户
+// @Tags 第三方登录
+// @Accept json
+// @Produce json
+// @Security BearerAuth
+// @Param provider path string true "提供方名称"
+// @Param request body OAuthCallbackRequest true "回调参数"
+// @Success 200 {object} response.Response{data=IdentityResponse} "关联成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未认证、state 无效或授权失败"
+// @Failure 409 {object} response.Response "第三方账户已关联其他用户"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /users/me/identities/{provider}/callback [post]
+func (h *Handler) LinkIdentityCallback(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Error(c, response.NewWithError(response.CodeUnauthorized, "未认证", nil))
+		return
+	}
+
+	var req OAuthCallbackRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "参数错误", err))
+		return
+	}
+
+	identity, err := h.oauthService.Link(c.Request.Context(), userID, c.Param("provider"), req.State, req.Code)
+	if err != nil {
+		slog.WarnContext(c.Request.Context(), "Link identity failed", "user_id", userID, "provider", c.Param("provider"), "error", err)
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, IdentityResponse{
+		Provider:    identity.Provider,
+		Email:       identity.Email,
+		LastLoginAt: identity.LastLoginAt,
+		CreatedAt:   identity.CreatedAt,
+	})
+}
+
+// UnlinkIdentity 解除关联第三方账户
+//
+// @Summary 解除关联第三方账户
+// @Description 解除当前用户与第三方账户的关联（没有设置密码时不能解除唯一的关联）
+// @Tags 第三方登录
+// @Produce json
+// @Security BearerAuth
+// @Param provider path string true "提供方名称"
+// @Success 200 {object} response.Response "解除成功"
+// @Failure 400 {object} response.Response "这是唯一的登录方式"
+// @Failure 401 {object} response.Response "未认证"
+// @Failure 404 {object} response.Response "未关联"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /users/me/identities/{provider} [delete]
+func (h *Handler) UnlinkIdentity(c *gin.Context) {
+	userID := middleware.GetUserID(c)
+	if userID == 0 {
+		response.Error(c, response.NewWithError(response.CodeUnauthorized, "未认证", nil))
+		return
+	}
+
+	if err := h.oauthService.Unlink(c.Request.Context(), userID, c.Param("provider")); err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	response.Success(c, nil)
+}
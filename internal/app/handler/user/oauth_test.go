@@ -0,0 +1,197 @@
+package user
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"gin_demo/internal/domain/service"
+	"gin_demo/internal/repository"
+	"gin_demo/pkg/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockOAuthService 是 OAuthService 的 mock 实现
+type MockOAuthService struct {
+	mock.Mock
+}
+
+func (m *MockOAuthService) Providers() []string {
+	args := m.Called()
+	return args.Get(0).([]string)
+}
+
+func (m *MockOAuthService) AuthorizationURL(ctx context.Context, provider string, linkUserID int64) (string, error) {
+	args := m.Called(ctx, provider, linkUserID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockOAuthService) Login(ctx context.Context, provider, state, code string) (repository.User, error) {
+	args := m.Called(ctx, provider, state, code)
+	return args.Get(0).(repository.User), args.Error(1)
+}
+
+func (m *MockOAuthService) Link(ctx context.Context, userID int64, provider, state, code string) (service.Identity, error) {
+	args := m.Called(ctx, userID, provider, state, code)
+	return args.Get(0).(service.Identity), args.Error(1)
+}
+
+func (m *MockOAuthService) ListIdentities(ctx context.Context, userID int64) ([]service.Identity, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]service.Identity), args.Error(1)
+}
+
+func (m *MockOAuthService) Unlink(ctx context.Context, userID int64, provider string) error {
+	args := m.Called(ctx, userID, provider)
+	return args.Error(0)
+}
+
+// setupOAuthTestHandler 设置测试 Handler（第三方登录服务由调用方 mock）
+func setupOAuthTestHandler() (*Handler, *MockUserService, *MockMFAService, *MockOAuthService, *auth.RBACJWTManager) {
+	handler, mockService, mockMFA, jwtManager := setupMFATestHandler()
+	mockOAuth := new(MockOAuthService)
+	handler.oauthService = mockOAuth
+	return handler, mockService, mockMFA, mockOAuth, jwtManager
+}
+
+// callOAuth 以 JSON 请求体调用带 provider 路径参数的 Handler
+func callOAuth(handlerFunc gin.HandlerFunc, method, path, provider string, userID int64, body interface{}) *httptest.ResponseRecorder {
+	var data []byte
+	if body != nil {
+		data, _ = json.Marshal(body)
+	}
+	w := httptest.NewRecorder()
+	c, _ := gin.CreateTestContext(w)
+	c.Request = httptest.NewRequest(method, path, bytes.NewReader(data))
+	c.Request.Header.Set("Content-Type", "application/json")
+	c.Params = gin.Params{{Key: "provider", Value: provider}}
+	if userID != 0 {
+		c.Set("user_id", userID)
+	}
+	handlerFunc(c)
+	return w
+}
+
+// TestHandler_OAuthCallback 测试第三方登录回调
+func TestHandler_OAuthCallback(t *testing.T) {
+	user := repository.User{ID: 1, Username: "octocat", Email: "octocat@example.com", Role: "user", Status: repository.UserStatusActive}
+	req := OAuthCallbackRequest{Code: "code-1", State: "state-1"}
+
+	t.Run("登录成功签发令牌", func(t *testing.T) {
+		handler, mockService, mockMFA, mockOAuth, jwtManager := setupOAuthTestHandler()
+		mockOAuth.On("Login", mock.Anything, "github", "state-1", "code-1").Return(user, nil)
+		mockMFA.On("IsEnabled", mock.Anything, user.ID).Return(false, nil)
+		mockService.On("GetUserPermissions", mock.Anything, user.ID).Return([]auth.Permission{}, nil)
+
+		w := callOAuth(handler.OAuthCallback, http.MethodPost, "/users/oauth/github/callback", "github", 0, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp struct {
+			Data LoginResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		claims, err := jwtManager.ValidateToken(resp.Data.Token)
+		require.NoError(t, err)
+		assert.Equal(t, user.ID, claims.UserID)
+		assert.NotEmpty(t, resp.Data.RefreshToken)
+		mockOAuth.AssertExpectations(t)
+	})
+
+	t.Run("已启用两步验证时返回待验证令牌", func(t *testing.T) {
+		handler, _, mockMFA, mockOAuth, _ := setupOAuthTestHandler()
+		mockOAuth.On("Login", mock.Anything, "github", "state-1", "code-1").Return(user, nil)
+		mockMFA.On("IsEnabled", mock.Anything, user.ID).Return(true, nil)
+
+		w := callOAuth(handler.OAuthCallback, http.MethodPost, "/users/oauth/github/callback", "github", 0, req)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp struct {
+			Data map[string]interface{} `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, true, resp.Data["mfa_required"])
+		assert.Nil(t, resp.Data["token"], "第三方登录也不能绕过两步验证")
+	})
+
+	t.Run("授权失败", func(t *testing.T) {
+		handler, _, _, mockOAuth, _ := setupOAuthTestHandler()
+		mockOAuth.On("Login", mock.Anything, "github", "state-1", "code-1").Return(repository.User{}, service.ErrOAuthFailed)
+
+		w := callOAuth(handler.OAuthCallback, http.MethodPost, "/users/oauth/github/callback", "github", 0, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("缺少 state", func(t *testing.T) {
+		handler, _, _, mockOAuth, _ := setupOAuthTestHandler()
+
+		w := callOAuth(handler.OAuthCallback, http.MethodPost, "/users/oauth/github/callback", "github", 0, OAuthCallbackRequest{Code: "code-1"})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockOAuth.AssertNotCalled(t, "Login", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// TestHandler_LinkIdentity 测试关联与解除关联第三方账户
+func TestHandler_LinkIdentity(t *testing.T) {
+	t.Run("开始关联时携带当前用户", func(t *testing.T) {
+		handler, _, _, mockOAuth, _ := setupOAuthTestHandler()
+		mockOAuth.On("AuthorizationURL", mock.Anything, "github", int64(7)).Return("https://github.example/authorize?state=x", nil)
+
+		w := callOAuth(handler.LinkIdentityAuthorize, http.MethodPost, "/users/me/identities/github", "github", 7, nil)
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp struct {
+			Data OAuthAuthorizeResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "https://github.example/authorize?state=x", resp.Data.AuthorizationURL)
+		mockOAuth.AssertExpectations(t)
+	})
+
+	t.Run("未认证", func(t *testing.T) {
+		handler, _, _, _, _ := setupOAuthTestHandler()
+
+		w := callOAuth(handler.LinkIdentityAuthorize, http.MethodPost, "/users/me/identities/github", "github", 0, nil)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("完成关联", func(t *testing.T) {
+		handler, _, _, mockOAuth, _ := setupOAuthTestHandler()
+		mockOAuth.On("Link", mock.Anything, int64(7), "github", "state-1", "code-1").
+			Return(service.Identity{Provider: "github", Email: "octocat@example.com"}, nil)
+
+		w := callOAuth(handler.LinkIdentityCallback, http.MethodPost, "/users/me/identities/github/callback", "github", 7,
+			OAuthCallbackRequest{Code: "code-1", State: "state-1"})
+		require.Equal(t, http.StatusOK, w.Code)
+
+		var resp struct {
+			Data IdentityResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "github", resp.Data.Provider)
+	})
+
+	t.Run("第三方账户已关联其他用户", func(t *testing.T) {
+		handler, _, _, mockOAuth, _ := setupOAuthTestHandler()
+		mockOAuth.On("Link", mock.Anything, int64(7), "github", "state-1", "code-1").
+			Return(service.Identity{}, service.ErrIdentityAlreadyLinked)
+
+		w := callOAuth(handler.LinkIdentityCallback, http.MethodPost, "/users/me/identities/github/callback", "github", 7,
+			OAuthCallbackRequest{Code: "code-1", State: "state-1"})
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+
+	t.Run("不能解除唯一的登录方式", func(t *testing.T) {
+		handler, _, _, mockOAuth, _ := setupOAuthTestHandler()
+		mockOAuth.On("Unlink", mock.Anything, int64(7), "github").Return(service.ErrLastLoginMethod)
+
+		w := callOAuth(handler.UnlinkIdentity, http.MethodDelete, "/users/me/identities/github", "github", 7, nil)
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+}
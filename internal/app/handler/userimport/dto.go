@@ -0,0 +1,51 @@
+package userimport
+
+import (
+	"encoding/json"
+	"time"
+
+	"gin_demo/pkg/task"
+)
+
+// ========================================
+// 请求 DTO
+// ========================================
+
+// JobIDRequest 作业 ID 路径参数
+type JobIDRequest struct {
+	ID string `uri:"id" binding:"required,hexadecimal,len=32"`
+}
+
+// ImportRequest 批量导入请求参数（文件内容为请求体）
+type ImportRequest struct {
+	Format string `form:"format" binding:"omitempty,oneof=csv jsonl"` // 默认按 Content-Type 判断，无法判断时为 csv
+}
+
+// ExportRequest 导出用户列表请求（过滤条件与用户列表接口相同，按创建时间排序）
+type ExportRequest struct {
+	Format      string    `form:"format" binding:"omitempty,oneof=csv jsonl"`              // 默认 csv
+	Status      []int16   `form:"status" binding:"omitempty,max=6,dive,oneof=1 2 3 4 5 6"` // 状态（可重复，默认只导出正常用户）
+	Role        []string  `form:"role" binding:"omitempty,max=10,dive,min=1,max=50"`       // 角色（可重复）
+	CreatedFrom time.Time `form:"created_from"`                                            // 创建时间不早于（RFC 3339）
+	CreatedTo   time.Time `form:"created_to"`                                              // 创建时间早于（RFC 3339）
+	Q           string    `form:"q" binding:"max=100"`                                     // 用户名或邮箱包含的文本
+	Order       string    `form:"order" binding:"omitempty,oneof=asc desc"`                // 创建时间排序方向（默认 desc）
+}
+
+// ========================================
+// 响应 DTO
+// ========================================
+
+// JobResponse 导入作业响应
+type JobResponse struct {
+	ID         string           `json:"id"`
+	Type       string           `json:"type"` // user.import
+	Status     task.JobStatus   `json:"status"`
+	Progress   task.JobProgress `json:"progress"`
+	Result     json.RawMessage  `json:"result,omitempty"`     // {"format":"csv","total":100,"created":97,"invited":40,"failed":3}
+	Error      string           `json:"error,omitempty"`      // 失败原因
+	ReportURL  string           `json:"report_url,omitempty"` // 作业成功后错误报告的下载地址
+	CreatedAt  time.Time        `json:"created_at"`
+	StartedAt  *time.Time       `json:"started_at,omitempty"`
+	FinishedAt *time.Time       `json:"finished_at,omitempty"`
+}
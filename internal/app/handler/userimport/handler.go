@@ -0,0 +1,243 @@
+package userimport
+
+import (
+	"errors"
+	"fmt"
+	"log/slog"
+	"mime"
+	"path"
+	"time"
+
+	"gin_demo/internal/app/middleware"
+	"gin_demo/internal/domain/service"
+	"gin_demo/internal/repository"
+	"gin_demo/internal/response"
+	"gin_demo/pkg/task"
+
+	"github.com/gin-gonic/gin"
+)
+
+// Handler 用户批量导入与导出处理器
+type Handler struct {
+	importService service.UserImportService
+}
+
+// NewHandler 创建用户批量导入与导出处理器
+func NewHandler(importService service.UserImportService) *Handler {
+	return &Handler{
+		importService: importService,
+	}
+}
+
+// jsonlContentTypes 按 JSONL 处理的 Content-Type（其他类型按 CSV 处理）
+var jsonlContentTypes = map[string]bool{
+	"application/x-ndjson":    true,
+	"application/jsonl":       true,
+	"application/jsonlines":   true,
+	"application/json-lines":  true,
+	"application/x-jsonlines": true,
+}
+
+// exportContentTypes 导出文件的 Content-Type
+var exportContentTypes = map[string]string{
+	service.TransferFormatCSV:   "text/csv; charset=utf-8",
+	service.TransferFormatJSONL: "application/x-ndjson",
+}
+
+// Import 批量导入用户
+//
+// @Summary 批量导入用户
+// @Description 请求体为 CSV（表头包含 username、email，可选 password）或 JSONL（每行一个对象）文件，流式保存后提交后台作业。
+// @Description 每一行按注册接口的规则校验并创建用户：有密码时发送邮箱验证邮件，没有密码时发送邀请邮件（通过链接设置密码）。
+// @Description 失败的行不影响其他行，作业完成后通过 report_url 下载错误报告；文件大小受 server.max_request_body_size 限制
+// @Tags 用户管理
+// @Accept text/csv,application/x-ndjson
+// @Produce json
+// @Security BearerAuth
+// @Param format query string false "文件格式（csv 或 jsonl，默认按 Content-Type 判断）"
+// @Param file body string true "文件内容"
+// @Success 202 {object} response.Response{data=JobResponse} "已提交"
+// @Failure 400 {object} response.Response "参数错误、文件为空或 CSV 表头无效"
+// @Failure 401 {object} response.Response "未认证"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 413 {object} response.Response "文件过大"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /users/imports [post]
+func (h *Handler) Import(c *gin.Context) {
+	var req ImportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "参数错误", err))
+		return
+	}
+	format := req.Format
+	if format == "" {
+		format = service.TransferFormatCSV
+		if mediaType, _, err := mime.ParseMediaType(c.ContentType()); err == nil && jsonlContentTypes[mediaType] {
+			format = service.TransferFormatJSONL
+		}
+	}
+
+	actorID := middleware.GetUserID(c)
+	job, err := h.importService.RequestImport(c.Request.Context(), service.ImportInput{
+		ActorID: actorID,
+		Format:  format,
+		Source:  c.Request.Body,
+	})
+	if err != nil {
+		if middleware.IsBodyTooLarge(err) {
+			response.Error(c, response.ErrRequestTooLarge)
+			return
+		}
+		slog.WarnContext(c.Request.Context(), "Request import failed", "actor_id", actorID, "error", err)
+		response.Error(c, err)
+		return
+	}
+
+	response.Accepted(c, toJobResponse(job))
+}
+
+// GetImport 查询导入作业
+//
+// @Summary 查询导入作业
+// @Description 查询当前管理员提交的导入作业的进度和结果（作业记录保留 24 小时）
+// @Tags 用户管理
+// @Produce json
+// @Security BearerAuth
+// @Param id path string true "作业ID"
+// @Success 200 {object} response.Response{data=JobResponse} "获取成功"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未认证"
+// @Failure 404 {object} response.Response "作业不存在"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /users/imports/{id} [get]
+func (h *Handler) GetImport(c *gin.Context) {
+	var req JobIDRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "无效的作业ID", err))
+		return
+	}
+
+	job, err := h.importService.GetImportJob(c.Request.Context(), middleware.GetUserID(c), req.ID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	resp := toJobResponse(job)
+	if job.Status == task.JobSucceeded {
+		resp.ReportURL = path.Join(c.Request.URL.Path, "errors")
+	}
+	response.Success(c, resp)
+}
+
+// DownloadReport 下载导入错误报告
+//
+// @Summary 下载导入错误报告
+// @Description 下载已完成的导入作业的错误报告（CSV：行号、用户名、邮箱、原因，不包含密码；没有失败的行时只有表头）
+// @Tags 用户管理
+// @Produce text/csv
+// @Security BearerAuth
+// @Param id path string true "作业ID"
+// @Success 200 {file} file "错误报告"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未认证"
+// @Failure 404 {object} response.Response "作业不存在或报告已过期"
+// @Failure 409 {object} response.Response "导入尚未完成"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /users/imports/{id}/errors [get]
+func (h *Handler) DownloadReport(c *gin.Context) {
+	var req JobIDRequest
+	if err := c.ShouldBindUri(&req); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "无效的作业ID", err))
+		return
+	}
+
+	file, err := h.importService.GetImportReport(c.Request.Context(), middleware.GetUserID(c), req.ID)
+	if err != nil {
+		response.Error(c, err)
+		return
+	}
+
+	c.Header("Cache-Control", "no-store")
+	c.FileAttachment(file.Path, file.Name)
+}
+
+// Export 导出用户列表
+//
+// @Summary 导出用户列表
+// @Description 按过滤条件（与用户列表接口相同）导出用户，按创建时间排序，边查询边写入响应，不限制条数。
+// @Description 开始写入后才出错（如数据库中途不可用）时无法再返回错误响应，客户端收到的文件不完整，服务端记录错误日志
+// @Tags 用户管理
+// @Produce text/csv,application/x-ndjson
+// @Security BearerAuth
+// @Param format query string false "文件格式（csv 或 jsonl）" default(csv)
+// @Param status query []int false "状态（可重复，默认只导出正常用户）" collectionFormat(multi)
+// @Param role query []string false "角色（可重复）" collectionFormat(multi)
+// @Param created_from query string false "创建时间不早于（RFC 3339）"
+// @Param created_to query string false "创建时间早于（RFC 3339）"
+// @Param q query string false "用户名或邮箱包含的文本"
+// @Param order query string false "创建时间排序方向（asc 或 desc）" default(desc)
+// @Success 200 {file} file "用户列表"
+// @Failure 400 {object} response.Response "参数错误"
+// @Failure 401 {object} response.Response "未认证"
+// @Failure 403 {object} response.Response "权限不足"
+// @Failure 500 {object} response.Response "服务器错误"
+// @Router /users/export [get]
+func (h *Handler) Export(c *gin.Context) {
+	var req ExportRequest
+	if err := c.ShouldBindQuery(&req); err != nil {
+		response.Error(c, response.NewWithError(response.CodeInvalidParams, "参数错误", err))
+		return
+	}
+	format := req.Format
+	if format == "" {
+		format = service.TransferFormatCSV
+	}
+
+	filter := repository.UserFilter{
+		Statuses:    req.Status,
+		Roles:       req.Role,
+		CreatedFrom: req.CreatedFrom,
+		CreatedTo:   req.CreatedTo,
+		Query:       req.Q,
+		Order:       repository.SortOrder(req.Order),
+	}
+
+	c.Header("Content-Type", exportContentTypes[format])
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="users-%s.%s"`, time.Now().Format("20060102"), format))
+	c.Header("Cache-Control", "no-store")
+
+	count, err := h.importService.ExportUsers(c.Request.Context(), filter, format, c.Writer)
+	if err == nil {
+		return
+	}
+
+	// 还没有写入任何内容时可以返回错误响应（先移除文件下载的响应头）
+	if !c.Writer.Written() {
+		c.Writer.Header().Del("Content-Type")
+		c.Writer.Header().Del("Content-Disposition")
+		if errors.Is(err, service.ErrInvalidInput) {
+			response.Error(c, response.New(response.CodeInvalidParams, "无效的过滤条件"))
+			return
+		}
+		slog.ErrorContext(c.Request.Context(), "Export users failed", "error", err)
+		response.Error(c, response.Wrap(err, response.CodeInternalError, "导出用户列表失败"))
+		return
+	}
+	slog.ErrorContext(c.Request.Context(), "Export users interrupted", "exported", count, "error", err)
+}
+
+// toJobResponse 转换作业响应
+func toJobResponse(job task.Job) JobResponse {
+	return JobResponse{
+		ID:         job.ID,
+		Type:       job.Type,
+		Status:     job.Status,
+		Progress:   job.Progress,
+		Result:     job.Result,
+		Error:      job.Error,
+		CreatedAt:  job.CreatedAt,
+		StartedAt:  job.StartedAt,
+		FinishedAt: job.FinishedAt,
+	}
+}
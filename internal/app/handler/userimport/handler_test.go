@@ -0,0 +1,243 @@
+package userimport
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gin_demo/internal/app/handler/user"
+	"gin_demo/internal/app/middleware"
+	"gin_demo/internal/domain/service"
+	"gin_demo/internal/repository"
+	"gin_demo/pkg/task"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const testJobID = "0123456789abcdef0123456789abcdef"
+
+// MockUserImportService 是 UserImportService 的 mock 实现（RequestImport 读取完文件内容后再记录调用）
+type MockUserImportService struct {
+	mock.Mock
+	uploaded string
+}
+
+func (m *MockUserImportService) RequestImport(ctx context.Context, input service.ImportInput) (task.Job, error) {
+	data, err := io.ReadAll(input.Source)
+	if err != nil {
+		return task.Job{}, err
+	}
+	m.uploaded = string(data)
+	args := m.Called(ctx, input.ActorID, input.Format)
+	return args.Get(0).(task.Job), args.Error(1)
+}
+
+func (m *MockUserImportService) GetImportJob(ctx context.Context, actorID int64, jobID string) (task.Job, error) {
+	args := m.Called(ctx, actorID, jobID)
+	return args.Get(0).(task.Job), args.Error(1)
+}
+
+func (m *MockUserImportService) GetImportReport(ctx context.Context, actorID int64, jobID string) (service.ExportFile, error) {
+	args := m.Called(ctx, actorID, jobID)
+	return args.Get(0).(service.ExportFile), args.Error(1)
+}
+
+func (m *MockUserImportService) ExportUsers(ctx context.Context, filter repository.UserFilter, format string, w io.Writer) (int64, error) {
+	args := m.Called(ctx, filter, format)
+	if body := args.String(0); body != "" {
+		io.WriteString(w, body)
+	}
+	return int64(args.Int(1)), args.Error(2)
+}
+
+// setupTestRouter 设置测试路由（模拟认证中间件设置当前管理员，请求体限制为 64 字节）
+func setupTestRouter() (*gin.Engine, *MockUserImportService) {
+	gin.SetMode(gin.TestMode)
+	mockService := new(MockUserImportService)
+	handler := NewHandler(mockService)
+
+	router := gin.New()
+	router.Use(middleware.BodyLimit(64))
+	router.Use(func(c *gin.Context) {
+		c.Set(middleware.UserIDKey, int64(1))
+		c.Next()
+	})
+	router.POST("/api/v1/users/imports", handler.Import)
+	router.GET("/api/v1/users/imports/:id", handler.GetImport)
+	router.GET("/api/v1/users/imports/:id/errors", handler.DownloadReport)
+	router.GET("/api/v1/users/export", handler.Export)
+	return router, mockService
+}
+
+// TestHandler_Import 测试提交导入作业
+func TestHandler_Import(t *testing.T) {
+	t.Run("按 Content-Type 判断格式", func(t *testing.T) {
+		router, mockService := setupTestRouter()
+		mockService.On("RequestImport", mock.Anything, int64(1), service.TransferFormatJSONL).
+			Return(task.Job{ID: testJobID, Type: service.JobTypeUserImport, Status: task.JobPending}, nil)
+
+		body := `{"username":"alice","email":"alice@example.com"}` + "\n"
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/users/imports", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/x-ndjson; charset=utf-8")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		assert.Equal(t, body, mockService.uploaded)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("默认 CSV", func(t *testing.T) {
+		router, mockService := setupTestRouter()
+		mockService.On("RequestImport", mock.Anything, int64(1), service.TransferFormatCSV).
+			Return(task.Job{ID: testJobID, Type: service.JobTypeUserImport, Status: task.JobPending}, nil)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/users/imports", strings.NewReader("username,email\n")))
+
+		assert.Equal(t, http.StatusAccepted, w.Code)
+		mockService.AssertExpectations(t)
+	})
+
+	t.Run("表头无效", func(t *testing.T) {
+		router, mockService := setupTestRouter()
+		mockService.On("RequestImport", mock.Anything, int64(1), service.TransferFormatCSV).Return(task.Job{}, service.ErrImportHeader)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/users/imports?format=csv", strings.NewReader("name\n")))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("未声明长度的请求体超过限制", func(t *testing.T) {
+		router, mockService := setupTestRouter()
+		mockService.On("RequestImport", mock.Anything, mock.Anything, mock.Anything).Return(task.Job{}, nil)
+
+		// io.MultiReader 隐藏了长度，请求按分块传输处理，读取时才发现超过限制
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/users/imports", io.MultiReader(strings.NewReader(strings.Repeat("a", 100))))
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusRequestEntityTooLarge, w.Code)
+		mockService.AssertNotCalled(t, "RequestImport", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// TestHandler_GetImport 测试查询导入作业和下载错误报告
+func TestHandler_GetImport(t *testing.T) {
+	t.Run("完成后返回报告地址", func(t *testing.T) {
+		router, mockService := setupTestRouter()
+		mockService.On("GetImportJob", mock.Anything, int64(1), testJobID).Return(task.Job{
+			ID:     testJobID,
+			Type:   service.JobTypeUserImport,
+			Status: task.JobSucceeded,
+			Result: json.RawMessage(`{"format":"csv","total":2,"created":1,"invited":0,"failed":1}`),
+		}, nil)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/users/imports/"+testJobID, nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		var resp struct {
+			Data JobResponse `json:"data"`
+		}
+		require.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+		assert.Equal(t, "/api/v1/users/imports/"+testJobID+"/errors", resp.Data.ReportURL)
+	})
+
+	t.Run("下载报告", func(t *testing.T) {
+		router, mockService := setupTestRouter()
+		path := filepath.Join(t.TempDir(), "report.csv")
+		require.NoError(t, os.WriteFile(path, []byte("line,username,email,error\n"), 0o600))
+		mockService.On("GetImportReport", mock.Anything, int64(1), testJobID).
+			Return(service.ExportFile{Path: path, Name: "user-import-20260101-errors.csv"}, nil)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/users/imports/"+testJobID+"/errors", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Contains(t, w.Header().Get("Content-Disposition"), "user-import-20260101-errors.csv")
+		assert.Equal(t, "no-store", w.Header().Get("Cache-Control"))
+	})
+
+	t.Run("尚未完成", func(t *testing.T) {
+		router, mockService := setupTestRouter()
+		mockService.On("GetImportReport", mock.Anything, int64(1), testJobID).Return(service.ExportFile{}, service.ErrImportNotReady)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/users/imports/"+testJobID+"/errors", nil))
+
+		assert.Equal(t, http.StatusConflict, w.Code)
+	})
+}
+
+// TestHandler_Export 测试导出用户列表
+func TestHandler_Export(t *testing.T) {
+	t.Run("流式写入文件", func(t *testing.T) {
+		router, mockService := setupTestRouter()
+		mockService.On("ExportUsers", mock.Anything, repository.UserFilter{
+			Statuses: []int16{repository.UserStatusActive, repository.UserStatusSuspended},
+			Query:    "example",
+			Order:    repository.SortAsc,
+		}, service.TransferFormatJSONL).Return(`{"id":1}`+"\n", 1, nil)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/users/export?format=jsonl&status=1&status=2&q=example&order=asc", nil))
+
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+		assert.Regexp(t, `attachment; filename="users-\d{8}\.jsonl"`, w.Header().Get("Content-Disposition"))
+		assert.Equal(t, `{"id":1}`+"\n", w.Body.String())
+	})
+
+	t.Run("写入前出错时返回错误响应", func(t *testing.T) {
+		router, mockService := setupTestRouter()
+		mockService.On("ExportUsers", mock.Anything, mock.Anything, service.TransferFormatCSV).Return("", 0, service.ErrInvalidInput)
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/users/export", nil))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		assert.Empty(t, w.Header().Get("Content-Disposition"))
+		assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+	})
+
+	t.Run("不支持的格式", func(t *testing.T) {
+		router, mockService := setupTestRouter()
+
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/users/export?format=xlsx", nil))
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+		mockService.AssertNotCalled(t, "ExportUsers", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// TestImportRow_MatchesRegisterRequest 导入的每一行与注册接口使用相同的校验规则（密码除外：导入时可以为空）
+func TestImportRow_MatchesRegisterRequest(t *testing.T) {
+	register := reflect.TypeOf(user.RegisterRequest{})
+	row := reflect.TypeOf(service.ImportRow{})
+
+	for _, name := range []string{"Username", "Email"} {
+		want, ok := register.FieldByName(name)
+		require.True(t, ok, name)
+		got, ok := row.FieldByName(name)
+		require.True(t, ok, name)
+		assert.Equal(t, want.Tag.Get("json"), got.Tag.Get("json"), name)
+		assert.Equal(t, want.Tag.Get("binding"), got.Tag.Get("binding"), name)
+	}
+
+	want, _ := register.FieldByName("Password")
+	got, _ := row.FieldByName("Password")
+	assert.Equal(t, strings.TrimPrefix(want.Tag.Get("binding"), "required,"), got.Tag.Get("binding"))
+}
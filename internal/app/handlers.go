@@ -1,27 +1,75 @@
 package app
 
 import (
+	"net/http"
+
+	"gin_demo/internal/app/handler/apikey"
+	"gin_demo/internal/app/handler/audit"
+	"gin_demo/internal/app/handler/avatar"
 	"gin_demo/internal/app/handler/health"
+	"gin_demo/internal/app/handler/jwks"
+	"gin_demo/internal/app/handler/privacy"
+	"gin_demo/internal/app/handler/role"
 	"gin_demo/internal/app/handler/user"
+	"gin_demo/internal/app/handler/userimport"
 	"gin_demo/internal/app/middleware"
 )
 
 // Handlers 所有 HTTP 处理器
 type Handlers struct {
-	User   *user.Handler
-	Health *health.Handler
-	Auth   *middleware.AuthMiddleware
+	User       *user.Handler
+	UserImport *userimport.Handler
+	APIKeys    *apikey.Handler
+	Health     *health.Handler
+	JWKS       *jwks.Handler
+	Roles      *role.Handler
+	Audit      *audit.Handler
+	Privacy    *privacy.Handler
+	Avatar     *avatar.Handler
+	Uploads    *StaticFiles // 为 nil 时不提供（文件由存储服务直接提供）
+	Auth       *middleware.AuthMiddleware
+	RBAC       *middleware.RBACMiddleware
+	APIKey     *middleware.APIKeyMiddleware
+	Tenant     *middleware.TenantMiddleware
+}
+
+// StaticFiles 静态文件（用户上传的文件）的访问
+type StaticFiles struct {
+	Path    string       // 路由前缀，如 /uploads
+	Handler http.Handler // 处理 {Path}/{文件} 的 GET / HEAD 请求
 }
 
 // NewHandlers 创建处理器集合
 func NewHandlers(
 	userHandler *user.Handler,
+	userImportHandler *userimport.Handler,
+	apiKeyHandler *apikey.Handler,
 	healthHandler *health.Handler,
+	jwksHandler *jwks.Handler,
+	roleHandler *role.Handler,
+	auditHandler *audit.Handler,
+	privacyHandler *privacy.Handler,
+	avatarHandler *avatar.Handler,
+	uploads *StaticFiles,
 	authMiddleware *middleware.AuthMiddleware,
+	rbacMiddleware *middleware.RBACMiddleware,
+	apiKeyMiddleware *middleware.APIKeyMiddleware,
+	tenantMiddleware *middleware.TenantMiddleware,
 ) *Handlers {
 	return &Handlers{
-		User:   userHandler,
-		Health: healthHandler,
-		Auth:   authMiddleware,
+		User:       userHandler,
+		UserImport: userImportHandler,
+		APIKeys:    apiKeyHandler,
+		Health:     healthHandler,
+		JWKS:       jwksHandler,
+		Roles:      roleHandler,
+		Audit:      auditHandler,
+		Privacy:    privacyHandler,
+		Avatar:     avatarHandler,
+		Uploads:    uploads,
+		Auth:       authMiddleware,
+		RBAC:       rbacMiddleware,
+		APIKey:     apiKeyMiddleware,
+		Tenant:     tenantMiddleware,
 	}
 }
@@ -0,0 +1,103 @@
+package middleware
+
+import (
+	"context"
+	"strings"
+
+	"gin_demo/internal/response"
+	"gin_demo/pkg/auth"
+	"gin_demo/pkg/metrics"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// APIKeyHeader API Key 请求头
+	APIKeyHeader = "X-API-Key"
+	// APIKeyPrefix Authorization 头中 API Key 的前缀
+	APIKeyPrefix = "ApiKey "
+)
+
+// APIKeyAuthenticator API Key 校验（由 APIKeyService 实现）
+type APIKeyAuthenticator interface {
+	// Authenticate 校验 API Key，返回所属用户的 Claims（Scopes 为 Key 的权限范围）
+	Authenticate(ctx context.Context, key string) (*auth.RBACClaims, error)
+}
+
+// APIKeyMiddleware API Key 认证中间件
+//
+// 与 RBACMiddleware 设置相同的 context 键（UserIDKey、RBACClaimsKey），
+// 后续的 RequireRole、RequirePermission 等中间件无需区分认证方式。
+// API Key 的权限受创建时授予的范围限制，路由应使用 RequirePermission 做细粒度控制。
+type APIKeyMiddleware struct {
+	keys     APIKeyAuthenticator
+	fallback *RBACMiddleware
+}
+
+// NewAPIKeyMiddleware 创建 API Key 认证中间件
+// fallback 不为 nil 时，请求未携带 API Key 则交给 JWT 认证
+func NewAPIKeyMiddleware(keys APIKeyAuthenticator, fallback *RBACMiddleware) *APIKeyMiddleware {
+	return &APIKeyMiddleware{
+		keys:     keys,
+		fallback: fallback,
+	}
+}
+
+// Handle 处理认证（API Key 或 JWT）
+func (m *APIKeyMiddleware) Handle() gin.HandlerFunc {
+	var jwtAuth gin.HandlerFunc
+	if m.fallback != nil {
+		jwtAuth = m.fallback.Handle()
+	}
+
+	return func(c *gin.Context) {
+		// 1. 提取 API Key（X-API-Key 优先）
+		key, ok := extractAPIKey(c)
+		if !ok {
+			if jwtAuth != nil {
+				jwtAuth(c)
+				return
+			}
+			response.Error(c, response.New(response.CodeUnauthorized, "未提供 API Key"))
+			c.Abort()
+			return
+		}
+
+		// 2. 校验 API Key 并加载所属用户的角色和权限
+		claims, err := m.keys.Authenticate(c.Request.Context(), key)
+		if err != nil {
+			metrics.AuthFailures.WithLabelValues("invalid_api_key").Inc()
+			response.Error(c, err)
+			c.Abort()
+			return
+		}
+
+		// 3. 校验 Key 所属租户（Key 只能在所属用户的租户内使用）
+		if err := bindTenant(c, claims.TenantID); err != nil {
+			response.Error(c, err)
+			c.Abort()
+			return
+		}
+
+		// 4. 将 Claims 存入 context（与 JWT 认证一致）
+		c.Set(UserIDKey, claims.UserID)
+		c.Set(RBACClaimsKey, claims)
+		c.Request = c.Request.WithContext(auth.WithClaims(c.Request.Context(), claims))
+		setAuditActor(c)
+
+		c.Next()
+	}
+}
+
+// extractAPIKey 从 X-API-Key 或 Authorization: ApiKey 中提取 API Key
+func extractAPIKey(c *gin.Context) (string, bool) {
+	if key := c.GetHeader(APIKeyHeader); key != "" {
+		return strings.TrimSpace(key), true
+	}
+
+	authHeader := c.GetHeader(AuthorizationHeader)
+	if strings.HasPrefix(authHeader, APIKeyPrefix) {
+		return strings.TrimSpace(strings.TrimPrefix(authHeader, APIKeyPrefix)), true
+	}
+	return "", false
+}
@@ -0,0 +1,100 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gin_demo/internal/response"
+	"gin_demo/pkg/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubAPIKeyAuthenticator 只接受固定 Key 的 API Key 校验
+type stubAPIKeyAuthenticator struct {
+	key    string
+	claims *auth.RBACClaims
+}
+
+func (s *stubAPIKeyAuthenticator) Authenticate(_ context.Context, key string) (*auth.RBACClaims, error) {
+	if key != s.key {
+		return nil, response.New(response.CodeUnauthorized, "API Key 无效或已过期")
+	}
+	return s.claims, nil
+}
+
+// TestAPIKeyMiddleware 测试 API Key 认证中间件
+func TestAPIKeyMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	jwtManager := auth.NewRBACJWTManager("test-secret", time.Hour)
+	keys := &stubAPIKeyAuthenticator{
+		key:    "gdk_0123456789ab_secret",
+		claims: &auth.RBACClaims{UserID: 42, Role: auth.RoleAdmin, Scopes: []auth.Permission{auth.PermissionUserRead}},
+	}
+	m := NewAPIKeyMiddleware(keys, NewRBACMiddleware(jwtManager, nil))
+
+	router := gin.New()
+	router.GET("/read", m.Handle(), RequirePermission(auth.PermissionUserRead), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"user_id": GetUserID(c), "role": GetUserRole(c)})
+	})
+	router.GET("/write", m.Handle(), RequirePermission(auth.PermissionUserWrite), func(c *gin.Context) {
+		c.Status(http.StatusOK)
+	})
+
+	do := func(path string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Header = header
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("X-API-Key 请求头", func(t *testing.T) {
+		w := do("/read", http.Header{"X-Api-Key": {keys.key}})
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"user_id": 42, "role": "admin"}`, w.Body.String())
+	})
+
+	t.Run("Authorization: ApiKey", func(t *testing.T) {
+		w := do("/read", http.Header{"Authorization": {"ApiKey " + keys.key}})
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("超出权限范围", func(t *testing.T) {
+		w := do("/write", http.Header{"X-Api-Key": {keys.key}})
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("Key 无效", func(t *testing.T) {
+		w := do("/read", http.Header{"X-Api-Key": {"gdk_0123456789ab_wrong"}})
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("未携带 API Key 时使用 JWT 认证", func(t *testing.T) {
+		token, err := jwtManager.GenerateToken(7, auth.RoleAdmin)
+		require.NoError(t, err)
+
+		w := do("/write", http.Header{"Authorization": {"Bearer " + token}})
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = do("/read", http.Header{})
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+
+	t.Run("没有 JWT 回退时必须携带 API Key", func(t *testing.T) {
+		router := gin.New()
+		router.GET("/", NewAPIKeyMiddleware(keys, nil).Handle(), func(c *gin.Context) { c.Status(http.StatusOK) })
+
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer whatever")
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
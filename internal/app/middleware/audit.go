@@ -0,0 +1,32 @@
+package middleware
+
+import (
+	"gin_demo/pkg/audit"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+)
+
+// Audit 审计中间件：将请求信息（IP、Request ID、User-Agent）存入 context，
+// Service 层记录审计事件时自动补全（需放在 Request ID 中间件之后）
+func Audit() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := audit.WithRequest(c.Request.Context(), audit.RequestInfo{
+			IP:        c.ClientIP(),
+			RequestID: requestid.Get(c),
+			UserAgent: c.Request.UserAgent(),
+		})
+		c.Request = c.Request.WithContext(ctx)
+
+		c.Next()
+	}
+}
+
+// setAuditActor 将当前用户和模拟登录的管理员存入 context（认证中间件在设置用户 ID 之后调用）
+func setAuditActor(c *gin.Context) {
+	ctx := audit.WithActor(c.Request.Context(), audit.Actor{
+		UserID:         GetUserID(c),
+		ImpersonatorID: GetImpersonatorID(c),
+	})
+	c.Request = c.Request.WithContext(ctx)
+}
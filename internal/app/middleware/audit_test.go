@@ -0,0 +1,48 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gin_demo/pkg/audit"
+	"gin_demo/pkg/auth"
+
+	"github.com/gin-contrib/requestid"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestAudit 测试审计中间件和认证中间件写入 context 的请求信息与操作者
+func TestAudit(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	jwtManager := auth.NewRBACJWTManager("test-secret", time.Hour)
+
+	var request audit.RequestInfo
+	var actor audit.Actor
+	router := gin.New()
+	router.Use(requestid.New(), Audit())
+	router.GET("/profile", NewRBACMiddleware(jwtManager, nil).Handle(), func(c *gin.Context) {
+		request = audit.RequestFromContext(c.Request.Context())
+		actor = audit.ActorFromContext(c.Request.Context())
+		c.Status(http.StatusOK)
+	})
+
+	token, err := jwtManager.GenerateToken(2, auth.RoleUser)
+	require.NoError(t, err)
+
+	req := httptest.NewRequest(http.MethodGet, "/profile", nil)
+	req.RemoteAddr = "203.0.113.7:4321"
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("User-Agent", "curl/8")
+	req.Header.Set("X-Request-ID", "req-1")
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, req)
+	require.Equal(t, http.StatusOK, w.Code)
+
+	assert.Equal(t, audit.RequestInfo{IP: "203.0.113.7", RequestID: "req-1", UserAgent: "curl/8"}, request)
+	assert.Equal(t, audit.Actor{UserID: 2}, actor)
+}
@@ -1,8 +1,11 @@
 package middleware
 
 import (
+	"context"
+	"errors"
 	"gin_demo/internal/response"
 	"gin_demo/pkg/auth"
+	"gin_demo/pkg/metrics"
 	"strings"
 
 	"github.com/gin-gonic/gin"
@@ -56,19 +59,56 @@ func authenticateRequest(c *gin.Context, jwtManager *auth.DefaultJWTManager) (*a
 	return claims, nil
 }
 
+// TokenVersionSource Token 版本号来源（用于服务端吊销 Token）
+//
+// 用户修改密码、被删除或主动"登出所有设备"时版本号递增，
+// 携带旧版本号的 Token 即使签名有效、未过期也会被拒绝。
+type TokenVersionSource interface {
+	// GetTokenVersion 获取用户当前的 Token 版本号（用户不存在时返回 response.ErrNotFound）
+	GetTokenVersion(ctx context.Context, userID int64) (int64, error)
+}
+
+// checkTokenVersion 校验 Token 中的版本号是否仍然有效（内部函数，versions 为 nil 时跳过）
+func checkTokenVersion(ctx context.Context, versions TokenVersionSource, userID, version int64) error {
+	if versions == nil {
+		return nil
+	}
+
+	current, err := versions.GetTokenVersion(ctx, userID)
+	if err != nil {
+		if errors.Is(err, response.ErrNotFound) {
+			metrics.AuthFailures.WithLabelValues("token_revoked").Inc()
+			return response.New(response.CodeUnauthorized, "用户不存在或已被禁用")
+		}
+		return response.NewWithError(response.CodeInternalError, "Token 校验失败", err)
+	}
+
+	if version != current {
+		metrics.AuthFailures.WithLabelValues("token_revoked").Inc()
+		return response.New(response.CodeUnauthorized, "Token 已失效，请重新登录")
+	}
+
+	return nil
+}
+
 // ============================================================================
 // 兼容性函数（保持向后兼容）
 // ============================================================================
 
 // Auth JWT 认证中间件（函数式，保留用于向后兼容）
-// 推荐使用 AuthMiddleware 结构体版本
+// 推荐使用 AuthMiddleware 结构体版本（支持服务端吊销 Token）
 func Auth(jwtManager *auth.DefaultJWTManager) gin.HandlerFunc {
-	middleware := NewAuthMiddleware(jwtManager)
+	middleware := NewAuthMiddleware(jwtManager, nil)
 	return middleware.Handle()
 }
 
 // OptionalAuth 可选认证中间件（Token 存在时验证，不存在时也放行）
 func OptionalAuth(jwtManager *auth.DefaultJWTManager) gin.HandlerFunc {
+	return optionalAuth(jwtManager, nil)
+}
+
+// optionalAuth 可选认证（内部函数，已吊销的 Token 视为未登录）
+func optionalAuth(jwtManager *auth.DefaultJWTManager, versions TokenVersionSource) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader(AuthorizationHeader)
 		if authHeader == "" {
@@ -83,11 +123,16 @@ func OptionalAuth(jwtManager *auth.DefaultJWTManager) gin.HandlerFunc {
 
 		tokenString := strings.TrimPrefix(authHeader, BearerPrefix)
 		claims, err := jwtManager.ValidateToken(tokenString)
-		if err == nil {
+		if err == nil && bindTenant(c, claims.TenantID) == nil &&
+			checkTokenVersion(c.Request.Context(), versions, claims.UserID, claims.Version) == nil {
 			c.Set(UserIDKey, claims.UserID)
+			setImpersonator(c, claims.ActorID)
+			setAuditActor(c)
 		}
 
 		c.Next()
+
+		auditImpersonation(c)
 	}
 }
 
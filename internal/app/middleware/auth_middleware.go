@@ -10,12 +10,15 @@ import (
 // AuthMiddleware 认证中间件（推荐使用的标准结构体风格）
 type AuthMiddleware struct {
 	jwtManager *auth.DefaultJWTManager
+	versions   TokenVersionSource
 }
 
 // NewAuthMiddleware 创建认证中间件
-func NewAuthMiddleware(jwtManager *auth.DefaultJWTManager) *AuthMiddleware {
+// versions 为 nil 时只校验签名和有效期，不支持服务端吊销
+func NewAuthMiddleware(jwtManager *auth.DefaultJWTManager, versions TokenVersionSource) *AuthMiddleware {
 	return &AuthMiddleware{
 		jwtManager: jwtManager,
+		versions:   versions,
 	}
 }
 
@@ -29,14 +32,33 @@ func (m *AuthMiddleware) Handle() gin.HandlerFunc {
 			return
 		}
 
+		// 校验 Token 所属租户（请求未指定租户时使用 Token 中的租户）
+		if err := bindTenant(c, claims.TenantID); err != nil {
+			response.Error(c, err)
+			c.Abort()
+			return
+		}
+
+		// 校验 Token 版本号（已吊销的 Token 立即失效）
+		if err := checkTokenVersion(c.Request.Context(), m.versions, claims.UserID, claims.Version); err != nil {
+			response.Error(c, err)
+			c.Abort()
+			return
+		}
+
 		// 将用户 ID 存入 context
 		c.Set(UserIDKey, claims.UserID)
+		setImpersonator(c, claims.ActorID)
+		setAuditActor(c)
 
 		c.Next()
+
+		// 模拟登录期间的请求记录审计日志
+		auditImpersonation(c)
 	}
 }
 
 // HandleOptional 可选认证（Token 存在时验证，不存在时也放行）
 func (m *AuthMiddleware) HandleOptional() gin.HandlerFunc {
-	return OptionalAuth(m.jwtManager)
+	return optionalAuth(m.jwtManager, m.versions)
 }
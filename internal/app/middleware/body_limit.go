@@ -0,0 +1,38 @@
+package middleware
+
+import (
+	"errors"
+	"net/http"
+
+	"gin_demo/internal/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+// BodyLimit 请求体大小限制中间件
+//
+// Content-Length 超过 maxBytes 时直接返回 413；未声明长度（分块传输）的请求体在读取超过 maxBytes 时出错，
+// Handler 绑定参数失败后可以用 IsBodyTooLarge 区分。maxBytes <= 0 时不限制。
+func BodyLimit(maxBytes int64) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if maxBytes <= 0 || c.Request.Body == nil {
+			c.Next()
+			return
+		}
+
+		if c.Request.ContentLength > maxBytes {
+			response.Error(c, response.ErrRequestTooLarge)
+			c.Abort()
+			return
+		}
+
+		c.Request.Body = http.MaxBytesReader(c.Writer, c.Request.Body, maxBytes)
+		c.Next()
+	}
+}
+
+// IsBodyTooLarge 判断读取请求体的错误是否因为超过 BodyLimit 的限制
+func IsBodyTooLarge(err error) bool {
+	var maxErr *http.MaxBytesError
+	return errors.As(err, &maxErr)
+}
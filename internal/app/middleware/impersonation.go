@@ -0,0 +1,81 @@
+package middleware
+
+import (
+	"log/slog"
+
+	"gin_demo/internal/response"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// ImpersonatorIDKey 模拟登录时实际操作的管理员 ID 在 context 中的键名
+	ImpersonatorIDKey = "impersonator_id"
+)
+
+// setImpersonator 记录模拟登录的管理员（认证中间件调用，actorID 为 0 表示不是模拟登录）
+func setImpersonator(c *gin.Context, actorID int64) {
+	if actorID != 0 {
+		c.Set(ImpersonatorIDKey, actorID)
+	}
+}
+
+// auditImpersonation 记录模拟登录期间的请求（认证中间件在 c.Next() 之后调用）
+func auditImpersonation(c *gin.Context) {
+	actorID := GetImpersonatorID(c)
+	if actorID == 0 {
+		return
+	}
+
+	slog.InfoContext(c.Request.Context(), "Impersonated request",
+		"audit", "impersonation",
+		"actor_id", actorID,
+		"user_id", GetUserID(c),
+		"tenant_id", GetTenantID(c),
+		"method", c.Request.Method,
+		"path", c.Request.URL.Path,
+		"status", c.Writer.Status(),
+		"ip", c.ClientIP(),
+	)
+}
+
+// GetImpersonatorID 获取模拟登录的管理员 ID（不是模拟登录时返回 0）
+// 模拟登录时 GetUserID 和 GetRBACClaims 返回被模拟的用户
+func GetImpersonatorID(c *gin.Context) int64 {
+	actorID, exists := c.Get(ImpersonatorIDKey)
+	if !exists {
+		return 0
+	}
+	id, ok := actorID.(int64)
+	if !ok {
+		return 0
+	}
+	return id
+}
+
+// IsImpersonating 当前请求是否为模拟登录
+func IsImpersonating(c *gin.Context) bool {
+	return GetImpersonatorID(c) != 0
+}
+
+// GetActorID 获取实际操作的用户 ID（模拟登录时为管理员，否则为当前用户）
+// 用于审计记录等需要追溯到真实操作者的场景
+func GetActorID(c *gin.Context) int64 {
+	if actorID := GetImpersonatorID(c); actorID != 0 {
+		return actorID
+	}
+	return GetUserID(c)
+}
+
+// DenyImpersonation 模拟登录期间禁止访问（中间件，用于修改密码、删除用户等破坏性操作）
+func DenyImpersonation() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if IsImpersonating(c) {
+			response.Error(c, response.New(response.CodeForbidden, "模拟登录期间不允许此操作"))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
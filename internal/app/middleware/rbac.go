@@ -11,17 +11,26 @@ import (
 const (
 	// RBACClaimsKey RBAC Claims 在 context 中的键名
 	RBACClaimsKey = "rbac_claims"
+	// ResourceKey RequirePolicy 加载的资源在 context 中的键名
+	ResourceKey = "resource"
 )
 
+// ResourceResolver 根据请求加载被访问的资源（如按路径参数查询用户）
+// 返回的错误直接作为响应（如资源不存在返回 404）
+type ResourceResolver func(c *gin.Context) (*auth.Resource, error)
+
 // RBACMiddleware RBAC 认证中间件
 type RBACMiddleware struct {
 	jwtManager *auth.RBACJWTManager
+	versions   TokenVersionSource
 }
 
 // NewRBACMiddleware 创建 RBAC 认证中间件
-func NewRBACMiddleware(jwtManager *auth.RBACJWTManager) *RBACMiddleware {
+// versions 为 nil 时只校验签名和有效期，不支持服务端吊销
+func NewRBACMiddleware(jwtManager *auth.RBACJWTManager, versions TokenVersionSource) *RBACMiddleware {
 	return &RBACMiddleware{
 		jwtManager: jwtManager,
+		versions:   versions,
 	}
 }
 
@@ -66,11 +75,31 @@ func (m *RBACMiddleware) Handle() gin.HandlerFunc {
 			return
 		}
 
-		// 5. 将 Claims 存入 context（包含用户ID、角色和权限）
+		// 5. 校验 Token 所属租户（请求未指定租户时使用 Token 中的租户）
+		if err := bindTenant(c, claims.TenantID); err != nil {
+			response.Error(c, err)
+			c.Abort()
+			return
+		}
+
+		// 6. 校验 Token 版本号（已吊销的 Token 立即失效）
+		if err := checkTokenVersion(c.Request.Context(), m.versions, claims.UserID, claims.Version); err != nil {
+			response.Error(c, err)
+			c.Abort()
+			return
+		}
+
+		// 7. 将 Claims 存入 context（包含用户ID、角色和权限）
 		c.Set(UserIDKey, claims.UserID)
 		c.Set(RBACClaimsKey, claims)
+		c.Request = c.Request.WithContext(auth.WithClaims(c.Request.Context(), claims)) // 供 Service 层 auth.Can 使用
+		setImpersonator(c, claims.ActorID)
+		setAuditActor(c)
 
 		c.Next()
+
+		// 8. 模拟登录期间的请求记录审计日志
+		auditImpersonation(c)
 	}
 }
 
@@ -134,6 +163,38 @@ func RequireAnyPermission(permissions ...auth.Permission) gin.HandlerFunc {
 	}
 }
 
+// RequirePolicy 要求对资源的访问策略允许（中间件）
+//
+// 先通过 resolve 加载资源，再由 ABAC 引擎（auth.DefaultPolicyEngine）判断当前用户能否执行 action。
+// 加载的资源存入 context（ResourceKey），Handler 可以通过 GetResource 获取。
+// 应放在 RequireRole/RequirePermission 之后，只做资源级约束。
+func RequirePolicy(action auth.Action, resolve ResourceResolver) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		claims := GetRBACClaims(c)
+		if claims == nil {
+			response.Error(c, response.New(response.CodeUnauthorized, "未认证"))
+			c.Abort()
+			return
+		}
+
+		resource, err := resolve(c)
+		if err != nil {
+			response.Error(c, err)
+			c.Abort()
+			return
+		}
+
+		if !auth.DefaultPolicyEngine().Allowed(claims, action, resource) {
+			response.Error(c, response.New(response.CodeForbidden, "权限不足：无权操作该资源"))
+			c.Abort()
+			return
+		}
+
+		c.Set(ResourceKey, resource)
+		c.Next()
+	}
+}
+
 // RequireAdmin 要求管理员权限（中间件）
 func RequireAdmin() gin.HandlerFunc {
 	return RequireRole(auth.RoleAdmin, auth.RoleSuperAdmin)
@@ -157,6 +218,19 @@ func GetRBACClaims(c *gin.Context) *auth.RBACClaims {
 	return rbacClaims
 }
 
+// GetResource 从 context 中获取 RequirePolicy 加载的资源
+func GetResource(c *gin.Context) *auth.Resource {
+	resource, exists := c.Get(ResourceKey)
+	if !exists {
+		return nil
+	}
+	r, ok := resource.(*auth.Resource)
+	if !ok {
+		return nil
+	}
+	return r
+}
+
 // GetUserRole 从 context 中获取用户角色
 func GetUserRole(c *gin.Context) auth.Role {
 	claims := GetRBACClaims(c)
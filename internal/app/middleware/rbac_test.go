@@ -0,0 +1,90 @@
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"gin_demo/internal/response"
+	"gin_demo/pkg/auth"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRequirePolicy 测试资源级授权中间件
+func TestRequirePolicy(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	// 用户 1 为管理员，用户 2 为普通用户，用户 3 为超级管理员
+	roles := map[int64]auth.Role{1: auth.RoleAdmin, 2: auth.RoleUser, 3: auth.RoleSuperAdmin}
+	resolve := func(c *gin.Context) (*auth.Resource, error) {
+		id, _ := strconv.ParseInt(c.Param("id"), 10, 64)
+		role, ok := roles[id]
+		if !ok {
+			return nil, response.New(response.CodeNotFound, "用户不存在")
+		}
+		return &auth.Resource{Type: auth.ResourceUser, ID: id, OwnerID: id, Attributes: map[string]any{auth.AttrRole: role}}, nil
+	}
+
+	jwtManager := auth.NewRBACJWTManager("test-secret", time.Hour)
+	rbac := NewRBACMiddleware(jwtManager, nil)
+
+	router := gin.New()
+	router.PUT("/users/:id", rbac.Handle(), RequirePolicy(auth.ActionUpdate, resolve), func(c *gin.Context) {
+		// Handler 可以获取已加载的资源，Service 层可以再次通过 auth.Can 检查
+		resource := GetResource(c)
+		if err := auth.Can(c.Request.Context(), auth.ActionUpdate, resource); err != nil {
+			c.Status(http.StatusInternalServerError)
+			return
+		}
+		c.JSON(http.StatusOK, gin.H{"id": resource.ID})
+	})
+
+	do := func(userID int64, role auth.Role, target string) *httptest.ResponseRecorder {
+		token, err := jwtManager.GenerateToken(userID, role)
+		require.NoError(t, err)
+		req := httptest.NewRequest(http.MethodPut, "/users/"+target, nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+
+	t.Run("管理员修改普通用户", func(t *testing.T) {
+		w := do(1, auth.RoleAdmin, "2")
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"id": 2}`, w.Body.String())
+	})
+
+	t.Run("管理员修改超级管理员", func(t *testing.T) {
+		w := do(1, auth.RoleAdmin, "3")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("普通用户修改自己", func(t *testing.T) {
+		w := do(2, auth.RoleUser, "2")
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("普通用户修改他人", func(t *testing.T) {
+		w := do(2, auth.RoleUser, "1")
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+
+	t.Run("资源不存在", func(t *testing.T) {
+		w := do(3, auth.RoleSuperAdmin, "404")
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("未认证", func(t *testing.T) {
+		r := gin.New()
+		r.PUT("/users/:id", RequirePolicy(auth.ActionUpdate, resolve), func(c *gin.Context) { c.Status(http.StatusOK) })
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, httptest.NewRequest(http.MethodPut, "/users/2", nil))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+	})
+}
@@ -0,0 +1,149 @@
+package middleware
+
+import (
+	"context"
+	"net"
+	"strings"
+
+	"gin_demo/internal/response"
+	"gin_demo/pkg/metrics"
+	"gin_demo/pkg/tenant"
+
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	// TenantIDKey 租户 ID 在 context 中的键名
+	TenantIDKey = "tenant_id"
+	// DefaultTenantHeader 默认的租户请求头
+	DefaultTenantHeader = "X-Tenant-ID"
+)
+
+// TenantResolver 租户解析（由 TenantService 实现）
+type TenantResolver interface {
+	// Resolve 通过标识查找租户，返回租户 ID（租户不存在或已停用时返回错误）
+	Resolve(ctx context.Context, slug string) (int64, error)
+}
+
+// TenantMiddleware 租户解析中间件
+//
+// 依次从子域名（配置了 baseDomain 时）和请求头中获取租户标识，两者同时存在时必须一致。
+// 解析出的租户 ID 存入 request context（tenant.WithID），Repository 据此隔离数据。
+// 两者都没有时不设置租户：认证中间件使用 Token 中的租户，未认证的请求属于默认租户。
+type TenantMiddleware struct {
+	resolver   TenantResolver
+	baseDomain string
+	header     string
+}
+
+// NewTenantMiddleware 创建租户解析中间件
+// baseDomain 为空时不按子域名解析；header 为空时使用 DefaultTenantHeader
+func NewTenantMiddleware(resolver TenantResolver, baseDomain, header string) *TenantMiddleware {
+	if header == "" {
+		header = DefaultTenantHeader
+	}
+	return &TenantMiddleware{
+		resolver:   resolver,
+		baseDomain: strings.ToLower(strings.TrimSuffix(baseDomain, ".")),
+		header:     header,
+	}
+}
+
+// Handle 解析请求所属的租户
+func (m *TenantMiddleware) Handle() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		// 1. 提取租户标识（子域名和请求头不一致时拒绝，避免混淆）
+		fromHost := m.subdomain(c.Request.Host)
+		fromHeader := strings.ToLower(strings.TrimSpace(c.GetHeader(m.header)))
+		if fromHost != "" && fromHeader != "" && fromHost != fromHeader {
+			response.Error(c, response.New(response.CodeInvalidParams, "子域名与 "+m.header+" 指定的租户不一致"))
+			c.Abort()
+			return
+		}
+
+		slug := fromHost
+		if slug == "" {
+			slug = fromHeader
+		}
+		if slug == "" {
+			c.Next()
+			return
+		}
+
+		// 2. 查找租户（不存在或已停用时拒绝）
+		tenantID, err := m.resolver.Resolve(c.Request.Context(), slug)
+		if err != nil {
+			response.Error(c, err)
+			c.Abort()
+			return
+		}
+
+		// 3. 将租户 ID 存入 context
+		setTenant(c, tenantID)
+
+		c.Next()
+	}
+}
+
+// subdomain 从 Host 中提取租户子域名（只取 baseDomain 的下一级）
+func (m *TenantMiddleware) subdomain(host string) string {
+	if m.baseDomain == "" {
+		return ""
+	}
+	if h, _, err := net.SplitHostPort(host); err == nil {
+		host = h
+	}
+	host = strings.ToLower(strings.TrimSuffix(host, "."))
+
+	prefix, ok := strings.CutSuffix(host, "."+m.baseDomain)
+	if !ok || prefix == "" || strings.Contains(prefix, ".") {
+		return ""
+	}
+	return prefix
+}
+
+// setTenant 将租户 ID 存入 request context 和 gin context
+func setTenant(c *gin.Context, tenantID int64) {
+	c.Set(TenantIDKey, tenantID)
+	c.Request = c.Request.WithContext(tenant.WithID(c.Request.Context(), tenantID))
+}
+
+// bindTenant 校验 Token 所属租户与请求的租户一致（认证中间件在校验版本号之前调用）
+//
+// 请求未指定租户时使用 Token 中的租户；Token 中没有租户（多租户之前签发）时视为默认租户。
+func bindTenant(c *gin.Context, claimsTenantID int64) error {
+	if claimsTenantID == 0 {
+		claimsTenantID = tenant.DefaultID
+	}
+
+	if current, ok := tenant.FromContext(c.Request.Context()); ok {
+		if current != claimsTenantID {
+			metrics.AuthFailures.WithLabelValues("tenant_mismatch").Inc()
+			return response.New(response.CodeUnauthorized, "Token 不属于当前租户")
+		}
+		return nil
+	}
+
+	setTenant(c, claimsTenantID)
+	return nil
+}
+
+// RequireDefaultTenant 只允许默认租户访问（中间件，用于修改所有租户共享的全局配置，如角色与权限）
+//
+// 放在认证中间件之后，此时租户已由请求或 Token 确定；其他租户的超级管理员返回 403。
+func RequireDefaultTenant() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if GetTenantID(c) != tenant.DefaultID {
+			response.Error(c, response.New(response.CodeForbidden, "只有默认租户可以执行此操作"))
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// GetTenantID 从 context 中获取租户 ID（未指定时返回默认租户）
+func GetTenantID(c *gin.Context) int64 {
+	return tenant.ID(c.Request.Context())
+}
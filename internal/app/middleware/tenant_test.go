@@ -0,0 +1,122 @@
+package middleware
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"gin_demo/internal/response"
+	"gin_demo/pkg/auth"
+	"gin_demo/pkg/tenant"
+
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubTenantResolver 固定租户列表的租户解析
+type stubTenantResolver map[string]int64
+
+func (s stubTenantResolver) Resolve(_ context.Context, slug string) (int64, error) {
+	id, ok := s[slug]
+	if !ok {
+		return 0, response.New(response.CodeNotFound, "租户不存在")
+	}
+	return id, nil
+}
+
+// TestTenantMiddleware 测试租户解析及与 Token 租户的绑定
+func TestTenantMiddleware(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+
+	jwtManager := auth.NewRBACJWTManager("test-secret", time.Hour)
+	tenants := NewTenantMiddleware(stubTenantResolver{"default": tenant.DefaultID, "acme": 2}, "example.com", "")
+
+	router := gin.New()
+	router.Use(tenants.Handle())
+	router.GET("/public", func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"tenant_id": GetTenantID(c)})
+	})
+	router.GET("/private", NewRBACMiddleware(jwtManager, nil).Handle(), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"tenant_id": GetTenantID(c)})
+	})
+	router.GET("/global", NewRBACMiddleware(jwtManager, nil).Handle(), RequireDefaultTenant(), func(c *gin.Context) {
+		c.JSON(http.StatusOK, gin.H{"tenant_id": GetTenantID(c)})
+	})
+
+	do := func(host, path string, header http.Header) *httptest.ResponseRecorder {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		req.Host = host
+		if header != nil {
+			req.Header = header
+		}
+		w := httptest.NewRecorder()
+		router.ServeHTTP(w, req)
+		return w
+	}
+	bearer := func(tenantID int64) http.Header {
+		token, err := jwtManager.GenerateTenantToken(tenantID, 1, 0, auth.RoleUser)
+		require.NoError(t, err)
+		return http.Header{"Authorization": {"Bearer " + token}}
+	}
+
+	t.Run("子域名", func(t *testing.T) {
+		w := do("acme.example.com:8080", "/public", nil)
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"tenant_id": 2}`, w.Body.String())
+	})
+
+	t.Run("请求头", func(t *testing.T) {
+		w := do("api.internal", "/public", http.Header{"X-Tenant-Id": {"ACME"}})
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"tenant_id": 2}`, w.Body.String())
+	})
+
+	t.Run("未指定时为默认租户", func(t *testing.T) {
+		// 多级子域名和 baseDomain 本身都不视为租户
+		for _, host := range []string{"example.com", "a.b.example.com", "localhost"} {
+			w := do(host, "/public", nil)
+			require.Equal(t, http.StatusOK, w.Code, host)
+			assert.JSONEq(t, `{"tenant_id": 1}`, w.Body.String(), host)
+		}
+	})
+
+	t.Run("子域名与请求头不一致", func(t *testing.T) {
+		w := do("acme.example.com", "/public", http.Header{"X-Tenant-Id": {"default"}})
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+	})
+
+	t.Run("租户不存在", func(t *testing.T) {
+		w := do("nobody.example.com", "/public", nil)
+		assert.Equal(t, http.StatusNotFound, w.Code)
+	})
+
+	t.Run("未指定租户时使用 Token 中的租户", func(t *testing.T) {
+		w := do("localhost", "/private", bearer(2))
+		require.Equal(t, http.StatusOK, w.Code)
+		assert.JSONEq(t, `{"tenant_id": 2}`, w.Body.String())
+	})
+
+	t.Run("Token 不属于当前租户", func(t *testing.T) {
+		w := do("acme.example.com", "/private", bearer(tenant.DefaultID))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+		// 没有租户声明的旧 Token 属于默认租户
+		w = do("acme.example.com", "/private", bearer(0))
+		assert.Equal(t, http.StatusUnauthorized, w.Code)
+		w = do("example.com", "/private", bearer(0))
+		assert.Equal(t, http.StatusOK, w.Code)
+	})
+
+	t.Run("全局配置只允许默认租户修改", func(t *testing.T) {
+		w := do("localhost", "/global", bearer(tenant.DefaultID))
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		w = do("localhost", "/global", bearer(2))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+		w = do("acme.example.com", "/global", bearer(2))
+		assert.Equal(t, http.StatusForbidden, w.Code)
+	})
+}
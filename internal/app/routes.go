@@ -26,12 +26,22 @@ func setupSystemRoutes(engine *gin.Engine, handlers *Handlers) {
 		health.GET("/ready", handlers.Health.Ready) // Readiness Probe
 		health.GET("/live", handlers.Health.Live)   // Liveness Probe
 	}
+
+	// JWT 验证公钥（供其他服务验证 Token）
+	engine.GET("/.well-known/jwks.json", handlers.JWKS.JWKS)
+
+	// 用户上传的文件（本地存储）
+	if handlers.Uploads != nil {
+		uploads := gin.WrapH(handlers.Uploads.Handler)
+		engine.GET(handlers.Uploads.Path+"/*filepath", uploads)
+		engine.HEAD(handlers.Uploads.Path+"/*filepath", uploads)
+	}
 }
 
 // setupAPIRoutes 配置 API 路由
 func setupAPIRoutes(engine *gin.Engine, handlers *Handlers) {
 	// API v1
-	v1 := engine.Group("/api/v1")
+	v1 := engine.Group("/api/v1", handlers.Tenant.Handle()) // 解析请求所属租户
 	{
 		setupAPIv1Routes(v1, handlers)
 	}
@@ -52,6 +52,8 @@ func (s *Server) SetupMiddlewares() {
 		s.configureCompressionMiddleware(), // Gzip 压缩
 		s.configureCORS(),                  // CORS
 		s.configureRequestID(),             // Request ID
+		middleware.BodyLimit(s.config.Server.MaxRequestBodySize), // 请求体大小
+		middleware.Audit(),                 // 审计日志请求信息
 		middleware.Logger(),                // 日志
 		middleware.RateLimit(middleware.NewRateLimiter(100, 200)), // 限流
 	)
@@ -9,15 +9,33 @@ import (
 
 // setupAPIv1Routes 配置 API v1 路由
 func setupAPIv1Routes(rg *gin.RouterGroup, handlers *Handlers) {
+	// 认证路由
+	setupAuthRoutes(rg, handlers)
+
 	// 用户路由
 	setupUserRoutes(rg, handlers)
 
+	// API Key 路由
+	setupAPIKeyRoutes(rg, handlers)
+
+	// 管理后台路由
+	setupAdminRoutes(rg, handlers)
+
 	// 可以在这里添加更多 v1 模块路由
 	// setupArticleRoutes(rg, handlers)
 	// setupCommentRoutes(rg, handlers)
 	// setupOrderRoutes(rg, handlers)
 }
 
+// setupAuthRoutes 配置认证路由（Token 刷新与登出，凭 Refresh Token 访问）
+func setupAuthRoutes(rg *gin.RouterGroup, handlers *Handlers) {
+	tokens := rg.Group("/auth")
+	{
+		tokens.POST("/refresh", handlers.User.RefreshToken) // 刷新 Token（轮换 Refresh Token）
+		tokens.POST("/logout", handlers.User.Logout)        // 登出（吊销 Refresh Token）
+	}
+}
+
 // setupUserRoutes 配置用户路由（包含 RBAC 权限控制）
 func setupUserRoutes(rg *gin.RouterGroup, handlers *Handlers) {
 	users := rg.Group("/users")
@@ -27,29 +45,78 @@ func setupUserRoutes(rg *gin.RouterGroup, handlers *Handlers) {
 		// ========================================
 		users.POST("/register", handlers.User.Register) // 用户注册
 		users.POST("/login", handlers.User.Login)       // 用户登录
+		users.POST("/login/mfa", handlers.User.LoginMFA) // 登录第二步（两步验证）
+		users.POST("/verify-email", handlers.User.VerifyEmail)               // 验证邮箱
+		users.POST("/verify-email/resend", handlers.User.ResendVerification) // 重新发送验证邮件
+		users.POST("/password/forgot", handlers.User.ForgotPassword)         // 忘记密码（发送重置邮件）
+		users.POST("/password/reset", handlers.User.ResetPassword)           // 重置密码
+
+		// 第三方登录（OAuth2 / OIDC）
+		users.GET("/oauth/providers", handlers.User.OAuthProviders)            // 第三方登录提供方
+		users.GET("/oauth/:provider/authorize", handlers.User.OAuthAuthorize)  // 开始第三方登录
+		users.POST("/oauth/:provider/callback", handlers.User.OAuthCallback)   // 完成第三方登录
 
 		// ========================================
 		// 个人资料路由（需要认证，操作当前用户）
 		// ========================================
 		profile := users.Group("")
 		profile.Use(handlers.Auth.Handle()) // 基础认证即可
+		// 模拟登录期间只能查看，修改资料（邮箱决定密码重置邮件的去向）、修改密码、两步验证、第三方账户关联等被禁止
+		noImpersonation := middleware.DenyImpersonation()
 		{
 			profile.GET("/me", handlers.User.GetProfile)              // 获取当前用户信息
-			profile.PUT("/me", handlers.User.UpdateProfile)           // 更新当前用户信息
-			profile.PUT("/me/password", handlers.User.ChangePassword) // 修改密码
+			profile.PUT("/me", noImpersonation, handlers.User.UpdateProfile) // 更新当前用户信息
+			profile.PUT("/me/password", noImpersonation, handlers.User.ChangePassword) // 修改密码
+			profile.DELETE("/me/sessions", noImpersonation, handlers.User.RevokeSessions) // 登出所有设备
+
+			// 头像（上传后裁剪缩放并重新编码，替换或清除时删除原来上传的文件）
+			profile.POST("/me/avatar", noImpersonation, handlers.Avatar.Upload)   // 上传头像
+			profile.DELETE("/me/avatar", noImpersonation, handlers.Avatar.Delete) // 清除头像
+
+			// 两步验证（TOTP）
+			profile.GET("/me/mfa", handlers.User.GetMFAStatus)                            // 两步验证状态
+			profile.DELETE("/me/mfa", noImpersonation, handlers.User.DisableMFA)                           // 关闭两步验证
+			profile.POST("/me/mfa/totp", noImpersonation, handlers.User.EnrollTOTP)                        // 开始绑定 TOTP
+			profile.POST("/me/mfa/totp/confirm", noImpersonation, handlers.User.ConfirmTOTP)               // 确认绑定 TOTP
+			profile.POST("/me/mfa/recovery-codes", noImpersonation, handlers.User.RegenerateRecoveryCodes) // 重新生成恢复码
+
+			// 第三方账户关联
+			profile.GET("/me/identities", handlers.User.ListIdentities)                              // 已关联的第三方账户
+			profile.POST("/me/identities/:provider", noImpersonation, handlers.User.LinkIdentityAuthorize)            // 开始关联
+			profile.POST("/me/identities/:provider/callback", noImpersonation, handlers.User.LinkIdentityCallback)    // 完成关联
+			profile.DELETE("/me/identities/:provider", noImpersonation, handlers.User.UnlinkIdentity)                 // 解除关联
+
+			// 个人数据导出与删除（后台作业，通过作业查询接口获取进度）
+			profile.GET("/me/export", noImpersonation, handlers.Privacy.Export)                      // 导出个人数据
+			profile.POST("/me/erasure", noImpersonation, handlers.Privacy.RequestErasure)            // 删除个人数据（匿名化，不可撤销）
+			profile.GET("/me/jobs/:id", handlers.Privacy.GetJob)                                     // 查询作业状态
+			profile.GET("/me/jobs/:id/download", noImpersonation, handlers.Privacy.Download)         // 下载导出文件
 		}
 
 		// ========================================
 		// 管理员路由（需要管理员权限）
 		// ========================================
 		// 方式 1: 使用 RequireRole 中间件（推荐）
+		// 同时接受 API Key（服务间调用），每个路由再按权限细分，API Key 只能访问其权限范围内的路由
 		admin := users.Group("")
-		admin.Use(handlers.Auth.Handle())                                            // 先认证
+		admin.Use(handlers.APIKey.Handle())                                          // 先认证（JWT 或 API Key）
 		admin.Use(middleware.RequireRole(auth.RoleAdmin, auth.RoleSuperAdmin))     // 再检查角色
+		admin.Use(middleware.DenyImpersonation())                                    // 模拟登录期间禁止（不能以被模拟的管理员身份操作其他用户）
 		{
-			admin.GET("", handlers.User.ListUsers)         // 用户列表（需要 admin 或 super_admin 角色）
-			admin.GET("/:id", handlers.User.GetUser)       // 获取指定用户
-			admin.PUT("/:id", handlers.User.UpdateUser)    // 更新指定用户
+			admin.GET("", middleware.RequirePermission(auth.PermissionUserRead), handlers.User.ListUsers)                   // 用户列表（需要 admin 或 super_admin 角色）
+			admin.POST("/batch", middleware.RequirePermission(auth.PermissionUserWrite), handlers.User.BatchUsers)          // 批量更新、停用、删除（每一条按目标用户单独校验策略）
+			admin.GET("/export", middleware.RequirePermission(auth.PermissionUserRead), handlers.UserImport.Export)                 // 导出用户列表（CSV / JSONL，流式写入）
+			admin.POST("/imports", middleware.RequirePermission(auth.PermissionUserWrite), handlers.UserImport.Import)             // 批量导入用户（后台作业）
+			admin.GET("/imports/:id", middleware.RequirePermission(auth.PermissionUserWrite), handlers.UserImport.GetImport)       // 查询导入作业
+			admin.GET("/imports/:id/errors", middleware.RequirePermission(auth.PermissionUserWrite), handlers.UserImport.DownloadReport) // 下载导入错误报告
+			admin.GET("/:id", middleware.RequirePermission(auth.PermissionUserRead), handlers.User.GetUser)                 // 获取指定用户
+			admin.PUT("/:id", middleware.RequirePermission(auth.PermissionUserWrite),
+				middleware.RequirePolicy(auth.ActionUpdate, handlers.User.ResolveUser), handlers.User.UpdateUser) // 更新指定用户（只能修改级别更低的用户）
+			admin.DELETE("/:id/lockout", middleware.RequirePermission(auth.PermissionUserWrite), handlers.User.UnlockLogin) // 解除登录锁定
+			admin.POST("/:id/suspend", middleware.RequirePermission(auth.PermissionUserWrite),
+				middleware.RequirePolicy(auth.ActionSuspend, handlers.User.ResolveUserAnyStatus), handlers.User.SuspendUser) // 停用用户（需要说明原因）
+			admin.POST("/:id/reactivate", middleware.RequirePermission(auth.PermissionUserWrite),
+				middleware.RequirePolicy(auth.ActionSuspend, handlers.User.ResolveUserAnyStatus), handlers.User.ReactivateUser) // 重新启用已停用的用户
 		}
 
 		// ========================================
@@ -57,22 +124,58 @@ func setupUserRoutes(rg *gin.RouterGroup, handlers *Handlers) {
 		// ========================================
 		// 方式 2: 使用 RequireSuperAdmin 快捷中间件
 		superAdmin := users.Group("")
-		superAdmin.Use(handlers.Auth.Handle())              // 先认证
+		superAdmin.Use(handlers.RBAC.Handle())              // 先认证（提取角色）
 		superAdmin.Use(middleware.RequireSuperAdmin())      // 超级管理员专用
+		superAdmin.Use(middleware.DenyImpersonation())      // 模拟登录期间禁止
 		{
-			superAdmin.DELETE("/:id", handlers.User.DeleteUser) // 删除用户（仅超级管理员）
+			superAdmin.DELETE("/:id", middleware.RequirePolicy(auth.ActionDelete, handlers.User.ResolveUser), handlers.User.DeleteUser)             // 删除用户（仅超级管理员，不能删除自己和其他超级管理员）
+			superAdmin.PUT("/:id/role", middleware.RequirePolicy(auth.ActionAssignRole, handlers.User.ResolveUser), handlers.User.UpdateUserRole) // 修改用户角色（仅超级管理员，同上）
+			superAdmin.POST("/:id/restore", middleware.RequirePolicy(auth.ActionDelete, handlers.User.ResolveUserAnyStatus), handlers.User.RestoreUser) // 恢复已删除的用户（保留期内）
+			superAdmin.POST("/:id/erasure", middleware.RequirePolicy(auth.ActionDelete, handlers.User.ResolveUserAnyStatus), handlers.Privacy.EraseUser) // 删除用户数据（匿名化，不可撤销）
+			superAdmin.POST("/:id/merge", middleware.RequirePolicy(auth.ActionDelete, handlers.User.ResolveUserAnyStatus), handlers.User.MergeUser) // 合并账户（数据转移到 into 指定的用户，支持预演）
 		}
+	}
+}
 
-		// ========================================
-		// 基于权限的路由示例（细粒度控制）
-		// ========================================
-		// 方式 3: 使用 RequirePermission 中间件
-		// management := users.Group("/management")
-		// management.Use(handlers.Auth.Handle())
-		// management.Use(middleware.RequirePermission(auth.PermissionUserWrite))
-		// {
-		//     management.POST("/batch", handlers.User.BatchUpdate)  // 批量更新（需要 user:write 权限）
-		// }
+// setupAPIKeyRoutes 配置 API Key 管理路由
+//
+// 只接受 JWT 认证：API Key 不能用来创建或吊销 API Key
+func setupAPIKeyRoutes(rg *gin.RouterGroup, handlers *Handlers) {
+	keys := rg.Group("/api-keys")
+	keys.Use(handlers.RBAC.Handle())
+	keys.Use(middleware.DenyImpersonation()) // 模拟登录期间不能创建或吊销 API Key
+	{
+		keys.POST("", handlers.APIKeys.Create)         // 创建 API Key
+		keys.GET("", handlers.APIKeys.List)            // API Key 列表
+		keys.DELETE("/:id", handlers.APIKeys.Revoke)   // 吊销 API Key
+	}
+}
+
+// setupAdminRoutes 配置管理后台路由（仅超级管理员，模拟登录期间禁止访问）
+func setupAdminRoutes(rg *gin.RouterGroup, handlers *Handlers) {
+	admin := rg.Group("/admin")
+	admin.Use(handlers.RBAC.Handle())
+	admin.Use(middleware.RequireSuperAdmin())
+	admin.Use(middleware.DenyImpersonation())
+	{
+		// 角色与权限（修改后立即生效，无需重新部署）
+		// 角色与权限由所有租户共享，只有默认租户的超级管理员可以修改
+		defaultTenant := middleware.RequireDefaultTenant()
+		admin.GET("/roles", handlers.Roles.ListRoles)                                      // 角色列表
+		admin.POST("/roles", defaultTenant, handlers.Roles.CreateRole)                     // 创建角色
+		admin.GET("/roles/:name", handlers.Roles.GetRole)                                  // 获取角色
+		admin.PUT("/roles/:name", defaultTenant, handlers.Roles.UpdateRole)                // 更新角色
+		admin.DELETE("/roles/:name", defaultTenant, handlers.Roles.DeleteRole)             // 删除角色
+		admin.GET("/permissions", handlers.Roles.ListPermissions)                          // 权限列表
+		admin.POST("/permissions", defaultTenant, handlers.Roles.CreatePermission)         // 创建权限
+		admin.DELETE("/permissions/:name", defaultTenant, handlers.Roles.DeletePermission) // 删除权限
+
+		// 审计日志（当前租户，按时间倒序，游标分页）
+		admin.GET("/audit", handlers.Audit.List) // 查询审计日志
+
+		// 模拟登录（只能模拟级别更低的用户，签发的 Token 中记录实际操作的管理员）
+		admin.POST("/users/:id/impersonate",
+			middleware.RequirePolicy(auth.ActionImpersonate, handlers.User.ResolveUser), handlers.User.Impersonate)
 	}
 }
 
@@ -87,7 +190,7 @@ func setupUserRoutes(rg *gin.RouterGroup, handlers *Handlers) {
 //    - admin: 管理员
 //    - super_admin: 超级管理员
 //
-// 2. 权限继承：
+// 2. 权限继承（内置默认值，超级管理员可通过 /api/v1/admin/roles 修改，无需重新部署）：
 //    - 超级管理员拥有所有权限
 //    - 管理员拥有大部分权限（除了系统配置）
 //    - 版主拥有内容审核权限
@@ -98,24 +201,34 @@ func setupUserRoutes(rg *gin.RouterGroup, handlers *Handlers) {
 //    方式 A: 角色检查（适用于粗粒度控制）
 //    ```go
 //    admin := router.Group("/admin")
-//    admin.Use(handlers.Auth.Handle())                    // 认证
+//    admin.Use(handlers.RBAC.Handle())                    // 认证
 //    admin.Use(middleware.RequireAdmin())                 // 需要管理员角色
 //    ```
 //
 //    方式 B: 权限检查（适用于细粒度控制）
 //    ```go
 //    users := router.Group("/users")
-//    users.Use(handlers.Auth.Handle())                    // 认证
+//    users.Use(handlers.RBAC.Handle())                    // 认证
 //    users.Use(middleware.RequirePermission(              // 需要特定权限
 //        auth.PermissionUserWrite,
 //        auth.PermissionUserDelete,
 //    ))
 //    ```
 //
-//    方式 C: 混合使用（最灵活）
+//    方式 C: 资源级控制（ABAC，按资源属性判断，如“只能管理级别更低的用户”）
+//    ```go
+//    users.PUT("/:id",
+//        middleware.RequirePermission(auth.PermissionUserWrite),               // 先检查权限
+//        middleware.RequirePolicy(auth.ActionUpdate, handlers.User.ResolveUser), // 再加载资源并检查规则
+//        handlers.User.UpdateUser)
+//    ```
+//    Service 层加载资源后可以调用 auth.Can(ctx, action, resource) 做同样的检查，
+//    规则见 auth.DefaultRules，新的资源类型通过 auth.DefaultPolicyEngine().AddRules 注册。
+//
+//    方式 D: 混合使用（最灵活）
 //    ```go
 //    sensitive := router.Group("/sensitive")
-//    sensitive.Use(handlers.Auth.Handle())                // 认证
+//    sensitive.Use(handlers.RBAC.Handle())                // 认证
 //    sensitive.Use(middleware.RequireRole(                // 角色检查
 //        auth.RoleAdmin,
 //        auth.RoleSuperAdmin,
@@ -145,7 +258,7 @@ func setupUserRoutes(rg *gin.RouterGroup, handlers *Handlers) {
 //    ```
 //
 // 5. 注意事项：
-//    - 先使用 handlers.Auth.Handle() 进行认证
+//    - 先使用 handlers.RBAC.Handle() 进行认证（handlers.Auth 只校验身份，不提取角色）
 //    - 再使用 RequireRole/RequirePermission 进行授权
 //    - 顺序不能颠倒
 //    - 可以多层叠加（先角色再权限）
@@ -0,0 +1,38 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// AuditConfig 审计日志配置
+type AuditConfig struct {
+	// 保留时间（清理任务每天删除更早的记录，0 表示永久保留）
+	Retention time.Duration `mapstructure:"retention"`
+
+	// 异步写入缓冲区大小（缓冲区满时丢弃新事件，不阻塞请求）
+	BufferSize int `mapstructure:"buffer_size"`
+
+	// 每批最多写入的事件数
+	BatchSize int `mapstructure:"batch_size"`
+
+	// 缓冲区不满一批时的最长等待时间
+	FlushInterval time.Duration `mapstructure:"flush_interval"`
+}
+
+// validate 验证审计日志配置
+func (c AuditConfig) validate() error {
+	if c.Retention < 0 {
+		return fmt.Errorf("audit.retention must not be negative")
+	}
+	if c.BufferSize <= 0 || c.BatchSize <= 0 {
+		return fmt.Errorf("audit.buffer_size and audit.batch_size must be positive")
+	}
+	if c.BatchSize > c.BufferSize {
+		return fmt.Errorf("audit.batch_size must not exceed audit.buffer_size")
+	}
+	if c.FlushInterval <= 0 {
+		return fmt.Errorf("audit.flush_interval must be positive")
+	}
+	return nil
+}
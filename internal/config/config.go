@@ -9,6 +9,7 @@ import (
 
 	"gin_demo/pkg/cache"
 
+	"github.com/go-viper/mapstructure/v2"
 	"github.com/spf13/viper"
 )
 
@@ -37,6 +38,27 @@ type Config struct {
 
 	// 缓存配置
 	Cache CacheConfig
+
+	// 邮件配置
+	Mail MailConfig
+
+	// 第三方登录配置
+	OAuth OAuthConfig
+
+	// 多租户配置
+	Tenant TenantConfig
+
+	// 审计日志配置
+	Audit AuditConfig
+
+	// 用户账户配置
+	User UserConfig
+
+	// 后台作业配置
+	Jobs JobConfig
+
+	// 文件存储配置
+	Storage StorageConfig
 }
 
 // ServerConfig 服务器配置
@@ -93,8 +115,35 @@ type LoggerConfig struct {
 
 // JWTConfig JWT 配置
 type JWTConfig struct {
-	Secret     string        // JWT 密钥
-	Expiration time.Duration // Token 过期时间
+	Secret            string        // JWT 密钥（仅 HS256 使用）
+	Expiration        time.Duration // Access Token 过期时间（建议较短）
+	RefreshExpiration time.Duration // Refresh Token 过期时间
+
+	// 当前签名密钥
+	Algorithm      string    // 签名算法: HS256（默认）/ RS256 / ES256 / EdDSA
+	KeyID          string    // 当前签名密钥 ID（写入 Token 的 kid 头）
+	PrivateKeyFile string    // 签名私钥 PEM 文件（非对称算法必填）
+	NotAfter       time.Time // 当前签名密钥计划停用时间（可选，仅用于到期提醒）
+
+	// 旧密钥（轮换后继续验证已签发的 Token，到期后退役）
+	VerificationKeys []JWTVerificationKey
+
+	// 密钥到期提醒提前量
+	KeyExpiryWarning time.Duration
+}
+
+// JWTVerificationKey JWT 验证密钥配置（轮换前的旧密钥）
+type JWTVerificationKey struct {
+	KeyID         string    `mapstructure:"key_id"`          // 密钥 ID
+	Algorithm     string    `mapstructure:"algorithm"`       // 签名算法
+	Secret        string    `mapstructure:"secret"`          // HS256 旧密钥
+	PublicKeyFile string    `mapstructure:"public_key_file"` // 公钥 PEM 文件（支持 PKIX 公钥或 X.509 证书）
+	NotAfter      time.Time `mapstructure:"not_after"`       // 有效期截止时间（之后拒绝验证并退役）
+}
+
+// IsSymmetric 是否使用对称签名算法（HS256）
+func (c JWTConfig) IsSymmetric() bool {
+	return c.Algorithm == "" || c.Algorithm == "HS256"
 }
 
 // CORSConfig CORS 跨域配置
@@ -211,8 +260,14 @@ func Load() (*Config, error) {
 			RequestIDKey: viper.GetString("logger.request_id_key"),
 		},
 		JWT: JWTConfig{
-			Secret:     viper.GetString("jwt.secret"),
-			Expiration: viper.GetDuration("jwt.expiration"),
+			Secret:            viper.GetString("jwt.secret"),
+			Expiration:        viper.GetDuration("jwt.expiration"),
+			RefreshExpiration: viper.GetDuration("jwt.refresh_expiration"),
+			Algorithm:         viper.GetString("jwt.algorithm"),
+			KeyID:             viper.GetString("jwt.key_id"),
+			PrivateKeyFile:    viper.GetString("jwt.private_key_file"),
+			NotAfter:          viper.GetTime("jwt.not_after"),
+			KeyExpiryWarning:  viper.GetDuration("jwt.key_expiry_warning"),
 		},
 		CORS: CORSConfig{
 			AllowedOrigins:   viper.GetStringSlice("cors.allowed_origins"),
@@ -238,6 +293,66 @@ func Load() (*Config, error) {
 				KeyFile:    viper.GetString("security.tls.key_file"),
 				MinVersion: viper.GetString("security.tls.min_version"),
 			},
+			LoginProtection: LoginProtectionConfig{
+				Enabled: viper.GetBool("security.login_protection.enabled"),
+				Account: LockoutConfig{
+					MaxAttempts: viper.GetInt("security.login_protection.account.max_attempts"),
+					BaseDelay:   viper.GetDuration("security.login_protection.account.base_delay"),
+					MaxDelay:    viper.GetDuration("security.login_protection.account.max_delay"),
+					Window:      viper.GetDuration("security.login_protection.account.window"),
+				},
+				IP: LockoutConfig{
+					MaxAttempts: viper.GetInt("security.login_protection.ip.max_attempts"),
+					BaseDelay:   viper.GetDuration("security.login_protection.ip.base_delay"),
+					MaxDelay:    viper.GetDuration("security.login_protection.ip.max_delay"),
+					Window:      viper.GetDuration("security.login_protection.ip.window"),
+				},
+			},
+			MFA: MFAConfig{
+				Issuer:          viper.GetString("security.mfa.issuer"),
+				PendingTokenTTL: viper.GetDuration("security.mfa.pending_token_ttl"),
+				RecoveryCodes:   viper.GetInt("security.mfa.recovery_codes"),
+			},
+			AccountTokens: AccountTokensConfig{
+				Secret:           viper.GetString("security.account_tokens.secret"),
+				VerifyEmailTTL:   viper.GetDuration("security.account_tokens.verify_email_ttl"),
+				PasswordResetTTL: viper.GetDuration("security.account_tokens.password_reset_ttl"),
+				InviteTTL:        viper.GetDuration("security.account_tokens.invite_ttl"),
+			},
+			Cursor: CursorConfig{
+				Secret: viper.GetString("security.cursor.secret"),
+			},
+			APIKeys: APIKeysConfig{
+				MaxPerUser:       viper.GetInt("security.api_keys.max_per_user"),
+				MaxTTL:           viper.GetDuration("security.api_keys.max_ttl"),
+				LastUsedInterval: viper.GetDuration("security.api_keys.last_used_interval"),
+			},
+			RBAC: RBACConfig{
+				PolicyReloadInterval: viper.GetDuration("security.rbac.policy_reload_interval"),
+			},
+			Impersonation: ImpersonationConfig{
+				TTL: viper.GetDuration("security.impersonation.ttl"),
+			},
+			Password: PasswordConfig{
+				Algorithm:         viper.GetString("security.password.algorithm"),
+				BcryptCost:        viper.GetInt("security.password.bcrypt_cost"),
+				Argon2Memory:      viper.GetUint32("security.password.argon2_memory"),
+				Argon2Iterations:  viper.GetUint32("security.password.argon2_iterations"),
+				Argon2Parallelism: viper.GetUint8("security.password.argon2_parallelism"),
+			},
+			PasswordPolicy: PasswordPolicyConfig{
+				MinLength:        viper.GetInt("security.password_policy.min_length"),
+				MaxLength:        viper.GetInt("security.password_policy.max_length"),
+				RequireUpper:     viper.GetBool("security.password_policy.require_upper"),
+				RequireLower:     viper.GetBool("security.password_policy.require_lower"),
+				RequireDigit:     viper.GetBool("security.password_policy.require_digit"),
+				RequireSymbol:    viper.GetBool("security.password_policy.require_symbol"),
+				BannedWords:      viper.GetStringSlice("security.password_policy.banned_words"),
+				HistorySize:      viper.GetInt("security.password_policy.history_size"),
+				MaxSimilarity:    viper.GetFloat64("security.password_policy.max_similarity"),
+				BreachedFile:     viper.GetString("security.password_policy.breached_file"),
+				BreachedMinCount: viper.GetInt("security.password_policy.breached_min_count"),
+			},
 		},
 		Cache: CacheConfig{
 			DefaultTTL:     viper.GetDuration("cache.default_ttl"),
@@ -252,7 +367,71 @@ func Load() (*Config, error) {
 			EnableJitter:   viper.GetBool("cache.enable_jitter"),
 			JitterPercent:  viper.GetInt("cache.jitter_percent"),
 		},
+		Mail: MailConfig{
+			Driver:  viper.GetString("mail.driver"),
+			From:    viper.GetString("mail.from"),
+			BaseURL: viper.GetString("mail.base_url"),
+			FileDir: viper.GetString("mail.file_dir"),
+			SMTP: SMTPConfig{
+				Host:     viper.GetString("mail.smtp.host"),
+				Port:     viper.GetInt("mail.smtp.port"),
+				Username: viper.GetString("mail.smtp.username"),
+				Password: viper.GetString("mail.smtp.password"),
+				TLSMode:  viper.GetString("mail.smtp.tls_mode"),
+				Timeout:  viper.GetDuration("mail.smtp.timeout"),
+			},
+		},
+		OAuth: OAuthConfig{
+			StateTTL: viper.GetDuration("oauth.state_ttl"),
+		},
+		Tenant: TenantConfig{
+			BaseDomain: viper.GetString("tenant.base_domain"),
+			Header:     viper.GetString("tenant.header"),
+			CacheTTL:   viper.GetDuration("tenant.cache_ttl"),
+		},
+		Audit: AuditConfig{
+			Retention:     viper.GetDuration("audit.retention"),
+			BufferSize:    viper.GetInt("audit.buffer_size"),
+			BatchSize:     viper.GetInt("audit.batch_size"),
+			FlushInterval: viper.GetDuration("audit.flush_interval"),
+		},
+		User: UserConfig{
+			DeletedRetention: viper.GetDuration("user.deleted_retention"),
+			AvatarMaxSize:    viper.GetInt64("user.avatar_max_size"),
+		},
+		Jobs: JobConfig{
+			Workers:   viper.GetInt("jobs.workers"),
+			Timeout:   viper.GetDuration("jobs.timeout"),
+			Retention: viper.GetDuration("jobs.retention"),
+			Dir:       viper.GetString("jobs.dir"),
+		},
+		Storage: StorageConfig{
+			Driver:    viper.GetString("storage.driver"),
+			PublicURL: viper.GetString("storage.public_url"),
+			Local: LocalStorageConfig{
+				Dir: viper.GetString("storage.local.dir"),
+			},
+			S3: S3StorageConfig{
+				Endpoint:        viper.GetString("storage.s3.endpoint"),
+				Region:          viper.GetString("storage.s3.region"),
+				Bucket:          viper.GetString("storage.s3.bucket"),
+				AccessKeyID:     viper.GetString("storage.s3.access_key_id"),
+				SecretAccessKey: viper.GetString("storage.s3.secret_access_key"),
+				PathStyle:       viper.GetBool("storage.s3.path_style"),
+			},
+		},
+	}
+
+	// 6.1 解析列表类型配置
+	if err := viper.UnmarshalKey("jwt.verification_keys", &cfg.JWT.VerificationKeys, viper.DecodeHook(
+		mapstructure.StringToTimeHookFunc(time.RFC3339),
+	)); err != nil {
+		return nil, fmt.Errorf("config: failed to parse jwt.verification_keys: %w", err)
 	}
+	if err := viper.UnmarshalKey("oauth.providers", &cfg.OAuth.Providers); err != nil {
+		return nil, fmt.Errorf("config: failed to parse oauth.providers: %w", err)
+	}
+	cfg.OAuth.applyEnv()
 
 	// 7. 验证配置
 	if err := cfg.Validate(env); err != nil {
@@ -313,7 +492,12 @@ func setDefaults() {
 
 	// JWT 默认值
 	viper.SetDefault("jwt.secret", "your-secret-key-change-in-production")
-	viper.SetDefault("jwt.expiration", 24*time.Hour)
+	viper.SetDefault("jwt.expiration", 15*time.Minute)
+	viper.SetDefault("jwt.refresh_expiration", 7*24*time.Hour)
+	viper.SetDefault("jwt.algorithm", "HS256")
+	viper.SetDefault("jwt.key_id", "default")
+	viper.SetDefault("jwt.private_key_file", "")
+	viper.SetDefault("jwt.key_expiry_warning", 7*24*time.Hour)
 
 	// CORS 默认值
 	viper.SetDefault("cors.allowed_origins", []string{"http://localhost:3000", "http://localhost:8080"})
@@ -335,6 +519,83 @@ func setDefaults() {
 	viper.SetDefault("security.tls.cert_file", "")
 	viper.SetDefault("security.tls.key_file", "")
 	viper.SetDefault("security.tls.min_version", "1.2")
+	viper.SetDefault("security.login_protection.enabled", true)
+	viper.SetDefault("security.login_protection.account.max_attempts", 5)
+	viper.SetDefault("security.login_protection.account.base_delay", 1*time.Minute)
+	viper.SetDefault("security.login_protection.account.max_delay", 1*time.Hour)
+	viper.SetDefault("security.login_protection.account.window", 24*time.Hour)
+	viper.SetDefault("security.login_protection.ip.max_attempts", 20)
+	viper.SetDefault("security.login_protection.ip.base_delay", 1*time.Minute)
+	viper.SetDefault("security.login_protection.ip.max_delay", 1*time.Hour)
+	viper.SetDefault("security.login_protection.ip.window", 1*time.Hour)
+	viper.SetDefault("security.mfa.issuer", "gin_demo")
+	viper.SetDefault("security.mfa.pending_token_ttl", 5*time.Minute)
+	viper.SetDefault("security.mfa.recovery_codes", 10)
+	viper.SetDefault("security.account_tokens.secret", "account-token-secret-change-in-production")
+	viper.SetDefault("security.account_tokens.verify_email_ttl", 24*time.Hour)
+	viper.SetDefault("security.account_tokens.password_reset_ttl", 30*time.Minute)
+	viper.SetDefault("security.account_tokens.invite_ttl", 7*24*time.Hour)
+	viper.SetDefault("security.cursor.secret", "cursor-secret-change-in-production-0000")
+	viper.SetDefault("security.api_keys.max_per_user", 20)
+	viper.SetDefault("security.api_keys.max_ttl", 365*24*time.Hour)
+	viper.SetDefault("security.api_keys.last_used_interval", 1*time.Minute)
+	viper.SetDefault("security.rbac.policy_reload_interval", 30*time.Second)
+	viper.SetDefault("security.impersonation.ttl", 15*time.Minute)
+	viper.SetDefault("security.password.algorithm", "argon2id")
+	viper.SetDefault("security.password.bcrypt_cost", 10)
+	viper.SetDefault("security.password.argon2_memory", 19456)
+	viper.SetDefault("security.password.argon2_iterations", 2)
+	viper.SetDefault("security.password.argon2_parallelism", 1)
+	viper.SetDefault("security.password_policy.min_length", 8)
+	viper.SetDefault("security.password_policy.max_length", 128)
+	viper.SetDefault("security.password_policy.require_upper", false)
+	viper.SetDefault("security.password_policy.require_lower", false)
+	viper.SetDefault("security.password_policy.require_digit", false)
+	viper.SetDefault("security.password_policy.require_symbol", false)
+	viper.SetDefault("security.password_policy.banned_words", []string{"password", "qwerty", "123456", "111111", "abc123", "iloveyou"})
+	viper.SetDefault("security.password_policy.history_size", 5)
+	viper.SetDefault("security.password_policy.max_similarity", 0.7)
+	viper.SetDefault("security.password_policy.breached_file", "")
+	viper.SetDefault("security.password_policy.breached_min_count", 1)
+
+	// 邮件默认配置（开发环境输出到日志）
+	viper.SetDefault("mail.driver", "log")
+	viper.SetDefault("mail.from", "gin_demo <noreply@example.com>")
+	viper.SetDefault("mail.base_url", "http://localhost:3000")
+	viper.SetDefault("mail.file_dir", "./storage/mail")
+	viper.SetDefault("mail.smtp.port", 587)
+	viper.SetDefault("mail.smtp.tls_mode", "starttls")
+	viper.SetDefault("mail.smtp.timeout", 10*time.Second)
+
+	// 第三方登录默认配置（默认不配置任何提供方）
+	viper.SetDefault("oauth.state_ttl", 10*time.Minute)
+
+	// 多租户默认配置（不按子域名解析，未指定租户的请求属于默认租户）
+	viper.SetDefault("tenant.base_domain", "")
+	viper.SetDefault("tenant.header", "X-Tenant-ID")
+	viper.SetDefault("tenant.cache_ttl", 1*time.Minute)
+
+	// 审计日志默认配置（保留 90 天）
+	viper.SetDefault("audit.retention", 90*24*time.Hour)
+	viper.SetDefault("audit.buffer_size", 1024)
+	viper.SetDefault("audit.batch_size", 100)
+	viper.SetDefault("audit.flush_interval", 1*time.Second)
+
+	// 用户账户默认配置
+	viper.SetDefault("user.deleted_retention", 30*24*time.Hour)
+	viper.SetDefault("user.avatar_max_size", int64(5*1024*1024)) // 5MB
+
+	// 后台作业默认配置
+	viper.SetDefault("jobs.workers", 2)
+	viper.SetDefault("jobs.timeout", 30*time.Minute)
+	viper.SetDefault("jobs.retention", 24*time.Hour)
+	viper.SetDefault("jobs.dir", "./storage/jobs")
+
+	// 文件存储默认配置（本地目录，由应用在 /uploads 下提供访问）
+	viper.SetDefault("storage.driver", "local")
+	viper.SetDefault("storage.public_url", "/uploads")
+	viper.SetDefault("storage.local.dir", "./storage/uploads")
+	viper.SetDefault("storage.s3.region", "us-east-1")
 
 	// 缓存默认值
 	viper.SetDefault("cache.default_ttl", 5*time.Minute)
@@ -372,16 +633,31 @@ func (c *Config) Validate(env string) error {
 	}
 
 	// 验证 JWT 配置
-	if c.JWT.Secret == "" {
-		return fmt.Errorf("jwt.secret is required")
+	if err := c.JWT.validate(); err != nil {
+		return err
 	}
 	
 	// 生产环境额外校验
 	if env == "prod" || env == "production" || c.Server.Mode == "release" {
 		// 强制要求自定义 JWT 密钥
-		if c.JWT.Secret == "your-secret-key-change-in-production" {
+		if c.JWT.IsSymmetric() && c.JWT.Secret == "your-secret-key-change-in-production" {
 			return fmt.Errorf("jwt.secret must be customized in production (current: %s)", c.JWT.Secret)
 		}
+
+		// 强制要求自定义邮件令牌密钥
+		if c.Security.AccountTokens.Secret == "account-token-secret-change-in-production" {
+			return fmt.Errorf("security.account_tokens.secret must be customized in production")
+		}
+
+		// 强制要求自定义分页游标密钥
+		if c.Security.Cursor.Secret == "cursor-secret-change-in-production-0000" {
+			return fmt.Errorf("security.cursor.secret must be customized in production")
+		}
+
+		// 生产环境邮件不能只输出到日志（链接中包含一次性令牌）
+		if c.Mail.Driver == "log" {
+			slog.Warn("⚠️  mail.driver is 'log' in production - verification and password reset emails will not be delivered")
+		}
 		
 		// 警告：生产环境未启用 TLS
 		if !c.Security.TLS.Enabled {
@@ -401,7 +677,7 @@ func (c *Config) Validate(env string) error {
 	
 	// 开发/测试环境放宽 JWT 校验
 	if env == "dev" || env == "development" || env == "test" {
-		if c.JWT.Secret == "your-secret-key-change-in-production" {
+		if c.JWT.IsSymmetric() && c.JWT.Secret == "your-secret-key-change-in-production" {
 			slog.Warn("Using default JWT secret in development mode")
 		}
 	}
@@ -410,10 +686,138 @@ func (c *Config) Validate(env string) error {
 		return fmt.Errorf("jwt.expiration must be positive")
 	}
 
+	if c.JWT.RefreshExpiration <= c.JWT.Expiration {
+		return fmt.Errorf("jwt.refresh_expiration must be longer than jwt.expiration")
+	}
+
 	if c.Server.MaxRequestBodySize <= 0 {
 		return fmt.Errorf("server.max_request_body_size must be positive")
 	}
 
+	if c.Security.LoginProtection.Enabled {
+		if err := c.Security.LoginProtection.Account.validate("account"); err != nil {
+			return err
+		}
+		if err := c.Security.LoginProtection.IP.validate("ip"); err != nil {
+			return err
+		}
+	}
+
+	if err := c.Security.MFA.validate(); err != nil {
+		return err
+	}
+
+	if err := c.Security.AccountTokens.validate(); err != nil {
+		return err
+	}
+
+	if err := c.Security.Cursor.validate(); err != nil {
+		return err
+	}
+
+	if err := c.Security.APIKeys.validate(); err != nil {
+		return err
+	}
+
+	if err := c.Security.RBAC.validate(); err != nil {
+		return err
+	}
+
+	if err := c.Security.Password.validate(); err != nil {
+		return err
+	}
+
+	if err := c.Security.PasswordPolicy.validate(); err != nil {
+		return err
+	}
+
+	if err := c.Security.Impersonation.validate(); err != nil {
+		return err
+	}
+
+	if err := c.Mail.validate(); err != nil {
+		return err
+	}
+
+	if err := c.OAuth.validate(); err != nil {
+		return err
+	}
+
+	if err := c.Tenant.validate(); err != nil {
+		return err
+	}
+
+	if err := c.Audit.validate(); err != nil {
+		return err
+	}
+
+	if err := c.User.validate(); err != nil {
+		return err
+	}
+
+	if err := c.Jobs.validate(); err != nil {
+		return err
+	}
+
+	if err := c.Storage.validate(); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// validate 验证 JWT 签名密钥配置
+func (c JWTConfig) validate() error {
+	if c.KeyID == "" {
+		return fmt.Errorf("jwt.key_id is required")
+	}
+
+	switch c.Algorithm {
+	case "", "HS256":
+		if c.Secret == "" {
+			return fmt.Errorf("jwt.secret is required")
+		}
+	case "RS256", "ES256", "EdDSA":
+		if c.PrivateKeyFile == "" {
+			return fmt.Errorf("jwt.private_key_file is required for algorithm %s", c.Algorithm)
+		}
+	default:
+		return fmt.Errorf("invalid jwt.algorithm: %s (supported: HS256, RS256, ES256, EdDSA)", c.Algorithm)
+	}
+
+	seen := map[string]bool{c.KeyID: true}
+	for i, key := range c.VerificationKeys {
+		if key.KeyID == "" {
+			return fmt.Errorf("jwt.verification_keys[%d]: key_id is required", i)
+		}
+		if seen[key.KeyID] {
+			return fmt.Errorf("jwt.verification_keys[%d]: duplicate key_id %q", i, key.KeyID)
+		}
+		seen[key.KeyID] = true
+
+		// 旧密钥必须设置退役时间，否则轮换出去的密钥会被永久信任
+		if key.NotAfter.IsZero() {
+			return fmt.Errorf("jwt.verification_keys[%d]: not_after is required", i)
+		}
+
+		switch key.Algorithm {
+		case "", "HS256":
+			if key.Secret == "" {
+				return fmt.Errorf("jwt.verification_keys[%d]: secret is required for HS256", i)
+			}
+		case "RS256", "ES256", "EdDSA":
+			if key.PublicKeyFile == "" {
+				return fmt.Errorf("jwt.verification_keys[%d]: public_key_file is required for %s", i, key.Algorithm)
+			}
+		default:
+			return fmt.Errorf("jwt.verification_keys[%d]: invalid algorithm %q", i, key.Algorithm)
+		}
+	}
+
+	if c.KeyExpiryWarning < 0 {
+		return fmt.Errorf("jwt.key_expiry_warning must not be negative")
+	}
+
 	return nil
 }
 
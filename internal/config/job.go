@@ -0,0 +1,35 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// JobConfig 后台作业配置（数据导出、数据删除等按需提交的作业）
+type JobConfig struct {
+	// 每个实例并发执行的作业数
+	Workers int `mapstructure:"workers"`
+
+	// 单个作业的超时时间
+	Timeout time.Duration `mapstructure:"timeout"`
+
+	// 作业记录和作业生成的文件的保留时间（清理任务每小时删除过期的文件）
+	Retention time.Duration `mapstructure:"retention"`
+
+	// 作业生成的文件（如数据导出包）的保存目录，多实例部署时需要使用共享存储
+	Dir string `mapstructure:"dir"`
+}
+
+// validate 验证后台作业配置
+func (c JobConfig) validate() error {
+	if c.Workers <= 0 {
+		return fmt.Errorf("jobs.workers must be positive")
+	}
+	if c.Timeout <= 0 || c.Retention <= 0 {
+		return fmt.Errorf("jobs.timeout and jobs.retention must be positive")
+	}
+	if c.Dir == "" {
+		return fmt.Errorf("jobs.dir is required")
+	}
+	return nil
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// MailConfig 邮件配置
+type MailConfig struct {
+	// 发送方式: smtp / file / log
+	Driver string `mapstructure:"driver"`
+
+	// 发件人，如 "gin_demo <noreply@example.com>"
+	From string `mapstructure:"from"`
+
+	// 邮件中链接的前缀（前端页面地址，如 https://app.example.com）
+	BaseURL string `mapstructure:"base_url"`
+
+	// file 方式的输出目录
+	FileDir string `mapstructure:"file_dir"`
+
+	// SMTP 配置
+	SMTP SMTPConfig `mapstructure:"smtp"`
+}
+
+// SMTPConfig SMTP 配置
+type SMTPConfig struct {
+	Host     string `mapstructure:"host"`
+	Port     int    `mapstructure:"port"`
+	Username string `mapstructure:"username"`
+	Password string `mapstructure:"password"`
+
+	// 加密方式: starttls（默认）/ tls（465 端口）/ none（仅用于本地调试）
+	TLSMode string `mapstructure:"tls_mode"`
+
+	// 单封邮件发送超时
+	Timeout time.Duration `mapstructure:"timeout"`
+}
+
+// validate 验证邮件配置
+func (c MailConfig) validate() error {
+	if c.From == "" {
+		return fmt.Errorf("mail.from is required")
+	}
+	if c.BaseURL == "" {
+		return fmt.Errorf("mail.base_url is required")
+	}
+
+	switch c.Driver {
+	case "smtp":
+		if c.SMTP.Host == "" || c.SMTP.Port <= 0 {
+			return fmt.Errorf("mail.smtp.host and mail.smtp.port are required when mail.driver is smtp")
+		}
+		switch c.SMTP.TLSMode {
+		case "", "starttls", "tls", "none":
+		default:
+			return fmt.Errorf("invalid mail.smtp.tls_mode: %s (must be starttls, tls or none)", c.SMTP.TLSMode)
+		}
+	case "file":
+		if c.FileDir == "" {
+			return fmt.Errorf("mail.file_dir is required when mail.driver is file")
+		}
+	case "log":
+	default:
+		return fmt.Errorf("invalid mail.driver: %s (must be smtp, file or log)", c.Driver)
+	}
+	return nil
+}
@@ -0,0 +1,93 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// oauthProviderNamePattern 提供方名称（出现在 URL 路径和环境变量名中）
+var oauthProviderNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_-]{0,49}$`)
+
+// OAuthConfig 第三方登录配置
+type OAuthConfig struct {
+	// 授权请求（state、nonce、PKCE code_verifier）的有效期，即用户在提供方页面停留的最长时间
+	StateTTL time.Duration `mapstructure:"state_ttl"`
+
+	// 提供方列表（为空表示不启用第三方登录）
+	Providers []OAuthProviderConfig `mapstructure:"providers"`
+}
+
+// OAuthProviderConfig 第三方登录提供方配置
+type OAuthProviderConfig struct {
+	// 名称，出现在接口路径中（如 /users/oauth/google/authorize）
+	Name string `mapstructure:"name"`
+
+	// 类型: oidc（Google、Keycloak 等标准 OIDC 提供方）/ github
+	Type string `mapstructure:"type"`
+
+	// OIDC Issuer（type 为 oidc 时必填，端点通过服务发现获取）
+	Issuer string `mapstructure:"issuer"`
+
+	ClientID string `mapstructure:"client_id"`
+
+	// 建议通过环境变量 OAUTH_<NAME>_CLIENT_SECRET 设置
+	ClientSecret string `mapstructure:"client_secret"`
+
+	// 回调地址（前端页面，须与提供方后台登记的一致）
+	RedirectURL string `mapstructure:"redirect_url"`
+
+	// 为空时使用默认值（oidc: openid email profile；github: read:user user:email）
+	Scopes []string `mapstructure:"scopes"`
+
+	// 可选，覆盖服务发现或默认的端点
+	AuthURL     string `mapstructure:"auth_url"`
+	TokenURL    string `mapstructure:"token_url"`
+	JWKSURL     string `mapstructure:"jwks_url"`
+	UserInfoURL string `mapstructure:"userinfo_url"`
+}
+
+// applyEnv 使用环境变量 OAUTH_<NAME>_CLIENT_SECRET 覆盖客户端密钥（列表项无法通过 AutomaticEnv 覆盖）
+func (c *OAuthConfig) applyEnv() {
+	for i := range c.Providers {
+		name := strings.ToUpper(strings.ReplaceAll(c.Providers[i].Name, "-", "_"))
+		if secret := os.Getenv("OAUTH_" + name + "_CLIENT_SECRET"); secret != "" {
+			c.Providers[i].ClientSecret = secret
+		}
+	}
+}
+
+// validate 验证第三方登录配置
+func (c OAuthConfig) validate() error {
+	if len(c.Providers) > 0 && c.StateTTL <= 0 {
+		return fmt.Errorf("oauth.state_ttl must be positive")
+	}
+
+	seen := make(map[string]bool, len(c.Providers))
+	for i, p := range c.Providers {
+		if !oauthProviderNamePattern.MatchString(p.Name) {
+			return fmt.Errorf("oauth.providers[%d]: invalid name %q (lowercase letters, digits, - and _)", i, p.Name)
+		}
+		if seen[p.Name] {
+			return fmt.Errorf("oauth.providers[%d]: duplicate name %q", i, p.Name)
+		}
+		seen[p.Name] = true
+
+		if p.ClientID == "" || p.ClientSecret == "" || p.RedirectURL == "" {
+			return fmt.Errorf("oauth.providers[%d]: client_id, client_secret and redirect_url are required", i)
+		}
+
+		switch p.Type {
+		case "oidc":
+			if p.Issuer == "" {
+				return fmt.Errorf("oauth.providers[%d]: issuer is required for type oidc", i)
+			}
+		case "github":
+		default:
+			return fmt.Errorf("oauth.providers[%d]: invalid type %q (must be oidc or github)", i, p.Type)
+		}
+	}
+	return nil
+}
@@ -1,5 +1,10 @@
 package config
 
+import (
+	"fmt"
+	"time"
+)
+
 // SecurityConfig 安全配置
 type SecurityConfig struct {
 	// HTTP 安全头
@@ -13,6 +18,167 @@ type SecurityConfig struct {
 	
 	// TLS/HTTPS 配置
 	TLS TLSConfig `mapstructure:"tls"`
+
+	// 登录暴力破解防护
+	LoginProtection LoginProtectionConfig `mapstructure:"login_protection"`
+
+	// 两步验证（TOTP）
+	MFA MFAConfig `mapstructure:"mfa"`
+
+	// 邮箱验证与密码重置令牌
+	AccountTokens AccountTokensConfig `mapstructure:"account_tokens"`
+
+	// 分页游标签名
+	Cursor CursorConfig `mapstructure:"cursor"`
+
+	// API Key（服务间调用）
+	APIKeys APIKeysConfig `mapstructure:"api_keys"`
+
+	// 角色权限策略
+	RBAC RBACConfig `mapstructure:"rbac"`
+
+	// 模拟登录（超级管理员以其他用户身份操作）
+	Impersonation ImpersonationConfig `mapstructure:"impersonation"`
+
+	// 密码哈希
+	Password PasswordConfig `mapstructure:"password"`
+
+	// 密码策略（注册、修改密码、重置密码时校验）
+	PasswordPolicy PasswordPolicyConfig `mapstructure:"password_policy"`
+}
+
+// PasswordPolicyConfig 密码策略配置
+type PasswordPolicyConfig struct {
+	// 最短长度（按字符计）
+	MinLength int `mapstructure:"min_length"`
+
+	// 最长长度
+	MaxLength int `mapstructure:"max_length"`
+
+	// 必须包含的字符类型
+	RequireUpper  bool `mapstructure:"require_upper"`
+	RequireLower  bool `mapstructure:"require_lower"`
+	RequireDigit  bool `mapstructure:"require_digit"`
+	RequireSymbol bool `mapstructure:"require_symbol"`
+
+	// 禁止包含的词（不区分大小写）
+	BannedWords []string `mapstructure:"banned_words"`
+
+	// 禁止重复使用最近 N 个密码（0 表示不限制）
+	HistorySize int `mapstructure:"history_size"`
+
+	// 与用户名、邮箱的最大相似度（0-1，0 表示不检查）
+	MaxSimilarity float64 `mapstructure:"max_similarity"`
+
+	// 本地泄露密码文件（Pwned Passwords SHA-1 按哈希排序格式，为空表示不检查）
+	BreachedFile string `mapstructure:"breached_file"`
+
+	// 在泄露密码文件中出现的次数达到该值时拒绝
+	BreachedMinCount int `mapstructure:"breached_min_count"`
+}
+
+// PasswordConfig 密码哈希配置
+//
+// 修改算法或参数后，已有密码仍能校验，用户下次登录时自动按新配置重新计算哈希。
+type PasswordConfig struct {
+	// 新密码使用的算法: "argon2id", "bcrypt"
+	Algorithm string `mapstructure:"algorithm"`
+
+	// bcrypt cost（4-31）
+	BcryptCost int `mapstructure:"bcrypt_cost"`
+
+	// Argon2id 内存（KiB）
+	Argon2Memory uint32 `mapstructure:"argon2_memory"`
+
+	// Argon2id 迭代次数
+	Argon2Iterations uint32 `mapstructure:"argon2_iterations"`
+
+	// Argon2id 并行度
+	Argon2Parallelism uint8 `mapstructure:"argon2_parallelism"`
+}
+
+// RBACConfig 角色权限策略配置
+type RBACConfig struct {
+	// 从数据库重新加载角色权限的间隔（多实例部署时，其他实例修改角色后最迟在该间隔内生效；0 表示不定期加载）
+	PolicyReloadInterval time.Duration `mapstructure:"policy_reload_interval"`
+}
+
+// ImpersonationConfig 模拟登录配置
+type ImpersonationConfig struct {
+	// 模拟登录 Token 的有效期（不签发 Refresh Token，过期后需要重新发起）
+	TTL time.Duration `mapstructure:"ttl"`
+}
+
+// APIKeysConfig API Key 配置
+type APIKeysConfig struct {
+	// 每个用户最多可用的 API Key 数量
+	MaxPerUser int `mapstructure:"max_per_user"`
+
+	// 最长有效期（0 表示允许永不过期的 Key；未指定有效期时使用该值）
+	MaxTTL time.Duration `mapstructure:"max_ttl"`
+
+	// 最近使用时间的记录间隔（避免每个请求都写库）
+	LastUsedInterval time.Duration `mapstructure:"last_used_interval"`
+}
+
+// AccountTokensConfig 邮箱验证与密码重置令牌配置
+type AccountTokensConfig struct {
+	// 令牌签名密钥（HMAC-SHA256）
+	Secret string `mapstructure:"secret"`
+
+	// 邮箱验证链接有效期
+	VerifyEmailTTL time.Duration `mapstructure:"verify_email_ttl"`
+
+	// 密码重置链接有效期
+	PasswordResetTTL time.Duration `mapstructure:"password_reset_ttl"`
+
+	// 邀请链接有效期（批量导入的无密码账户设置密码）
+	InviteTTL time.Duration `mapstructure:"invite_ttl"`
+}
+
+// CursorConfig 分页游标配置
+type CursorConfig struct {
+	// 游标签名密钥（HMAC-SHA256，防止客户端构造任意位置的游标）
+	Secret string `mapstructure:"secret"`
+}
+
+// MFAConfig 两步验证配置
+type MFAConfig struct {
+	// TOTP 签发方（显示在验证器 App 中）
+	Issuer string `mapstructure:"issuer"`
+
+	// 密码验证通过后，完成第二步验证的有效期
+	PendingTokenTTL time.Duration `mapstructure:"pending_token_ttl"`
+
+	// 每次生成的恢复码数量
+	RecoveryCodes int `mapstructure:"recovery_codes"`
+}
+
+// LoginProtectionConfig 登录暴力破解防护配置
+type LoginProtectionConfig struct {
+	// 是否启用
+	Enabled bool `mapstructure:"enabled"`
+
+	// 按账户（登录邮箱）锁定
+	Account LockoutConfig `mapstructure:"account"`
+
+	// 按客户端 IP 锁定（阈值应高于账户，避免误伤 NAT 后的用户）
+	IP LockoutConfig `mapstructure:"ip"`
+}
+
+// LockoutConfig 锁定策略配置（指数退避）
+type LockoutConfig struct {
+	// 开始锁定前允许的失败次数（0 表示不限制）
+	MaxAttempts int `mapstructure:"max_attempts"`
+
+	// 首次锁定时长，之后每次失败翻倍
+	BaseDelay time.Duration `mapstructure:"base_delay"`
+
+	// 最长锁定时长
+	MaxDelay time.Duration `mapstructure:"max_delay"`
+
+	// 失败计数保留时长（从最后一次失败算起）
+	Window time.Duration `mapstructure:"window"`
 }
 
 // SecurityHeadersConfig HTTP 安全头配置
@@ -48,3 +214,117 @@ type TLSConfig struct {
 	// 最小 TLS 版本: "1.2", "1.3"
 	MinVersion string `mapstructure:"min_version"`
 }
+
+// validate 验证两步验证配置
+func (c MFAConfig) validate() error {
+	if c.Issuer == "" {
+		return fmt.Errorf("security.mfa.issuer is required")
+	}
+	if c.PendingTokenTTL <= 0 {
+		return fmt.Errorf("security.mfa.pending_token_ttl must be positive")
+	}
+	if c.RecoveryCodes <= 0 {
+		return fmt.Errorf("security.mfa.recovery_codes must be positive")
+	}
+	return nil
+}
+
+// validate 验证邮箱验证与密码重置令牌配置
+func (c AccountTokensConfig) validate() error {
+	if len(c.Secret) < 32 {
+		return fmt.Errorf("security.account_tokens.secret must be at least 32 characters")
+	}
+	if c.VerifyEmailTTL <= 0 || c.PasswordResetTTL <= 0 || c.InviteTTL <= 0 {
+		return fmt.Errorf("security.account_tokens: verify_email_ttl, password_reset_ttl and invite_ttl must be positive")
+	}
+	return nil
+}
+
+// validate 验证分页游标配置
+func (c CursorConfig) validate() error {
+	if len(c.Secret) < 32 {
+		return fmt.Errorf("security.cursor.secret must be at least 32 characters")
+	}
+	return nil
+}
+
+// validate 验证 API Key 配置
+func (c APIKeysConfig) validate() error {
+	if c.MaxPerUser <= 0 {
+		return fmt.Errorf("security.api_keys.max_per_user must be positive")
+	}
+	if c.MaxTTL < 0 || c.LastUsedInterval < 0 {
+		return fmt.Errorf("security.api_keys: max_ttl and last_used_interval must not be negative")
+	}
+	return nil
+}
+
+// validate 验证角色权限策略配置
+func (c RBACConfig) validate() error {
+	if c.PolicyReloadInterval < 0 {
+		return fmt.Errorf("security.rbac.policy_reload_interval must not be negative")
+	}
+	return nil
+}
+
+// validate 验证模拟登录配置
+func (c ImpersonationConfig) validate() error {
+	if c.TTL <= 0 || c.TTL > time.Hour {
+		return fmt.Errorf("security.impersonation.ttl must be positive and at most 1h")
+	}
+	return nil
+}
+
+// validate 验证密码哈希配置
+func (c PasswordConfig) validate() error {
+	if c.Algorithm != "argon2id" && c.Algorithm != "bcrypt" {
+		return fmt.Errorf("security.password.algorithm must be argon2id or bcrypt")
+	}
+	if c.BcryptCost < 4 || c.BcryptCost > 31 {
+		return fmt.Errorf("security.password.bcrypt_cost must be between 4 and 31")
+	}
+	if c.Argon2Iterations == 0 || c.Argon2Parallelism == 0 {
+		return fmt.Errorf("security.password: argon2_iterations and argon2_parallelism must be positive")
+	}
+	if c.Argon2Memory < 8*uint32(c.Argon2Parallelism) {
+		return fmt.Errorf("security.password.argon2_memory must be at least 8 KiB per thread")
+	}
+	return nil
+}
+
+// validate 验证密码策略配置
+func (c PasswordPolicyConfig) validate() error {
+	if c.MinLength <= 0 {
+		return fmt.Errorf("security.password_policy.min_length must be positive")
+	}
+	if c.MaxLength < c.MinLength || c.MaxLength > 1024 {
+		return fmt.Errorf("security.password_policy.max_length must be between min_length and 1024")
+	}
+	if c.HistorySize < 0 || c.HistorySize > 24 {
+		return fmt.Errorf("security.password_policy.history_size must be between 0 and 24")
+	}
+	if c.MaxSimilarity < 0 || c.MaxSimilarity > 1 {
+		return fmt.Errorf("security.password_policy.max_similarity must be between 0 and 1")
+	}
+	if c.BreachedFile != "" && c.BreachedMinCount <= 0 {
+		return fmt.Errorf("security.password_policy.breached_min_count must be positive when breached_file is set")
+	}
+	return nil
+}
+
+// validate 验证锁定策略（max_attempts 为 0 时不启用该维度）
+func (c LockoutConfig) validate(scope string) error {
+	if c.MaxAttempts < 0 {
+		return fmt.Errorf("security.login_protection.%s.max_attempts must not be negative", scope)
+	}
+	if c.MaxAttempts == 0 {
+		return nil
+	}
+	if c.BaseDelay <= 0 || c.MaxDelay < c.BaseDelay {
+		return fmt.Errorf("security.login_protection.%s: base_delay must be positive and not exceed max_delay", scope)
+	}
+	if c.Window < c.MaxDelay {
+		return fmt.Errorf("security.login_protection.%s.window must not be shorter than max_delay", scope)
+	}
+	return nil
+}
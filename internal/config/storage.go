@@ -0,0 +1,66 @@
+package config
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// StorageConfig 文件存储配置（用户上传的头像等）
+type StorageConfig struct {
+	// 存储方式: local（本地目录，由应用提供访问）/ s3（S3 兼容服务，如 AWS S3、MinIO）
+	Driver string `mapstructure:"driver"`
+
+	// 文件访问地址前缀，如 /uploads、https://cdn.example.com/uploads
+	// local 方式下应用在该地址的路径部分提供文件；s3 方式下为空时使用存储桶地址
+	PublicURL string `mapstructure:"public_url"`
+
+	// 本地存储配置
+	Local LocalStorageConfig `mapstructure:"local"`
+
+	// S3 兼容存储配置
+	S3 S3StorageConfig `mapstructure:"s3"`
+}
+
+// LocalStorageConfig 本地存储配置
+type LocalStorageConfig struct {
+	// 文件保存目录（多实例部署时需要使用共享存储）
+	Dir string `mapstructure:"dir"`
+}
+
+// S3StorageConfig S3 兼容存储配置
+type S3StorageConfig struct {
+	Endpoint        string `mapstructure:"endpoint"` // 如 https://s3.us-east-1.amazonaws.com、http://minio:9000
+	Region          string `mapstructure:"region"`
+	Bucket          string `mapstructure:"bucket"` // 需要允许公开读取（或通过 public_url 指向的 CDN 访问）
+	AccessKeyID     string `mapstructure:"access_key_id"`
+	SecretAccessKey string `mapstructure:"secret_access_key"`
+	PathStyle       bool   `mapstructure:"path_style"` // 路径风格地址（MinIO 需要开启）
+}
+
+// validate 验证文件存储配置
+func (c StorageConfig) validate() error {
+	switch c.Driver {
+	case "local":
+		if c.Local.Dir == "" {
+			return fmt.Errorf("storage.local.dir is required when storage.driver is local")
+		}
+		u, err := url.Parse(c.PublicURL)
+		if err != nil || strings.Trim(u.Path, "/") == "" {
+			return fmt.Errorf("storage.public_url must have a non-root path when storage.driver is local (e.g. /uploads)")
+		}
+		if path := "/" + strings.Trim(u.Path, "/"); path == "/api" || strings.HasPrefix(path, "/api/") {
+			return fmt.Errorf("storage.public_url must not be under /api")
+		}
+	case "s3":
+		if c.S3.Endpoint == "" || c.S3.Region == "" || c.S3.Bucket == "" {
+			return fmt.Errorf("storage.s3.endpoint, storage.s3.region and storage.s3.bucket are required when storage.driver is s3")
+		}
+		if c.S3.AccessKeyID == "" || c.S3.SecretAccessKey == "" {
+			return fmt.Errorf("storage.s3.access_key_id and storage.s3.secret_access_key are required when storage.driver is s3")
+		}
+	default:
+		return fmt.Errorf("invalid storage.driver: %s (must be local or s3)", c.Driver)
+	}
+	return nil
+}
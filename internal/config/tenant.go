@@ -0,0 +1,34 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// TenantConfig 多租户配置
+type TenantConfig struct {
+	// 租户子域名的上级域名（如 example.com，acme.example.com 解析为租户 acme）
+	// 为空时不按子域名解析租户
+	BaseDomain string `mapstructure:"base_domain"`
+
+	// 指定租户标识的请求头（子域名和请求头同时存在时必须一致）
+	Header string `mapstructure:"header"`
+
+	// 租户信息的进程内缓存时间（停用租户最迟在该时间后生效）
+	CacheTTL time.Duration `mapstructure:"cache_ttl"`
+}
+
+// validate 验证多租户配置
+func (c TenantConfig) validate() error {
+	if c.Header == "" {
+		return fmt.Errorf("tenant.header is required")
+	}
+	if strings.HasPrefix(c.BaseDomain, ".") || strings.Contains(c.BaseDomain, ":") {
+		return fmt.Errorf("invalid tenant.base_domain: %s (use a bare domain such as example.com)", c.BaseDomain)
+	}
+	if c.CacheTTL < 0 {
+		return fmt.Errorf("tenant.cache_ttl must not be negative")
+	}
+	return nil
+}
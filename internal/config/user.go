@@ -0,0 +1,34 @@
+package config
+
+import (
+	"fmt"
+	"time"
+)
+
+// UserConfig 用户账户配置
+type UserConfig struct {
+	// 软删除用户的保留时间（清理任务每天删除更早删除的用户，0 表示永久保留，可随时恢复）
+	DeletedRetention time.Duration `mapstructure:"deleted_retention"`
+
+	// 头像文件的最大字节数（0 表示与 server.max_request_body_size 相同，超过请求体限制时按请求体限制）
+	AvatarMaxSize int64 `mapstructure:"avatar_max_size"`
+}
+
+// AvatarUploadLimit 头像文件的实际大小限制（整个请求体受 BodyLimit 中间件限制，头像不能超过请求体限制）
+func (c UserConfig) AvatarUploadLimit(maxRequestBodySize int64) int64 {
+	if c.AvatarMaxSize <= 0 {
+		return maxRequestBodySize
+	}
+	return min(c.AvatarMaxSize, maxRequestBodySize)
+}
+
+// validate 验证用户账户配置
+func (c UserConfig) validate() error {
+	if c.DeletedRetention < 0 {
+		return fmt.Errorf("user.deleted_retention must not be negative")
+	}
+	if c.AvatarMaxSize < 0 {
+		return fmt.Errorf("user.avatar_max_size must not be negative")
+	}
+	return nil
+}
@@ -0,0 +1,319 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/url"
+	"strings"
+	"time"
+
+	"gin_demo/internal/repository"
+	"gin_demo/internal/response"
+	"gin_demo/pkg/auth"
+	"gin_demo/pkg/mail"
+	"gin_demo/pkg/metrics"
+)
+
+// ErrInvalidAccountToken 邮件链接中的令牌无效、已使用或已过期
+var ErrInvalidAccountToken = response.New(response.CodeInvalidParams, "链接无效或已过期，请重新获取")
+
+// AccountConfig 邮箱验证与密码重置配置
+type AccountConfig struct {
+	BaseURL          string        // 邮件中链接的前缀（前端页面地址）
+	VerifyEmailTTL   time.Duration // 邮箱验证链接有效期
+	PasswordResetTTL time.Duration // 密码重置链接有效期
+	InviteTTL        time.Duration // 邀请链接有效期（批量导入的无密码账户）
+}
+
+// AccountService 邮箱验证与密码重置业务逻辑接口
+type AccountService interface {
+	// SendVerificationEmail 发送邮箱验证邮件（注册后调用，之前发送的链接作废）
+	SendVerificationEmail(ctx context.Context, user repository.User) error
+
+	// ResendVerificationEmail 重新发送邮箱验证邮件（邮箱不存在或已验证时静默忽略，避免探测账户）
+	ResendVerificationEmail(ctx context.Context, email string) error
+
+	// VerifyEmail 使用邮件中的令牌验证邮箱
+	VerifyEmail(ctx context.Context, token string) error
+
+	// RequestPasswordReset 发送密码重置邮件（邮箱不存在时静默忽略，避免探测账户）
+	RequestPasswordReset(ctx context.Context, email string) error
+
+	// SendInvitation 发送邀请邮件（管理员导入的无密码账户，通过邮件中的链接设置密码）
+	SendInvitation(ctx context.Context, user repository.User) error
+
+	// ResetPassword 使用邮件中的令牌重置密码（同时吊销所有已签发的 Token）
+	ResetPassword(ctx context.Context, token, newPassword string) error
+}
+
+// accountService 邮箱验证与密码重置业务逻辑实现
+type accountService struct {
+	userRepo  repository.UserRepositoryInterface
+	tokenRepo repository.UserTokenRepositoryInterface
+	mailer    mail.Mailer
+	signer    *auth.OneTimeTokenSigner
+	hasher    auth.PasswordHasher
+	passwords PasswordService
+	config    AccountConfig
+}
+
+// NewAccountService 创建邮箱验证与密码重置服务实例
+func NewAccountService(
+	userRepo repository.UserRepositoryInterface,
+	tokenRepo repository.UserTokenRepositoryInterface,
+	mailer mail.Mailer,
+	signer *auth.OneTimeTokenSigner,
+	hasher auth.PasswordHasher,
+	passwords PasswordService,
+	config AccountConfig,
+) AccountService {
+	config.BaseURL = strings.TrimRight(config.BaseURL, "/")
+	return &accountService{
+		userRepo:  userRepo,
+		tokenRepo: tokenRepo,
+		mailer:    mailer,
+		signer:    signer,
+		hasher:    hasher,
+		passwords: passwords,
+		config:    config,
+	}
+}
+
+// SendVerificationEmail 发送邮箱验证邮件
+func (s *accountService) SendVerificationEmail(ctx context.Context, user repository.User) error {
+	token, err := s.issueToken(ctx, user.ID, repository.TokenPurposeVerifyEmail, s.config.VerifyEmailTTL)
+	if err != nil {
+		return err
+	}
+
+	link := s.link("/verify-email", token)
+	body := fmt.Sprintf("%s，您好：\n\n请点击下面的链接验证您的邮箱（%s 内有效）：\n\n%s\n\n如果这不是您本人的操作，请忽略本邮件。\n",
+		user.Username, s.config.VerifyEmailTTL, link)
+
+	if err := s.mailer.Send(ctx, mail.Message{
+		To:      []string{user.Email},
+		Subject: "请验证您的邮箱",
+		Body:    body,
+	}); err != nil {
+		metrics.RecordUserOperation("send_verification_email", false)
+		return fmt.Errorf("service: send verification email: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Verification email sent", "user_id", user.ID)
+	metrics.RecordUserOperation("send_verification_email", true)
+	return nil
+}
+
+// ResendVerificationEmail 重新发送邮箱验证邮件
+func (s *accountService) ResendVerificationEmail(ctx context.Context, email string) error {
+	user, err := s.userRepo.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			slog.InfoContext(ctx, "Resend verification skipped: user not found", "email", email)
+			return nil
+		}
+		return fmt.Errorf("service: get user: %w", err)
+	}
+
+	if user.Status != repository.UserStatusPending {
+		slog.InfoContext(ctx, "Resend verification skipped: already verified", "user_id", user.ID)
+		return nil
+	}
+
+	return s.SendVerificationEmail(ctx, user)
+}
+
+// VerifyEmail 验证邮箱
+func (s *accountService) VerifyEmail(ctx context.Context, token string) error {
+	userID, err := s.consumeToken(ctx, repository.TokenPurposeVerifyEmail, token)
+	if err != nil {
+		return err
+	}
+
+	// 重复验证（用户已是正常状态）视为成功
+	verified, err := s.userRepo.MarkEmailVerified(ctx, userID)
+	if err != nil {
+		metrics.RecordUserOperation("verify_email", false)
+		return fmt.Errorf("service: mark email verified: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Email verified", "user_id", userID, "status_changed", verified)
+	metrics.RecordUserOperation("verify_email", true)
+	return nil
+}
+
+// RequestPasswordReset 发送密码重置邮件
+func (s *accountService) RequestPasswordReset(ctx context.Context, email string) error {
+	user, err := s.userRepo.GetUserByEmail(ctx, email)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			slog.InfoContext(ctx, "Password reset skipped: user not found", "email", email)
+			return nil
+		}
+		return fmt.Errorf("service: get user: %w", err)
+	}
+
+	token, err := s.issueToken(ctx, user.ID, repository.TokenPurposeResetPassword, s.config.PasswordResetTTL)
+	if err != nil {
+		return err
+	}
+
+	link := s.link("/reset-password", token)
+	body := fmt.Sprintf("%s，您好：\n\n我们收到了重置您账户密码的请求。请点击下面的链接设置新密码（%s 内有效，只能使用一次）：\n\n%s\n\n如果这不是您本人的操作，请忽略本邮件，您的密码不会被修改。\n",
+		user.Username, s.config.PasswordResetTTL, link)
+
+	if err := s.mailer.Send(ctx, mail.Message{
+		To:      []string{user.Email},
+		Subject: "重置您的密码",
+		Body:    body,
+	}); err != nil {
+		metrics.RecordUserOperation("send_password_reset_email", false)
+		return fmt.Errorf("service: send password reset email: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Password reset email sent", "user_id", user.ID)
+	metrics.RecordUserOperation("send_password_reset_email", true)
+	return nil
+}
+
+// SendInvitation 发送邀请邮件
+//
+// 邀请链接就是有效期更长的密码重置链接：用户设置密码后邮箱同时被验证，账户变为正常状态。
+func (s *accountService) SendInvitation(ctx context.Context, user repository.User) error {
+	token, err := s.issueToken(ctx, user.ID, repository.TokenPurposeResetPassword, s.config.InviteTTL)
+	if err != nil {
+		return err
+	}
+
+	link := s.link("/reset-password", token)
+	body := fmt.Sprintf("%s，您好：\n\n管理员为您创建了账户（%s）。请点击下面的链接设置密码（%s 内有效，只能使用一次）：\n\n%s\n\n如果您不认识发送方，请忽略本邮件。\n",
+		user.Username, user.Email, s.config.InviteTTL, link)
+
+	if err := s.mailer.Send(ctx, mail.Message{
+		To:      []string{user.Email},
+		Subject: "邀请您设置账户密码",
+		Body:    body,
+	}); err != nil {
+		metrics.RecordUserOperation("send_invitation_email", false)
+		return fmt.Errorf("service: send invitation email: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Invitation email sent", "user_id", user.ID)
+	metrics.RecordUserOperation("send_invitation_email", true)
+	return nil
+}
+
+// ResetPassword 重置密码
+func (s *accountService) ResetPassword(ctx context.Context, token, newPassword string) error {
+	if newPassword == "" {
+		return ErrInvalidInput
+	}
+
+	// 先校验新密码再使用令牌，新密码不符合策略时链接仍然有效
+	userID, err := s.peekToken(ctx, repository.TokenPurposeResetPassword, token)
+	if err != nil {
+		return err
+	}
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrInvalidAccountToken
+		}
+		return fmt.Errorf("service: get user: %w", err)
+	}
+	if err := s.passwords.Validate(ctx, PasswordCheck{
+		UserID:   userID,
+		Field:    "new_password",
+		Password: newPassword,
+		Username: user.Username,
+		Email:    user.Email,
+	}); err != nil {
+		return err
+	}
+
+	if _, err := s.consumeToken(ctx, repository.TokenPurposeResetPassword, token); err != nil {
+		return err
+	}
+
+	hashedPassword, err := s.hasher.Hash(newPassword)
+	if err != nil {
+		return fmt.Errorf("service: hash password: %w", err)
+	}
+
+	// 更新密码同时递增 Token 版本号，所有设备上的登录状态失效
+	if err := s.userRepo.UpdateUserPassword(ctx, userID, hashedPassword); err != nil {
+		metrics.RecordUserOperation("password_reset", false)
+		return fmt.Errorf("service: update password: %w", err)
+	}
+	if err := s.passwords.Remember(ctx, userID, hashedPassword); err != nil {
+		slog.WarnContext(ctx, "Failed to save password history", "user_id", userID, "error", err)
+	}
+
+	// 能收到重置邮件即证明拥有该邮箱
+	if _, err := s.userRepo.MarkEmailVerified(ctx, userID); err != nil {
+		slog.WarnContext(ctx, "Failed to mark email verified after password reset", "user_id", userID, "error", err)
+	}
+
+	slog.InfoContext(ctx, "Password reset", "user_id", userID)
+	metrics.RecordUserOperation("password_reset", true)
+	return nil
+}
+
+// issueToken 签发一次性令牌并保存哈希
+func (s *accountService) issueToken(ctx context.Context, userID int64, purpose string, ttl time.Duration) (string, error) {
+	token, tokenHash, err := s.signer.Issue(purpose)
+	if err != nil {
+		return "", fmt.Errorf("service: issue %s token: %w", purpose, err)
+	}
+
+	if err := s.tokenRepo.CreateToken(ctx, userID, purpose, tokenHash, time.Now().Add(ttl)); err != nil {
+		return "", fmt.Errorf("service: save %s token: %w", purpose, err)
+	}
+	return token, nil
+}
+
+// peekToken 校验签名并查询令牌所属用户 ID（不使用令牌）
+func (s *accountService) peekToken(ctx context.Context, purpose, token string) (int64, error) {
+	tokenHash, err := s.signer.Verify(purpose, token)
+	if err != nil {
+		slog.WarnContext(ctx, "Invalid account token signature", "purpose", purpose)
+		return 0, ErrInvalidAccountToken
+	}
+
+	userID, err := s.tokenRepo.PeekToken(ctx, purpose, tokenHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			slog.WarnContext(ctx, "Account token not found, used or expired", "purpose", purpose)
+			return 0, ErrInvalidAccountToken
+		}
+		return 0, fmt.Errorf("service: get %s token: %w", purpose, err)
+	}
+	return userID, nil
+}
+
+// consumeToken 校验签名并使用令牌，返回所属用户 ID
+func (s *accountService) consumeToken(ctx context.Context, purpose, token string) (int64, error) {
+	tokenHash, err := s.signer.Verify(purpose, token)
+	if err != nil {
+		slog.WarnContext(ctx, "Invalid account token signature", "purpose", purpose)
+		return 0, ErrInvalidAccountToken
+	}
+
+	userID, err := s.tokenRepo.ConsumeToken(ctx, purpose, tokenHash)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			slog.WarnContext(ctx, "Account token not found, used or expired", "purpose", purpose)
+			return 0, ErrInvalidAccountToken
+		}
+		return 0, fmt.Errorf("service: consume %s token: %w", purpose, err)
+	}
+	return userID, nil
+}
+
+// link 生成邮件中的链接
+func (s *accountService) link(path, token string) string {
+	return s.config.BaseURL + path + "?token=" + url.QueryEscape(token)
+}
@@ -0,0 +1,262 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gin_demo/internal/repository"
+	"gin_demo/pkg/auth"
+	"gin_demo/pkg/mail"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockUserTokenRepository 是 UserTokenRepository 的 mock 实现
+type MockUserTokenRepository struct {
+	mock.Mock
+}
+
+func (m *MockUserTokenRepository) CreateToken(ctx context.Context, userID int64, purpose, tokenHash string, expiresAt time.Time) error {
+	args := m.Called(ctx, userID, purpose, tokenHash, expiresAt)
+	return args.Error(0)
+}
+
+func (m *MockUserTokenRepository) ConsumeToken(ctx context.Context, purpose, tokenHash string) (int64, error) {
+	args := m.Called(ctx, purpose, tokenHash)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockUserTokenRepository) PeekToken(ctx context.Context, purpose, tokenHash string) (int64, error) {
+	args := m.Called(ctx, purpose, tokenHash)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockUserTokenRepository) DeleteExpiredTokens(ctx context.Context, before time.Time) (int64, error) {
+	args := m.Called(ctx, before)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+// captureMailer 记录发送的邮件
+type captureMailer struct {
+	mu       sync.Mutex
+	messages []mail.Message
+	err      error
+}
+
+func (m *captureMailer) Send(_ context.Context, msg mail.Message) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.err != nil {
+		return m.err
+	}
+	m.messages = append(m.messages, msg)
+	return nil
+}
+
+// tokenFromMail 从邮件正文的链接中提取令牌
+func tokenFromMail(t *testing.T, msg mail.Message) string {
+	t.Helper()
+	for _, field := range strings.Fields(msg.Body) {
+		if strings.HasPrefix(field, "http") {
+			u, err := url.Parse(field)
+			require.NoError(t, err)
+			return u.Query().Get("token")
+		}
+	}
+	t.Fatal("no link in mail body")
+	return ""
+}
+
+func newTestAccountService() (AccountService, *MockUserRepository, *MockUserTokenRepository, *captureMailer) {
+	userRepo := new(MockUserRepository)
+	tokenRepo := new(MockUserTokenRepository)
+	mailer := &captureMailer{}
+	service := NewAccountService(userRepo, tokenRepo, mailer,
+		auth.NewOneTimeTokenSigner([]byte("test-account-token-secret-0123456789")), testHasher, testPasswords,
+		AccountConfig{BaseURL: "https://app.example.com/", VerifyEmailTTL: 24 * time.Hour, PasswordResetTTL: 30 * time.Minute, InviteTTL: 7 * 24 * time.Hour})
+	return service, userRepo, tokenRepo, mailer
+}
+
+// TestAccountService_VerifyEmail 测试邮箱验证流程
+func TestAccountService_VerifyEmail(t *testing.T) {
+	ctx := context.Background()
+	user := repository.User{ID: 1, Username: "testuser", Email: "test@example.com", Status: repository.UserStatusPending}
+
+	t.Run("发送并验证", func(t *testing.T) {
+		service, userRepo, tokenRepo, mailer := newTestAccountService()
+
+		var savedHash string
+		tokenRepo.On("CreateToken", ctx, int64(1), repository.TokenPurposeVerifyEmail, mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).
+			Run(func(args mock.Arguments) { savedHash = args.String(3) }).Return(nil)
+
+		require.NoError(t, service.SendVerificationEmail(ctx, user))
+		require.Len(t, mailer.messages, 1)
+		assert.Equal(t, []string{"test@example.com"}, mailer.messages[0].To)
+		assert.Contains(t, mailer.messages[0].Body, "https://app.example.com/verify-email?token=")
+
+		// 数据库只保存令牌哈希
+		token := tokenFromMail(t, mailer.messages[0])
+		assert.NotEqual(t, token, savedHash)
+
+		tokenRepo.On("ConsumeToken", ctx, repository.TokenPurposeVerifyEmail, savedHash).Return(int64(1), nil)
+		userRepo.On("MarkEmailVerified", ctx, int64(1)).Return(true, nil)
+
+		require.NoError(t, service.VerifyEmail(ctx, token))
+		tokenRepo.AssertExpectations(t)
+		userRepo.AssertExpectations(t)
+	})
+
+	t.Run("篡改的令牌不查询数据库", func(t *testing.T) {
+		service, _, tokenRepo, _ := newTestAccountService()
+
+		err := service.VerifyEmail(ctx, "forged.token")
+		assert.ErrorIs(t, err, ErrInvalidAccountToken)
+		tokenRepo.AssertNotCalled(t, "ConsumeToken", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("重置密码令牌不能用于验证邮箱", func(t *testing.T) {
+		service, userRepo, tokenRepo, mailer := newTestAccountService()
+		userRepo.On("GetUserByEmail", ctx, "test@example.com").Return(user, nil)
+		tokenRepo.On("CreateToken", ctx, int64(1), repository.TokenPurposeResetPassword, mock.Anything, mock.Anything).Return(nil)
+
+		require.NoError(t, service.RequestPasswordReset(ctx, "test@example.com"))
+		token := tokenFromMail(t, mailer.messages[0])
+
+		err := service.VerifyEmail(ctx, token)
+		assert.ErrorIs(t, err, ErrInvalidAccountToken)
+	})
+
+	t.Run("令牌已使用或已过期", func(t *testing.T) {
+		service, _, tokenRepo, mailer := newTestAccountService()
+		tokenRepo.On("CreateToken", ctx, int64(1), repository.TokenPurposeVerifyEmail, mock.Anything, mock.Anything).Return(nil)
+		tokenRepo.On("ConsumeToken", ctx, repository.TokenPurposeVerifyEmail, mock.Anything).Return(int64(0), sql.ErrNoRows)
+
+		require.NoError(t, service.SendVerificationEmail(ctx, user))
+		err := service.VerifyEmail(ctx, tokenFromMail(t, mailer.messages[0]))
+		assert.ErrorIs(t, err, ErrInvalidAccountToken)
+	})
+
+	t.Run("重新发送时已验证的用户静默忽略", func(t *testing.T) {
+		service, userRepo, tokenRepo, mailer := newTestAccountService()
+		verified := user
+		verified.Status = repository.UserStatusActive
+		userRepo.On("GetUserByEmail", ctx, "test@example.com").Return(verified, nil)
+
+		require.NoError(t, service.ResendVerificationEmail(ctx, "test@example.com"))
+		assert.Empty(t, mailer.messages)
+		tokenRepo.AssertNotCalled(t, "CreateToken", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// TestAccountService_PasswordReset 测试密码重置流程
+func TestAccountService_PasswordReset(t *testing.T) {
+	ctx := context.Background()
+	user := repository.User{ID: 1, Username: "testuser", Email: "test@example.com", Status: repository.UserStatusActive}
+
+	t.Run("请求并重置", func(t *testing.T) {
+		service, userRepo, tokenRepo, mailer := newTestAccountService()
+		userRepo.On("GetUserByEmail", ctx, "test@example.com").Return(user, nil)
+
+		var savedHash string
+		var expiresAt time.Time
+		tokenRepo.On("CreateToken", ctx, int64(1), repository.TokenPurposeResetPassword, mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).
+			Run(func(args mock.Arguments) {
+				savedHash = args.String(3)
+				expiresAt = args.Get(4).(time.Time)
+			}).Return(nil)
+
+		require.NoError(t, service.RequestPasswordReset(ctx, "test@example.com"))
+		require.Len(t, mailer.messages, 1)
+		assert.WithinDuration(t, time.Now().Add(30*time.Minute), expiresAt, time.Minute)
+
+		tokenRepo.On("PeekToken", ctx, repository.TokenPurposeResetPassword, savedHash).Return(int64(1), nil)
+		userRepo.On("GetUserByID", ctx, int64(1)).Return(user, nil)
+		tokenRepo.On("ConsumeToken", ctx, repository.TokenPurposeResetPassword, savedHash).Return(int64(1), nil)
+		userRepo.On("UpdateUserPassword", ctx, int64(1), mock.AnythingOfType("string")).Return(nil)
+		userRepo.On("MarkEmailVerified", ctx, int64(1)).Return(false, nil)
+
+		require.NoError(t, service.ResetPassword(ctx, tokenFromMail(t, mailer.messages[0]), "newpassword"))
+
+		// 保存的是新密码的哈希
+		var hashed string
+		for _, call := range userRepo.Calls {
+			if call.Method == "UpdateUserPassword" {
+				hashed = call.Arguments.String(2)
+			}
+		}
+		ok, err := testHasher.Verify("newpassword", hashed)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		userRepo.AssertExpectations(t)
+	})
+
+	t.Run("邮箱不存在时静默忽略", func(t *testing.T) {
+		service, userRepo, tokenRepo, mailer := newTestAccountService()
+		userRepo.On("GetUserByEmail", ctx, "nobody@example.com").Return(repository.User{}, sql.ErrNoRows)
+
+		require.NoError(t, service.RequestPasswordReset(ctx, "nobody@example.com"))
+		assert.Empty(t, mailer.messages)
+		tokenRepo.AssertNotCalled(t, "CreateToken", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("邮件发送失败", func(t *testing.T) {
+		service, userRepo, tokenRepo, mailer := newTestAccountService()
+		mailer.err = errors.New("smtp down")
+		userRepo.On("GetUserByEmail", ctx, "test@example.com").Return(user, nil)
+		tokenRepo.On("CreateToken", ctx, int64(1), repository.TokenPurposeResetPassword, mock.Anything, mock.Anything).Return(nil)
+
+		assert.Error(t, service.RequestPasswordReset(ctx, "test@example.com"))
+	})
+
+	t.Run("新密码不符合策略时不消费令牌", func(t *testing.T) {
+		userRepo := new(MockUserRepository)
+		tokenRepo := new(MockUserTokenRepository)
+		mailer := &captureMailer{}
+		signer := auth.NewOneTimeTokenSigner([]byte("test-account-token-secret-0123456789"))
+		passwords := NewPasswordService(auth.NewPasswordPolicy(auth.PasswordPolicyConfig{MinLength: 12}, nil), testHasher, userRepo, 0)
+		service := NewAccountService(userRepo, tokenRepo, mailer, signer, testHasher, passwords,
+			AccountConfig{BaseURL: "https://app.example.com/", PasswordResetTTL: 30 * time.Minute})
+
+		userRepo.On("GetUserByEmail", ctx, "test@example.com").Return(user, nil)
+		tokenRepo.On("CreateToken", ctx, int64(1), repository.TokenPurposeResetPassword, mock.Anything, mock.Anything).Return(nil)
+		require.NoError(t, service.RequestPasswordReset(ctx, "test@example.com"))
+
+		tokenRepo.On("PeekToken", ctx, repository.TokenPurposeResetPassword, mock.Anything).Return(int64(1), nil)
+		userRepo.On("GetUserByID", ctx, int64(1)).Return(user, nil)
+
+		err := service.ResetPassword(ctx, tokenFromMail(t, mailer.messages[0]), "short")
+		assert.ErrorIs(t, err, ErrPasswordPolicy)
+		tokenRepo.AssertNotCalled(t, "ConsumeToken", mock.Anything, mock.Anything, mock.Anything)
+		userRepo.AssertNotCalled(t, "UpdateUserPassword", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("邀请链接是有效期更长的重置链接", func(t *testing.T) {
+		service, _, tokenRepo, mailer := newTestAccountService()
+
+		var expiresAt time.Time
+		tokenRepo.On("CreateToken", ctx, int64(1), repository.TokenPurposeResetPassword, mock.AnythingOfType("string"), mock.AnythingOfType("time.Time")).
+			Run(func(args mock.Arguments) { expiresAt = args.Get(4).(time.Time) }).Return(nil)
+
+		require.NoError(t, service.SendInvitation(ctx, user))
+		require.Len(t, mailer.messages, 1)
+		assert.Equal(t, []string{"test@example.com"}, mailer.messages[0].To)
+		assert.Contains(t, mailer.messages[0].Body, "https://app.example.com/reset-password?token=")
+		assert.WithinDuration(t, time.Now().Add(7*24*time.Hour), expiresAt, time.Minute)
+	})
+
+	t.Run("令牌无效时不修改密码", func(t *testing.T) {
+		service, userRepo, _, _ := newTestAccountService()
+
+		err := service.ResetPassword(ctx, "forged.token", "newpassword")
+		assert.ErrorIs(t, err, ErrInvalidAccountToken)
+		userRepo.AssertNotCalled(t, "UpdateUserPassword", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
@@ -0,0 +1,352 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gin_demo/internal/repository"
+	"gin_demo/internal/response"
+	"gin_demo/pkg/auth"
+	"gin_demo/pkg/metrics"
+	"gin_demo/pkg/tenant"
+)
+
+var (
+	// ErrInvalidAPIKey API Key 无效、已吊销或已过期（不区分原因，避免泄露 Key 状态）
+	ErrInvalidAPIKey = response.New(response.CodeUnauthorized, "API Key 无效或已过期")
+	// ErrAPIKeyNotFound API Key 不存在（或不属于当前用户）
+	ErrAPIKeyNotFound = response.New(response.CodeNotFound, "API Key 不存在")
+	// ErrAPIKeyLimitExceeded API Key 数量已达上限
+	ErrAPIKeyLimitExceeded = response.New(response.CodeInvalidParams, "API Key 数量已达上限，请先吊销不再使用的 Key")
+	// ErrAPIKeyScopeNotAllowed 授予的权限范围超出了用户自身的权限
+	ErrAPIKeyScopeNotAllowed = response.New(response.CodeForbidden, "不能授予自己没有的权限")
+)
+
+// APIKeyConfig API Key 配置
+type APIKeyConfig struct {
+	MaxPerUser       int           // 每个用户最多可用的 Key 数量
+	MaxTTL           time.Duration // 最长有效期（0 表示允许永不过期）
+	LastUsedInterval time.Duration // 最近使用时间的记录间隔
+}
+
+// APIKey API Key 信息（不包含密钥）
+type APIKey struct {
+	ID         int64
+	Name       string
+	Prefix     string
+	Scopes     []auth.Permission
+	ExpiresAt  *time.Time
+	LastUsedAt *time.Time
+	CreatedAt  time.Time
+}
+
+// CreatedAPIKey 新创建的 API Key（Key 明文只返回这一次）
+type CreatedAPIKey struct {
+	APIKey
+	Key string
+}
+
+// CreateAPIKeyInput 创建 API Key 输入
+type CreateAPIKeyInput struct {
+	UserID int64
+	Name   string
+	Scopes []auth.Permission
+	TTL    time.Duration // 有效期（0 表示使用配置的最长有效期）
+}
+
+// APIKeyService API Key 业务逻辑接口
+type APIKeyService interface {
+	// Create 创建 API Key（权限范围不能超出用户自身的权限）
+	Create(ctx context.Context, input CreateAPIKeyInput) (CreatedAPIKey, error)
+
+	// List 列出用户未吊销的 API Key
+	List(ctx context.Context, userID int64) ([]APIKey, error)
+
+	// Revoke 吊销用户的 API Key（立即生效）
+	Revoke(ctx context.Context, userID, keyID int64) error
+
+	// Authenticate 校验 API Key，返回与 JWT 认证相同结构的 Claims（权限受 Key 的范围限制）
+	Authenticate(ctx context.Context, key string) (*auth.RBACClaims, error)
+}
+
+// apiKeyService API Key 业务逻辑实现
+type apiKeyService struct {
+	keyRepo  repository.APIKeyRepositoryInterface
+	userRepo repository.UserRepositoryInterface
+	config   APIKeyConfig
+}
+
+// NewAPIKeyService 创建 API Key 服务实例
+func NewAPIKeyService(
+	keyRepo repository.APIKeyRepositoryInterface,
+	userRepo repository.UserRepositoryInterface,
+	config APIKeyConfig,
+) APIKeyService {
+	return &apiKeyService{
+		keyRepo:  keyRepo,
+		userRepo: userRepo,
+		config:   config,
+	}
+}
+
+// Create 创建 API Key
+func (s *apiKeyService) Create(ctx context.Context, input CreateAPIKeyInput) (CreatedAPIKey, error) {
+	// 1. 参数验证
+	if input.Name == "" || len(input.Scopes) == 0 || input.TTL < 0 {
+		return CreatedAPIKey{}, ErrInvalidInput
+	}
+	scopes, err := normalizeScopes(input.Scopes)
+	if err != nil {
+		return CreatedAPIKey{}, err
+	}
+
+	ttl := input.TTL
+	if ttl == 0 {
+		ttl = s.config.MaxTTL
+	}
+	if s.config.MaxTTL > 0 && ttl > s.config.MaxTTL {
+		return CreatedAPIKey{}, response.New(response.CodeInvalidParams,
+			fmt.Sprintf("有效期不能超过 %s", s.config.MaxTTL))
+	}
+
+	// 2. 权限范围不能超出用户自身的权限（防止借 Key 提权）
+	owner, err := s.ownerClaims(ctx, input.UserID)
+	if err != nil {
+		return CreatedAPIKey{}, err
+	}
+	for _, scope := range scopes {
+		if !owner.HasPermission(scope) {
+			slog.WarnContext(ctx, "API key scope not allowed", "user_id", input.UserID, "scope", scope)
+			return CreatedAPIKey{}, ErrAPIKeyScopeNotAllowed
+		}
+	}
+
+	// 3. 数量上限
+	now := time.Now()
+	count, err := s.keyRepo.CountActiveAPIKeys(ctx, input.UserID, now)
+	if err != nil {
+		return CreatedAPIKey{}, fmt.Errorf("service: count api keys: %w", err)
+	}
+	if count >= int64(s.config.MaxPerUser) {
+		return CreatedAPIKey{}, ErrAPIKeyLimitExceeded
+	}
+
+	// 4. 生成 Key（只保存密钥哈希）
+	generated, err := auth.GenerateAPIKey()
+	if err != nil {
+		return CreatedAPIKey{}, fmt.Errorf("service: generate api key: %w", err)
+	}
+
+	var expiresAt sql.NullTime
+	if ttl > 0 {
+		expiresAt = sql.NullTime{Time: now.Add(ttl), Valid: true}
+	}
+
+	scopeValues := make([]string, len(scopes))
+	for i, scope := range scopes {
+		scopeValues[i] = string(scope)
+	}
+
+	keyID, err := s.keyRepo.CreateAPIKey(ctx, repository.CreateAPIKeyParams{
+		TenantID:   owner.TenantID,
+		UserID:     input.UserID,
+		Name:       input.Name,
+		Prefix:     generated.Prefix,
+		SecretHash: generated.SecretHash,
+		ExpiresAt:  expiresAt,
+	}, scopeValues)
+	if err != nil {
+		metrics.RecordUserOperation("create_api_key", false)
+		return CreatedAPIKey{}, fmt.Errorf("service: create api key: %w", err)
+	}
+
+	slog.InfoContext(ctx, "API key created", "user_id", input.UserID, "key_id", keyID, "prefix", generated.Prefix, "scopes", scopeValues)
+	metrics.RecordUserOperation("create_api_key", true)
+
+	return CreatedAPIKey{
+		APIKey: APIKey{
+			ID:        keyID,
+			Name:      input.Name,
+			Prefix:    generated.Prefix,
+			Scopes:    scopes,
+			ExpiresAt: nullTimePtr(expiresAt),
+			CreatedAt: now,
+		},
+		Key: generated.Key,
+	}, nil
+}
+
+// List 列出用户未吊销的 API Key
+func (s *apiKeyService) List(ctx context.Context, userID int64) ([]APIKey, error) {
+	keys, err := s.keyRepo.ListUserAPIKeys(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("service: list api keys: %w", err)
+	}
+
+	scopes, err := s.keyRepo.ListUserAPIKeyScopes(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("service: list api key scopes: %w", err)
+	}
+
+	result := make([]APIKey, len(keys))
+	for i, key := range keys {
+		result[i] = APIKey{
+			ID:         key.ID,
+			Name:       key.Name,
+			Prefix:     key.Prefix,
+			Scopes:     toPermissions(scopes[key.ID]),
+			ExpiresAt:  nullTimePtr(key.ExpiresAt),
+			LastUsedAt: nullTimePtr(key.LastUsedAt),
+			CreatedAt:  key.CreatedAt,
+		}
+	}
+	return result, nil
+}
+
+// Revoke 吊销用户的 API Key
+func (s *apiKeyService) Revoke(ctx context.Context, userID, keyID int64) error {
+	revoked, err := s.keyRepo.RevokeAPIKey(ctx, userID, keyID)
+	if err != nil {
+		metrics.RecordUserOperation("revoke_api_key", false)
+		return fmt.Errorf("service: revoke api key: %w", err)
+	}
+	if !revoked {
+		return ErrAPIKeyNotFound
+	}
+
+	slog.InfoContext(ctx, "API key revoked", "user_id", userID, "key_id", keyID)
+	metrics.RecordUserOperation("revoke_api_key", true)
+	return nil
+}
+
+// Authenticate 校验 API Key
+func (s *apiKeyService) Authenticate(ctx context.Context, key string) (*auth.RBACClaims, error) {
+	// 1. 解析并查找 Key
+	prefix, secretHash, err := auth.ParseAPIKey(key)
+	if err != nil {
+		return nil, ErrInvalidAPIKey
+	}
+
+	apiKey, err := s.keyRepo.GetAPIKeyByPrefix(ctx, prefix)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, fmt.Errorf("service: get api key: %w", err)
+	}
+
+	// 2. 校验密钥、吊销状态和有效期
+	now := time.Now()
+	if !auth.APIKeySecretMatches(secretHash, apiKey.SecretHash) {
+		return nil, ErrInvalidAPIKey
+	}
+	if apiKey.RevokedAt.Valid || (apiKey.ExpiresAt.Valid && !now.Before(apiKey.ExpiresAt.Time)) {
+		slog.WarnContext(ctx, "Revoked or expired api key used", "key_id", apiKey.ID, "user_id", apiKey.UserID)
+		return nil, ErrInvalidAPIKey
+	}
+
+	// 3. 按 Key 所属的租户加载用户（请求未指定租户时使用 Key 的租户，指定了其他租户时拒绝）
+	if current, ok := tenant.FromContext(ctx); ok && current != apiKey.TenantID {
+		slog.WarnContext(ctx, "API key used in another tenant", "key_id", apiKey.ID, "tenant_id", current)
+		return nil, ErrInvalidAPIKey
+	}
+	ctx = tenant.WithID(ctx, apiKey.TenantID)
+
+	// 4. 加载所属用户的当前角色和权限（用户被禁用或角色变更立即生效）
+	claims, err := s.ownerClaims(ctx, apiKey.UserID)
+	if err != nil {
+		if errors.Is(err, ErrUserNotFound) {
+			return nil, ErrInvalidAPIKey
+		}
+		return nil, err
+	}
+
+	scopes, err := s.keyRepo.ListAPIKeyScopes(ctx, apiKey.ID)
+	if err != nil {
+		return nil, fmt.Errorf("service: list api key scopes: %w", err)
+	}
+	claims.Scopes = toPermissions(scopes)
+	if len(claims.Scopes) == 0 {
+		// 没有有效的权限范围时拒绝，不能退化为拥有用户全部权限
+		return nil, ErrInvalidAPIKey
+	}
+
+	// 5. 记录最近使用时间（失败不影响本次请求）
+	if err := s.keyRepo.TouchAPIKey(ctx, apiKey.ID, now, s.config.LastUsedInterval); err != nil {
+		slog.WarnContext(ctx, "Failed to record api key usage", "key_id", apiKey.ID, "error", err)
+	}
+
+	return claims, nil
+}
+
+// ownerClaims 加载用户当前的角色、额外权限和 Token 版本号
+func (s *apiKeyService) ownerClaims(ctx context.Context, userID int64) (*auth.RBACClaims, error) {
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrUserNotFound
+		}
+		return nil, fmt.Errorf("service: get user: %w", err)
+	}
+	if user.Status != repository.UserStatusActive {
+		return nil, ErrUserNotFound
+	}
+
+	permissions, err := s.userRepo.GetUserPermissions(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("service: get permissions: %w", err)
+	}
+
+	role := auth.Role(user.Role)
+	if role == "" {
+		role = auth.RoleUser
+	}
+
+	return &auth.RBACClaims{
+		UserID:      user.ID,
+		Role:        role,
+		Permissions: toPermissions(permissions),
+		Version:     user.TokenVersion,
+		TenantID:    user.TenantID,
+	}, nil
+}
+
+// normalizeScopes 校验并去重权限范围
+func normalizeScopes(scopes []auth.Permission) ([]auth.Permission, error) {
+	seen := make(map[auth.Permission]struct{}, len(scopes))
+	result := make([]auth.Permission, 0, len(scopes))
+	for _, scope := range scopes {
+		if !scope.IsValid() {
+			return nil, response.New(response.CodeInvalidParams, "无效的权限: "+string(scope))
+		}
+		if _, ok := seen[scope]; ok {
+			continue
+		}
+		seen[scope] = struct{}{}
+		result = append(result, scope)
+	}
+	return result, nil
+}
+
+// toPermissions 转换持久化的权限值（忽略未定义的权限）
+func toPermissions(values []string) []auth.Permission {
+	permissions := make([]auth.Permission, 0, len(values))
+	for _, v := range values {
+		if p := auth.Permission(v); p.IsValid() {
+			permissions = append(permissions, p)
+		}
+	}
+	return permissions
+}
+
+// nullTimePtr sql.NullTime 转换为指针（NULL 返回 nil）
+func nullTimePtr(t sql.NullTime) *time.Time {
+	if !t.Valid {
+		return nil
+	}
+	return &t.Time
+}
@@ -0,0 +1,283 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"gin_demo/internal/repository"
+	"gin_demo/pkg/auth"
+	"gin_demo/pkg/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockAPIKeyRepository 是 APIKeyRepository 的 mock 实现
+type MockAPIKeyRepository struct {
+	mock.Mock
+}
+
+func (m *MockAPIKeyRepository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (repository.ApiKey, error) {
+	args := m.Called(ctx, prefix)
+	return args.Get(0).(repository.ApiKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) ListAPIKeyScopes(ctx context.Context, keyID int64) ([]string, error) {
+	args := m.Called(ctx, keyID)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) ListUserAPIKeys(ctx context.Context, userID int64) ([]repository.ApiKey, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]repository.ApiKey), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) ListUserAPIKeyScopes(ctx context.Context, userID int64) (map[int64][]string, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(map[int64][]string), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) CountActiveAPIKeys(ctx context.Context, userID int64, now time.Time) (int64, error) {
+	args := m.Called(ctx, userID, now)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) CreateAPIKey(ctx context.Context, params repository.CreateAPIKeyParams, scopes []string) (int64, error) {
+	args := m.Called(ctx, params, scopes)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) RevokeAPIKey(ctx context.Context, userID, keyID int64) (bool, error) {
+	args := m.Called(ctx, userID, keyID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockAPIKeyRepository) TouchAPIKey(ctx context.Context, keyID int64, now time.Time, interval time.Duration) error {
+	args := m.Called(ctx, keyID, now, interval)
+	return args.Error(0)
+}
+
+func newTestAPIKeyService() (APIKeyService, *MockAPIKeyRepository, *MockUserRepository) {
+	keyRepo := new(MockAPIKeyRepository)
+	userRepo := new(MockUserRepository)
+	service := NewAPIKeyService(keyRepo, userRepo, APIKeyConfig{
+		MaxPerUser:       2,
+		MaxTTL:           30 * 24 * time.Hour,
+		LastUsedInterval: time.Minute,
+	})
+	return service, keyRepo, userRepo
+}
+
+// TestAPIKeyService_Create 测试创建 API Key
+func TestAPIKeyService_Create(t *testing.T) {
+	ctx := context.Background()
+	admin := repository.User{ID: 1, Role: string(auth.RoleAdmin), Status: repository.UserStatusActive, TenantID: tenant.DefaultID}
+
+	t.Run("创建成功", func(t *testing.T) {
+		service, keyRepo, userRepo := newTestAPIKeyService()
+		userRepo.On("GetUserByID", ctx, int64(1)).Return(admin, nil)
+		userRepo.On("GetUserPermissions", ctx, int64(1)).Return([]string{}, nil)
+		keyRepo.On("CountActiveAPIKeys", ctx, int64(1), mock.AnythingOfType("time.Time")).Return(int64(1), nil)
+		keyRepo.On("CreateAPIKey", ctx, mock.AnythingOfType("repository.CreateAPIKeyParams"), []string{"user:read"}).Return(int64(7), nil)
+
+		created, err := service.Create(ctx, CreateAPIKeyInput{
+			UserID: 1,
+			Name:   "batch job",
+			Scopes: []auth.Permission{auth.PermissionUserRead, auth.PermissionUserRead},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), created.ID)
+		assert.Equal(t, []auth.Permission{auth.PermissionUserRead}, created.Scopes)
+
+		// 未指定有效期时使用最长有效期，存储的是密钥哈希而不是明文
+		require.NotNil(t, created.ExpiresAt)
+		assert.WithinDuration(t, time.Now().Add(30*24*time.Hour), *created.ExpiresAt, time.Minute)
+		params := keyRepo.Calls[len(keyRepo.Calls)-1].Arguments.Get(1).(repository.CreateAPIKeyParams)
+		assert.Equal(t, created.Prefix, params.Prefix)
+		assert.Equal(t, tenant.DefaultID, params.TenantID)
+		assert.NotContains(t, created.Key, params.SecretHash)
+
+		prefix, secretHash, err := auth.ParseAPIKey(created.Key)
+		require.NoError(t, err)
+		assert.Equal(t, params.Prefix, prefix)
+		assert.Equal(t, params.SecretHash, secretHash)
+	})
+
+	t.Run("不能授予自己没有的权限", func(t *testing.T) {
+		service, keyRepo, userRepo := newTestAPIKeyService()
+		userRepo.On("GetUserByID", ctx, int64(1)).Return(admin, nil)
+		userRepo.On("GetUserPermissions", ctx, int64(1)).Return([]string{}, nil)
+
+		_, err := service.Create(ctx, CreateAPIKeyInput{
+			UserID: 1,
+			Name:   "config",
+			Scopes: []auth.Permission{auth.PermissionSystemConfig},
+		})
+		assert.ErrorIs(t, err, ErrAPIKeyScopeNotAllowed)
+		keyRepo.AssertNotCalled(t, "CreateAPIKey", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("无效的权限", func(t *testing.T) {
+		service, _, _ := newTestAPIKeyService()
+
+		_, err := service.Create(ctx, CreateAPIKeyInput{UserID: 1, Name: "x", Scopes: []auth.Permission{"user:*"}})
+		assert.Error(t, err)
+	})
+
+	t.Run("有效期超过上限", func(t *testing.T) {
+		service, _, _ := newTestAPIKeyService()
+
+		_, err := service.Create(ctx, CreateAPIKeyInput{
+			UserID: 1,
+			Name:   "x",
+			Scopes: []auth.Permission{auth.PermissionUserRead},
+			TTL:    365 * 24 * time.Hour,
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("数量已达上限", func(t *testing.T) {
+		service, keyRepo, userRepo := newTestAPIKeyService()
+		userRepo.On("GetUserByID", ctx, int64(1)).Return(admin, nil)
+		userRepo.On("GetUserPermissions", ctx, int64(1)).Return([]string{}, nil)
+		keyRepo.On("CountActiveAPIKeys", ctx, int64(1), mock.Anything).Return(int64(2), nil)
+
+		_, err := service.Create(ctx, CreateAPIKeyInput{UserID: 1, Name: "x", Scopes: []auth.Permission{auth.PermissionUserRead}})
+		assert.ErrorIs(t, err, ErrAPIKeyLimitExceeded)
+	})
+}
+
+// TestAPIKeyService_Authenticate 测试 API Key 认证
+func TestAPIKeyService_Authenticate(t *testing.T) {
+	ctx := context.Background()
+	admin := repository.User{ID: 1, Role: string(auth.RoleAdmin), Status: repository.UserStatusActive, TokenVersion: 3, TenantID: tenant.DefaultID}
+
+	generated, err := auth.GenerateAPIKey()
+	require.NoError(t, err)
+	stored := repository.ApiKey{
+		ID:         7,
+		UserID:     1,
+		Prefix:     generated.Prefix,
+		SecretHash: generated.SecretHash,
+		ExpiresAt:  sql.NullTime{Time: time.Now().Add(time.Hour), Valid: true},
+		TenantID:   tenant.DefaultID,
+	}
+
+	// inTenant 匹配租户为 id 的 context（认证时使用 Key 所属的租户）
+	inTenant := func(id int64) any {
+		return mock.MatchedBy(func(ctx context.Context) bool { return tenant.ID(ctx) == id })
+	}
+
+	t.Run("认证成功并限制权限范围", func(t *testing.T) {
+		service, keyRepo, userRepo := newTestAPIKeyService()
+		keyRepo.On("GetAPIKeyByPrefix", ctx, generated.Prefix).Return(stored, nil)
+		keyRepo.On("ListAPIKeyScopes", inTenant(tenant.DefaultID), int64(7)).Return([]string{"user:read"}, nil)
+		keyRepo.On("TouchAPIKey", inTenant(tenant.DefaultID), int64(7), mock.AnythingOfType("time.Time"), time.Minute).Return(nil)
+		userRepo.On("GetUserByID", inTenant(tenant.DefaultID), int64(1)).Return(admin, nil)
+		userRepo.On("GetUserPermissions", inTenant(tenant.DefaultID), int64(1)).Return([]string{}, nil)
+
+		claims, err := service.Authenticate(ctx, generated.Key)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), claims.UserID)
+		assert.Equal(t, auth.RoleAdmin, claims.Role)
+		assert.Equal(t, int64(3), claims.Version)
+		assert.True(t, claims.HasPermission(auth.PermissionUserRead))
+		assert.False(t, claims.HasPermission(auth.PermissionUserWrite))
+		keyRepo.AssertExpectations(t)
+	})
+
+	t.Run("请求未指定租户时使用 Key 所属的租户", func(t *testing.T) {
+		service, keyRepo, userRepo := newTestAPIKeyService()
+		key := stored
+		key.TenantID = 2
+		owner := admin
+		owner.TenantID = 2
+		keyRepo.On("GetAPIKeyByPrefix", ctx, generated.Prefix).Return(key, nil)
+		keyRepo.On("ListAPIKeyScopes", inTenant(2), int64(7)).Return([]string{"user:read"}, nil)
+		keyRepo.On("TouchAPIKey", inTenant(2), int64(7), mock.AnythingOfType("time.Time"), time.Minute).Return(nil)
+		userRepo.On("GetUserByID", inTenant(2), int64(1)).Return(owner, nil)
+		userRepo.On("GetUserPermissions", inTenant(2), int64(1)).Return([]string{}, nil)
+
+		claims, err := service.Authenticate(ctx, generated.Key)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), claims.TenantID)
+		userRepo.AssertExpectations(t)
+	})
+
+	t.Run("请求指定了其他租户", func(t *testing.T) {
+		service, keyRepo, userRepo := newTestAPIKeyService()
+		other := tenant.WithID(ctx, 2)
+		keyRepo.On("GetAPIKeyByPrefix", other, generated.Prefix).Return(stored, nil)
+
+		_, err := service.Authenticate(other, generated.Key)
+		assert.ErrorIs(t, err, ErrInvalidAPIKey)
+		userRepo.AssertNotCalled(t, "GetUserByID", mock.Anything, mock.Anything)
+	})
+
+	t.Run("密钥错误", func(t *testing.T) {
+		service, keyRepo, _ := newTestAPIKeyService()
+		keyRepo.On("GetAPIKeyByPrefix", ctx, generated.Prefix).Return(stored, nil)
+
+		_, err := service.Authenticate(ctx, "gdk_"+generated.Prefix+"_wrong-secret")
+		assert.ErrorIs(t, err, ErrInvalidAPIKey)
+	})
+
+	t.Run("已吊销", func(t *testing.T) {
+		service, keyRepo, _ := newTestAPIKeyService()
+		revoked := stored
+		revoked.RevokedAt = sql.NullTime{Time: time.Now(), Valid: true}
+		keyRepo.On("GetAPIKeyByPrefix", ctx, generated.Prefix).Return(revoked, nil)
+
+		_, err := service.Authenticate(ctx, generated.Key)
+		assert.ErrorIs(t, err, ErrInvalidAPIKey)
+	})
+
+	t.Run("已过期", func(t *testing.T) {
+		service, keyRepo, _ := newTestAPIKeyService()
+		expired := stored
+		expired.ExpiresAt = sql.NullTime{Time: time.Now().Add(-time.Second), Valid: true}
+		keyRepo.On("GetAPIKeyByPrefix", ctx, generated.Prefix).Return(expired, nil)
+
+		_, err := service.Authenticate(ctx, generated.Key)
+		assert.ErrorIs(t, err, ErrInvalidAPIKey)
+	})
+
+	t.Run("所属用户已禁用", func(t *testing.T) {
+		service, keyRepo, userRepo := newTestAPIKeyService()
+		keyRepo.On("GetAPIKeyByPrefix", ctx, generated.Prefix).Return(stored, nil)
+		userRepo.On("GetUserByID", inTenant(tenant.DefaultID), int64(1)).Return(repository.User{}, sql.ErrNoRows)
+
+		_, err := service.Authenticate(ctx, generated.Key)
+		assert.ErrorIs(t, err, ErrInvalidAPIKey)
+	})
+
+	t.Run("Key 不存在", func(t *testing.T) {
+		service, keyRepo, _ := newTestAPIKeyService()
+		keyRepo.On("GetAPIKeyByPrefix", ctx, generated.Prefix).Return(repository.ApiKey{}, sql.ErrNoRows)
+
+		_, err := service.Authenticate(ctx, generated.Key)
+		assert.ErrorIs(t, err, ErrInvalidAPIKey)
+	})
+}
+
+// TestAPIKeyService_Revoke 测试吊销 API Key
+func TestAPIKeyService_Revoke(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("吊销成功", func(t *testing.T) {
+		service, keyRepo, _ := newTestAPIKeyService()
+		keyRepo.On("RevokeAPIKey", ctx, int64(1), int64(7)).Return(true, nil)
+
+		assert.NoError(t, service.Revoke(ctx, 1, 7))
+	})
+
+	t.Run("不属于当前用户", func(t *testing.T) {
+		service, keyRepo, _ := newTestAPIKeyService()
+		keyRepo.On("RevokeAPIKey", ctx, int64(2), int64(7)).Return(false, nil)
+
+		assert.ErrorIs(t, service.Revoke(ctx, 2, 7), ErrAPIKeyNotFound)
+	})
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"gin_demo/internal/repository"
+	"gin_demo/internal/response"
+	"gin_demo/pkg/audit"
+)
+
+// 审计操作（Event.Action）
+const (
+	AuditActionUserRegister       = "user.register"
+	AuditActionUserUpdate         = "user.update"
+	AuditActionUserPasswordChange = "user.password_change"
+	AuditActionUserDelete         = "user.delete"
+	AuditActionUserRoleUpdate     = "user.role_update"
+	AuditActionUserSuspend        = "user.suspend"
+	AuditActionUserReactivate     = "user.reactivate"
+	AuditActionUserRestore        = "user.restore"
+	AuditActionUserExport         = "user.export"
+	AuditActionUserErase          = "user.erase"
+	AuditActionUserMerge          = "user.merge"
+	AuditActionUserImport         = "user.import"
+	AuditActionUserListExport     = "user.list_export"
+)
+
+// AuditTargetUser 审计目标类型：用户
+const AuditTargetUser = "user"
+
+const (
+	// defaultAuditPageSize 默认每页条数
+	defaultAuditPageSize = 50
+	// maxAuditPageSize 每页最多条数
+	maxAuditPageSize = 200
+)
+
+var (
+	// ErrInvalidAuditCursor 游标无效
+	ErrInvalidAuditCursor = response.New(response.CodeInvalidParams, "无效的分页游标")
+)
+
+// AuditFilter 审计日志查询条件（零值表示不过滤）
+type AuditFilter struct {
+	ActorID    int64
+	Action     string
+	TargetType string
+	TargetID   int64
+	Since      time.Time // 起始时间（包含）
+	Until      time.Time // 结束时间（不包含）
+	Cursor     string    // 上一页返回的 NextCursor，为空表示第一页
+	Limit      int       // 每页条数（默认 50，最多 200）
+}
+
+// AuditEvent 审计事件
+type AuditEvent struct {
+	ID             int64
+	ActorID        int64 // 0 表示匿名操作
+	ImpersonatorID int64 // 0 表示不是模拟登录
+	Action         string
+	TargetType     string
+	TargetID       int64
+	Changes        audit.Changes
+	IP             string
+	RequestID      string
+	UserAgent      string
+	CreatedAt      time.Time
+}
+
+// AuditPage 审计日志分页结果（按时间倒序）
+type AuditPage struct {
+	Events     []AuditEvent
+	NextCursor string // 为空表示没有更多数据
+}
+
+// AuditService 审计日志业务逻辑接口
+type AuditService interface {
+	// List 查询当前租户的审计日志（游标分页）
+	List(ctx context.Context, filter AuditFilter) (AuditPage, error)
+
+	// WriteEvents 批量写入审计事件（实现 audit.Store，由审计写入器在后台调用）
+	WriteEvents(ctx context.Context, events []audit.Event) error
+}
+
+// auditService 审计日志业务逻辑实现
+type auditService struct {
+	auditRepo repository.AuditRepositoryInterface
+}
+
+// NewAuditService 创建审计日志服务实例
+func NewAuditService(auditRepo repository.AuditRepositoryInterface) AuditService {
+	return &auditService{
+		auditRepo: auditRepo,
+	}
+}
+
+// List 查询审计日志
+//
+// 按 ID 倒序分页：游标为上一页最后一条记录的 ID，新写入的事件不会导致翻页时重复或遗漏。
+func (s *auditService) List(ctx context.Context, filter AuditFilter) (AuditPage, error) {
+	limit := filter.Limit
+	if limit <= 0 {
+		limit = defaultAuditPageSize
+	}
+	if limit > maxAuditPageSize {
+		limit = maxAuditPageSize
+	}
+
+	beforeID, err := decodeAuditCursor(filter.Cursor)
+	if err != nil {
+		return AuditPage{}, err
+	}
+
+	// 多查一条判断是否还有下一页
+	rows, err := s.auditRepo.ListAuditEvents(ctx, repository.ListAuditEventsParams{
+		BeforeID:   beforeID,
+		ActorID:    filter.ActorID,
+		Action:     filter.Action,
+		TargetType: filter.TargetType,
+		TargetID:   filter.TargetID,
+		Since:      sql.NullTime{Time: filter.Since, Valid: !filter.Since.IsZero()},
+		Until:      sql.NullTime{Time: filter.Until, Valid: !filter.Until.IsZero()},
+		Limit:      int32(limit + 1),
+	})
+	if err != nil {
+		return AuditPage{}, fmt.Errorf("service: list audit events: %w", err)
+	}
+
+	var page AuditPage
+	if len(rows) > limit {
+		rows = rows[:limit]
+		page.NextCursor = encodeAuditCursor(rows[limit-1].ID)
+	}
+
+	page.Events = make([]AuditEvent, 0, len(rows))
+	for _, row := range rows {
+		event, err := toAuditEvent(row)
+		if err != nil {
+			return AuditPage{}, err
+		}
+		page.Events = append(page.Events, event)
+	}
+	return page, nil
+}
+
+// WriteEvents 批量写入审计事件
+func (s *auditService) WriteEvents(ctx context.Context, events []audit.Event) error {
+	params := make([]repository.CreateAuditEventParams, 0, len(events))
+	for _, event := range events {
+		var changes json.RawMessage
+		if len(event.Changes) > 0 {
+			data, err := json.Marshal(event.Changes)
+			if err != nil {
+				return fmt.Errorf("service: marshal audit changes: %w", err)
+			}
+			changes = data
+		}
+
+		params = append(params, repository.CreateAuditEventParams{
+			TenantID:       event.TenantID,
+			ActorID:        nullID(event.ActorID),
+			ImpersonatorID: nullID(event.ImpersonatorID),
+			Action:         event.Action,
+			TargetType:     event.TargetType,
+			TargetID:       nullID(event.TargetID),
+			Changes:        changes,
+			Ip:             event.IP,
+			RequestID:      event.RequestID,
+			UserAgent:      truncate(event.UserAgent, 255),
+			CreatedAt:      event.CreatedAt,
+		})
+	}
+
+	if err := s.auditRepo.CreateAuditEvents(ctx, params); err != nil {
+		return fmt.Errorf("service: write audit events: %w", err)
+	}
+	return nil
+}
+
+// toAuditEvent 转换为审计事件
+func toAuditEvent(row repository.AuditEvent) (AuditEvent, error) {
+	event := AuditEvent{
+		ID:             row.ID,
+		ActorID:        row.ActorID.Int64,
+		ImpersonatorID: row.ImpersonatorID.Int64,
+		Action:         row.Action,
+		TargetType:     row.TargetType,
+		TargetID:       row.TargetID.Int64,
+		IP:             row.Ip,
+		RequestID:      row.RequestID,
+		UserAgent:      row.UserAgent,
+		CreatedAt:      row.CreatedAt,
+	}
+	if len(row.Changes) > 0 && string(row.Changes) != "null" {
+		if err := json.Unmarshal(row.Changes, &event.Changes); err != nil {
+			return AuditEvent{}, fmt.Errorf("service: unmarshal audit changes %d: %w", row.ID, err)
+		}
+	}
+	return event, nil
+}
+
+// encodeAuditCursor 编码游标（不透明字符串，客户端原样传回）
+func encodeAuditCursor(id int64) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(strconv.FormatInt(id, 10)))
+}
+
+// decodeAuditCursor 解码游标（为空时返回 0，表示第一页）
+func decodeAuditCursor(cursor string) (int64, error) {
+	if cursor == "" {
+		return 0, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return 0, ErrInvalidAuditCursor
+	}
+	id, err := strconv.ParseInt(string(data), 10, 64)
+	if err != nil || id <= 0 {
+		return 0, ErrInvalidAuditCursor
+	}
+	return id, nil
+}
+
+// nullID 将 0 转换为 NULL
+func nullID(id int64) sql.NullInt64 {
+	return sql.NullInt64{Int64: id, Valid: id != 0}
+}
+
+// truncate 按字符截断字符串
+func truncate(s string, n int) string {
+	runes := []rune(s)
+	if len(runes) <= n {
+		return s
+	}
+	return string(runes[:n])
+}
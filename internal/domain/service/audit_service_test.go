@@ -0,0 +1,150 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"gin_demo/internal/repository"
+	"gin_demo/pkg/audit"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockAuditRepository 是 AuditRepository 的 mock 实现
+type MockAuditRepository struct {
+	mock.Mock
+}
+
+func (m *MockAuditRepository) CreateAuditEvents(ctx context.Context, events []repository.CreateAuditEventParams) error {
+	args := m.Called(ctx, events)
+	return args.Error(0)
+}
+
+func (m *MockAuditRepository) ListAuditEvents(ctx context.Context, params repository.ListAuditEventsParams) ([]repository.AuditEvent, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).([]repository.AuditEvent), args.Error(1)
+}
+
+// TestAuditService_List 测试审计日志游标分页
+func TestAuditService_List(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("有下一页时返回游标", func(t *testing.T) {
+		repo := new(MockAuditRepository)
+		svc := NewAuditService(repo)
+
+		since := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		repo.On("ListAuditEvents", ctx, repository.ListAuditEventsParams{
+			Action: AuditActionUserUpdate,
+			Since:  sql.NullTime{Time: since, Valid: true},
+			Limit:  3,
+		}).Return([]repository.AuditEvent{
+			{ID: 9, Action: AuditActionUserUpdate, ActorID: sql.NullInt64{Int64: 1, Valid: true},
+				Changes: json.RawMessage(`{"email":{"from":"a@example.com","to":"b@example.com"}}`)},
+			{ID: 7, Action: AuditActionUserUpdate},
+			{ID: 4, Action: AuditActionUserUpdate},
+		}, nil)
+
+		page, err := svc.List(ctx, AuditFilter{Action: AuditActionUserUpdate, Since: since, Limit: 2})
+		require.NoError(t, err)
+		require.Len(t, page.Events, 2)
+		assert.Equal(t, int64(1), page.Events[0].ActorID)
+		assert.Equal(t, audit.Changes{"email": {From: "a@example.com", To: "b@example.com"}}, page.Events[0].Changes)
+		assert.Zero(t, page.Events[1].ActorID)
+		require.NotEmpty(t, page.NextCursor)
+
+		// 下一页从游标之后开始
+		repo.On("ListAuditEvents", ctx, repository.ListAuditEventsParams{
+			BeforeID: 7,
+			Action:   AuditActionUserUpdate,
+			Limit:    3,
+		}).Return([]repository.AuditEvent{{ID: 4, Action: AuditActionUserUpdate}}, nil)
+
+		page, err = svc.List(ctx, AuditFilter{Action: AuditActionUserUpdate, Cursor: page.NextCursor, Limit: 2})
+		require.NoError(t, err)
+		assert.Len(t, page.Events, 1)
+		assert.Empty(t, page.NextCursor, "没有更多数据")
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("每页条数限制", func(t *testing.T) {
+		repo := new(MockAuditRepository)
+		svc := NewAuditService(repo)
+		repo.On("ListAuditEvents", ctx, repository.ListAuditEventsParams{Limit: maxAuditPageSize + 1}).
+			Return([]repository.AuditEvent{}, nil)
+
+		page, err := svc.List(ctx, AuditFilter{Limit: 10000})
+		require.NoError(t, err)
+		assert.Empty(t, page.Events)
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("无效游标", func(t *testing.T) {
+		repo := new(MockAuditRepository)
+		svc := NewAuditService(repo)
+
+		for _, cursor := range []string{"!!!", base64.RawURLEncoding.EncodeToString([]byte("abc")), base64.RawURLEncoding.EncodeToString([]byte("-1"))} {
+			_, err := svc.List(ctx, AuditFilter{Cursor: cursor})
+			assert.ErrorIs(t, err, ErrInvalidAuditCursor, cursor)
+		}
+		repo.AssertNotCalled(t, "ListAuditEvents", mock.Anything, mock.Anything)
+	})
+}
+
+// TestAuditService_WriteEvents 测试写入审计事件
+func TestAuditService_WriteEvents(t *testing.T) {
+	ctx := context.Background()
+	repo := new(MockAuditRepository)
+	svc := NewAuditService(repo)
+
+	createdAt := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	repo.On("CreateAuditEvents", ctx, []repository.CreateAuditEventParams{
+		{
+			TenantID:   1,
+			Action:     AuditActionUserRegister,
+			TargetType: AuditTargetUser,
+			TargetID:   sql.NullInt64{Int64: 5, Valid: true},
+			Changes:    json.RawMessage(`{"username":{"to":"alice"}}`),
+			Ip:         "10.0.0.1",
+			CreatedAt:  createdAt,
+		},
+		{
+			TenantID:       1,
+			ActorID:        sql.NullInt64{Int64: 5, Valid: true},
+			ImpersonatorID: sql.NullInt64{Int64: 1, Valid: true},
+			Action:         AuditActionUserPasswordChange,
+			TargetType:     AuditTargetUser,
+			TargetID:       sql.NullInt64{Int64: 5, Valid: true},
+			CreatedAt:      createdAt,
+		},
+	}).Return(nil)
+
+	err := svc.WriteEvents(ctx, []audit.Event{
+		{
+			TenantID:   1,
+			Action:     AuditActionUserRegister,
+			TargetType: AuditTargetUser,
+			TargetID:   5,
+			Changes:    audit.Changes{"username": {To: "alice"}},
+			IP:         "10.0.0.1",
+			CreatedAt:  createdAt,
+		},
+		{
+			TenantID:       1,
+			ActorID:        5,
+			ImpersonatorID: 1,
+			Action:         AuditActionUserPasswordChange,
+			TargetType:     AuditTargetUser,
+			TargetID:       5,
+			CreatedAt:      createdAt,
+		},
+	})
+	require.NoError(t, err)
+	repo.AssertExpectations(t)
+}
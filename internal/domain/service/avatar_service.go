@@ -0,0 +1,219 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"path"
+	"strconv"
+	"strings"
+
+	"gin_demo/internal/repository"
+	"gin_demo/internal/response"
+	"gin_demo/pkg/imaging"
+	"gin_demo/pkg/metrics"
+	"gin_demo/pkg/storage"
+)
+
+// AvatarSizes 头像的尺寸（正方形边长，像素）：第一个为用户资料中的头像，其余为缩略图
+//
+// 所有尺寸保存在同一目录下，文件名为 {边长}.jpg，客户端可以把头像地址中的 256.jpg 替换为 64.jpg 得到缩略图。
+var AvatarSizes = []int{256, 64}
+
+const (
+	// avatarMaxPixels 原图的最大像素数（解码后的图片在内存中约占每像素 1.5 到 4 字节）
+	avatarMaxPixels = 25_000_000
+	// avatarQuality JPEG 编码质量
+	avatarQuality = 85
+	// avatarCacheControl 头像文件的缓存策略（每次上传使用新的目录，文件不会被覆盖）
+	avatarCacheControl = "public, max-age=31536000, immutable"
+)
+
+var (
+	// ErrAvatarTooLarge 头像文件超过大小限制
+	ErrAvatarTooLarge = response.New(response.CodeRequestTooLarge, "头像文件过大")
+	// ErrAvatarFormat 头像内容不是支持的图片格式
+	ErrAvatarFormat = response.New(response.CodeInvalidParams, "不支持的图片格式（支持 JPEG、PNG、GIF）")
+	// ErrAvatarDimensions 头像图片的像素数超过限制
+	ErrAvatarDimensions = response.New(response.CodeInvalidParams, "图片尺寸过大")
+)
+
+// AvatarConfig 头像配置
+type AvatarConfig struct {
+	MaxSize int64 // 上传文件的最大字节数（不超过请求体大小限制）
+}
+
+// Avatar 上传后的头像
+type Avatar struct {
+	URL   string         // 用户资料中的头像地址（AvatarSizes[0]）
+	Sizes map[int]string // 各尺寸的地址
+}
+
+// AvatarService 头像业务逻辑接口
+type AvatarService interface {
+	// UploadAvatar 上传头像：按内容识别格式，裁剪缩放为 AvatarSizes 的各尺寸并重新编码为 JPEG，
+	// 保存后更新用户头像，并删除原来上传的头像文件
+	UploadAvatar(ctx context.Context, userID int64, r io.Reader) (Avatar, error)
+
+	// DeleteAvatar 清除用户头像并删除上传的头像文件
+	DeleteAvatar(ctx context.Context, userID int64) error
+
+	// RemoveAvatarFiles 删除用户当前头像的文件（不修改用户，如匿名化之后删除个人数据；失败只记录日志）
+	RemoveAvatarFiles(ctx context.Context, user repository.User)
+}
+
+// avatarService 头像业务逻辑实现
+type avatarService struct {
+	users  UserService
+	store  storage.BlobStore
+	config AvatarConfig
+}
+
+// NewAvatarService 创建头像服务实例
+func NewAvatarService(users UserService, store storage.BlobStore, config AvatarConfig) AvatarService {
+	return &avatarService{
+		users:  users,
+		store:  store,
+		config: config,
+	}
+}
+
+// UploadAvatar 上传头像
+//
+// 同时上传两次时，后完成的一次生效，先完成的一次的文件不会被删除。
+func (s *avatarService) UploadAvatar(ctx context.Context, userID int64, r io.Reader) (Avatar, error) {
+	data, err := io.ReadAll(io.LimitReader(r, s.config.MaxSize+1))
+	if err != nil {
+		return Avatar{}, fmt.Errorf("service: read avatar: %w", err)
+	}
+	if int64(len(data)) > s.config.MaxSize {
+		return Avatar{}, ErrAvatarTooLarge
+	}
+
+	img, err := imaging.Decode(data, avatarMaxPixels)
+	if err != nil {
+		if errors.Is(err, imaging.ErrTooLarge) {
+			return Avatar{}, ErrAvatarDimensions
+		}
+		slog.InfoContext(ctx, "Invalid avatar image", "user_id", userID, "error", err)
+		return Avatar{}, ErrAvatarFormat
+	}
+
+	user, err := s.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return Avatar{}, err
+	}
+
+	// 每次上传使用新的目录，旧地址在更新用户之前一直可用
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return Avatar{}, fmt.Errorf("service: generate avatar id: %w", err)
+	}
+	dir := avatarPrefix(user) + hex.EncodeToString(id)
+
+	avatar := Avatar{Sizes: make(map[int]string, len(AvatarSizes))}
+	keys := make([]string, 0, len(AvatarSizes))
+	for _, size := range AvatarSizes {
+		var buf bytes.Buffer
+		if err := imaging.EncodeJPEG(&buf, img.Square(size), avatarQuality); err != nil {
+			s.removeKeys(ctx, keys)
+			metrics.RecordUserOperation("avatar_upload", false)
+			return Avatar{}, fmt.Errorf("service: encode avatar: %w", err)
+		}
+
+		key := avatarKey(dir, size)
+		if err := s.store.Put(ctx, key, &buf, int64(buf.Len()), storage.PutOptions{
+			ContentType:  "image/jpeg",
+			CacheControl: avatarCacheControl,
+		}); err != nil {
+			s.removeKeys(ctx, keys)
+			metrics.RecordUserOperation("avatar_upload", false)
+			return Avatar{}, fmt.Errorf("service: store avatar: %w", err)
+		}
+		keys = append(keys, key)
+		avatar.Sizes[size] = s.store.URL(key)
+	}
+	avatar.URL = avatar.Sizes[AvatarSizes[0]]
+
+	// 更新用户（记录头像变更的审计日志），失败时删除刚上传的文件
+	if err := s.users.UpdateUser(ctx, UpdateUserInput{UserID: userID, Avatar: &avatar.URL}); err != nil {
+		s.removeKeys(ctx, keys)
+		metrics.RecordUserOperation("avatar_upload", false)
+		return Avatar{}, err
+	}
+
+	s.RemoveAvatarFiles(ctx, user)
+	metrics.RecordUserOperation("avatar_upload", true)
+	slog.InfoContext(ctx, "Avatar uploaded",
+		"user_id", userID,
+		"format", img.ContentType(),
+		"width", img.Bounds().Dx(),
+		"height", img.Bounds().Dy(),
+	)
+	return avatar, nil
+}
+
+// DeleteAvatar 清除用户头像
+func (s *avatarService) DeleteAvatar(ctx context.Context, userID int64) error {
+	user, err := s.users.GetUserByID(ctx, userID)
+	if err != nil {
+		return err
+	}
+	if !user.Avatar.Valid {
+		return nil
+	}
+
+	empty := ""
+	if err := s.users.UpdateUser(ctx, UpdateUserInput{UserID: userID, Avatar: &empty}); err != nil {
+		return err
+	}
+
+	s.RemoveAvatarFiles(ctx, user)
+	return nil
+}
+
+// RemoveAvatarFiles 删除用户当前头像的文件
+//
+// 只删除该用户自己上传的头像（地址属于存储且在用户的目录下）；头像为外部地址，
+// 或管理员将头像设置为其他用户上传的地址时不删除。
+func (s *avatarService) RemoveAvatarFiles(ctx context.Context, user repository.User) {
+	if !user.Avatar.Valid {
+		return
+	}
+	key, ok := s.store.KeyFromURL(user.Avatar.String)
+	if !ok || !strings.HasPrefix(key, avatarPrefix(user)) {
+		return
+	}
+
+	dir := path.Dir(key)
+	keys := make([]string, 0, len(AvatarSizes))
+	for _, size := range AvatarSizes {
+		keys = append(keys, avatarKey(dir, size))
+	}
+	s.removeKeys(ctx, keys)
+}
+
+// removeKeys 删除头像文件（用户已经更新，请求取消时也继续删除；失败只记录日志，文件成为孤立文件）
+func (s *avatarService) removeKeys(ctx context.Context, keys []string) {
+	ctx = context.WithoutCancel(ctx)
+	for _, key := range keys {
+		if err := s.store.Delete(ctx, key); err != nil {
+			slog.WarnContext(ctx, "Failed to delete avatar file", "key", key, "error", err)
+		}
+	}
+}
+
+// avatarPrefix 用户头像文件的前缀（avatars/{租户}/{用户}/）
+func avatarPrefix(user repository.User) string {
+	return "avatars/" + strconv.FormatInt(user.TenantID, 10) + "/" + strconv.FormatInt(user.ID, 10) + "/"
+}
+
+// avatarKey 一个尺寸的头像文件
+func avatarKey(dir string, size int) string {
+	return dir + "/" + strconv.Itoa(size) + ".jpg"
+}
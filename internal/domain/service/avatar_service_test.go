@@ -0,0 +1,186 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"errors"
+	"image"
+	"image/color"
+	"image/jpeg"
+	"image/png"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gin_demo/internal/repository"
+	"gin_demo/pkg/storage"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubProfileService 只实现 GetUserByID 和 UpdateUser（只更新头像）
+type stubProfileService struct {
+	UserService
+
+	users     map[int64]repository.User
+	updateErr error
+}
+
+func (s *stubProfileService) GetUserByID(_ context.Context, userID int64) (repository.User, error) {
+	user, ok := s.users[userID]
+	if !ok {
+		return repository.User{}, ErrUserNotFound
+	}
+	return user, nil
+}
+
+func (s *stubProfileService) UpdateUser(_ context.Context, input UpdateUserInput) error {
+	if s.updateErr != nil {
+		return s.updateErr
+	}
+	user := s.users[input.UserID]
+	user.Avatar = sql.NullString{String: *input.Avatar, Valid: *input.Avatar != ""}
+	s.users[input.UserID] = user
+	return nil
+}
+
+// testAvatarPNG 生成测试用的 PNG 图片
+func testAvatarPNG(t *testing.T, w, h int) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, w, h))
+	for i := range img.Pix {
+		img.Pix[i] = 200
+	}
+	img.SetRGBA(0, 0, color.RGBA{R: 255, A: 255})
+
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+// listFiles 列出目录下的所有文件（相对路径）
+func listFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	var files []string
+	filepath.WalkDir(dir, func(path string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			rel, _ := filepath.Rel(dir, path)
+			files = append(files, filepath.ToSlash(rel))
+		}
+		return nil
+	})
+	return files
+}
+
+// TestAvatarService 测试上传、替换和删除头像
+func TestAvatarService(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) (*avatarService, *stubProfileService, string) {
+		dir := t.TempDir()
+		users := &stubProfileService{users: map[int64]repository.User{
+			1: {ID: 1, TenantID: 2, Username: "alice"},
+			3: {ID: 3, TenantID: 2, Username: "bob"},
+		}}
+		svc := NewAvatarService(users, storage.NewLocalStore(dir, "/uploads"), AvatarConfig{MaxSize: 1 << 20}).(*avatarService)
+		return svc, users, dir
+	}
+
+	t.Run("上传后生成各尺寸的 JPEG 并更新用户", func(t *testing.T) {
+		svc, users, dir := setup(t)
+
+		avatar, err := svc.UploadAvatar(ctx, 1, bytes.NewReader(testAvatarPNG(t, 400, 300)))
+		require.NoError(t, err)
+		assert.Regexp(t, `^/uploads/avatars/2/1/[0-9a-f]{16}/256\.jpg$`, avatar.URL)
+		assert.Equal(t, avatar.URL, users.users[1].Avatar.String)
+		assert.Equal(t, strings.TrimSuffix(avatar.URL, "256.jpg")+"64.jpg", avatar.Sizes[64])
+
+		files := listFiles(t, dir)
+		require.Len(t, files, 2)
+		for _, size := range AvatarSizes {
+			data, err := os.ReadFile(filepath.Join(dir, strings.TrimPrefix(avatar.Sizes[size], "/uploads/")))
+			require.NoError(t, err)
+			cfg, err := jpeg.DecodeConfig(bytes.NewReader(data))
+			require.NoError(t, err)
+			assert.Equal(t, size, cfg.Width)
+			assert.Equal(t, size, cfg.Height)
+		}
+	})
+
+	t.Run("替换时删除原来的文件", func(t *testing.T) {
+		svc, _, dir := setup(t)
+
+		first, err := svc.UploadAvatar(ctx, 1, bytes.NewReader(testAvatarPNG(t, 64, 64)))
+		require.NoError(t, err)
+		second, err := svc.UploadAvatar(ctx, 1, bytes.NewReader(testAvatarPNG(t, 64, 64)))
+		require.NoError(t, err)
+		assert.NotEqual(t, first.URL, second.URL)
+
+		files := listFiles(t, dir)
+		assert.Len(t, files, 2)
+		for _, file := range files {
+			assert.True(t, strings.HasPrefix("/uploads/"+file, strings.TrimSuffix(second.URL, "256.jpg")), file)
+		}
+	})
+
+	t.Run("不删除其他用户的文件和外部地址", func(t *testing.T) {
+		svc, users, dir := setup(t)
+
+		bob, err := svc.UploadAvatar(ctx, 3, bytes.NewReader(testAvatarPNG(t, 64, 64)))
+		require.NoError(t, err)
+
+		// 管理员将 alice 的头像设置为 bob 上传的地址
+		users.users[1] = repository.User{ID: 1, TenantID: 2, Avatar: sql.NullString{String: bob.URL, Valid: true}}
+		_, err = svc.UploadAvatar(ctx, 1, bytes.NewReader(testAvatarPNG(t, 64, 64)))
+		require.NoError(t, err)
+		assert.Len(t, listFiles(t, dir), 4)
+
+		users.users[3] = repository.User{ID: 3, TenantID: 2, Avatar: sql.NullString{String: "https://example.com/bob.png", Valid: true}}
+		_, err = svc.UploadAvatar(ctx, 3, bytes.NewReader(testAvatarPNG(t, 64, 64)))
+		require.NoError(t, err)
+		assert.Len(t, listFiles(t, dir), 6)
+	})
+
+	t.Run("无效的文件", func(t *testing.T) {
+		svc, users, dir := setup(t)
+
+		_, err := svc.UploadAvatar(ctx, 1, strings.NewReader("<svg></svg>"))
+		assert.ErrorIs(t, err, ErrAvatarFormat)
+
+		_, err = svc.UploadAvatar(ctx, 1, bytes.NewReader(make([]byte, 1<<20+1)))
+		assert.ErrorIs(t, err, ErrAvatarTooLarge)
+
+		var large bytes.Buffer
+		require.NoError(t, png.Encode(&large, image.NewGray(image.Rect(0, 0, 6000, 5000))))
+		_, err = svc.UploadAvatar(ctx, 1, &large)
+		assert.ErrorIs(t, err, ErrAvatarDimensions)
+
+		assert.False(t, users.users[1].Avatar.Valid)
+		assert.Empty(t, listFiles(t, dir))
+	})
+
+	t.Run("更新用户失败时删除上传的文件", func(t *testing.T) {
+		svc, users, dir := setup(t)
+		users.updateErr = errors.New("database unavailable")
+
+		_, err := svc.UploadAvatar(ctx, 1, bytes.NewReader(testAvatarPNG(t, 64, 64)))
+		assert.Error(t, err)
+		assert.Empty(t, listFiles(t, dir))
+	})
+
+	t.Run("清除头像", func(t *testing.T) {
+		svc, users, dir := setup(t)
+
+		_, err := svc.UploadAvatar(ctx, 1, bytes.NewReader(testAvatarPNG(t, 64, 64)))
+		require.NoError(t, err)
+		require.NoError(t, svc.DeleteAvatar(ctx, 1))
+		assert.False(t, users.users[1].Avatar.Valid)
+		assert.Empty(t, listFiles(t, dir))
+
+		assert.NoError(t, svc.DeleteAvatar(ctx, 1), "没有头像时不做任何操作")
+		assert.ErrorIs(t, svc.DeleteAvatar(ctx, 9), ErrUserNotFound)
+	})
+}
@@ -0,0 +1,303 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"gin_demo/internal/repository"
+	"gin_demo/internal/response"
+	"gin_demo/pkg/auth"
+	"gin_demo/pkg/metrics"
+)
+
+var (
+	// ErrMFANotEnabled 未启用两步验证
+	ErrMFANotEnabled = response.New(response.CodeInvalidParams, "未启用两步验证")
+	// ErrMFAAlreadyEnabled 已启用两步验证
+	ErrMFAAlreadyEnabled = response.New(response.CodeAlreadyExists, "已启用两步验证")
+	// ErrMFAEnrollmentNotStarted 尚未开始绑定
+	ErrMFAEnrollmentNotStarted = response.New(response.CodeInvalidParams, "请先获取两步验证密钥")
+	// ErrInvalidMFACode 验证码错误（包括已使用过的验证码和恢复码）
+	ErrInvalidMFACode = response.New(response.CodeUnauthorized, "验证码错误")
+)
+
+// MFAConfig 两步验证配置
+type MFAConfig struct {
+	Issuer        string // TOTP 签发方（显示在验证器 App 中）
+	RecoveryCodes int    // 每次生成的恢复码数量
+}
+
+// MFAStatus 两步验证状态
+type MFAStatus struct {
+	Enabled                bool
+	EnabledAt              *time.Time
+	RecoveryCodesRemaining int64
+}
+
+// TOTPEnrollment TOTP 绑定信息
+type TOTPEnrollment struct {
+	Secret string // Base32 密钥（手动输入）
+	URI    string // otpauth:// URI（生成二维码）
+}
+
+// MFAService 两步验证业务逻辑接口
+type MFAService interface {
+	// GetStatus 查询两步验证状态
+	GetStatus(ctx context.Context, userID int64) (MFAStatus, error)
+
+	// IsEnabled 是否已启用两步验证（登录时判断是否需要第二步）
+	IsEnabled(ctx context.Context, userID int64) (bool, error)
+
+	// BeginTOTPEnrollment 开始绑定 TOTP，生成新密钥（验证通过前不生效）
+	BeginTOTPEnrollment(ctx context.Context, userID int64) (TOTPEnrollment, error)
+
+	// ConfirmTOTPEnrollment 使用验证器 App 生成的验证码确认绑定，返回恢复码明文（只显示一次）
+	ConfirmTOTPEnrollment(ctx context.Context, userID int64, code string) ([]string, error)
+
+	// Verify 校验 TOTP 验证码或恢复码（每个验证码和恢复码只能使用一次）
+	Verify(ctx context.Context, userID int64, code string) error
+
+	// RegenerateRecoveryCodes 重新生成恢复码（旧恢复码全部作废，需要验证码确认）
+	RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) ([]string, error)
+
+	// Disable 关闭两步验证（需要验证码确认）
+	Disable(ctx context.Context, userID int64, code string) error
+}
+
+// mfaService 两步验证业务逻辑实现
+type mfaService struct {
+	mfaRepo  repository.MFARepositoryInterface
+	userRepo repository.UserRepositoryInterface
+	totp     *auth.TOTP
+	config   MFAConfig
+}
+
+// NewMFAService 创建两步验证服务实例
+func NewMFAService(mfaRepo repository.MFARepositoryInterface, userRepo repository.UserRepositoryInterface, config MFAConfig) MFAService {
+	if config.RecoveryCodes <= 0 {
+		config.RecoveryCodes = 10
+	}
+	return &mfaService{
+		mfaRepo:  mfaRepo,
+		userRepo: userRepo,
+		totp:     auth.NewTOTP(config.Issuer),
+		config:   config,
+	}
+}
+
+// GetStatus 查询两步验证状态
+func (s *mfaService) GetStatus(ctx context.Context, userID int64) (MFAStatus, error) {
+	var status MFAStatus
+
+	mfa, err := s.getEnabledMFA(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrMFANotEnabled) {
+			return status, nil
+		}
+		return status, err
+	}
+
+	remaining, err := s.mfaRepo.CountRecoveryCodes(ctx, userID)
+	if err != nil {
+		return status, fmt.Errorf("service: count recovery codes: %w", err)
+	}
+
+	status.Enabled = true
+	status.RecoveryCodesRemaining = remaining
+	if mfa.EnabledAt.Valid {
+		status.EnabledAt = &mfa.EnabledAt.Time
+	}
+	return status, nil
+}
+
+// IsEnabled 是否已启用两步验证
+func (s *mfaService) IsEnabled(ctx context.Context, userID int64) (bool, error) {
+	_, err := s.getEnabledMFA(ctx, userID)
+	if err != nil {
+		if errors.Is(err, ErrMFANotEnabled) {
+			return false, nil
+		}
+		return false, err
+	}
+	return true, nil
+}
+
+// BeginTOTPEnrollment 开始绑定 TOTP
+func (s *mfaService) BeginTOTPEnrollment(ctx context.Context, userID int64) (TOTPEnrollment, error) {
+	var enrollment TOTPEnrollment
+
+	// 1. 已启用时不允许直接覆盖（需要先关闭，避免绕过验证码更换密钥）
+	enabled, err := s.IsEnabled(ctx, userID)
+	if err != nil {
+		return enrollment, err
+	}
+	if enabled {
+		return enrollment, ErrMFAAlreadyEnabled
+	}
+
+	// 2. 获取用户（otpauth URI 中显示邮箱）
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return enrollment, ErrUserNotFound
+		}
+		return enrollment, fmt.Errorf("service: get user: %w", err)
+	}
+
+	// 3. 生成并保存密钥（未启用状态）
+	secret, err := s.totp.GenerateSecret()
+	if err != nil {
+		return enrollment, fmt.Errorf("service: generate totp secret: %w", err)
+	}
+	if err := s.mfaRepo.SaveTOTPSecret(ctx, userID, secret); err != nil {
+		return enrollment, fmt.Errorf("service: save totp secret: %w", err)
+	}
+
+	enrollment.Secret = secret
+	enrollment.URI = s.totp.URI(user.Email, secret)
+	return enrollment, nil
+}
+
+// ConfirmTOTPEnrollment 确认绑定 TOTP
+func (s *mfaService) ConfirmTOTPEnrollment(ctx context.Context, userID int64, code string) ([]string, error) {
+	// 1. 获取待绑定的密钥
+	mfa, err := s.mfaRepo.GetUserMFA(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrMFAEnrollmentNotStarted
+		}
+		return nil, fmt.Errorf("service: get mfa: %w", err)
+	}
+	if mfa.Enabled {
+		return nil, ErrMFAAlreadyEnabled
+	}
+
+	// 2. 校验验证码（证明验证器 App 已正确保存密钥）
+	step, ok := s.totp.Validate(mfa.TotpSecret, code, time.Now())
+	if !ok {
+		return nil, ErrInvalidMFACode
+	}
+
+	// 3. 生成恢复码并启用
+	codes, hashes, err := s.generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.EnableMFA(ctx, userID, step, hashes); err != nil {
+		metrics.RecordUserOperation("mfa_enable", false)
+		return nil, fmt.Errorf("service: enable mfa: %w", err)
+	}
+
+	slog.InfoContext(ctx, "MFA enabled", "user_id", userID)
+	metrics.RecordUserOperation("mfa_enable", true)
+
+	return codes, nil
+}
+
+// Verify 校验 TOTP 验证码或恢复码
+func (s *mfaService) Verify(ctx context.Context, userID int64, code string) error {
+	mfa, err := s.getEnabledMFA(ctx, userID)
+	if err != nil {
+		return err
+	}
+
+	// 1. TOTP 验证码（时间步只能递增，同一验证码不能重放）
+	if step, ok := s.totp.Validate(mfa.TotpSecret, code, time.Now()); ok {
+		fresh, err := s.mfaRepo.UseTOTPStep(ctx, userID, step)
+		if err != nil {
+			return fmt.Errorf("service: use totp step: %w", err)
+		}
+		if !fresh {
+			slog.WarnContext(ctx, "MFA verification failed: totp code replayed", "user_id", userID)
+			return ErrInvalidMFACode
+		}
+		return nil
+	}
+
+	// 2. 恢复码
+	used, err := s.mfaRepo.UseRecoveryCode(ctx, userID, auth.HashRecoveryCode(code))
+	if err != nil {
+		return fmt.Errorf("service: use recovery code: %w", err)
+	}
+	if !used {
+		return ErrInvalidMFACode
+	}
+
+	remaining, err := s.mfaRepo.CountRecoveryCodes(ctx, userID)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to count recovery codes", "user_id", userID, "error", err)
+	}
+	slog.WarnContext(ctx, "MFA recovery code used",
+		"user_id", userID,
+		"recovery_codes_remaining", remaining,
+	)
+	return nil
+}
+
+// RegenerateRecoveryCodes 重新生成恢复码
+func (s *mfaService) RegenerateRecoveryCodes(ctx context.Context, userID int64, code string) ([]string, error) {
+	if err := s.Verify(ctx, userID, code); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := s.generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+	if err := s.mfaRepo.ReplaceRecoveryCodes(ctx, userID, hashes); err != nil {
+		return nil, fmt.Errorf("service: replace recovery codes: %w", err)
+	}
+
+	slog.InfoContext(ctx, "MFA recovery codes regenerated", "user_id", userID)
+	return codes, nil
+}
+
+// Disable 关闭两步验证
+func (s *mfaService) Disable(ctx context.Context, userID int64, code string) error {
+	if err := s.Verify(ctx, userID, code); err != nil {
+		return err
+	}
+
+	if err := s.mfaRepo.DisableMFA(ctx, userID); err != nil {
+		metrics.RecordUserOperation("mfa_disable", false)
+		return fmt.Errorf("service: disable mfa: %w", err)
+	}
+
+	slog.InfoContext(ctx, "MFA disabled", "user_id", userID)
+	metrics.RecordUserOperation("mfa_disable", true)
+
+	return nil
+}
+
+// getEnabledMFA 获取已启用的两步验证配置（未绑定或未完成绑定时返回 ErrMFANotEnabled）
+func (s *mfaService) getEnabledMFA(ctx context.Context, userID int64) (repository.UserMfa, error) {
+	mfa, err := s.mfaRepo.GetUserMFA(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return mfa, ErrMFANotEnabled
+		}
+		return mfa, fmt.Errorf("service: get mfa: %w", err)
+	}
+	if !mfa.Enabled {
+		return mfa, ErrMFANotEnabled
+	}
+	return mfa, nil
+}
+
+// generateRecoveryCodes 生成恢复码及其哈希
+func (s *mfaService) generateRecoveryCodes() ([]string, []string, error) {
+	codes, err := auth.GenerateRecoveryCodes(s.config.RecoveryCodes)
+	if err != nil {
+		return nil, nil, fmt.Errorf("service: generate recovery codes: %w", err)
+	}
+
+	hashes := make([]string, 0, len(codes))
+	for _, code := range codes {
+		hashes = append(hashes, auth.HashRecoveryCode(code))
+	}
+	return codes, hashes, nil
+}
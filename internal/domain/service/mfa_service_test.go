@@ -0,0 +1,180 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"gin_demo/internal/repository"
+	"gin_demo/pkg/auth"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockMFARepository 是 MFARepository 的 mock 实现
+type MockMFARepository struct {
+	mock.Mock
+}
+
+func (m *MockMFARepository) GetUserMFA(ctx context.Context, userID int64) (repository.UserMfa, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(repository.UserMfa), args.Error(1)
+}
+
+func (m *MockMFARepository) CountRecoveryCodes(ctx context.Context, userID int64) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockMFARepository) SaveTOTPSecret(ctx context.Context, userID int64, secret string) error {
+	args := m.Called(ctx, userID, secret)
+	return args.Error(0)
+}
+
+func (m *MockMFARepository) EnableMFA(ctx context.Context, userID, step int64, recoveryCodeHashes []string) error {
+	args := m.Called(ctx, userID, step, recoveryCodeHashes)
+	return args.Error(0)
+}
+
+func (m *MockMFARepository) ReplaceRecoveryCodes(ctx context.Context, userID int64, recoveryCodeHashes []string) error {
+	args := m.Called(ctx, userID, recoveryCodeHashes)
+	return args.Error(0)
+}
+
+func (m *MockMFARepository) UseTOTPStep(ctx context.Context, userID, step int64) (bool, error) {
+	args := m.Called(ctx, userID, step)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMFARepository) UseRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error) {
+	args := m.Called(ctx, userID, codeHash)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockMFARepository) DisableMFA(ctx context.Context, userID int64) error {
+	args := m.Called(ctx, userID)
+	return args.Error(0)
+}
+
+// TestMFAService_Enrollment 测试 TOTP 绑定流程
+func TestMFAService_Enrollment(t *testing.T) {
+	ctx := context.Background()
+	totp := auth.NewTOTP("test")
+
+	t.Run("绑定并确认", func(t *testing.T) {
+		mfaRepo := new(MockMFARepository)
+		userRepo := new(MockUserRepository)
+		service := NewMFAService(mfaRepo, userRepo, MFAConfig{Issuer: "test", RecoveryCodes: 5})
+
+		mfaRepo.On("GetUserMFA", ctx, int64(1)).Return(repository.UserMfa{}, sql.ErrNoRows).Once()
+		userRepo.On("GetUserByID", ctx, int64(1)).Return(repository.User{ID: 1, Email: "test@example.com"}, nil)
+		mfaRepo.On("SaveTOTPSecret", ctx, int64(1), mock.AnythingOfType("string")).Return(nil)
+
+		enrollment, err := service.BeginTOTPEnrollment(ctx, 1)
+		require.NoError(t, err)
+		assert.NotEmpty(t, enrollment.Secret)
+		assert.Contains(t, enrollment.URI, "otpauth://totp/")
+
+		// 确认绑定：使用 App 生成的验证码
+		mfaRepo.On("GetUserMFA", ctx, int64(1)).Return(repository.UserMfa{UserID: 1, TotpSecret: enrollment.Secret}, nil).Once()
+		mfaRepo.On("EnableMFA", ctx, int64(1), mock.AnythingOfType("int64"), mock.AnythingOfType("[]string")).Return(nil)
+
+		code, err := totp.Code(enrollment.Secret, time.Now())
+		require.NoError(t, err)
+		codes, err := service.ConfirmTOTPEnrollment(ctx, 1, code)
+		require.NoError(t, err)
+		assert.Len(t, codes, 5)
+
+		// 数据库只保存恢复码哈希
+		hashes := mfaRepo.Calls[len(mfaRepo.Calls)-1].Arguments.Get(3).([]string)
+		assert.Equal(t, auth.HashRecoveryCode(codes[0]), hashes[0])
+
+		mfaRepo.AssertExpectations(t)
+		userRepo.AssertExpectations(t)
+	})
+
+	t.Run("已启用时不能重新绑定", func(t *testing.T) {
+		mfaRepo := new(MockMFARepository)
+		service := NewMFAService(mfaRepo, new(MockUserRepository), MFAConfig{Issuer: "test"})
+
+		mfaRepo.On("GetUserMFA", ctx, int64(1)).Return(repository.UserMfa{UserID: 1, Enabled: true}, nil)
+
+		_, err := service.BeginTOTPEnrollment(ctx, 1)
+		assert.ErrorIs(t, err, ErrMFAAlreadyEnabled)
+		mfaRepo.AssertNotCalled(t, "SaveTOTPSecret", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("确认时验证码错误", func(t *testing.T) {
+		mfaRepo := new(MockMFARepository)
+		service := NewMFAService(mfaRepo, new(MockUserRepository), MFAConfig{Issuer: "test"})
+
+		secret, _ := totp.GenerateSecret()
+		mfaRepo.On("GetUserMFA", ctx, int64(1)).Return(repository.UserMfa{UserID: 1, TotpSecret: secret}, nil)
+
+		_, err := service.ConfirmTOTPEnrollment(ctx, 1, "abcdef")
+		assert.ErrorIs(t, err, ErrInvalidMFACode)
+		mfaRepo.AssertNotCalled(t, "EnableMFA", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// TestMFAService_Verify 测试验证码校验
+func TestMFAService_Verify(t *testing.T) {
+	ctx := context.Background()
+	totp := auth.NewTOTP("test")
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+	enabled := repository.UserMfa{UserID: 1, TotpSecret: secret, Enabled: true}
+
+	t.Run("TOTP 验证码", func(t *testing.T) {
+		mfaRepo := new(MockMFARepository)
+		service := NewMFAService(mfaRepo, new(MockUserRepository), MFAConfig{Issuer: "test"})
+
+		now := time.Now()
+		code, _ := totp.Code(secret, now)
+		mfaRepo.On("GetUserMFA", ctx, int64(1)).Return(enabled, nil)
+		mfaRepo.On("UseTOTPStep", ctx, int64(1), mock.AnythingOfType("int64")).Return(true, nil).Once()
+
+		assert.NoError(t, service.Verify(ctx, 1, code))
+
+		// 同一时间步再次使用视为重放
+		mfaRepo.On("UseTOTPStep", ctx, int64(1), mock.AnythingOfType("int64")).Return(false, nil).Once()
+		assert.ErrorIs(t, service.Verify(ctx, 1, code), ErrInvalidMFACode)
+
+		mfaRepo.AssertExpectations(t)
+	})
+
+	t.Run("恢复码", func(t *testing.T) {
+		mfaRepo := new(MockMFARepository)
+		service := NewMFAService(mfaRepo, new(MockUserRepository), MFAConfig{Issuer: "test"})
+
+		mfaRepo.On("GetUserMFA", ctx, int64(1)).Return(enabled, nil)
+		mfaRepo.On("UseRecoveryCode", ctx, int64(1), auth.HashRecoveryCode("abcde-12345")).Return(true, nil)
+		mfaRepo.On("CountRecoveryCodes", ctx, int64(1)).Return(int64(9), nil)
+
+		// 大小写和分隔符不影响匹配
+		assert.NoError(t, service.Verify(ctx, 1, "ABCDE 12345"))
+		mfaRepo.AssertExpectations(t)
+	})
+
+	t.Run("无效恢复码", func(t *testing.T) {
+		mfaRepo := new(MockMFARepository)
+		service := NewMFAService(mfaRepo, new(MockUserRepository), MFAConfig{Issuer: "test"})
+
+		mfaRepo.On("GetUserMFA", ctx, int64(1)).Return(enabled, nil)
+		mfaRepo.On("UseRecoveryCode", ctx, int64(1), mock.AnythingOfType("string")).Return(false, nil)
+
+		assert.ErrorIs(t, service.Verify(ctx, 1, "xxxxx-yyyyy"), ErrInvalidMFACode)
+	})
+
+	t.Run("未启用", func(t *testing.T) {
+		mfaRepo := new(MockMFARepository)
+		service := NewMFAService(mfaRepo, new(MockUserRepository), MFAConfig{Issuer: "test"})
+
+		mfaRepo.On("GetUserMFA", ctx, int64(1)).Return(repository.UserMfa{UserID: 1, TotpSecret: secret}, nil)
+
+		assert.ErrorIs(t, service.Verify(ctx, 1, "123456"), ErrMFANotEnabled)
+	})
+}
@@ -0,0 +1,411 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"strings"
+	"time"
+	"unicode"
+
+	"gin_demo/internal/repository"
+	"gin_demo/internal/response"
+	"gin_demo/pkg/auth"
+	"gin_demo/pkg/metrics"
+)
+
+var (
+	// ErrOAuthProviderNotFound 未配置该第三方登录提供方
+	ErrOAuthProviderNotFound = response.New(response.CodeNotFound, "不支持的第三方登录方式")
+	// ErrOAuthFailed 第三方登录失败（state 无效或已过期、授权码无效、ID Token 校验失败，不区分原因）
+	ErrOAuthFailed = response.New(response.CodeUnauthorized, "第三方登录失败，请重新发起授权")
+	// ErrOAuthEmailRequired 第三方账户没有提供已验证的邮箱，无法创建账户
+	ErrOAuthEmailRequired = response.New(response.CodeInvalidParams, "第三方账户没有已验证的邮箱，无法注册")
+	// ErrOAuthEmailRegistered 邮箱已被其他账户使用（不自动关联，避免通过第三方账户接管已有账户）
+	ErrOAuthEmailRegistered = response.New(response.CodeAlreadyExists, "该邮箱已注册，请使用密码登录后在账户设置中关联")
+	// ErrIdentityAlreadyLinked 第三方账户已关联其他用户
+	ErrIdentityAlreadyLinked = response.New(response.CodeAlreadyExists, "该第三方账户已关联其他用户")
+	// ErrProviderAlreadyLinked 用户已关联该提供方的其他账户
+	ErrProviderAlreadyLinked = response.New(response.CodeAlreadyExists, "已关联该提供方的其他账户，请先解除关联")
+	// ErrIdentityNotFound 用户没有关联该提供方
+	ErrIdentityNotFound = response.New(response.CodeNotFound, "未关联该第三方账户")
+	// ErrLastLoginMethod 解除关联后将无法登录
+	ErrLastLoginMethod = response.New(response.CodeInvalidParams, "这是唯一的登录方式，请先设置密码再解除关联")
+)
+
+// Identity 已关联的第三方账户
+type Identity struct {
+	Provider    string
+	Email       string
+	LastLoginAt *time.Time
+	CreatedAt   time.Time
+}
+
+// OAuthService 第三方登录业务逻辑接口
+type OAuthService interface {
+	// Providers 已配置的提供方名称
+	Providers() []string
+
+	// AuthorizationURL 生成提供方授权地址（linkUserID 非 0 表示为该用户关联第三方账户）
+	AuthorizationURL(ctx context.Context, provider string, linkUserID int64) (string, error)
+
+	// Login 处理登录回调：已关联的账户直接登录，未关联时使用第三方邮箱注册无密码账户
+	Login(ctx context.Context, provider, state, code string) (repository.User, error)
+
+	// Link 处理关联回调：将第三方账户关联到当前用户
+	Link(ctx context.Context, userID int64, provider, state, code string) (Identity, error)
+
+	// ListIdentities 列出用户关联的第三方账户
+	ListIdentities(ctx context.Context, userID int64) ([]Identity, error)
+
+	// Unlink 解除关联（无密码且只有这一个关联时拒绝）
+	Unlink(ctx context.Context, userID int64, provider string) error
+}
+
+// oauthService 第三方登录业务逻辑实现
+type oauthService struct {
+	oauth        *auth.OAuthManager
+	identityRepo repository.IdentityRepositoryInterface
+	userRepo     repository.UserRepositoryInterface
+	userService  UserService
+}
+
+// NewOAuthService 创建第三方登录服务实例
+func NewOAuthService(
+	oauth *auth.OAuthManager,
+	identityRepo repository.IdentityRepositoryInterface,
+	userRepo repository.UserRepositoryInterface,
+	userService UserService,
+) OAuthService {
+	return &oauthService{
+		oauth:        oauth,
+		identityRepo: identityRepo,
+		userRepo:     userRepo,
+		userService:  userService,
+	}
+}
+
+// Providers 已配置的提供方名称
+func (s *oauthService) Providers() []string {
+	return s.oauth.Providers()
+}
+
+// AuthorizationURL 生成提供方授权地址
+func (s *oauthService) AuthorizationURL(ctx context.Context, provider string, linkUserID int64) (string, error) {
+	authURL, err := s.oauth.AuthorizationURL(ctx, provider, linkUserID)
+	if err != nil {
+		if errors.Is(err, auth.ErrOAuthProviderNotFound) {
+			return "", ErrOAuthProviderNotFound
+		}
+		slog.ErrorContext(ctx, "Failed to build oauth authorization url", "provider", provider, "error", err)
+		return "", fmt.Errorf("service: oauth authorization url: %w", err)
+	}
+	return authURL, nil
+}
+
+// Login 处理登录回调
+func (s *oauthService) Login(ctx context.Context, provider, state, code string) (repository.User, error) {
+	// 1. 校验 state 并获取第三方账户信息
+	external, record, err := s.complete(ctx, provider, state, code)
+	if err != nil {
+		return repository.User{}, err
+	}
+	if record.LinkUserID != 0 {
+		// 关联流程的 state 不能用于登录
+		slog.WarnContext(ctx, "OAuth login rejected: state belongs to link flow", "provider", provider)
+		metrics.AuthFailures.WithLabelValues("oauth").Inc()
+		return repository.User{}, ErrOAuthFailed
+	}
+
+	// 2. 已关联：直接登录
+	identity, err := s.identityRepo.GetIdentity(ctx, provider, external.Subject)
+	if err == nil {
+		return s.loginLinked(ctx, identity)
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		slog.ErrorContext(ctx, "Failed to get identity", "provider", provider, "error", err)
+		return repository.User{}, fmt.Errorf("service: get identity: %w", err)
+	}
+
+	// 3. 未关联：使用第三方已验证的邮箱注册新账户
+	return s.registerExternal(ctx, external)
+}
+
+// loginLinked 通过已关联的第三方账户登录
+func (s *oauthService) loginLinked(ctx context.Context, identity repository.Identity) (repository.User, error) {
+	user, err := s.userRepo.GetUserByID(ctx, identity.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			// 用户已被禁用
+			slog.WarnContext(ctx, "OAuth login failed: user not found", "user_id", identity.UserID, "provider", identity.Provider)
+			metrics.RecordUserLogin(false)
+			return repository.User{}, ErrUserNotFound
+		}
+		return repository.User{}, fmt.Errorf("service: get user: %w", err)
+	}
+	if user.Status == repository.UserStatusPending {
+		metrics.RecordUserLogin(false)
+		return repository.User{}, ErrEmailNotVerified
+	}
+
+	if err := s.identityRepo.TouchIdentity(ctx, identity.ID, time.Now()); err != nil {
+		// 只影响展示，不影响登录
+		slog.WarnContext(ctx, "Failed to touch identity", "identity_id", identity.ID, "error", err)
+	}
+
+	slog.InfoContext(ctx, "User logged in with oauth",
+		"user_id", user.ID,
+		"provider", identity.Provider,
+	)
+	metrics.RecordUserLogin(true)
+	return user, nil
+}
+
+// registerExternal 为未关联的第三方账户注册无密码账户并建立关联
+func (s *oauthService) registerExternal(ctx context.Context, external auth.ExternalIdentity) (repository.User, error) {
+	if external.Email == "" || !external.EmailVerified {
+		slog.WarnContext(ctx, "OAuth registration rejected: no verified email", "provider", external.Provider)
+		return repository.User{}, ErrOAuthEmailRequired
+	}
+
+	// 邮箱已注册时不自动关联：提供方对邮箱的验证不等于用户拥有本站的这个账户
+	if existing, err := s.userRepo.GetUserByEmail(ctx, external.Email); err == nil && existing.ID > 0 {
+		slog.WarnContext(ctx, "OAuth registration rejected: email already registered",
+			"provider", external.Provider,
+			"existing_user_id", existing.ID,
+		)
+		return repository.User{}, ErrOAuthEmailRegistered
+	}
+
+	username, err := s.availableUsername(ctx, external)
+	if err != nil {
+		return repository.User{}, err
+	}
+
+	user, err := s.userService.Register(ctx, RegisterInput{
+		Username:      username,
+		Email:         external.Email,
+		Passwordless:  true,
+		EmailVerified: true,
+	})
+	if err != nil {
+		return repository.User{}, err
+	}
+
+	if _, err := s.identityRepo.CreateIdentity(ctx, repository.CreateIdentityParams{
+		UserID:   user.ID,
+		Provider: external.Provider,
+		Subject:  external.Subject,
+		Email:    external.Email,
+	}); err != nil {
+		slog.ErrorContext(ctx, "Failed to link identity after registration",
+			"user_id", user.ID,
+			"provider", external.Provider,
+			"error", err,
+		)
+		return repository.User{}, fmt.Errorf("service: create identity: %w", err)
+	}
+
+	slog.InfoContext(ctx, "User registered with oauth",
+		"user_id", user.ID,
+		"provider", external.Provider,
+	)
+	metrics.RecordUserLogin(true)
+	return user, nil
+}
+
+// availableUsername 根据第三方账户信息生成未被占用的用户名
+//
+// 优先使用提供方的用户名，其次是邮箱的本地部分；被占用时追加随机后缀。
+func (s *oauthService) availableUsername(ctx context.Context, external auth.ExternalIdentity) (string, error) {
+	base := sanitizeUsername(external.Username)
+	if base == "" {
+		base = sanitizeUsername(strings.SplitN(external.Email, "@", 2)[0])
+	}
+	if len(base) < 3 {
+		base = external.Provider + "_" + base
+	}
+
+	candidate := base
+	for range 5 {
+		_, err := s.userRepo.GetUserByUsername(ctx, candidate)
+		if errors.Is(err, sql.ErrNoRows) {
+			return candidate, nil
+		}
+		if err != nil {
+			return "", fmt.Errorf("service: check username: %w", err)
+		}
+		candidate = fmt.Sprintf("%s_%04d", base, rand.IntN(10000))
+	}
+
+	slog.WarnContext(ctx, "Failed to find available username", "base", base)
+	return "", ErrUserExists
+}
+
+// sanitizeUsername 只保留字母、数字、-、_ 和 .，并截断到 40 个字符（为随机后缀留出空间）
+func sanitizeUsername(s string) string {
+	var b strings.Builder
+	for _, r := range s {
+		switch {
+		case r < unicode.MaxASCII && (unicode.IsLetter(r) || unicode.IsDigit(r)), r == '-', r == '_', r == '.':
+			b.WriteRune(r)
+		case unicode.IsSpace(r):
+			b.WriteRune('_')
+		}
+		if b.Len() >= 40 {
+			break
+		}
+	}
+	return b.String()
+}
+
+// Link 处理关联回调
+func (s *oauthService) Link(ctx context.Context, userID int64, provider, state, code string) (Identity, error) {
+	// 1. 校验 state，且必须是当前用户发起的关联请求
+	external, record, err := s.complete(ctx, provider, state, code)
+	if err != nil {
+		return Identity{}, err
+	}
+	if record.LinkUserID != userID {
+		slog.WarnContext(ctx, "OAuth link rejected: state belongs to another flow",
+			"user_id", userID,
+			"provider", provider,
+		)
+		metrics.AuthFailures.WithLabelValues("oauth").Inc()
+		return Identity{}, ErrOAuthFailed
+	}
+
+	// 2. 第三方账户不能同时关联多个用户
+	existing, err := s.identityRepo.GetIdentity(ctx, provider, external.Subject)
+	if err == nil {
+		if existing.UserID != userID {
+			slog.WarnContext(ctx, "OAuth link rejected: identity linked to another user",
+				"user_id", userID,
+				"provider", provider,
+			)
+			return Identity{}, ErrIdentityAlreadyLinked
+		}
+		return toIdentity(existing), nil
+	}
+	if !errors.Is(err, sql.ErrNoRows) {
+		return Identity{}, fmt.Errorf("service: get identity: %w", err)
+	}
+
+	// 3. 每个提供方只能关联一个账户
+	identities, err := s.identityRepo.ListUserIdentities(ctx, userID)
+	if err != nil {
+		return Identity{}, fmt.Errorf("service: list identities: %w", err)
+	}
+	for _, identity := range identities {
+		if identity.Provider == provider {
+			return Identity{}, ErrProviderAlreadyLinked
+		}
+	}
+
+	// 4. 建立关联
+	now := time.Now()
+	if _, err := s.identityRepo.CreateIdentity(ctx, repository.CreateIdentityParams{
+		UserID:   userID,
+		Provider: provider,
+		Subject:  external.Subject,
+		Email:    external.Email,
+	}); err != nil {
+		slog.ErrorContext(ctx, "Failed to create identity", "user_id", userID, "provider", provider, "error", err)
+		return Identity{}, fmt.Errorf("service: create identity: %w", err)
+	}
+
+	slog.InfoContext(ctx, "OAuth identity linked", "user_id", userID, "provider", provider)
+	return Identity{
+		Provider:  provider,
+		Email:     external.Email,
+		CreatedAt: now,
+	}, nil
+}
+
+// ListIdentities 列出用户关联的第三方账户
+func (s *oauthService) ListIdentities(ctx context.Context, userID int64) ([]Identity, error) {
+	identities, err := s.identityRepo.ListUserIdentities(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("service: list identities: %w", err)
+	}
+
+	result := make([]Identity, 0, len(identities))
+	for _, identity := range identities {
+		result = append(result, toIdentity(identity))
+	}
+	return result, nil
+}
+
+// Unlink 解除关联
+func (s *oauthService) Unlink(ctx context.Context, userID int64, provider string) error {
+	// 1. 无密码账户至少保留一个关联，否则将无法登录
+	user, err := s.userRepo.GetUserByID(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("service: get user: %w", err)
+	}
+	// GetUserByID 不加载密码
+	withPassword, err := s.userRepo.GetUserByEmail(ctx, user.Email)
+	if err != nil {
+		return fmt.Errorf("service: get user: %w", err)
+	}
+	if withPassword.Password == "" {
+		count, err := s.identityRepo.CountUserIdentities(ctx, userID)
+		if err != nil {
+			return fmt.Errorf("service: count identities: %w", err)
+		}
+		if count <= 1 {
+			return ErrLastLoginMethod
+		}
+	}
+
+	// 2. 解除关联
+	deleted, err := s.identityRepo.DeleteIdentity(ctx, userID, provider)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to delete identity", "user_id", userID, "provider", provider, "error", err)
+		return fmt.Errorf("service: delete identity: %w", err)
+	}
+	if !deleted {
+		return ErrIdentityNotFound
+	}
+
+	slog.InfoContext(ctx, "OAuth identity unlinked", "user_id", userID, "provider", provider)
+	return nil
+}
+
+// complete 校验回调并获取第三方账户信息（协议层错误统一转换为 ErrOAuthFailed）
+func (s *oauthService) complete(ctx context.Context, provider, state, code string) (auth.ExternalIdentity, auth.OAuthState, error) {
+	external, record, err := s.oauth.Complete(ctx, provider, state, code)
+	if err == nil {
+		return external, record, nil
+	}
+
+	switch {
+	case errors.Is(err, auth.ErrOAuthProviderNotFound):
+		return external, record, ErrOAuthProviderNotFound
+	case errors.Is(err, auth.ErrOAuthStateInvalid),
+		errors.Is(err, auth.ErrOAuthExchangeFailed),
+		errors.Is(err, auth.ErrIDTokenInvalid):
+		slog.WarnContext(ctx, "OAuth callback rejected", "provider", provider, "error", err)
+		metrics.AuthFailures.WithLabelValues("oauth").Inc()
+		return external, record, ErrOAuthFailed
+	default:
+		slog.ErrorContext(ctx, "OAuth callback failed", "provider", provider, "error", err)
+		return external, record, fmt.Errorf("service: oauth callback: %w", err)
+	}
+}
+
+// toIdentity 转换为业务层的关联信息
+func toIdentity(identity repository.Identity) Identity {
+	return Identity{
+		Provider:    identity.Provider,
+		Email:       identity.Email,
+		LastLoginAt: nullTimePtr(identity.LastLoginAt),
+		CreatedAt:   identity.CreatedAt,
+	}
+}
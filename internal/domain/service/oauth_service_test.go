@@ -0,0 +1,286 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"gin_demo/internal/repository"
+	"gin_demo/pkg/audit"
+	"gin_demo/pkg/auth"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockIdentityRepository 是 IdentityRepository 的 mock 实现
+type MockIdentityRepository struct {
+	mock.Mock
+}
+
+func (m *MockIdentityRepository) GetIdentity(ctx context.Context, provider, subject string) (repository.Identity, error) {
+	args := m.Called(ctx, provider, subject)
+	return args.Get(0).(repository.Identity), args.Error(1)
+}
+
+func (m *MockIdentityRepository) ListUserIdentities(ctx context.Context, userID int64) ([]repository.Identity, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]repository.Identity), args.Error(1)
+}
+
+func (m *MockIdentityRepository) CountUserIdentities(ctx context.Context, userID int64) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockIdentityRepository) CreateIdentity(ctx context.Context, params repository.CreateIdentityParams) (int64, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockIdentityRepository) TouchIdentity(ctx context.Context, identityID int64, now time.Time) error {
+	args := m.Called(ctx, identityID, now)
+	return args.Error(0)
+}
+
+func (m *MockIdentityRepository) DeleteIdentity(ctx context.Context, userID int64, provider string) (bool, error) {
+	args := m.Called(ctx, userID, provider)
+	return args.Bool(0), args.Error(1)
+}
+
+// stubGitHubUser 桩提供方返回的用户
+type stubGitHubUser struct {
+	ID       int64
+	Login    string
+	Email    string
+	Verified bool
+}
+
+// newStubOAuthManager 创建指向本地桩提供方（GitHub 风格）的第三方登录管理器
+func newStubOAuthManager(t *testing.T, user *stubGitHubUser) *auth.OAuthManager {
+	t.Helper()
+
+	writeJSON := func(w http.ResponseWriter, v any) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(v)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		if r.FormValue("code") != "valid-code" || r.FormValue("code_verifier") == "" {
+			writeJSON(w, map[string]string{"error": "bad_verification_code"})
+			return
+		}
+		writeJSON(w, map[string]string{"access_token": "gho_token", "token_type": "bearer"})
+	})
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, map[string]any{"id": user.ID, "login": user.Login})
+	})
+	mux.HandleFunc("/user/emails", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, []map[string]any{{"email": user.Email, "primary": true, "verified": user.Verified}})
+	})
+	server := httptest.NewServer(mux)
+	t.Cleanup(server.Close)
+
+	provider, err := auth.NewOAuthProvider(auth.OAuthProviderConfig{
+		Name:         "github",
+		Type:         auth.OAuthProviderGitHub,
+		ClientID:     "client-1",
+		ClientSecret: "client-secret",
+		RedirectURL:  "http://localhost:3000/oauth/callback",
+		AuthURL:      server.URL + "/login/oauth/authorize",
+		TokenURL:     server.URL + "/login/oauth/access_token",
+		UserInfoURL:  server.URL + "/user",
+	}, server.Client())
+	require.NoError(t, err)
+
+	return auth.NewOAuthManager(auth.NewMemoryOAuthStateStore(), 10*time.Minute, provider)
+}
+
+// startOAuth 发起授权并返回 state
+func startOAuth(t *testing.T, svc OAuthService, linkUserID int64) string {
+	t.Helper()
+
+	authURL, err := svc.AuthorizationURL(context.Background(), "github", linkUserID)
+	require.NoError(t, err)
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+	return u.Query().Get("state")
+}
+
+// setupOAuthService 创建使用桩提供方和 mock 仓库的第三方登录服务
+func setupOAuthService(t *testing.T, user *stubGitHubUser) (OAuthService, *MockIdentityRepository, *MockUserRepository) {
+	identityRepo := new(MockIdentityRepository)
+	userRepo := new(MockUserRepository)
+	svc := NewOAuthService(newStubOAuthManager(t, user), identityRepo, userRepo, NewUserService(userRepo, testHasher, testPasswords, audit.NopRecorder{}))
+	return svc, identityRepo, userRepo
+}
+
+// TestOAuthService_Login 测试第三方登录
+func TestOAuthService_Login(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("已关联的账户直接登录", func(t *testing.T) {
+		svc, identityRepo, userRepo := setupOAuthService(t, &stubGitHubUser{ID: 583231, Login: "octocat", Email: "octocat@github.com", Verified: true})
+		identityRepo.On("GetIdentity", mock.Anything, "github", "583231").Return(repository.Identity{ID: 9, UserID: 1, Provider: "github"}, nil)
+		identityRepo.On("TouchIdentity", mock.Anything, int64(9), mock.Anything).Return(nil)
+		userRepo.On("GetUserByID", mock.Anything, int64(1)).Return(repository.User{ID: 1, Status: repository.UserStatusActive}, nil)
+
+		user, err := svc.Login(ctx, "github", startOAuth(t, svc, 0), "valid-code")
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), user.ID)
+		identityRepo.AssertExpectations(t)
+	})
+
+	t.Run("未关联时注册无密码账户", func(t *testing.T) {
+		svc, identityRepo, userRepo := setupOAuthService(t, &stubGitHubUser{ID: 583231, Login: "octocat", Email: "octocat@github.com", Verified: true})
+		identityRepo.On("GetIdentity", mock.Anything, "github", "583231").Return(repository.Identity{}, sql.ErrNoRows)
+		userRepo.On("GetUserByEmail", mock.Anything, "octocat@github.com").Return(repository.User{}, sql.ErrNoRows)
+		// 用户名被占用时追加随机后缀
+		userRepo.On("GetUserByUsername", mock.Anything, "octocat").Return(repository.User{ID: 5}, nil)
+		userRepo.On("GetUserByUsername", mock.Anything, mock.MatchedBy(func(name string) bool {
+			return len(name) == len("octocat_0000") && name[:8] == "octocat_"
+		})).Return(repository.User{}, sql.ErrNoRows)
+		userRepo.On("CreateUser", mock.Anything, mock.MatchedBy(func(p repository.CreateUserParams) bool {
+			return p.Email == "octocat@github.com" && p.Password == "" && p.Status == repository.UserStatusActive
+		})).Return(repository.User{ID: 2, Email: "octocat@github.com", Status: repository.UserStatusActive}, nil)
+		identityRepo.On("CreateIdentity", mock.Anything, repository.CreateIdentityParams{
+			UserID:   2,
+			Provider: "github",
+			Subject:  "583231",
+			Email:    "octocat@github.com",
+		}).Return(int64(10), nil)
+
+		user, err := svc.Login(ctx, "github", startOAuth(t, svc, 0), "valid-code")
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), user.ID)
+		userRepo.AssertExpectations(t)
+		identityRepo.AssertExpectations(t)
+	})
+
+	t.Run("邮箱已注册时不自动关联", func(t *testing.T) {
+		svc, identityRepo, userRepo := setupOAuthService(t, &stubGitHubUser{ID: 583231, Login: "octocat", Email: "octocat@github.com", Verified: true})
+		identityRepo.On("GetIdentity", mock.Anything, "github", "583231").Return(repository.Identity{}, sql.ErrNoRows)
+		userRepo.On("GetUserByEmail", mock.Anything, "octocat@github.com").Return(repository.User{ID: 1}, nil)
+
+		_, err := svc.Login(ctx, "github", startOAuth(t, svc, 0), "valid-code")
+		assert.ErrorIs(t, err, ErrOAuthEmailRegistered)
+		identityRepo.AssertNotCalled(t, "CreateIdentity", mock.Anything, mock.Anything)
+	})
+
+	t.Run("邮箱未验证", func(t *testing.T) {
+		svc, identityRepo, _ := setupOAuthService(t, &stubGitHubUser{ID: 583231, Login: "octocat", Email: "octocat@github.com", Verified: false})
+		identityRepo.On("GetIdentity", mock.Anything, "github", "583231").Return(repository.Identity{}, sql.ErrNoRows)
+
+		_, err := svc.Login(ctx, "github", startOAuth(t, svc, 0), "valid-code")
+		assert.ErrorIs(t, err, ErrOAuthEmailRequired)
+	})
+
+	t.Run("授权码无效", func(t *testing.T) {
+		svc, _, _ := setupOAuthService(t, &stubGitHubUser{ID: 583231})
+
+		_, err := svc.Login(ctx, "github", startOAuth(t, svc, 0), "invalid-code")
+		assert.ErrorIs(t, err, ErrOAuthFailed)
+	})
+
+	t.Run("关联流程的 state 不能用于登录", func(t *testing.T) {
+		svc, _, _ := setupOAuthService(t, &stubGitHubUser{ID: 583231})
+
+		_, err := svc.Login(ctx, "github", startOAuth(t, svc, 1), "valid-code")
+		assert.ErrorIs(t, err, ErrOAuthFailed)
+	})
+
+	t.Run("未配置的提供方", func(t *testing.T) {
+		svc, _, _ := setupOAuthService(t, &stubGitHubUser{ID: 583231})
+
+		_, err := svc.AuthorizationURL(ctx, "unknown", 0)
+		assert.ErrorIs(t, err, ErrOAuthProviderNotFound)
+	})
+}
+
+// TestOAuthService_Link 测试关联第三方账户
+func TestOAuthService_Link(t *testing.T) {
+	ctx := context.Background()
+	external := &stubGitHubUser{ID: 583231, Login: "octocat", Email: "octocat@github.com", Verified: true}
+
+	t.Run("关联成功", func(t *testing.T) {
+		svc, identityRepo, _ := setupOAuthService(t, external)
+		identityRepo.On("GetIdentity", mock.Anything, "github", "583231").Return(repository.Identity{}, sql.ErrNoRows)
+		identityRepo.On("ListUserIdentities", mock.Anything, int64(1)).Return([]repository.Identity{}, nil)
+		identityRepo.On("CreateIdentity", mock.Anything, repository.CreateIdentityParams{
+			UserID:   1,
+			Provider: "github",
+			Subject:  "583231",
+			Email:    "octocat@github.com",
+		}).Return(int64(10), nil)
+
+		identity, err := svc.Link(ctx, 1, "github", startOAuth(t, svc, 1), "valid-code")
+		require.NoError(t, err)
+		assert.Equal(t, "github", identity.Provider)
+		identityRepo.AssertExpectations(t)
+	})
+
+	t.Run("state 不是当前用户发起的", func(t *testing.T) {
+		svc, _, _ := setupOAuthService(t, external)
+
+		_, err := svc.Link(ctx, 1, "github", startOAuth(t, svc, 2), "valid-code")
+		assert.ErrorIs(t, err, ErrOAuthFailed)
+	})
+
+	t.Run("已关联其他用户", func(t *testing.T) {
+		svc, identityRepo, _ := setupOAuthService(t, external)
+		identityRepo.On("GetIdentity", mock.Anything, "github", "583231").Return(repository.Identity{UserID: 2}, nil)
+
+		_, err := svc.Link(ctx, 1, "github", startOAuth(t, svc, 1), "valid-code")
+		assert.ErrorIs(t, err, ErrIdentityAlreadyLinked)
+	})
+
+	t.Run("已关联该提供方的其他账户", func(t *testing.T) {
+		svc, identityRepo, _ := setupOAuthService(t, external)
+		identityRepo.On("GetIdentity", mock.Anything, "github", "583231").Return(repository.Identity{}, sql.ErrNoRows)
+		identityRepo.On("ListUserIdentities", mock.Anything, int64(1)).Return([]repository.Identity{{Provider: "github", Subject: "1"}}, nil)
+
+		_, err := svc.Link(ctx, 1, "github", startOAuth(t, svc, 1), "valid-code")
+		assert.ErrorIs(t, err, ErrProviderAlreadyLinked)
+	})
+}
+
+// TestOAuthService_Unlink 测试解除关联
+func TestOAuthService_Unlink(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("无密码账户不能解除唯一的关联", func(t *testing.T) {
+		svc, identityRepo, userRepo := setupOAuthService(t, &stubGitHubUser{})
+		userRepo.On("GetUserByID", mock.Anything, int64(1)).Return(repository.User{ID: 1, Email: "octocat@github.com"}, nil)
+		userRepo.On("GetUserByEmail", mock.Anything, "octocat@github.com").Return(repository.User{ID: 1, Password: ""}, nil)
+		identityRepo.On("CountUserIdentities", mock.Anything, int64(1)).Return(int64(1), nil)
+
+		err := svc.Unlink(ctx, 1, "github")
+		assert.ErrorIs(t, err, ErrLastLoginMethod)
+		identityRepo.AssertNotCalled(t, "DeleteIdentity", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("有密码的账户可以解除", func(t *testing.T) {
+		svc, identityRepo, userRepo := setupOAuthService(t, &stubGitHubUser{})
+		userRepo.On("GetUserByID", mock.Anything, int64(1)).Return(repository.User{ID: 1, Email: "octocat@github.com"}, nil)
+		userRepo.On("GetUserByEmail", mock.Anything, "octocat@github.com").Return(repository.User{ID: 1, Password: "$2a$10$hash"}, nil)
+		identityRepo.On("DeleteIdentity", mock.Anything, int64(1), "github").Return(true, nil)
+
+		assert.NoError(t, svc.Unlink(ctx, 1, "github"))
+	})
+
+	t.Run("未关联", func(t *testing.T) {
+		svc, identityRepo, userRepo := setupOAuthService(t, &stubGitHubUser{})
+		userRepo.On("GetUserByID", mock.Anything, int64(1)).Return(repository.User{ID: 1, Email: "octocat@github.com"}, nil)
+		userRepo.On("GetUserByEmail", mock.Anything, "octocat@github.com").Return(repository.User{ID: 1, Password: "$2a$10$hash"}, nil)
+		identityRepo.On("DeleteIdentity", mock.Anything, int64(1), "github").Return(false, nil)
+
+		assert.ErrorIs(t, svc.Unlink(ctx, 1, "github"), ErrIdentityNotFound)
+	})
+}
@@ -0,0 +1,129 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+
+	"gin_demo/internal/repository"
+	"gin_demo/internal/response"
+	"gin_demo/pkg/auth"
+)
+
+// ErrPasswordPolicy 密码不符合策略（Fields 中为具体原因）
+var ErrPasswordPolicy = response.New(response.CodeInvalidParams, "密码不符合安全要求")
+
+// PasswordCheck 密码校验参数
+type PasswordCheck struct {
+	UserID   int64  // 修改或重置密码的用户（注册时为 0，不检查历史密码）
+	Field    string // 返回字段错误时使用的字段名（与请求 JSON 字段一致，如 password、new_password）
+	Password string
+	Username string // 用于相似度检查
+	Email    string // 用于相似度检查
+}
+
+// PasswordService 密码策略业务逻辑接口
+type PasswordService interface {
+	// Validate 校验新密码，不符合策略时返回带字段错误的 ErrPasswordPolicy
+	Validate(ctx context.Context, check PasswordCheck) error
+
+	// Remember 记录新密码的哈希（设置密码成功后调用，用于禁止重复使用）
+	Remember(ctx context.Context, userID int64, passwordHash string) error
+}
+
+// passwordService 密码策略业务逻辑实现
+type passwordService struct {
+	policy      *auth.PasswordPolicy
+	hasher      auth.PasswordHasher
+	userRepo    repository.UserRepositoryInterface
+	historySize int // 禁止重复使用最近 N 个密码（0 表示不限制）
+}
+
+// NewPasswordService 创建密码策略服务实例
+func NewPasswordService(
+	policy *auth.PasswordPolicy,
+	hasher auth.PasswordHasher,
+	userRepo repository.UserRepositoryInterface,
+	historySize int,
+) PasswordService {
+	return &passwordService{
+		policy:      policy,
+		hasher:      hasher,
+		userRepo:    userRepo,
+		historySize: historySize,
+	}
+}
+
+// Validate 校验新密码
+//
+// 泄露密码库查询失败时只记录日志，不阻止设置密码；
+// 历史密码逐个校验哈希，开销较大，其他检查通过后才检查。
+func (s *passwordService) Validate(ctx context.Context, check PasswordCheck) error {
+	violations, err := s.policy.Check(ctx, check.Password, auth.PasswordOwner{
+		Username: check.Username,
+		Email:    check.Email,
+	})
+	if err != nil {
+		slog.WarnContext(ctx, "Breached password check failed", "error", err)
+	}
+
+	if len(violations) == 0 && check.UserID != 0 && s.historySize > 0 {
+		reused, err := s.reused(ctx, check.UserID, check.Password)
+		if err != nil {
+			return err
+		}
+		if reused {
+			violations = append(violations, auth.PasswordViolation{
+				Code:    auth.PasswordReused,
+				Message: fmt.Sprintf("不能使用最近 %d 次用过的密码", s.historySize),
+			})
+		}
+	}
+
+	if len(violations) == 0 {
+		return nil
+	}
+
+	fields := make([]response.FieldError, 0, len(violations))
+	for _, v := range violations {
+		fields = append(fields, response.FieldError{Field: check.Field, Code: v.Code, Message: v.Message})
+	}
+	return ErrPasswordPolicy.WithFields(fields...)
+}
+
+// reused 判断密码是否与当前密码或最近的历史密码相同
+// （升级本功能前设置的密码没有历史记录，当前密码单独检查）
+func (s *passwordService) reused(ctx context.Context, userID int64, password string) (bool, error) {
+	current, err := s.userRepo.GetUserPassword(ctx, userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return false, fmt.Errorf("service: get user password: %w", err)
+	}
+
+	history, err := s.userRepo.GetPasswordHistory(ctx, userID, int32(s.historySize))
+	if err != nil {
+		return false, fmt.Errorf("service: get password history: %w", err)
+	}
+
+	for _, hash := range append([]string{current}, history...) {
+		if hash == "" {
+			continue
+		}
+		if ok, _ := s.hasher.Verify(password, hash); ok {
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+// Remember 记录新密码的哈希
+func (s *passwordService) Remember(ctx context.Context, userID int64, passwordHash string) error {
+	if s.historySize <= 0 || passwordHash == "" {
+		return nil
+	}
+	if err := s.userRepo.AddPasswordHistory(ctx, userID, passwordHash, int32(s.historySize)); err != nil {
+		return fmt.Errorf("service: add password history: %w", err)
+	}
+	return nil
+}
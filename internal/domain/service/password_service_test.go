@@ -0,0 +1,119 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	"gin_demo/internal/response"
+	"gin_demo/pkg/auth"
+	pkgerrors "gin_demo/pkg/errors"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// newTestPasswordService 创建测试用的密码策略服务（最短 8 位，必须包含数字，禁止重复使用最近 3 个密码）
+func newTestPasswordService(userRepo *MockUserRepository) PasswordService {
+	policy := auth.NewPasswordPolicy(auth.PasswordPolicyConfig{
+		MinLength:     8,
+		MaxLength:     64,
+		RequireDigit:  true,
+		BannedWords:   []string{"password"},
+		MaxSimilarity: 0.7,
+	}, nil)
+	return NewPasswordService(policy, testHasher, userRepo, 3)
+}
+
+// TestPasswordService_Validate 测试密码校验
+func TestPasswordService_Validate(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("返回字段错误", func(t *testing.T) {
+		userRepo := new(MockUserRepository)
+		svc := newTestPasswordService(userRepo)
+
+		err := svc.Validate(ctx, PasswordCheck{Field: "password", Password: "alice", Username: "alice"})
+		require.ErrorIs(t, err, ErrPasswordPolicy)
+
+		var bizErr *pkgerrors.Error
+		require.True(t, errors.As(err, &bizErr))
+		assert.Equal(t, []response.FieldError{
+			{Field: "password", Code: auth.PasswordTooShort, Message: "密码长度不能少于 8 个字符"},
+			{Field: "password", Code: auth.PasswordMissingDigit, Message: "密码必须包含数字"},
+			{Field: "password", Code: auth.PasswordSimilarToUsername, Message: "密码与用户名过于相似"},
+		}, bizErr.Fields)
+		assert.Empty(t, ErrPasswordPolicy.Fields, "不修改预定义错误")
+
+		// 注册时（UserID 为 0）不检查历史密码
+		userRepo.AssertNotCalled(t, "GetPasswordHistory", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("符合策略", func(t *testing.T) {
+		userRepo := new(MockUserRepository)
+		userRepo.On("GetUserPassword", ctx, int64(1)).Return("", sql.ErrNoRows)
+		userRepo.On("GetPasswordHistory", ctx, int64(1), int32(3)).Return([]string{}, nil)
+		svc := newTestPasswordService(userRepo)
+
+		assert.NoError(t, svc.Validate(ctx, PasswordCheck{UserID: 1, Field: "new_password", Password: "correct-horse-7"}))
+		userRepo.AssertExpectations(t)
+	})
+
+	t.Run("禁止重复使用历史密码", func(t *testing.T) {
+		current, err := testHasher.Hash("current-pass-1")
+		require.NoError(t, err)
+		previous, err := testHasher.Hash("previous-pass-1")
+		require.NoError(t, err)
+
+		userRepo := new(MockUserRepository)
+		userRepo.On("GetUserPassword", ctx, int64(1)).Return(current, nil)
+		userRepo.On("GetPasswordHistory", ctx, int64(1), int32(3)).Return([]string{previous}, nil)
+		svc := newTestPasswordService(userRepo)
+
+		for _, password := range []string{"current-pass-1", "previous-pass-1"} {
+			err := svc.Validate(ctx, PasswordCheck{UserID: 1, Field: "new_password", Password: password})
+			var bizErr *pkgerrors.Error
+			require.True(t, errors.As(err, &bizErr), password)
+			require.Len(t, bizErr.Fields, 1)
+			assert.Equal(t, auth.PasswordReused, bizErr.Fields[0].Code)
+			assert.Equal(t, "new_password", bizErr.Fields[0].Field)
+		}
+
+		assert.NoError(t, svc.Validate(ctx, PasswordCheck{UserID: 1, Field: "new_password", Password: "brand-new-pass-1"}))
+	})
+
+	t.Run("查询历史密码失败", func(t *testing.T) {
+		userRepo := new(MockUserRepository)
+		userRepo.On("GetUserPassword", ctx, int64(1)).Return("", errors.New("db down"))
+		svc := newTestPasswordService(userRepo)
+
+		err := svc.Validate(ctx, PasswordCheck{UserID: 1, Field: "new_password", Password: "correct-horse-7"})
+		assert.Error(t, err)
+		assert.NotErrorIs(t, err, ErrPasswordPolicy)
+	})
+}
+
+// TestPasswordService_Remember 测试记录历史密码
+func TestPasswordService_Remember(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("保留最近 N 个", func(t *testing.T) {
+		userRepo := new(MockUserRepository)
+		userRepo.On("AddPasswordHistory", ctx, int64(1), "hash", int32(3)).Return(nil)
+		svc := newTestPasswordService(userRepo)
+
+		require.NoError(t, svc.Remember(ctx, 1, "hash"))
+		userRepo.AssertExpectations(t)
+	})
+
+	t.Run("未启用或无密码时不记录", func(t *testing.T) {
+		userRepo := new(MockUserRepository)
+		svc := NewPasswordService(auth.NewPasswordPolicy(auth.PasswordPolicyConfig{}, nil), testHasher, userRepo, 0)
+		require.NoError(t, svc.Remember(ctx, 1, "hash"))
+
+		require.NoError(t, newTestPasswordService(userRepo).Remember(ctx, 1, ""))
+		userRepo.AssertNotCalled(t, "AddPasswordHistory", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
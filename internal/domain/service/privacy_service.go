@@ -0,0 +1,645 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"time"
+
+	"gin_demo/internal/repository"
+	"gin_demo/internal/response"
+	"gin_demo/pkg/audit"
+	"gin_demo/pkg/auth"
+	"gin_demo/pkg/metrics"
+	"gin_demo/pkg/task"
+	"gin_demo/pkg/tenant"
+)
+
+// 后台作业类型
+const (
+	JobTypeUserExport = "user.export" // 导出用户数据
+	JobTypeUserErase  = "user.erase"  // 匿名化用户
+)
+
+// 数据导出格式
+const (
+	ExportFormatJSON = "json" // 单个 JSON 文件
+	ExportFormatZip  = "zip"  // 每个部分一个 JSON 文件，打包为 zip
+)
+
+var (
+	// ErrJobNotFound 作业不存在（或不是当前用户提交的，或记录已过期）
+	ErrJobNotFound = response.New(response.CodeNotFound, "作业不存在")
+	// ErrExportFormat 不支持的导出格式
+	ErrExportFormat = response.New(response.CodeInvalidParams, "不支持的导出格式")
+	// ErrExportNotReady 导出作业尚未完成或执行失败
+	ErrExportNotReady = response.New(response.CodeInvalidState, "导出尚未完成")
+	// ErrExportExpired 导出文件已过期删除
+	ErrExportExpired = response.New(response.CodeNotFound, "导出文件已过期，请重新导出")
+)
+
+// PrivacyConfig 数据导出与删除配置
+type PrivacyConfig struct {
+	Dir string // 导出文件的保存目录（过期文件由清理任务删除）
+}
+
+// ErasureInput 数据删除参数
+type ErasureInput struct {
+	UserID   int64  // 被删除数据的用户
+	ActorID  int64  // 提交者（本人或管理员，作业归属于提交者）
+	Password string // 本人申请时确认当前密码（没有密码的第三方登录账户除外；管理员申请时忽略）
+}
+
+// ExportResult 导出作业的结果（保存在 Job.Result 中）
+type ExportResult struct {
+	Format string `json:"format"`
+	Size   int64  `json:"size"` // 文件大小（字节）
+}
+
+// ExportFile 已完成的导出文件
+type ExportFile struct {
+	Path string // 本地文件路径
+	Name string // 下载时使用的文件名
+}
+
+// UserExport 用户数据导出内容
+//
+// 包含我们保存的与用户有关的所有数据（密码哈希、两步验证密钥、API Key 密钥等凭据除外）。
+// 刷新令牌只以哈希的形式按令牌保存，无法按用户列出，会话部分只包含当前的 Token 版本号。
+type UserExport struct {
+	ExportedAt  time.Time          `json:"exported_at"`
+	Profile     ExportProfile      `json:"profile"`
+	Identities  []ExportIdentity   `json:"identities"`
+	APIKeys     []ExportAPIKey     `json:"api_keys"`
+	MFA         ExportMFA          `json:"mfa"`
+	Sessions    ExportSessions     `json:"sessions"`
+	AuditEvents []ExportAuditEvent `json:"audit_events"`
+}
+
+// ExportProfile 导出的用户资料
+type ExportProfile struct {
+	ID           int64      `json:"id"`
+	Username     string     `json:"username"`
+	Email        string     `json:"email"`
+	Avatar       string     `json:"avatar,omitempty"`
+	Role         string     `json:"role"`
+	Permissions  []string   `json:"permissions"`
+	Status       int16      `json:"status"`
+	StatusReason string     `json:"status_reason,omitempty"`
+	HasPassword  bool       `json:"has_password"`
+	CreatedAt    time.Time  `json:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty"`
+}
+
+// ExportIdentity 导出的第三方账户关联
+type ExportIdentity struct {
+	Provider    string     `json:"provider"`
+	Email       string     `json:"email,omitempty"`
+	LastLoginAt *time.Time `json:"last_login_at,omitempty"`
+	CreatedAt   time.Time  `json:"created_at"`
+}
+
+// ExportAPIKey 导出的 API Key（不包含密钥）
+type ExportAPIKey struct {
+	Name       string     `json:"name"`
+	Prefix     string     `json:"prefix"`
+	Scopes     []string   `json:"scopes"`
+	ExpiresAt  *time.Time `json:"expires_at,omitempty"`
+	LastUsedAt *time.Time `json:"last_used_at,omitempty"`
+	CreatedAt  time.Time  `json:"created_at"`
+}
+
+// ExportMFA 导出的两步验证状态（不包含密钥和恢复码）
+type ExportMFA struct {
+	Enabled                bool       `json:"enabled"`
+	EnabledAt              *time.Time `json:"enabled_at,omitempty"`
+	RecoveryCodesRemaining int64      `json:"recovery_codes_remaining"`
+}
+
+// ExportSessions 导出的会话信息
+type ExportSessions struct {
+	TokenVersion int64 `json:"token_version"` // 每次登出所有设备、修改密码或状态变化时递增
+}
+
+// ExportAuditEvent 导出的审计事件（用户作为操作者或操作目标）
+type ExportAuditEvent struct {
+	ID         int64         `json:"id"`
+	ActorID    int64         `json:"actor_id,omitempty"`
+	Action     string        `json:"action"`
+	TargetType string        `json:"target_type"`
+	TargetID   int64         `json:"target_id,omitempty"`
+	Changes    audit.Changes `json:"changes,omitempty"`
+	IP         string        `json:"ip,omitempty"`
+	UserAgent  string        `json:"user_agent,omitempty"`
+	CreatedAt  time.Time     `json:"created_at"`
+}
+
+// PrivacyService 数据导出与删除业务逻辑接口（GDPR 访问权和删除权）
+type PrivacyService interface {
+	// RequestExport 提交导出用户数据的作业（同一用户同时只有一个未完成的导出作业，重复提交时返回该作业）
+	RequestExport(ctx context.Context, userID int64, format string) (task.Job, error)
+
+	// RequestErasure 提交匿名化用户的作业（同一用户同时只有一个未完成的删除作业）
+	RequestErasure(ctx context.Context, input ErasureInput) (task.Job, error)
+
+	// GetJob 查询用户提交的作业
+	GetJob(ctx context.Context, userID int64, jobID string) (task.Job, error)
+
+	// GetExportFile 获取已完成的导出文件
+	GetExportFile(ctx context.Context, userID int64, jobID string) (ExportFile, error)
+}
+
+// exportPayload 导出作业参数
+type exportPayload struct {
+	TenantID int64  `json:"tenant_id"`
+	UserID   int64  `json:"user_id"`
+	Format   string `json:"format"`
+}
+
+// erasurePayload 删除作业参数
+type erasurePayload struct {
+	TenantID int64 `json:"tenant_id"`
+	UserID   int64 `json:"user_id"`
+	ActorID  int64 `json:"actor_id"`
+}
+
+// privacyService 数据导出与删除业务逻辑实现
+type privacyService struct {
+	userRepo repository.UserRepositoryInterface
+	hasher   auth.PasswordHasher
+	audits   AuditService
+	apiKeys  APIKeyService
+	oauth    OAuthService
+	mfa      MFAService
+	avatars  AvatarService
+	auditor  audit.Recorder
+	jobs     *task.JobQueue
+	config   PrivacyConfig
+}
+
+// NewPrivacyService 创建数据导出与删除服务实例（在作业队列中注册导出和删除作业）
+func NewPrivacyService(
+	userRepo repository.UserRepositoryInterface,
+	hasher auth.PasswordHasher,
+	audits AuditService,
+	apiKeys APIKeyService,
+	oauth OAuthService,
+	mfa MFAService,
+	avatars AvatarService,
+	auditor audit.Recorder,
+	jobs *task.JobQueue,
+	config PrivacyConfig,
+) (PrivacyService, error) {
+	s := &privacyService{
+		userRepo: userRepo,
+		hasher:   hasher,
+		audits:   audits,
+		apiKeys:  apiKeys,
+		oauth:    oauth,
+		mfa:      mfa,
+		avatars:  avatars,
+		auditor:  auditor,
+		jobs:     jobs,
+		config:   config,
+	}
+
+	if err := jobs.Handle(JobTypeUserExport, s.runExport); err != nil {
+		return nil, err
+	}
+	if err := jobs.Handle(JobTypeUserErase, s.runErasure); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// RequestExport 提交导出作业
+func (s *privacyService) RequestExport(ctx context.Context, userID int64, format string) (task.Job, error) {
+	if format == "" {
+		format = ExportFormatJSON
+	}
+	if format != ExportFormatJSON && format != ExportFormatZip {
+		return task.Job{}, ErrExportFormat
+	}
+
+	tenantID := tenant.ID(ctx)
+	job, err := s.jobs.Submit(ctx, JobTypeUserExport, jobOwner(tenantID, userID),
+		exportPayload{TenantID: tenantID, UserID: userID, Format: format},
+		task.SubmitOptions{UniqueKey: fmt.Sprintf("export:%d:%d", tenantID, userID)},
+	)
+	if err != nil {
+		metrics.RecordUserOperation("export", false)
+		return task.Job{}, fmt.Errorf("service: submit export job: %w", err)
+	}
+
+	s.auditor.Record(ctx, audit.Event{
+		Action:     AuditActionUserExport,
+		TargetType: AuditTargetUser,
+		TargetID:   userID,
+	})
+	slog.InfoContext(ctx, "User data export requested", "user_id", userID, "job_id", job.ID, "format", format)
+	return job, nil
+}
+
+// RequestErasure 提交删除作业
+//
+// 提交前检查用户状态和密码，立即返回明确的错误；实际的匿名化在后台执行。
+func (s *privacyService) RequestErasure(ctx context.Context, input ErasureInput) (task.Job, error) {
+	user, err := s.userRepo.GetUserByIDAnyStatus(ctx, input.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return task.Job{}, ErrUserNotFound
+		}
+		return task.Job{}, fmt.Errorf("service: get user: %w", err)
+	}
+	if !CanTransitionUser(user.Status, repository.UserStatusErased) {
+		return task.Job{}, ErrInvalidUserTransition
+	}
+
+	// 本人申请时确认密码，防止会话被盗用后删除账户
+	if input.ActorID == input.UserID {
+		if err := s.confirmPassword(ctx, input.UserID, input.Password); err != nil {
+			return task.Job{}, err
+		}
+	}
+
+	tenantID := tenant.ID(ctx)
+	job, err := s.jobs.Submit(ctx, JobTypeUserErase, jobOwner(tenantID, input.ActorID),
+		erasurePayload{TenantID: tenantID, UserID: input.UserID, ActorID: input.ActorID},
+		task.SubmitOptions{UniqueKey: fmt.Sprintf("erase:%d:%d", tenantID, input.UserID)},
+	)
+	if err != nil {
+		metrics.RecordUserOperation("erase", false)
+		return task.Job{}, fmt.Errorf("service: submit erasure job: %w", err)
+	}
+
+	slog.InfoContext(ctx, "User erasure requested",
+		"user_id", input.UserID,
+		"actor_id", input.ActorID,
+		"job_id", job.ID,
+	)
+	return job, nil
+}
+
+// confirmPassword 校验当前密码（没有密码的账户不校验）
+func (s *privacyService) confirmPassword(ctx context.Context, userID int64, password string) error {
+	hash, err := s.userRepo.GetUserPassword(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("service: get user password: %w", err)
+	}
+	if hash == "" {
+		return nil
+	}
+	if ok, _ := s.hasher.Verify(password, hash); !ok {
+		slog.WarnContext(ctx, "Erasure request rejected: invalid password", "user_id", userID)
+		return ErrInvalidPassword
+	}
+	return nil
+}
+
+// GetJob 查询用户提交的作业
+func (s *privacyService) GetJob(ctx context.Context, userID int64, jobID string) (task.Job, error) {
+	job, err := s.jobs.Get(ctx, jobID)
+	if err != nil {
+		if errors.Is(err, task.ErrJobNotFound) {
+			return task.Job{}, ErrJobNotFound
+		}
+		return task.Job{}, fmt.Errorf("service: get job: %w", err)
+	}
+	// 其他用户的作业同样返回不存在，不泄露作业 ID 是否有效
+	if job.Owner != jobOwner(tenant.ID(ctx), userID) {
+		return task.Job{}, ErrJobNotFound
+	}
+	return job, nil
+}
+
+// GetExportFile 获取已完成的导出文件
+func (s *privacyService) GetExportFile(ctx context.Context, userID int64, jobID string) (ExportFile, error) {
+	job, err := s.GetJob(ctx, userID, jobID)
+	if err != nil {
+		return ExportFile{}, err
+	}
+	if job.Type != JobTypeUserExport {
+		return ExportFile{}, ErrJobNotFound
+	}
+	if job.Status != task.JobSucceeded {
+		return ExportFile{}, ErrExportNotReady
+	}
+
+	var payload exportPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return ExportFile{}, fmt.Errorf("service: decode export job: %w", err)
+	}
+
+	path := s.exportPath(payload, job.ID)
+	if _, err := os.Stat(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ExportFile{}, ErrExportExpired
+		}
+		return ExportFile{}, fmt.Errorf("service: stat export file: %w", err)
+	}
+
+	name := fmt.Sprintf("user-%d-export-%s.%s", payload.UserID, job.CreatedAt.Format("20060102"), payload.Format)
+	return ExportFile{Path: path, Name: name}, nil
+}
+
+// runExport 执行导出作业
+func (s *privacyService) runExport(ctx context.Context, job task.Job, progress task.ProgressFunc) (any, error) {
+	var payload exportPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("decode payload: %w", err)
+	}
+	ctx = tenant.WithID(ctx, payload.TenantID)
+
+	export, err := s.collect(ctx, payload.UserID, progress)
+	if err != nil {
+		metrics.RecordUserOperation("export", false)
+		return nil, err
+	}
+
+	path := s.exportPath(payload, job.ID)
+	size, err := writeExport(path, payload.Format, export)
+	if err != nil {
+		metrics.RecordUserOperation("export", false)
+		return nil, err
+	}
+
+	metrics.RecordUserOperation("export", true)
+	slog.InfoContext(ctx, "User data exported", "user_id", payload.UserID, "job_id", job.ID, "size", size)
+	return ExportResult{Format: payload.Format, Size: size}, nil
+}
+
+// collect 收集用户数据
+func (s *privacyService) collect(ctx context.Context, userID int64, progress task.ProgressFunc) (UserExport, error) {
+	const steps = 6
+	export := UserExport{ExportedAt: time.Now().UTC()}
+
+	user, err := s.userRepo.GetUserByIDAnyStatus(ctx, userID)
+	if err != nil {
+		return export, fmt.Errorf("get user: %w", err)
+	}
+	permissions, err := s.userRepo.GetUserPermissions(ctx, userID)
+	if err != nil {
+		return export, fmt.Errorf("get permissions: %w", err)
+	}
+	password, err := s.userRepo.GetUserPassword(ctx, userID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return export, fmt.Errorf("get password: %w", err)
+	}
+	export.Profile = ExportProfile{
+		ID:           user.ID,
+		Username:     user.Username,
+		Email:        user.Email,
+		Avatar:       user.Avatar.String,
+		Role:         user.Role,
+		Permissions:  nonNil(permissions),
+		Status:       user.Status,
+		StatusReason: user.StatusReason.String,
+		HasPassword:  password != "",
+		CreatedAt:    user.CreatedAt,
+		UpdatedAt:    user.UpdatedAt,
+		DeletedAt:    nullTimePtr(user.DeletedAt),
+	}
+	export.Sessions = ExportSessions{TokenVersion: user.TokenVersion}
+	progress(1, steps)
+
+	identities, err := s.oauth.ListIdentities(ctx, userID)
+	if err != nil {
+		return export, fmt.Errorf("list identities: %w", err)
+	}
+	export.Identities = make([]ExportIdentity, len(identities))
+	for i, identity := range identities {
+		export.Identities[i] = ExportIdentity(identity)
+	}
+	progress(2, steps)
+
+	keys, err := s.apiKeys.List(ctx, userID)
+	if err != nil {
+		return export, fmt.Errorf("list api keys: %w", err)
+	}
+	export.APIKeys = make([]ExportAPIKey, len(keys))
+	for i, key := range keys {
+		scopes := make([]string, len(key.Scopes))
+		for j, scope := range key.Scopes {
+			scopes[j] = string(scope)
+		}
+		export.APIKeys[i] = ExportAPIKey{
+			Name:       key.Name,
+			Prefix:     key.Prefix,
+			Scopes:     scopes,
+			ExpiresAt:  key.ExpiresAt,
+			LastUsedAt: key.LastUsedAt,
+			CreatedAt:  key.CreatedAt,
+		}
+	}
+	progress(3, steps)
+
+	mfa, err := s.mfa.GetStatus(ctx, userID)
+	if err != nil {
+		return export, fmt.Errorf("get mfa status: %w", err)
+	}
+	export.MFA = ExportMFA(mfa)
+	progress(4, steps)
+
+	// 用户作为操作者和作为操作目标的事件，合并后按时间倒序
+	events := make(map[int64]AuditEvent)
+	for _, filter := range []AuditFilter{
+		{ActorID: userID},
+		{TargetType: AuditTargetUser, TargetID: userID},
+	} {
+		if err := s.listAllAuditEvents(ctx, filter, events); err != nil {
+			return export, err
+		}
+	}
+	ids := make([]int64, 0, len(events))
+	for id := range events {
+		ids = append(ids, id)
+	}
+	slices.Sort(ids)
+	slices.Reverse(ids)
+	export.AuditEvents = make([]ExportAuditEvent, len(ids))
+	for i, id := range ids {
+		event := events[id]
+		export.AuditEvents[i] = ExportAuditEvent{
+			ID:         event.ID,
+			ActorID:    event.ActorID,
+			Action:     event.Action,
+			TargetType: event.TargetType,
+			TargetID:   event.TargetID,
+			Changes:    event.Changes,
+			IP:         event.IP,
+			UserAgent:  event.UserAgent,
+			CreatedAt:  event.CreatedAt,
+		}
+	}
+	progress(5, steps)
+
+	return export, nil
+}
+
+// listAllAuditEvents 逐页读取符合条件的所有审计事件
+func (s *privacyService) listAllAuditEvents(ctx context.Context, filter AuditFilter, events map[int64]AuditEvent) error {
+	filter.Limit = maxAuditPageSize
+	for {
+		page, err := s.audits.List(ctx, filter)
+		if err != nil {
+			return fmt.Errorf("list audit events: %w", err)
+		}
+		for _, event := range page.Events {
+			events[event.ID] = event
+		}
+		if page.NextCursor == "" {
+			return nil
+		}
+		filter.Cursor = page.NextCursor
+	}
+}
+
+// runErasure 执行删除作业
+func (s *privacyService) runErasure(ctx context.Context, job task.Job, _ task.ProgressFunc) (any, error) {
+	var payload erasurePayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("decode payload: %w", err)
+	}
+	ctx = tenant.WithID(ctx, payload.TenantID)
+
+	// 匿名化会清除头像地址，先读取用户以便删除上传的头像文件
+	user, err := s.userRepo.GetUserByIDAnyStatus(ctx, payload.UserID)
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		return nil, fmt.Errorf("get user: %w", err)
+	}
+
+	anonymized, err := s.userRepo.AnonymizeUser(ctx, payload.UserID)
+	if err != nil {
+		metrics.RecordUserOperation("erase", false)
+		return nil, err
+	}
+	if !anonymized {
+		// 提交后被其他作业处理过，视为成功
+		slog.InfoContext(ctx, "User already erased", "user_id", payload.UserID, "job_id", job.ID)
+		return nil, nil
+	}
+
+	// 已生成的导出文件和上传的头像同样包含个人信息，一并删除
+	s.removeExports(ctx, payload.TenantID, payload.UserID)
+	s.avatars.RemoveAvatarFiles(ctx, user)
+
+	// 不记录变更内容（变更前的值就是要删除的个人信息）
+	s.auditor.Record(ctx, audit.Event{
+		TenantID:   payload.TenantID,
+		ActorID:    payload.ActorID,
+		Action:     AuditActionUserErase,
+		TargetType: AuditTargetUser,
+		TargetID:   payload.UserID,
+	})
+	metrics.RecordUserOperation("erase", true)
+	slog.InfoContext(ctx, "User erased", "user_id", payload.UserID, "actor_id", payload.ActorID, "job_id", job.ID)
+	return nil, nil
+}
+
+// exportPath 导出文件路径（文件名包含租户和用户，删除数据时按前缀查找）
+func (s *privacyService) exportPath(payload exportPayload, jobID string) string {
+	return filepath.Join(s.config.Dir, "exports", exportPrefix(payload.TenantID, payload.UserID)+jobID+"."+payload.Format)
+}
+
+// removeExports 删除用户的所有导出文件
+func (s *privacyService) removeExports(ctx context.Context, tenantID, userID int64) {
+	paths, _ := filepath.Glob(filepath.Join(s.config.Dir, "exports", exportPrefix(tenantID, userID)+"*"))
+	for _, path := range paths {
+		if err := os.Remove(path); err != nil {
+			slog.WarnContext(ctx, "Failed to remove export file", "path", path, "error", err)
+		}
+	}
+}
+
+// exportPrefix 导出文件名前缀
+func exportPrefix(tenantID, userID int64) string {
+	return strconv.FormatInt(tenantID, 10) + "_" + strconv.FormatInt(userID, 10) + "_"
+}
+
+// writeExport 写入导出文件（先写临时文件再重命名，下载时不会读到不完整的文件），返回文件大小
+func writeExport(path, format string, export UserExport) (int64, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return 0, fmt.Errorf("create export dir: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".export-*")
+	if err != nil {
+		return 0, fmt.Errorf("create export file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if format == ExportFormatZip {
+		err = writeExportZip(tmp, export)
+	} else {
+		encoder := json.NewEncoder(tmp)
+		encoder.SetIndent("", "  ")
+		err = encoder.Encode(export)
+	}
+	if err != nil {
+		tmp.Close()
+		return 0, fmt.Errorf("write export file: %w", err)
+	}
+
+	info, err := tmp.Stat()
+	if err != nil {
+		tmp.Close()
+		return 0, fmt.Errorf("stat export file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return 0, fmt.Errorf("close export file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		return 0, fmt.Errorf("rename export file: %w", err)
+	}
+	return info.Size(), nil
+}
+
+// writeExportZip 每个部分写入一个 JSON 文件
+func writeExportZip(file *os.File, export UserExport) error {
+	w := zip.NewWriter(file)
+	parts := []struct {
+		name string
+		data any
+	}{
+		{"profile.json", export.Profile},
+		{"identities.json", export.Identities},
+		{"api_keys.json", export.APIKeys},
+		{"mfa.json", export.MFA},
+		{"sessions.json", export.Sessions},
+		{"audit_events.json", export.AuditEvents},
+	}
+	for _, part := range parts {
+		f, err := w.CreateHeader(&zip.FileHeader{Name: part.name, Method: zip.Deflate, Modified: export.ExportedAt})
+		if err != nil {
+			return err
+		}
+		encoder := json.NewEncoder(f)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(part.data); err != nil {
+			return err
+		}
+	}
+	return w.Close()
+}
+
+// jobOwner 作业归属（租户 + 用户）
+func jobOwner(tenantID, userID int64) string {
+	return strconv.FormatInt(tenantID, 10) + ":" + strconv.FormatInt(userID, 10)
+}
+
+// nonNil 把 nil 切片转为空切片（导出的 JSON 中为 [] 而不是 null）
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
@@ -0,0 +1,280 @@
+package service
+
+import (
+	"archive/zip"
+	"context"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"testing"
+	"time"
+
+	"gin_demo/internal/repository"
+	"gin_demo/pkg/audit"
+	"gin_demo/pkg/task"
+	"gin_demo/pkg/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// stubOAuthService 只实现 ListIdentities
+type stubOAuthService struct {
+	OAuthService
+	identities []Identity
+}
+
+func (s stubOAuthService) ListIdentities(context.Context, int64) ([]Identity, error) {
+	return s.identities, nil
+}
+
+// stubAPIKeyService 只实现 List
+type stubAPIKeyService struct {
+	APIKeyService
+	keys []APIKey
+}
+
+func (s stubAPIKeyService) List(context.Context, int64) ([]APIKey, error) {
+	return s.keys, nil
+}
+
+// stubMFAService 只实现 GetStatus
+type stubMFAService struct {
+	MFAService
+	status MFAStatus
+}
+
+func (s stubMFAService) GetStatus(context.Context, int64) (MFAStatus, error) {
+	return s.status, nil
+}
+
+// stubAvatarService 只实现 RemoveAvatarFiles（记录删除了哪些用户的头像）
+type stubAvatarService struct {
+	AvatarService
+	removed []repository.User
+}
+
+func (s *stubAvatarService) RemoveAvatarFiles(_ context.Context, user repository.User) {
+	s.removed = append(s.removed, user)
+}
+
+// newTestPrivacyService 创建测试用的数据导出与删除服务（内存作业队列，已启动）
+func newTestPrivacyService(t *testing.T, userRepo *MockUserRepository, auditRepo *MockAuditRepository, auditor audit.Recorder, avatars AvatarService) (PrivacyService, string) {
+	t.Helper()
+
+	jobs := task.NewJobQueue(task.JobQueueConfig{Store: task.NewMemoryJobStore(10), Workers: 1, Timeout: 5 * time.Second})
+	dir := t.TempDir()
+	svc, err := NewPrivacyService(
+		userRepo,
+		testHasher,
+		NewAuditService(auditRepo),
+		stubAPIKeyService{keys: []APIKey{{ID: 3, Name: "ci", Prefix: "gd_abc", CreatedAt: time.Now()}}},
+		stubOAuthService{identities: []Identity{{Provider: "github", Email: "alice@example.com"}}},
+		stubMFAService{status: MFAStatus{Enabled: true, RecoveryCodesRemaining: 8}},
+		avatars,
+		auditor,
+		jobs,
+		PrivacyConfig{Dir: dir},
+	)
+	require.NoError(t, err)
+
+	jobs.Start()
+	t.Cleanup(jobs.Stop)
+	return svc, dir
+}
+
+// waitJob 等待作业结束
+func waitJob(t *testing.T, svc PrivacyService, ctx context.Context, userID int64, jobID string) task.Job {
+	t.Helper()
+
+	var job task.Job
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = svc.GetJob(ctx, userID, jobID)
+		require.NoError(t, err)
+		return job.Status.Finished()
+	}, 5*time.Second, 10*time.Millisecond)
+	return job
+}
+
+// TestPrivacyService_Export 测试导出用户数据
+func TestPrivacyService_Export(t *testing.T) {
+	ctx := tenant.WithID(context.Background(), 2)
+	user := repository.User{ID: 1, Username: "alice", Email: "alice@example.com", Status: repository.UserStatusActive, Role: "user", TenantID: 2, TokenVersion: 4}
+
+	for _, format := range []string{ExportFormatJSON, ExportFormatZip} {
+		t.Run(format, func(t *testing.T) {
+			userRepo := new(MockUserRepository)
+			userRepo.On("GetUserByIDAnyStatus", mock.Anything, int64(1)).Return(user, nil)
+			userRepo.On("GetUserPermissions", mock.Anything, int64(1)).Return([]string{"user:read"}, nil)
+			userRepo.On("GetUserPassword", mock.Anything, int64(1)).Return("hash", nil)
+
+			// 作为操作者和作为目标的事件有重叠（如修改自己的资料），导出时去重
+			auditRepo := new(MockAuditRepository)
+			auditRepo.On("ListAuditEvents", mock.Anything, repository.ListAuditEventsParams{
+				ActorID: 1, Limit: maxAuditPageSize + 1,
+			}).Return([]repository.AuditEvent{{ID: 5, Action: AuditActionUserUpdate}, {ID: 2, Action: AuditActionUserRegister}}, nil)
+			auditRepo.On("ListAuditEvents", mock.Anything, repository.ListAuditEventsParams{
+				TargetType: AuditTargetUser, TargetID: 1, Limit: maxAuditPageSize + 1,
+			}).Return([]repository.AuditEvent{{ID: 6, Action: AuditActionUserSuspend}, {ID: 5, Action: AuditActionUserUpdate}}, nil)
+
+			auditor := &recordingAuditor{}
+			svc, dir := newTestPrivacyService(t, userRepo, auditRepo, auditor, &stubAvatarService{})
+
+			job, err := svc.RequestExport(ctx, 1, format)
+			require.NoError(t, err)
+			assert.Equal(t, "2:1", job.Owner)
+
+			job = waitJob(t, svc, ctx, 1, job.ID)
+			require.Equal(t, task.JobSucceeded, job.Status, job.Error)
+			assert.Equal(t, task.JobProgress{Done: 5, Total: 6}, job.Progress)
+			assert.Equal(t, AuditActionUserExport, auditor.events[0].Action)
+
+			file, err := svc.GetExportFile(ctx, 1, job.ID)
+			require.NoError(t, err)
+			assert.Equal(t, filepath.Join(dir, "exports"), filepath.Dir(file.Path))
+			info, err := os.Stat(file.Path)
+			require.NoError(t, err)
+			assert.Equal(t, os.FileMode(0o600), info.Mode().Perm())
+
+			var export UserExport
+			if format == ExportFormatJSON {
+				data, err := os.ReadFile(file.Path)
+				require.NoError(t, err)
+				require.NoError(t, json.Unmarshal(data, &export))
+			} else {
+				r, err := zip.OpenReader(file.Path)
+				require.NoError(t, err)
+				defer r.Close()
+
+				names := make([]string, 0, len(r.File))
+				for _, f := range r.File {
+					names = append(names, f.Name)
+				}
+				sort.Strings(names)
+				assert.Equal(t, []string{"api_keys.json", "audit_events.json", "identities.json", "mfa.json", "profile.json", "sessions.json"}, names)
+
+				f, err := r.Open("audit_events.json")
+				require.NoError(t, err)
+				require.NoError(t, json.NewDecoder(f).Decode(&export.AuditEvents))
+				f.Close()
+				f, err = r.Open("profile.json")
+				require.NoError(t, err)
+				require.NoError(t, json.NewDecoder(f).Decode(&export.Profile))
+				f.Close()
+			}
+
+			assert.Equal(t, "alice@example.com", export.Profile.Email)
+			assert.Equal(t, []string{"user:read"}, export.Profile.Permissions)
+			assert.True(t, export.Profile.HasPassword)
+			require.Len(t, export.AuditEvents, 3)
+			assert.Equal(t, []int64{6, 5, 2}, []int64{export.AuditEvents[0].ID, export.AuditEvents[1].ID, export.AuditEvents[2].ID})
+			if format == ExportFormatJSON {
+				assert.Equal(t, int64(4), export.Sessions.TokenVersion)
+				assert.Equal(t, "github", export.Identities[0].Provider)
+				assert.Equal(t, "gd_abc", export.APIKeys[0].Prefix)
+				assert.True(t, export.MFA.Enabled)
+			}
+
+			// 其他用户或其他租户查询不到
+			_, err = svc.GetJob(ctx, 9, job.ID)
+			assert.ErrorIs(t, err, ErrJobNotFound)
+			_, err = svc.GetJob(tenant.WithID(context.Background(), 3), 1, job.ID)
+			assert.ErrorIs(t, err, ErrJobNotFound)
+		})
+	}
+
+	t.Run("不支持的格式", func(t *testing.T) {
+		svc, _ := newTestPrivacyService(t, new(MockUserRepository), new(MockAuditRepository), audit.NopRecorder{}, &stubAvatarService{})
+		_, err := svc.RequestExport(ctx, 1, "xml")
+		assert.ErrorIs(t, err, ErrExportFormat)
+	})
+
+	t.Run("作业不存在", func(t *testing.T) {
+		svc, _ := newTestPrivacyService(t, new(MockUserRepository), new(MockAuditRepository), audit.NopRecorder{}, &stubAvatarService{})
+		_, err := svc.GetExportFile(ctx, 1, "missing")
+		assert.ErrorIs(t, err, ErrJobNotFound)
+	})
+}
+
+// TestPrivacyService_Erasure 测试删除用户数据
+func TestPrivacyService_Erasure(t *testing.T) {
+	ctx := tenant.WithID(context.Background(), 2)
+	hash, err := testHasher.Hash("secret-pass-1")
+	require.NoError(t, err)
+	active := repository.User{ID: 1, Username: "alice", Status: repository.UserStatusActive, TenantID: 2}
+
+	t.Run("本人申请需要确认密码", func(t *testing.T) {
+		userRepo := new(MockUserRepository)
+		userRepo.On("GetUserByIDAnyStatus", ctx, int64(1)).Return(active, nil)
+		userRepo.On("GetUserPassword", ctx, int64(1)).Return(hash, nil)
+		svc, _ := newTestPrivacyService(t, userRepo, new(MockAuditRepository), audit.NopRecorder{}, &stubAvatarService{})
+
+		_, err := svc.RequestErasure(ctx, ErasureInput{UserID: 1, ActorID: 1, Password: "wrong"})
+		assert.ErrorIs(t, err, ErrInvalidPassword)
+		userRepo.AssertNotCalled(t, "AnonymizeUser", mock.Anything, mock.Anything)
+	})
+
+	t.Run("匿名化并删除导出文件", func(t *testing.T) {
+		userRepo := new(MockUserRepository)
+		userRepo.On("GetUserByIDAnyStatus", ctx, int64(1)).Return(active, nil)
+		userRepo.On("GetUserPassword", ctx, int64(1)).Return(hash, nil)
+		userRepo.On("GetUserByIDAnyStatus", mock.Anything, int64(1)).Return(active, nil)
+		userRepo.On("AnonymizeUser", mock.Anything, int64(1)).Return(true, nil).Once()
+		auditor := &recordingAuditor{}
+		avatars := &stubAvatarService{}
+		svc, dir := newTestPrivacyService(t, userRepo, new(MockAuditRepository), auditor, avatars)
+
+		// 之前生成的导出文件（其他用户的文件保留）
+		exports := filepath.Join(dir, "exports")
+		require.NoError(t, os.MkdirAll(exports, 0o700))
+		mine := filepath.Join(exports, "2_1_abc.json")
+		other := filepath.Join(exports, "2_11_abc.json")
+		require.NoError(t, os.WriteFile(mine, []byte("{}"), 0o600))
+		require.NoError(t, os.WriteFile(other, []byte("{}"), 0o600))
+
+		job, err := svc.RequestErasure(ctx, ErasureInput{UserID: 1, ActorID: 1, Password: "secret-pass-1"})
+		require.NoError(t, err)
+
+		job = waitJob(t, svc, ctx, 1, job.ID)
+		require.Equal(t, task.JobSucceeded, job.Status, job.Error)
+		userRepo.AssertExpectations(t)
+
+		assert.NoFileExists(t, mine)
+		assert.FileExists(t, other)
+		assert.Equal(t, []repository.User{active}, avatars.removed, "匿名化之前的头像文件一并删除")
+		assert.Equal(t, []audit.Event{{
+			TenantID:   2,
+			ActorID:    1,
+			Action:     AuditActionUserErase,
+			TargetType: AuditTargetUser,
+			TargetID:   1,
+		}}, auditor.events)
+	})
+
+	t.Run("管理员申请不需要密码", func(t *testing.T) {
+		userRepo := new(MockUserRepository)
+		userRepo.On("GetUserByIDAnyStatus", mock.Anything, int64(1)).Return(repository.User{ID: 1, Status: repository.UserStatusDeleted}, nil)
+		userRepo.On("AnonymizeUser", mock.Anything, int64(1)).Return(true, nil)
+		svc, _ := newTestPrivacyService(t, userRepo, new(MockAuditRepository), audit.NopRecorder{}, &stubAvatarService{})
+
+		job, err := svc.RequestErasure(ctx, ErasureInput{UserID: 1, ActorID: 7})
+		require.NoError(t, err)
+		assert.Equal(t, "2:7", job.Owner, "作业归属于提交者")
+
+		job = waitJob(t, svc, ctx, 7, job.ID)
+		assert.Equal(t, task.JobSucceeded, job.Status)
+		userRepo.AssertNotCalled(t, "GetUserPassword", mock.Anything, mock.Anything)
+	})
+
+	t.Run("已匿名的用户", func(t *testing.T) {
+		userRepo := new(MockUserRepository)
+		userRepo.On("GetUserByIDAnyStatus", ctx, int64(1)).Return(repository.User{ID: 1, Status: repository.UserStatusErased}, nil)
+		svc, _ := newTestPrivacyService(t, userRepo, new(MockAuditRepository), audit.NopRecorder{}, &stubAvatarService{})
+
+		_, err := svc.RequestErasure(ctx, ErasureInput{UserID: 1, ActorID: 7})
+		assert.ErrorIs(t, err, ErrInvalidUserTransition)
+	})
+}
@@ -0,0 +1,463 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"sort"
+	"time"
+
+	"gin_demo/internal/repository"
+	"gin_demo/internal/response"
+	"gin_demo/pkg/auth"
+	"gin_demo/pkg/metrics"
+)
+
+var (
+	// ErrRoleNotFound 角色不存在
+	ErrRoleNotFound = response.New(response.CodeNotFound, "角色不存在")
+	// ErrRoleExists 角色已存在
+	ErrRoleExists = response.New(response.CodeAlreadyExists, "角色已存在")
+	// ErrRoleInUse 仍有用户使用该角色
+	ErrRoleInUse = response.New(response.CodeAlreadyExists, "仍有用户使用该角色，请先修改这些用户的角色")
+	// ErrSystemRole 内置角色不能删除，超级管理员角色不能修改
+	ErrSystemRole = response.New(response.CodeForbidden, "内置角色不能删除，超级管理员角色不能修改")
+	// ErrInvalidRoleName 角色名称格式错误
+	ErrInvalidRoleName = response.New(response.CodeInvalidParams, "角色名称只能包含小写字母、数字和下划线，以字母开头，长度 2-32")
+	// ErrInvalidRoleLevel 角色级别超出范围
+	ErrInvalidRoleLevel = response.New(response.CodeInvalidParams, fmt.Sprintf("角色级别必须在 0-%d 之间", auth.SuperAdminLevel-1))
+	// ErrPermissionNotFound 权限不存在
+	ErrPermissionNotFound = response.New(response.CodeNotFound, "权限不存在")
+	// ErrPermissionExists 权限已存在
+	ErrPermissionExists = response.New(response.CodeAlreadyExists, "权限已存在")
+	// ErrSystemPermission 内置权限在代码中使用，不能删除
+	ErrSystemPermission = response.New(response.CodeForbidden, "内置权限不能删除")
+	// ErrInvalidPermissionName 权限名称格式错误
+	ErrInvalidPermissionName = response.New(response.CodeInvalidParams, "权限名称格式为 资源:操作（如 report:export），只能包含小写字母、数字和下划线")
+)
+
+var (
+	// roleNamePattern 角色名称（保存在 users.role，最长 32）
+	roleNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{1,31}$`)
+	// permissionNamePattern 权限名称（资源:操作）
+	permissionNamePattern = regexp.MustCompile(`^[a-z][a-z0-9_]{0,30}:[a-z][a-z0-9_]{0,30}$`)
+)
+
+// RoleInfo 角色信息
+type RoleInfo struct {
+	Name        auth.Role
+	Description string
+	Level       int
+	System      bool
+	Permissions []auth.Permission
+	CreatedAt   time.Time
+	UpdatedAt   time.Time
+}
+
+// PermissionInfo 权限信息
+type PermissionInfo struct {
+	Name        auth.Permission
+	Description string
+	System      bool
+	CreatedAt   time.Time
+}
+
+// RoleInput 创建或更新角色的输入（更新时 Name 不可修改，忽略该字段）
+type RoleInput struct {
+	Name        auth.Role
+	Description string
+	Level       int
+	Permissions []auth.Permission
+}
+
+// PermissionInput 创建权限的输入
+type PermissionInput struct {
+	Name        auth.Permission
+	Description string
+}
+
+// RoleService 角色权限管理接口
+//
+// 角色和权限保存在数据库中，修改后立即替换本实例的策略（auth.SetActivePolicy），
+// 其他实例由 auth.PolicyManager 定期加载。
+type RoleService interface {
+	// ListRoles 列出所有角色（按级别从高到低）
+	ListRoles(ctx context.Context) ([]RoleInfo, error)
+
+	// GetRole 查询角色
+	GetRole(ctx context.Context, name auth.Role) (RoleInfo, error)
+
+	// CreateRole 创建角色
+	CreateRole(ctx context.Context, input RoleInput) (RoleInfo, error)
+
+	// UpdateRole 更新角色的描述、级别和权限（权限整体替换）
+	UpdateRole(ctx context.Context, name auth.Role, input RoleInput) (RoleInfo, error)
+
+	// DeleteRole 删除角色（内置角色和仍在使用的角色不能删除）
+	DeleteRole(ctx context.Context, name auth.Role) error
+
+	// ListPermissions 列出所有权限
+	ListPermissions(ctx context.Context) ([]PermissionInfo, error)
+
+	// CreatePermission 创建权限
+	CreatePermission(ctx context.Context, input PermissionInput) (PermissionInfo, error)
+
+	// DeletePermission 删除权限（从所有角色中移除；内置权限不能删除）
+	DeletePermission(ctx context.Context, name auth.Permission) error
+
+	// LoadPolicy 从数据库加载角色权限策略（auth.PolicyLoader）
+	LoadPolicy(ctx context.Context) (*auth.Policy, error)
+}
+
+// roleService 角色权限管理实现
+type roleService struct {
+	roleRepo repository.RoleRepositoryInterface
+}
+
+// NewRoleService 创建角色权限管理服务实例
+func NewRoleService(roleRepo repository.RoleRepositoryInterface) RoleService {
+	return &roleService{
+		roleRepo: roleRepo,
+	}
+}
+
+// ListRoles 列出所有角色
+func (s *roleService) ListRoles(ctx context.Context) ([]RoleInfo, error) {
+	roles, err := s.roleRepo.ListRoles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("service: list roles: %w", err)
+	}
+
+	permissions, err := s.roleRepo.ListRolePermissions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("service: list role permissions: %w", err)
+	}
+
+	result := make([]RoleInfo, len(roles))
+	for i, role := range roles {
+		result[i] = toRoleInfo(role, permissions[role.Name])
+	}
+	return result, nil
+}
+
+// GetRole 查询角色
+func (s *roleService) GetRole(ctx context.Context, name auth.Role) (RoleInfo, error) {
+	role, err := s.getRole(ctx, name)
+	if err != nil {
+		return RoleInfo{}, err
+	}
+
+	permissions, err := s.roleRepo.ListRolePermissions(ctx)
+	if err != nil {
+		return RoleInfo{}, fmt.Errorf("service: list role permissions: %w", err)
+	}
+
+	return toRoleInfo(role, permissions[role.Name]), nil
+}
+
+// CreateRole 创建角色
+func (s *roleService) CreateRole(ctx context.Context, input RoleInput) (RoleInfo, error) {
+	// 1. 参数验证
+	if !roleNamePattern.MatchString(string(input.Name)) {
+		return RoleInfo{}, ErrInvalidRoleName
+	}
+	if input.Level < 0 || input.Level >= auth.SuperAdminLevel {
+		return RoleInfo{}, ErrInvalidRoleLevel
+	}
+	permissions, err := s.normalizePermissions(ctx, input.Permissions)
+	if err != nil {
+		return RoleInfo{}, err
+	}
+
+	// 2. 检查是否已存在
+	if _, err := s.roleRepo.GetRoleByName(ctx, string(input.Name)); err == nil {
+		return RoleInfo{}, ErrRoleExists
+	} else if !errors.Is(err, sql.ErrNoRows) {
+		return RoleInfo{}, fmt.Errorf("service: get role: %w", err)
+	}
+
+	// 3. 创建
+	if _, err := s.roleRepo.CreateRole(ctx, repository.CreateRoleParams{
+		Name:        string(input.Name),
+		Description: input.Description,
+		Level:       int32(input.Level),
+	}, permissions); err != nil {
+		metrics.RecordUserOperation("create_role", false)
+		return RoleInfo{}, fmt.Errorf("service: create role: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Role created", "role", input.Name, "level", input.Level, "permissions", permissions)
+	metrics.RecordUserOperation("create_role", true)
+	s.reloadPolicy(ctx)
+
+	return s.GetRole(ctx, input.Name)
+}
+
+// UpdateRole 更新角色
+func (s *roleService) UpdateRole(ctx context.Context, name auth.Role, input RoleInput) (RoleInfo, error) {
+	// 超级管理员隐式拥有所有权限，修改没有意义，还可能导致无人能管理角色
+	if name == auth.RoleSuperAdmin {
+		return RoleInfo{}, ErrSystemRole
+	}
+	if input.Level < 0 || input.Level >= auth.SuperAdminLevel {
+		return RoleInfo{}, ErrInvalidRoleLevel
+	}
+	permissions, err := s.normalizePermissions(ctx, input.Permissions)
+	if err != nil {
+		return RoleInfo{}, err
+	}
+
+	role, err := s.getRole(ctx, name)
+	if err != nil {
+		return RoleInfo{}, err
+	}
+
+	if err := s.roleRepo.UpdateRole(ctx, repository.UpdateRoleParams{
+		Description: input.Description,
+		Level:       int32(input.Level),
+		ID:          role.ID,
+	}, permissions); err != nil {
+		metrics.RecordUserOperation("update_role", false)
+		return RoleInfo{}, fmt.Errorf("service: update role: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Role updated", "role", name, "level", input.Level, "permissions", permissions)
+	metrics.RecordUserOperation("update_role", true)
+	s.reloadPolicy(ctx)
+
+	return s.GetRole(ctx, name)
+}
+
+// DeleteRole 删除角色
+func (s *roleService) DeleteRole(ctx context.Context, name auth.Role) error {
+	role, err := s.getRole(ctx, name)
+	if err != nil {
+		return err
+	}
+	if role.IsSystem {
+		return ErrSystemRole
+	}
+
+	// 删除仍在使用的角色会让这些用户失去所有权限，要求先迁移用户
+	count, err := s.roleRepo.CountUsersByRole(ctx, role.Name)
+	if err != nil {
+		return fmt.Errorf("service: count users by role: %w", err)
+	}
+	if count > 0 {
+		return ErrRoleInUse
+	}
+
+	deleted, err := s.roleRepo.DeleteRole(ctx, role.ID)
+	if err != nil {
+		metrics.RecordUserOperation("delete_role", false)
+		return fmt.Errorf("service: delete role: %w", err)
+	}
+	if !deleted {
+		return ErrRoleNotFound
+	}
+
+	slog.InfoContext(ctx, "Role deleted", "role", name)
+	metrics.RecordUserOperation("delete_role", true)
+	s.reloadPolicy(ctx)
+
+	return nil
+}
+
+// ListPermissions 列出所有权限
+func (s *roleService) ListPermissions(ctx context.Context) ([]PermissionInfo, error) {
+	permissions, err := s.roleRepo.ListPermissions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("service: list permissions: %w", err)
+	}
+
+	result := make([]PermissionInfo, len(permissions))
+	for i, p := range permissions {
+		result[i] = toPermissionInfo(p)
+	}
+	return result, nil
+}
+
+// CreatePermission 创建权限
+func (s *roleService) CreatePermission(ctx context.Context, input PermissionInput) (PermissionInfo, error) {
+	if !permissionNamePattern.MatchString(string(input.Name)) {
+		return PermissionInfo{}, ErrInvalidPermissionName
+	}
+
+	existing, err := s.roleRepo.ListPermissions(ctx)
+	if err != nil {
+		return PermissionInfo{}, fmt.Errorf("service: list permissions: %w", err)
+	}
+	for _, p := range existing {
+		if p.Name == string(input.Name) {
+			return PermissionInfo{}, ErrPermissionExists
+		}
+	}
+
+	if _, err := s.roleRepo.CreatePermission(ctx, repository.CreatePermissionParams{
+		Name:        string(input.Name),
+		Description: input.Description,
+	}); err != nil {
+		return PermissionInfo{}, fmt.Errorf("service: create permission: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Permission created", "permission", input.Name)
+	s.reloadPolicy(ctx)
+
+	return PermissionInfo{
+		Name:        input.Name,
+		Description: input.Description,
+		CreatedAt:   time.Now(),
+	}, nil
+}
+
+// DeletePermission 删除权限
+func (s *roleService) DeletePermission(ctx context.Context, name auth.Permission) error {
+	permissions, err := s.roleRepo.ListPermissions(ctx)
+	if err != nil {
+		return fmt.Errorf("service: list permissions: %w", err)
+	}
+
+	var found *repository.Permission
+	for i := range permissions {
+		if permissions[i].Name == string(name) {
+			found = &permissions[i]
+			break
+		}
+	}
+	if found == nil {
+		return ErrPermissionNotFound
+	}
+	if found.IsSystem {
+		return ErrSystemPermission
+	}
+
+	deleted, err := s.roleRepo.DeletePermission(ctx, string(name))
+	if err != nil {
+		return fmt.Errorf("service: delete permission: %w", err)
+	}
+	if !deleted {
+		return ErrPermissionNotFound
+	}
+
+	slog.InfoContext(ctx, "Permission deleted", "permission", name)
+	s.reloadPolicy(ctx)
+
+	return nil
+}
+
+// LoadPolicy 从数据库加载角色权限策略
+func (s *roleService) LoadPolicy(ctx context.Context) (*auth.Policy, error) {
+	permissions, err := s.roleRepo.ListPermissions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("service: list permissions: %w", err)
+	}
+	roles, err := s.roleRepo.ListRoles(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("service: list roles: %w", err)
+	}
+	rolePermissions, err := s.roleRepo.ListRolePermissions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("service: list role permissions: %w", err)
+	}
+
+	perms := make([]auth.Permission, len(permissions))
+	for i, p := range permissions {
+		perms[i] = auth.Permission(p.Name)
+	}
+
+	defs := make([]auth.RoleDefinition, len(roles))
+	for i, role := range roles {
+		defs[i] = auth.RoleDefinition{
+			Name:        auth.Role(role.Name),
+			Level:       int(role.Level),
+			Permissions: toRawPermissions(rolePermissions[role.Name]),
+		}
+	}
+
+	return auth.NewPolicy(perms, defs)
+}
+
+// reloadPolicy 写操作成功后立即替换本实例的策略（失败时等待定期加载）
+func (s *roleService) reloadPolicy(ctx context.Context) {
+	policy, err := s.LoadPolicy(ctx)
+	if err != nil {
+		slog.WarnContext(ctx, "Failed to reload policy after change", "error", err)
+		return
+	}
+	auth.SetActivePolicy(policy)
+}
+
+// getRole 查询角色（不存在时返回 ErrRoleNotFound）
+func (s *roleService) getRole(ctx context.Context, name auth.Role) (repository.Role, error) {
+	role, err := s.roleRepo.GetRoleByName(ctx, string(name))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return repository.Role{}, ErrRoleNotFound
+		}
+		return repository.Role{}, fmt.Errorf("service: get role: %w", err)
+	}
+	return role, nil
+}
+
+// normalizePermissions 去重、排序，并检查权限是否存在（以数据库为准，不依赖本实例的策略是否最新）
+func (s *roleService) normalizePermissions(ctx context.Context, permissions []auth.Permission) ([]string, error) {
+	existing, err := s.roleRepo.ListPermissions(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("service: list permissions: %w", err)
+	}
+	known := make(map[string]struct{}, len(existing))
+	for _, p := range existing {
+		known[p.Name] = struct{}{}
+	}
+
+	seen := make(map[string]struct{}, len(permissions))
+	result := make([]string, 0, len(permissions))
+	for _, p := range permissions {
+		name := string(p)
+		if _, ok := known[name]; !ok {
+			return nil, response.New(response.CodeInvalidParams, "无效的权限: "+name)
+		}
+		if _, ok := seen[name]; ok {
+			continue
+		}
+		seen[name] = struct{}{}
+		result = append(result, name)
+	}
+	sort.Strings(result)
+	return result, nil
+}
+
+// toRoleInfo 转换为角色信息
+func toRoleInfo(role repository.Role, permissions []string) RoleInfo {
+	return RoleInfo{
+		Name:        auth.Role(role.Name),
+		Description: role.Description,
+		Level:       int(role.Level),
+		System:      role.IsSystem,
+		Permissions: toRawPermissions(permissions),
+		CreatedAt:   role.CreatedAt,
+		UpdatedAt:   role.UpdatedAt,
+	}
+}
+
+// toPermissionInfo 转换为权限信息
+func toPermissionInfo(p repository.Permission) PermissionInfo {
+	return PermissionInfo{
+		Name:        auth.Permission(p.Name),
+		Description: p.Description,
+		System:      p.IsSystem,
+		CreatedAt:   p.CreatedAt,
+	}
+}
+
+// toRawPermissions 字符串转换为权限（不经过当前策略过滤，用于构建新策略）
+func toRawPermissions(values []string) []auth.Permission {
+	permissions := make([]auth.Permission, len(values))
+	for i, v := range values {
+		permissions[i] = auth.Permission(v)
+	}
+	return permissions
+}
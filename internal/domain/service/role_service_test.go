@@ -0,0 +1,203 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	"gin_demo/internal/repository"
+	"gin_demo/pkg/auth"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockRoleRepository 是 RoleRepository 的 mock 实现
+type MockRoleRepository struct {
+	mock.Mock
+}
+
+func (m *MockRoleRepository) ListRoles(ctx context.Context) ([]repository.Role, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]repository.Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) GetRoleByName(ctx context.Context, name string) (repository.Role, error) {
+	args := m.Called(ctx, name)
+	return args.Get(0).(repository.Role), args.Error(1)
+}
+
+func (m *MockRoleRepository) ListPermissions(ctx context.Context) ([]repository.Permission, error) {
+	args := m.Called(ctx)
+	return args.Get(0).([]repository.Permission), args.Error(1)
+}
+
+func (m *MockRoleRepository) ListRolePermissions(ctx context.Context) (map[string][]string, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(map[string][]string), args.Error(1)
+}
+
+func (m *MockRoleRepository) CountUsersByRole(ctx context.Context, role string) (int64, error) {
+	args := m.Called(ctx, role)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRoleRepository) CreateRole(ctx context.Context, params repository.CreateRoleParams, permissions []string) (int64, error) {
+	args := m.Called(ctx, params, permissions)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRoleRepository) UpdateRole(ctx context.Context, params repository.UpdateRoleParams, permissions []string) error {
+	args := m.Called(ctx, params, permissions)
+	return args.Error(0)
+}
+
+func (m *MockRoleRepository) DeleteRole(ctx context.Context, roleID int64) (bool, error) {
+	args := m.Called(ctx, roleID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockRoleRepository) CreatePermission(ctx context.Context, params repository.CreatePermissionParams) (int64, error) {
+	args := m.Called(ctx, params)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockRoleRepository) DeletePermission(ctx context.Context, name string) (bool, error) {
+	args := m.Called(ctx, name)
+	return args.Bool(0), args.Error(1)
+}
+
+// setupRoleService 创建角色服务，数据库中已有内置角色 super_admin、user 和两个权限
+func setupRoleService(t *testing.T) (RoleService, *MockRoleRepository) {
+	t.Cleanup(func() { auth.SetActivePolicy(nil) })
+
+	repo := new(MockRoleRepository)
+	repo.On("ListPermissions", mock.Anything).Return([]repository.Permission{
+		{ID: 1, Name: "report:export"},
+		{ID: 2, Name: "user:read", IsSystem: true},
+	}, nil)
+	return NewRoleService(repo), repo
+}
+
+// TestRoleService_CreateRole 测试创建角色
+func TestRoleService_CreateRole(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("创建成功后立即生效", func(t *testing.T) {
+		svc, repo := setupRoleService(t)
+
+		repo.On("GetRoleByName", ctx, "auditor").Return(repository.Role{}, sql.ErrNoRows).Once()
+		repo.On("CreateRole", ctx, repository.CreateRoleParams{Name: "auditor", Description: "审计", Level: 50},
+			[]string{"report:export", "user:read"}).Return(int64(3), nil)
+
+		// 创建后重新加载策略
+		auditor := repository.Role{ID: 3, Name: "auditor", Description: "审计", Level: 50}
+		repo.On("ListRoles", ctx).Return([]repository.Role{
+			{ID: 1, Name: "super_admin", Level: 100, IsSystem: true},
+			auditor,
+		}, nil)
+		repo.On("ListRolePermissions", ctx).Return(map[string][]string{
+			"auditor": {"report:export", "user:read"},
+		}, nil)
+		repo.On("GetRoleByName", ctx, "auditor").Return(auditor, nil)
+
+		role, err := svc.CreateRole(ctx, RoleInput{
+			Name:        "auditor",
+			Description: "审计",
+			Level:       50,
+			Permissions: []auth.Permission{"user:read", "report:export", "user:read"},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, []auth.Permission{"report:export", "user:read"}, role.Permissions)
+
+		claims := &auth.RBACClaims{UserID: 1, Role: "auditor"}
+		assert.True(t, auth.Role("auditor").IsValid())
+		assert.True(t, claims.HasPermission("report:export"))
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("角色已存在", func(t *testing.T) {
+		svc, repo := setupRoleService(t)
+		repo.On("GetRoleByName", ctx, "auditor").Return(repository.Role{ID: 3, Name: "auditor"}, nil)
+
+		_, err := svc.CreateRole(ctx, RoleInput{Name: "auditor", Level: 50})
+		assert.ErrorIs(t, err, ErrRoleExists)
+	})
+
+	t.Run("权限不存在", func(t *testing.T) {
+		svc, repo := setupRoleService(t)
+
+		_, err := svc.CreateRole(ctx, RoleInput{Name: "auditor", Level: 50, Permissions: []auth.Permission{"report:delete"}})
+		assert.Error(t, err)
+		repo.AssertNotCalled(t, "CreateRole", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("名称和级别校验", func(t *testing.T) {
+		svc, _ := setupRoleService(t)
+
+		_, err := svc.CreateRole(ctx, RoleInput{Name: "Auditor", Level: 50})
+		assert.ErrorIs(t, err, ErrInvalidRoleName)
+
+		_, err = svc.CreateRole(ctx, RoleInput{Name: "auditor", Level: auth.SuperAdminLevel})
+		assert.ErrorIs(t, err, ErrInvalidRoleLevel)
+	})
+}
+
+// TestRoleService_UpdateDeleteRole 测试修改和删除角色的限制
+func TestRoleService_UpdateDeleteRole(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("超级管理员角色不能修改", func(t *testing.T) {
+		svc, repo := setupRoleService(t)
+
+		_, err := svc.UpdateRole(ctx, auth.RoleSuperAdmin, RoleInput{Level: 50})
+		assert.ErrorIs(t, err, ErrSystemRole)
+		repo.AssertNotCalled(t, "UpdateRole", mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("内置角色不能删除", func(t *testing.T) {
+		svc, repo := setupRoleService(t)
+		repo.On("GetRoleByName", ctx, "user").Return(repository.Role{ID: 2, Name: "user", IsSystem: true}, nil)
+
+		assert.ErrorIs(t, svc.DeleteRole(ctx, auth.RoleUser), ErrSystemRole)
+	})
+
+	t.Run("仍有用户使用的角色不能删除", func(t *testing.T) {
+		svc, repo := setupRoleService(t)
+		repo.On("GetRoleByName", ctx, "auditor").Return(repository.Role{ID: 3, Name: "auditor"}, nil)
+		repo.On("CountUsersByRole", ctx, "auditor").Return(int64(2), nil)
+
+		assert.ErrorIs(t, svc.DeleteRole(ctx, "auditor"), ErrRoleInUse)
+		repo.AssertNotCalled(t, "DeleteRole", mock.Anything, mock.Anything)
+	})
+
+	t.Run("角色不存在", func(t *testing.T) {
+		svc, repo := setupRoleService(t)
+		repo.On("GetRoleByName", ctx, "nobody").Return(repository.Role{}, sql.ErrNoRows)
+
+		assert.ErrorIs(t, svc.DeleteRole(ctx, "nobody"), ErrRoleNotFound)
+	})
+
+	t.Run("内置权限不能删除", func(t *testing.T) {
+		svc, _ := setupRoleService(t)
+
+		assert.ErrorIs(t, svc.DeletePermission(ctx, auth.PermissionUserRead), ErrSystemPermission)
+		assert.ErrorIs(t, svc.DeletePermission(ctx, "report:delete"), ErrPermissionNotFound)
+	})
+}
+
+// TestRoleService_LoadPolicy 测试从数据库加载策略
+func TestRoleService_LoadPolicy(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("数据不一致时加载失败", func(t *testing.T) {
+		svc, repo := setupRoleService(t)
+		// 缺少 super_admin
+		repo.On("ListRoles", ctx).Return([]repository.Role{{ID: 2, Name: "user", Level: 40}}, nil)
+		repo.On("ListRolePermissions", ctx).Return(map[string][]string{}, nil)
+
+		_, err := svc.LoadPolicy(ctx)
+		assert.Error(t, err)
+	})
+}
@@ -0,0 +1,85 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"gin_demo/internal/repository"
+	"gin_demo/internal/response"
+)
+
+var (
+	// ErrTenantNotFound 租户不存在
+	ErrTenantNotFound = response.New(response.CodeNotFound, "租户不存在")
+	// ErrTenantDisabled 租户已停用
+	ErrTenantDisabled = response.New(response.CodeForbidden, "租户已停用")
+)
+
+// TenantService 租户业务逻辑接口
+type TenantService interface {
+	// Resolve 通过标识查找租户，返回租户 ID（租户不存在或已停用时返回错误）
+	Resolve(ctx context.Context, slug string) (int64, error)
+}
+
+// tenantEntry 租户缓存项
+type tenantEntry struct {
+	id        int64
+	status    int16
+	expiresAt time.Time
+}
+
+// tenantService 租户业务逻辑实现
+//
+// 每个请求都要解析租户，查询结果在进程内缓存 cacheTTL（停用租户最迟在 cacheTTL 后生效）。
+// 不存在的标识不缓存，避免随意构造的子域名占满缓存。
+type tenantService struct {
+	tenantRepo repository.TenantRepositoryInterface
+	cacheTTL   time.Duration
+
+	mu      sync.RWMutex
+	entries map[string]tenantEntry
+}
+
+// NewTenantService 创建租户服务实例
+func NewTenantService(tenantRepo repository.TenantRepositoryInterface, cacheTTL time.Duration) TenantService {
+	return &tenantService{
+		tenantRepo: tenantRepo,
+		cacheTTL:   cacheTTL,
+		entries:    make(map[string]tenantEntry),
+	}
+}
+
+// Resolve 通过标识查找租户
+func (s *tenantService) Resolve(ctx context.Context, slug string) (int64, error) {
+	now := time.Now()
+
+	s.mu.RLock()
+	entry, ok := s.entries[slug]
+	s.mu.RUnlock()
+
+	if !ok || now.After(entry.expiresAt) {
+		t, err := s.tenantRepo.GetTenantBySlug(ctx, slug)
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return 0, ErrTenantNotFound
+			}
+			return 0, fmt.Errorf("service: get tenant: %w", err)
+		}
+
+		entry = tenantEntry{id: t.ID, status: t.Status, expiresAt: now.Add(s.cacheTTL)}
+		if s.cacheTTL > 0 {
+			s.mu.Lock()
+			s.entries[slug] = entry
+			s.mu.Unlock()
+		}
+	}
+
+	if entry.status != repository.TenantStatusActive {
+		return 0, ErrTenantDisabled
+	}
+	return entry.id, nil
+}
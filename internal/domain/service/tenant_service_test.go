@@ -0,0 +1,70 @@
+package service
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+	"time"
+
+	"gin_demo/internal/repository"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+// MockTenantRepository 是 TenantRepository 的 mock 实现
+type MockTenantRepository struct {
+	mock.Mock
+}
+
+func (m *MockTenantRepository) GetTenantByID(ctx context.Context, id int64) (repository.Tenant, error) {
+	args := m.Called(ctx, id)
+	return args.Get(0).(repository.Tenant), args.Error(1)
+}
+
+func (m *MockTenantRepository) GetTenantBySlug(ctx context.Context, slug string) (repository.Tenant, error) {
+	args := m.Called(ctx, slug)
+	return args.Get(0).(repository.Tenant), args.Error(1)
+}
+
+// TestTenantService_Resolve 测试租户解析
+func TestTenantService_Resolve(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("结果在进程内缓存", func(t *testing.T) {
+		repo := new(MockTenantRepository)
+		repo.On("GetTenantBySlug", ctx, "acme").
+			Return(repository.Tenant{ID: 2, Slug: "acme", Status: repository.TenantStatusActive}, nil).Once()
+		svc := NewTenantService(repo, time.Minute)
+
+		for i := 0; i < 3; i++ {
+			id, err := svc.Resolve(ctx, "acme")
+			require.NoError(t, err)
+			assert.Equal(t, int64(2), id)
+		}
+		repo.AssertExpectations(t)
+	})
+
+	t.Run("租户已停用", func(t *testing.T) {
+		repo := new(MockTenantRepository)
+		repo.On("GetTenantBySlug", ctx, "acme").
+			Return(repository.Tenant{ID: 2, Slug: "acme", Status: repository.TenantStatusDisabled}, nil)
+		svc := NewTenantService(repo, time.Minute)
+
+		_, err := svc.Resolve(ctx, "acme")
+		assert.ErrorIs(t, err, ErrTenantDisabled)
+	})
+
+	t.Run("租户不存在时不缓存", func(t *testing.T) {
+		repo := new(MockTenantRepository)
+		repo.On("GetTenantBySlug", ctx, "nobody").Return(repository.Tenant{}, sql.ErrNoRows).Twice()
+		svc := NewTenantService(repo, time.Minute)
+
+		_, err := svc.Resolve(ctx, "nobody")
+		assert.ErrorIs(t, err, ErrTenantNotFound)
+		_, err = svc.Resolve(ctx, "nobody")
+		assert.ErrorIs(t, err, ErrTenantNotFound)
+		repo.AssertExpectations(t)
+	})
+}
@@ -0,0 +1,926 @@
+package service
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gin_demo/internal/repository"
+	"gin_demo/internal/response"
+	"gin_demo/pkg/audit"
+	pkgerrors "gin_demo/pkg/errors"
+	"gin_demo/pkg/metrics"
+	"gin_demo/pkg/task"
+	"gin_demo/pkg/tenant"
+
+	"github.com/go-playground/validator/v10"
+)
+
+// JobTypeUserImport 后台作业类型：批量导入用户
+const JobTypeUserImport = "user.import"
+
+// 批量导入导出的文件格式
+const (
+	TransferFormatCSV   = "csv"   // 第一行为表头
+	TransferFormatJSONL = "jsonl" // 每行一个 JSON 对象
+)
+
+const (
+	// exportPageSize 导出时每次查询的用户数
+	exportPageSize = 500
+	// importProgressInterval 导入时每处理多少行保存一次进度
+	importProgressInterval = 100
+	// maxImportLineSize JSONL 单行的最大长度
+	maxImportLineSize = 64 * 1024
+)
+
+var (
+	// ErrTransferFormat 不支持的导入导出格式
+	ErrTransferFormat = response.New(response.CodeInvalidParams, "不支持的文件格式")
+	// ErrImportEmpty 导入文件为空
+	ErrImportEmpty = response.New(response.CodeInvalidParams, "导入文件为空")
+	// ErrImportHeader CSV 表头缺少必需的列或包含未知的列
+	ErrImportHeader = response.New(response.CodeInvalidParams, "CSV 表头必须包含 username 和 email 列（password 列可选）")
+	// ErrImportNotReady 导入作业尚未完成或执行失败
+	ErrImportNotReady = response.New(response.CodeInvalidState, "导入尚未完成")
+	// ErrImportReportExpired 错误报告已过期删除
+	ErrImportReportExpired = response.New(response.CodeNotFound, "错误报告已过期")
+)
+
+// UserImportConfig 批量导入导出配置
+type UserImportConfig struct {
+	Dir string // 上传文件和错误报告的保存目录（过期文件由清理任务删除）
+}
+
+// ImportRow 导入文件中的一行
+//
+// 校验规则与注册接口的 RegisterRequest 相同，只是密码可以为空：为空时创建无密码账户并发送邀请邮件。
+type ImportRow struct {
+	Username string `json:"username" binding:"required,min=3,max=50"`
+	Email    string `json:"email" binding:"required,email"`
+	Password string `json:"password" binding:"max=1024"` // 长度和复杂度由密码策略校验
+}
+
+// ImportInput 批量导入参数
+type ImportInput struct {
+	ActorID int64     // 提交导入的管理员（作业归属于提交者，注册的审计日志记录为该管理员的操作）
+	Format  string    // csv 或 jsonl
+	Source  io.Reader // 文件内容（流式写入磁盘，不读入内存）
+}
+
+// ImportResult 导入作业的结果（保存在 Job.Result 中）
+type ImportResult struct {
+	Format     string `json:"format"`
+	Total      int64  `json:"total"`                 // 数据行数
+	Created    int64  `json:"created"`               // 创建的用户数
+	Invited    int64  `json:"invited"`               // 其中没有密码、发送邀请邮件的用户数
+	Failed     int64  `json:"failed"`                // 失败的行数（原因见错误报告）
+	MailFailed int64  `json:"mail_failed,omitempty"` // 已创建但邮件发送失败的用户数（可以通过忘记密码或重新发送验证邮件补发）
+}
+
+// ExportedUser 导出的用户（不包含密码哈希等凭据）
+type ExportedUser struct {
+	ID        int64     `json:"id"`
+	Username  string    `json:"username"`
+	Email     string    `json:"email"`
+	Avatar    string    `json:"avatar,omitempty"`
+	Role      string    `json:"role"`
+	Status    int16     `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// exportColumns 导出文件的列（顺序与 CSV 表头一致）
+var exportColumns = []string{"id", "username", "email", "avatar", "role", "status", "created_at", "updated_at"}
+
+// UserImportService 用户批量导入与导出业务逻辑接口
+type UserImportService interface {
+	// RequestImport 保存上传的文件并提交导入作业（文件格式和 CSV 表头在提交前校验）
+	RequestImport(ctx context.Context, input ImportInput) (task.Job, error)
+
+	// GetImportJob 查询管理员提交的导入作业
+	GetImportJob(ctx context.Context, actorID int64, jobID string) (task.Job, error)
+
+	// GetImportReport 获取已完成的导入作业的错误报告（CSV：行号、用户名、邮箱、原因）
+	GetImportReport(ctx context.Context, actorID int64, jobID string) (ExportFile, error)
+
+	// ExportUsers 按过滤条件导出用户列表，逐页查询并写入 w，返回导出的用户数
+	//
+	// 按创建时间排序（filter.Sort 被忽略），任何时候内存中只有一页数据。
+	ExportUsers(ctx context.Context, filter repository.UserFilter, format string, w io.Writer) (int64, error)
+}
+
+// importPayload 导入作业参数
+type importPayload struct {
+	TenantID int64  `json:"tenant_id"`
+	ActorID  int64  `json:"actor_id"`
+	Format   string `json:"format"`
+	Upload   string `json:"upload"` // 上传文件名（位于 imports 目录）
+}
+
+// userImportService 用户批量导入与导出业务逻辑实现
+type userImportService struct {
+	users    UserService
+	accounts AccountService
+	auditor  audit.Recorder
+	jobs     *task.JobQueue
+	config   UserImportConfig
+}
+
+// NewUserImportService 创建用户批量导入与导出服务实例（在作业队列中注册导入作业）
+func NewUserImportService(
+	users UserService,
+	accounts AccountService,
+	auditor audit.Recorder,
+	jobs *task.JobQueue,
+	config UserImportConfig,
+) (UserImportService, error) {
+	s := &userImportService{
+		users:    users,
+		accounts: accounts,
+		auditor:  auditor,
+		jobs:     jobs,
+		config:   config,
+	}
+
+	if err := jobs.Handle(JobTypeUserImport, s.runImport); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// RequestImport 保存上传的文件并提交导入作业
+func (s *userImportService) RequestImport(ctx context.Context, input ImportInput) (task.Job, error) {
+	if input.Format != TransferFormatCSV && input.Format != TransferFormatJSONL {
+		return task.Job{}, ErrTransferFormat
+	}
+
+	// 1. 写入磁盘（读取请求体超过限制时返回的错误保留在错误链中，Handler 据此返回 413）
+	upload, err := s.saveUpload(input.Source, input.Format)
+	if err != nil {
+		return task.Job{}, err
+	}
+
+	// 2. 提交前检查文件不为空、CSV 表头有效，格式错误立即返回
+	if err := checkImportFile(upload, input.Format); err != nil {
+		os.Remove(upload)
+		return task.Job{}, err
+	}
+
+	// 3. 提交作业
+	tenantID := tenant.ID(ctx)
+	job, err := s.jobs.Submit(ctx, JobTypeUserImport, jobOwner(tenantID, input.ActorID),
+		importPayload{TenantID: tenantID, ActorID: input.ActorID, Format: input.Format, Upload: filepath.Base(upload)},
+		task.SubmitOptions{},
+	)
+	if err != nil {
+		os.Remove(upload)
+		metrics.RecordUserOperation("import", false)
+		return task.Job{}, fmt.Errorf("service: submit import job: %w", err)
+	}
+
+	s.auditor.Record(ctx, audit.Event{
+		Action:     AuditActionUserImport,
+		TargetType: AuditTargetUser,
+		Changes:    audit.Diff(nil, map[string]any{"format": input.Format, "job_id": job.ID}),
+	})
+	slog.InfoContext(ctx, "User import requested", "actor_id", input.ActorID, "job_id", job.ID, "format", input.Format)
+	return job, nil
+}
+
+// saveUpload 把上传的内容写入 imports 目录，返回文件路径
+func (s *userImportService) saveUpload(src io.Reader, format string) (string, error) {
+	dir := filepath.Join(s.config.Dir, "imports")
+	if err := os.MkdirAll(dir, 0o700); err != nil {
+		return "", fmt.Errorf("service: create import dir: %w", err)
+	}
+
+	file, err := os.CreateTemp(dir, "upload-*."+format)
+	if err != nil {
+		return "", fmt.Errorf("service: create upload file: %w", err)
+	}
+	if _, err := io.Copy(file, src); err != nil {
+		file.Close()
+		os.Remove(file.Name())
+		return "", fmt.Errorf("service: save upload: %w", err)
+	}
+	if err := file.Close(); err != nil {
+		os.Remove(file.Name())
+		return "", fmt.Errorf("service: close upload file: %w", err)
+	}
+	return file.Name(), nil
+}
+
+// GetImportJob 查询管理员提交的导入作业
+func (s *userImportService) GetImportJob(ctx context.Context, actorID int64, jobID string) (task.Job, error) {
+	job, err := s.jobs.Get(ctx, jobID)
+	if err != nil {
+		if errors.Is(err, task.ErrJobNotFound) {
+			return task.Job{}, ErrJobNotFound
+		}
+		return task.Job{}, fmt.Errorf("service: get job: %w", err)
+	}
+	// 其他管理员的作业和其他类型的作业同样返回不存在
+	if job.Owner != jobOwner(tenant.ID(ctx), actorID) || job.Type != JobTypeUserImport {
+		return task.Job{}, ErrJobNotFound
+	}
+	return job, nil
+}
+
+// GetImportReport 获取错误报告
+func (s *userImportService) GetImportReport(ctx context.Context, actorID int64, jobID string) (ExportFile, error) {
+	job, err := s.GetImportJob(ctx, actorID, jobID)
+	if err != nil {
+		return ExportFile{}, err
+	}
+	if job.Status != task.JobSucceeded {
+		return ExportFile{}, ErrImportNotReady
+	}
+
+	var payload importPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return ExportFile{}, fmt.Errorf("service: decode import job: %w", err)
+	}
+
+	path := s.reportPath(payload, job.ID)
+	if _, err := os.Stat(path); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return ExportFile{}, ErrImportReportExpired
+		}
+		return ExportFile{}, fmt.Errorf("service: stat import report: %w", err)
+	}
+
+	name := fmt.Sprintf("user-import-%s-errors.csv", job.CreatedAt.Format("20060102"))
+	return ExportFile{Path: path, Name: name}, nil
+}
+
+// runImport 执行导入作业
+//
+// 逐行注册用户，单行失败（格式错误、校验失败、用户已存在等）写入错误报告后继续处理下一行。
+func (s *userImportService) runImport(ctx context.Context, job task.Job, progress task.ProgressFunc) (any, error) {
+	var payload importPayload
+	if err := json.Unmarshal(job.Payload, &payload); err != nil {
+		return nil, fmt.Errorf("decode payload: %w", err)
+	}
+	ctx = tenant.WithID(ctx, payload.TenantID)
+	ctx = audit.WithActor(ctx, audit.Actor{UserID: payload.ActorID})
+
+	// 上传的文件可能包含明文密码，无论成功与否处理后立即删除
+	upload := filepath.Join(s.config.Dir, "imports", payload.Upload)
+	defer os.Remove(upload)
+
+	// 先数出总行数用于报告进度
+	total, err := countImportRows(upload, payload.Format)
+	if err != nil {
+		metrics.RecordUserOperation("import", false)
+		return nil, err
+	}
+	progress(0, total)
+
+	file, err := os.Open(upload)
+	if err != nil {
+		metrics.RecordUserOperation("import", false)
+		return nil, fmt.Errorf("open upload: %w", err)
+	}
+	defer file.Close()
+
+	reader, err := newImportReader(file, payload.Format)
+	if err != nil {
+		metrics.RecordUserOperation("import", false)
+		return nil, err
+	}
+
+	report, err := newImportReport(s.reportPath(payload, job.ID))
+	if err != nil {
+		metrics.RecordUserOperation("import", false)
+		return nil, err
+	}
+	defer report.discard()
+
+	result := ImportResult{Format: payload.Format, Total: total}
+	for done := int64(1); ; done++ {
+		if err := ctx.Err(); err != nil {
+			metrics.RecordUserOperation("import", false)
+			return nil, fmt.Errorf("import interrupted after %d rows: %w", done-1, err)
+		}
+
+		line, row, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		var rowErr *importRowError
+		if err != nil && !errors.As(err, &rowErr) {
+			metrics.RecordUserOperation("import", false)
+			return nil, fmt.Errorf("read line %d: %w", line, err)
+		}
+
+		if err == nil {
+			err = s.importRow(ctx, row, &result)
+		}
+		if err != nil {
+			result.Failed++
+			if werr := report.add(line, row, importErrorMessage(ctx, err)); werr != nil {
+				metrics.RecordUserOperation("import", false)
+				return nil, werr
+			}
+		}
+
+		if done%importProgressInterval == 0 {
+			progress(done, total)
+		}
+	}
+
+	if err := report.commit(); err != nil {
+		metrics.RecordUserOperation("import", false)
+		return nil, err
+	}
+	progress(total, total)
+
+	metrics.RecordUserOperation("import", true)
+	slog.InfoContext(ctx, "Users imported",
+		"actor_id", payload.ActorID,
+		"job_id", job.ID,
+		"total", result.Total,
+		"created", result.Created,
+		"failed", result.Failed,
+	)
+	return result, nil
+}
+
+// importRow 校验并注册一行，然后发送验证邮件（有密码）或邀请邮件（无密码）
+//
+// 邮件发送失败不影响导入结果，只计入 MailFailed。
+func (s *userImportService) importRow(ctx context.Context, row ImportRow, result *ImportResult) error {
+	row.Username = strings.TrimSpace(row.Username)
+	row.Email = strings.TrimSpace(row.Email)
+	if err := validateImportRow(row); err != nil {
+		return err
+	}
+
+	invite := row.Password == ""
+	user, err := s.users.Register(ctx, RegisterInput{
+		Username:     row.Username,
+		Email:        row.Email,
+		Password:     row.Password,
+		Passwordless: invite,
+	})
+	if err != nil {
+		return err
+	}
+	result.Created++
+
+	if invite {
+		result.Invited++
+		err = s.accounts.SendInvitation(ctx, user)
+	} else {
+		err = s.accounts.SendVerificationEmail(ctx, user)
+	}
+	if err != nil {
+		result.MailFailed++
+		slog.WarnContext(ctx, "Failed to send mail to imported user", "user_id", user.ID, "invite", invite, "error", err)
+	}
+	return nil
+}
+
+// reportPath 错误报告路径（文件名包含租户和提交者）
+func (s *userImportService) reportPath(payload importPayload, jobID string) string {
+	return filepath.Join(s.config.Dir, "imports", exportPrefix(payload.TenantID, payload.ActorID)+jobID+".errors.csv")
+}
+
+// ExportUsers 逐页导出用户列表
+func (s *userImportService) ExportUsers(ctx context.Context, filter repository.UserFilter, format string, w io.Writer) (int64, error) {
+	var writer exportWriter
+	switch format {
+	case TransferFormatCSV:
+		writer = newCSVExportWriter(w)
+	case TransferFormatJSONL:
+		writer = newJSONLExportWriter(w)
+	default:
+		return 0, ErrTransferFormat
+	}
+
+	// keyset 分页按 (created_at, id) 定位，不能按其他字段排序
+	filter.Sort = ""
+
+	var count int64
+	page := repository.KeysetPage{Limit: exportPageSize}
+	for {
+		result, err := s.users.ListUsersByCursor(ctx, filter, page)
+		if err != nil {
+			metrics.RecordUserOperation("list_export", false)
+			return count, err
+		}
+
+		for _, user := range result.Items {
+			if err := writer.write(toExportedUser(user)); err != nil {
+				metrics.RecordUserOperation("list_export", false)
+				return count, fmt.Errorf("service: write export: %w", err)
+			}
+			count++
+		}
+		// 每页结束后把已写入的内容发送给客户端
+		if err := writer.flush(); err != nil {
+			metrics.RecordUserOperation("list_export", false)
+			return count, fmt.Errorf("service: flush export: %w", err)
+		}
+
+		if !result.HasNext || len(result.Items) == 0 {
+			break
+		}
+		last := result.Items[len(result.Items)-1]
+		page = repository.KeysetPage{HasCursor: true, CreatedAt: last.CreatedAt, ID: last.ID, Limit: exportPageSize}
+	}
+
+	s.auditor.Record(ctx, audit.Event{
+		Action:     AuditActionUserListExport,
+		TargetType: AuditTargetUser,
+		Changes:    audit.Diff(nil, map[string]any{"format": format, "count": count}),
+	})
+	metrics.RecordUserOperation("list_export", true)
+	slog.InfoContext(ctx, "Users exported", "format", format, "count", count)
+	return count, nil
+}
+
+// toExportedUser 转换导出的用户
+func toExportedUser(user repository.User) ExportedUser {
+	return ExportedUser{
+		ID:        user.ID,
+		Username:  user.Username,
+		Email:     user.Email,
+		Avatar:    user.Avatar.String,
+		Role:      user.Role,
+		Status:    user.Status,
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+	}
+}
+
+// ========================================
+// 导入文件读取
+// ========================================
+
+// importRowError 单行格式错误（跳过该行，继续读取）
+type importRowError struct {
+	err error
+}
+
+func (e *importRowError) Error() string { return e.err.Error() }
+
+func (e *importRowError) Unwrap() error { return e.err }
+
+// importReader 逐行读取导入文件
+type importReader interface {
+	// Next 返回下一行及其行号（从 1 开始，CSV 的表头是第 1 行），文件结束时返回 io.EOF；
+	// 单行格式错误返回 *importRowError，可以继续读取
+	Next() (int, ImportRow, error)
+}
+
+// newImportReader 按格式创建读取器（CSV 读取并校验表头）
+func newImportReader(r io.Reader, format string) (importReader, error) {
+	if format == TransferFormatCSV {
+		return newCSVImportReader(r)
+	}
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), maxImportLineSize)
+	return &jsonlImportReader{scanner: scanner}, nil
+}
+
+// checkImportFile 检查上传的文件不为空，CSV 表头有效
+func checkImportFile(path, format string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return fmt.Errorf("service: open upload: %w", err)
+	}
+	defer file.Close()
+
+	reader, err := newImportReader(file, format)
+	if err != nil {
+		return err
+	}
+	if _, _, err := reader.Next(); errors.Is(err, io.EOF) {
+		return ErrImportEmpty
+	}
+	return nil
+}
+
+// countImportRows 统计数据行数（包括格式错误的行）
+func countImportRows(path, format string) (int64, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return 0, fmt.Errorf("open upload: %w", err)
+	}
+	defer file.Close()
+
+	reader, err := newImportReader(file, format)
+	if err != nil {
+		return 0, err
+	}
+	var n int64
+	for {
+		line, _, err := reader.Next()
+		if errors.Is(err, io.EOF) {
+			return n, nil
+		}
+		var rowErr *importRowError
+		if err != nil && !errors.As(err, &rowErr) {
+			return n, fmt.Errorf("read line %d: %w", line, err)
+		}
+		n++
+	}
+}
+
+// importColumns 导入使用的列
+var importColumns = []string{"username", "email", "password"}
+
+// importColumnIgnored 导出文件特有的列（导入时忽略，导出的文件可以直接导入）
+func importColumnIgnored(column string) bool {
+	return slices.Contains(exportColumns, column) && !slices.Contains(importColumns, column)
+}
+
+// csvImportReader CSV 读取器（列的顺序不限，列名不区分大小写）
+type csvImportReader struct {
+	r       *csv.Reader
+	columns map[string]int // 列名 → 下标
+}
+
+// newCSVImportReader 读取并校验表头（必须包含 username 和 email，未知的列视为错误，避免拼错的列名被静默忽略）
+func newCSVImportReader(src io.Reader) (*csvImportReader, error) {
+	r := csv.NewReader(skipBOM(src))
+	r.ReuseRecord = true
+
+	header, err := r.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return nil, ErrImportEmpty
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			return nil, ErrImportHeader
+		}
+		return nil, fmt.Errorf("read header: %w", err)
+	}
+
+	columns := make(map[string]int, len(header))
+	for i, name := range header {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if importColumnIgnored(name) {
+			continue
+		}
+		if _, dup := columns[name]; dup || !slices.Contains(importColumns, name) {
+			return nil, ErrImportHeader
+		}
+		columns[name] = i
+	}
+	if _, ok := columns["username"]; !ok {
+		return nil, ErrImportHeader
+	}
+	if _, ok := columns["email"]; !ok {
+		return nil, ErrImportHeader
+	}
+	return &csvImportReader{r: r, columns: columns}, nil
+}
+
+// Next 读取下一行（列数与表头不一致时返回 *importRowError）
+func (c *csvImportReader) Next() (int, ImportRow, error) {
+	record, err := c.r.Read()
+	if err != nil {
+		if errors.Is(err, io.EOF) {
+			return 0, ImportRow{}, io.EOF
+		}
+		var parseErr *csv.ParseError
+		if errors.As(err, &parseErr) {
+			msg := "CSV 格式错误：" + parseErr.Err.Error()
+			if errors.Is(parseErr.Err, csv.ErrFieldCount) {
+				msg = "列数与表头不一致"
+			}
+			return parseErr.StartLine, ImportRow{}, &importRowError{err: response.New(response.CodeInvalidParams, msg)}
+		}
+		return 0, ImportRow{}, err
+	}
+
+	line, _ := c.r.FieldPos(0)
+	row := ImportRow{
+		Username: unescapeCSVCell(record[c.columns["username"]]),
+		Email:    unescapeCSVCell(record[c.columns["email"]]),
+	}
+	if i, ok := c.columns["password"]; ok {
+		row.Password = record[i]
+	}
+	return line, row, nil
+}
+
+// jsonlImportReader JSONL 读取器（跳过空行）
+type jsonlImportReader struct {
+	scanner *bufio.Scanner
+	line    int
+}
+
+// Next 读取下一行（不是 JSON 对象或包含未知的字段时返回 *importRowError）
+func (j *jsonlImportReader) Next() (int, ImportRow, error) {
+	for j.scanner.Scan() {
+		j.line++
+		data := bytes.TrimSpace(j.scanner.Bytes())
+		if j.line == 1 {
+			data = bytes.TrimPrefix(data, utf8BOM)
+		}
+		if len(data) == 0 {
+			continue
+		}
+
+		row, err := decodeJSONLRow(data)
+		if err != nil {
+			return j.line, row, &importRowError{err: err}
+		}
+		return j.line, row, nil
+	}
+	if err := j.scanner.Err(); err != nil {
+		return j.line + 1, ImportRow{}, err
+	}
+	return 0, ImportRow{}, io.EOF
+}
+
+// decodeJSONLRow 解析一行 JSON（导出文件特有的字段被忽略，其他未知的字段视为错误）
+func decodeJSONLRow(data []byte) (ImportRow, error) {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return ImportRow{}, response.New(response.CodeInvalidParams, "不是有效的 JSON 对象")
+	}
+	for name := range fields {
+		if !slices.Contains(importColumns, name) && !importColumnIgnored(name) {
+			return ImportRow{}, response.New(response.CodeInvalidParams, "未知的字段："+name)
+		}
+	}
+
+	var row ImportRow
+	for name, field := range map[string]*string{"username": &row.Username, "email": &row.Email, "password": &row.Password} {
+		raw, ok := fields[name]
+		if !ok || string(raw) == "null" {
+			continue
+		}
+		if err := json.Unmarshal(raw, field); err != nil {
+			return row, response.New(response.CodeInvalidParams, "字段 "+name+" 必须是字符串")
+		}
+	}
+	return row, nil
+}
+
+// utf8BOM 表格软件保存的 UTF-8 文件开头可能带有 BOM
+var utf8BOM = []byte{0xEF, 0xBB, 0xBF}
+
+// skipBOM 跳过开头的 UTF-8 BOM
+func skipBOM(r io.Reader) io.Reader {
+	br := bufio.NewReader(r)
+	if prefix, err := br.Peek(len(utf8BOM)); err == nil && bytes.Equal(prefix, utf8BOM) {
+		br.Discard(len(utf8BOM))
+	}
+	return br
+}
+
+// ========================================
+// 行校验
+// ========================================
+
+// importValidator 使用与 Gin 参数绑定相同的校验规则（binding 标签），字段名取 json 标签
+var importValidator = newImportValidator()
+
+func newImportValidator() *validator.Validate {
+	v := validator.New()
+	v.SetTagName("binding")
+	v.RegisterTagNameFunc(func(field reflect.StructField) string {
+		return strings.SplitN(field.Tag.Get("json"), ",", 2)[0]
+	})
+	return v
+}
+
+// validateImportRow 校验一行，失败时返回带字段错误的 ErrInvalidInput
+func validateImportRow(row ImportRow) error {
+	err := importValidator.Struct(row)
+	if err == nil {
+		return nil
+	}
+
+	var validationErrs validator.ValidationErrors
+	if !errors.As(err, &validationErrs) {
+		return err
+	}
+	fields := make([]response.FieldError, 0, len(validationErrs))
+	for _, fe := range validationErrs {
+		fields = append(fields, response.FieldError{Field: fe.Field(), Code: fe.Tag(), Message: validationMessage(fe)})
+	}
+	return ErrInvalidInput.WithFields(fields...)
+}
+
+// validationMessage 校验失败的提示信息
+func validationMessage(fe validator.FieldError) string {
+	switch fe.Tag() {
+	case "required":
+		return "不能为空"
+	case "min":
+		return "长度不能少于 " + fe.Param() + " 个字符"
+	case "max":
+		return "长度不能超过 " + fe.Param() + " 个字符"
+	case "email":
+		return "邮箱格式不正确"
+	default:
+		return "格式不正确"
+	}
+}
+
+// importErrorMessage 错误报告中的原因（业务错误使用提示信息和字段错误，其他错误记录日志后使用通用提示）
+func importErrorMessage(ctx context.Context, err error) string {
+	var appErr *pkgerrors.Error
+	if !errors.As(err, &appErr) {
+		slog.ErrorContext(ctx, "Failed to import user", "error", err)
+		return response.Message(response.CodeInternalError)
+	}
+
+	msg := appErr.Message
+	if len(appErr.Fields) > 0 {
+		details := make([]string, 0, len(appErr.Fields))
+		for _, f := range appErr.Fields {
+			details = append(details, f.Field+"："+f.Message)
+		}
+		msg += "（" + strings.Join(details, "；") + "）"
+	}
+	return msg
+}
+
+// ========================================
+// 错误报告
+// ========================================
+
+// importReport 错误报告（先写临时文件，完成后重命名，下载时不会读到不完整的文件）
+type importReport struct {
+	path string
+	file *os.File
+	w    *csv.Writer
+}
+
+// newImportReport 创建错误报告并写入表头
+func newImportReport(path string) (*importReport, error) {
+	file, err := os.CreateTemp(filepath.Dir(path), ".report-*")
+	if err != nil {
+		return nil, fmt.Errorf("create import report: %w", err)
+	}
+	r := &importReport{path: path, file: file, w: csv.NewWriter(file)}
+	if err := r.w.Write([]string{"line", "username", "email", "error"}); err != nil {
+		r.discard()
+		return nil, fmt.Errorf("write import report: %w", err)
+	}
+	return r, nil
+}
+
+// add 记录失败的行（不记录密码）
+func (r *importReport) add(line int, row ImportRow, reason string) error {
+	if err := r.w.Write([]string{strconv.Itoa(line), escapeCSVCell(row.Username), escapeCSVCell(row.Email), reason}); err != nil {
+		return fmt.Errorf("write import report: %w", err)
+	}
+	return nil
+}
+
+// commit 完成写入并重命名为正式文件
+func (r *importReport) commit() error {
+	r.w.Flush()
+	if err := r.w.Error(); err != nil {
+		return fmt.Errorf("write import report: %w", err)
+	}
+	if err := r.file.Close(); err != nil {
+		return fmt.Errorf("close import report: %w", err)
+	}
+	if err := os.Rename(r.file.Name(), r.path); err != nil {
+		return fmt.Errorf("rename import report: %w", err)
+	}
+	r.file = nil
+	return nil
+}
+
+// discard 删除未完成的报告（已提交时不做任何事）
+func (r *importReport) discard() {
+	if r.file == nil {
+		return
+	}
+	r.file.Close()
+	os.Remove(r.file.Name())
+}
+
+// ========================================
+// 导出文件写入
+// ========================================
+
+// csvFormulaPrefixes 电子表格按公式解析的起始字符（以及转义使用的单引号本身）
+const csvFormulaPrefixes = "=+-@\t\r'"
+
+// escapeCSVCell 转义用户填写的内容：以公式字符开头时加上单引号，
+// 避免管理员用电子表格打开导出文件或错误报告时执行公式（CSV 注入）
+func escapeCSVCell(value string) string {
+	if value != "" && strings.ContainsRune(csvFormulaPrefixes, rune(value[0])) {
+		return "'" + value
+	}
+	return value
+}
+
+// unescapeCSVCell 还原 escapeCSVCell 转义的内容（导出的文件可以直接导入）
+func unescapeCSVCell(value string) string {
+	if len(value) > 1 && value[0] == '\'' && strings.ContainsRune(csvFormulaPrefixes, rune(value[1])) {
+		return value[1:]
+	}
+	return value
+}
+
+// exportWriter 导出文件写入器
+type exportWriter interface {
+	write(user ExportedUser) error
+	flush() error
+}
+
+// flusher 支持把缓冲的内容立即发送给客户端（如 gin.ResponseWriter）
+type flusher interface {
+	Flush()
+}
+
+// csvExportWriter CSV 导出（第一行为表头）
+type csvExportWriter struct {
+	dst    io.Writer
+	w      *csv.Writer
+	header bool
+}
+
+func newCSVExportWriter(dst io.Writer) *csvExportWriter {
+	return &csvExportWriter{dst: dst, w: csv.NewWriter(dst)}
+}
+
+func (c *csvExportWriter) write(user ExportedUser) error {
+	if !c.header {
+		if err := c.w.Write(exportColumns); err != nil {
+			return err
+		}
+		c.header = true
+	}
+	return c.w.Write([]string{
+		strconv.FormatInt(user.ID, 10),
+		escapeCSVCell(user.Username),
+		escapeCSVCell(user.Email),
+		escapeCSVCell(user.Avatar),
+		user.Role,
+		strconv.Itoa(int(user.Status)),
+		user.CreatedAt.UTC().Format(time.RFC3339),
+		user.UpdatedAt.UTC().Format(time.RFC3339),
+	})
+}
+
+// flush 没有任何用户时也写入表头
+func (c *csvExportWriter) flush() error {
+	if !c.header {
+		if err := c.w.Write(exportColumns); err != nil {
+			return err
+		}
+		c.header = true
+	}
+	c.w.Flush()
+	if err := c.w.Error(); err != nil {
+		return err
+	}
+	if f, ok := c.dst.(flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
+
+// jsonlExportWriter JSONL 导出（每行一个用户）
+type jsonlExportWriter struct {
+	dst io.Writer
+	buf *bufio.Writer
+	enc *json.Encoder
+}
+
+func newJSONLExportWriter(dst io.Writer) *jsonlExportWriter {
+	buf := bufio.NewWriter(dst)
+	return &jsonlExportWriter{dst: dst, buf: buf, enc: json.NewEncoder(buf)}
+}
+
+func (j *jsonlExportWriter) write(user ExportedUser) error {
+	return j.enc.Encode(user)
+}
+
+func (j *jsonlExportWriter) flush() error {
+	if err := j.buf.Flush(); err != nil {
+		return err
+	}
+	if f, ok := j.dst.(flusher); ok {
+		f.Flush()
+	}
+	return nil
+}
@@ -0,0 +1,288 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"database/sql"
+	"encoding/csv"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"gin_demo/internal/repository"
+	"gin_demo/pkg/audit"
+	"gin_demo/pkg/task"
+	"gin_demo/pkg/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubUserService 只实现 Register 和 ListUsersByCursor（用户名已存在时返回 ErrUserExists）
+type stubUserService struct {
+	UserService
+
+	mu         sync.Mutex
+	registered []RegisterInput
+	actors     []int64
+	pages      []repository.KeysetResult[repository.User]
+	requests   []repository.KeysetPage
+}
+
+func (s *stubUserService) Register(ctx context.Context, input RegisterInput) (repository.User, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range s.registered {
+		if r.Username == input.Username {
+			return repository.User{}, ErrUserExists
+		}
+	}
+	s.registered = append(s.registered, input)
+	s.actors = append(s.actors, audit.ActorFromContext(ctx).UserID)
+	return repository.User{ID: int64(len(s.registered)), Username: input.Username, Email: input.Email, TenantID: tenant.ID(ctx)}, nil
+}
+
+func (s *stubUserService) ListUsersByCursor(_ context.Context, _ repository.UserFilter, page repository.KeysetPage) (repository.KeysetResult[repository.User], error) {
+	s.requests = append(s.requests, page)
+	result := s.pages[0]
+	s.pages = s.pages[1:]
+	return result, nil
+}
+
+// stubAccountService 记录发送的验证邮件和邀请邮件
+type stubAccountService struct {
+	AccountService
+
+	mu       sync.Mutex
+	verified []string
+	invited  []string
+}
+
+func (s *stubAccountService) SendVerificationEmail(_ context.Context, user repository.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.verified = append(s.verified, user.Username)
+	return nil
+}
+
+func (s *stubAccountService) SendInvitation(_ context.Context, user repository.User) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.invited = append(s.invited, user.Username)
+	return nil
+}
+
+// newTestUserImportService 创建测试用的批量导入导出服务（内存作业队列，已启动）
+func newTestUserImportService(t *testing.T, users *stubUserService, accounts *stubAccountService) (UserImportService, string) {
+	t.Helper()
+
+	jobs := task.NewJobQueue(task.JobQueueConfig{Store: task.NewMemoryJobStore(10), Workers: 1, Timeout: 5 * time.Second})
+	dir := t.TempDir()
+	svc, err := NewUserImportService(users, accounts, &recordingAuditor{}, jobs, UserImportConfig{Dir: dir})
+	require.NoError(t, err)
+
+	jobs.Start()
+	t.Cleanup(jobs.Stop)
+	return svc, dir
+}
+
+// waitImportJob 等待导入作业结束
+func waitImportJob(t *testing.T, svc UserImportService, ctx context.Context, actorID int64, jobID string) task.Job {
+	t.Helper()
+
+	var job task.Job
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = svc.GetImportJob(ctx, actorID, jobID)
+		require.NoError(t, err)
+		return job.Status.Finished()
+	}, 5*time.Second, 10*time.Millisecond)
+	return job
+}
+
+// TestUserImportService_Import 测试批量导入
+func TestUserImportService_Import(t *testing.T) {
+	ctx := tenant.WithID(context.Background(), 2)
+
+	files := map[string]string{
+		TransferFormatCSV: "\uFEFFEmail,Username,Password\n" +
+			"alice@example.com,alice,Secret123!\n" +
+			"bob@example.com,bob,\n" +
+			"not-an-email,carol,Secret123!\n" +
+			"dave@example.com,al,\n" +
+			"alice2@example.com,alice,Secret123!\n" +
+			"too,many,fields,here\n" +
+			"erin@example.com, erin ,\n",
+		TransferFormatJSONL: `{"username":"alice","email":"alice@example.com","password":"Secret123!"}` + "\n" +
+			`{"username":"bob","email":"bob@example.com"}` + "\n" +
+			`{"username":"carol","email":"not-an-email","password":"Secret123!"}` + "\n" +
+			"\n" +
+			`{"username":"al","email":"dave@example.com"}` + "\n" +
+			`{"username":"alice","email":"alice2@example.com","password":"Secret123!"}` + "\n" +
+			`{"username":"frank","email":"frank@example.com","passwd":"typo"}` + "\n" +
+			`{"id":9,"username":"erin","email":"erin@example.com","role":"user","status":1}` + "\n",
+	}
+	// 失败行的行号（JSONL 中第 4 行是空行）
+	failedLines := map[string][]string{
+		TransferFormatCSV:   {"4", "5", "6", "7"},
+		TransferFormatJSONL: {"3", "5", "6", "7"},
+	}
+
+	for format, content := range files {
+		t.Run(format, func(t *testing.T) {
+			users, accounts := &stubUserService{}, &stubAccountService{}
+			svc, dir := newTestUserImportService(t, users, accounts)
+
+			job, err := svc.RequestImport(ctx, ImportInput{ActorID: 7, Format: format, Source: strings.NewReader(content)})
+			require.NoError(t, err)
+			assert.Equal(t, "2:7", job.Owner)
+
+			job = waitImportJob(t, svc, ctx, 7, job.ID)
+			require.Equal(t, task.JobSucceeded, job.Status, job.Error)
+			assert.Equal(t, task.JobProgress{Done: 7, Total: 7}, job.Progress)
+
+			var result ImportResult
+			require.NoError(t, json.Unmarshal(job.Result, &result))
+			assert.Equal(t, ImportResult{Format: format, Total: 7, Created: 3, Invited: 2, Failed: 4}, result)
+
+			// 有密码的用户发送验证邮件，没有密码的发送邀请邮件；注册记录为提交者的操作
+			assert.Equal(t, []string{"alice"}, accounts.verified)
+			assert.Equal(t, []string{"bob", "erin"}, accounts.invited)
+			assert.Equal(t, []int64{7, 7, 7}, users.actors)
+			assert.True(t, users.registered[1].Passwordless)
+
+			// 上传的文件处理后删除
+			uploads, _ := filepath.Glob(filepath.Join(dir, "imports", "upload-*"))
+			assert.Empty(t, uploads)
+
+			report, err := svc.GetImportReport(ctx, 7, job.ID)
+			require.NoError(t, err)
+			data, err := os.ReadFile(report.Path)
+			require.NoError(t, err)
+			assert.NotContains(t, string(data), "Secret123!", "错误报告不包含密码")
+
+			records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+			require.NoError(t, err)
+			require.Len(t, records, 5)
+			assert.Equal(t, []string{"line", "username", "email", "error"}, records[0])
+			var lines []string
+			for _, record := range records[1:] {
+				lines = append(lines, record[0])
+			}
+			assert.Equal(t, failedLines[format], lines)
+			assert.Contains(t, records[1][3], "email：邮箱格式不正确")
+			assert.Contains(t, records[2][3], "username：长度不能少于 3 个字符")
+		})
+	}
+
+	t.Run("CSV 表头无效时不提交作业", func(t *testing.T) {
+		svc, dir := newTestUserImportService(t, &stubUserService{}, &stubAccountService{})
+
+		_, err := svc.RequestImport(ctx, ImportInput{ActorID: 7, Format: TransferFormatCSV, Source: strings.NewReader("username,mail\nalice,alice@example.com\n")})
+		assert.ErrorIs(t, err, ErrImportHeader)
+		_, err = svc.RequestImport(ctx, ImportInput{ActorID: 7, Format: TransferFormatCSV, Source: strings.NewReader("username,email,passwd\n")})
+		assert.ErrorIs(t, err, ErrImportHeader, "拼错的列名不能被忽略")
+		_, err = svc.RequestImport(ctx, ImportInput{ActorID: 7, Format: TransferFormatJSONL, Source: strings.NewReader("\n\n")})
+		assert.ErrorIs(t, err, ErrImportEmpty)
+
+		uploads, _ := filepath.Glob(filepath.Join(dir, "imports", "upload-*"))
+		assert.Empty(t, uploads)
+	})
+
+	t.Run("其他管理员的作业不存在", func(t *testing.T) {
+		svc, _ := newTestUserImportService(t, &stubUserService{}, &stubAccountService{})
+
+		job, err := svc.RequestImport(ctx, ImportInput{ActorID: 7, Format: TransferFormatCSV, Source: strings.NewReader("username,email\nalice,alice@example.com\n")})
+		require.NoError(t, err)
+		_, err = svc.GetImportJob(ctx, 8, job.ID)
+		assert.ErrorIs(t, err, ErrJobNotFound)
+	})
+}
+
+// TestUserImportService_ExportUsers 测试逐页导出用户列表
+func TestUserImportService_ExportUsers(t *testing.T) {
+	ctx := context.Background()
+	at := time.Date(2026, 1, 2, 3, 4, 5, 0, time.UTC)
+	alice := repository.User{ID: 3, Username: "alice", Email: "alice@example.com", Role: "user", Status: repository.UserStatusActive, CreatedAt: at, UpdatedAt: at}
+	bob := repository.User{ID: 2, Username: "bob", Email: "bob@example.com", Role: "admin", Status: repository.UserStatusActive, CreatedAt: at.Add(-time.Hour), UpdatedAt: at}
+
+	t.Run("CSV", func(t *testing.T) {
+		users := &stubUserService{pages: []repository.KeysetResult[repository.User]{
+			{Items: []repository.User{alice}, HasNext: true},
+			{Items: []repository.User{bob}},
+		}}
+		svc, _ := newTestUserImportService(t, users, &stubAccountService{})
+
+		var buf bytes.Buffer
+		count, err := svc.ExportUsers(ctx, repository.UserFilter{Sort: repository.UserSortUsername}, TransferFormatCSV, &buf)
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), count)
+		assert.Equal(t, "id,username,email,avatar,role,status,created_at,updated_at\n"+
+			"3,alice,alice@example.com,,user,1,2026-01-02T03:04:05Z,2026-01-02T03:04:05Z\n"+
+			"2,bob,bob@example.com,,admin,1,2026-01-02T02:04:05Z,2026-01-02T03:04:05Z\n", buf.String())
+
+		// 第二页从第一页最后一条之后开始
+		require.Len(t, users.requests, 2)
+		assert.False(t, users.requests[0].HasCursor)
+		assert.Equal(t, repository.KeysetPage{HasCursor: true, CreatedAt: at, ID: 3, Limit: exportPageSize}, users.requests[1])
+	})
+
+	t.Run("JSONL", func(t *testing.T) {
+		users := &stubUserService{pages: []repository.KeysetResult[repository.User]{{Items: []repository.User{alice, bob}}}}
+		svc, _ := newTestUserImportService(t, users, &stubAccountService{})
+
+		var buf bytes.Buffer
+		_, err := svc.ExportUsers(ctx, repository.UserFilter{}, TransferFormatJSONL, &buf)
+		require.NoError(t, err)
+
+		lines := strings.Split(strings.TrimSpace(buf.String()), "\n")
+		require.Len(t, lines, 2)
+		var exported ExportedUser
+		require.NoError(t, json.Unmarshal([]byte(lines[1]), &exported))
+		assert.Equal(t, toExportedUser(bob), exported)
+	})
+
+	t.Run("导出的文件可以直接导入", func(t *testing.T) {
+		users := &stubUserService{pages: []repository.KeysetResult[repository.User]{{Items: []repository.User{alice}}}}
+		svc, _ := newTestUserImportService(t, users, &stubAccountService{})
+
+		var buf bytes.Buffer
+		_, err := svc.ExportUsers(ctx, repository.UserFilter{}, TransferFormatCSV, &buf)
+		require.NoError(t, err)
+		_, err = svc.RequestImport(ctx, ImportInput{ActorID: 7, Format: TransferFormatCSV, Source: &buf})
+		assert.NoError(t, err)
+	})
+
+	t.Run("转义公式字符", func(t *testing.T) {
+		mallory := repository.User{ID: 4, Username: `=HYPERLINK("http://evil.example")`, Email: "+cmd@example.com", Avatar: sql.NullString{String: "@SUM(A1)", Valid: true}, Role: "user", Status: repository.UserStatusActive, CreatedAt: at, UpdatedAt: at}
+		users := &stubUserService{pages: []repository.KeysetResult[repository.User]{{Items: []repository.User{mallory}}}}
+		svc, _ := newTestUserImportService(t, users, &stubAccountService{})
+
+		var buf bytes.Buffer
+		_, err := svc.ExportUsers(ctx, repository.UserFilter{}, TransferFormatCSV, &buf)
+		require.NoError(t, err)
+		assert.Equal(t, "id,username,email,avatar,role,status,created_at,updated_at\n"+
+			`4,"'=HYPERLINK(""http://evil.example"")",'+cmd@example.com,'@SUM(A1),user,1,2026-01-02T03:04:05Z,2026-01-02T03:04:05Z`+"\n", buf.String())
+
+		// 导入时还原
+		reader, err := newCSVImportReader(&buf)
+		require.NoError(t, err)
+		_, row, err := reader.Next()
+		require.NoError(t, err)
+		assert.Equal(t, ImportRow{Username: mallory.Username, Email: mallory.Email}, row)
+
+		for _, value := range []string{"-1", "\tx", "\rx", "'quoted", "alice", "", "'"} {
+			assert.Equal(t, value, unescapeCSVCell(escapeCSVCell(value)), value)
+		}
+	})
+
+	t.Run("不支持的格式", func(t *testing.T) {
+		svc, _ := newTestUserImportService(t, &stubUserService{}, &stubAccountService{})
+		_, err := svc.ExportUsers(ctx, repository.UserFilter{}, "xml", &bytes.Buffer{})
+		assert.ErrorIs(t, err, ErrTransferFormat)
+	})
+}
@@ -6,12 +6,14 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strings"
 
 	"gin_demo/internal/repository"
 	"gin_demo/internal/response"
+	"gin_demo/pkg/audit"
+	"gin_demo/pkg/auth"
 	"gin_demo/pkg/metrics"
-
-	"golang.org/x/crypto/bcrypt"
 )
 
 var (
@@ -23,13 +25,53 @@ var (
 	ErrInvalidPassword = response.ErrInvalidPassword
 	// ErrInvalidInput 输入参数错误
 	ErrInvalidInput = response.ErrInvalidParams
+	// ErrEmailNotVerified 邮箱未验证
+	ErrEmailNotVerified = response.ErrEmailNotVerified
+	// ErrInvalidUserTransition 用户当前状态不允许该操作（如恢复未删除的用户）
+	ErrInvalidUserTransition = response.New(response.CodeInvalidState, "用户当前状态不允许该操作")
+	// ErrMergeTarget 合并的目标用户不能接收数据（已停用、删除、匿名或合并）
+	ErrMergeTarget = response.New(response.CodeInvalidState, "目标用户当前状态不能合并")
+	// ErrMergeConflict 两个用户关联了同一提供方的第三方账户，需要先解除其中一个
+	ErrMergeConflict = response.New(response.CodeInvalidState, "两个用户关联了相同提供方的第三方账户")
+	// ErrBatchTooLarge 批量操作条数超过 MaxBatchOperations
+	ErrBatchTooLarge = response.New(response.CodeRequestTooLarge, "批量操作条数超过上限")
+	// ErrBatchAborted 原子模式下其他操作失败，该操作已回滚或未执行
+	ErrBatchAborted = response.New(response.CodeInvalidState, "批量操作中有其他操作失败，已全部回滚")
+	// ErrBatchForbidden 批量操作的目标用户不允许当前用户操作（如级别不低于当前用户）
+	ErrBatchForbidden = response.New(response.CodeForbidden, "权限不足：无权操作该用户")
 )
 
+// userTransitions 用户状态机：当前状态 → 允许转换到的状态
+//
+//	待激活 → 正常（验证邮箱）、停用、已删除、已匿名、已合并
+//	正常   → 停用、已删除、已匿名、已合并
+//	停用   → 正常（重新启用）、已删除、已匿名、已合并
+//	已删除 → 正常（恢复）、已匿名
+//	已合并 → 已匿名（合并后仍保留用户名和邮箱）
+//	已匿名为最终状态（个人信息已清除，不能恢复）
+var userTransitions = map[int16][]int16{
+	repository.UserStatusPending:   {repository.UserStatusActive, repository.UserStatusSuspended, repository.UserStatusDeleted, repository.UserStatusErased, repository.UserStatusMerged},
+	repository.UserStatusActive:    {repository.UserStatusSuspended, repository.UserStatusDeleted, repository.UserStatusErased, repository.UserStatusMerged},
+	repository.UserStatusSuspended: {repository.UserStatusActive, repository.UserStatusDeleted, repository.UserStatusErased, repository.UserStatusMerged},
+	repository.UserStatusDeleted:   {repository.UserStatusActive, repository.UserStatusErased},
+	repository.UserStatusMerged:    {repository.UserStatusErased},
+}
+
+// CanTransitionUser 判断用户状态能否从 from 转换到 to
+func CanTransitionUser(from, to int16) bool {
+	return slices.Contains(userTransitions[from], to)
+}
+
 // RegisterInput 注册输入参数
 type RegisterInput struct {
 	Username string
 	Email    string
 	Password string
+
+	// Passwordless 第三方登录注册，不设置密码（之后可以通过忘记密码流程设置）
+	Passwordless bool
+	// EmailVerified 邮箱已由第三方提供方验证，注册后直接激活，不再发送验证邮件
+	EmailVerified bool
 }
 
 // LoginInput 登录输入参数
@@ -53,12 +95,78 @@ type ChangePasswordInput struct {
 	NewPassword string
 }
 
-// BatchUpdateInput 批量更新输入参数
-type BatchUpdateInput struct {
+// UpdateRoleInput 更新角色输入参数
+type UpdateRoleInput struct {
+	UserID      int64
+	Role        auth.Role
+	Permissions []auth.Permission // 额外权限（整体替换）
+}
+
+// TransferInput 合并账户输入参数
+type TransferInput struct {
+	FromUserID int64 // 源用户（合并后标记为已合并）
+	ToUserID   int64 // 目标用户（接收源用户的数据）
+	DryRun     bool  // 只返回将要转移的数据，不执行合并
+}
+
+// TransferReport 合并账户报告（预演时为将要转移的数据）
+type TransferReport struct {
+	FromUserID            int64
+	ToUserID              int64
+	DryRun                bool
+	Identities            []string // 转移的第三方账户（提供方）
+	ConflictingIdentities []string // 两个用户都关联了的提供方（存在时不能合并）
+	APIKeys               []string // 转移的 API Key（公开标识）
+}
+
+// BatchAction 批量操作类型
+type BatchAction string
+
+const (
+	BatchActionUpdate  BatchAction = "update"  // 更新用户名、邮箱、头像
+	BatchActionSuspend BatchAction = "suspend" // 停用（需要说明原因）
+	BatchActionDelete  BatchAction = "delete"  // 删除（软删除）
+)
+
+// batchPolicyActions 批量操作对应的资源级策略（与单个用户的接口一致）
+var batchPolicyActions = map[BatchAction]auth.Action{
+	BatchActionUpdate:  auth.ActionUpdate,
+	BatchActionSuspend: auth.ActionSuspend,
+	BatchActionDelete:  auth.ActionDelete,
+}
+
+// BatchMode 批量操作的执行方式
+type BatchMode string
+
+const (
+	BatchModeAtomic     BatchMode = "atomic"      // 在一个事务中执行，任一操作失败时全部回滚
+	BatchModeBestEffort BatchMode = "best_effort" // 逐条执行，失败的操作不影响其他操作
+)
+
+// MaxBatchOperations 单次批量操作的最大条数
+const MaxBatchOperations = 100
+
+// BatchOperation 批量操作中的一条
+type BatchOperation struct {
+	Action   BatchAction
 	UserID   int64
-	Username *string
-	Email    *string
-	Avatar   *string
+	Username *string // 更新时使用（nil 或空字符串表示不修改）
+	Email    *string // 同上
+	Avatar   *string // 同上（空字符串表示清除头像）
+	Reason   string  // 停用原因
+}
+
+// BatchInput 批量操作输入参数
+type BatchInput struct {
+	Mode       BatchMode
+	Operations []BatchOperation // 同一用户只能出现一次
+}
+
+// BatchResult 批量操作中一条操作的结果（与 BatchInput.Operations 按顺序对应）
+type BatchResult struct {
+	UserID int64
+	Action BatchAction
+	Err    error // nil 表示成功；原子模式下因其他操作失败而回滚或未执行的操作为 ErrBatchAborted
 }
 
 // UserService 用户业务逻辑接口
@@ -81,28 +189,66 @@ type UserService interface {
 	// ChangePassword 修改密码
 	ChangePassword(ctx context.Context, input ChangePasswordInput) error
 
-	// DeleteUser 删除用户
+	// GetUserByIDAnyStatus 通过 ID 获取用户，包含停用和已删除的用户（管理员使用）
+	GetUserByIDAnyStatus(ctx context.Context, userID int64) (repository.User, error)
+
+	// DeleteUser 删除用户（软删除，保留期内可以恢复）
 	DeleteUser(ctx context.Context, userID int64) error
 
-	// ListUsers 用户列表（分页）
-	ListUsers(ctx context.Context, limit, offset int32) ([]repository.User, int64, error)
+	// SuspendUser 停用用户（需要说明原因）
+	SuspendUser(ctx context.Context, userID int64, reason string) (repository.User, error)
 
-	// TransferUserData 转移用户数据（示例：需要事务的复杂操作）
-	TransferUserData(ctx context.Context, fromUserID, toUserID int64) error
+	// ReactivateUser 重新启用已停用的用户
+	ReactivateUser(ctx context.Context, userID int64) (repository.User, error)
 
-	// BatchUpdateUsers 批量更新用户（示例：批量操作事务）
-	BatchUpdateUsers(ctx context.Context, updates []BatchUpdateInput) error
+	// RestoreUser 恢复已删除的用户（恢复为正常状态）
+	RestoreUser(ctx context.Context, userID int64) (repository.User, error)
+
+	// ListUsers 用户列表（按条件过滤和排序，分页），同时返回符合条件的总数
+	ListUsers(ctx context.Context, filter repository.UserFilter, limit, offset int32) ([]repository.User, int64, error)
+
+	// ListUsersByCursor 用户列表（按条件过滤，按创建时间排序的游标分页）
+	ListUsersByCursor(ctx context.Context, filter repository.UserFilter, page repository.KeysetPage) (repository.KeysetResult[repository.User], error)
+
+	// GetUserPermissions 获取用户的额外权限（用于签发 RBAC Token）
+	GetUserPermissions(ctx context.Context, userID int64) ([]auth.Permission, error)
+
+	// UpdateUserRole 更新用户角色及额外权限
+	UpdateUserRole(ctx context.Context, input UpdateRoleInput) error
+
+	// GetTokenVersion 获取用户当前的 Token 版本号（用于校验 Token 是否已被吊销）
+	GetTokenVersion(ctx context.Context, userID int64) (int64, error)
+
+	// RevokeAllSessions 吊销用户所有已签发的 Token（登出所有设备）
+	RevokeAllSessions(ctx context.Context, userID int64) error
+
+	// TransferUserData 合并账户：把源用户的第三方账户和 API Key 转移给目标用户，源用户标记为已合并
+	TransferUserData(ctx context.Context, input TransferInput) (TransferReport, error)
+
+	// BatchUsers 批量更新、停用、删除用户，按顺序返回每一条操作的结果
+	BatchUsers(ctx context.Context, input BatchInput) ([]BatchResult, error)
 }
 
 // userService 用户业务逻辑实现
 type userService struct {
-	userRepo repository.UserRepositoryInterface
+	userRepo  repository.UserRepositoryInterface
+	hasher    auth.PasswordHasher
+	passwords PasswordService // 新密码的策略校验和历史记录
+	auditor   audit.Recorder  // 注册、修改、删除等操作写入审计日志
 }
 
 // NewUserService 创建用户服务实例
-func NewUserService(userRepo repository.UserRepositoryInterface) UserService {
+func NewUserService(
+	userRepo repository.UserRepositoryInterface,
+	hasher auth.PasswordHasher,
+	passwords PasswordService,
+	auditor audit.Recorder,
+) UserService {
 	return &userService{
-		userRepo: userRepo,
+		userRepo:  userRepo,
+		hasher:    hasher,
+		passwords: passwords,
+		auditor:   auditor,
 	}
 }
 
@@ -111,7 +257,7 @@ func (s *userService) Register(ctx context.Context, input RegisterInput) (reposi
 	var user repository.User
 
 	// 1. 验证输入
-	if input.Username == "" || input.Email == "" || input.Password == "" {
+	if input.Username == "" || input.Email == "" || (input.Password == "" && !input.Passwordless) {
 		slog.WarnContext(ctx, "Invalid registration input",
 			"has_username", input.Username != "",
 			"has_email", input.Email != "",
@@ -140,22 +286,41 @@ func (s *userService) Register(ctx context.Context, input RegisterInput) (reposi
 		return user, ErrUserExists
 	}
 
-	// 4. 密码加密
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.Password), bcrypt.DefaultCost)
-	if err != nil {
-		slog.ErrorContext(ctx, "Failed to hash password",
-			"error", err,
-			"username", input.Username,
-		)
-		return user, fmt.Errorf("service: hash password: %w", err)
+	// 4. 校验密码策略并加密（无密码账户保存空字符串，任何密码都无法通过校验）
+	var hashedPassword string
+	if !input.Passwordless {
+		if err := s.passwords.Validate(ctx, PasswordCheck{
+			Field:    "password",
+			Password: input.Password,
+			Username: input.Username,
+			Email:    input.Email,
+		}); err != nil {
+			return user, err
+		}
+
+		hashedPassword, err = s.hasher.Hash(input.Password)
+		if err != nil {
+			slog.ErrorContext(ctx, "Failed to hash password",
+				"error", err,
+				"username", input.Username,
+			)
+			return user, fmt.Errorf("service: hash password: %w", err)
+		}
+	}
+
+	// 验证邮箱后才能登录；第三方已验证的邮箱直接激活
+	status := repository.UserStatusPending
+	if input.EmailVerified {
+		status = repository.UserStatusActive
 	}
 
 	// 5. 创建用户
 	user, err = s.userRepo.CreateUser(ctx, repository.CreateUserParams{
 		Username: input.Username,
 		Email:    input.Email,
-		Password: string(hashedPassword),
+		Password: hashedPassword,
 		Avatar:   sql.NullString{Valid: false},
+		Status:   status,
 	})
 	if err != nil {
 		slog.ErrorContext(ctx, "Failed to create user",
@@ -167,12 +332,21 @@ func (s *userService) Register(ctx context.Context, input RegisterInput) (reposi
 		return user, fmt.Errorf("service: create user: %w", err)
 	}
 
-	// 记录成功日志和指标
-	slog.InfoContext(ctx, "User registered successfully",
-		"user_id", user.ID,
-		"username", user.Username,
-		"email", user.Email,
-	)
+	if err := s.passwords.Remember(ctx, user.ID, hashedPassword); err != nil {
+		slog.WarnContext(ctx, "Failed to save password history", "user_id", user.ID, "error", err)
+	}
+
+	// 记录审计日志和指标
+	s.auditor.Record(ctx, audit.Event{
+		Action:     AuditActionUserRegister,
+		TargetType: AuditTargetUser,
+		TargetID:   user.ID,
+		Changes: audit.Diff(nil, map[string]any{
+			"username":     user.Username,
+			"email":        user.Email,
+			"passwordless": input.Passwordless,
+		}),
+	})
 	metrics.RecordUserRegistration()
 	metrics.RecordUserOperation("register", true)
 
@@ -208,8 +382,15 @@ func (s *userService) Login(ctx context.Context, input LoginInput) (repository.U
 		return user, fmt.Errorf("service: get user: %w", err)
 	}
 
-	// 3. 验证密码
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.Password)); err != nil {
+	// 3. 验证密码（哈希格式错误时同样视为密码错误）
+	ok, err := s.hasher.Verify(input.Password, user.Password)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to verify password",
+			"error", err,
+			"user_id", user.ID,
+		)
+	}
+	if !ok {
 		slog.WarnContext(ctx, "Login failed: invalid password",
 			"user_id", user.ID,
 			"email", input.Email,
@@ -218,6 +399,21 @@ func (s *userService) Login(ctx context.Context, input LoginInput) (repository.U
 		return user, ErrInvalidPassword
 	}
 
+	// 4. 邮箱未验证的用户不能登录（密码校验之后再判断，避免泄露账户状态）
+	if user.Status == repository.UserStatusPending {
+		slog.WarnContext(ctx, "Login failed: email not verified",
+			"user_id", user.ID,
+			"email", input.Email,
+		)
+		metrics.RecordUserLogin(false)
+		return user, ErrEmailNotVerified
+	}
+
+	// 5. 哈希使用了过时的算法或参数时，用本次的明文密码重新计算
+	if s.hasher.NeedsRehash(user.Password) {
+		s.rehashPassword(ctx, user.ID, user.Password, input.Password)
+	}
+
 	// 记录登录成功
 	slog.InfoContext(ctx, "User logged in successfully",
 		"user_id", user.ID,
@@ -229,6 +425,24 @@ func (s *userService) Login(ctx context.Context, input LoginInput) (repository.U
 	return user, nil
 }
 
+// rehashPassword 升级密码哈希（失败只记录日志，不影响本次登录）
+func (s *userService) rehashPassword(ctx context.Context, userID int64, oldHash, password string) {
+	newHash, err := s.hasher.Hash(password)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to rehash password", "error", err, "user_id", userID)
+		return
+	}
+
+	updated, err := s.userRepo.RehashUserPassword(ctx, userID, oldHash, newHash)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to save rehashed password", "error", err, "user_id", userID)
+		return
+	}
+	if updated {
+		slog.InfoContext(ctx, "Password hash upgraded", "user_id", userID)
+	}
+}
+
 // GetUserByID 通过 ID 获取用户
 func (s *userService) GetUserByID(ctx context.Context, userID int64) (repository.User, error) {
 	user, err := s.userRepo.GetUserByID(ctx, userID)
@@ -305,7 +519,13 @@ func (s *userService) UpdateUser(ctx context.Context, input UpdateUserInput) err
 		return fmt.Errorf("service: update user: %w", err)
 	}
 
-	// 记录更新成功指标
+	// 记录审计日志和指标
+	s.auditor.Record(ctx, audit.Event{
+		Action:     AuditActionUserUpdate,
+		TargetType: AuditTargetUser,
+		TargetID:   input.UserID,
+		Changes:    audit.Diff(userFields(currentUser.Username, currentUser.Email, currentUser.Avatar), userFields(username, email, avatar)),
+	})
 	metrics.RecordUserOperation("update", true)
 
 	return nil
@@ -318,89 +538,240 @@ func (s *userService) ChangePassword(ctx context.Context, input ChangePasswordIn
 		return ErrInvalidInput
 	}
 
-	// 2. 获取用户
-	user, err := s.userRepo.GetUserByID(ctx, input.UserID)
+	// 2. 获取当前密码哈希（用户缓存中不含密码）
+	currentHash, err := s.userRepo.GetUserPassword(ctx, input.UserID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return ErrUserNotFound
 		}
-		return fmt.Errorf("service: get user: %w", err)
+		return fmt.Errorf("service: get user password: %w", err)
 	}
 
 	// 3. 验证旧密码
-	if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(input.OldPassword)); err != nil {
+	ok, err := s.hasher.Verify(input.OldPassword, currentHash)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to verify password", "error", err, "user_id", input.UserID)
+	}
+	if !ok {
 		return ErrInvalidPassword
 	}
 
-	// 4. 加密新密码
-	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(input.NewPassword), bcrypt.DefaultCost)
+	// 4. 校验新密码策略（相似度检查需要用户名和邮箱）
+	user, err := s.userRepo.GetUserByID(ctx, input.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return ErrUserNotFound
+		}
+		return fmt.Errorf("service: get user: %w", err)
+	}
+	if err := s.passwords.Validate(ctx, PasswordCheck{
+		UserID:   input.UserID,
+		Field:    "new_password",
+		Password: input.NewPassword,
+		Username: user.Username,
+		Email:    user.Email,
+	}); err != nil {
+		return err
+	}
+
+	// 5. 加密新密码
+	hashedPassword, err := s.hasher.Hash(input.NewPassword)
 	if err != nil {
 		return fmt.Errorf("service: hash password: %w", err)
 	}
 
-	// 5. 更新密码
-	if err := s.userRepo.UpdateUserPassword(ctx, input.UserID, string(hashedPassword)); err != nil {
+	// 6. 更新密码（同时递增 Token 版本号，所有已登录设备需重新登录）
+	if err := s.userRepo.UpdateUserPassword(ctx, input.UserID, hashedPassword); err != nil {
 		return fmt.Errorf("service: update password: %w", err)
 	}
+	if err := s.passwords.Remember(ctx, input.UserID, hashedPassword); err != nil {
+		slog.WarnContext(ctx, "Failed to save password history", "user_id", input.UserID, "error", err)
+	}
 
-	// 记录密码修改指标
+	// 记录审计日志（不记录密码）和指标
+	s.auditor.Record(ctx, audit.Event{
+		Action:     AuditActionUserPasswordChange,
+		TargetType: AuditTargetUser,
+		TargetID:   input.UserID,
+	})
 	metrics.PasswordChanges.Inc()
 
 	return nil
 }
 
-// DeleteUser 删除用户
+// GetUserByIDAnyStatus 通过 ID 获取用户，包含停用和已删除的用户
+func (s *userService) GetUserByIDAnyStatus(ctx context.Context, userID int64) (repository.User, error) {
+	user, err := s.userRepo.GetUserByIDAnyStatus(ctx, userID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return repository.User{}, ErrUserNotFound
+		}
+		return repository.User{}, fmt.Errorf("service: get user: %w", err)
+	}
+	return user, nil
+}
+
+// DeleteUser 删除用户（软删除，超过保留期后由清理任务永久删除）
 func (s *userService) DeleteUser(ctx context.Context, userID int64) error {
-	// 1. 检查用户是否存在
-	user, err := s.userRepo.GetUserByID(ctx, userID)
+	// 删除用户（同时递增 Token 版本号，已签发的 Token 立即失效）
+	user, err := s.changeUserStatus(ctx, userID, repository.UserStatusDeleted, "", "delete")
+	if err != nil {
+		return err
+	}
+
+	// 记录审计日志（保留删除前的用户名和邮箱）
+	s.auditor.Record(ctx, audit.Event{
+		Action:     AuditActionUserDelete,
+		TargetType: AuditTargetUser,
+		TargetID:   userID,
+		Changes:    audit.Diff(map[string]any{"username": user.Username, "email": user.Email}, nil),
+	})
+	metrics.UserDeletions.Inc()
+
+	return nil
+}
+
+// SuspendUser 停用用户（已签发的 Token 立即失效，停用期间不能登录）
+func (s *userService) SuspendUser(ctx context.Context, userID int64, reason string) (repository.User, error) {
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return repository.User{}, ErrInvalidInput
+	}
+
+	user, err := s.changeUserStatus(ctx, userID, repository.UserStatusSuspended, reason, "suspend")
+	if err != nil {
+		return repository.User{}, err
+	}
+
+	s.recordStatusChange(ctx, AuditActionUserSuspend, user, repository.UserStatusSuspended, reason)
+	return s.withStatus(user, repository.UserStatusSuspended, reason), nil
+}
+
+// ReactivateUser 重新启用已停用的用户
+func (s *userService) ReactivateUser(ctx context.Context, userID int64) (repository.User, error) {
+	// 已删除的用户通过 RestoreUser 恢复
+	user, err := s.changeUserStatus(ctx, userID, repository.UserStatusActive, "", "reactivate", repository.UserStatusSuspended)
+	if err != nil {
+		return repository.User{}, err
+	}
+
+	s.recordStatusChange(ctx, AuditActionUserReactivate, user, repository.UserStatusActive, "")
+	return s.withStatus(user, repository.UserStatusActive, ""), nil
+}
+
+// RestoreUser 恢复已删除的用户（恢复为正常状态，用户需要重新登录）
+func (s *userService) RestoreUser(ctx context.Context, userID int64) (repository.User, error) {
+	// 停用的用户通过 ReactivateUser 重新启用
+	user, err := s.changeUserStatus(ctx, userID, repository.UserStatusActive, "", "restore", repository.UserStatusDeleted)
+	if err != nil {
+		return repository.User{}, err
+	}
+
+	s.recordStatusChange(ctx, AuditActionUserRestore, user, repository.UserStatusActive, "")
+	return s.withStatus(user, repository.UserStatusActive, ""), nil
+}
+
+// changeUserStatus 按状态机变更用户状态（同时吊销所有已签发的 Token），返回变更前的用户
+// from 不为空时只允许从这些状态转换（同一目标状态对应不同操作时区分，如重新启用和恢复）
+func (s *userService) changeUserStatus(ctx context.Context, userID int64, to int16, reason, operation string, from ...int16) (repository.User, error) {
+	// 1. 检查用户是否存在（包含停用和已删除的用户）
+	user, err := s.userRepo.GetUserByIDAnyStatus(ctx, userID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
-			slog.WarnContext(ctx, "Delete failed: user not found",
+			slog.WarnContext(ctx, "Change status failed: user not found",
 				"user_id", userID,
+				"operation", operation,
 			)
-			return ErrUserNotFound
+			return repository.User{}, ErrUserNotFound
 		}
-		slog.ErrorContext(ctx, "Failed to get user for deletion",
+		slog.ErrorContext(ctx, "Failed to get user for status change",
 			"error", err,
 			"user_id", userID,
+			"operation", operation,
 		)
-		return fmt.Errorf("service: get user: %w", err)
+		return repository.User{}, fmt.Errorf("service: get user: %w", err)
+	}
+
+	// 2. 校验状态转换
+	if !CanTransitionUser(user.Status, to) || (len(from) > 0 && !slices.Contains(from, user.Status)) {
+		slog.WarnContext(ctx, "Invalid user status transition",
+			"user_id", userID,
+			"from", user.Status,
+			"to", to,
+		)
+		return repository.User{}, ErrInvalidUserTransition
 	}
 
-	// 2. 删除用户
-	if err := s.userRepo.DeleteUser(ctx, userID); err != nil {
-		slog.ErrorContext(ctx, "Failed to delete user",
+	// 3. 变更状态（只在状态未被并发修改时生效）
+	changed, err := s.userRepo.UpdateUserStatus(ctx, userID, user.Status, to, reason)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to change user status",
 			"error", err,
 			"user_id", userID,
-			"username", user.Username,
-			"email", user.Email,
+			"from", user.Status,
+			"to", to,
 		)
-		metrics.RecordUserOperation("delete", false)
-		return fmt.Errorf("service: delete user: %w", err)
+		metrics.RecordUserOperation(operation, false)
+		return repository.User{}, fmt.Errorf("service: update user status: %w", err)
+	}
+	if !changed {
+		metrics.RecordUserOperation(operation, false)
+		return repository.User{}, ErrInvalidUserTransition
 	}
 
-	// 记录删除成功
-	slog.InfoContext(ctx, "User deleted successfully",
+	slog.InfoContext(ctx, "User status changed",
 		"user_id", userID,
-		"username", user.Username,
-		"email", user.Email,
+		"from", user.Status,
+		"to", to,
 	)
-	metrics.UserDeletions.Inc()
-	metrics.RecordUserOperation("delete", true)
+	metrics.RecordUserOperation(operation, true)
 
-	return nil
+	return user, nil
+}
+
+// recordStatusChange 记录状态变更的审计日志
+func (s *userService) recordStatusChange(ctx context.Context, action string, before repository.User, to int16, reason string) {
+	previous := map[string]any{"status": before.Status, "status_reason": nil}
+	if before.StatusReason.Valid {
+		previous["status_reason"] = before.StatusReason.String
+	}
+	current := map[string]any{"status": to, "status_reason": nil}
+	if reason != "" {
+		current["status_reason"] = reason
+	}
+
+	s.auditor.Record(ctx, audit.Event{
+		Action:     action,
+		TargetType: AuditTargetUser,
+		TargetID:   before.ID,
+		Changes:    audit.Diff(previous, current),
+	})
 }
 
-// ListUsers 用户列表（分页）
-func (s *userService) ListUsers(ctx context.Context, limit, offset int32) ([]repository.User, int64, error) {
-	// 1. 查询用户列表
-	users, err := s.userRepo.ListUsers(ctx, limit, offset)
+// withStatus 返回状态变更后的用户
+func (s *userService) withStatus(user repository.User, status int16, reason string) repository.User {
+	user.Status = status
+	user.StatusReason = sql.NullString{String: reason, Valid: reason != ""}
+	user.DeletedAt = sql.NullTime{}
+	return user
+}
+
+// ListUsers 用户列表（按条件过滤和排序，分页）
+func (s *userService) ListUsers(ctx context.Context, filter repository.UserFilter, limit, offset int32) ([]repository.User, int64, error) {
+	// 1. 校验排序字段（只允许白名单中的字段）和创建时间范围
+	if err := filter.Validate(); err != nil {
+		slog.WarnContext(ctx, "Invalid user list filter", "error", err)
+		return nil, 0, ErrInvalidInput
+	}
+
+	// 2. 查询用户列表
+	users, err := s.userRepo.ListUsers(ctx, filter, limit, offset)
 	if err != nil {
 		return nil, 0, fmt.Errorf("service: list users: %w", err)
 	}
 
-	// 2. 查询总数
-	total, err := s.userRepo.CountUsers(ctx)
+	// 3. 查询符合条件的总数
+	total, err := s.userRepo.CountUsers(ctx, filter)
 	if err != nil {
 		return nil, 0, fmt.Errorf("service: count users: %w", err)
 	}
@@ -408,117 +779,496 @@ func (s *userService) ListUsers(ctx context.Context, limit, offset int32) ([]rep
 	return users, total, nil
 }
 
-// ============================================================================
-// 事务方法示例
-// ============================================================================
+// ListUsersByCursor 用户列表（游标分页，只支持按创建时间排序；不返回总数）
+func (s *userService) ListUsersByCursor(ctx context.Context, filter repository.UserFilter, page repository.KeysetPage) (repository.KeysetResult[repository.User], error) {
+	// 1. 校验过滤条件（游标按 (created_at, id) 定位，不能按其他字段排序）
+	if err := filter.Validate(); err != nil || (filter.Sort != "" && filter.Sort != repository.UserSortCreatedAt) {
+		slog.WarnContext(ctx, "Invalid user list filter for cursor pagination", "sort", filter.Sort, "error", err)
+		return repository.KeysetResult[repository.User]{}, ErrInvalidInput
+	}
+	page.Desc = filter.Order != repository.SortAsc
+	page.Backward = page.Backward && page.HasCursor
 
-// TransferUserData 转移用户数据（示例：复杂的多步骤事务操作）
-//
-// 场景：将一个用户的数据转移到另一个用户（例如合并账户）
-// 需要在一个事务中完成多个操作，确保数据一致性
-func (s *userService) TransferUserData(ctx context.Context, fromUserID, toUserID int64) error {
-	// 1. 验证源用户和目标用户都存在
-	fromUser, err := s.userRepo.GetUserByID(ctx, fromUserID)
+	// 2. 查询一页
+	result, err := s.userRepo.ListUsersByCursor(ctx, filter, page)
+	if err != nil {
+		return repository.KeysetResult[repository.User]{}, fmt.Errorf("service: list users by cursor: %w", err)
+	}
+	return result, nil
+}
+
+// GetUserPermissions 获取用户的额外权限（忽略未定义的权限值）
+func (s *userService) GetUserPermissions(ctx context.Context, userID int64) ([]auth.Permission, error) {
+	values, err := s.userRepo.GetUserPermissions(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("service: get permissions: %w", err)
+	}
+
+	permissions := make([]auth.Permission, 0, len(values))
+	for _, v := range values {
+		p := auth.Permission(v)
+		if !p.IsValid() {
+			slog.WarnContext(ctx, "Ignoring unknown permission",
+				"user_id", userID,
+				"permission", v,
+			)
+			continue
+		}
+		permissions = append(permissions, p)
+	}
+
+	return permissions, nil
+}
+
+// UpdateUserRole 更新用户角色及额外权限
+func (s *userService) UpdateUserRole(ctx context.Context, input UpdateRoleInput) error {
+	// 1. 验证输入
+	if !input.Role.IsValid() || input.Role == auth.RoleGuest {
+		return ErrInvalidInput
+	}
+
+	permissions := make([]string, 0, len(input.Permissions))
+	for _, p := range input.Permissions {
+		if !p.IsValid() {
+			return ErrInvalidInput
+		}
+		permissions = append(permissions, string(p))
+	}
+
+	// 2. 检查用户是否存在
+	user, err := s.userRepo.GetUserByID(ctx, input.UserID)
 	if err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return ErrUserNotFound
 		}
-		return fmt.Errorf("service: get source user: %w", err)
+		return fmt.Errorf("service: get user: %w", err)
+	}
+	previous, err := s.userRepo.GetUserPermissions(ctx, input.UserID)
+	if err != nil {
+		return fmt.Errorf("service: get permissions: %w", err)
+	}
+	if previous == nil {
+		previous = []string{}
 	}
 
-	toUser, err := s.userRepo.GetUserByID(ctx, toUserID)
+	// 3. 更新角色
+	if err := s.userRepo.UpdateUserRole(ctx, input.UserID, string(input.Role), permissions); err != nil {
+		metrics.RecordUserOperation("update_role", false)
+		return fmt.Errorf("service: update role: %w", err)
+	}
+
+	s.auditor.Record(ctx, audit.Event{
+		Action:     AuditActionUserRoleUpdate,
+		TargetType: AuditTargetUser,
+		TargetID:   input.UserID,
+		Changes: audit.Diff(
+			map[string]any{"role": user.Role, "permissions": previous},
+			map[string]any{"role": string(input.Role), "permissions": permissions},
+		),
+	})
+	metrics.RecordUserOperation("update_role", true)
+
+	return nil
+}
+
+// userFields 用户资料中记录审计差异的字段
+func userFields(username, email string, avatar sql.NullString) map[string]any {
+	fields := map[string]any{"username": username, "email": email, "avatar": nil}
+	if avatar.Valid {
+		fields["avatar"] = avatar.String
+	}
+	return fields
+}
+
+// GetTokenVersion 获取用户当前的 Token 版本号（已禁用的用户视为不存在）
+func (s *userService) GetTokenVersion(ctx context.Context, userID int64) (int64, error) {
+	version, err := s.userRepo.GetUserTokenVersion(ctx, userID)
 	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrUserNotFound
+		}
+		return 0, fmt.Errorf("service: get token version: %w", err)
+	}
+	return version, nil
+}
+
+// RevokeAllSessions 吊销用户所有已签发的 Token（递增 Token 版本号）
+func (s *userService) RevokeAllSessions(ctx context.Context, userID int64) error {
+	// 1. 检查用户是否存在
+	if _, err := s.userRepo.GetUserByID(ctx, userID); err != nil {
 		if errors.Is(err, sql.ErrNoRows) {
 			return ErrUserNotFound
 		}
-		return fmt.Errorf("service: get target user: %w", err)
-	}
-
-	// 2. 在事务中执行数据转移
-	return s.userRepo.WithTx(ctx, func(tx *sql.Tx) error {
-		// 2.1 这里可以执行多个数据库操作
-		// 例如：转移用户的订单、评论、收藏等数据到目标用户
-		// txRepo := s.userRepo.WithTx(tx)
-		
-		// 示例：更新源用户状态为已合并
-		// if err := txRepo.UpdateUserStatus(ctx, fromUserID, StatusMerged); err != nil {
-		//     return fmt.Errorf("failed to update source user status: %w", err)
-		// }
-
-		// 示例：更新目标用户的最后更新时间
-		// if err := txRepo.TouchUser(ctx, toUserID); err != nil {
-		//     return fmt.Errorf("failed to update target user: %w", err)
-		// }
-
-		// 2.2 记录日志（示例）
-		slog.InfoContext(ctx, "User data transferred successfully",
-			"from_user_id", fromUser.ID,
-			"from_email", fromUser.Email,
-			"to_user_id", toUser.ID,
-			"to_email", toUser.Email,
+		return fmt.Errorf("service: get user: %w", err)
+	}
+
+	// 2. 递增版本号（访问令牌和刷新令牌随之失效）
+	if err := s.userRepo.IncrementTokenVersion(ctx, userID); err != nil {
+		metrics.RecordUserOperation("revoke_sessions", false)
+		return fmt.Errorf("service: revoke sessions: %w", err)
+	}
+
+	slog.InfoContext(ctx, "User sessions revoked",
+		"user_id", userID,
+	)
+	metrics.RecordUserOperation("revoke_sessions", true)
+
+	return nil
+}
+
+// TransferUserData 合并账户（如同一个人注册了两个账户）
+//
+// 源用户的第三方账户关联和未吊销的 API Key 转移给目标用户，源用户标记为已合并（记录 merged_into）
+// 并吊销所有 Token，两步验证、一次性令牌和历史密码被删除。目标用户的角色和额外权限不变，
+// 转移的 API Key 只能在目标用户的权限范围内使用。审计日志不转移，仍然指向源用户。
+// 两个用户关联了同一提供方的第三方账户时拒绝合并；DryRun 时只返回将要转移的数据。
+func (s *userService) TransferUserData(ctx context.Context, input TransferInput) (TransferReport, error) {
+	report := TransferReport{FromUserID: input.FromUserID, ToUserID: input.ToUserID, DryRun: input.DryRun}
+	if input.FromUserID == input.ToUserID {
+		return report, ErrInvalidInput
+	}
+
+	// 1. 检查源用户状态（已删除的用户需要先恢复）
+	source, err := s.userRepo.GetUserByIDAnyStatus(ctx, input.FromUserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return report, ErrUserNotFound
+		}
+		return report, fmt.Errorf("service: get source user: %w", err)
+	}
+	if !CanTransitionUser(source.Status, repository.UserStatusMerged) {
+		slog.WarnContext(ctx, "Merge failed: invalid source status",
+			"from_user_id", source.ID,
+			"status", source.Status,
 		)
+		return report, ErrInvalidUserTransition
+	}
 
-		return nil
+	// 2. 检查目标用户状态（只能合并到正常或待激活的用户）
+	target, err := s.userRepo.GetUserByIDAnyStatus(ctx, input.ToUserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return report, ErrUserNotFound
+		}
+		return report, fmt.Errorf("service: get target user: %w", err)
+	}
+	if target.Status != repository.UserStatusActive && target.Status != repository.UserStatusPending {
+		slog.WarnContext(ctx, "Merge failed: invalid target status",
+			"to_user_id", target.ID,
+			"status", target.Status,
+		)
+		return report, ErrMergeTarget
+	}
+
+	// 3. 查询将要转移的数据
+	preview, err := s.userRepo.PreviewMerge(ctx, source.ID, target.ID)
+	if err != nil {
+		return report, fmt.Errorf("service: preview merge: %w", err)
+	}
+	report.Identities = preview.Identities
+	report.ConflictingIdentities = preview.ConflictingIdentities
+	report.APIKeys = preview.APIKeys
+	if input.DryRun {
+		return report, nil
+	}
+	if len(preview.ConflictingIdentities) > 0 {
+		slog.WarnContext(ctx, "Merge failed: conflicting identities",
+			"from_user_id", source.ID,
+			"to_user_id", target.ID,
+			"providers", preview.ConflictingIdentities,
+		)
+		return report, ErrMergeConflict
+	}
+
+	// 4. 在事务中合并（只在源用户状态未被并发修改时生效）
+	result, err := s.userRepo.MergeUser(ctx, source.ID, target.ID, source.Status)
+	if err != nil {
+		slog.ErrorContext(ctx, "Failed to merge users",
+			"error", err,
+			"from_user_id", source.ID,
+			"to_user_id", target.ID,
+		)
+		metrics.RecordUserOperation("merge", false)
+		return report, fmt.Errorf("service: merge user: %w", err)
+	}
+	if !result.Merged {
+		metrics.RecordUserOperation("merge", false)
+		return report, ErrInvalidUserTransition
+	}
+
+	slog.InfoContext(ctx, "Users merged",
+		"from_user_id", source.ID,
+		"to_user_id", target.ID,
+		"identities", result.Identities,
+		"api_keys", result.APIKeys,
+	)
+	metrics.RecordUserOperation("merge", true)
+
+	s.auditor.Record(ctx, audit.Event{
+		Action:     AuditActionUserMerge,
+		TargetType: AuditTargetUser,
+		TargetID:   source.ID,
+		Changes: audit.Diff(
+			map[string]any{"status": source.Status, "merged_into": nil},
+			map[string]any{"status": repository.UserStatusMerged, "merged_into": target.ID},
+		),
 	})
+	return report, nil
+}
+
+// ============================================================================
+// 批量操作
+// ============================================================================
+
+// batchChange 一条批量操作修改前后的用户（用于审计日志和清理缓存）
+type batchChange struct {
+	before repository.User
+	after  repository.User
 }
 
-// BatchUpdateUsers 批量更新用户（示例：批量操作事务）
+// BatchUsers 批量更新、停用、删除用户
 //
-// 场景：批量更新多个用户的信息
-// 使用事务确保要么全部成功，要么全部失败
-func (s *userService) BatchUpdateUsers(ctx context.Context, updates []BatchUpdateInput) error {
-	if len(updates) == 0 {
+// 每一条操作都按单个用户接口的资源级策略校验（只能操作级别更低的用户）。
+// 原子模式在一个事务中执行，任一操作失败时全部回滚，失败的操作返回其错误，其余返回 ErrBatchAborted；
+// 尽力模式逐条执行，返回每一条操作各自的结果。请求本身无效时返回错误，不执行任何操作。
+func (s *userService) BatchUsers(ctx context.Context, input BatchInput) ([]BatchResult, error) {
+	// 1. 校验请求
+	if err := validateBatchInput(input); err != nil {
+		slog.WarnContext(ctx, "Invalid batch user operations",
+			"mode", input.Mode,
+			"operations", len(input.Operations),
+			"error", err,
+		)
+		return nil, err
+	}
+
+	results := make([]BatchResult, len(input.Operations))
+	for i, op := range input.Operations {
+		results[i] = BatchResult{UserID: op.UserID, Action: op.Action}
+	}
+
+	// 2. 执行
+	var err error
+	if input.Mode == BatchModeAtomic {
+		err = s.batchAtomic(ctx, input.Operations, results)
+	} else {
+		s.batchBestEffort(ctx, input.Operations, results)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	failed := 0
+	for _, result := range results {
+		if result.Err != nil {
+			failed++
+		}
+	}
+	slog.InfoContext(ctx, "Batch user operations completed",
+		"mode", input.Mode,
+		"operations", len(results),
+		"failed", failed,
+	)
+	return results, nil
+}
+
+// validateBatchInput 校验批量操作的执行方式、条数和每一条操作的参数
+func validateBatchInput(input BatchInput) error {
+	if input.Mode != BatchModeAtomic && input.Mode != BatchModeBestEffort {
+		return ErrInvalidInput
+	}
+	if len(input.Operations) == 0 {
 		return ErrInvalidInput
 	}
+	if len(input.Operations) > MaxBatchOperations {
+		return ErrBatchTooLarge
+	}
 
-	// 使用批量事务操作
-	ops := make([]func(ctx context.Context, tx *sql.Tx) error, 0, len(updates))
+	seen := make(map[int64]struct{}, len(input.Operations))
+	for _, op := range input.Operations {
+		if _, ok := batchPolicyActions[op.Action]; !ok || op.UserID <= 0 {
+			return ErrInvalidInput
+		}
+		if op.Action == BatchActionSuspend && strings.TrimSpace(op.Reason) == "" {
+			return ErrInvalidInput
+		}
+		// 同一用户的多条操作在两种模式下的结果不一致，要求调用方合并
+		if _, ok := seen[op.UserID]; ok {
+			return ErrInvalidInput
+		}
+		seen[op.UserID] = struct{}{}
+	}
+	return nil
+}
 
-	for _, update := range updates {
-		// 捕获循环变量
-		u := update
+// batchBestEffort 逐条执行（每一条操作由 Repository 各自清理缓存）
+func (s *userService) batchBestEffort(ctx context.Context, ops []BatchOperation, results []BatchResult) {
+	for i, op := range ops {
+		change, err := s.applyBatchOperation(ctx, s.userRepo, op)
+		if err != nil {
+			results[i].Err = err
+			metrics.RecordUserOperation(string(op.Action), false)
+			continue
+		}
+		s.recordBatchOperation(ctx, op, change)
+	}
+}
 
-		ops = append(ops, func(ctx context.Context, tx *sql.Tx) error {
-			// 获取当前用户信息
-			currentUser, err := s.userRepo.GetUserByID(ctx, u.UserID)
+// batchAtomic 在一个事务中执行，提交后清理所有受影响用户的缓存
+func (s *userService) batchAtomic(ctx context.Context, ops []BatchOperation, results []BatchResult) error {
+	changes := make([]batchChange, len(ops))
+	failed, failedErr := -1, error(nil)
+
+	txOps := make([]func(ctx context.Context, tx *sql.Tx) error, 0, len(ops))
+	for i, op := range ops {
+		txOps = append(txOps, func(ctx context.Context, tx *sql.Tx) error {
+			change, err := s.applyBatchOperation(ctx, s.userRepo.WithTxRepo(tx), op)
 			if err != nil {
-				if errors.Is(err, sql.ErrNoRows) {
-					return fmt.Errorf("user %d not found: %w", u.UserID, ErrUserNotFound)
-				}
-				return fmt.Errorf("failed to get user %d: %w", u.UserID, err)
+				failed, failedErr = i, err
+				return err
 			}
+			changes[i] = change
+			return nil
+		})
+	}
 
-			// 准备更新参数
-			username := currentUser.Username
-			if u.Username != nil && *u.Username != "" {
-				username = *u.Username
-			}
+	if err := s.userRepo.BatchExecInTx(ctx, txOps); err != nil {
+		// 开始或提交事务失败，没有可以归属的操作
+		if failed < 0 {
+			slog.ErrorContext(ctx, "Batch user transaction failed", "error", err)
+			return fmt.Errorf("service: batch users: %w", err)
+		}
 
-			email := currentUser.Email
-			if u.Email != nil && *u.Email != "" {
-				email = *u.Email
-			}
+		for i := range results {
+			results[i].Err = ErrBatchAborted
+		}
+		results[failed].Err = failedErr
+		metrics.RecordUserOperation(string(ops[failed].Action), false)
+		return nil
+	}
+
+	// 事务中的写操作在提交前清理了缓存，提交后再清理一次
+	users := make([]repository.User, 0, 2*len(changes))
+	for _, change := range changes {
+		users = append(users, change.before, change.after)
+	}
+	if err := s.userRepo.InvalidateUserCache(ctx, users...); err != nil {
+		slog.WarnContext(ctx, "Failed to invalidate user cache after batch", "error", err)
+	}
+
+	for i, op := range ops {
+		s.recordBatchOperation(ctx, op, changes[i])
+	}
+	return nil
+}
+
+// applyBatchOperation 执行一条批量操作（repo 为事务中的 Repository 时在事务中执行）
+func (s *userService) applyBatchOperation(ctx context.Context, repo repository.UserRepositoryInterface, op BatchOperation) (batchChange, error) {
+	// 1. 加载目标用户（包含停用和已删除的用户）
+	user, err := repo.GetUserByIDAnyStatus(ctx, op.UserID)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return batchChange{}, ErrUserNotFound
+		}
+		return batchChange{}, fmt.Errorf("service: get user: %w", err)
+	}
 
-			avatar := currentUser.Avatar
-			if u.Avatar != nil {
-				if *u.Avatar != "" {
-					avatar = sql.NullString{String: *u.Avatar, Valid: true}
-				} else {
-					avatar = sql.NullString{Valid: false}
-				}
+	// 2. 资源级校验（RequirePolicy 只能校验路径中的单个用户）
+	role := auth.Role(user.Role)
+	if role == "" {
+		role = auth.RoleUser
+	}
+	resource := &auth.Resource{
+		Type:       auth.ResourceUser,
+		ID:         user.ID,
+		OwnerID:    user.ID,
+		Attributes: map[string]any{auth.AttrRole: role},
+	}
+	if err := auth.Can(ctx, batchPolicyActions[op.Action], resource); err != nil {
+		return batchChange{}, ErrBatchForbidden
+	}
+	// 与单个用户的删除接口一致：删除只允许超级管理员
+	if op.Action == BatchActionDelete {
+		if claims := auth.ClaimsFromContext(ctx); claims == nil || claims.Role != auth.RoleSuperAdmin {
+			return batchChange{}, ErrBatchForbidden
+		}
+	}
+
+	// 3. 执行
+	change := batchChange{before: user, after: user}
+	switch op.Action {
+	case BatchActionUpdate:
+		// 与 UpdateUser 一致：只能修改正常和待激活的用户
+		if user.Status != repository.UserStatusActive && user.Status != repository.UserStatusPending {
+			return batchChange{}, ErrUserNotFound
+		}
+		if op.Username != nil && *op.Username != "" {
+			change.after.Username = *op.Username
+		}
+		if op.Email != nil && *op.Email != "" && *op.Email != user.Email {
+			existing, err := repo.GetUserByEmail(ctx, *op.Email)
+			if err == nil && existing.ID != user.ID {
+				return batchChange{}, ErrUserExists
 			}
+			change.after.Email = *op.Email
+		}
+		if op.Avatar != nil {
+			change.after.Avatar = sql.NullString{String: *op.Avatar, Valid: *op.Avatar != ""}
+		}
 
-			// 使用事务中的 Repository 执行更新
-			txRepo := s.userRepo.WithTxRepo(tx)
-			return txRepo.UpdateUser(ctx, repository.UpdateUserParams{
-				ID:       u.UserID,
-				Username: username,
-				Email:    email,
-				Avatar:   avatar,
-			})
+		err = repo.UpdateUser(ctx, repository.UpdateUserParams{
+			ID:       user.ID,
+			Username: change.after.Username,
+			Email:    change.after.Email,
+			Avatar:   change.after.Avatar,
 		})
+		if err != nil {
+			return batchChange{}, fmt.Errorf("service: update user: %w", err)
+		}
+
+	case BatchActionSuspend, BatchActionDelete:
+		to, reason := repository.UserStatusDeleted, ""
+		if op.Action == BatchActionSuspend {
+			to, reason = repository.UserStatusSuspended, strings.TrimSpace(op.Reason)
+		}
+		if !CanTransitionUser(user.Status, to) {
+			return batchChange{}, ErrInvalidUserTransition
+		}
+
+		changed, err := repo.UpdateUserStatus(ctx, user.ID, user.Status, to, reason)
+		if err != nil {
+			return batchChange{}, fmt.Errorf("service: update user status: %w", err)
+		}
+		if !changed {
+			return batchChange{}, ErrInvalidUserTransition
+		}
+		change.after = s.withStatus(user, to, reason)
 	}
 
-	// 在一个事务中执行所有操作
-	return s.userRepo.BatchExecInTx(ctx, ops)
+	return change, nil
+}
+
+// recordBatchOperation 记录一条成功的批量操作的审计日志和指标（原子模式在事务提交后记录）
+func (s *userService) recordBatchOperation(ctx context.Context, op BatchOperation, change batchChange) {
+	before, after := change.before, change.after
+	switch op.Action {
+	case BatchActionUpdate:
+		s.auditor.Record(ctx, audit.Event{
+			Action:     AuditActionUserUpdate,
+			TargetType: AuditTargetUser,
+			TargetID:   before.ID,
+			Changes:    audit.Diff(userFields(before.Username, before.Email, before.Avatar), userFields(after.Username, after.Email, after.Avatar)),
+		})
+	case BatchActionSuspend:
+		s.recordStatusChange(ctx, AuditActionUserSuspend, before, after.Status, after.StatusReason.String)
+	case BatchActionDelete:
+		s.auditor.Record(ctx, audit.Event{
+			Action:     AuditActionUserDelete,
+			TargetType: AuditTargetUser,
+			TargetID:   before.ID,
+			Changes:    audit.Diff(map[string]any{"username": before.Username, "email": before.Email}, nil),
+		})
+		metrics.UserDeletions.Inc()
+	}
+	metrics.RecordUserOperation(string(op.Action), true)
 }
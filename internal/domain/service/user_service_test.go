@@ -5,14 +5,43 @@ import (
 	"database/sql"
 	"errors"
 	"testing"
+	"time"
 
 	"gin_demo/internal/repository"
+	"gin_demo/pkg/audit"
+	"gin_demo/pkg/auth"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 	"golang.org/x/crypto/bcrypt"
 )
 
+// testHasher 测试用的密码哈希器（bcrypt，cost 与测试数据一致，登录时不触发升级）
+var testHasher = func() auth.PasswordHasher {
+	hasher, err := auth.NewPasswordHasher(auth.PasswordHashConfig{
+		Algorithm:  auth.PasswordAlgorithmBcrypt,
+		BcryptCost: bcrypt.DefaultCost,
+		Argon2id:   auth.DefaultArgon2idParams(),
+	})
+	if err != nil {
+		panic(err)
+	}
+	return hasher
+}()
+
+// testPasswords 测试用的密码策略（不限制）
+var testPasswords = NewPasswordService(auth.NewPasswordPolicy(auth.PasswordPolicyConfig{}, nil), testHasher, nil, 0)
+
+// recordingAuditor 记录审计事件（同步，便于断言）
+type recordingAuditor struct {
+	events []audit.Event
+}
+
+func (r *recordingAuditor) Record(_ context.Context, event audit.Event) {
+	r.events = append(r.events, event)
+}
+
 // MockUserRepository 是 UserRepository 的 mock 实现
 type MockUserRepository struct {
 	mock.Mock
@@ -23,6 +52,11 @@ func (m *MockUserRepository) GetUserByID(ctx context.Context, userID int64) (rep
 	return args.Get(0).(repository.User), args.Error(1)
 }
 
+func (m *MockUserRepository) GetUserByIDAnyStatus(ctx context.Context, userID int64) (repository.User, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(repository.User), args.Error(1)
+}
+
 func (m *MockUserRepository) GetUserByEmail(ctx context.Context, email string) (repository.User, error) {
 	args := m.Called(ctx, email)
 	return args.Get(0).(repository.User), args.Error(1)
@@ -48,11 +82,71 @@ func (m *MockUserRepository) UpdateUserPassword(ctx context.Context, userID int6
 	return args.Error(0)
 }
 
-func (m *MockUserRepository) DeleteUser(ctx context.Context, userID int64) error {
+func (m *MockUserRepository) GetUserPassword(ctx context.Context, userID int64) (string, error) {
+	args := m.Called(ctx, userID)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockUserRepository) RehashUserPassword(ctx context.Context, userID int64, oldHash, newHash string) (bool, error) {
+	args := m.Called(ctx, userID, oldHash, newHash)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepository) GetPasswordHistory(ctx context.Context, userID int64, limit int32) ([]string, error) {
+	args := m.Called(ctx, userID, limit)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockUserRepository) AddPasswordHistory(ctx context.Context, userID int64, passwordHash string, keep int32) error {
+	args := m.Called(ctx, userID, passwordHash, keep)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) UpdateUserStatus(ctx context.Context, userID int64, from, to int16, reason string) (bool, error) {
+	args := m.Called(ctx, userID, from, to, reason)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepository) AnonymizeUser(ctx context.Context, userID int64) (bool, error) {
+	args := m.Called(ctx, userID)
+	return args.Bool(0), args.Error(1)
+}
+
+func (m *MockUserRepository) PreviewMerge(ctx context.Context, fromUserID, toUserID int64) (repository.MergePreview, error) {
+	args := m.Called(ctx, fromUserID, toUserID)
+	return args.Get(0).(repository.MergePreview), args.Error(1)
+}
+
+func (m *MockUserRepository) MergeUser(ctx context.Context, fromUserID, toUserID int64, from int16) (repository.MergeResult, error) {
+	args := m.Called(ctx, fromUserID, toUserID, from)
+	return args.Get(0).(repository.MergeResult), args.Error(1)
+}
+
+func (m *MockUserRepository) GetUserPermissions(ctx context.Context, userID int64) ([]string, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).([]string), args.Error(1)
+}
+
+func (m *MockUserRepository) UpdateUserRole(ctx context.Context, userID int64, role string, permissions []string) error {
+	args := m.Called(ctx, userID, role, permissions)
+	return args.Error(0)
+}
+
+func (m *MockUserRepository) GetUserTokenVersion(ctx context.Context, userID int64) (int64, error) {
+	args := m.Called(ctx, userID)
+	return args.Get(0).(int64), args.Error(1)
+}
+
+func (m *MockUserRepository) IncrementTokenVersion(ctx context.Context, userID int64) error {
 	args := m.Called(ctx, userID)
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) MarkEmailVerified(ctx context.Context, userID int64) (bool, error) {
+	args := m.Called(ctx, userID)
+	return args.Bool(0), args.Error(1)
+}
+
 func (m *MockUserRepository) WithTx(ctx context.Context, fn func(tx *sql.Tx) error) error {
 	args := m.Called(ctx, fn)
 	return args.Error(0)
@@ -63,18 +157,28 @@ func (m *MockUserRepository) BatchExecInTx(ctx context.Context, ops []func(ctx c
 	return args.Error(0)
 }
 
+func (m *MockUserRepository) InvalidateUserCache(ctx context.Context, users ...repository.User) error {
+	args := m.Called(ctx, users)
+	return args.Error(0)
+}
+
 func (m *MockUserRepository) WithTxRepo(tx *sql.Tx) repository.UserRepositoryInterface {
 	args := m.Called(tx)
 	return args.Get(0).(repository.UserRepositoryInterface)
 }
 
-func (m *MockUserRepository) ListUsers(ctx context.Context, limit, offset int32) ([]repository.User, error) {
-	args := m.Called(ctx, limit, offset)
+func (m *MockUserRepository) ListUsers(ctx context.Context, filter repository.UserFilter, limit, offset int32) ([]repository.User, error) {
+	args := m.Called(ctx, filter, limit, offset)
 	return args.Get(0).([]repository.User), args.Error(1)
 }
 
-func (m *MockUserRepository) CountUsers(ctx context.Context) (int64, error) {
-	args := m.Called(ctx)
+func (m *MockUserRepository) ListUsersByCursor(ctx context.Context, filter repository.UserFilter, page repository.KeysetPage) (repository.KeysetResult[repository.User], error) {
+	args := m.Called(ctx, filter, page)
+	return args.Get(0).(repository.KeysetResult[repository.User]), args.Error(1)
+}
+
+func (m *MockUserRepository) CountUsers(ctx context.Context, filter repository.UserFilter) (int64, error) {
+	args := m.Called(ctx, filter)
 	return args.Get(0).(int64), args.Error(1)
 }
 
@@ -84,7 +188,7 @@ func TestUserService_Register(t *testing.T) {
 
 	t.Run("成功注册", func(t *testing.T) {
 		mockRepo := new(MockUserRepository)
-		service := NewUserService(mockRepo)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
 
 		// Mock 数据
 		input := RegisterInput{
@@ -118,7 +222,7 @@ func TestUserService_Register(t *testing.T) {
 
 	t.Run("邮箱已存在", func(t *testing.T) {
 		mockRepo := new(MockUserRepository)
-		service := NewUserService(mockRepo)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
 
 		input := RegisterInput{
 			Username: "testuser",
@@ -144,7 +248,7 @@ func TestUserService_Register(t *testing.T) {
 
 	t.Run("用户名已存在", func(t *testing.T) {
 		mockRepo := new(MockUserRepository)
-		service := NewUserService(mockRepo)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
 
 		input := RegisterInput{
 			Username: "existinguser",
@@ -171,7 +275,7 @@ func TestUserService_Register(t *testing.T) {
 
 	t.Run("参数验证失败", func(t *testing.T) {
 		mockRepo := new(MockUserRepository)
-		service := NewUserService(mockRepo)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
 
 		testCases := []struct {
 			name  string
@@ -198,7 +302,7 @@ func TestUserService_Login(t *testing.T) {
 
 	t.Run("成功登录", func(t *testing.T) {
 		mockRepo := new(MockUserRepository)
-		service := NewUserService(mockRepo)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
 
 		input := LoginInput{
 			Email:    "test@example.com",
@@ -230,7 +334,7 @@ func TestUserService_Login(t *testing.T) {
 
 	t.Run("用户不存在", func(t *testing.T) {
 		mockRepo := new(MockUserRepository)
-		service := NewUserService(mockRepo)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
 
 		input := LoginInput{
 			Email:    "notexist@example.com",
@@ -250,7 +354,7 @@ func TestUserService_Login(t *testing.T) {
 
 	t.Run("密码错误", func(t *testing.T) {
 		mockRepo := new(MockUserRepository)
-		service := NewUserService(mockRepo)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
 
 		input := LoginInput{
 			Email:    "test@example.com",
@@ -278,6 +382,57 @@ func TestUserService_Login(t *testing.T) {
 		assert.Equal(t, ErrInvalidPassword, err)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("邮箱未验证", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
+
+		hashedPasswordBytes, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
+		user := repository.User{
+			ID:       1,
+			Email:    "test@example.com",
+			Password: string(hashedPasswordBytes),
+			Status:   repository.UserStatusPending,
+		}
+		mockRepo.On("GetUserByEmail", ctx, "test@example.com").Return(user, nil)
+
+		_, err := service.Login(ctx, LoginInput{Email: "test@example.com", Password: "password123"})
+		assert.ErrorIs(t, err, ErrEmailNotVerified)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("过时的哈希登录后自动升级", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
+
+		// cost 低于当前配置
+		legacyHash, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+		user := repository.User{ID: 1, Email: "test@example.com", Password: string(legacyHash), Status: 1}
+		mockRepo.On("GetUserByEmail", ctx, "test@example.com").Return(user, nil)
+		mockRepo.On("RehashUserPassword", ctx, int64(1), string(legacyHash), mock.MatchedBy(func(newHash string) bool {
+			ok, _ := testHasher.Verify("password123", newHash)
+			return ok && !testHasher.NeedsRehash(newHash)
+		})).Return(true, nil)
+
+		_, err := service.Login(ctx, LoginInput{Email: "test@example.com", Password: "password123"})
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("升级哈希失败不影响登录", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
+
+		legacyHash, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.MinCost)
+		user := repository.User{ID: 1, Email: "test@example.com", Password: string(legacyHash), Status: 1}
+		mockRepo.On("GetUserByEmail", ctx, "test@example.com").Return(user, nil)
+		mockRepo.On("RehashUserPassword", ctx, int64(1), string(legacyHash), mock.AnythingOfType("string")).
+			Return(false, errors.New("database down"))
+
+		_, err := service.Login(ctx, LoginInput{Email: "test@example.com", Password: "password123"})
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
 }
 
 // TestUserService_GetUserByID 测试通过ID获取用户
@@ -286,7 +441,7 @@ func TestUserService_GetUserByID(t *testing.T) {
 
 	t.Run("成功获取用户", func(t *testing.T) {
 		mockRepo := new(MockUserRepository)
-		service := NewUserService(mockRepo)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
 
 		userID := int64(1)
 		expectedUser := repository.User{
@@ -309,7 +464,7 @@ func TestUserService_GetUserByID(t *testing.T) {
 
 	t.Run("用户不存在", func(t *testing.T) {
 		mockRepo := new(MockUserRepository)
-		service := NewUserService(mockRepo)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
 
 		userID := int64(999)
 
@@ -331,7 +486,8 @@ func TestUserService_UpdateUser(t *testing.T) {
 
 	t.Run("成功更新用户", func(t *testing.T) {
 		mockRepo := new(MockUserRepository)
-		service := NewUserService(mockRepo)
+		auditor := &recordingAuditor{}
+		service := NewUserService(mockRepo, testHasher, testPasswords, auditor)
 
 		userID := int64(1)
 		newUsername := "newusername"
@@ -360,11 +516,22 @@ func TestUserService_UpdateUser(t *testing.T) {
 		// 断言
 		assert.NoError(t, err)
 		mockRepo.AssertExpectations(t)
+
+		// 审计日志只记录发生变化的字段
+		assert.Equal(t, []audit.Event{{
+			Action:     AuditActionUserUpdate,
+			TargetType: AuditTargetUser,
+			TargetID:   userID,
+			Changes: audit.Changes{
+				"username": {From: "oldusername", To: newUsername},
+				"email":    {From: "oldemail@example.com", To: newEmail},
+			},
+		}}, auditor.events)
 	})
 
 	t.Run("用户不存在", func(t *testing.T) {
 		mockRepo := new(MockUserRepository)
-		service := NewUserService(mockRepo)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
 
 		userID := int64(999)
 		newUsername := "newusername"
@@ -387,7 +554,7 @@ func TestUserService_UpdateUser(t *testing.T) {
 
 	t.Run("邮箱已被占用", func(t *testing.T) {
 		mockRepo := new(MockUserRepository)
-		service := NewUserService(mockRepo)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
 
 		userID := int64(1)
 		newEmail := "existing@example.com"
@@ -428,7 +595,7 @@ func TestUserService_ChangePassword(t *testing.T) {
 
 	t.Run("成功修改密码", func(t *testing.T) {
 		mockRepo := new(MockUserRepository)
-		service := NewUserService(mockRepo)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
 
 		userID := int64(1)
 		oldPassword := "password123"
@@ -438,19 +605,14 @@ func TestUserService_ChangePassword(t *testing.T) {
 		hashedOldPasswordBytes, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
 		hashedOldPassword := string(hashedOldPasswordBytes)
 
-		user := repository.User{
-			ID:       userID,
-			Password: hashedOldPassword,
-			Status:   1,
-		}
-
 		input := ChangePasswordInput{
 			UserID:      userID,
 			OldPassword: oldPassword,
 			NewPassword: newPassword,
 		}
 
-		mockRepo.On("GetUserByID", ctx, userID).Return(user, nil)
+		mockRepo.On("GetUserPassword", ctx, userID).Return(hashedOldPassword, nil)
+		mockRepo.On("GetUserByID", ctx, userID).Return(repository.User{ID: userID, Username: "testuser", Email: "test@example.com"}, nil)
 		mockRepo.On("UpdateUserPassword", ctx, userID, mock.AnythingOfType("string")).Return(nil)
 
 		// 执行测试
@@ -463,7 +625,7 @@ func TestUserService_ChangePassword(t *testing.T) {
 
 	t.Run("旧密码错误", func(t *testing.T) {
 		mockRepo := new(MockUserRepository)
-		service := NewUserService(mockRepo)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
 
 		userID := int64(1)
 		wrongOldPassword := "wrongpassword"
@@ -473,19 +635,13 @@ func TestUserService_ChangePassword(t *testing.T) {
 		hashedOldPasswordBytes, _ := bcrypt.GenerateFromPassword([]byte("password123"), bcrypt.DefaultCost)
 		hashedOldPassword := string(hashedOldPasswordBytes)
 
-		user := repository.User{
-			ID:       userID,
-			Password: hashedOldPassword,
-			Status:   1,
-		}
-
 		input := ChangePasswordInput{
 			UserID:      userID,
 			OldPassword: wrongOldPassword,
 			NewPassword: newPassword,
 		}
 
-		mockRepo.On("GetUserByID", ctx, userID).Return(user, nil)
+		mockRepo.On("GetUserPassword", ctx, userID).Return(hashedOldPassword, nil)
 
 		// 执行测试
 		err := service.ChangePassword(ctx, input)
@@ -498,7 +654,7 @@ func TestUserService_ChangePassword(t *testing.T) {
 
 	t.Run("参数验证失败", func(t *testing.T) {
 		mockRepo := new(MockUserRepository)
-		service := NewUserService(mockRepo)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
 
 		testCases := []struct {
 			name  string
@@ -524,7 +680,7 @@ func TestUserService_DeleteUser(t *testing.T) {
 
 	t.Run("成功删除用户", func(t *testing.T) {
 		mockRepo := new(MockUserRepository)
-		service := NewUserService(mockRepo)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
 
 		userID := int64(1)
 		user := repository.User{
@@ -532,8 +688,8 @@ func TestUserService_DeleteUser(t *testing.T) {
 			Status: 1,
 		}
 
-		mockRepo.On("GetUserByID", ctx, userID).Return(user, nil)
-		mockRepo.On("DeleteUser", ctx, userID).Return(nil)
+		mockRepo.On("GetUserByIDAnyStatus", ctx, userID).Return(user, nil)
+		mockRepo.On("UpdateUserStatus", ctx, userID, repository.UserStatusActive, repository.UserStatusDeleted, "").Return(true, nil)
 
 		// 执行测试
 		err := service.DeleteUser(ctx, userID)
@@ -545,11 +701,11 @@ func TestUserService_DeleteUser(t *testing.T) {
 
 	t.Run("用户不存在", func(t *testing.T) {
 		mockRepo := new(MockUserRepository)
-		service := NewUserService(mockRepo)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
 
 		userID := int64(999)
 
-		mockRepo.On("GetUserByID", ctx, userID).Return(repository.User{}, sql.ErrNoRows)
+		mockRepo.On("GetUserByIDAnyStatus", ctx, userID).Return(repository.User{}, sql.ErrNoRows)
 
 		// 执行测试
 		err := service.DeleteUser(ctx, userID)
@@ -562,7 +718,7 @@ func TestUserService_DeleteUser(t *testing.T) {
 
 	t.Run("删除失败", func(t *testing.T) {
 		mockRepo := new(MockUserRepository)
-		service := NewUserService(mockRepo)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
 
 		userID := int64(1)
 		user := repository.User{
@@ -572,8 +728,8 @@ func TestUserService_DeleteUser(t *testing.T) {
 
 		dbError := errors.New("database error")
 
-		mockRepo.On("GetUserByID", ctx, userID).Return(user, nil)
-		mockRepo.On("DeleteUser", ctx, userID).Return(dbError)
+		mockRepo.On("GetUserByIDAnyStatus", ctx, userID).Return(user, nil)
+		mockRepo.On("UpdateUserStatus", ctx, userID, repository.UserStatusActive, repository.UserStatusDeleted, "").Return(false, dbError)
 
 		// 执行测试
 		err := service.DeleteUser(ctx, userID)
@@ -582,6 +738,273 @@ func TestUserService_DeleteUser(t *testing.T) {
 		assert.Error(t, err)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("已删除的用户不能再次删除", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
+
+		mockRepo.On("GetUserByIDAnyStatus", ctx, int64(1)).Return(repository.User{ID: 1, Status: repository.UserStatusDeleted}, nil)
+
+		err := service.DeleteUser(ctx, 1)
+		assert.ErrorIs(t, err, ErrInvalidUserTransition)
+		mockRepo.AssertNotCalled(t, "UpdateUserStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// TestCanTransitionUser 测试用户状态机
+func TestCanTransitionUser(t *testing.T) {
+	const (
+		active    = repository.UserStatusActive
+		suspended = repository.UserStatusSuspended
+		pending   = repository.UserStatusPending
+		deleted   = repository.UserStatusDeleted
+		erased    = repository.UserStatusErased
+		merged    = repository.UserStatusMerged
+	)
+
+	cases := []struct {
+		from, to int16
+		want     bool
+	}{
+		{pending, active, true},
+		{pending, suspended, true},
+		{pending, deleted, true},
+		{active, suspended, true},
+		{active, deleted, true},
+		{active, pending, false},
+		{suspended, active, true},
+		{suspended, deleted, true},
+		{suspended, pending, false},
+		{deleted, active, true},
+		{deleted, suspended, false},
+		{deleted, pending, false},
+		{pending, erased, true},
+		{active, erased, true},
+		{suspended, erased, true},
+		{deleted, erased, true},
+		{erased, active, false},
+		{erased, deleted, false},
+		{pending, merged, true},
+		{active, merged, true},
+		{suspended, merged, true},
+		{deleted, merged, false},
+		{erased, merged, false},
+		{merged, erased, true},
+		{merged, active, false},
+		{merged, deleted, false},
+		{active, active, false},
+		{99, active, false},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.want, CanTransitionUser(tc.from, tc.to), "%d -> %d", tc.from, tc.to)
+	}
+}
+
+// TestUserService_Lifecycle 测试停用、重新启用和恢复
+func TestUserService_Lifecycle(t *testing.T) {
+	ctx := context.Background()
+	suspended := repository.User{ID: 1, Username: "alice", Status: repository.UserStatusSuspended,
+		StatusReason: sql.NullString{String: "spam", Valid: true}}
+	deleted := repository.User{ID: 1, Username: "alice", Status: repository.UserStatusDeleted,
+		DeletedAt: sql.NullTime{Time: time.Now(), Valid: true}}
+
+	t.Run("停用并记录原因", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		auditor := &recordingAuditor{}
+		service := NewUserService(mockRepo, testHasher, testPasswords, auditor)
+
+		mockRepo.On("GetUserByIDAnyStatus", ctx, int64(1)).Return(repository.User{ID: 1, Status: repository.UserStatusActive}, nil)
+		mockRepo.On("UpdateUserStatus", ctx, int64(1), repository.UserStatusActive, repository.UserStatusSuspended, "spam").Return(true, nil)
+
+		user, err := service.SuspendUser(ctx, 1, "  spam ")
+		require.NoError(t, err)
+		assert.Equal(t, repository.UserStatusSuspended, user.Status)
+		assert.Equal(t, "spam", user.StatusReason.String)
+
+		require.Len(t, auditor.events, 1)
+		assert.Equal(t, AuditActionUserSuspend, auditor.events[0].Action)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("停用需要原因", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
+
+		_, err := service.SuspendUser(ctx, 1, " ")
+		assert.Equal(t, ErrInvalidInput, err)
+		mockRepo.AssertNotCalled(t, "GetUserByIDAnyStatus", mock.Anything, mock.Anything)
+	})
+
+	t.Run("重新启用", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
+
+		mockRepo.On("GetUserByIDAnyStatus", ctx, int64(1)).Return(suspended, nil)
+		mockRepo.On("UpdateUserStatus", ctx, int64(1), repository.UserStatusSuspended, repository.UserStatusActive, "").Return(true, nil)
+
+		user, err := service.ReactivateUser(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, repository.UserStatusActive, user.Status)
+		assert.False(t, user.StatusReason.Valid)
+	})
+
+	t.Run("已删除的用户只能恢复，不能重新启用", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
+
+		mockRepo.On("GetUserByIDAnyStatus", ctx, int64(1)).Return(deleted, nil)
+
+		_, err := service.ReactivateUser(ctx, 1)
+		assert.ErrorIs(t, err, ErrInvalidUserTransition)
+
+		mockRepo.On("UpdateUserStatus", ctx, int64(1), repository.UserStatusDeleted, repository.UserStatusActive, "").Return(true, nil)
+		user, err := service.RestoreUser(ctx, 1)
+		require.NoError(t, err)
+		assert.Equal(t, repository.UserStatusActive, user.Status)
+		assert.False(t, user.DeletedAt.Valid)
+	})
+
+	t.Run("恢复未删除的用户", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
+
+		mockRepo.On("GetUserByIDAnyStatus", ctx, int64(1)).Return(suspended, nil)
+
+		_, err := service.RestoreUser(ctx, 1)
+		assert.ErrorIs(t, err, ErrInvalidUserTransition)
+		mockRepo.AssertNotCalled(t, "UpdateUserStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("状态被并发修改", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
+
+		mockRepo.On("GetUserByIDAnyStatus", ctx, int64(1)).Return(suspended, nil)
+		mockRepo.On("UpdateUserStatus", ctx, int64(1), repository.UserStatusSuspended, repository.UserStatusActive, "").Return(false, nil)
+
+		_, err := service.ReactivateUser(ctx, 1)
+		assert.ErrorIs(t, err, ErrInvalidUserTransition)
+	})
+}
+
+// TestUserService_TransferUserData 测试合并账户
+func TestUserService_TransferUserData(t *testing.T) {
+	ctx := context.Background()
+	source := repository.User{ID: 1, Username: "alice2", Status: repository.UserStatusSuspended}
+	target := repository.User{ID: 2, Username: "alice", Status: repository.UserStatusActive}
+	preview := repository.MergePreview{Identities: []string{"github"}, APIKeys: []string{"gd_abc", "gd_def"}}
+
+	t.Run("预演不修改数据", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
+
+		mockRepo.On("GetUserByIDAnyStatus", ctx, int64(1)).Return(source, nil)
+		mockRepo.On("GetUserByIDAnyStatus", ctx, int64(2)).Return(target, nil)
+		mockRepo.On("PreviewMerge", ctx, int64(1), int64(2)).Return(preview, nil)
+
+		report, err := service.TransferUserData(ctx, TransferInput{FromUserID: 1, ToUserID: 2, DryRun: true})
+		require.NoError(t, err)
+		assert.True(t, report.DryRun)
+		assert.Equal(t, []string{"github"}, report.Identities)
+		assert.Equal(t, []string{"gd_abc", "gd_def"}, report.APIKeys)
+		mockRepo.AssertNotCalled(t, "MergeUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("合并并记录审计日志", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		auditor := &recordingAuditor{}
+		service := NewUserService(mockRepo, testHasher, testPasswords, auditor)
+
+		mockRepo.On("GetUserByIDAnyStatus", ctx, int64(1)).Return(source, nil)
+		mockRepo.On("GetUserByIDAnyStatus", ctx, int64(2)).Return(target, nil)
+		mockRepo.On("PreviewMerge", ctx, int64(1), int64(2)).Return(preview, nil)
+		mockRepo.On("MergeUser", ctx, int64(1), int64(2), repository.UserStatusSuspended).
+			Return(repository.MergeResult{Merged: true, Identities: 1, APIKeys: 2}, nil)
+
+		report, err := service.TransferUserData(ctx, TransferInput{FromUserID: 1, ToUserID: 2})
+		require.NoError(t, err)
+		assert.False(t, report.DryRun)
+		assert.Equal(t, []string{"github"}, report.Identities)
+
+		require.Len(t, auditor.events, 1)
+		assert.Equal(t, AuditActionUserMerge, auditor.events[0].Action)
+		assert.Equal(t, int64(1), auditor.events[0].TargetID)
+		assert.Equal(t, int64(2), auditor.events[0].Changes["merged_into"].To)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("第三方账户冲突", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
+
+		mockRepo.On("GetUserByIDAnyStatus", ctx, int64(1)).Return(source, nil)
+		mockRepo.On("GetUserByIDAnyStatus", ctx, int64(2)).Return(target, nil)
+		mockRepo.On("PreviewMerge", ctx, int64(1), int64(2)).
+			Return(repository.MergePreview{ConflictingIdentities: []string{"google"}}, nil)
+
+		// 预演时报告冲突
+		report, err := service.TransferUserData(ctx, TransferInput{FromUserID: 1, ToUserID: 2, DryRun: true})
+		require.NoError(t, err)
+		assert.Equal(t, []string{"google"}, report.ConflictingIdentities)
+
+		_, err = service.TransferUserData(ctx, TransferInput{FromUserID: 1, ToUserID: 2})
+		assert.ErrorIs(t, err, ErrMergeConflict)
+		mockRepo.AssertNotCalled(t, "MergeUser", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+
+	t.Run("不能合并到自己", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
+
+		_, err := service.TransferUserData(ctx, TransferInput{FromUserID: 1, ToUserID: 1})
+		assert.Equal(t, ErrInvalidInput, err)
+		mockRepo.AssertNotCalled(t, "GetUserByIDAnyStatus", mock.Anything, mock.Anything)
+	})
+
+	t.Run("源用户已删除", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
+
+		mockRepo.On("GetUserByIDAnyStatus", ctx, int64(1)).Return(repository.User{ID: 1, Status: repository.UserStatusDeleted}, nil)
+
+		_, err := service.TransferUserData(ctx, TransferInput{FromUserID: 1, ToUserID: 2})
+		assert.ErrorIs(t, err, ErrInvalidUserTransition)
+	})
+
+	t.Run("目标用户已停用", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
+
+		mockRepo.On("GetUserByIDAnyStatus", ctx, int64(1)).Return(source, nil)
+		mockRepo.On("GetUserByIDAnyStatus", ctx, int64(2)).Return(repository.User{ID: 2, Status: repository.UserStatusSuspended}, nil)
+
+		_, err := service.TransferUserData(ctx, TransferInput{FromUserID: 1, ToUserID: 2, DryRun: true})
+		assert.ErrorIs(t, err, ErrMergeTarget)
+	})
+
+	t.Run("目标用户不存在", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
+
+		mockRepo.On("GetUserByIDAnyStatus", ctx, int64(1)).Return(source, nil)
+		mockRepo.On("GetUserByIDAnyStatus", ctx, int64(2)).Return(repository.User{}, sql.ErrNoRows)
+
+		_, err := service.TransferUserData(ctx, TransferInput{FromUserID: 1, ToUserID: 2})
+		assert.ErrorIs(t, err, ErrUserNotFound)
+	})
+
+	t.Run("状态被并发修改", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
+
+		mockRepo.On("GetUserByIDAnyStatus", ctx, int64(1)).Return(source, nil)
+		mockRepo.On("GetUserByIDAnyStatus", ctx, int64(2)).Return(target, nil)
+		mockRepo.On("PreviewMerge", ctx, int64(1), int64(2)).Return(preview, nil)
+		mockRepo.On("MergeUser", ctx, int64(1), int64(2), repository.UserStatusSuspended).Return(repository.MergeResult{}, nil)
+
+		_, err := service.TransferUserData(ctx, TransferInput{FromUserID: 1, ToUserID: 2})
+		assert.ErrorIs(t, err, ErrInvalidUserTransition)
+	})
 }
 
 // TestUserService_ListUsers 测试用户列表
@@ -590,7 +1013,7 @@ func TestUserService_ListUsers(t *testing.T) {
 
 	t.Run("成功获取用户列表", func(t *testing.T) {
 		mockRepo := new(MockUserRepository)
-		service := NewUserService(mockRepo)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
 
 		limit := int32(10)
 		offset := int32(0)
@@ -602,11 +1025,11 @@ func TestUserService_ListUsers(t *testing.T) {
 			{ID: 3, Username: "user3", Email: "user3@example.com"},
 		}
 
-		mockRepo.On("ListUsers", ctx, limit, offset).Return(expectedUsers, nil)
-		mockRepo.On("CountUsers", ctx).Return(total, nil)
+		mockRepo.On("ListUsers", ctx, repository.UserFilter{}, limit, offset).Return(expectedUsers, nil)
+		mockRepo.On("CountUsers", ctx, repository.UserFilter{}).Return(total, nil)
 
 		// 执行测试
-		users, count, err := service.ListUsers(ctx, limit, offset)
+		users, count, err := service.ListUsers(ctx, repository.UserFilter{}, limit, offset)
 
 		// 断言
 		assert.NoError(t, err)
@@ -618,17 +1041,17 @@ func TestUserService_ListUsers(t *testing.T) {
 
 	t.Run("空列表", func(t *testing.T) {
 		mockRepo := new(MockUserRepository)
-		service := NewUserService(mockRepo)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
 
 		limit := int32(10)
 		offset := int32(100)
 		total := int64(50)
 
-		mockRepo.On("ListUsers", ctx, limit, offset).Return([]repository.User{}, nil)
-		mockRepo.On("CountUsers", ctx).Return(total, nil)
+		mockRepo.On("ListUsers", ctx, repository.UserFilter{}, limit, offset).Return([]repository.User{}, nil)
+		mockRepo.On("CountUsers", ctx, repository.UserFilter{}).Return(total, nil)
 
 		// 执行测试
-		users, count, err := service.ListUsers(ctx, limit, offset)
+		users, count, err := service.ListUsers(ctx, repository.UserFilter{}, limit, offset)
 
 		// 断言
 		assert.NoError(t, err)
@@ -636,4 +1059,371 @@ func TestUserService_ListUsers(t *testing.T) {
 		assert.Equal(t, total, count)
 		mockRepo.AssertExpectations(t)
 	})
+
+	t.Run("过滤条件同时用于列表和总数", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
+
+		filter := repository.UserFilter{
+			Statuses: []int16{repository.UserStatusSuspended},
+			Query:    "alice",
+			Sort:     repository.UserSortUsername,
+			Order:    repository.SortAsc,
+		}
+		mockRepo.On("ListUsers", ctx, filter, int32(10), int32(0)).Return([]repository.User{{ID: 1}}, nil)
+		mockRepo.On("CountUsers", ctx, filter).Return(int64(1), nil)
+
+		_, count, err := service.ListUsers(ctx, filter, 10, 0)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), count)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("不支持的排序字段", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
+
+		_, _, err := service.ListUsers(ctx, repository.UserFilter{Sort: "password"}, 10, 0)
+		assert.Equal(t, ErrInvalidInput, err)
+
+		now := time.Now()
+		_, _, err = service.ListUsers(ctx, repository.UserFilter{CreatedFrom: now, CreatedTo: now.Add(-time.Hour)}, 10, 0)
+		assert.Equal(t, ErrInvalidInput, err)
+		mockRepo.AssertNotCalled(t, "ListUsers", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// TestUserService_ListUsersByCursor 测试用户列表的游标分页
+func TestUserService_ListUsersByCursor(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("按排序方向设置查询方向", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
+
+		filter := repository.UserFilter{Order: repository.SortAsc}
+		expected := repository.KeysetResult[repository.User]{Items: []repository.User{{ID: 1}}, HasNext: true}
+		mockRepo.On("ListUsersByCursor", ctx, filter, repository.KeysetPage{Limit: 10}).Return(expected, nil)
+
+		// 第一页没有游标，忽略 Backward
+		result, err := service.ListUsersByCursor(ctx, filter, repository.KeysetPage{Backward: true, Limit: 10})
+		require.NoError(t, err)
+		assert.Equal(t, expected, result)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("默认倒序", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
+
+		page := repository.KeysetPage{HasCursor: true, CreatedAt: time.Now(), ID: 5, Limit: 10}
+		desc := page
+		desc.Desc = true
+		mockRepo.On("ListUsersByCursor", ctx, repository.UserFilter{}, desc).
+			Return(repository.KeysetResult[repository.User]{}, nil)
+
+		_, err := service.ListUsersByCursor(ctx, repository.UserFilter{}, page)
+		require.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("只支持按创建时间排序", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
+
+		_, err := service.ListUsersByCursor(ctx, repository.UserFilter{Sort: repository.UserSortUsername}, repository.KeysetPage{Limit: 10})
+		assert.Equal(t, ErrInvalidInput, err)
+		mockRepo.AssertNotCalled(t, "ListUsersByCursor", mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// TestUserService_UpdateUserRole 测试更新用户角色
+func TestUserService_UpdateUserRole(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("成功更新角色", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		auditor := &recordingAuditor{}
+		service := NewUserService(mockRepo, testHasher, testPasswords, auditor)
+
+		mockRepo.On("GetUserByID", ctx, int64(1)).Return(repository.User{ID: 1, Role: "user"}, nil)
+		mockRepo.On("GetUserPermissions", ctx, int64(1)).Return([]string{}, nil)
+		mockRepo.On("UpdateUserRole", ctx, int64(1), "admin", []string{"system:monitor"}).Return(nil)
+
+		err := service.UpdateUserRole(ctx, UpdateRoleInput{
+			UserID:      1,
+			Role:        auth.RoleAdmin,
+			Permissions: []auth.Permission{auth.PermissionSystemMonitor},
+		})
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+		if assert.Len(t, auditor.events, 1) {
+			assert.Equal(t, AuditActionUserRoleUpdate, auditor.events[0].Action)
+			assert.Equal(t, audit.Changes{
+				"role":        {From: "user", To: "admin"},
+				"permissions": {From: []string{}, To: []string{"system:monitor"}},
+			}, auditor.events[0].Changes)
+		}
+	})
+
+	t.Run("无效角色或权限", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
+
+		testCases := []struct {
+			name  string
+			input UpdateRoleInput
+		}{
+			{"未知角色", UpdateRoleInput{UserID: 1, Role: "root"}},
+			{"游客角色", UpdateRoleInput{UserID: 1, Role: auth.RoleGuest}},
+			{"未知权限", UpdateRoleInput{UserID: 1, Role: auth.RoleUser, Permissions: []auth.Permission{"user:everything"}}},
+		}
+
+		for _, tc := range testCases {
+			t.Run(tc.name, func(t *testing.T) {
+				err := service.UpdateUserRole(ctx, tc.input)
+				assert.Equal(t, ErrInvalidInput, err)
+			})
+		}
+		mockRepo.AssertNotCalled(t, "UpdateUserRole", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
+}
+
+// TestUserService_GetUserPermissions 测试获取额外权限
+func TestUserService_GetUserPermissions(t *testing.T) {
+	ctx := context.Background()
+
+	mockRepo := new(MockUserRepository)
+	service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
+
+	mockRepo.On("GetUserPermissions", ctx, int64(1)).Return([]string{"content:audit", "legacy:unknown"}, nil)
+
+	permissions, err := service.GetUserPermissions(ctx, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []auth.Permission{auth.PermissionContentAudit}, permissions)
+	mockRepo.AssertExpectations(t)
+}
+
+// TestUserService_GetTokenVersion 测试获取 Token 版本号
+func TestUserService_GetTokenVersion(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("成功获取", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
+
+		mockRepo.On("GetUserTokenVersion", ctx, int64(1)).Return(int64(3), nil)
+
+		version, err := service.GetTokenVersion(ctx, 1)
+
+		assert.NoError(t, err)
+		assert.Equal(t, int64(3), version)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("用户不存在或已禁用", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
+
+		mockRepo.On("GetUserTokenVersion", ctx, int64(999)).Return(int64(0), sql.ErrNoRows)
+
+		_, err := service.GetTokenVersion(ctx, 999)
+
+		assert.Equal(t, ErrUserNotFound, err)
+		mockRepo.AssertExpectations(t)
+	})
+}
+
+// TestUserService_RevokeAllSessions 测试登出所有设备
+func TestUserService_RevokeAllSessions(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("成功吊销", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
+
+		mockRepo.On("GetUserByID", ctx, int64(1)).Return(repository.User{ID: 1, Status: 1}, nil)
+		mockRepo.On("IncrementTokenVersion", ctx, int64(1)).Return(nil)
+
+		err := service.RevokeAllSessions(ctx, 1)
+
+		assert.NoError(t, err)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("用户不存在", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
+
+		mockRepo.On("GetUserByID", ctx, int64(999)).Return(repository.User{}, sql.ErrNoRows)
+
+		err := service.RevokeAllSessions(ctx, 999)
+
+		assert.Equal(t, ErrUserNotFound, err)
+		mockRepo.AssertNotCalled(t, "IncrementTokenVersion", mock.Anything, mock.Anything)
+	})
+}
+
+// TestUserService_BatchUsers 测试批量操作用户
+func TestUserService_BatchUsers(t *testing.T) {
+	ctx := auth.WithClaims(context.Background(), &auth.RBACClaims{UserID: 9, Role: auth.RoleSuperAdmin})
+	alice := repository.User{ID: 2, Username: "alice", Email: "alice@example.com", Role: string(auth.RoleUser), Status: repository.UserStatusActive}
+	bob := repository.User{ID: 3, Username: "bob", Email: "bob@example.com", Role: string(auth.RoleUser), Status: repository.UserStatusActive}
+	root := repository.User{ID: 4, Username: "root", Email: "root@example.com", Role: string(auth.RoleSuperAdmin), Status: repository.UserStatusActive}
+	email := "alice@new.example.com"
+
+	t.Run("尽力模式逐条执行", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		auditor := &recordingAuditor{}
+		service := NewUserService(mockRepo, testHasher, testPasswords, auditor)
+
+		mockRepo.On("GetUserByIDAnyStatus", ctx, int64(2)).Return(alice, nil)
+		mockRepo.On("GetUserByEmail", ctx, email).Return(repository.User{}, sql.ErrNoRows)
+		mockRepo.On("UpdateUser", ctx, repository.UpdateUserParams{ID: 2, Username: "alice", Email: email}).Return(nil)
+		mockRepo.On("GetUserByIDAnyStatus", ctx, int64(4)).Return(root, nil)
+		mockRepo.On("GetUserByIDAnyStatus", ctx, int64(5)).Return(repository.User{}, sql.ErrNoRows)
+
+		results, err := service.BatchUsers(ctx, BatchInput{
+			Mode: BatchModeBestEffort,
+			Operations: []BatchOperation{
+				{Action: BatchActionUpdate, UserID: 2, Email: &email},
+				{Action: BatchActionSuspend, UserID: 4, Reason: "spam"}, // 同级的超级管理员
+				{Action: BatchActionDelete, UserID: 5},
+			},
+		})
+		require.NoError(t, err)
+		require.Len(t, results, 3)
+		assert.NoError(t, results[0].Err)
+		assert.Equal(t, ErrBatchForbidden, results[1].Err)
+		assert.Equal(t, ErrUserNotFound, results[2].Err)
+
+		require.Len(t, auditor.events, 1)
+		assert.Equal(t, AuditActionUserUpdate, auditor.events[0].Action)
+		mockRepo.AssertNotCalled(t, "UpdateUserStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+		mockRepo.AssertNotCalled(t, "InvalidateUserCache", mock.Anything, mock.Anything)
+	})
+
+	// runInTx 模拟 BatchExecInTx：按顺序执行，遇到错误停止
+	runInTx := func(mockRepo *MockUserRepository) {
+		mockRepo.On("WithTxRepo", (*sql.Tx)(nil)).Return(mockRepo)
+		mockRepo.On("BatchExecInTx", ctx, mock.Anything).Return(nil).Run(func(args mock.Arguments) {
+			for _, op := range args.Get(1).([]func(context.Context, *sql.Tx) error) {
+				if op(ctx, nil) != nil {
+					return
+				}
+			}
+		})
+	}
+
+	t.Run("原子模式提交后清理缓存", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		auditor := &recordingAuditor{}
+		service := NewUserService(mockRepo, testHasher, testPasswords, auditor)
+		runInTx(mockRepo)
+
+		mockRepo.On("GetUserByIDAnyStatus", ctx, int64(2)).Return(alice, nil)
+		mockRepo.On("UpdateUserStatus", ctx, int64(2), repository.UserStatusActive, repository.UserStatusSuspended, "spam").Return(true, nil)
+		mockRepo.On("GetUserByIDAnyStatus", ctx, int64(3)).Return(bob, nil)
+		mockRepo.On("UpdateUserStatus", ctx, int64(3), repository.UserStatusActive, repository.UserStatusDeleted, "").Return(true, nil)
+		mockRepo.On("InvalidateUserCache", ctx, mock.MatchedBy(func(users []repository.User) bool {
+			return len(users) == 4 && users[0].ID == 2 && users[2].ID == 3
+		})).Return(nil)
+
+		results, err := service.BatchUsers(ctx, BatchInput{
+			Mode: BatchModeAtomic,
+			Operations: []BatchOperation{
+				{Action: BatchActionSuspend, UserID: 2, Reason: " spam "},
+				{Action: BatchActionDelete, UserID: 3},
+			},
+		})
+		require.NoError(t, err)
+		assert.NoError(t, results[0].Err)
+		assert.NoError(t, results[1].Err)
+
+		require.Len(t, auditor.events, 2)
+		assert.Equal(t, AuditActionUserSuspend, auditor.events[0].Action)
+		assert.Equal(t, AuditActionUserDelete, auditor.events[1].Action)
+		mockRepo.AssertExpectations(t)
+	})
+
+	t.Run("原子模式任一条失败时全部回滚", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		auditor := &recordingAuditor{}
+		service := NewUserService(mockRepo, testHasher, testPasswords, auditor)
+		mockRepo.On("WithTxRepo", (*sql.Tx)(nil)).Return(mockRepo)
+		mockRepo.On("BatchExecInTx", ctx, mock.Anything).Return(ErrInvalidUserTransition).Run(func(args mock.Arguments) {
+			for _, op := range args.Get(1).([]func(context.Context, *sql.Tx) error) {
+				if op(ctx, nil) != nil {
+					return
+				}
+			}
+		})
+
+		deleted := bob
+		deleted.Status = repository.UserStatusDeleted
+		mockRepo.On("GetUserByIDAnyStatus", ctx, int64(2)).Return(alice, nil)
+		mockRepo.On("UpdateUserStatus", ctx, int64(2), repository.UserStatusActive, repository.UserStatusDeleted, "").Return(true, nil)
+		mockRepo.On("GetUserByIDAnyStatus", ctx, int64(3)).Return(deleted, nil)
+
+		results, err := service.BatchUsers(ctx, BatchInput{
+			Mode: BatchModeAtomic,
+			Operations: []BatchOperation{
+				{Action: BatchActionDelete, UserID: 2},
+				{Action: BatchActionDelete, UserID: 3}, // 已删除
+				{Action: BatchActionDelete, UserID: 4}, // 未执行
+			},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, ErrBatchAborted, results[0].Err)
+		assert.Equal(t, ErrInvalidUserTransition, results[1].Err)
+		assert.Equal(t, ErrBatchAborted, results[2].Err)
+
+		assert.Empty(t, auditor.events)
+		mockRepo.AssertNotCalled(t, "InvalidateUserCache", mock.Anything, mock.Anything)
+	})
+
+	t.Run("无效的请求", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
+
+		tooMany := make([]BatchOperation, MaxBatchOperations+1)
+		for i := range tooMany {
+			tooMany[i] = BatchOperation{Action: BatchActionDelete, UserID: int64(i + 1)}
+		}
+		_, err := service.BatchUsers(ctx, BatchInput{Mode: BatchModeAtomic, Operations: tooMany})
+		assert.Equal(t, ErrBatchTooLarge, err)
+
+		for _, input := range []BatchInput{
+			{Mode: BatchModeAtomic},
+			{Mode: "parallel", Operations: []BatchOperation{{Action: BatchActionDelete, UserID: 2}}},
+			{Mode: BatchModeAtomic, Operations: []BatchOperation{{Action: "purge", UserID: 2}}},
+			{Mode: BatchModeAtomic, Operations: []BatchOperation{{Action: BatchActionSuspend, UserID: 2, Reason: " "}}},
+			{Mode: BatchModeAtomic, Operations: []BatchOperation{
+				{Action: BatchActionDelete, UserID: 2},
+				{Action: BatchActionSuspend, UserID: 2, Reason: "spam"},
+			}},
+		} {
+			_, err := service.BatchUsers(ctx, input)
+			assert.Equal(t, ErrInvalidInput, err)
+		}
+		mockRepo.AssertNotCalled(t, "GetUserByIDAnyStatus", mock.Anything, mock.Anything)
+	})
+
+	t.Run("删除只允许超级管理员", func(t *testing.T) {
+		mockRepo := new(MockUserRepository)
+		service := NewUserService(mockRepo, testHasher, testPasswords, audit.NopRecorder{})
+		adminCtx := auth.WithClaims(context.Background(), &auth.RBACClaims{UserID: 1, Role: auth.RoleAdmin})
+
+		mockRepo.On("GetUserByIDAnyStatus", adminCtx, int64(2)).Return(alice, nil)
+
+		results, err := service.BatchUsers(adminCtx, BatchInput{
+			Mode:       BatchModeBestEffort,
+			Operations: []BatchOperation{{Action: BatchActionDelete, UserID: 2}},
+		})
+		require.NoError(t, err)
+		assert.Equal(t, ErrBatchForbidden, results[0].Err)
+		mockRepo.AssertNotCalled(t, "UpdateUserStatus", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+	})
 }
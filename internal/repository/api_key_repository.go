@@ -0,0 +1,157 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"gin_demo/pkg/cache"
+	dbContext "gin_demo/pkg/database"
+	"gin_demo/pkg/tenant"
+)
+
+// APIKeyRepository API Key 仓库层
+//
+// 吊销必须立即生效，认证时每次都查库，不使用缓存。
+// 按用户查询时按 context 中的租户（tenant.ID）过滤；GetAPIKeyByPrefix 不过滤租户，
+// 调用方使用返回的 TenantID 确定 Key 所属的租户。
+type APIKeyRepository struct {
+	*BaseRepository[ApiKey]
+	queries *Queries
+}
+
+// NewAPIKeyRepository 创建 API Key 仓库实例
+func NewAPIKeyRepository(db *sql.DB, cacheManager *cache.Manager) *APIKeyRepository {
+	return &APIKeyRepository{
+		BaseRepository: NewBaseRepository[ApiKey](db, cacheManager),
+		queries:        New(db),
+	}
+}
+
+// ============================================================================
+// 查询方法
+// ============================================================================
+
+// GetAPIKeyByPrefix 通过公开标识查询 API Key
+func (r *APIKeyRepository) GetAPIKeyByPrefix(ctx context.Context, prefix string) (ApiKey, error) {
+	ctx, cancel := dbContext.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return r.queries.GetAPIKeyByPrefix(ctx, prefix)
+}
+
+// ListAPIKeyScopes 列出 API Key 的权限范围
+func (r *APIKeyRepository) ListAPIKeyScopes(ctx context.Context, keyID int64) ([]string, error) {
+	ctx, cancel := dbContext.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return r.queries.ListAPIKeyScopes(ctx, keyID)
+}
+
+// ListUserAPIKeys 列出用户未吊销的 API Key
+func (r *APIKeyRepository) ListUserAPIKeys(ctx context.Context, userID int64) ([]ApiKey, error) {
+	ctx, cancel := dbContext.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return r.queries.ListAPIKeysByUser(ctx, ListAPIKeysByUserParams{
+		TenantID: tenant.ID(ctx),
+		UserID:   userID,
+	})
+}
+
+// ListUserAPIKeyScopes 列出用户所有未吊销 API Key 的权限范围（一次查询，避免 N+1）
+func (r *APIKeyRepository) ListUserAPIKeyScopes(ctx context.Context, userID int64) (map[int64][]string, error) {
+	ctx, cancel := dbContext.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.queries.ListAPIKeyScopesByUser(ctx, ListAPIKeyScopesByUserParams{
+		TenantID: tenant.ID(ctx),
+		UserID:   userID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	scopes := make(map[int64][]string)
+	for _, row := range rows {
+		scopes[row.ApiKeyID] = append(scopes[row.ApiKeyID], row.Permission)
+	}
+	return scopes, nil
+}
+
+// CountActiveAPIKeys 统计用户可用的 API Key 数量
+func (r *APIKeyRepository) CountActiveAPIKeys(ctx context.Context, userID int64, now time.Time) (int64, error) {
+	ctx, cancel := dbContext.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return r.queries.CountActiveAPIKeys(ctx, CountActiveAPIKeysParams{
+		TenantID: tenant.ID(ctx),
+		UserID:   userID,
+		Now:      now,
+	})
+}
+
+// ============================================================================
+// 写操作
+// ============================================================================
+
+// CreateAPIKey 创建 API Key 并保存权限范围（事务内执行）
+func (r *APIKeyRepository) CreateAPIKey(ctx context.Context, params CreateAPIKeyParams, scopes []string) (int64, error) {
+	var keyID int64
+
+	err := r.WithTx(ctx, func(tx *sql.Tx) error {
+		q := r.queries.WithTx(tx)
+
+		result, err := q.CreateAPIKey(ctx, params)
+		if err != nil {
+			return fmt.Errorf("repository: create api key: %w", err)
+		}
+
+		keyID, err = result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("repository: get api key id: %w", err)
+		}
+
+		for _, scope := range scopes {
+			if err := q.AddAPIKeyScope(ctx, AddAPIKeyScopeParams{
+				ApiKeyID:   keyID,
+				Permission: scope,
+			}); err != nil {
+				return fmt.Errorf("repository: add api key scope: %w", err)
+			}
+		}
+		return nil
+	})
+
+	return keyID, err
+}
+
+// RevokeAPIKey 吊销用户的 API Key
+func (r *APIKeyRepository) RevokeAPIKey(ctx context.Context, userID, keyID int64) (bool, error) {
+	ctx, cancel := dbContext.WithQueryTimeout(ctx)
+	defer cancel()
+
+	n, err := r.queries.RevokeAPIKey(ctx, RevokeAPIKeyParams{
+		ID:       keyID,
+		TenantID: tenant.ID(ctx),
+		UserID:   userID,
+	})
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// TouchAPIKey 记录最近使用时间
+func (r *APIKeyRepository) TouchAPIKey(ctx context.Context, keyID int64, now time.Time, interval time.Duration) error {
+	ctx, cancel := dbContext.WithQueryTimeout(ctx)
+	defer cancel()
+
+	_, err := r.queries.TouchAPIKey(ctx, TouchAPIKeyParams{
+		Now:    now,
+		ID:     keyID,
+		Before: now.Add(-interval),
+	})
+	return err
+}
@@ -0,0 +1,44 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// APIKeyRepositoryInterface API Key 仓库接口（用于依赖注入和测试）
+type APIKeyRepositoryInterface interface {
+	// ========================================
+	// 查询方法
+	// ========================================
+
+	// GetAPIKeyByPrefix 通过公开标识查询 API Key（不存在时返回 sql.ErrNoRows）
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (ApiKey, error)
+
+	// ListAPIKeyScopes 列出 API Key 的权限范围
+	ListAPIKeyScopes(ctx context.Context, keyID int64) ([]string, error)
+
+	// ListUserAPIKeys 列出用户未吊销的 API Key
+	ListUserAPIKeys(ctx context.Context, userID int64) ([]ApiKey, error)
+
+	// ListUserAPIKeyScopes 列出用户所有未吊销 API Key 的权限范围（按 Key ID 分组）
+	ListUserAPIKeyScopes(ctx context.Context, userID int64) (map[int64][]string, error)
+
+	// CountActiveAPIKeys 统计用户可用的 API Key 数量（未吊销且未过期）
+	CountActiveAPIKeys(ctx context.Context, userID int64, now time.Time) (int64, error)
+
+	// ========================================
+	// 写操作方法
+	// ========================================
+
+	// CreateAPIKey 创建 API Key 并保存权限范围（事务内执行），返回新 Key 的 ID
+	CreateAPIKey(ctx context.Context, params CreateAPIKeyParams, scopes []string) (int64, error)
+
+	// RevokeAPIKey 吊销用户的 API Key，返回 false 表示不存在、不属于该用户或已吊销
+	RevokeAPIKey(ctx context.Context, userID, keyID int64) (bool, error)
+
+	// TouchAPIKey 记录最近使用时间（距上次记录不足 interval 时跳过）
+	TouchAPIKey(ctx context.Context, keyID int64, now time.Time, interval time.Duration) error
+}
+
+// 确保 APIKeyRepository 实现了接口
+var _ APIKeyRepositoryInterface = (*APIKeyRepository)(nil)
@@ -0,0 +1,58 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"gin_demo/pkg/cache"
+	dbContext "gin_demo/pkg/database"
+	"gin_demo/pkg/tenant"
+)
+
+// AuditRepository 审计日志仓库层
+//
+// 审计日志只追加，不更新也不缓存；过期记录由保留期清理任务删除。
+type AuditRepository struct {
+	*BaseRepository[AuditEvent]
+	queries *Queries
+}
+
+// NewAuditRepository 创建审计日志仓库实例
+func NewAuditRepository(db *sql.DB, cacheManager *cache.Manager) *AuditRepository {
+	return &AuditRepository{
+		BaseRepository: NewBaseRepository[AuditEvent](db, cacheManager),
+		queries:        New(db),
+	}
+}
+
+// CreateAuditEvents 批量写入审计事件（一个事务内执行）
+//
+// 由审计写入器在后台调用，事件的租户在记录时已确定。
+func (r *AuditRepository) CreateAuditEvents(ctx context.Context, events []CreateAuditEventParams) error {
+	if len(events) == 0 {
+		return nil
+	}
+
+	ctx, cancel := dbContext.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return r.WithTx(ctx, func(tx *sql.Tx) error {
+		q := r.queries.WithTx(tx)
+		for _, event := range events {
+			if err := q.CreateAuditEvent(ctx, event); err != nil {
+				return fmt.Errorf("repository: create audit event: %w", err)
+			}
+		}
+		return nil
+	})
+}
+
+// ListAuditEvents 查询审计事件（限定在 context 中的租户内）
+func (r *AuditRepository) ListAuditEvents(ctx context.Context, params ListAuditEventsParams) ([]AuditEvent, error) {
+	ctx, cancel := dbContext.WithQueryTimeout(ctx)
+	defer cancel()
+
+	params.TenantID = tenant.ID(ctx)
+	return r.queries.ListAuditEvents(ctx, params)
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+)
+
+// AuditRepositoryInterface 审计日志仓库接口（用于依赖注入和测试）
+type AuditRepositoryInterface interface {
+	// CreateAuditEvents 批量写入审计事件（事件自带租户，不使用 context 中的租户）
+	CreateAuditEvents(ctx context.Context, events []CreateAuditEventParams) error
+
+	// ListAuditEvents 查询 context 中租户的审计事件（按 ID 倒序）
+	ListAuditEvents(ctx context.Context, params ListAuditEventsParams) ([]AuditEvent, error)
+}
+
+// 确保 AuditRepository 实现了 AuditRepositoryInterface 接口
+var _ AuditRepositoryInterface = (*AuditRepository)(nil)
@@ -5,6 +5,7 @@ import (
 	"database/sql"
 	"fmt"
 	"gin_demo/pkg/cache"
+	"slices"
 	"strconv"
 	"time"
 )
@@ -93,6 +94,81 @@ func (r *BaseRepository[T]) ExecWithIndexCache(
 	return r.cache.ExecByIDWithIndexes(ctx, entity, id, indexes, execFn)
 }
 
+// ============================================================================
+// Keyset 分页
+// ============================================================================
+
+// KeysetPage keyset（游标）分页请求，按 (created_at, id) 定位
+//
+// 与 LIMIT/OFFSET 相比，翻页代价不随页数增加，并发插入时也不会跳过或重复记录。
+type KeysetPage struct {
+	HasCursor bool      // false 表示第一页
+	CreatedAt time.Time // 游标所在记录的创建时间
+	ID        int64     // 游标所在记录的 ID
+	Backward  bool      // true 表示返回游标之前的一页
+	Desc      bool      // 列表按 (created_at, id) 倒序
+	Limit     int32     // 每页数量
+}
+
+// KeysetResult keyset 分页结果（Items 始终按列表顺序排列）
+type KeysetResult[T any] struct {
+	Items   []T
+	HasNext bool // 最后一条之后还有记录
+	HasPrev bool // 第一条之前还有记录
+}
+
+// KeysetClause 构建 keyset 分页的条件和排序
+//
+// timeColumn、idColumn 必须是常量列名；游标位置通过占位符传入。
+// 没有游标时 cond 为空。向前翻页时按相反方向查询，由 ListWithKeyset 反转结果。
+func KeysetClause(timeColumn, idColumn string, page KeysetPage) (cond string, args []any, orderBy string) {
+	// 实际查询方向：倒序列表向后翻页、正序列表向前翻页时按倒序查询
+	desc := page.Desc != page.Backward
+	op, direction := ">", " ASC"
+	if desc {
+		op, direction = "<", " DESC"
+	}
+
+	orderBy = timeColumn + direction + ", " + idColumn + direction
+	if !page.HasCursor {
+		return "", nil, orderBy
+	}
+
+	cond = "(" + timeColumn + " " + op + " ? OR (" + timeColumn + " = ? AND " + idColumn + " " + op + " ?))"
+	return cond, []any{page.CreatedAt, page.CreatedAt, page.ID}, orderBy
+}
+
+// ListWithKeyset 通用的 keyset 分页查询（泛型）
+//
+// queryFn 使用 KeysetClause 返回的条件和排序查询最多 limit 条记录（比每页数量多一条，用于判断是否还有更多）。
+func (r *BaseRepository[T]) ListWithKeyset(
+	ctx context.Context,
+	page KeysetPage,
+	queryFn func(ctx context.Context, cond string, args []any, orderBy string, limit int32) ([]T, error),
+) (KeysetResult[T], error) {
+	cond, args, orderBy := KeysetClause("created_at", "id", page)
+	items, err := queryFn(ctx, cond, args, orderBy, page.Limit+1)
+	if err != nil {
+		return KeysetResult[T]{}, err
+	}
+
+	more := len(items) > int(page.Limit)
+	if more {
+		items = items[:page.Limit]
+	}
+
+	result := KeysetResult[T]{Items: items}
+	if page.Backward {
+		slices.Reverse(result.Items)
+		result.HasPrev = more
+		result.HasNext = true // 游标所在记录在这一页之后
+	} else {
+		result.HasNext = more
+		result.HasPrev = page.HasCursor
+	}
+	return result, nil
+}
+
 // ============================================================================
 // 事务管理
 // ============================================================================
@@ -0,0 +1,70 @@
+package repository
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestKeysetClause 测试 keyset 分页条件的构建
+func TestKeysetClause(t *testing.T) {
+	at := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+	t.Run("第一页", func(t *testing.T) {
+		cond, args, orderBy := KeysetClause("created_at", "id", KeysetPage{Desc: true, Limit: 10})
+		assert.Empty(t, cond)
+		assert.Nil(t, args)
+		assert.Equal(t, "created_at DESC, id DESC", orderBy)
+	})
+
+	t.Run("倒序列表的下一页", func(t *testing.T) {
+		cond, args, orderBy := KeysetClause("created_at", "id", KeysetPage{HasCursor: true, CreatedAt: at, ID: 7, Desc: true})
+		assert.Equal(t, "(created_at < ? OR (created_at = ? AND id < ?))", cond)
+		assert.Equal(t, []any{at, at, int64(7)}, args)
+		assert.Equal(t, "created_at DESC, id DESC", orderBy)
+	})
+
+	t.Run("倒序列表的上一页按正序查询", func(t *testing.T) {
+		cond, _, orderBy := KeysetClause("created_at", "id", KeysetPage{HasCursor: true, CreatedAt: at, ID: 7, Desc: true, Backward: true})
+		assert.Equal(t, "(created_at > ? OR (created_at = ? AND id > ?))", cond)
+		assert.Equal(t, "created_at ASC, id ASC", orderBy)
+	})
+}
+
+// TestBaseRepository_ListWithKeyset 测试多取一条判断是否还有更多，以及向前翻页的结果顺序
+func TestBaseRepository_ListWithKeyset(t *testing.T) {
+	repo := NewBaseRepository[int64](nil, nil)
+	rows := func(ids ...int64) func(context.Context, string, []any, string, int32) ([]int64, error) {
+		return func(_ context.Context, _ string, _ []any, _ string, limit int32) ([]int64, error) {
+			assert.Equal(t, int32(3), limit, "比每页数量多取一条")
+			return ids, nil
+		}
+	}
+
+	t.Run("第一页", func(t *testing.T) {
+		result, err := repo.ListWithKeyset(context.Background(), KeysetPage{Limit: 2}, rows(5, 4, 3))
+		require.NoError(t, err)
+		assert.Equal(t, []int64{5, 4}, result.Items)
+		assert.True(t, result.HasNext)
+		assert.False(t, result.HasPrev)
+	})
+
+	t.Run("最后一页", func(t *testing.T) {
+		result, err := repo.ListWithKeyset(context.Background(), KeysetPage{HasCursor: true, ID: 4, Limit: 2}, rows(3))
+		require.NoError(t, err)
+		assert.Equal(t, []int64{3}, result.Items)
+		assert.False(t, result.HasNext)
+		assert.True(t, result.HasPrev)
+	})
+
+	t.Run("上一页", func(t *testing.T) {
+		result, err := repo.ListWithKeyset(context.Background(), KeysetPage{HasCursor: true, ID: 3, Backward: true, Limit: 2}, rows(4, 5))
+		require.NoError(t, err)
+		assert.Equal(t, []int64{5, 4}, result.Items, "按列表顺序返回")
+		assert.True(t, result.HasNext)
+		assert.False(t, result.HasPrev)
+	})
+}
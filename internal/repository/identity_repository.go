@@ -0,0 +1,118 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"gin_demo/pkg/cache"
+	dbContext "gin_demo/pkg/database"
+	"gin_demo/pkg/tenant"
+)
+
+// IdentityRepository 第三方账户关联仓库层
+//
+// 只在第三方登录回调和账户设置中使用，访问频率低，不使用缓存。
+// 所有查询按 context 中的租户（tenant.ID）过滤。
+type IdentityRepository struct {
+	*BaseRepository[Identity]
+	queries *Queries
+}
+
+// NewIdentityRepository 创建第三方账户关联仓库实例
+func NewIdentityRepository(db *sql.DB, cacheManager *cache.Manager) *IdentityRepository {
+	return &IdentityRepository{
+		BaseRepository: NewBaseRepository[Identity](db, cacheManager),
+		queries:        New(db),
+	}
+}
+
+// ============================================================================
+// 查询方法
+// ============================================================================
+
+// GetIdentity 通过提供方和提供方用户标识查询关联
+func (r *IdentityRepository) GetIdentity(ctx context.Context, provider, subject string) (Identity, error) {
+	ctx, cancel := dbContext.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return r.queries.GetIdentity(ctx, GetIdentityParams{
+		TenantID: tenant.ID(ctx),
+		Provider: provider,
+		Subject:  subject,
+	})
+}
+
+// ListUserIdentities 列出用户关联的第三方账户
+func (r *IdentityRepository) ListUserIdentities(ctx context.Context, userID int64) ([]Identity, error) {
+	ctx, cancel := dbContext.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return r.queries.ListIdentitiesByUser(ctx, ListIdentitiesByUserParams{
+		TenantID: tenant.ID(ctx),
+		UserID:   userID,
+	})
+}
+
+// CountUserIdentities 统计用户关联的第三方账户数量
+func (r *IdentityRepository) CountUserIdentities(ctx context.Context, userID int64) (int64, error) {
+	ctx, cancel := dbContext.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return r.queries.CountIdentitiesByUser(ctx, CountIdentitiesByUserParams{
+		TenantID: tenant.ID(ctx),
+		UserID:   userID,
+	})
+}
+
+// ============================================================================
+// 写操作
+// ============================================================================
+
+// CreateIdentity 关联第三方账户
+func (r *IdentityRepository) CreateIdentity(ctx context.Context, params CreateIdentityParams) (int64, error) {
+	ctx, cancel := dbContext.WithQueryTimeout(ctx)
+	defer cancel()
+
+	params.TenantID = tenant.ID(ctx)
+
+	result, err := r.queries.CreateIdentity(ctx, params)
+	if err != nil {
+		return 0, fmt.Errorf("repository: create identity: %w", err)
+	}
+
+	id, err := result.LastInsertId()
+	if err != nil {
+		return 0, fmt.Errorf("repository: get identity id: %w", err)
+	}
+	return id, nil
+}
+
+// TouchIdentity 记录通过第三方账户登录的时间
+func (r *IdentityRepository) TouchIdentity(ctx context.Context, identityID int64, now time.Time) error {
+	ctx, cancel := dbContext.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return r.queries.TouchIdentity(ctx, TouchIdentityParams{
+		LastLoginAt: sql.NullTime{Time: now, Valid: true},
+		ID:          identityID,
+		TenantID:    tenant.ID(ctx),
+	})
+}
+
+// DeleteIdentity 解除用户与第三方账户的关联
+func (r *IdentityRepository) DeleteIdentity(ctx context.Context, userID int64, provider string) (bool, error) {
+	ctx, cancel := dbContext.WithQueryTimeout(ctx)
+	defer cancel()
+
+	n, err := r.queries.DeleteIdentity(ctx, DeleteIdentityParams{
+		TenantID: tenant.ID(ctx),
+		UserID:   userID,
+		Provider: provider,
+	})
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
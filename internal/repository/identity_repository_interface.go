@@ -0,0 +1,38 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// IdentityRepositoryInterface 第三方账户关联仓库接口（用于依赖注入和测试）
+type IdentityRepositoryInterface interface {
+	// ========================================
+	// 查询方法
+	// ========================================
+
+	// GetIdentity 通过提供方和提供方用户标识查询关联（不存在时返回 sql.ErrNoRows）
+	GetIdentity(ctx context.Context, provider, subject string) (Identity, error)
+
+	// ListUserIdentities 列出用户关联的第三方账户
+	ListUserIdentities(ctx context.Context, userID int64) ([]Identity, error)
+
+	// CountUserIdentities 统计用户关联的第三方账户数量
+	CountUserIdentities(ctx context.Context, userID int64) (int64, error)
+
+	// ========================================
+	// 写操作方法
+	// ========================================
+
+	// CreateIdentity 关联第三方账户，返回关联 ID
+	CreateIdentity(ctx context.Context, params CreateIdentityParams) (int64, error)
+
+	// TouchIdentity 记录通过第三方账户登录的时间
+	TouchIdentity(ctx context.Context, identityID int64, now time.Time) error
+
+	// DeleteIdentity 解除关联，返回 false 表示用户没有关联该提供方
+	DeleteIdentity(ctx context.Context, userID int64, provider string) (bool, error)
+}
+
+// 确保 IdentityRepository 实现了接口
+var _ IdentityRepositoryInterface = (*IdentityRepository)(nil)
@@ -0,0 +1,146 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"gin_demo/pkg/cache"
+	dbContext "gin_demo/pkg/database"
+)
+
+// MFARepository 两步验证仓库层
+//
+// 两步验证数据只在登录和绑定时读取，且必须读到最新值（防重放），因此不使用缓存。
+type MFARepository struct {
+	*BaseRepository[UserMfa]
+	queries *Queries
+}
+
+// NewMFARepository 创建两步验证仓库实例
+func NewMFARepository(db *sql.DB, cacheManager *cache.Manager) *MFARepository {
+	return &MFARepository{
+		BaseRepository: NewBaseRepository[UserMfa](db, cacheManager),
+		queries:        New(db),
+	}
+}
+
+// ============================================================================
+// 查询方法
+// ============================================================================
+
+// GetUserMFA 查询用户两步验证配置
+func (r *MFARepository) GetUserMFA(ctx context.Context, userID int64) (UserMfa, error) {
+	ctx, cancel := dbContext.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return r.queries.GetUserMFA(ctx, userID)
+}
+
+// CountRecoveryCodes 统计剩余可用的恢复码
+func (r *MFARepository) CountRecoveryCodes(ctx context.Context, userID int64) (int64, error) {
+	ctx, cancel := dbContext.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return r.queries.CountUnusedRecoveryCodes(ctx, userID)
+}
+
+// ============================================================================
+// 写操作
+// ============================================================================
+
+// SaveTOTPSecret 保存待绑定的 TOTP 密钥
+func (r *MFARepository) SaveTOTPSecret(ctx context.Context, userID int64, secret string) error {
+	ctx, cancel := dbContext.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return r.queries.UpsertUserMFASecret(ctx, UpsertUserMFASecretParams{
+		UserID:     userID,
+		TotpSecret: secret,
+	})
+}
+
+// EnableMFA 启用两步验证并保存恢复码（事务内执行）
+func (r *MFARepository) EnableMFA(ctx context.Context, userID, step int64, recoveryCodeHashes []string) error {
+	return r.WithTx(ctx, func(tx *sql.Tx) error {
+		q := r.queries.WithTx(tx)
+
+		if err := q.EnableUserMFA(ctx, EnableUserMFAParams{
+			LastUsedStep: step,
+			UserID:       userID,
+		}); err != nil {
+			return fmt.Errorf("repository: enable mfa: %w", err)
+		}
+
+		return replaceRecoveryCodes(ctx, q, userID, recoveryCodeHashes)
+	})
+}
+
+// ReplaceRecoveryCodes 替换恢复码（事务内执行）
+func (r *MFARepository) ReplaceRecoveryCodes(ctx context.Context, userID int64, recoveryCodeHashes []string) error {
+	return r.WithTx(ctx, func(tx *sql.Tx) error {
+		return replaceRecoveryCodes(ctx, r.queries.WithTx(tx), userID, recoveryCodeHashes)
+	})
+}
+
+// UseTOTPStep 记录已使用的 TOTP 时间步（条件更新，并发下同一验证码只有一次成功）
+func (r *MFARepository) UseTOTPStep(ctx context.Context, userID, step int64) (bool, error) {
+	ctx, cancel := dbContext.WithQueryTimeout(ctx)
+	defer cancel()
+
+	n, err := r.queries.UpdateUserMFALastUsedStep(ctx, UpdateUserMFALastUsedStepParams{
+		Step:   step,
+		UserID: userID,
+	})
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// UseRecoveryCode 使用恢复码（条件更新，并发下同一恢复码只有一次成功）
+func (r *MFARepository) UseRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error) {
+	ctx, cancel := dbContext.WithQueryTimeout(ctx)
+	defer cancel()
+
+	n, err := r.queries.UseUserRecoveryCode(ctx, UseUserRecoveryCodeParams{
+		UserID:   userID,
+		CodeHash: codeHash,
+	})
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// DisableMFA 关闭两步验证（事务内删除配置和恢复码）
+func (r *MFARepository) DisableMFA(ctx context.Context, userID int64) error {
+	return r.WithTx(ctx, func(tx *sql.Tx) error {
+		q := r.queries.WithTx(tx)
+
+		if err := q.DeleteUserRecoveryCodes(ctx, userID); err != nil {
+			return fmt.Errorf("repository: delete recovery codes: %w", err)
+		}
+		if err := q.DeleteUserMFA(ctx, userID); err != nil {
+			return fmt.Errorf("repository: delete mfa: %w", err)
+		}
+		return nil
+	})
+}
+
+// replaceRecoveryCodes 删除旧恢复码并写入新恢复码
+func replaceRecoveryCodes(ctx context.Context, q *Queries, userID int64, hashes []string) error {
+	if err := q.DeleteUserRecoveryCodes(ctx, userID); err != nil {
+		return fmt.Errorf("repository: delete recovery codes: %w", err)
+	}
+
+	for _, hash := range hashes {
+		if err := q.CreateUserRecoveryCode(ctx, CreateUserRecoveryCodeParams{
+			UserID:   userID,
+			CodeHash: hash,
+		}); err != nil {
+			return fmt.Errorf("repository: create recovery code: %w", err)
+		}
+	}
+	return nil
+}
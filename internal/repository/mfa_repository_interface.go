@@ -0,0 +1,41 @@
+package repository
+
+import "context"
+
+// MFARepositoryInterface 两步验证仓库接口（用于依赖注入和测试）
+type MFARepositoryInterface interface {
+	// ========================================
+	// 查询方法
+	// ========================================
+
+	// GetUserMFA 查询用户两步验证配置（未绑定时返回 sql.ErrNoRows）
+	GetUserMFA(ctx context.Context, userID int64) (UserMfa, error)
+
+	// CountRecoveryCodes 统计剩余可用的恢复码
+	CountRecoveryCodes(ctx context.Context, userID int64) (int64, error)
+
+	// ========================================
+	// 写操作方法
+	// ========================================
+
+	// SaveTOTPSecret 保存待绑定的 TOTP 密钥（已有配置时重置为未启用）
+	SaveTOTPSecret(ctx context.Context, userID int64, secret string) error
+
+	// EnableMFA 启用两步验证并保存恢复码哈希（step 为绑定时使用的时间步）
+	EnableMFA(ctx context.Context, userID, step int64, recoveryCodeHashes []string) error
+
+	// ReplaceRecoveryCodes 用新的恢复码哈希替换所有旧恢复码
+	ReplaceRecoveryCodes(ctx context.Context, userID int64, recoveryCodeHashes []string) error
+
+	// UseTOTPStep 记录已使用的 TOTP 时间步，返回 false 表示该时间步已被使用（重放）
+	UseTOTPStep(ctx context.Context, userID, step int64) (bool, error)
+
+	// UseRecoveryCode 使用恢复码，返回 false 表示恢复码无效或已使用
+	UseRecoveryCode(ctx context.Context, userID int64, codeHash string) (bool, error)
+
+	// DisableMFA 关闭两步验证（同时删除恢复码）
+	DisableMFA(ctx context.Context, userID int64) error
+}
+
+// 确保 MFARepository 实现了接口
+var _ MFARepositoryInterface = (*MFARepository)(nil)
@@ -6,9 +6,132 @@ package repository
 
 import (
 	"database/sql"
+	"encoding/json"
 	"time"
 )
 
+// API Key 表
+type ApiKey struct {
+	ID     int64 `json:"id"`
+	UserID int64 `json:"user_id"`
+	// 名称（便于用户区分用途）
+	Name string `json:"name"`
+	// 公开标识（Key 中 gdk_ 之后的部分）
+	Prefix string `json:"prefix"`
+	// 密钥 SHA-256 哈希
+	SecretHash string `json:"secret_hash"`
+	// 过期时间（NULL 表示永不过期）
+	ExpiresAt  sql.NullTime `json:"expires_at"`
+	LastUsedAt sql.NullTime `json:"last_used_at"`
+	RevokedAt  sql.NullTime `json:"revoked_at"`
+	CreatedAt  time.Time    `json:"created_at"`
+	// 所属租户（与用户所属租户相同）
+	TenantID int64 `json:"tenant_id"`
+}
+
+// API Key 权限范围表
+type ApiKeyScope struct {
+	ApiKeyID int64 `json:"api_key_id"`
+	// 权限（如 user:read）
+	Permission string `json:"permission"`
+}
+
+// 审计日志表
+type AuditEvent struct {
+	ID int64 `json:"id"`
+	// 所属租户
+	TenantID int64 `json:"tenant_id"`
+	// 操作者用户 ID（匿名操作如注册为 NULL）
+	ActorID sql.NullInt64 `json:"actor_id"`
+	// 模拟登录时实际操作的管理员 ID
+	ImpersonatorID sql.NullInt64 `json:"impersonator_id"`
+	// 操作（如 user.register、user.update）
+	Action string `json:"action"`
+	// 目标类型（如 user）
+	TargetType string `json:"target_type"`
+	// 目标 ID
+	TargetID sql.NullInt64 `json:"target_id"`
+	// 变更内容 {"字段":{"from":旧值,"to":新值}}
+	Changes json.RawMessage `json:"changes"`
+	// 客户端 IP
+	Ip string `json:"ip"`
+	// 请求 ID
+	RequestID string `json:"request_id"`
+	// 客户端 User-Agent
+	UserAgent string    `json:"user_agent"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// 第三方账户关联表
+type Identity struct {
+	ID     int64 `json:"id"`
+	UserID int64 `json:"user_id"`
+	// 提供方名称（配置中的 name，如 google）
+	Provider string `json:"provider"`
+	// 提供方内的用户唯一标识（OIDC sub）
+	Subject string `json:"subject"`
+	// 关联时提供方返回的邮箱（仅用于展示）
+	Email       string       `json:"email"`
+	LastLoginAt sql.NullTime `json:"last_login_at"`
+	CreatedAt   time.Time    `json:"created_at"`
+	// 所属租户（与用户所属租户相同）
+	TenantID int64 `json:"tenant_id"`
+}
+
+// 历史密码表
+type PasswordHistory struct {
+	ID int64 `json:"id"`
+	// 所属租户（与用户所属租户相同）
+	TenantID int64 `json:"tenant_id"`
+	UserID   int64 `json:"user_id"`
+	// 密码哈希（与 users.password 格式相同）
+	PasswordHash string    `json:"password_hash"`
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// 权限表
+type Permission struct {
+	ID int64 `json:"id"`
+	// 权限名称（如 user:read）
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// 内置权限（代码中使用，不能删除）
+	IsSystem  bool      `json:"is_system"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// 角色表
+type Role struct {
+	ID int64 `json:"id"`
+	// 角色名称（与 users.role 对应）
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// 角色级别（用于角色高低比较，super_admin 为 100）
+	Level int32 `json:"level"`
+	// 内置角色（不能删除）
+	IsSystem  bool      `json:"is_system"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// 角色权限关联表
+type RolePermission struct {
+	RoleID       int64 `json:"role_id"`
+	PermissionID int64 `json:"permission_id"`
+}
+
+// 租户表
+type Tenant struct {
+	ID int64 `json:"id"`
+	// 租户标识（子域名或 X-Tenant-ID 请求头中使用）
+	Slug string `json:"slug"`
+	Name string `json:"name"`
+	// 1:正常 2:停用
+	Status    int16     `json:"status"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
 // 用户表
 type User struct {
 	ID       int64          `json:"id"`
@@ -16,8 +139,64 @@ type User struct {
 	Email    string         `json:"email"`
 	Password string         `json:"password"`
 	Avatar   sql.NullString `json:"avatar"`
-	// 1:正常 2:禁用
+	// 1:正常 2:停用 3:待激活 4:已删除 5:已匿名 6:已合并
 	Status    int16     `json:"status"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+	// 角色: guest/user/moderator/admin/super_admin
+	Role string `json:"role"`
+	// Token 版本号（递增即吊销所有已签发 Token）
+	TokenVersion int64 `json:"token_version"`
+	// 所属租户
+	TenantID int64 `json:"tenant_id"`
+	// 停用原因
+	StatusReason sql.NullString `json:"status_reason"`
+	// 删除时间（软删除）
+	DeletedAt sql.NullTime `json:"deleted_at"`
+	// 合并到的用户（状态为已合并时）
+	MergedInto sql.NullInt64 `json:"merged_into"`
+}
+
+// 用户两步验证表
+type UserMfa struct {
+	UserID int64 `json:"user_id"`
+	// TOTP 密钥（Base32）
+	TotpSecret string `json:"totp_secret"`
+	// 是否已启用（绑定时验证通过后启用）
+	Enabled bool `json:"enabled"`
+	// 最近一次使用的 TOTP 时间步（防止验证码重放）
+	LastUsedStep int64        `json:"last_used_step"`
+	EnabledAt    sql.NullTime `json:"enabled_at"`
+	CreatedAt    time.Time    `json:"created_at"`
+	UpdatedAt    time.Time    `json:"updated_at"`
+}
+
+// 用户额外权限表
+type UserPermission struct {
+	UserID     int64     `json:"user_id"`
+	Permission string    `json:"permission"`
+	CreatedAt  time.Time `json:"created_at"`
+}
+
+// 用户恢复码表
+type UserRecoveryCode struct {
+	ID     int64 `json:"id"`
+	UserID int64 `json:"user_id"`
+	// 恢复码 SHA-256 哈希
+	CodeHash  string       `json:"code_hash"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+// 用户一次性令牌表
+type UserToken struct {
+	ID     int64 `json:"id"`
+	UserID int64 `json:"user_id"`
+	// 用途：verify_email / reset_password
+	Purpose string `json:"purpose"`
+	// 令牌 SHA-256 哈希
+	TokenHash string       `json:"token_hash"`
+	ExpiresAt time.Time    `json:"expires_at"`
+	UsedAt    sql.NullTime `json:"used_at"`
+	CreatedAt time.Time    `json:"created_at"`
 }
@@ -7,31 +7,164 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"time"
 )
 
 type Querier interface {
-	// 统计用户总数
-	CountUsers(ctx context.Context) (int64, error)
-	// 创建用户（MySQL 使用 execresult 获取 LastInsertId）
+	// 授予 API Key 权限范围
+	AddAPIKeyScope(ctx context.Context, arg AddAPIKeyScopeParams) error
+	// 为角色添加权限
+	AddRolePermission(ctx context.Context, arg AddRolePermissionParams) error
+	// 授予用户额外权限
+	AddUserPermission(ctx context.Context, arg AddUserPermissionParams) error
+	// 匿名化用户（清除个人信息并设为已匿名状态，保留记录；同时递增 Token 版本号）
+	AnonymizeUser(ctx context.Context, arg AnonymizeUserParams) (int64, error)
+	// 统计用户可用的 API Key 数量（未吊销且未过期）
+	CountActiveAPIKeys(ctx context.Context, arg CountActiveAPIKeysParams) (int64, error)
+	// 统计用户关联的第三方账户数量
+	CountIdentitiesByUser(ctx context.Context, arg CountIdentitiesByUserParams) (int64, error)
+	// 统计剩余可用的恢复码
+	CountUnusedRecoveryCodes(ctx context.Context, userID int64) (int64, error)
+	// 统计使用指定角色的用户数量
+	CountUsersByRole(ctx context.Context, role string) (int64, error)
+	// 创建 API Key（MySQL 使用 execresult 获取 LastInsertId）
+	CreateAPIKey(ctx context.Context, arg CreateAPIKeyParams) (sql.Result, error)
+	// 写入审计事件（只追加）
+	CreateAuditEvent(ctx context.Context, arg CreateAuditEventParams) error
+	// 关联第三方账户（MySQL 使用 execresult 获取 LastInsertId）
+	CreateIdentity(ctx context.Context, arg CreateIdentityParams) (sql.Result, error)
+	// 记录密码哈希
+	CreatePasswordHistory(ctx context.Context, arg CreatePasswordHistoryParams) error
+	// 创建权限
+	CreatePermission(ctx context.Context, arg CreatePermissionParams) (sql.Result, error)
+	// 创建角色（MySQL 使用 execresult 获取 LastInsertId）
+	CreateRole(ctx context.Context, arg CreateRoleParams) (sql.Result, error)
+	// 创建用户（MySQL 使用 execresult 获取 LastInsertId；status 1:正常 3:邮箱未验证）
 	CreateUser(ctx context.Context, arg CreateUserParams) (sql.Result, error)
-	// 软删除用户（设置状态为禁用）
-	DeleteUser(ctx context.Context, id int64) error
-	// 通过 Email 获取用户（包含密码，用于登录验证）
-	GetUserByEmail(ctx context.Context, email string) (User, error)
-	// 通过 ID 获取用户
-	GetUserByID(ctx context.Context, id int64) (GetUserByIDRow, error)
+	// 保存恢复码哈希
+	CreateUserRecoveryCode(ctx context.Context, arg CreateUserRecoveryCodeParams) error
+	// 保存一次性令牌哈希
+	CreateUserToken(ctx context.Context, arg CreateUserTokenParams) error
+	// 删除用户所有用途的令牌
+	DeleteAllUserTokens(ctx context.Context, userID int64) error
+	// 删除保留期之前的审计事件（分批删除，避免长时间锁表）
+	DeleteAuditEventsBefore(ctx context.Context, arg DeleteAuditEventsBeforeParams) (int64, error)
+	// 清理过期或已使用的令牌
+	DeleteExpiredUserTokens(ctx context.Context, before time.Time) (int64, error)
+	// 解除用户与第三方账户的关联
+	DeleteIdentity(ctx context.Context, arg DeleteIdentityParams) (int64, error)
+	// 删除用户的所有历史密码
+	DeletePasswordHistory(ctx context.Context, arg DeletePasswordHistoryParams) error
+	// 删除权限（内置权限不能删除，角色关联随之删除）
+	DeletePermission(ctx context.Context, name string) (int64, error)
+	// 删除角色（内置角色不能删除）
+	DeleteRole(ctx context.Context, id int64) (int64, error)
+	// 清空角色的权限
+	DeleteRolePermissions(ctx context.Context, roleID int64) error
+	// 删除用户的所有 API Key（权限范围通过外键级联删除）
+	DeleteUserAPIKeys(ctx context.Context, arg DeleteUserAPIKeysParams) error
+	// 解除用户与所有第三方账户的关联
+	DeleteUserIdentities(ctx context.Context, arg DeleteUserIdentitiesParams) error
+	// 关闭两步验证
+	DeleteUserMFA(ctx context.Context, userID int64) error
+	// 清空用户的额外权限
+	DeleteUserPermissions(ctx context.Context, userID int64) error
+	// 清空用户的恢复码
+	DeleteUserRecoveryCodes(ctx context.Context, userID int64) error
+	// 删除用户某种用途的所有令牌（签发新令牌或使用后作废旧令牌）
+	DeleteUserTokens(ctx context.Context, arg DeleteUserTokensParams) error
+	// 启用两步验证（记录绑定时使用的时间步）
+	EnableUserMFA(ctx context.Context, arg EnableUserMFAParams) error
+	// 通过公开标识查询 API Key
+	GetAPIKeyByPrefix(ctx context.Context, prefix string) (ApiKey, error)
+	// 通过提供方和提供方用户标识查询关联
+	GetIdentity(ctx context.Context, arg GetIdentityParams) (Identity, error)
+	// 通过名称查询角色
+	GetRoleByName(ctx context.Context, name string) (Role, error)
+	// 通过 ID 获取租户
+	GetTenantByID(ctx context.Context, id int64) (Tenant, error)
+	// 通过标识获取租户（子域名或请求头）
+	GetTenantBySlug(ctx context.Context, slug string) (Tenant, error)
+	// 通过 Email 获取用户（包含密码，用于登录验证；包含邮箱未验证的用户）
+	GetUserByEmail(ctx context.Context, arg GetUserByEmailParams) (User, error)
+	// 通过 ID 获取用户（包含邮箱未验证的用户）
+	GetUserByID(ctx context.Context, arg GetUserByIDParams) (GetUserByIDRow, error)
+	// 通过 ID 获取用户（包含停用和已删除的用户，用于管理员变更用户状态）
+	GetUserByIDAnyStatus(ctx context.Context, arg GetUserByIDAnyStatusParams) (GetUserByIDAnyStatusRow, error)
 	// 通过 Username 获取用户
-	GetUserByUsername(ctx context.Context, username string) (GetUserByUsernameRow, error)
+	GetUserByUsername(ctx context.Context, arg GetUserByUsernameParams) (GetUserByUsernameRow, error)
 	// 通过 Email 获取用户 ID（用于缓存索引）
-	GetUserIDByEmail(ctx context.Context, email string) (int64, error)
+	GetUserIDByEmail(ctx context.Context, arg GetUserIDByEmailParams) (int64, error)
 	// 通过 Username 获取用户 ID（用于缓存索引）
-	GetUserIDByUsername(ctx context.Context, username string) (int64, error)
-	// 列出用户（分页）
-	ListUsers(ctx context.Context, arg ListUsersParams) ([]ListUsersRow, error)
+	GetUserIDByUsername(ctx context.Context, arg GetUserIDByUsernameParams) (int64, error)
+	// 获取用户两步验证配置
+	GetUserMFA(ctx context.Context, userID int64) (UserMfa, error)
+	// 通过 ID 获取用户密码哈希（用于修改密码时校验旧密码）
+	GetUserPasswordByID(ctx context.Context, arg GetUserPasswordByIDParams) (string, error)
+	// 通过令牌哈希查询一次性令牌
+	GetUserToken(ctx context.Context, arg GetUserTokenParams) (UserToken, error)
+	// 获取用户 Token 版本号（用于校验 Token 是否已被吊销）
+	GetUserTokenVersion(ctx context.Context, arg GetUserTokenVersionParams) (int64, error)
+	// 递增 Token 版本号（吊销所有已签发 Token）
+	IncrementUserTokenVersion(ctx context.Context, arg IncrementUserTokenVersionParams) error
+	// 列出 API Key 的权限范围
+	ListAPIKeyScopes(ctx context.Context, apiKeyID int64) ([]string, error)
+	// 列出用户所有未吊销 API Key 的权限范围
+	ListAPIKeyScopesByUser(ctx context.Context, arg ListAPIKeyScopesByUserParams) ([]ListAPIKeyScopesByUserRow, error)
+	// 列出用户未吊销的 API Key（包含已过期的）
+	ListAPIKeysByUser(ctx context.Context, arg ListAPIKeysByUserParams) ([]ApiKey, error)
+	// 查询审计事件（按 ID 倒序，before_id 为游标，过滤条件为零值时不过滤）
+	ListAuditEvents(ctx context.Context, arg ListAuditEventsParams) ([]AuditEvent, error)
+	// 列出用户关联的第三方账户
+	ListIdentitiesByUser(ctx context.Context, arg ListIdentitiesByUserParams) ([]Identity, error)
+	// 列出用户最近的密码哈希（最新的在前）
+	ListPasswordHistory(ctx context.Context, arg ListPasswordHistoryParams) ([]string, error)
+	// 列出所有权限
+	ListPermissions(ctx context.Context) ([]Permission, error)
+	// 列出所有角色的权限（加载策略时一次查出）
+	ListRolePermissions(ctx context.Context) ([]ListRolePermissionsRow, error)
+	// 列出所有角色
+	ListRoles(ctx context.Context) ([]Role, error)
+	// 列出用户的额外权限
+	ListUserPermissions(ctx context.Context, userID int64) ([]string, error)
+	// 标记用户已合并到另一个用户（只在当前状态与预期一致时更新，同时递增 Token 版本号）
+	MergeUser(ctx context.Context, arg MergeUserParams) (int64, error)
+	// 只保留用户最近的 limit 条记录（MySQL 不支持 IN 子查询中使用 LIMIT，外层再包一层派生表）
+	PrunePasswordHistory(ctx context.Context, arg PrunePasswordHistoryParams) error
+	// 永久删除超过保留期的已删除用户（所有租户，分批执行；关联数据通过外键级联删除）
+	PurgeDeletedUsers(ctx context.Context, arg PurgeDeletedUsersParams) (int64, error)
+	// 更新密码哈希（算法或参数升级，密码未变，不吊销 Token；旧哈希不匹配时不更新，避免覆盖并发修改的密码）
+	RehashUserPassword(ctx context.Context, arg RehashUserPasswordParams) (int64, error)
+	// 吊销 API Key（只能吊销自己的 Key，影响行数为 0 表示不存在或已吊销）
+	RevokeAPIKey(ctx context.Context, arg RevokeAPIKeyParams) (int64, error)
+	// 记录最近使用时间（距上次记录不足 before 时不更新，避免每个请求都写库）
+	TouchAPIKey(ctx context.Context, arg TouchAPIKeyParams) (int64, error)
+	// 记录通过第三方账户登录的时间
+	TouchIdentity(ctx context.Context, arg TouchIdentityParams) error
+	// 将用户未吊销的 API Key 转移给另一个用户（合并账户；已吊销的 Key 留在原用户）
+	TransferAPIKeys(ctx context.Context, arg TransferAPIKeysParams) (int64, error)
+	// 将用户关联的第三方账户转移给另一个用户（合并账户）
+	TransferIdentities(ctx context.Context, arg TransferIdentitiesParams) (int64, error)
+	// 更新角色描述和级别
+	UpdateRole(ctx context.Context, arg UpdateRoleParams) error
 	// 更新用户信息
 	UpdateUser(ctx context.Context, arg UpdateUserParams) error
-	// 更新用户密码
+	// 记录已使用的 TOTP 时间步（只允许递增，影响行数为 0 表示验证码已被使用）
+	UpdateUserMFALastUsedStep(ctx context.Context, arg UpdateUserMFALastUsedStepParams) (int64, error)
+	// 更新用户密码（同时递增 Token 版本号，使旧 Token 失效）
 	UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) error
+	// 更新用户角色
+	UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) error
+	// 变更用户状态（状态 1:正常 2:停用 3:待激活 4:已删除；只在当前状态与预期一致时更新，同时递增 Token 版本号）
+	UpdateUserStatus(ctx context.Context, arg UpdateUserStatusParams) (int64, error)
+	// 保存待绑定的 TOTP 密钥（重新绑定时重置为未启用）
+	UpsertUserMFASecret(ctx context.Context, arg UpsertUserMFASecretParams) error
+	// 使用恢复码（影响行数为 0 表示恢复码无效或已使用）
+	UseUserRecoveryCode(ctx context.Context, arg UseUserRecoveryCodeParams) (int64, error)
+	// 使用一次性令牌（影响行数为 0 表示令牌已使用或已过期）
+	UseUserToken(ctx context.Context, id int64) (int64, error)
+	// 标记邮箱已验证（仅对未验证状态生效）
+	VerifyUserEmail(ctx context.Context, arg VerifyUserEmailParams) (int64, error)
 }
 
 var _ Querier = (*Queries)(nil)
@@ -0,0 +1,171 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+
+	"gin_demo/pkg/cache"
+	dbContext "gin_demo/pkg/database"
+)
+
+// RoleRepository 角色权限仓库层
+//
+// 角色权限在内存中以策略快照的形式缓存（见 auth.PolicyManager），这里直接查库。
+type RoleRepository struct {
+	*BaseRepository[Role]
+	queries *Queries
+}
+
+// NewRoleRepository 创建角色权限仓库实例
+func NewRoleRepository(db *sql.DB, cacheManager *cache.Manager) *RoleRepository {
+	return &RoleRepository{
+		BaseRepository: NewBaseRepository[Role](db, cacheManager),
+		queries:        New(db),
+	}
+}
+
+// ============================================================================
+// 查询方法
+// ============================================================================
+
+// ListRoles 列出所有角色
+func (r *RoleRepository) ListRoles(ctx context.Context) ([]Role, error) {
+	ctx, cancel := dbContext.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return r.queries.ListRoles(ctx)
+}
+
+// GetRoleByName 通过名称查询角色
+func (r *RoleRepository) GetRoleByName(ctx context.Context, name string) (Role, error) {
+	ctx, cancel := dbContext.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return r.queries.GetRoleByName(ctx, name)
+}
+
+// ListPermissions 列出所有权限
+func (r *RoleRepository) ListPermissions(ctx context.Context) ([]Permission, error) {
+	ctx, cancel := dbContext.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return r.queries.ListPermissions(ctx)
+}
+
+// ListRolePermissions 列出所有角色的权限（一次查询，避免 N+1）
+func (r *RoleRepository) ListRolePermissions(ctx context.Context) (map[string][]string, error) {
+	ctx, cancel := dbContext.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.queries.ListRolePermissions(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := make(map[string][]string)
+	for _, row := range rows {
+		permissions[row.RoleName] = append(permissions[row.RoleName], row.PermissionName)
+	}
+	return permissions, nil
+}
+
+// CountUsersByRole 统计使用指定角色的用户数量
+func (r *RoleRepository) CountUsersByRole(ctx context.Context, role string) (int64, error) {
+	ctx, cancel := dbContext.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return r.queries.CountUsersByRole(ctx, role)
+}
+
+// ============================================================================
+// 写操作
+// ============================================================================
+
+// CreateRole 创建角色并设置权限（事务内执行）
+func (r *RoleRepository) CreateRole(ctx context.Context, params CreateRoleParams, permissions []string) (int64, error) {
+	var roleID int64
+
+	err := r.WithTx(ctx, func(tx *sql.Tx) error {
+		q := r.queries.WithTx(tx)
+
+		result, err := q.CreateRole(ctx, params)
+		if err != nil {
+			return fmt.Errorf("repository: create role: %w", err)
+		}
+
+		roleID, err = result.LastInsertId()
+		if err != nil {
+			return fmt.Errorf("repository: get role id: %w", err)
+		}
+
+		return addRolePermissions(ctx, q, roleID, permissions)
+	})
+
+	return roleID, err
+}
+
+// UpdateRole 更新角色并替换权限（事务内执行）
+func (r *RoleRepository) UpdateRole(ctx context.Context, params UpdateRoleParams, permissions []string) error {
+	return r.WithTx(ctx, func(tx *sql.Tx) error {
+		q := r.queries.WithTx(tx)
+
+		if err := q.UpdateRole(ctx, params); err != nil {
+			return fmt.Errorf("repository: update role: %w", err)
+		}
+		if err := q.DeleteRolePermissions(ctx, params.ID); err != nil {
+			return fmt.Errorf("repository: clear role permissions: %w", err)
+		}
+
+		return addRolePermissions(ctx, q, params.ID, permissions)
+	})
+}
+
+// addRolePermissions 为角色添加权限
+func addRolePermissions(ctx context.Context, q *Queries, roleID int64, permissions []string) error {
+	for _, permission := range permissions {
+		if err := q.AddRolePermission(ctx, AddRolePermissionParams{
+			RoleID: roleID,
+			Name:   permission,
+		}); err != nil {
+			return fmt.Errorf("repository: add role permission: %w", err)
+		}
+	}
+	return nil
+}
+
+// DeleteRole 删除角色（内置角色不会被删除）
+func (r *RoleRepository) DeleteRole(ctx context.Context, roleID int64) (bool, error) {
+	ctx, cancel := dbContext.WithQueryTimeout(ctx)
+	defer cancel()
+
+	n, err := r.queries.DeleteRole(ctx, roleID)
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// CreatePermission 创建权限
+func (r *RoleRepository) CreatePermission(ctx context.Context, params CreatePermissionParams) (int64, error) {
+	ctx, cancel := dbContext.WithQueryTimeout(ctx)
+	defer cancel()
+
+	result, err := r.queries.CreatePermission(ctx, params)
+	if err != nil {
+		return 0, err
+	}
+	return result.LastInsertId()
+}
+
+// DeletePermission 删除权限（内置权限不会被删除）
+func (r *RoleRepository) DeletePermission(ctx context.Context, name string) (bool, error) {
+	ctx, cancel := dbContext.WithQueryTimeout(ctx)
+	defer cancel()
+
+	n, err := r.queries.DeletePermission(ctx, name)
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
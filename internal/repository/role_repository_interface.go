@@ -0,0 +1,49 @@
+package repository
+
+import (
+	"context"
+)
+
+// RoleRepositoryInterface 角色权限仓库接口（用于依赖注入和测试）
+type RoleRepositoryInterface interface {
+	// ========================================
+	// 查询方法
+	// ========================================
+
+	// ListRoles 列出所有角色（按级别从高到低）
+	ListRoles(ctx context.Context) ([]Role, error)
+
+	// GetRoleByName 通过名称查询角色（不存在时返回 sql.ErrNoRows）
+	GetRoleByName(ctx context.Context, name string) (Role, error)
+
+	// ListPermissions 列出所有权限
+	ListPermissions(ctx context.Context) ([]Permission, error)
+
+	// ListRolePermissions 列出所有角色的权限（按角色名称分组）
+	ListRolePermissions(ctx context.Context) (map[string][]string, error)
+
+	// CountUsersByRole 统计使用指定角色的用户数量
+	CountUsersByRole(ctx context.Context, role string) (int64, error)
+
+	// ========================================
+	// 写操作方法
+	// ========================================
+
+	// CreateRole 创建角色并设置权限（事务内执行），返回角色 ID
+	CreateRole(ctx context.Context, params CreateRoleParams, permissions []string) (int64, error)
+
+	// UpdateRole 更新角色并替换权限（事务内执行）
+	UpdateRole(ctx context.Context, params UpdateRoleParams, permissions []string) error
+
+	// DeleteRole 删除角色，返回 false 表示角色不存在或是内置角色
+	DeleteRole(ctx context.Context, roleID int64) (bool, error)
+
+	// CreatePermission 创建权限，返回权限 ID
+	CreatePermission(ctx context.Context, params CreatePermissionParams) (int64, error)
+
+	// DeletePermission 删除权限，返回 false 表示权限不存在或是内置权限
+	DeletePermission(ctx context.Context, name string) (bool, error)
+}
+
+// 确保 RoleRepository 实现了接口
+var _ RoleRepositoryInterface = (*RoleRepository)(nil)
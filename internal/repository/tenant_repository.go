@@ -0,0 +1,50 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+
+	"gin_demo/pkg/cache"
+	dbContext "gin_demo/pkg/database"
+)
+
+// 租户状态（tenants.status）
+const (
+	// TenantStatusActive 正常
+	TenantStatusActive int16 = 1
+	// TenantStatusDisabled 停用（该租户的请求一律拒绝）
+	TenantStatusDisabled int16 = 2
+)
+
+// TenantRepository 租户仓库层
+//
+// 租户在 TenantService 中按进程缓存，这里直接查库。
+// 租户表本身不按租户隔离，不使用 Redis 缓存（缓存键按租户划分命名空间）。
+type TenantRepository struct {
+	*BaseRepository[Tenant]
+	queries *Queries
+}
+
+// NewTenantRepository 创建租户仓库实例
+func NewTenantRepository(db *sql.DB, cacheManager *cache.Manager) *TenantRepository {
+	return &TenantRepository{
+		BaseRepository: NewBaseRepository[Tenant](db, cacheManager),
+		queries:        New(db),
+	}
+}
+
+// GetTenantByID 通过 ID 查询租户
+func (r *TenantRepository) GetTenantByID(ctx context.Context, id int64) (Tenant, error) {
+	ctx, cancel := dbContext.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return r.queries.GetTenantByID(ctx, id)
+}
+
+// GetTenantBySlug 通过标识查询租户
+func (r *TenantRepository) GetTenantBySlug(ctx context.Context, slug string) (Tenant, error) {
+	ctx, cancel := dbContext.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return r.queries.GetTenantBySlug(ctx, slug)
+}
@@ -0,0 +1,17 @@
+package repository
+
+import (
+	"context"
+)
+
+// TenantRepositoryInterface 租户仓库接口（用于依赖注入和测试）
+type TenantRepositoryInterface interface {
+	// GetTenantByID 通过 ID 查询租户（不存在时返回 sql.ErrNoRows）
+	GetTenantByID(ctx context.Context, id int64) (Tenant, error)
+
+	// GetTenantBySlug 通过标识查询租户（不存在时返回 sql.ErrNoRows）
+	GetTenantBySlug(ctx context.Context, slug string) (Tenant, error)
+}
+
+// 确保 TenantRepository 实现了 TenantRepositoryInterface 接口
+var _ TenantRepositoryInterface = (*TenantRepository)(nil)
@@ -0,0 +1,172 @@
+package repository
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+)
+
+// UserSortField 用户列表排序字段
+type UserSortField string
+
+// 允许的排序字段（白名单，对应的列名见 userSortColumns）
+const (
+	UserSortCreatedAt UserSortField = "created_at"
+	UserSortUpdatedAt UserSortField = "updated_at"
+	UserSortUsername  UserSortField = "username"
+	UserSortEmail     UserSortField = "email"
+	UserSortID        UserSortField = "id"
+)
+
+// SortOrder 排序方向
+type SortOrder string
+
+const (
+	SortAsc  SortOrder = "asc"
+	SortDesc SortOrder = "desc"
+)
+
+// userSortColumns 排序字段 → 列名（ORDER BY 只能使用这里的列名，不能拼接调用方传入的字符串）
+var userSortColumns = map[UserSortField]string{
+	UserSortCreatedAt: "created_at",
+	UserSortUpdatedAt: "updated_at",
+	UserSortUsername:  "username",
+	UserSortEmail:     "email",
+	UserSortID:        "id",
+}
+
+// userListColumns 用户列表查询的列（与 UserRepository.queryUsers 的扫描顺序一致）
+const userListColumns = "id, username, email, avatar, status, created_at, updated_at, role, tenant_id, status_reason, deleted_at, merged_into"
+
+// UserFilter 用户列表的过滤和排序条件（零值列出所有正常用户，按创建时间倒序）
+type UserFilter struct {
+	Statuses    []int16   // 状态（为空时只列出正常用户）
+	Roles       []string  // 角色
+	CreatedFrom time.Time // 创建时间不早于（零值不限制）
+	CreatedTo   time.Time // 创建时间早于（零值不限制）
+	Query       string    // 用户名或邮箱包含的文本
+
+	Sort  UserSortField // 排序字段（默认 created_at）
+	Order SortOrder     // 排序方向（默认 desc）
+}
+
+// IsZero 是否没有设置任何过滤条件（排序不影响结果集）
+func (f UserFilter) IsZero() bool {
+	return len(f.Statuses) == 0 && len(f.Roles) == 0 &&
+		f.CreatedFrom.IsZero() && f.CreatedTo.IsZero() && f.Query == ""
+}
+
+// Validate 校验排序字段、方向和创建时间范围
+func (f UserFilter) Validate() error {
+	if !f.CreatedFrom.IsZero() && !f.CreatedTo.IsZero() && !f.CreatedFrom.Before(f.CreatedTo) {
+		return fmt.Errorf("repository: created_from must be before created_to")
+	}
+	if _, ok := userSortColumns[f.sort()]; !ok {
+		return fmt.Errorf("repository: unsupported sort field %q", f.Sort)
+	}
+	if f.Order != "" && f.Order != SortAsc && f.Order != SortDesc {
+		return fmt.Errorf("repository: unsupported sort order %q", f.Order)
+	}
+	return nil
+}
+
+func (f UserFilter) sort() UserSortField {
+	if f.Sort == "" {
+		return UserSortCreatedAt
+	}
+	return f.Sort
+}
+
+func (f UserFilter) desc() bool {
+	return f.Order != SortAsc
+}
+
+// userQuery 动态构建的用户查询
+//
+// 所有条件的值都通过占位符传入，SQL 文本只由常量片段和白名单中的列名组成。
+type userQuery struct {
+	where []string
+	args  []any
+}
+
+// newUserQuery 根据过滤条件构建 WHERE 子句（始终限定租户）
+func newUserQuery(tenantID int64, f UserFilter) *userQuery {
+	q := &userQuery{}
+	q.add("tenant_id = ?", tenantID)
+
+	statuses := f.Statuses
+	if len(statuses) == 0 {
+		statuses = []int16{UserStatusActive}
+	}
+	addIn(q, "status", statuses)
+	if len(f.Roles) > 0 {
+		addIn(q, "role", f.Roles)
+	}
+	if !f.CreatedFrom.IsZero() {
+		q.add("created_at >= ?", f.CreatedFrom)
+	}
+	if !f.CreatedTo.IsZero() {
+		q.add("created_at < ?", f.CreatedTo)
+	}
+	if text := strings.TrimSpace(f.Query); text != "" {
+		pattern := "%" + escapeLike(text) + "%"
+		q.add("(username LIKE ? OR email LIKE ?)", pattern, pattern)
+	}
+	return q
+}
+
+// add 添加条件（cond 必须是常量片段）
+func (q *userQuery) add(cond string, args ...any) {
+	q.where = append(q.where, cond)
+	q.args = append(q.args, args...)
+}
+
+// addIn 添加 column IN (?, ...) 条件（column 必须是常量列名）
+func addIn[T any](q *userQuery, column string, values []T) {
+	placeholders := strings.TrimSuffix(strings.Repeat("?, ", len(values)), ", ")
+	q.where = append(q.where, column+" IN ("+placeholders+")")
+	for _, v := range values {
+		q.args = append(q.args, v)
+	}
+}
+
+// whereClause 返回 WHERE 子句
+func (q *userQuery) whereClause() string {
+	return " WHERE " + strings.Join(q.where, " AND ")
+}
+
+// listSQL 返回分页查询语句和参数（同一排序值按 ID 排序，保证分页稳定）
+func (q *userQuery) listSQL(f UserFilter, limit, offset int32) (string, []any) {
+	column := userSortColumns[f.sort()]
+	direction := " ASC"
+	if f.desc() {
+		direction = " DESC"
+	}
+	orderBy := " ORDER BY " + column + direction
+	if column != "id" {
+		orderBy += ", id" + direction
+	}
+
+	query := "SELECT " + userListColumns + " FROM users" + q.whereClause() + orderBy + " LIMIT ? OFFSET ?"
+	return query, append(slices.Clone(q.args), limit, offset)
+}
+
+// keysetSQL 返回 keyset 分页查询语句和参数（条件和排序由 KeysetClause 构建）
+func (q *userQuery) keysetSQL(orderBy string, limit int32) (string, []any) {
+	query := "SELECT " + userListColumns + " FROM users" + q.whereClause() + " ORDER BY " + orderBy + " LIMIT ?"
+	return query, append(slices.Clone(q.args), limit)
+}
+
+// countSQL 返回计数语句和参数
+func (q *userQuery) countSQL() (string, []any) {
+	return "SELECT COUNT(*) FROM users" + q.whereClause(), slices.Clone(q.args)
+}
+
+// likeEscaper 转义 LIKE 通配符（MySQL 默认转义字符为反斜杠）
+var likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+
+// escapeLike 转义用户输入中的 LIKE 通配符，按字面匹配
+func escapeLike(s string) string {
+	return likeEscaper.Replace(s)
+}
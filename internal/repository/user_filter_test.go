@@ -0,0 +1,73 @@
+package repository
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestUserQuery 测试用户列表查询的构建
+func TestUserQuery(t *testing.T) {
+	t.Run("默认只列出正常用户，按创建时间倒序", func(t *testing.T) {
+		filter := UserFilter{}
+		query, args := newUserQuery(2, filter).listSQL(filter, 10, 20)
+
+		assert.Equal(t, "SELECT "+userListColumns+" FROM users WHERE tenant_id = ? AND status IN (?)"+
+			" ORDER BY created_at DESC, id DESC LIMIT ? OFFSET ?", query)
+		assert.Equal(t, []any{int64(2), UserStatusActive, int32(10), int32(20)}, args)
+	})
+
+	t.Run("所有过滤条件", func(t *testing.T) {
+		from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+		to := from.AddDate(0, 1, 0)
+		filter := UserFilter{
+			Statuses:    []int16{UserStatusSuspended, UserStatusDeleted},
+			Roles:       []string{"admin"},
+			CreatedFrom: from,
+			CreatedTo:   to,
+			Query:       " alice ",
+			Sort:        UserSortUsername,
+			Order:       SortAsc,
+		}
+		q := newUserQuery(2, filter)
+
+		query, args := q.listSQL(filter, 10, 0)
+		assert.Equal(t, "SELECT "+userListColumns+" FROM users WHERE tenant_id = ? AND status IN (?, ?) AND role IN (?)"+
+			" AND created_at >= ? AND created_at < ? AND (username LIKE ? OR email LIKE ?)"+
+			" ORDER BY username ASC, id ASC LIMIT ? OFFSET ?", query)
+		assert.Equal(t, []any{int64(2), UserStatusSuspended, UserStatusDeleted, "admin", from, to, "%alice%", "%alice%", int32(10), int32(0)}, args)
+
+		// 总数使用相同的条件
+		count, countArgs := q.countSQL()
+		assert.Equal(t, "SELECT COUNT(*) FROM users WHERE tenant_id = ? AND status IN (?, ?) AND role IN (?)"+
+			" AND created_at >= ? AND created_at < ? AND (username LIKE ? OR email LIKE ?)", count)
+		assert.Equal(t, args[:len(args)-2], countArgs)
+	})
+
+	t.Run("用户输入只作为参数传入", func(t *testing.T) {
+		filter := UserFilter{Query: "x' OR 1=1 --", Roles: []string{"admin') OR ('1'='1"}}
+		query, args := newUserQuery(2, filter).countSQL()
+
+		assert.NotContains(t, query, "OR 1=1")
+		assert.NotContains(t, query, "'1'='1")
+		assert.Contains(t, args, "%x' OR 1=1 --%")
+	})
+
+	t.Run("转义 LIKE 通配符", func(t *testing.T) {
+		_, args := newUserQuery(2, UserFilter{Query: `50%_off\`}).countSQL()
+		assert.Equal(t, `%50\%\_off\\%`, args[len(args)-1])
+	})
+}
+
+// TestUserFilter_Validate 测试排序字段白名单
+func TestUserFilter_Validate(t *testing.T) {
+	assert.NoError(t, UserFilter{}.Validate())
+	assert.NoError(t, UserFilter{Sort: UserSortEmail, Order: SortDesc}.Validate())
+	assert.Error(t, UserFilter{Sort: "password"}.Validate())
+	assert.Error(t, UserFilter{Sort: "created_at; DROP TABLE users"}.Validate())
+	assert.Error(t, UserFilter{Order: "sideways"}.Validate())
+
+	assert.True(t, UserFilter{Sort: UserSortEmail}.IsZero(), "排序不算过滤条件")
+	assert.False(t, UserFilter{Query: "a"}.IsZero())
+}
@@ -8,9 +8,43 @@ import (
 
 	"gin_demo/pkg/cache"
 	dbContext "gin_demo/pkg/database"
+	"gin_demo/pkg/tenant"
 )
 
+// 用户状态（users.status，状态之间的转换规则见 service 层）
+const (
+	// UserStatusActive 正常
+	UserStatusActive int16 = 1
+	// UserStatusSuspended 停用（管理员停用，可以重新启用）
+	UserStatusSuspended int16 = 2
+	// UserStatusPending 待激活（注册后、验证邮箱前）
+	UserStatusPending int16 = 3
+	// UserStatusDeleted 已删除（软删除，可以恢复；超过保留期后永久删除）
+	UserStatusDeleted int16 = 4
+	// UserStatusErased 已匿名（个人信息已清除，保留记录；不能恢复）
+	UserStatusErased int16 = 5
+	// UserStatusMerged 已合并（数据已转移到 merged_into 指向的用户，保留记录；不能恢复）
+	UserStatusMerged int16 = 6
+)
+
+// MergePreview 合并账户时将要转移的数据
+type MergePreview struct {
+	Identities            []string // 将要转移的第三方账户（提供方）
+	ConflictingIdentities []string // 目标用户已关联同一提供方的第三方账户（存在时不能合并）
+	APIKeys               []string // 将要转移的未吊销 API Key（公开标识）
+}
+
+// MergeResult 合并账户的结果
+type MergeResult struct {
+	Merged     bool  // false 表示源用户状态已被并发修改，没有执行合并
+	Identities int64 // 转移的第三方账户数
+	APIKeys    int64 // 转移的 API Key 数
+}
+
 // UserRepository 用户仓库层（结合缓存）
+//
+// 所有查询和写操作都限定在 context 中的租户内（tenant.ID），缓存 Key 同样按租户隔离，
+// 调用方无需（也不能）通过参数指定租户。
 type UserRepository struct {
 	*BaseRepository[User]
 	queries *Queries
@@ -32,38 +66,76 @@ func NewUserRepository(db *sql.DB, cacheManager *cache.Manager) *UserRepository
 func (r *UserRepository) GetUserByID(ctx context.Context, userID int64) (User, error) {
 	return r.GetByIDWithCache(ctx, "user", userID, 5*time.Minute,
 		func(ctx context.Context) (User, error) {
-			row, err := r.queries.GetUserByID(ctx, userID)
+			row, err := r.queries.GetUserByID(ctx, GetUserByIDParams{
+				ID:       userID,
+				TenantID: tenant.ID(ctx),
+			})
 			if err != nil {
 				return User{}, err
 			}
-			return r.rowToUser(row.ID, row.Username, row.Email, "", row.Avatar, row.Status, row.CreatedAt, row.UpdatedAt), nil
+			user := r.rowToUser(row.ID, row.Username, row.Email, "", row.Avatar, row.Role, row.Status, row.CreatedAt, row.UpdatedAt)
+			user.TokenVersion = row.TokenVersion
+			user.TenantID = row.TenantID
+			return user, nil
 		})
 }
 
 // rowToUser 转换查询结果为 User
-func (r *UserRepository) rowToUser(id int64, username, email, password string, avatar sql.NullString, status int16, createdAt, updatedAt time.Time) User {
+func (r *UserRepository) rowToUser(id int64, username, email, password string, avatar sql.NullString, role string, status int16, createdAt, updatedAt time.Time) User {
 	return User{
 		ID:        id,
 		Username:  username,
 		Email:     email,
 		Password:  password,
 		Avatar:    avatar,
+		Role:      role,
 		Status:    status,
 		CreatedAt: createdAt,
 		UpdatedAt: updatedAt,
 	}
 }
 
-// GetUserByEmail 通过 Email 查询用户（包含密码，用于登录）
-func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) (User, error) {
+// GetUserByIDAnyStatus 通过 ID 查询用户，包含停用和已删除的用户（不缓存，用于管理员变更用户状态）
+func (r *UserRepository) GetUserByIDAnyStatus(ctx context.Context, userID int64) (User, error) {
 	ctx, cancel := dbContext.WithQueryTimeout(ctx)
 	defer cancel()
 
-	row, err := r.queries.GetUserByEmail(ctx, email)
+	row, err := r.queries.GetUserByIDAnyStatus(ctx, GetUserByIDAnyStatusParams{
+		ID:       userID,
+		TenantID: tenant.ID(ctx),
+	})
 	if err != nil {
 		return User{}, err
 	}
-	return r.rowToUser(row.ID, row.Username, row.Email, row.Password, row.Avatar, row.Status, row.CreatedAt, row.UpdatedAt), nil
+	user := r.rowToUser(row.ID, row.Username, row.Email, "", row.Avatar, row.Role, row.Status, row.CreatedAt, row.UpdatedAt)
+	user.TokenVersion = row.TokenVersion
+	user.TenantID = row.TenantID
+	user.StatusReason = row.StatusReason
+	user.DeletedAt = row.DeletedAt
+	user.MergedInto = row.MergedInto
+	return user, nil
+}
+
+// GetUserByEmail 通过 Email 查询用户（包含密码，用于登录）
+func (r *UserRepository) GetUserByEmail(ctx context.Context, email string) (User, error) {
+	ctx, cancel := dbContext.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return r.queries.GetUserByEmail(ctx, GetUserByEmailParams{
+		Email:    email,
+		TenantID: tenant.ID(ctx),
+	})
+}
+
+// GetUserPassword 查询用户密码哈希（不缓存，密码不进入缓存）
+func (r *UserRepository) GetUserPassword(ctx context.Context, userID int64) (string, error) {
+	ctx, cancel := dbContext.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return r.queries.GetUserPasswordByID(ctx, GetUserPasswordByIDParams{
+		ID:       userID,
+		TenantID: tenant.ID(ctx),
+	})
 }
 
 // GetUserByUsername 通过 Username 查询用户（索引缓存）
@@ -71,49 +143,130 @@ func (r *UserRepository) GetUserByUsername(ctx context.Context, username string)
 	ctx, cancel := dbContext.WithQueryTimeout(ctx)
 	defer cancel()
 
-	row, err := r.queries.GetUserByUsername(ctx, username)
+	row, err := r.queries.GetUserByUsername(ctx, GetUserByUsernameParams{
+		Username: username,
+		TenantID: tenant.ID(ctx),
+	})
 	if err != nil {
 		return User{}, err
 	}
-	return r.rowToUser(row.ID, row.Username, row.Email, "", row.Avatar, row.Status, row.CreatedAt, row.UpdatedAt), nil
+	user := r.rowToUser(row.ID, row.Username, row.Email, "", row.Avatar, row.Role, row.Status, row.CreatedAt, row.UpdatedAt)
+	user.TenantID = row.TenantID
+	return user, nil
 }
 
-// ListUsers 列出用户（分页，不缓存）
-func (r *UserRepository) ListUsers(ctx context.Context, limit, offset int32) ([]User, error) {
+// ListUsers 按过滤条件列出用户（LIMIT/OFFSET 分页，不缓存）
+func (r *UserRepository) ListUsers(ctx context.Context, filter UserFilter, limit, offset int32) ([]User, error) {
+	if err := filter.Validate(); err != nil {
+		return nil, err
+	}
+
 	return r.ListWithPagination(ctx, func(ctx context.Context) ([]User, error) {
-		rows, err := r.queries.ListUsers(ctx, ListUsersParams{
-			Limit:  limit,
-			Offset: offset,
-		})
-		if err != nil {
-			return nil, err
-		}
+		query, args := newUserQuery(tenant.ID(ctx), filter).listSQL(filter, limit, offset)
+		return r.queryUsers(ctx, query, args)
+	})
+}
 
-		users := make([]User, 0, len(rows))
-		for _, row := range rows {
-			users = append(users, r.rowToUser(row.ID, row.Username, row.Email, "", row.Avatar, row.Status, row.CreatedAt, row.UpdatedAt))
+// ListUsersByCursor 按过滤条件列出用户（按 (created_at, id) 的 keyset 分页，不缓存；忽略 filter 中的排序）
+func (r *UserRepository) ListUsersByCursor(ctx context.Context, filter UserFilter, page KeysetPage) (KeysetResult[User], error) {
+	return r.ListWithKeyset(ctx, page, func(ctx context.Context, cond string, args []any, orderBy string, limit int32) ([]User, error) {
+		q := newUserQuery(tenant.ID(ctx), filter)
+		if cond != "" {
+			q.add(cond, args...)
 		}
-		return users, nil
+		query, args := q.keysetSQL(orderBy, limit)
+		return r.queryUsers(ctx, query, args)
 	})
 }
 
-// CountUsers 统计用户总数（短期缓存）
-func (r *UserRepository) CountUsers(ctx context.Context) (int64, error) {
-	return r.CountWithCache(ctx, "user:count", 1*time.Minute,
+// queryUsers 执行用户列表查询（列见 userListColumns）
+func (r *UserRepository) queryUsers(ctx context.Context, query string, args []any) ([]User, error) {
+	ctx, cancel := dbContext.WithQueryTimeout(ctx)
+	defer cancel()
+
+	rows, err := r.DB().QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("repository: list users: %w", err)
+	}
+	defer rows.Close()
+
+	users := []User{}
+	for rows.Next() {
+		var user User
+		if err := rows.Scan(
+			&user.ID,
+			&user.Username,
+			&user.Email,
+			&user.Avatar,
+			&user.Status,
+			&user.CreatedAt,
+			&user.UpdatedAt,
+			&user.Role,
+			&user.TenantID,
+			&user.StatusReason,
+			&user.DeletedAt,
+			&user.MergedInto,
+		); err != nil {
+			return nil, fmt.Errorf("repository: scan user: %w", err)
+		}
+		users = append(users, user)
+	}
+	return users, rows.Err()
+}
+
+// GetUserPermissions 查询用户的额外权限（不缓存，仅在签发 Token 时使用）
+func (r *UserRepository) GetUserPermissions(ctx context.Context, userID int64) ([]string, error) {
+	ctx, cancel := dbContext.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return r.queries.ListUserPermissions(ctx, userID)
+}
+
+// GetUserTokenVersion 查询用户 Token 版本号（主键缓存，每个认证请求都会调用）
+func (r *UserRepository) GetUserTokenVersion(ctx context.Context, userID int64) (int64, error) {
+	return cache.TakeByID(ctx, r.Cache(), "user:token_version", userID, 5*time.Minute,
 		func(ctx context.Context) (int64, error) {
-			return r.queries.CountUsers(ctx)
+			ctx, cancel := dbContext.WithQueryTimeout(ctx)
+			defer cancel()
+
+			return r.queries.GetUserTokenVersion(ctx, GetUserTokenVersionParams{
+				ID:       userID,
+				TenantID: tenant.ID(ctx),
+			})
 		})
 }
 
+// CountUsers 按过滤条件统计用户数（没有过滤条件时短期缓存）
+func (r *UserRepository) CountUsers(ctx context.Context, filter UserFilter) (int64, error) {
+	count := func(ctx context.Context) (int64, error) {
+		ctx, cancel := dbContext.WithQueryTimeout(ctx)
+		defer cancel()
+
+		query, args := newUserQuery(tenant.ID(ctx), filter).countSQL()
+		var total int64
+		if err := r.DB().QueryRowContext(ctx, query, args...).Scan(&total); err != nil {
+			return 0, fmt.Errorf("repository: count users: %w", err)
+		}
+		return total, nil
+	}
+
+	if !filter.IsZero() {
+		return count(ctx)
+	}
+	return r.CountWithCache(ctx, "user:count", 1*time.Minute, count)
+}
+
 // ============================================================================
 // 写操作（自动清理缓存）
 // ============================================================================
 
-// CreateUser 创建用户（清理统计缓存）
+// CreateUser 创建用户（归属 context 中的租户，清理统计缓存）
 func (r *UserRepository) CreateUser(ctx context.Context, params CreateUserParams) (User, error) {
 	ctx, cancel := dbContext.WithQueryTimeout(ctx)
 	defer cancel()
 
+	params.TenantID = tenant.ID(ctx)
+
 	// MySQL: 执行创建操作，返回 sql.Result
 	result, err := r.queries.CreateUser(ctx, params)
 	if err != nil {
@@ -127,7 +280,7 @@ func (r *UserRepository) CreateUser(ctx context.Context, params CreateUserParams
 	}
 
 	// 清理统计缓存
-	_ = r.ExecWithCache(ctx, "user:count", "total", func(context.Context) error {
+	_ = r.ExecWithCache(ctx, "user:count", "count", func(context.Context) error {
 		return nil // 只删除缓存，不执行 DB 操作
 	})
 
@@ -137,18 +290,23 @@ func (r *UserRepository) CreateUser(ctx context.Context, params CreateUserParams
 
 // UpdateUser 更新用户信息（清理主键和索引缓存）
 func (r *UserRepository) UpdateUser(ctx context.Context, params UpdateUserParams) error {
+	params.TenantID = tenant.ID(ctx)
+
 	// 先获取旧数据（用于清理旧索引）
-	oldUser, err := r.queries.GetUserByID(ctx, params.ID)
+	oldUser, err := r.queries.GetUserByID(ctx, GetUserByIDParams{
+		ID:       params.ID,
+		TenantID: params.TenantID,
+	})
 	if err != nil {
 		return fmt.Errorf("repository: get old user: %w", err)
 	}
 
 	// 构建需要清理的索引 Key
 	indexes := []string{
-		r.Cache().BuildIndexKey("user", "email", oldUser.Email),
-		r.Cache().BuildIndexKey("user", "email", params.Email),
-		r.Cache().BuildIndexKey("user", "username", oldUser.Username),
-		r.Cache().BuildIndexKey("user", "username", params.Username),
+		r.Cache().BuildIndexKey(ctx, "user", "email", oldUser.Email),
+		r.Cache().BuildIndexKey(ctx, "user", "email", params.Email),
+		r.Cache().BuildIndexKey(ctx, "user", "username", oldUser.Username),
+		r.Cache().BuildIndexKey(ctx, "user", "username", params.Username),
 	}
 
 	return r.ExecWithIndexCache(ctx, "user", params.ID, indexes,
@@ -157,39 +315,395 @@ func (r *UserRepository) UpdateUser(ctx context.Context, params UpdateUserParams
 		})
 }
 
-// UpdateUserPassword 更新用户密码（只清理主键缓存）
+// UpdateUserPassword 更新用户密码（同时递增 Token 版本号，清理主键和版本号缓存）
 func (r *UserRepository) UpdateUserPassword(ctx context.Context, userID int64, password string) error {
-	return r.ExecWithCache(ctx, "user", userID, func(ctx context.Context) error {
+	indexes := []string{
+		r.Cache().BuildKey(ctx, "user:token_version", userID),
+	}
+
+	return r.ExecWithIndexCache(ctx, "user", userID, indexes, func(ctx context.Context) error {
 		return r.queries.UpdateUserPassword(ctx, UpdateUserPasswordParams{
 			ID:       userID,
+			TenantID: tenant.ID(ctx),
 			Password: password,
 		})
 	})
 }
 
-// DeleteUser 删除用户（软删除，清理所有相关缓存）
-func (r *UserRepository) DeleteUser(ctx context.Context, userID int64) error {
+// RehashUserPassword 用新算法或参数的哈希替换旧哈希（不吊销 Token；缓存中不含密码，无需清理）
+// 返回 false 表示密码已被修改（旧哈希不匹配），未更新
+func (r *UserRepository) RehashUserPassword(ctx context.Context, userID int64, oldHash, newHash string) (bool, error) {
+	ctx, cancel := dbContext.WithQueryTimeout(ctx)
+	defer cancel()
+
+	affected, err := r.queries.RehashUserPassword(ctx, RehashUserPasswordParams{
+		NewPassword: newHash,
+		ID:          userID,
+		TenantID:    tenant.ID(ctx),
+		OldPassword: oldHash,
+	})
+	return affected > 0, err
+}
+
+// GetPasswordHistory 查询用户最近的密码哈希（最新的在前，不缓存）
+func (r *UserRepository) GetPasswordHistory(ctx context.Context, userID int64, limit int32) ([]string, error) {
+	ctx, cancel := dbContext.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return r.queries.ListPasswordHistory(ctx, ListPasswordHistoryParams{
+		TenantID: tenant.ID(ctx),
+		UserID:   userID,
+		Limit:    limit,
+	})
+}
+
+// AddPasswordHistory 记录密码哈希，只保留最近 keep 条（事务内执行）
+func (r *UserRepository) AddPasswordHistory(ctx context.Context, userID int64, passwordHash string, keep int32) error {
+	return r.WithTx(ctx, func(tx *sql.Tx) error {
+		q := r.queries.WithTx(tx)
+
+		if err := q.CreatePasswordHistory(ctx, CreatePasswordHistoryParams{
+			TenantID:     tenant.ID(ctx),
+			UserID:       userID,
+			PasswordHash: passwordHash,
+		}); err != nil {
+			return err
+		}
+		return q.PrunePasswordHistory(ctx, PrunePasswordHistoryParams{
+			TenantID: tenant.ID(ctx),
+			UserID:   userID,
+			Limit:    keep,
+		})
+	})
+}
+
+// IncrementTokenVersion 递增 Token 版本号，吊销用户所有已签发的 Token（清理主键和版本号缓存）
+func (r *UserRepository) IncrementTokenVersion(ctx context.Context, userID int64) error {
+	indexes := []string{
+		r.Cache().BuildKey(ctx, "user:token_version", userID),
+	}
+
+	return r.ExecWithIndexCache(ctx, "user", userID, indexes, func(ctx context.Context) error {
+		return r.queries.IncrementUserTokenVersion(ctx, IncrementUserTokenVersionParams{
+			ID:       userID,
+			TenantID: tenant.ID(ctx),
+		})
+	})
+}
+
+// MarkEmailVerified 标记邮箱已验证（清理主键缓存），返回 false 表示用户此前不是未验证状态
+func (r *UserRepository) MarkEmailVerified(ctx context.Context, userID int64) (bool, error) {
+	var affected int64
+	err := r.ExecWithCache(ctx, "user", userID, func(ctx context.Context) error {
+		ctx, cancel := dbContext.WithQueryTimeout(ctx)
+		defer cancel()
+
+		var err error
+		affected, err = r.queries.VerifyUserEmail(ctx, VerifyUserEmailParams{
+			ID:       userID,
+			TenantID: tenant.ID(ctx),
+		})
+		return err
+	})
+	return affected > 0, err
+}
+
+// UpdateUserRole 更新用户角色并替换额外权限（事务内执行，同时吊销所有已签发的 Token，清理主键和版本号缓存）
+func (r *UserRepository) UpdateUserRole(ctx context.Context, userID int64, role string, permissions []string) error {
+	indexes := []string{
+		r.Cache().BuildKey(ctx, "user:token_version", userID),
+	}
+
+	return r.ExecWithIndexCache(ctx, "user", userID, indexes, func(ctx context.Context) error {
+		return r.WithTx(ctx, func(tx *sql.Tx) error {
+			q := r.queries.WithTx(tx)
+
+			tenantID := tenant.ID(ctx)
+
+			// 额外权限只按 user_id 关联，先确认用户属于当前租户
+			if _, err := q.GetUserByID(ctx, GetUserByIDParams{ID: userID, TenantID: tenantID}); err != nil {
+				return fmt.Errorf("repository: get user: %w", err)
+			}
+
+			if err := q.UpdateUserRole(ctx, UpdateUserRoleParams{
+				ID:       userID,
+				TenantID: tenantID,
+				Role:     role,
+			}); err != nil {
+				return fmt.Errorf("repository: update role: %w", err)
+			}
+
+			if err := q.DeleteUserPermissions(ctx, userID); err != nil {
+				return fmt.Errorf("repository: clear permissions: %w", err)
+			}
+
+			for _, permission := range permissions {
+				if err := q.AddUserPermission(ctx, AddUserPermissionParams{
+					UserID:     userID,
+					Permission: permission,
+				}); err != nil {
+					return fmt.Errorf("repository: add permission %s: %w", permission, err)
+				}
+			}
+
+			return nil
+		})
+	})
+}
+
+// UpdateUserStatus 变更用户状态（同时吊销所有已签发的 Token，清理所有相关缓存）
+//
+// 只在当前状态为 from 时更新，返回 false 表示状态已被并发修改（或用户不存在）。
+// reason 为停用原因（空字符串保存为 NULL）；变为已删除状态时记录删除时间，其他状态清空删除时间。
+func (r *UserRepository) UpdateUserStatus(ctx context.Context, userID int64, from, to int16, reason string) (bool, error) {
+	tenantID := tenant.ID(ctx)
+
 	// 先获取用户数据（用于清理索引）
-	user, err := r.queries.GetUserByID(ctx, userID)
+	user, err := r.queries.GetUserByIDAnyStatus(ctx, GetUserByIDAnyStatusParams{ID: userID, TenantID: tenantID})
 	if err != nil {
-		return fmt.Errorf("repository: get user: %w", err)
+		return false, fmt.Errorf("repository: get user: %w", err)
 	}
 
 	indexes := []string{
-		r.Cache().BuildIndexKey("user", "email", user.Email),
-		r.Cache().BuildIndexKey("user", "username", user.Username),
-		r.Cache().BuildKey("user:count", "total"),
+		r.Cache().BuildIndexKey(ctx, "user", "email", user.Email),
+		r.Cache().BuildIndexKey(ctx, "user", "username", user.Username),
+		r.Cache().BuildKey(ctx, "user:count", "count"),
+		r.Cache().BuildKey(ctx, "user:token_version", userID),
 	}
 
-	return r.ExecWithIndexCache(ctx, "user", userID, indexes,
+	params := UpdateUserStatusParams{
+		Status:       to,
+		StatusReason: sql.NullString{String: reason, Valid: reason != ""},
+		ID:           userID,
+		TenantID:     tenantID,
+		FromStatus:   from,
+	}
+	if to == UserStatusDeleted {
+		params.DeletedAt = sql.NullTime{Time: time.Now(), Valid: true}
+	}
+
+	var affected int64
+	err = r.ExecWithIndexCache(ctx, "user", userID, indexes,
 		func(ctx context.Context) error {
-			return r.queries.DeleteUser(ctx, userID)
+			var err error
+			affected, err = r.queries.UpdateUserStatus(ctx, params)
+			return err
 		})
+	return affected > 0, err
 }
 
-// ============================================================================
-// 事务支持
-// ============================================================================
+// AnonymizeUser 匿名化用户（数据删除权）
+//
+// 在一个事务中清除用户表中的个人信息（用户名和邮箱替换为按 ID 生成的占位值，清空密码和头像），
+// 删除第三方账户关联、API Key、两步验证、一次性令牌和历史密码，保留用户记录本身，
+// 审计日志等按用户 ID 关联的数据仍然有效。同时吊销所有已签发的 Token 并清理所有相关缓存。
+// 返回 false 表示用户已经匿名化。
+func (r *UserRepository) AnonymizeUser(ctx context.Context, userID int64) (bool, error) {
+	tenantID := tenant.ID(ctx)
+
+	// 先获取用户数据（用于清理索引）
+	user, err := r.queries.GetUserByIDAnyStatus(ctx, GetUserByIDAnyStatusParams{ID: userID, TenantID: tenantID})
+	if err != nil {
+		return false, fmt.Errorf("repository: get user: %w", err)
+	}
+
+	indexes := []string{
+		r.Cache().BuildIndexKey(ctx, "user", "email", user.Email),
+		r.Cache().BuildIndexKey(ctx, "user", "username", user.Username),
+		r.Cache().BuildKey(ctx, "user:count", "count"),
+		r.Cache().BuildKey(ctx, "user:token_version", userID),
+	}
+
+	var affected int64
+	err = r.ExecWithIndexCache(ctx, "user", userID, indexes, func(ctx context.Context) error {
+		return r.WithTx(ctx, func(tx *sql.Tx) error {
+			q := r.queries.WithTx(tx)
+
+			var err error
+			affected, err = q.AnonymizeUser(ctx, AnonymizeUserParams{
+				Username: fmt.Sprintf("erased_%d", userID),
+				Email:    fmt.Sprintf("erased_%d@erased.invalid", userID),
+				ID:       userID,
+				TenantID: tenantID,
+			})
+			if err != nil {
+				return fmt.Errorf("repository: anonymize user: %w", err)
+			}
+			if affected == 0 {
+				return nil
+			}
+
+			// 关联表只按 user_id 关联，上面的更新已确认用户属于当前租户
+			cleanups := []struct {
+				name string
+				fn   func(context.Context, int64) error
+			}{
+				{"identities", func(ctx context.Context, userID int64) error {
+					return q.DeleteUserIdentities(ctx, DeleteUserIdentitiesParams{TenantID: tenantID, UserID: userID})
+				}},
+				{"api keys", func(ctx context.Context, userID int64) error {
+					return q.DeleteUserAPIKeys(ctx, DeleteUserAPIKeysParams{TenantID: tenantID, UserID: userID})
+				}},
+				{"mfa", q.DeleteUserMFA},
+				{"recovery codes", q.DeleteUserRecoveryCodes},
+				{"tokens", q.DeleteAllUserTokens},
+				{"password history", func(ctx context.Context, userID int64) error {
+					return q.DeletePasswordHistory(ctx, DeletePasswordHistoryParams{TenantID: tenantID, UserID: userID})
+				}},
+			}
+			for _, cleanup := range cleanups {
+				if err := cleanup.fn(ctx, userID); err != nil {
+					return fmt.Errorf("repository: delete %s: %w", cleanup.name, err)
+				}
+			}
+			return nil
+		})
+	})
+	return affected > 0, err
+}
+
+// PreviewMerge 查询将源用户合并到目标用户时将要转移的数据（不修改数据）
+func (r *UserRepository) PreviewMerge(ctx context.Context, fromUserID, toUserID int64) (MergePreview, error) {
+	ctx, cancel := dbContext.WithQueryTimeout(ctx)
+	defer cancel()
+
+	tenantID := tenant.ID(ctx)
+
+	var preview MergePreview
+	from, err := r.queries.ListIdentitiesByUser(ctx, ListIdentitiesByUserParams{TenantID: tenantID, UserID: fromUserID})
+	if err != nil {
+		return preview, fmt.Errorf("repository: list identities: %w", err)
+	}
+	to, err := r.queries.ListIdentitiesByUser(ctx, ListIdentitiesByUserParams{TenantID: tenantID, UserID: toUserID})
+	if err != nil {
+		return preview, fmt.Errorf("repository: list identities: %w", err)
+	}
+	linked := make(map[string]bool, len(to))
+	for _, identity := range to {
+		linked[identity.Provider] = true
+	}
+	preview.Identities = make([]string, 0, len(from))
+	for _, identity := range from {
+		if linked[identity.Provider] {
+			preview.ConflictingIdentities = append(preview.ConflictingIdentities, identity.Provider)
+			continue
+		}
+		preview.Identities = append(preview.Identities, identity.Provider)
+	}
+
+	keys, err := r.queries.ListAPIKeysByUser(ctx, ListAPIKeysByUserParams{TenantID: tenantID, UserID: fromUserID})
+	if err != nil {
+		return preview, fmt.Errorf("repository: list api keys: %w", err)
+	}
+	preview.APIKeys = make([]string, len(keys))
+	for i, key := range keys {
+		preview.APIKeys[i] = key.Prefix
+	}
+	return preview, nil
+}
+
+// MergeUser 将源用户合并到目标用户（合并账户）
+//
+// 在一个事务中将源用户标记为已合并（记录 merged_into，只在当前状态为 from 时更新），
+// 把第三方账户关联和未吊销的 API Key 转移给目标用户，删除源用户的两步验证、一次性令牌和历史密码。
+// 源用户所有已签发的 Token 被吊销，两个用户的缓存和索引都被清理。
+// 目标用户已关联同一提供方的第三方账户时违反唯一约束，事务回滚（调用方应先通过 PreviewMerge 检查）。
+func (r *UserRepository) MergeUser(ctx context.Context, fromUserID, toUserID int64, from int16) (MergeResult, error) {
+	tenantID := tenant.ID(ctx)
+
+	// 先获取两个用户的数据（用于清理索引）
+	source, err := r.queries.GetUserByIDAnyStatus(ctx, GetUserByIDAnyStatusParams{ID: fromUserID, TenantID: tenantID})
+	if err != nil {
+		return MergeResult{}, fmt.Errorf("repository: get source user: %w", err)
+	}
+	target, err := r.queries.GetUserByIDAnyStatus(ctx, GetUserByIDAnyStatusParams{ID: toUserID, TenantID: tenantID})
+	if err != nil {
+		return MergeResult{}, fmt.Errorf("repository: get target user: %w", err)
+	}
+
+	indexes := []string{
+		r.Cache().BuildIndexKey(ctx, "user", "email", source.Email),
+		r.Cache().BuildIndexKey(ctx, "user", "username", source.Username),
+		r.Cache().BuildKey(ctx, "user:token_version", fromUserID),
+		r.Cache().BuildKey(ctx, "user", toUserID),
+		r.Cache().BuildIndexKey(ctx, "user", "email", target.Email),
+		r.Cache().BuildIndexKey(ctx, "user", "username", target.Username),
+		r.Cache().BuildKey(ctx, "user:count", "count"),
+	}
+
+	var result MergeResult
+	err = r.ExecWithIndexCache(ctx, "user", fromUserID, indexes, func(ctx context.Context) error {
+		return r.WithTx(ctx, func(tx *sql.Tx) error {
+			q := r.queries.WithTx(tx)
+
+			affected, err := q.MergeUser(ctx, MergeUserParams{
+				MergedInto: sql.NullInt64{Int64: toUserID, Valid: true},
+				ID:         fromUserID,
+				TenantID:   tenantID,
+				FromStatus: from,
+			})
+			if err != nil {
+				return fmt.Errorf("repository: merge user: %w", err)
+			}
+			if affected == 0 {
+				return nil
+			}
+			result.Merged = true
+
+			transfer := TransferIdentitiesParams{ToUserID: toUserID, TenantID: tenantID, FromUserID: fromUserID}
+			if result.Identities, err = q.TransferIdentities(ctx, transfer); err != nil {
+				return fmt.Errorf("repository: transfer identities: %w", err)
+			}
+			if result.APIKeys, err = q.TransferAPIKeys(ctx, TransferAPIKeysParams(transfer)); err != nil {
+				return fmt.Errorf("repository: transfer api keys: %w", err)
+			}
+
+			cleanups := []struct {
+				name string
+				fn   func(context.Context, int64) error
+			}{
+				{"mfa", q.DeleteUserMFA},
+				{"recovery codes", q.DeleteUserRecoveryCodes},
+				{"tokens", q.DeleteAllUserTokens},
+				{"password history", func(ctx context.Context, userID int64) error {
+					return q.DeletePasswordHistory(ctx, DeletePasswordHistoryParams{TenantID: tenantID, UserID: userID})
+				}},
+			}
+			for _, cleanup := range cleanups {
+				if err := cleanup.fn(ctx, fromUserID); err != nil {
+					return fmt.Errorf("repository: delete %s: %w", cleanup.name, err)
+				}
+			}
+			return nil
+		})
+	})
+	if err != nil || !result.Merged {
+		return MergeResult{}, err
+	}
+	return result, nil
+}
+
+// InvalidateUserCache 清理用户的主键、索引、Token 版本号和计数缓存
+//
+// 事务中的写操作在提交前清理缓存，提交前的并发读取可能把旧数据重新写入缓存；
+// 事务提交后对所有受影响的用户再清理一次。users 应同时包含修改前后的用户（用户名、邮箱可能已变化）。
+func (r *UserRepository) InvalidateUserCache(ctx context.Context, users ...User) error {
+	for _, user := range users {
+		indexes := []string{
+			r.Cache().BuildIndexKey(ctx, "user", "email", user.Email),
+			r.Cache().BuildIndexKey(ctx, "user", "username", user.Username),
+			r.Cache().BuildKey(ctx, "user:count", "count"),
+			r.Cache().BuildKey(ctx, "user:token_version", user.ID),
+		}
+		err := r.ExecWithIndexCache(ctx, "user", user.ID, indexes, func(context.Context) error {
+			return nil // 只删除缓存，不执行额外操作
+		})
+		if err != nil {
+			return fmt.Errorf("repository: invalidate user cache: %w", err)
+		}
+	}
+	return nil
+}
 
 // ============================================================================
 // 事务支持
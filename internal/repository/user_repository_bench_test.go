@@ -26,6 +26,7 @@ func BenchmarkGetUserByID(b *testing.B) {
 		Email:    "bench@example.com",
 		Password: "hashedpassword",
 		Avatar:   sql.NullString{String: "avatar.jpg", Valid: true},
+		Status:   UserStatusActive,
 	})
 	if err != nil {
 		b.Fatal(err)
@@ -61,6 +62,7 @@ func BenchmarkGetUserByEmail(b *testing.B) {
 		Email:    email,
 		Password: "hashedpassword",
 		Avatar:   sql.NullString{String: "avatar.jpg", Valid: true},
+		Status:   UserStatusActive,
 	})
 	if err != nil {
 		b.Fatal(err)
@@ -98,6 +100,7 @@ func BenchmarkListUsers(b *testing.B) {
 			Email:    email,
 			Password: "hashedpassword",
 			Avatar:   sql.NullString{String: "avatar.jpg", Valid: true},
+			Status:   UserStatusActive,
 		})
 		if err != nil {
 			b.Fatal(err)
@@ -109,7 +112,7 @@ func BenchmarkListUsers(b *testing.B) {
 
 	// 性能测试
 	for i := 0; i < b.N; i++ {
-		_, err := repo.ListUsers(ctx, 10, 0)
+		_, err := repo.ListUsers(ctx, UserFilter{}, 10, 0)
 		if err != nil {
 			b.Fatal(err)
 		}
@@ -139,6 +142,7 @@ func BenchmarkCreateUser(b *testing.B) {
 			Email:    email,
 			Password: "hashedpassword",
 			Avatar:   sql.NullString{String: "avatar.jpg", Valid: true},
+			Status:   UserStatusActive,
 		})
 		if err != nil {
 			b.Fatal(err)
@@ -163,6 +167,7 @@ func BenchmarkUpdateUser(b *testing.B) {
 		Email:    "bench@example.com",
 		Password: "hashedpassword",
 		Avatar:   sql.NullString{String: "avatar.jpg", Valid: true},
+		Status:   UserStatusActive,
 	})
 	if err != nil {
 		b.Fatal(err)
@@ -207,6 +212,7 @@ func BenchmarkCountUsers(b *testing.B) {
 			Email:    email,
 			Password: "hashedpassword",
 			Avatar:   sql.NullString{String: "avatar.jpg", Valid: true},
+			Status:   UserStatusActive,
 		})
 		if err != nil {
 			b.Fatal(err)
@@ -218,7 +224,7 @@ func BenchmarkCountUsers(b *testing.B) {
 
 	// 性能测试
 	for i := 0; i < b.N; i++ {
-		_, err := repo.CountUsers(ctx)
+		_, err := repo.CountUsers(ctx, UserFilter{})
 		if err != nil {
 			b.Fatal(err)
 		}
@@ -242,6 +248,7 @@ func BenchmarkCacheHit(b *testing.B) {
 		Email:    "cache@example.com",
 		Password: "hashedpassword",
 		Avatar:   sql.NullString{String: "avatar.jpg", Valid: true},
+		Status:   UserStatusActive,
 	})
 	if err != nil {
 		b.Fatal(err)
@@ -283,6 +290,7 @@ func BenchmarkCacheMiss(b *testing.B) {
 			Email:    email,
 			Password: "hashedpassword",
 			Avatar:   sql.NullString{String: "avatar.jpg", Valid: true},
+			Status:   UserStatusActive,
 		})
 		if err != nil {
 			b.Fatal(err)
@@ -297,7 +305,7 @@ func BenchmarkCacheMiss(b *testing.B) {
 	for i := 0; i < b.N; i++ {
 		userID := userIDs[i%len(userIDs)]
 		// 先清除缓存
-		_ = rdb.Del(ctx, cache.NewManager(rdb).BuildKey("user", userID))
+		_ = rdb.Del(ctx, cache.NewManager(rdb).BuildKey(ctx, "user", userID))
 		// 查询（缓存未命中）
 		_, err := repo.GetUserByID(ctx, userID)
 		if err != nil {
@@ -14,17 +14,35 @@ type UserRepositoryInterface interface {
 	// GetUserByID 通过 ID 查询用户
 	GetUserByID(ctx context.Context, userID int64) (User, error)
 
+	// GetUserByIDAnyStatus 通过 ID 查询用户，包含停用和已删除的用户
+	GetUserByIDAnyStatus(ctx context.Context, userID int64) (User, error)
+
 	// GetUserByEmail 通过 Email 查询用户（包含密码）
 	GetUserByEmail(ctx context.Context, email string) (User, error)
 
+	// GetUserPassword 查询用户密码哈希（用户不存在、已停用或已删除时返回 sql.ErrNoRows）
+	GetUserPassword(ctx context.Context, userID int64) (string, error)
+
+	// GetPasswordHistory 查询用户最近的密码哈希（最新的在前）
+	GetPasswordHistory(ctx context.Context, userID int64, limit int32) ([]string, error)
+
 	// GetUserByUsername 通过 Username 查询用户
 	GetUserByUsername(ctx context.Context, username string) (User, error)
 
-	// ListUsers 列出用户（分页）
-	ListUsers(ctx context.Context, limit, offset int32) ([]User, error)
+	// ListUsers 按过滤条件列出用户（分页）
+	ListUsers(ctx context.Context, filter UserFilter, limit, offset int32) ([]User, error)
+
+	// ListUsersByCursor 按过滤条件列出用户（按 (created_at, id) 的 keyset 分页）
+	ListUsersByCursor(ctx context.Context, filter UserFilter, page KeysetPage) (KeysetResult[User], error)
 
-	// CountUsers 统计用户总数
-	CountUsers(ctx context.Context) (int64, error)
+	// CountUsers 按过滤条件统计用户数（与 ListUsers 使用相同的过滤条件）
+	CountUsers(ctx context.Context, filter UserFilter) (int64, error)
+
+	// GetUserPermissions 查询用户的额外权限（角色隐式权限之外单独授予的）
+	GetUserPermissions(ctx context.Context, userID int64) ([]string, error)
+
+	// GetUserTokenVersion 查询用户当前的 Token 版本号（用户不存在、已停用或已删除时返回 sql.ErrNoRows）
+	GetUserTokenVersion(ctx context.Context, userID int64) (int64, error)
 
 	// ========================================
 	// 写操作方法
@@ -36,11 +54,38 @@ type UserRepositoryInterface interface {
 	// UpdateUser 更新用户信息
 	UpdateUser(ctx context.Context, params UpdateUserParams) error
 
-	// UpdateUserPassword 更新用户密码
+	// UpdateUserPassword 更新用户密码（同时吊销所有已签发的 Token）
 	UpdateUserPassword(ctx context.Context, userID int64, password string) error
 
-	// DeleteUser 删除用户
-	DeleteUser(ctx context.Context, userID int64) error
+	// RehashUserPassword 用新算法或参数的哈希替换旧哈希（不吊销 Token），返回 false 表示旧哈希已不匹配
+	RehashUserPassword(ctx context.Context, userID int64, oldHash, newHash string) (bool, error)
+
+	// AddPasswordHistory 记录密码哈希，只保留最近 keep 条
+	AddPasswordHistory(ctx context.Context, userID int64, passwordHash string, keep int32) error
+
+	// IncrementTokenVersion 递增 Token 版本号，吊销用户所有已签发的 Token
+	IncrementTokenVersion(ctx context.Context, userID int64) error
+
+	// MarkEmailVerified 标记邮箱已验证（未验证 → 正常），返回 false 表示用户此前不是未验证状态
+	MarkEmailVerified(ctx context.Context, userID int64) (bool, error)
+
+	// UpdateUserRole 更新用户角色，并用 permissions 替换其额外权限（同时吊销所有已签发的 Token）
+	UpdateUserRole(ctx context.Context, userID int64, role string, permissions []string) error
+
+	// UpdateUserStatus 变更用户状态（当前状态为 from 时才更新，同时吊销所有已签发的 Token），返回 false 表示状态已被并发修改
+	UpdateUserStatus(ctx context.Context, userID int64, from, to int16, reason string) (bool, error)
+
+	// AnonymizeUser 匿名化用户（清除个人信息并删除凭据，保留用户记录；返回 false 表示已经匿名化）
+	AnonymizeUser(ctx context.Context, userID int64) (bool, error)
+
+	// PreviewMerge 查询将源用户合并到目标用户时将要转移的数据（不修改数据）
+	PreviewMerge(ctx context.Context, fromUserID, toUserID int64) (MergePreview, error)
+
+	// MergeUser 将源用户合并到目标用户（当前状态为 from 时才合并，转移第三方账户和 API Key），Merged 为 false 表示状态已被并发修改
+	MergeUser(ctx context.Context, fromUserID, toUserID int64, from int16) (MergeResult, error)
+
+	// InvalidateUserCache 清理用户的主键、索引、Token 版本号和计数缓存（事务提交后调用）
+	InvalidateUserCache(ctx context.Context, users ...User) error
 
 	// ========================================
 	// 事务方法
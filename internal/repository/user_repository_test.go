@@ -6,6 +6,7 @@ import (
 	"testing"
 
 	"gin_demo/pkg/cache"
+	"gin_demo/pkg/tenant"
 
 	"github.com/redis/go-redis/v9"
 	"github.com/stretchr/testify/assert"
@@ -177,6 +178,30 @@ func TestUserRepository_Integration(t *testing.T) {
 		assert.Equal(t, "new_password", updatedUser.Password)
 	})
 
+	t.Run("修改角色时吊销 Token", func(t *testing.T) {
+		user, err := repo.CreateUser(ctx, CreateUserParams{
+			Username: "testuser_role",
+			Email:    "test_role@example.com",
+			Password: "password",
+		})
+		require.NoError(t, err)
+
+		// 先读取一次，版本号进入缓存
+		before, err := repo.GetUserTokenVersion(ctx, user.ID)
+		require.NoError(t, err)
+
+		require.NoError(t, repo.UpdateUserRole(ctx, user.ID, "admin", []string{"content:audit"}))
+
+		after, err := repo.GetUserTokenVersion(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, before+1, after)
+
+		updated, err := repo.GetUserByID(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "admin", updated.Role)
+		assert.Equal(t, after, updated.TokenVersion)
+	})
+
 	t.Run("删除用户（软删除）", func(t *testing.T) {
 		// 创建用户
 		user, err := repo.CreateUser(ctx, CreateUserParams{
@@ -188,13 +213,25 @@ func TestUserRepository_Integration(t *testing.T) {
 		require.NoError(t, err)
 
 		// 删除用户
-		err = repo.DeleteUser(ctx, user.ID)
+		changed, err := repo.UpdateUserStatus(ctx, user.ID, user.Status, UserStatusDeleted, "")
 		require.NoError(t, err)
+		assert.True(t, changed)
 
 		// 查询应该返回 not found（软删除）
 		_, err = repo.GetUserByID(ctx, user.ID)
 		assert.Error(t, err)
 		assert.Equal(t, sql.ErrNoRows, err)
+
+		// 管理员仍能查到，并记录删除时间
+		deleted, err := repo.GetUserByIDAnyStatus(ctx, user.ID)
+		require.NoError(t, err)
+		assert.Equal(t, UserStatusDeleted, deleted.Status)
+		assert.True(t, deleted.DeletedAt.Valid)
+
+		// 状态已变化时不再更新
+		changed, err = repo.UpdateUserStatus(ctx, user.ID, user.Status, UserStatusSuspended, "spam")
+		require.NoError(t, err)
+		assert.False(t, changed)
 	})
 
 	t.Run("用户列表和统计", func(t *testing.T) {
@@ -210,17 +247,17 @@ func TestUserRepository_Integration(t *testing.T) {
 		}
 
 		// 查询列表
-		users, err := repo.ListUsers(ctx, 10, 0)
+		users, err := repo.ListUsers(ctx, UserFilter{}, 10, 0)
 		require.NoError(t, err)
 		assert.GreaterOrEqual(t, len(users), 5)
 
 		// 统计总数
-		count, err := repo.CountUsers(ctx)
+		count, err := repo.CountUsers(ctx, UserFilter{})
 		require.NoError(t, err)
 		assert.GreaterOrEqual(t, count, int64(5))
 
 		// 再次统计（应该从缓存读取）
-		count2, err := repo.CountUsers(ctx)
+		count2, err := repo.CountUsers(ctx, UserFilter{})
 		require.NoError(t, err)
 		assert.Equal(t, count, count2)
 	})
@@ -237,7 +274,7 @@ func TestUserRepository_Integration(t *testing.T) {
 		assert.Equal(t, sql.ErrNoRows, err)
 
 		// 验证缓存中存在占位符
-		key := cacheManager.BuildKey("user", 999999)
+		key := cacheManager.BuildKey(ctx, "user", 999999)
 		val, err := rdb.Get(ctx, key).Result()
 		require.NoError(t, err)
 		assert.Equal(t, cache.NotFoundPlaceholder, val)
@@ -254,7 +291,7 @@ func TestUserRepository_Integration(t *testing.T) {
 		require.NoError(t, err)
 
 		// 清理缓存
-		key := cacheManager.BuildKey("user", user.ID)
+		key := cacheManager.BuildKey(ctx, "user", user.ID)
 		_ = rdb.Del(ctx, key)
 
 		// 并发查询同一用户（测试 singleflight）
@@ -272,6 +309,44 @@ func TestUserRepository_Integration(t *testing.T) {
 			<-done
 		}
 	})
+
+	t.Run("租户隔离", func(t *testing.T) {
+		_, err := db.Exec("INSERT IGNORE INTO tenants (id, slug, name) VALUES (2, 'test-tenant', '测试租户')")
+		require.NoError(t, err)
+		otherCtx := tenant.WithID(ctx, 2)
+
+		// 同一邮箱可以在不同租户中注册
+		user, err := repo.CreateUser(ctx, CreateUserParams{
+			Username: "tenant_user",
+			Email:    "test_tenant@example.com",
+			Password: "password",
+		})
+		require.NoError(t, err)
+		other, err := repo.CreateUser(otherCtx, CreateUserParams{
+			TenantID: tenant.DefaultID, // 由 context 决定，参数中的值被忽略
+			Username: "tenant_user",
+			Email:    "test_tenant@example.com",
+			Password: "password",
+		})
+		require.NoError(t, err)
+		assert.Equal(t, int64(2), other.TenantID)
+
+		// 其他租户的用户不可见（包括缓存）
+		_, err = repo.GetUserByID(otherCtx, user.ID)
+		assert.Equal(t, sql.ErrNoRows, err)
+		_, err = repo.GetUserByID(ctx, other.ID)
+		assert.Equal(t, sql.ErrNoRows, err)
+		assert.NotEqual(t, cacheManager.BuildKey(ctx, "user", user.ID), cacheManager.BuildKey(otherCtx, "user", user.ID))
+
+		found, err := repo.GetUserByEmail(otherCtx, "test_tenant@example.com")
+		require.NoError(t, err)
+		assert.Equal(t, other.ID, found.ID)
+
+		// 不能修改或删除其他租户的用户
+		_, err = repo.UpdateUserStatus(otherCtx, user.ID, user.Status, UserStatusDeleted, "")
+		assert.Error(t, err)
+		assert.Error(t, repo.UpdateUserRole(otherCtx, user.ID, "admin", nil))
+	})
 }
 
 // BenchmarkUserRepository_GetUserByID 性能基准测试
@@ -315,7 +390,7 @@ func BenchmarkUserRepository_GetUserByID(b *testing.B) {
 	})
 
 	b.Run("CacheMiss", func(b *testing.B) {
-		key := cacheManager.BuildKey("user", user.ID)
+		key := cacheManager.BuildKey(ctx, "user", user.ID)
 		for i := 0; i < b.N; i++ {
 			// 每次都清理缓存，模拟缓存未命中
 			_ = rdb.Del(ctx, key)
@@ -0,0 +1,115 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"gin_demo/pkg/cache"
+	dbContext "gin_demo/pkg/database"
+)
+
+// UserTokenRepository 一次性令牌仓库层（邮箱验证、密码重置）
+//
+// 令牌只使用一次，不使用缓存。
+type UserTokenRepository struct {
+	*BaseRepository[UserToken]
+	queries *Queries
+}
+
+// NewUserTokenRepository 创建一次性令牌仓库实例
+func NewUserTokenRepository(db *sql.DB, cacheManager *cache.Manager) *UserTokenRepository {
+	return &UserTokenRepository{
+		BaseRepository: NewBaseRepository[UserToken](db, cacheManager),
+		queries:        New(db),
+	}
+}
+
+// CreateToken 保存新令牌哈希（事务内先作废旧令牌）
+func (r *UserTokenRepository) CreateToken(ctx context.Context, userID int64, purpose, tokenHash string, expiresAt time.Time) error {
+	return r.WithTx(ctx, func(tx *sql.Tx) error {
+		q := r.queries.WithTx(tx)
+
+		if err := q.DeleteUserTokens(ctx, DeleteUserTokensParams{
+			UserID:  userID,
+			Purpose: purpose,
+		}); err != nil {
+			return fmt.Errorf("repository: delete old tokens: %w", err)
+		}
+
+		if err := q.CreateUserToken(ctx, CreateUserTokenParams{
+			UserID:    userID,
+			Purpose:   purpose,
+			TokenHash: tokenHash,
+			ExpiresAt: expiresAt,
+		}); err != nil {
+			return fmt.Errorf("repository: create token: %w", err)
+		}
+
+		return nil
+	})
+}
+
+// ConsumeToken 使用令牌（事务内执行，条件更新保证并发请求只有一个成功）
+func (r *UserTokenRepository) ConsumeToken(ctx context.Context, purpose, tokenHash string) (int64, error) {
+	var userID int64
+
+	err := r.WithTx(ctx, func(tx *sql.Tx) error {
+		q := r.queries.WithTx(tx)
+
+		token, err := q.GetUserToken(ctx, GetUserTokenParams{
+			TokenHash: tokenHash,
+			Purpose:   purpose,
+		})
+		if err != nil {
+			return err
+		}
+
+		affected, err := q.UseUserToken(ctx, token.ID)
+		if err != nil {
+			return fmt.Errorf("repository: use token: %w", err)
+		}
+		if affected == 0 {
+			return sql.ErrNoRows
+		}
+
+		if err := q.DeleteUserTokens(ctx, DeleteUserTokensParams{
+			UserID:  token.UserID,
+			Purpose: purpose,
+		}); err != nil {
+			return fmt.Errorf("repository: delete tokens: %w", err)
+		}
+
+		userID = token.UserID
+		return nil
+	})
+
+	return userID, err
+}
+
+// PeekToken 查询令牌所属用户 ID（不使用令牌；已使用或已过期时返回 sql.ErrNoRows）
+func (r *UserTokenRepository) PeekToken(ctx context.Context, purpose, tokenHash string) (int64, error) {
+	ctx, cancel := dbContext.WithQueryTimeout(ctx)
+	defer cancel()
+
+	token, err := r.queries.GetUserToken(ctx, GetUserTokenParams{
+		TokenHash: tokenHash,
+		Purpose:   purpose,
+	})
+	if err != nil {
+		return 0, err
+	}
+	if token.UsedAt.Valid || !token.ExpiresAt.After(time.Now()) {
+		return 0, sql.ErrNoRows
+	}
+	return token.UserID, nil
+}
+
+// DeleteExpiredTokens 清理过期或已使用的令牌
+func (r *UserTokenRepository) DeleteExpiredTokens(ctx context.Context, before time.Time) (int64, error) {
+	ctx, cancel := dbContext.WithQueryTimeout(ctx)
+	defer cancel()
+
+	return r.queries.DeleteExpiredUserTokens(ctx, before)
+}
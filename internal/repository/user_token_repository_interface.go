@@ -0,0 +1,36 @@
+package repository
+
+import (
+	"context"
+	"time"
+)
+
+// 一次性令牌用途
+const (
+	// TokenPurposeVerifyEmail 邮箱验证
+	TokenPurposeVerifyEmail = "verify_email"
+	// TokenPurposeResetPassword 密码重置
+	TokenPurposeResetPassword = "reset_password"
+)
+
+// UserTokenRepositoryInterface 一次性令牌仓库接口（用于依赖注入和测试）
+type UserTokenRepositoryInterface interface {
+	// CreateToken 保存新令牌哈希，同时作废该用户同一用途的旧令牌
+	CreateToken(ctx context.Context, userID int64, purpose, tokenHash string, expiresAt time.Time) error
+
+	// ConsumeToken 使用令牌并返回所属用户 ID（不存在、已使用或已过期时返回 sql.ErrNoRows）
+	//
+	// 使用成功后该用户同一用途的其他令牌一并作废。
+	ConsumeToken(ctx context.Context, purpose, tokenHash string) (int64, error)
+
+	// PeekToken 查询令牌所属用户 ID，不使用令牌（不存在、已使用或已过期时返回 sql.ErrNoRows）
+	//
+	// 用于在使用令牌前完成其他校验（如新密码不符合策略时令牌仍然有效）。
+	PeekToken(ctx context.Context, purpose, tokenHash string) (int64, error)
+
+	// DeleteExpiredTokens 清理 before 之前过期或已使用的令牌
+	DeleteExpiredTokens(ctx context.Context, before time.Time) (int64, error)
+}
+
+// 确保 UserTokenRepository 实现了接口
+var _ UserTokenRepositoryInterface = (*UserTokenRepository)(nil)
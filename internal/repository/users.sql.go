@@ -11,64 +11,81 @@ import (
 	"time"
 )
 
-const countUsers = `-- name: CountUsers :one
-SELECT COUNT(*) as total
-FROM users
-WHERE status = 1
+const anonymizeUser = `-- name: AnonymizeUser :execrows
+UPDATE users
+SET username = ?,
+    email = ?,
+    password = '',
+    avatar = NULL,
+    status = 5,
+    status_reason = NULL,
+    deleted_at = COALESCE(deleted_at, CURRENT_TIMESTAMP),
+    token_version = token_version + 1
+WHERE id = ? AND tenant_id = ? AND status <> 5
 `
 
-// 统计用户总数
-func (q *Queries) CountUsers(ctx context.Context) (int64, error) {
-	row := q.db.QueryRowContext(ctx, countUsers)
-	var total int64
-	err := row.Scan(&total)
-	return total, err
+type AnonymizeUserParams struct {
+	Username string `json:"username"`
+	Email    string `json:"email"`
+	ID       int64  `json:"id"`
+	TenantID int64  `json:"tenant_id"`
+}
+
+// 匿名化用户（清除个人信息并设为已匿名状态，保留记录；同时递增 Token 版本号）
+func (q *Queries) AnonymizeUser(ctx context.Context, arg AnonymizeUserParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, anonymizeUser,
+		arg.Username,
+		arg.Email,
+		arg.ID,
+		arg.TenantID,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
 
 const createUser = `-- name: CreateUser :execresult
-INSERT INTO users (username, email, password, avatar)
-VALUES (?, ?, ?, ?)
+INSERT INTO users (tenant_id, username, email, password, avatar, status)
+VALUES (?, ?, ?, ?, ?, ?)
 `
 
 type CreateUserParams struct {
+	TenantID int64          `json:"tenant_id"`
 	Username string         `json:"username"`
 	Email    string         `json:"email"`
 	Password string         `json:"password"`
 	Avatar   sql.NullString `json:"avatar"`
+	Status   int16          `json:"status"`
 }
 
-// 创建用户（MySQL 使用 execresult 获取 LastInsertId）
+// 创建用户（MySQL 使用 execresult 获取 LastInsertId；status 1:正常 3:邮箱未验证）
 func (q *Queries) CreateUser(ctx context.Context, arg CreateUserParams) (sql.Result, error) {
 	return q.db.ExecContext(ctx, createUser,
+		arg.TenantID,
 		arg.Username,
 		arg.Email,
 		arg.Password,
 		arg.Avatar,
+		arg.Status,
 	)
 }
 
-const deleteUser = `-- name: DeleteUser :exec
-UPDATE users
-SET status = 2
-WHERE id = ?
-`
-
-// 软删除用户（设置状态为禁用）
-func (q *Queries) DeleteUser(ctx context.Context, id int64) error {
-	_, err := q.db.ExecContext(ctx, deleteUser, id)
-	return err
-}
-
 const getUserByEmail = `-- name: GetUserByEmail :one
-SELECT id, username, email, password, avatar, status, created_at, updated_at
+SELECT id, username, email, password, avatar, status, created_at, updated_at, role, token_version, tenant_id, status_reason, deleted_at
 FROM users
-WHERE email = ? AND status = 1
+WHERE email = ? AND tenant_id = ? AND status IN (1, 3)
 LIMIT 1
 `
 
-// 通过 Email 获取用户（包含密码，用于登录验证）
-func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error) {
-	row := q.db.QueryRowContext(ctx, getUserByEmail, email)
+type GetUserByEmailParams struct {
+	Email    string `json:"email"`
+	TenantID int64  `json:"tenant_id"`
+}
+
+// 通过 Email 获取用户（包含密码，用于登录验证；包含邮箱未验证的用户）
+func (q *Queries) GetUserByEmail(ctx context.Context, arg GetUserByEmailParams) (User, error) {
+	row := q.db.QueryRowContext(ctx, getUserByEmail, arg.Email, arg.TenantID)
 	var i User
 	err := row.Scan(
 		&i.ID,
@@ -79,30 +96,43 @@ func (q *Queries) GetUserByEmail(ctx context.Context, email string) (User, error
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Role,
+		&i.TokenVersion,
+		&i.TenantID,
+		&i.StatusReason,
+		&i.DeletedAt,
 	)
 	return i, err
 }
 
 const getUserByID = `-- name: GetUserByID :one
-SELECT id, username, email, avatar, status, created_at, updated_at
+SELECT id, username, email, avatar, status, created_at, updated_at, role, token_version, tenant_id
 FROM users
-WHERE id = ? AND status = 1
+WHERE id = ? AND tenant_id = ? AND status IN (1, 3)
 LIMIT 1
 `
 
+type GetUserByIDParams struct {
+	ID       int64 `json:"id"`
+	TenantID int64 `json:"tenant_id"`
+}
+
 type GetUserByIDRow struct {
-	ID        int64          `json:"id"`
-	Username  string         `json:"username"`
-	Email     string         `json:"email"`
-	Avatar    sql.NullString `json:"avatar"`
-	Status    int16          `json:"status"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
+	ID           int64          `json:"id"`
+	Username     string         `json:"username"`
+	Email        string         `json:"email"`
+	Avatar       sql.NullString `json:"avatar"`
+	Status       int16          `json:"status"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	Role         string         `json:"role"`
+	TokenVersion int64          `json:"token_version"`
+	TenantID     int64          `json:"tenant_id"`
 }
 
-// 通过 ID 获取用户
-func (q *Queries) GetUserByID(ctx context.Context, id int64) (GetUserByIDRow, error) {
-	row := q.db.QueryRowContext(ctx, getUserByID, id)
+// 通过 ID 获取用户（包含邮箱未验证的用户）
+func (q *Queries) GetUserByID(ctx context.Context, arg GetUserByIDParams) (GetUserByIDRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserByID, arg.ID, arg.TenantID)
 	var i GetUserByIDRow
 	err := row.Scan(
 		&i.ID,
@@ -112,17 +142,75 @@ func (q *Queries) GetUserByID(ctx context.Context, id int64) (GetUserByIDRow, er
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Role,
+		&i.TokenVersion,
+		&i.TenantID,
+	)
+	return i, err
+}
+
+const getUserByIDAnyStatus = `-- name: GetUserByIDAnyStatus :one
+SELECT id, username, email, avatar, status, created_at, updated_at, role, token_version, tenant_id, status_reason, deleted_at, merged_into
+FROM users
+WHERE id = ? AND tenant_id = ?
+LIMIT 1
+`
+
+type GetUserByIDAnyStatusParams struct {
+	ID       int64 `json:"id"`
+	TenantID int64 `json:"tenant_id"`
+}
+
+type GetUserByIDAnyStatusRow struct {
+	ID           int64          `json:"id"`
+	Username     string         `json:"username"`
+	Email        string         `json:"email"`
+	Avatar       sql.NullString `json:"avatar"`
+	Status       int16          `json:"status"`
+	CreatedAt    time.Time      `json:"created_at"`
+	UpdatedAt    time.Time      `json:"updated_at"`
+	Role         string         `json:"role"`
+	TokenVersion int64          `json:"token_version"`
+	TenantID     int64          `json:"tenant_id"`
+	StatusReason sql.NullString `json:"status_reason"`
+	DeletedAt    sql.NullTime   `json:"deleted_at"`
+	MergedInto   sql.NullInt64  `json:"merged_into"`
+}
+
+// 通过 ID 获取用户（包含停用和已删除的用户，用于管理员变更用户状态）
+func (q *Queries) GetUserByIDAnyStatus(ctx context.Context, arg GetUserByIDAnyStatusParams) (GetUserByIDAnyStatusRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserByIDAnyStatus, arg.ID, arg.TenantID)
+	var i GetUserByIDAnyStatusRow
+	err := row.Scan(
+		&i.ID,
+		&i.Username,
+		&i.Email,
+		&i.Avatar,
+		&i.Status,
+		&i.CreatedAt,
+		&i.UpdatedAt,
+		&i.Role,
+		&i.TokenVersion,
+		&i.TenantID,
+		&i.StatusReason,
+		&i.DeletedAt,
+		&i.MergedInto,
 	)
 	return i, err
 }
 
 const getUserByUsername = `-- name: GetUserByUsername :one
-SELECT id, username, email, avatar, status, created_at, updated_at
+SELECT id, username, email, avatar, status, created_at, updated_at, role, tenant_id
 FROM users
-WHERE username = ? AND status = 1
+WHERE username = ? AND tenant_id = ? AND status IN (1, 3)
 LIMIT 1
 `
 
+type GetUserByUsernameParams struct {
+	Username string `json:"username"`
+	TenantID int64  `json:"tenant_id"`
+}
+
 type GetUserByUsernameRow struct {
 	ID        int64          `json:"id"`
 	Username  string         `json:"username"`
@@ -131,11 +219,13 @@ type GetUserByUsernameRow struct {
 	Status    int16          `json:"status"`
 	CreatedAt time.Time      `json:"created_at"`
 	UpdatedAt time.Time      `json:"updated_at"`
+	Role      string         `json:"role"`
+	TenantID  int64          `json:"tenant_id"`
 }
 
 // 通过 Username 获取用户
-func (q *Queries) GetUserByUsername(ctx context.Context, username string) (GetUserByUsernameRow, error) {
-	row := q.db.QueryRowContext(ctx, getUserByUsername, username)
+func (q *Queries) GetUserByUsername(ctx context.Context, arg GetUserByUsernameParams) (GetUserByUsernameRow, error) {
+	row := q.db.QueryRowContext(ctx, getUserByUsername, arg.Username, arg.TenantID)
 	var i GetUserByUsernameRow
 	err := row.Scan(
 		&i.ID,
@@ -145,6 +235,8 @@ func (q *Queries) GetUserByUsername(ctx context.Context, username string) (GetUs
 		&i.Status,
 		&i.CreatedAt,
 		&i.UpdatedAt,
+		&i.Role,
+		&i.TenantID,
 	)
 	return i, err
 }
@@ -152,13 +244,18 @@ func (q *Queries) GetUserByUsername(ctx context.Context, username string) (GetUs
 const getUserIDByEmail = `-- name: GetUserIDByEmail :one
 SELECT id
 FROM users
-WHERE email = ? AND status = 1
+WHERE email = ? AND tenant_id = ? AND status IN (1, 3)
 LIMIT 1
 `
 
+type GetUserIDByEmailParams struct {
+	Email    string `json:"email"`
+	TenantID int64  `json:"tenant_id"`
+}
+
 // 通过 Email 获取用户 ID（用于缓存索引）
-func (q *Queries) GetUserIDByEmail(ctx context.Context, email string) (int64, error) {
-	row := q.db.QueryRowContext(ctx, getUserIDByEmail, email)
+func (q *Queries) GetUserIDByEmail(ctx context.Context, arg GetUserIDByEmailParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getUserIDByEmail, arg.Email, arg.TenantID)
 	var id int64
 	err := row.Scan(&id)
 	return id, err
@@ -167,71 +264,155 @@ func (q *Queries) GetUserIDByEmail(ctx context.Context, email string) (int64, er
 const getUserIDByUsername = `-- name: GetUserIDByUsername :one
 SELECT id
 FROM users
-WHERE username = ? AND status = 1
+WHERE username = ? AND tenant_id = ? AND status IN (1, 3)
 LIMIT 1
 `
 
+type GetUserIDByUsernameParams struct {
+	Username string `json:"username"`
+	TenantID int64  `json:"tenant_id"`
+}
+
 // 通过 Username 获取用户 ID（用于缓存索引）
-func (q *Queries) GetUserIDByUsername(ctx context.Context, username string) (int64, error) {
-	row := q.db.QueryRowContext(ctx, getUserIDByUsername, username)
+func (q *Queries) GetUserIDByUsername(ctx context.Context, arg GetUserIDByUsernameParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getUserIDByUsername, arg.Username, arg.TenantID)
 	var id int64
 	err := row.Scan(&id)
 	return id, err
 }
 
-const listUsers = `-- name: ListUsers :many
-SELECT id, username, email, avatar, status, created_at, updated_at
+const getUserPasswordByID = `-- name: GetUserPasswordByID :one
+SELECT password
 FROM users
-WHERE status = 1
-ORDER BY created_at DESC
-LIMIT ? OFFSET ?
+WHERE id = ? AND tenant_id = ? AND status IN (1, 3)
+LIMIT 1
 `
 
-type ListUsersParams struct {
-	Limit  int32 `json:"limit"`
-	Offset int32 `json:"offset"`
+type GetUserPasswordByIDParams struct {
+	ID       int64 `json:"id"`
+	TenantID int64 `json:"tenant_id"`
 }
 
-type ListUsersRow struct {
-	ID        int64          `json:"id"`
-	Username  string         `json:"username"`
-	Email     string         `json:"email"`
-	Avatar    sql.NullString `json:"avatar"`
-	Status    int16          `json:"status"`
-	CreatedAt time.Time      `json:"created_at"`
-	UpdatedAt time.Time      `json:"updated_at"`
+// 通过 ID 获取用户密码哈希（用于修改密码时校验旧密码）
+func (q *Queries) GetUserPasswordByID(ctx context.Context, arg GetUserPasswordByIDParams) (string, error) {
+	row := q.db.QueryRowContext(ctx, getUserPasswordByID, arg.ID, arg.TenantID)
+	var password string
+	err := row.Scan(&password)
+	return password, err
+}
+
+const getUserTokenVersion = `-- name: GetUserTokenVersion :one
+SELECT token_version
+FROM users
+WHERE id = ? AND tenant_id = ? AND status = 1
+LIMIT 1
+`
+
+type GetUserTokenVersionParams struct {
+	ID       int64 `json:"id"`
+	TenantID int64 `json:"tenant_id"`
+}
+
+// 获取用户 Token 版本号（用于校验 Token 是否已被吊销）
+func (q *Queries) GetUserTokenVersion(ctx context.Context, arg GetUserTokenVersionParams) (int64, error) {
+	row := q.db.QueryRowContext(ctx, getUserTokenVersion, arg.ID, arg.TenantID)
+	var token_version int64
+	err := row.Scan(&token_version)
+	return token_version, err
+}
+
+const incrementUserTokenVersion = `-- name: IncrementUserTokenVersion :exec
+UPDATE users
+SET token_version = token_version + 1
+WHERE id = ? AND tenant_id = ?
+`
+
+type IncrementUserTokenVersionParams struct {
+	ID       int64 `json:"id"`
+	TenantID int64 `json:"tenant_id"`
+}
+
+// 递增 Token 版本号（吊销所有已签发 Token）
+func (q *Queries) IncrementUserTokenVersion(ctx context.Context, arg IncrementUserTokenVersionParams) error {
+	_, err := q.db.ExecContext(ctx, incrementUserTokenVersion, arg.ID, arg.TenantID)
+	return err
+}
+
+const mergeUser = `-- name: MergeUser :execrows
+UPDATE users
+SET status = 6,
+    merged_into = ?,
+    status_reason = NULL,
+    token_version = token_version + 1
+WHERE id = ? AND tenant_id = ? AND status = ?
+`
+
+type MergeUserParams struct {
+	MergedInto sql.NullInt64 `json:"merged_into"`
+	ID         int64         `json:"id"`
+	TenantID   int64         `json:"tenant_id"`
+	FromStatus int16         `json:"from_status"`
 }
 
-// 列出用户（分页）
-func (q *Queries) ListUsers(ctx context.Context, arg ListUsersParams) ([]ListUsersRow, error) {
-	rows, err := q.db.QueryContext(ctx, listUsers, arg.Limit, arg.Offset)
+// 标记用户已合并到另一个用户（只在当前状态与预期一致时更新，同时递增 Token 版本号）
+func (q *Queries) MergeUser(ctx context.Context, arg MergeUserParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, mergeUser,
+		arg.MergedInto,
+		arg.ID,
+		arg.TenantID,
+		arg.FromStatus,
+	)
 	if err != nil {
-		return nil, err
-	}
-	defer rows.Close()
-	items := []ListUsersRow{}
-	for rows.Next() {
-		var i ListUsersRow
-		if err := rows.Scan(
-			&i.ID,
-			&i.Username,
-			&i.Email,
-			&i.Avatar,
-			&i.Status,
-			&i.CreatedAt,
-			&i.UpdatedAt,
-		); err != nil {
-			return nil, err
-		}
-		items = append(items, i)
+		return 0, err
 	}
-	if err := rows.Close(); err != nil {
-		return nil, err
+	return result.RowsAffected()
+}
+
+const purgeDeletedUsers = `-- name: PurgeDeletedUsers :execrows
+DELETE FROM users
+WHERE status = 4 AND deleted_at < ?
+LIMIT ?
+`
+
+type PurgeDeletedUsersParams struct {
+	DeletedAt sql.NullTime `json:"deleted_at"`
+	Limit     int32        `json:"limit"`
+}
+
+// 永久删除超过保留期的已删除用户（所有租户，分批执行；关联数据通过外键级联删除）
+func (q *Queries) PurgeDeletedUsers(ctx context.Context, arg PurgeDeletedUsersParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, purgeDeletedUsers, arg.DeletedAt, arg.Limit)
+	if err != nil {
+		return 0, err
 	}
-	if err := rows.Err(); err != nil {
-		return nil, err
+	return result.RowsAffected()
+}
+
+const rehashUserPassword = `-- name: RehashUserPassword :execrows
+UPDATE users
+SET password = ?
+WHERE id = ? AND tenant_id = ? AND password = ?
+`
+
+type RehashUserPasswordParams struct {
+	NewPassword string `json:"new_password"`
+	ID          int64  `json:"id"`
+	TenantID    int64  `json:"tenant_id"`
+	OldPassword string `json:"old_password"`
+}
+
+// 更新密码哈希（算法或参数升级，密码未变，不吊销 Token；旧哈希不匹配时不更新，避免覆盖并发修改的密码）
+func (q *Queries) RehashUserPassword(ctx context.Context, arg RehashUserPasswordParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, rehashUserPassword,
+		arg.NewPassword,
+		arg.ID,
+		arg.TenantID,
+		arg.OldPassword,
+	)
+	if err != nil {
+		return 0, err
 	}
-	return items, nil
+	return result.RowsAffected()
 }
 
 const updateUser = `-- name: UpdateUser :exec
@@ -239,7 +420,7 @@ UPDATE users
 SET username = ?,
     email = ?,
     avatar = ?
-WHERE id = ?
+WHERE id = ? AND tenant_id = ?
 `
 
 type UpdateUserParams struct {
@@ -247,6 +428,7 @@ type UpdateUserParams struct {
 	Email    string         `json:"email"`
 	Avatar   sql.NullString `json:"avatar"`
 	ID       int64          `json:"id"`
+	TenantID int64          `json:"tenant_id"`
 }
 
 // 更新用户信息
@@ -256,23 +438,99 @@ func (q *Queries) UpdateUser(ctx context.Context, arg UpdateUserParams) error {
 		arg.Email,
 		arg.Avatar,
 		arg.ID,
+		arg.TenantID,
 	)
 	return err
 }
 
 const updateUserPassword = `-- name: UpdateUserPassword :exec
 UPDATE users
-SET password = ?
-WHERE id = ?
+SET password = ?,
+    token_version = token_version + 1
+WHERE id = ? AND tenant_id = ?
 `
 
 type UpdateUserPasswordParams struct {
 	Password string `json:"password"`
 	ID       int64  `json:"id"`
+	TenantID int64  `json:"tenant_id"`
 }
 
-// 更新用户密码
+// 更新用户密码（同时递增 Token 版本号，使旧 Token 失效）
 func (q *Queries) UpdateUserPassword(ctx context.Context, arg UpdateUserPasswordParams) error {
-	_, err := q.db.ExecContext(ctx, updateUserPassword, arg.Password, arg.ID)
+	_, err := q.db.ExecContext(ctx, updateUserPassword, arg.Password, arg.ID, arg.TenantID)
 	return err
 }
+
+const updateUserRole = `-- name: UpdateUserRole :exec
+UPDATE users
+SET role = ?,
+    token_version = token_version + 1
+WHERE id = ? AND tenant_id = ?
+`
+
+type UpdateUserRoleParams struct {
+	Role     string `json:"role"`
+	ID       int64  `json:"id"`
+	TenantID int64  `json:"tenant_id"`
+}
+
+// 更新用户角色（同时递增 Token 版本号，已签发的 Token 中的旧角色立即失效）
+func (q *Queries) UpdateUserRole(ctx context.Context, arg UpdateUserRoleParams) error {
+	_, err := q.db.ExecContext(ctx, updateUserRole, arg.Role, arg.ID, arg.TenantID)
+	return err
+}
+
+const updateUserStatus = `-- name: UpdateUserStatus :execrows
+UPDATE users
+SET status = ?,
+    status_reason = ?,
+    deleted_at = ?,
+    token_version = token_version + 1
+WHERE id = ? AND tenant_id = ? AND status = ?
+`
+
+type UpdateUserStatusParams struct {
+	Status       int16          `json:"status"`
+	StatusReason sql.NullString `json:"status_reason"`
+	DeletedAt    sql.NullTime   `json:"deleted_at"`
+	ID           int64          `json:"id"`
+	TenantID     int64          `json:"tenant_id"`
+	FromStatus   int16          `json:"from_status"`
+}
+
+// 变更用户状态（状态 1:正常 2:停用 3:待激活 4:已删除；只在当前状态与预期一致时更新，同时递增 Token 版本号）
+func (q *Queries) UpdateUserStatus(ctx context.Context, arg UpdateUserStatusParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, updateUserStatus,
+		arg.Status,
+		arg.StatusReason,
+		arg.DeletedAt,
+		arg.ID,
+		arg.TenantID,
+		arg.FromStatus,
+	)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
+const verifyUserEmail = `-- name: VerifyUserEmail :execrows
+UPDATE users
+SET status = 1
+WHERE id = ? AND tenant_id = ? AND status = 3
+`
+
+type VerifyUserEmailParams struct {
+	ID       int64 `json:"id"`
+	TenantID int64 `json:"tenant_id"`
+}
+
+// 标记邮箱已验证（仅对未验证状态生效）
+func (q *Queries) VerifyUserEmail(ctx context.Context, arg VerifyUserEmailParams) (int64, error) {
+	result, err := q.db.ExecContext(ctx, verifyUserEmail, arg.ID, arg.TenantID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
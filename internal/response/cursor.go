@@ -0,0 +1,140 @@
+package response
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// ErrInvalidCursor 分页游标格式错误、签名不匹配或不属于当前列表
+var ErrInvalidCursor = New(CodeInvalidParams, "无效的分页游标")
+
+// Cursor 游标分页位置（按 (created_at, id) 定位的一条记录）
+type Cursor struct {
+	CreatedAt time.Time
+	ID        int64
+	Backward  bool // true 表示返回该记录之前的一页（prev_cursor）
+}
+
+// cursorPayloadSize 游标内容长度：created_at（Unix 纳秒）+ id + 方向
+const cursorPayloadSize = 8 + 8 + 1
+
+// cursorMACSize 签名长度（截断的 HMAC-SHA256）
+const cursorMACSize = 16
+
+// CursorCodec 游标编解码器
+//
+// 游标对客户端是不透明的字符串，签名防止客户端构造任意位置；
+// 签名绑定 scope（如列表名和排序方向），一个列表的游标不能用于另一个列表。
+type CursorCodec struct {
+	secret []byte
+}
+
+// NewCursorCodec 创建游标编解码器
+func NewCursorCodec(secret []byte) *CursorCodec {
+	return &CursorCodec{secret: secret}
+}
+
+// Encode 编码并签名游标
+func (c *CursorCodec) Encode(scope string, cursor Cursor) string {
+	buf := make([]byte, cursorPayloadSize, cursorPayloadSize+cursorMACSize)
+	binary.BigEndian.PutUint64(buf[0:8], uint64(cursor.CreatedAt.UnixNano()))
+	binary.BigEndian.PutUint64(buf[8:16], uint64(cursor.ID))
+	if cursor.Backward {
+		buf[16] = 1
+	}
+	buf = append(buf, c.sign(scope, buf)...)
+	return base64.RawURLEncoding.EncodeToString(buf)
+}
+
+// Decode 校验签名并解码游标
+func (c *CursorCodec) Decode(scope, token string) (Cursor, error) {
+	buf, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil || len(buf) != cursorPayloadSize+cursorMACSize {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	payload, mac := buf[:cursorPayloadSize], buf[cursorPayloadSize:]
+	if !hmac.Equal(mac, c.sign(scope, payload)) || payload[16] > 1 {
+		return Cursor{}, ErrInvalidCursor
+	}
+
+	return Cursor{
+		CreatedAt: time.Unix(0, int64(binary.BigEndian.Uint64(payload[0:8]))).UTC(),
+		ID:        int64(binary.BigEndian.Uint64(payload[8:16])),
+		Backward:  payload[16] == 1,
+	}, nil
+}
+
+// sign 计算 HMAC-SHA256(scope + ":" + payload)，截断为 cursorMACSize 字节
+func (c *CursorCodec) sign(scope string, payload []byte) []byte {
+	mac := hmac.New(sha256.New, c.secret)
+	mac.Write([]byte(scope))
+	mac.Write([]byte{':'})
+	mac.Write(payload)
+	return mac.Sum(nil)[:cursorMACSize]
+}
+
+// CursorPaginationRequest 游标分页请求参数
+type CursorPaginationRequest struct {
+	Cursor   string // 上一页响应中的 next_cursor 或 prev_cursor（为空表示第一页）
+	PageSize int    // 每页数量
+}
+
+// GetCursorPagination 从请求中获取游标分页参数
+// 请求中带有 cursor 参数（第一页传空值）时使用游标分页，返回 false 表示使用 page/size 分页
+func GetCursorPagination(c *gin.Context) (CursorPaginationRequest, bool) {
+	cursor, ok := c.GetQuery("cursor")
+	if !ok {
+		return CursorPaginationRequest{}, false
+	}
+
+	size, _ := strconv.Atoi(c.Query("size"))
+	if size < 1 {
+		size, _ = strconv.Atoi(c.Query("limit"))
+	}
+	if size < 1 {
+		size = 10
+	} else if size > 100 {
+		size = 100
+	}
+
+	return CursorPaginationRequest{
+		Cursor:   cursor,
+		PageSize: size,
+	}, true
+}
+
+// GetLimit 获取查询限制数量
+func (p CursorPaginationRequest) GetLimit() int32 {
+	return int32(p.PageSize)
+}
+
+// CursorPaginationResponse 游标分页响应
+type CursorPaginationResponse struct {
+	PageSize   int    `json:"page_size"`             // 每页数量
+	NextCursor string `json:"next_cursor,omitempty"` // 下一页游标（为空表示没有下一页）
+	PrevCursor string `json:"prev_cursor,omitempty"` // 上一页游标（为空表示没有上一页）
+}
+
+// CursorListResponse 通用列表响应（游标分页）
+type CursorListResponse[T any] struct {
+	Items      []T                      `json:"items"`
+	Pagination CursorPaginationResponse `json:"pagination"`
+}
+
+// NewCursorListResponse 创建游标分页的列表响应
+func NewCursorListResponse[T any](items []T, pagination CursorPaginationResponse) CursorListResponse[T] {
+	if items == nil {
+		items = []T{} // 避免返回 null
+	}
+	return CursorListResponse[T]{
+		Items:      items,
+		Pagination: pagination,
+	}
+}
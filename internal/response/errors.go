@@ -10,13 +10,17 @@ const (
 	CodeOK Code = 0
 
 	// 客户端错误 (10xxx)
-	CodeInvalidParams   Code = 10001 // 参数错误
-	CodeUnauthorized    Code = 10002 // 未授权
-	CodeForbidden       Code = 10003 // 禁止访问
-	CodeNotFound        Code = 10004 // 资源不存在
-	CodeAlreadyExists   Code = 10005 // 资源已存在
-	CodeTooManyRequests Code = 10006 // 请求过于频繁
-	CodeInvalidPassword Code = 10007 // 密码错误
+	CodeInvalidParams    Code = 10001 // 参数错误
+	CodeUnauthorized     Code = 10002 // 未授权
+	CodeForbidden        Code = 10003 // 禁止访问
+	CodeNotFound         Code = 10004 // 资源不存在
+	CodeAlreadyExists    Code = 10005 // 资源已存在
+	CodeTooManyRequests  Code = 10006 // 请求过于频繁
+	CodeInvalidPassword  Code = 10007 // 密码错误
+	CodeAccountLocked    Code = 10008 // 登录失败次数过多，暂时锁定
+	CodeEmailNotVerified Code = 10009 // 邮箱未验证
+	CodeInvalidState     Code = 10010 // 当前状态不允许该操作
+	CodeRequestTooLarge  Code = 10011 // 请求体或批量条数超过上限
 
 	// 服务端错误 (50xxx)
 	CodeInternalError Code = 50001 // 内部错误
@@ -26,17 +30,21 @@ const (
 
 // 错误码消息映射
 var codeMessages = map[Code]string{
-	CodeOK:              "success",
-	CodeInvalidParams:   "参数错误",
-	CodeUnauthorized:    "未授权",
-	CodeForbidden:       "禁止访问",
-	CodeNotFound:        "资源不存在",
-	CodeAlreadyExists:   "资源已存在",
-	CodeTooManyRequests: "请求过于频繁",
-	CodeInvalidPassword: "密码错误",
-	CodeInternalError:   "服务器内部错误",
-	CodeDatabaseError:   "数据库错误",
-	CodeCacheError:      "缓存错误",
+	CodeOK:               "success",
+	CodeInvalidParams:    "参数错误",
+	CodeUnauthorized:     "未授权",
+	CodeForbidden:        "禁止访问",
+	CodeNotFound:         "资源不存在",
+	CodeAlreadyExists:    "资源已存在",
+	CodeTooManyRequests:  "请求过于频繁",
+	CodeInvalidPassword:  "密码错误",
+	CodeAccountLocked:    "登录失败次数过多，请稍后再试",
+	CodeEmailNotVerified: "邮箱未验证，请先完成邮箱验证",
+	CodeInvalidState:     "当前状态不允许该操作",
+	CodeRequestTooLarge:  "请求过大",
+	CodeInternalError:    "服务器内部错误",
+	CodeDatabaseError:    "数据库错误",
+	CodeCacheError:       "缓存错误",
 }
 
 // Message 获取错误码对应的消息
@@ -62,16 +70,30 @@ func Wrap(err error, code Code, message string) *errors.Error {
 	return errors.Wrap(err, code, message)
 }
 
+// CodeOf 获取错误的业务错误码和消息（非业务错误返回内部错误，不暴露原始错误信息）
+//
+// 用于在一个响应中返回多个错误（如批量操作中每一条的结果）。
+func CodeOf(err error) (Code, string) {
+	if code := errors.GetCode(err); code != 0 {
+		return code, errors.GetMessage(err)
+	}
+	return CodeInternalError, Message(CodeInternalError)
+}
+
 // 预定义错误（常用错误的快捷方式）
 var (
-	ErrInvalidParams   = errors.New(CodeInvalidParams, Message(CodeInvalidParams))
-	ErrUnauthorized    = errors.New(CodeUnauthorized, Message(CodeUnauthorized))
-	ErrForbidden       = errors.New(CodeForbidden, Message(CodeForbidden))
-	ErrNotFound        = errors.New(CodeNotFound, Message(CodeNotFound))
-	ErrAlreadyExists   = errors.New(CodeAlreadyExists, Message(CodeAlreadyExists))
-	ErrTooManyRequests = errors.New(CodeTooManyRequests, Message(CodeTooManyRequests))
-	ErrInvalidPassword = errors.New(CodeInvalidPassword, Message(CodeInvalidPassword))
-	ErrInternalError   = errors.New(CodeInternalError, Message(CodeInternalError))
-	ErrDatabaseError   = errors.New(CodeDatabaseError, Message(CodeDatabaseError))
-	ErrCacheError      = errors.New(CodeCacheError, Message(CodeCacheError))
+	ErrInvalidParams    = errors.New(CodeInvalidParams, Message(CodeInvalidParams))
+	ErrUnauthorized     = errors.New(CodeUnauthorized, Message(CodeUnauthorized))
+	ErrForbidden        = errors.New(CodeForbidden, Message(CodeForbidden))
+	ErrNotFound         = errors.New(CodeNotFound, Message(CodeNotFound))
+	ErrAlreadyExists    = errors.New(CodeAlreadyExists, Message(CodeAlreadyExists))
+	ErrTooManyRequests  = errors.New(CodeTooManyRequests, Message(CodeTooManyRequests))
+	ErrInvalidPassword  = errors.New(CodeInvalidPassword, Message(CodeInvalidPassword))
+	ErrAccountLocked    = errors.New(CodeAccountLocked, Message(CodeAccountLocked))
+	ErrEmailNotVerified = errors.New(CodeEmailNotVerified, Message(CodeEmailNotVerified))
+	ErrInvalidState     = errors.New(CodeInvalidState, Message(CodeInvalidState))
+	ErrRequestTooLarge  = errors.New(CodeRequestTooLarge, Message(CodeRequestTooLarge))
+	ErrInternalError    = errors.New(CodeInternalError, Message(CodeInternalError))
+	ErrDatabaseError    = errors.New(CodeDatabaseError, Message(CodeDatabaseError))
+	ErrCacheError       = errors.New(CodeCacheError, Message(CodeCacheError))
 )
@@ -13,8 +13,13 @@ type Response struct {
 	Message string `json:"message"`         // 提示信息
 	Data    any    `json:"data,omitempty"`  // 响应数据
 	Error   string `json:"error,omitempty"` // 错误详情
+
+	Fields []FieldError `json:"fields,omitempty"` // 字段错误（参数校验失败时）
 }
 
+// FieldError 字段错误
+type FieldError = errors.FieldError
+
 // Success 成功响应
 func Success(c *gin.Context, data any) {
 	c.JSON(http.StatusOK, Response{
@@ -24,6 +29,15 @@ func Success(c *gin.Context, data any) {
 	})
 }
 
+// Accepted 已受理响应（202，请求已提交为后台作业，客户端通过 data 中的作业信息查询进度）
+func Accepted(c *gin.Context, data any) {
+	c.JSON(http.StatusAccepted, Response{
+		Code:    CodeOK,
+		Message: "accepted",
+		Data:    data,
+	})
+}
+
 // Error 错误响应
 func Error(c *gin.Context, err error) {
 	// 提取业务错误
@@ -41,6 +55,7 @@ func Error(c *gin.Context, err error) {
 	resp := Response{
 		Code:    bizErr.Code,
 		Message: bizErr.Message,
+		Fields:  bizErr.Fields,
 	}
 
 	// 开发环境返回详细错误
@@ -68,16 +83,18 @@ func getHTTPStatus(code Code) int {
 		return http.StatusBadRequest
 	case CodeUnauthorized:
 		return http.StatusUnauthorized
-	case CodeForbidden:
+	case CodeForbidden, CodeEmailNotVerified:
 		return http.StatusForbidden
 	case CodeNotFound:
 		return http.StatusNotFound
-	case CodeAlreadyExists:
+	case CodeAlreadyExists, CodeInvalidState:
 		return http.StatusConflict
-	case CodeTooManyRequests:
+	case CodeTooManyRequests, CodeAccountLocked:
 		return http.StatusTooManyRequests
 	case CodeInvalidPassword:
 		return http.StatusBadRequest
+	case CodeRequestTooLarge:
+		return http.StatusRequestEntityTooLarge
 	default:
 		return http.StatusInternalServerError
 	}
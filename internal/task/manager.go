@@ -3,8 +3,10 @@ package task
 import (
 	"database/sql"
 	"log/slog"
+	"time"
 
 	"gin_demo/internal/task/tasks"
+	"gin_demo/pkg/auth"
 	"gin_demo/pkg/task"
 	"github.com/redis/go-redis/v9"
 )
@@ -12,10 +14,15 @@ import (
 // Manager 任务管理器
 type Manager struct {
 	scheduler *task.Scheduler
+	jobs      *task.JobQueue
 }
 
 // NewManager 创建任务管理器
-func NewManager(redis redis.UniversalClient, db *sql.DB) *Manager {
+//
+// keyExpiryWarning 为 JWT 密钥到期提醒的提前量，auditRetention 为审计日志的保留时间，
+// deletedUserRetention 为软删除用户的保留时间；jobs 为后台作业队列（随定时任务一起启停），
+// jobDir 和 jobRetention 为作业生成的文件的目录和保留时间。
+func NewManager(redis redis.UniversalClient, db *sql.DB, keys *auth.KeySet, keyExpiryWarning, auditRetention, deletedUserRetention time.Duration, jobs *task.JobQueue, jobDir string, jobRetention time.Duration) *Manager {
 	// 创建调度器
 	scheduler := task.NewScheduler(task.Config{
 		Redis:      redis,
@@ -23,19 +30,25 @@ func NewManager(redis redis.UniversalClient, db *sql.DB) *Manager {
 	})
 	
 	// 注册所有任务
-	registerTasks(scheduler, redis, db)
+	registerTasks(scheduler, redis, db, keys, keyExpiryWarning, auditRetention, deletedUserRetention, jobDir, jobRetention)
 	
 	return &Manager{
 		scheduler: scheduler,
+		jobs:      jobs,
 	}
 }
 
 // registerTasks 注册所有任务
-func registerTasks(scheduler *task.Scheduler, redis redis.UniversalClient, db *sql.DB) {
+func registerTasks(scheduler *task.Scheduler, redis redis.UniversalClient, db *sql.DB, keys *auth.KeySet, keyExpiryWarning, auditRetention, deletedUserRetention time.Duration, jobDir string, jobRetention time.Duration) {
 	taskList := []task.Task{
 		tasks.NewExampleTask(),
 		tasks.NewCleanupTask(redis),
 		tasks.NewStatsTask(db),
+		tasks.NewJWTKeyRotationTask(keys, keyExpiryWarning),
+		tasks.NewUserTokenCleanupTask(db),
+		tasks.NewAuditRetentionTask(db, auditRetention),
+		tasks.NewUserPurgeTask(db, deletedUserRetention),
+		tasks.NewJobFileCleanupTask(jobDir, jobRetention),
 		// 在这里添加更多任务...
 	}
 
@@ -47,14 +60,16 @@ func registerTasks(scheduler *task.Scheduler, redis redis.UniversalClient, db *s
 	}
 }
 
-// Start 启动任务调度
+// Start 启动任务调度和后台作业
 func (m *Manager) Start() {
 	m.scheduler.Start()
+	m.jobs.Start()
 }
 
-// Stop 停止任务调度
+// Stop 停止任务调度和后台作业（正在执行的作业被取消并标记为失败）
 func (m *Manager) Stop() {
 	m.scheduler.Stop()
+	m.jobs.Stop()
 }
 
 // ListTasks 列出所有任务
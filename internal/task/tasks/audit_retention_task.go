@@ -0,0 +1,67 @@
+package tasks
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"gin_demo/internal/repository"
+	"gin_demo/pkg/task"
+)
+
+// auditRetentionBatchSize 每批删除的审计事件数（分批删除，避免长时间锁表）
+const auditRetentionBatchSize = 5000
+
+// AuditRetentionTask 删除超过保留时间的审计日志
+type AuditRetentionTask struct {
+	queries   *repository.Queries
+	retention time.Duration
+}
+
+// NewAuditRetentionTask 创建审计日志清理任务（retention 为 0 时永久保留，任务不执行删除）
+func NewAuditRetentionTask(db *sql.DB, retention time.Duration) task.Task {
+	return &AuditRetentionTask{
+		queries:   repository.New(db),
+		retention: retention,
+	}
+}
+
+func (t *AuditRetentionTask) Name() string {
+	return "audit_retention_task"
+}
+
+func (t *AuditRetentionTask) Spec() string {
+	// 每天凌晨 4 点执行
+	return "0 0 4 * * *"
+}
+
+func (t *AuditRetentionTask) Timeout() time.Duration {
+	return 30 * time.Minute
+}
+
+func (t *AuditRetentionTask) Run(ctx context.Context) error {
+	if t.retention <= 0 {
+		return nil
+	}
+
+	before := time.Now().Add(-t.retention)
+	var total int64
+	for {
+		deleted, err := t.queries.DeleteAuditEventsBefore(ctx, repository.DeleteAuditEventsBeforeParams{
+			CreatedAt: before,
+			Limit:     auditRetentionBatchSize,
+		})
+		if err != nil {
+			slog.Error("AuditRetentionTask: Failed to delete audit events", "error", err, "deleted", total)
+			return err
+		}
+		total += deleted
+		if deleted < auditRetentionBatchSize {
+			break
+		}
+	}
+
+	slog.Info("AuditRetentionTask: Expired audit events deleted", "deleted", total, "before", before)
+	return nil
+}
@@ -0,0 +1,83 @@
+package tasks
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gin_demo/pkg/task"
+)
+
+// JobFileCleanupTask 删除后台作业生成的过期文件（如数据导出包）
+//
+// 作业记录过期后文件无法再下载，按修改时间删除超过保留期的文件。
+type JobFileCleanupTask struct {
+	dir       string
+	retention time.Duration
+}
+
+// NewJobFileCleanupTask 创建作业文件清理任务
+func NewJobFileCleanupTask(dir string, retention time.Duration) task.Task {
+	return &JobFileCleanupTask{
+		dir:       dir,
+		retention: retention,
+	}
+}
+
+func (t *JobFileCleanupTask) Name() string {
+	return "job_file_cleanup_task"
+}
+
+func (t *JobFileCleanupTask) Spec() string {
+	// 每小时第 15 分钟执行
+	return "0 15 * * * *"
+}
+
+func (t *JobFileCleanupTask) Timeout() time.Duration {
+	return 10 * time.Minute
+}
+
+func (t *JobFileCleanupTask) Run(ctx context.Context) error {
+	before := time.Now().Add(-t.retention)
+	var removed int
+
+	err := filepath.WalkDir(t.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				return nil
+			}
+			return err
+		}
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if d.IsDir() {
+			return nil
+		}
+
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		if info.ModTime().After(before) {
+			return nil
+		}
+		if err := os.Remove(path); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			slog.Warn("JobFileCleanupTask: Failed to remove file", "path", path, "error", err)
+			return nil
+		}
+		removed++
+		return nil
+	})
+	if err != nil {
+		slog.Error("JobFileCleanupTask: Failed to clean up job files", "error", err, "removed", removed)
+		return err
+	}
+
+	slog.Info("JobFileCleanupTask: Expired job files removed", "removed", removed, "before", before)
+	return nil
+}
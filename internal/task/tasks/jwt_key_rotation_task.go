@@ -0,0 +1,73 @@
+package tasks
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"gin_demo/pkg/auth"
+	"gin_demo/pkg/task"
+)
+
+// JWTKeyRotationTask JWT 密钥到期检查任务
+//
+// 即将到期的密钥输出告警日志，已过期的旧密钥从密钥集中退役。
+// 过期密钥在验证时已被拒绝（见 auth.KeySet），本任务负责清理和提醒。
+type JWTKeyRotationTask struct {
+	keys       *auth.KeySet
+	warnBefore time.Duration
+}
+
+// NewJWTKeyRotationTask 创建 JWT 密钥到期检查任务
+func NewJWTKeyRotationTask(keys *auth.KeySet, warnBefore time.Duration) task.Task {
+	return &JWTKeyRotationTask{
+		keys:       keys,
+		warnBefore: warnBefore,
+	}
+}
+
+func (t *JWTKeyRotationTask) Name() string {
+	return "jwt_key_rotation_task"
+}
+
+func (t *JWTKeyRotationTask) Spec() string {
+	// 每小时执行一次
+	return "0 0 * * * *"
+}
+
+func (t *JWTKeyRotationTask) Timeout() time.Duration {
+	return time.Minute
+}
+
+func (t *JWTKeyRotationTask) Run(ctx context.Context) error {
+	now := time.Now()
+	signing := t.keys.SigningKey()
+
+	retired := 0
+	for _, key := range t.keys.Keys() {
+		if key.NotAfter.IsZero() {
+			continue
+		}
+
+		switch {
+		case key.Expired(now) && key == signing:
+			// 当前签名密钥不能自动退役，只能通过更换配置完成轮换
+			slog.ErrorContext(ctx, "JWTKeyRotationTask: signing key expired, rotate it now",
+				"kid", key.ID, "not_after", key.NotAfter)
+		case key.Expired(now):
+			if err := t.keys.Retire(key.ID); err != nil {
+				slog.WarnContext(ctx, "JWTKeyRotationTask: failed to retire key", "kid", key.ID, "error", err)
+				continue
+			}
+			retired++
+			slog.InfoContext(ctx, "JWTKeyRotationTask: key retired", "kid", key.ID, "not_after", key.NotAfter)
+		case key.NotAfter.Sub(now) <= t.warnBefore:
+			slog.WarnContext(ctx, "JWTKeyRotationTask: key expires soon",
+				"kid", key.ID, "signing", key == signing, "not_after", key.NotAfter,
+				"remaining", key.NotAfter.Sub(now).Round(time.Minute))
+		}
+	}
+
+	slog.InfoContext(ctx, "JWTKeyRotationTask: Completed", "retired_keys", retired)
+	return nil
+}
@@ -0,0 +1,68 @@
+package tasks
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"gin_demo/internal/repository"
+	"gin_demo/pkg/task"
+)
+
+// userPurgeBatchSize 每批彻底删除的用户数（关联数据级联删除，批次不宜过大）
+const userPurgeBatchSize = 1000
+
+// UserPurgeTask 彻底删除超过保留期的已删除用户
+type UserPurgeTask struct {
+	queries   *repository.Queries
+	retention time.Duration
+}
+
+// NewUserPurgeTask 创建已删除用户清理任务（retention 为 0 时永久保留，任务不执行删除）
+func NewUserPurgeTask(db *sql.DB, retention time.Duration) task.Task {
+	return &UserPurgeTask{
+		queries:   repository.New(db),
+		retention: retention,
+	}
+}
+
+func (t *UserPurgeTask) Name() string {
+	return "user_purge_task"
+}
+
+func (t *UserPurgeTask) Spec() string {
+	// 每天凌晨 4:30 执行
+	return "0 30 4 * * *"
+}
+
+func (t *UserPurgeTask) Timeout() time.Duration {
+	return 30 * time.Minute
+}
+
+func (t *UserPurgeTask) Run(ctx context.Context) error {
+	if t.retention <= 0 {
+		return nil
+	}
+
+	// 删除时已清除用户缓存并递增 token_version，这里只需删除数据库记录
+	before := time.Now().Add(-t.retention)
+	var total int64
+	for {
+		purged, err := t.queries.PurgeDeletedUsers(ctx, repository.PurgeDeletedUsersParams{
+			DeletedAt: sql.NullTime{Time: before, Valid: true},
+			Limit:     userPurgeBatchSize,
+		})
+		if err != nil {
+			slog.Error("UserPurgeTask: Failed to purge deleted users", "error", err, "purged", total)
+			return err
+		}
+		total += purged
+		if purged < userPurgeBatchSize {
+			break
+		}
+	}
+
+	slog.Info("UserPurgeTask: Deleted users purged", "purged", total, "before", before)
+	return nil
+}
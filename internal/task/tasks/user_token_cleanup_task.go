@@ -0,0 +1,47 @@
+package tasks
+
+import (
+	"context"
+	"database/sql"
+	"log/slog"
+	"time"
+
+	"gin_demo/internal/repository"
+	"gin_demo/pkg/task"
+)
+
+// UserTokenCleanupTask 清理已过期或已使用的一次性令牌（邮箱验证、密码重置）
+type UserTokenCleanupTask struct {
+	queries *repository.Queries
+}
+
+// NewUserTokenCleanupTask 创建一次性令牌清理任务
+func NewUserTokenCleanupTask(db *sql.DB) task.Task {
+	return &UserTokenCleanupTask{
+		queries: repository.New(db),
+	}
+}
+
+func (t *UserTokenCleanupTask) Name() string {
+	return "user_token_cleanup_task"
+}
+
+func (t *UserTokenCleanupTask) Spec() string {
+	// 每天凌晨 3 点执行
+	return "0 0 3 * * *"
+}
+
+func (t *UserTokenCleanupTask) Timeout() time.Duration {
+	return 5 * time.Minute
+}
+
+func (t *UserTokenCleanupTask) Run(ctx context.Context) error {
+	deleted, err := t.queries.DeleteExpiredUserTokens(ctx, time.Now())
+	if err != nil {
+		slog.Error("UserTokenCleanupTask: Failed to delete expired tokens", "error", err)
+		return err
+	}
+
+	slog.Info("UserTokenCleanupTask: Expired tokens deleted", "deleted", deleted)
+	return nil
+}
@@ -1,9 +1,20 @@
 package wire
 
 import (
+	"gin_demo/internal/app"
+	"gin_demo/internal/app/handler/apikey"
+	"gin_demo/internal/app/handler/audit"
+	"gin_demo/internal/app/handler/avatar"
 	"gin_demo/internal/app/handler/health"
+	"gin_demo/internal/app/handler/jwks"
+	"gin_demo/internal/app/handler/privacy"
+	"gin_demo/internal/app/handler/role"
 	"gin_demo/internal/app/handler/user"
+	"gin_demo/internal/app/handler/userimport"
 	"gin_demo/internal/app/middleware"
+	"gin_demo/internal/config"
+	"gin_demo/internal/domain/service"
+	"gin_demo/pkg/storage"
 
 	"github.com/google/wire"
 )
@@ -11,6 +22,48 @@ import (
 // HandlerSet Handler 层 Provider 集合
 var HandlerSet = wire.NewSet(
 	user.NewHandler,
+	userimport.NewHandler,
+	apikey.NewHandler,
 	health.NewHandler,
+	jwks.NewHandler,
+	role.NewHandler,
+	audit.NewHandler,
+	privacy.NewHandler,
+	avatar.NewHandler,
+	provideUploadFiles,
 	middleware.NewAuthMiddleware,
+	middleware.NewRBACMiddleware,
+	middleware.NewAPIKeyMiddleware,
+	provideTenantMiddleware,
+	provideTokenVersionSource,
+	provideAPIKeyAuthenticator,
 )
+
+// provideTokenVersionSource 提供 Token 版本号来源（认证中间件据此拒绝已吊销的 Token）
+func provideTokenVersionSource(userService service.UserService) middleware.TokenVersionSource {
+	return userService
+}
+
+// provideAPIKeyAuthenticator 提供 API Key 校验（API Key 中间件使用）
+func provideAPIKeyAuthenticator(apiKeyService service.APIKeyService) middleware.APIKeyAuthenticator {
+	return apiKeyService
+}
+
+// provideTenantMiddleware 提供租户解析中间件
+func provideTenantMiddleware(cfg *config.Config, tenantService service.TenantService) *middleware.TenantMiddleware {
+	return middleware.NewTenantMiddleware(tenantService, cfg.Tenant.BaseDomain, cfg.Tenant.Header)
+}
+
+// provideUploadFiles 提供上传文件的访问（只有本地存储由应用提供，S3 存储直接访问存储桶或 CDN）
+//
+// 每次上传使用新的对象键，文件不会被覆盖，可以长期缓存。
+func provideUploadFiles(store storage.BlobStore) *app.StaticFiles {
+	local, ok := store.(*storage.LocalStore)
+	if !ok {
+		return nil
+	}
+	return &app.StaticFiles{
+		Path:    local.Path(),
+		Handler: local.Handler("public, max-age=31536000, immutable"),
+	}
+}
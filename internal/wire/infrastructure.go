@@ -2,12 +2,17 @@ package wire
 
 import (
 	"database/sql"
+	"fmt"
 	"gin_demo/internal/config"
 	internalHealth "gin_demo/internal/health"
+	"gin_demo/internal/response"
 	"gin_demo/pkg/auth"
 	"gin_demo/pkg/cache"
 	"gin_demo/pkg/database"
 	"gin_demo/pkg/health"
+	"gin_demo/pkg/mail"
+	"gin_demo/pkg/storage"
+	"gin_demo/pkg/task"
 
 	"github.com/google/wire"
 	"github.com/redis/go-redis/v9"
@@ -18,8 +23,22 @@ var InfrastructureSet = wire.NewSet(
 	provideDatabase,
 	provideRedis,
 	provideCacheManager,
+	provideJWTKeySet,
 	provideJWTManager,
+	provideRBACJWTManager,
+	provideRefreshTokenManager,
+	provideMFATokenManager,
+	provideImpersonationTokenManager,
+	provideOneTimeTokenSigner,
+	provideCursorCodec,
+	providePasswordHasher,
+	providePasswordPolicy,
+	provideMailer,
+	provideLoginGuard,
+	provideOAuthManager,
 	provideHealthChecker,
+	provideJobQueue,
+	provideBlobStore,
 )
 
 // provideDatabase 提供数据库连接（支持 MySQL 和 PostgreSQL）
@@ -78,9 +97,189 @@ func provideCacheManager(rdb redis.UniversalClient) *cache.Manager {
 	return cache.NewManager(rdb)
 }
 
+// provideJWTKeySet 提供 JWT 密钥集（当前签名密钥 + 轮换前的旧密钥）
+//
+// HS256 使用 secret，非对称算法从 PEM 文件加载。
+func provideJWTKeySet(cfg *config.Config) (*auth.KeySet, error) {
+	var (
+		signing *auth.Key
+		err     error
+	)
+	if cfg.JWT.IsSymmetric() {
+		signing = auth.NewHMACKey(cfg.JWT.KeyID, []byte(cfg.JWT.Secret))
+	} else {
+		signing, err = auth.LoadSigningKeyFile(cfg.JWT.KeyID, cfg.JWT.Algorithm, cfg.JWT.PrivateKeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("wire: load jwt signing key: %w", err)
+		}
+	}
+	signing.NotAfter = cfg.JWT.NotAfter
+
+	verification := make([]*auth.Key, 0, len(cfg.JWT.VerificationKeys))
+	for _, k := range cfg.JWT.VerificationKeys {
+		var key *auth.Key
+		if k.Algorithm == "" || k.Algorithm == "HS256" {
+			key = auth.NewHMACKey(k.KeyID, []byte(k.Secret))
+		} else {
+			key, err = auth.LoadVerificationKeyFile(k.KeyID, k.Algorithm, k.PublicKeyFile)
+			if err != nil {
+				return nil, fmt.Errorf("wire: load jwt verification key %s: %w", k.KeyID, err)
+			}
+		}
+		key.NotAfter = k.NotAfter
+		verification = append(verification, key)
+	}
+
+	return auth.NewKeySet(signing, verification...)
+}
+
 // provideJWTManager 提供 JWT 管理器（默认 int64 类型）
-func provideJWTManager(cfg *config.Config) *auth.DefaultJWTManager {
-	return auth.NewDefaultJWTManager(cfg.JWT.Secret, cfg.JWT.Expiration)
+func provideJWTManager(cfg *config.Config, keys *auth.KeySet) *auth.DefaultJWTManager {
+	return auth.NewDefaultJWTManagerWithKeys(keys, cfg.JWT.Expiration)
+}
+
+// provideRBACJWTManager 提供 RBAC JWT 管理器（与 JWTManager 共用密钥集，Token 可互相校验）
+func provideRBACJWTManager(cfg *config.Config, keys *auth.KeySet) *auth.RBACJWTManager {
+	return auth.NewRBACJWTManagerWithKeys(keys, cfg.JWT.Expiration)
+}
+
+// provideRefreshTokenManager 提供刷新令牌管理器（令牌存储在 Redis）
+func provideRefreshTokenManager(cfg *config.Config, rdb redis.UniversalClient) *auth.RefreshTokenManager {
+	store := auth.NewRedisRefreshTokenStore(rdb, "auth:refresh:")
+	return auth.NewRefreshTokenManager(store, cfg.JWT.RefreshExpiration)
+}
+
+// provideMFATokenManager 提供两步验证待验证令牌管理器（与访问令牌共用密钥集，通过 typ 头区分）
+func provideMFATokenManager(cfg *config.Config, keys *auth.KeySet) *auth.MFATokenManager {
+	return auth.NewMFATokenManager(keys, cfg.Security.MFA.PendingTokenTTL)
+}
+
+// provideImpersonationTokenManager 提供模拟登录令牌管理器（签发普通访问令牌，有效期较短）
+func provideImpersonationTokenManager(cfg *config.Config, keys *auth.KeySet) *auth.ImpersonationTokenManager {
+	return auth.NewImpersonationTokenManager(keys, cfg.Security.Impersonation.TTL)
+}
+
+// provideOneTimeTokenSigner 提供一次性令牌签名器（邮箱验证、密码重置）
+func provideOneTimeTokenSigner(cfg *config.Config) *auth.OneTimeTokenSigner {
+	return auth.NewOneTimeTokenSigner([]byte(cfg.Security.AccountTokens.Secret))
+}
+
+// provideCursorCodec 提供分页游标编解码器（签名 next_cursor / prev_cursor）
+func provideCursorCodec(cfg *config.Config) *response.CursorCodec {
+	return response.NewCursorCodec([]byte(cfg.Security.Cursor.Secret))
+}
+
+// providePasswordHasher 提供密码哈希器（新密码使用配置的算法，旧哈希登录时自动升级）
+func providePasswordHasher(cfg *config.Config) (auth.PasswordHasher, error) {
+	hasher, err := auth.NewPasswordHasher(auth.PasswordHashConfig{
+		Algorithm:  cfg.Security.Password.Algorithm,
+		BcryptCost: cfg.Security.Password.BcryptCost,
+		Argon2id: auth.Argon2idParams{
+			Memory:      cfg.Security.Password.Argon2Memory,
+			Iterations:  cfg.Security.Password.Argon2Iterations,
+			Parallelism: cfg.Security.Password.Argon2Parallelism,
+		},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("wire: create password hasher: %w", err)
+	}
+	return hasher, nil
+}
+
+// providePasswordPolicy 提供密码策略（配置了泄露密码文件时打开文件，进程退出前一直保持打开）
+func providePasswordPolicy(cfg *config.Config) (*auth.PasswordPolicy, error) {
+	policy := cfg.Security.PasswordPolicy
+
+	var breached auth.BreachedPasswordSource
+	if policy.BreachedFile != "" {
+		file, err := auth.OpenBreachedPasswordFile(policy.BreachedFile)
+		if err != nil {
+			return nil, fmt.Errorf("wire: %w", err)
+		}
+		breached = file
+	}
+
+	return auth.NewPasswordPolicy(auth.PasswordPolicyConfig{
+		MinLength:     policy.MinLength,
+		MaxLength:     policy.MaxLength,
+		RequireUpper:  policy.RequireUpper,
+		RequireLower:  policy.RequireLower,
+		RequireDigit:  policy.RequireDigit,
+		RequireSymbol: policy.RequireSymbol,
+		BannedWords:   policy.BannedWords,
+		MaxSimilarity: policy.MaxSimilarity,
+		BreachedMin:   policy.BreachedMinCount,
+	}, breached), nil
+}
+
+// provideMailer 提供邮件发送器（smtp / file / log）
+func provideMailer(cfg *config.Config) mail.Mailer {
+	switch cfg.Mail.Driver {
+	case "smtp":
+		return mail.NewSMTPMailer(mail.SMTPConfig{
+			Host:     cfg.Mail.SMTP.Host,
+			Port:     cfg.Mail.SMTP.Port,
+			Username: cfg.Mail.SMTP.Username,
+			Password: cfg.Mail.SMTP.Password,
+			From:     cfg.Mail.From,
+			TLSMode:  cfg.Mail.SMTP.TLSMode,
+			Timeout:  cfg.Mail.SMTP.Timeout,
+		})
+	case "file":
+		return mail.NewFileMailer(cfg.Mail.FileDir, cfg.Mail.From)
+	default:
+		return mail.NewLogMailer()
+	}
+}
+
+// provideLoginGuard 提供登录暴力破解防护（失败记录存储在 Redis，多实例共享）
+func provideLoginGuard(cfg *config.Config, rdb redis.UniversalClient) *auth.LoginGuard {
+	store := auth.NewRedisLoginAttemptStore(rdb, "auth:login:")
+
+	// 未启用时使用空策略，Check/RecordFailure 不做任何限制
+	protection := cfg.Security.LoginProtection
+	if !protection.Enabled {
+		return auth.NewLoginGuard(store, auth.LockoutPolicy{}, auth.LockoutPolicy{})
+	}
+
+	return auth.NewLoginGuard(store, lockoutPolicy(protection.Account), lockoutPolicy(protection.IP))
+}
+
+// lockoutPolicy 将锁定策略配置转换为 auth.LockoutPolicy
+func lockoutPolicy(c config.LockoutConfig) auth.LockoutPolicy {
+	return auth.LockoutPolicy{
+		MaxAttempts: c.MaxAttempts,
+		BaseDelay:   c.BaseDelay,
+		MaxDelay:    c.MaxDelay,
+		Window:      c.Window,
+	}
+}
+
+// provideOAuthManager 提供第三方登录管理器（授权请求存储在 Redis，回调可落在任意实例）
+func provideOAuthManager(cfg *config.Config, rdb redis.UniversalClient) (*auth.OAuthManager, error) {
+	providers := make([]*auth.OAuthProvider, 0, len(cfg.OAuth.Providers))
+	for _, p := range cfg.OAuth.Providers {
+		provider, err := auth.NewOAuthProvider(auth.OAuthProviderConfig{
+			Name:         p.Name,
+			Type:         p.Type,
+			Issuer:       p.Issuer,
+			ClientID:     p.ClientID,
+			ClientSecret: p.ClientSecret,
+			RedirectURL:  p.RedirectURL,
+			Scopes:       p.Scopes,
+			AuthURL:      p.AuthURL,
+			TokenURL:     p.TokenURL,
+			JWKSURL:      p.JWKSURL,
+			UserInfoURL:  p.UserInfoURL,
+		}, nil)
+		if err != nil {
+			return nil, fmt.Errorf("wire: create oauth provider %s: %w", p.Name, err)
+		}
+		providers = append(providers, provider)
+	}
+
+	store := auth.NewRedisOAuthStateStore(rdb, "auth:oauth:")
+	return auth.NewOAuthManager(store, cfg.OAuth.StateTTL, providers...), nil
 }
 
 // provideHealthChecker 提供健康检查器
@@ -92,3 +291,29 @@ func provideHealthChecker(db *sql.DB, rdb redis.UniversalClient) health.Checker
 	// 创建多组件检查器
 	return health.NewMultiChecker("3.0.0", dbChecker, redisChecker)
 }
+
+// provideJobQueue 提供后台作业队列（作业记录和队列保存在 Redis，任意实例都可以执行和查询）
+func provideJobQueue(cfg *config.Config, rdb redis.UniversalClient) *task.JobQueue {
+	return task.NewJobQueue(task.JobQueueConfig{
+		Store:     task.NewRedisJobStore(rdb, "task:job:"),
+		Workers:   cfg.Jobs.Workers,
+		Timeout:   cfg.Jobs.Timeout,
+		Retention: cfg.Jobs.Retention,
+	})
+}
+
+// provideBlobStore 提供文件存储（用户上传的头像等）
+func provideBlobStore(cfg *config.Config) (storage.BlobStore, error) {
+	if cfg.Storage.Driver == "s3" {
+		return storage.NewS3Store(storage.S3Config{
+			Endpoint:        cfg.Storage.S3.Endpoint,
+			Region:          cfg.Storage.S3.Region,
+			Bucket:          cfg.Storage.S3.Bucket,
+			AccessKeyID:     cfg.Storage.S3.AccessKeyID,
+			SecretAccessKey: cfg.Storage.S3.SecretAccessKey,
+			PathStyle:       cfg.Storage.S3.PathStyle,
+			PublicURL:       cfg.Storage.PublicURL,
+		})
+	}
+	return storage.NewLocalStore(cfg.Storage.Local.Dir, cfg.Storage.PublicURL), nil
+}
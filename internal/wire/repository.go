@@ -10,6 +10,20 @@ import (
 var RepositorySet = wire.NewSet(
 	repository.NewUserRepository,
 	wire.Bind(new(repository.UserRepositoryInterface), new(*repository.UserRepository)),
+	repository.NewMFARepository,
+	wire.Bind(new(repository.MFARepositoryInterface), new(*repository.MFARepository)),
+	repository.NewUserTokenRepository,
+	wire.Bind(new(repository.UserTokenRepositoryInterface), new(*repository.UserTokenRepository)),
+	repository.NewAPIKeyRepository,
+	wire.Bind(new(repository.APIKeyRepositoryInterface), new(*repository.APIKeyRepository)),
+	repository.NewIdentityRepository,
+	wire.Bind(new(repository.IdentityRepositoryInterface), new(*repository.IdentityRepository)),
+	repository.NewRoleRepository,
+	wire.Bind(new(repository.RoleRepositoryInterface), new(*repository.RoleRepository)),
+	repository.NewTenantRepository,
+	wire.Bind(new(repository.TenantRepositoryInterface), new(*repository.TenantRepository)),
+	repository.NewAuditRepository,
+	wire.Bind(new(repository.AuditRepositoryInterface), new(*repository.AuditRepository)),
 	// 未来可以在这里添加其他 Repository
 	// repository.NewArticleRepository,
 	// repository.NewCommentRepository,
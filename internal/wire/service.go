@@ -1,7 +1,16 @@
 package wire
 
 import (
+	"context"
+	"log/slog"
+	"time"
+
+	"gin_demo/internal/app"
+	"gin_demo/internal/config"
 	"gin_demo/internal/domain/service"
+	"gin_demo/internal/repository"
+	"gin_demo/pkg/audit"
+	"gin_demo/pkg/auth"
 
 	"github.com/google/wire"
 )
@@ -9,7 +18,115 @@ import (
 // ServiceSet Service 层 Provider 集合
 var ServiceSet = wire.NewSet(
 	service.NewUserService,
+	providePasswordService,
+	service.NewMFAService,
+	provideMFAConfig,
+	service.NewAccountService,
+	provideAccountConfig,
+	service.NewAPIKeyService,
+	provideAPIKeyConfig,
+	service.NewOAuthService,
+	service.NewRoleService,
+	providePolicyWatcher,
+	provideTenantService,
+	service.NewAuditService,
+	provideAuditWriter,
+	service.NewPrivacyService,
+	providePrivacyConfig,
+	service.NewUserImportService,
+	provideUserImportConfig,
+	service.NewAvatarService,
+	provideAvatarConfig,
+	wire.Bind(new(audit.Recorder), new(*audit.Writer)),
+	wire.Bind(new(app.AuditWriter), new(*audit.Writer)),
 	// 未来可以在这里添加其他 Service
 	// service.NewArticleService,
 	// service.NewCommentService,
 )
+
+// providePasswordService 提供密码策略服务
+func providePasswordService(
+	cfg *config.Config,
+	policy *auth.PasswordPolicy,
+	hasher auth.PasswordHasher,
+	userRepo repository.UserRepositoryInterface,
+) service.PasswordService {
+	return service.NewPasswordService(policy, hasher, userRepo, cfg.Security.PasswordPolicy.HistorySize)
+}
+
+// provideMFAConfig 提供两步验证服务配置
+func provideMFAConfig(cfg *config.Config) service.MFAConfig {
+	return service.MFAConfig{
+		Issuer:        cfg.Security.MFA.Issuer,
+		RecoveryCodes: cfg.Security.MFA.RecoveryCodes,
+	}
+}
+
+// provideAccountConfig 提供邮箱验证与密码重置服务配置
+func provideAccountConfig(cfg *config.Config) service.AccountConfig {
+	return service.AccountConfig{
+		BaseURL:          cfg.Mail.BaseURL,
+		VerifyEmailTTL:   cfg.Security.AccountTokens.VerifyEmailTTL,
+		PasswordResetTTL: cfg.Security.AccountTokens.PasswordResetTTL,
+		InviteTTL:        cfg.Security.AccountTokens.InviteTTL,
+	}
+}
+
+// provideAPIKeyConfig 提供 API Key 服务配置
+func provideAPIKeyConfig(cfg *config.Config) service.APIKeyConfig {
+	return service.APIKeyConfig{
+		MaxPerUser:       cfg.Security.APIKeys.MaxPerUser,
+		MaxTTL:           cfg.Security.APIKeys.MaxTTL,
+		LastUsedInterval: cfg.Security.APIKeys.LastUsedInterval,
+	}
+}
+
+// provideTenantService 提供租户服务
+func provideTenantService(cfg *config.Config, tenantRepo repository.TenantRepositoryInterface) service.TenantService {
+	return service.NewTenantService(tenantRepo, cfg.Tenant.CacheTTL)
+}
+
+// provideAuditWriter 提供审计日志异步写入器（Service 层记录事件，应用启动和关闭时启停）
+func provideAuditWriter(cfg *config.Config, auditService service.AuditService) *audit.Writer {
+	return audit.NewWriter(auditService, audit.Config{
+		BufferSize:    cfg.Audit.BufferSize,
+		BatchSize:     cfg.Audit.BatchSize,
+		FlushInterval: cfg.Audit.FlushInterval,
+	})
+}
+
+// providePrivacyConfig 提供数据导出与删除服务配置
+func providePrivacyConfig(cfg *config.Config) service.PrivacyConfig {
+	return service.PrivacyConfig{
+		Dir: cfg.Jobs.Dir,
+	}
+}
+
+// provideUserImportConfig 提供用户批量导入导出服务配置（上传文件和错误报告与其他作业文件保存在同一目录）
+func provideUserImportConfig(cfg *config.Config) service.UserImportConfig {
+	return service.UserImportConfig{
+		Dir: cfg.Jobs.Dir,
+	}
+}
+
+// provideAvatarConfig 提供头像服务配置（上传大小不超过请求体大小限制）
+func provideAvatarConfig(cfg *config.Config) service.AvatarConfig {
+	return service.AvatarConfig{
+		MaxSize: cfg.User.AvatarUploadLimit(cfg.Server.MaxRequestBodySize),
+	}
+}
+
+// providePolicyWatcher 提供角色权限策略管理器（启动时加载一次，之后定期加载）
+//
+// 启动时加载失败（如尚未执行迁移）不阻止启动，继续使用内置策略，等待下一次定期加载。
+func providePolicyWatcher(cfg *config.Config, roleService service.RoleService) app.PolicyWatcher {
+	manager := auth.NewPolicyManager(roleService.LoadPolicy, cfg.Security.RBAC.PolicyReloadInterval)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := manager.Reload(ctx); err != nil {
+		slog.Warn("Failed to load policy from database, using built-in policy", "error", err)
+	}
+
+	return manager
+}
@@ -4,7 +4,10 @@ import (
 	"database/sql"
 
 	"gin_demo/internal/app"
+	"gin_demo/internal/config"
 	"gin_demo/internal/task"
+	"gin_demo/pkg/auth"
+	pkgtask "gin_demo/pkg/task"
 	"github.com/google/wire"
 	"github.com/redis/go-redis/v9"
 )
@@ -15,6 +18,7 @@ var TaskSet = wire.NewSet(
 )
 
 // provideTaskManager 提供任务管理器
-func provideTaskManager(db *sql.DB, redis redis.UniversalClient) app.TaskManager {
-	return task.NewManager(redis, db)
+func provideTaskManager(cfg *config.Config, db *sql.DB, redis redis.UniversalClient, keys *auth.KeySet, jobs *pkgtask.JobQueue) app.TaskManager {
+	return task.NewManager(redis, db, keys, cfg.JWT.KeyExpiryWarning, cfg.Audit.Retention, cfg.User.DeletedRetention,
+		jobs, cfg.Jobs.Dir, cfg.Jobs.Retention)
 }
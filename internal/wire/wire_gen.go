@@ -8,8 +8,15 @@ package wire
 
 import (
 	"gin_demo/internal/app"
+	"gin_demo/internal/app/handler/apikey"
+	"gin_demo/internal/app/handler/audit"
+	"gin_demo/internal/app/handler/avatar"
 	"gin_demo/internal/app/handler/health"
+	"gin_demo/internal/app/handler/jwks"
+	"gin_demo/internal/app/handler/privacy"
+	"gin_demo/internal/app/handler/role"
 	"gin_demo/internal/app/handler/user"
+	"gin_demo/internal/app/handler/userimport"
 	"gin_demo/internal/app/middleware"
 	"gin_demo/internal/config"
 	"gin_demo/internal/domain/service"
@@ -27,14 +34,88 @@ func InitApp(cfg *config.Config) (*app.Application, error) {
 	universalClient := provideRedis(cfg)
 	manager := provideCacheManager(universalClient)
 	userRepository := repository.NewUserRepository(db, manager)
-	userService := service.NewUserService(userRepository)
-	jwtManager := provideJWTManager(cfg)
-	handler := user.NewHandler(userService, jwtManager)
+	auditRepository := repository.NewAuditRepository(db, manager)
+	auditService := service.NewAuditService(auditRepository)
+	writer := provideAuditWriter(cfg, auditService)
+	passwordHasher, err := providePasswordHasher(cfg)
+	if err != nil {
+		return nil, err
+	}
+	passwordPolicy, err := providePasswordPolicy(cfg)
+	if err != nil {
+		return nil, err
+	}
+	passwordService := providePasswordService(cfg, passwordPolicy, passwordHasher, userRepository)
+	userService := service.NewUserService(userRepository, passwordHasher, passwordService, writer)
+	keySet, err := provideJWTKeySet(cfg)
+	if err != nil {
+		return nil, err
+	}
+	rbacjwtManager := provideRBACJWTManager(cfg, keySet)
+	refreshTokenManager := provideRefreshTokenManager(cfg, universalClient)
+	loginGuard := provideLoginGuard(cfg, universalClient)
+	mfaRepository := repository.NewMFARepository(db, manager)
+	mfaConfig := provideMFAConfig(cfg)
+	mfaService := service.NewMFAService(mfaRepository, userRepository, mfaConfig)
+	userTokenRepository := repository.NewUserTokenRepository(db, manager)
+	mailer := provideMailer(cfg)
+	oneTimeTokenSigner := provideOneTimeTokenSigner(cfg)
+	accountConfig := provideAccountConfig(cfg)
+	accountService := service.NewAccountService(userRepository, userTokenRepository, mailer, oneTimeTokenSigner, passwordHasher, passwordService, accountConfig)
+	oAuthManager, err := provideOAuthManager(cfg, universalClient)
+	if err != nil {
+		return nil, err
+	}
+	identityRepository := repository.NewIdentityRepository(db, manager)
+	oAuthService := service.NewOAuthService(oAuthManager, identityRepository, userRepository, userService)
+	mfaTokenManager := provideMFATokenManager(cfg, keySet)
+	impersonationTokenManager := provideImpersonationTokenManager(cfg, keySet)
+	cursorCodec := provideCursorCodec(cfg)
+	handler := user.NewHandler(userService, mfaService, accountService, oAuthService, rbacjwtManager, refreshTokenManager, mfaTokenManager, impersonationTokenManager, loginGuard, cursorCodec)
+	jobQueue := provideJobQueue(cfg, universalClient)
+	userImportConfig := provideUserImportConfig(cfg)
+	userImportService, err := service.NewUserImportService(userService, accountService, writer, jobQueue, userImportConfig)
+	if err != nil {
+		return nil, err
+	}
+	userimportHandler := userimport.NewHandler(userImportService)
+	apiKeyRepository := repository.NewAPIKeyRepository(db, manager)
+	apiKeyConfig := provideAPIKeyConfig(cfg)
+	apiKeyService := service.NewAPIKeyService(apiKeyRepository, userRepository, apiKeyConfig)
+	apikeyHandler := apikey.NewHandler(apiKeyService)
 	checker := provideHealthChecker(db, universalClient)
 	healthHandler := health.NewHandler(checker)
-	authMiddleware := middleware.NewAuthMiddleware(jwtManager)
-	handlers := app.NewHandlers(handler, healthHandler, authMiddleware)
-	taskManager := provideTaskManager(db, universalClient)
-	application := app.New(cfg, db, universalClient, handlers, taskManager)
+	jwksHandler := jwks.NewHandler(keySet)
+	roleRepository := repository.NewRoleRepository(db, manager)
+	roleService := service.NewRoleService(roleRepository)
+	roleHandler := role.NewHandler(roleService)
+	auditHandler := audit.NewHandler(auditService)
+	blobStore, err := provideBlobStore(cfg)
+	if err != nil {
+		return nil, err
+	}
+	avatarConfig := provideAvatarConfig(cfg)
+	avatarService := service.NewAvatarService(userService, blobStore, avatarConfig)
+	privacyConfig := providePrivacyConfig(cfg)
+	privacyService, err := service.NewPrivacyService(userRepository, passwordHasher, auditService, apiKeyService, oAuthService, mfaService, avatarService, writer, jobQueue, privacyConfig)
+	if err != nil {
+		return nil, err
+	}
+	privacyHandler := privacy.NewHandler(privacyService)
+	avatarHandler := avatar.NewHandler(avatarService)
+	staticFiles := provideUploadFiles(blobStore)
+	jwtManager := provideJWTManager(cfg, keySet)
+	tokenVersionSource := provideTokenVersionSource(userService)
+	authMiddleware := middleware.NewAuthMiddleware(jwtManager, tokenVersionSource)
+	rbacMiddleware := middleware.NewRBACMiddleware(rbacjwtManager, tokenVersionSource)
+	apiKeyAuthenticator := provideAPIKeyAuthenticator(apiKeyService)
+	apiKeyMiddleware := middleware.NewAPIKeyMiddleware(apiKeyAuthenticator, rbacMiddleware)
+	tenantRepository := repository.NewTenantRepository(db, manager)
+	tenantService := provideTenantService(cfg, tenantRepository)
+	tenantMiddleware := provideTenantMiddleware(cfg, tenantService)
+	handlers := app.NewHandlers(handler, userimportHandler, apikeyHandler, healthHandler, jwksHandler, roleHandler, auditHandler, privacyHandler, avatarHandler, staticFiles, authMiddleware, rbacMiddleware, apiKeyMiddleware, tenantMiddleware)
+	taskManager := provideTaskManager(cfg, db, universalClient, keySet, jobQueue)
+	policyWatcher := providePolicyWatcher(cfg, roleService)
+	application := app.New(cfg, db, universalClient, handlers, taskManager, policyWatcher, writer)
 	return application, nil
 }
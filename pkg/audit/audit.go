@@ -0,0 +1,149 @@
+// Package audit 审计日志
+//
+// 业务代码通过 Recorder 记录“谁在什么时候对什么做了什么”，
+// 操作者、租户和请求信息（IP、Request ID、User-Agent）由中间件写入 context，记录时自动补全。
+// 生产环境使用 Writer 在后台批量写入存储，记录审计事件不增加请求延迟。
+package audit
+
+import (
+	"context"
+	"reflect"
+	"time"
+
+	"gin_demo/pkg/tenant"
+)
+
+// Event 审计事件
+type Event struct {
+	TenantID       int64   // 所属租户（为 0 时使用 context 中的租户）
+	ActorID        int64   // 操作者用户 ID（为 0 时使用 context 中的操作者，仍为 0 表示匿名）
+	ImpersonatorID int64   // 模拟登录时实际操作的管理员 ID
+	Action         string  // 操作（如 user.update）
+	TargetType     string  // 目标类型（如 user）
+	TargetID       int64   // 目标 ID
+	Changes        Changes // 变更内容（可选，不要包含密码等敏感值）
+
+	IP        string
+	RequestID string
+	UserAgent string
+	CreatedAt time.Time
+}
+
+// Change 字段变更
+type Change struct {
+	From any `json:"from,omitempty"`
+	To   any `json:"to,omitempty"`
+}
+
+// Changes 变更内容（字段名 -> 变更）
+type Changes map[string]Change
+
+// Diff 比较修改前后的字段，只保留发生变化的字段（没有变化时返回 nil）
+func Diff(before, after map[string]any) Changes {
+	var changes Changes
+	add := func(key string) {
+		if _, done := changes[key]; done {
+			return
+		}
+		from, to := before[key], after[key]
+		if reflect.DeepEqual(from, to) {
+			return
+		}
+		if changes == nil {
+			changes = make(Changes)
+		}
+		changes[key] = Change{From: from, To: to}
+	}
+
+	for key := range before {
+		add(key)
+	}
+	for key := range after {
+		add(key)
+	}
+	return changes
+}
+
+// Recorder 审计事件记录接口
+type Recorder interface {
+	// Record 记录审计事件（不返回错误：审计失败不影响业务操作）
+	Record(ctx context.Context, event Event)
+}
+
+// NopRecorder 不记录任何事件（用于测试和不需要审计的场景）
+type NopRecorder struct{}
+
+// Record 丢弃事件
+func (NopRecorder) Record(context.Context, Event) {}
+
+// Complete 用 context 中的租户、操作者和请求信息补全事件（已设置的字段保持不变）
+func Complete(ctx context.Context, event Event) Event {
+	if event.TenantID == 0 {
+		event.TenantID = tenant.ID(ctx)
+	}
+	if event.ActorID == 0 {
+		actor := ActorFromContext(ctx)
+		event.ActorID = actor.UserID
+		if event.ImpersonatorID == 0 {
+			event.ImpersonatorID = actor.ImpersonatorID
+		}
+	}
+
+	req := RequestFromContext(ctx)
+	if event.IP == "" {
+		event.IP = req.IP
+	}
+	if event.RequestID == "" {
+		event.RequestID = req.RequestID
+	}
+	if event.UserAgent == "" {
+		event.UserAgent = req.UserAgent
+	}
+	if event.CreatedAt.IsZero() {
+		event.CreatedAt = time.Now()
+	}
+	return event
+}
+
+// ========================================
+// Context
+// ========================================
+
+// RequestInfo 请求信息
+type RequestInfo struct {
+	IP        string
+	RequestID string
+	UserAgent string
+}
+
+// Actor 操作者
+type Actor struct {
+	UserID         int64 // 当前用户（模拟登录时为被模拟的用户）
+	ImpersonatorID int64 // 模拟登录时实际操作的管理员
+}
+
+type requestContextKey struct{}
+
+type actorContextKey struct{}
+
+// WithRequest 将请求信息存入 context（审计中间件调用）
+func WithRequest(ctx context.Context, info RequestInfo) context.Context {
+	return context.WithValue(ctx, requestContextKey{}, info)
+}
+
+// RequestFromContext 从 context 中获取请求信息
+func RequestFromContext(ctx context.Context) RequestInfo {
+	info, _ := ctx.Value(requestContextKey{}).(RequestInfo)
+	return info
+}
+
+// WithActor 将操作者存入 context（认证中间件调用）
+func WithActor(ctx context.Context, actor Actor) context.Context {
+	return context.WithValue(ctx, actorContextKey{}, actor)
+}
+
+// ActorFromContext 从 context 中获取操作者
+func ActorFromContext(ctx context.Context) Actor {
+	actor, _ := ctx.Value(actorContextKey{}).(Actor)
+	return actor
+}
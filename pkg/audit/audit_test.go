@@ -0,0 +1,165 @@
+package audit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"gin_demo/pkg/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// memoryStore 内存存储，记录每批写入的事件
+type memoryStore struct {
+	mu       sync.Mutex
+	batches  [][]Event
+	attempts int
+	err      error
+}
+
+func (s *memoryStore) WriteEvents(_ context.Context, events []Event) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.attempts++
+	if s.err != nil {
+		return s.err
+	}
+	s.batches = append(s.batches, append([]Event(nil), events...))
+	return nil
+}
+
+func (s *memoryStore) events() []Event {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var all []Event
+	for _, batch := range s.batches {
+		all = append(all, batch...)
+	}
+	return all
+}
+
+// TestDiff 测试字段比较
+func TestDiff(t *testing.T) {
+	changes := Diff(
+		map[string]any{"username": "alice", "email": "a@example.com", "avatar": nil},
+		map[string]any{"username": "alice", "email": "b@example.com", "avatar": "x.png"},
+	)
+	assert.Equal(t, Changes{
+		"email":  {From: "a@example.com", To: "b@example.com"},
+		"avatar": {From: nil, To: "x.png"},
+	}, changes)
+
+	assert.Nil(t, Diff(map[string]any{"role": "user"}, map[string]any{"role": "user"}), "没有变化")
+	assert.Equal(t, Changes{"role": {From: "user"}}, Diff(map[string]any{"role": "user"}, nil), "字段被移除")
+}
+
+// TestComplete 测试用 context 补全事件
+func TestComplete(t *testing.T) {
+	ctx := tenant.WithID(context.Background(), 7)
+	ctx = WithActor(ctx, Actor{UserID: 2, ImpersonatorID: 1})
+	ctx = WithRequest(ctx, RequestInfo{IP: "10.0.0.1", RequestID: "req-1", UserAgent: "curl/8"})
+
+	event := Complete(ctx, Event{Action: "user.update", TargetType: "user", TargetID: 2})
+	assert.Equal(t, int64(7), event.TenantID)
+	assert.Equal(t, int64(2), event.ActorID)
+	assert.Equal(t, int64(1), event.ImpersonatorID)
+	assert.Equal(t, "10.0.0.1", event.IP)
+	assert.Equal(t, "req-1", event.RequestID)
+	assert.Equal(t, "curl/8", event.UserAgent)
+	assert.False(t, event.CreatedAt.IsZero())
+
+	// 已设置的字段保持不变
+	event = Complete(ctx, Event{ActorID: 5, Action: "user.register"})
+	assert.Equal(t, int64(5), event.ActorID)
+	assert.Zero(t, event.ImpersonatorID)
+
+	// 没有中间件信息时为匿名操作、默认租户
+	event = Complete(context.Background(), Event{Action: "user.register"})
+	assert.Equal(t, tenant.DefaultID, event.TenantID)
+	assert.Zero(t, event.ActorID)
+}
+
+// TestWriter 测试异步批量写入
+func TestWriter(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("攒够一批后写入", func(t *testing.T) {
+		store := &memoryStore{}
+		w := NewWriter(store, Config{BatchSize: 2, FlushInterval: time.Hour})
+		w.Start()
+		defer w.Stop()
+
+		w.Record(ctx, Event{Action: "a"})
+		w.Record(ctx, Event{Action: "b"})
+		assert.Eventually(t, func() bool { return len(store.events()) == 2 }, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("到达刷新间隔后写入", func(t *testing.T) {
+		store := &memoryStore{}
+		w := NewWriter(store, Config{BatchSize: 100, FlushInterval: 10 * time.Millisecond})
+		w.Start()
+		defer w.Stop()
+
+		w.Record(ctx, Event{Action: "a"})
+		assert.Eventually(t, func() bool { return len(store.events()) == 1 }, time.Second, 5*time.Millisecond)
+	})
+
+	t.Run("停止时写完缓冲区", func(t *testing.T) {
+		store := &memoryStore{}
+		w := NewWriter(store, Config{BatchSize: 2, FlushInterval: time.Hour})
+		for _, action := range []string{"a", "b", "c"} {
+			w.Record(ctx, Event{Action: action})
+		}
+
+		w.Start()
+		w.Start() // 重复调用无效
+		w.Stop()
+		w.Stop() // 重复调用无效
+
+		events := store.events()
+		require.Len(t, events, 3)
+		assert.Equal(t, "c", events[2].Action)
+		assert.Len(t, store.batches, 2)
+	})
+
+	t.Run("缓冲区满时丢弃", func(t *testing.T) {
+		store := &memoryStore{}
+		w := NewWriter(store, Config{BufferSize: 1, FlushInterval: time.Hour})
+		w.Record(ctx, Event{Action: "a"})
+		w.Record(ctx, Event{Action: "b"}) // 不阻塞
+
+		w.Start()
+		w.Stop()
+		events := store.events()
+		require.Len(t, events, 1)
+		assert.Equal(t, "a", events[0].Action)
+	})
+
+	t.Run("写入失败不影响后续事件", func(t *testing.T) {
+		store := &memoryStore{err: errors.New("database down")}
+		w := NewWriter(store, Config{BatchSize: 1, FlushInterval: time.Hour})
+		w.Start()
+		w.Record(ctx, Event{Action: "a"})
+		assert.Eventually(t, func() bool {
+			store.mu.Lock()
+			defer store.mu.Unlock()
+			return store.attempts == 1
+		}, time.Second, 5*time.Millisecond)
+
+		store.mu.Lock()
+		store.err = nil
+		store.mu.Unlock()
+		w.Record(ctx, Event{Action: "b"})
+		w.Stop()
+
+		events := store.events()
+		require.Len(t, events, 1)
+		assert.Equal(t, "b", events[0].Action)
+	})
+}
@@ -0,0 +1,163 @@
+package audit
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"gin_demo/pkg/metrics"
+)
+
+// Store 审计事件存储
+type Store interface {
+	// WriteEvents 批量写入事件
+	WriteEvents(ctx context.Context, events []Event) error
+}
+
+// Config 异步写入配置
+type Config struct {
+	BufferSize    int           // 缓冲区大小（缓冲区满时丢弃新事件，不阻塞请求）
+	BatchSize     int           // 每批最多写入的事件数
+	FlushInterval time.Duration // 缓冲区不满一批时的最长等待时间
+	WriteTimeout  time.Duration // 每批写入的超时时间
+}
+
+// Writer 异步审计写入器
+//
+// Record 只把事件放入缓冲区，后台协程按批写入 Store。
+// Stop 时写完缓冲区中剩余的事件；写入失败的事件记录日志后丢弃，不重试。
+type Writer struct {
+	store  Store
+	config Config
+	events chan Event
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewWriter 创建异步审计写入器（配置为零值时使用默认值）
+func NewWriter(store Store, config Config) *Writer {
+	if config.BufferSize <= 0 {
+		config.BufferSize = 1024
+	}
+	if config.BatchSize <= 0 {
+		config.BatchSize = 100
+	}
+	if config.FlushInterval <= 0 {
+		config.FlushInterval = time.Second
+	}
+	if config.WriteTimeout <= 0 {
+		config.WriteTimeout = 5 * time.Second
+	}
+
+	return &Writer{
+		store:  store,
+		config: config,
+		events: make(chan Event, config.BufferSize),
+	}
+}
+
+// Record 记录审计事件（用 context 补全事件后放入缓冲区，缓冲区满时丢弃）
+func (w *Writer) Record(ctx context.Context, event Event) {
+	event = Complete(ctx, event)
+
+	select {
+	case w.events <- event:
+	default:
+		metrics.RecordAuditEvents("dropped", 1)
+		slog.WarnContext(ctx, "Audit buffer full, event dropped",
+			"action", event.Action,
+			"target_type", event.TargetType,
+			"target_id", event.TargetID,
+			"actor_id", event.ActorID,
+		)
+	}
+}
+
+// Start 启动后台写入（重复调用无效）
+func (w *Writer) Start() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.cancel != nil {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	w.cancel = cancel
+	w.done = make(chan struct{})
+
+	go func() {
+		defer close(w.done)
+		w.run(ctx)
+	}()
+}
+
+// Stop 停止后台写入（写完缓冲区中的事件后返回）
+func (w *Writer) Stop() {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+
+	if w.cancel == nil {
+		return
+	}
+	w.cancel()
+	<-w.done
+	w.cancel = nil
+}
+
+// run 按批写入：攒够一批或到达刷新间隔时写入
+func (w *Writer) run(ctx context.Context) {
+	ticker := time.NewTicker(w.config.FlushInterval)
+	defer ticker.Stop()
+
+	batch := make([]Event, 0, w.config.BatchSize)
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+		w.write(batch)
+		batch = batch[:0]
+	}
+
+	for {
+		select {
+		case event := <-w.events:
+			batch = append(batch, event)
+			if len(batch) >= w.config.BatchSize {
+				flush()
+			}
+		case <-ticker.C:
+			flush()
+		case <-ctx.Done():
+			// 写完缓冲区中剩余的事件
+			for {
+				select {
+				case event := <-w.events:
+					batch = append(batch, event)
+					if len(batch) >= w.config.BatchSize {
+						flush()
+					}
+				default:
+					flush()
+					return
+				}
+			}
+		}
+	}
+}
+
+// write 写入一批事件（使用独立的 context，停止时也能写完）
+func (w *Writer) write(events []Event) {
+	ctx, cancel := context.WithTimeout(context.Background(), w.config.WriteTimeout)
+	defer cancel()
+
+	if err := w.store.WriteEvents(ctx, events); err != nil {
+		metrics.RecordAuditEvents("failed", len(events))
+		slog.Error("Failed to write audit events", "error", err, "count", len(events))
+		return
+	}
+	metrics.RecordAuditEvents("written", len(events))
+}
@@ -0,0 +1,263 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync"
+)
+
+var (
+	// ErrAccessDenied 策略不允许对该资源执行操作
+	ErrAccessDenied = errors.New("access denied")
+	// ErrNoSubject context 中没有当前用户（未经过认证中间件）
+	ErrNoSubject = errors.New("no subject in context")
+)
+
+// Action 对资源执行的操作
+type Action string
+
+const (
+	// ActionRead 读取
+	ActionRead Action = "read"
+	// ActionUpdate 修改
+	ActionUpdate Action = "update"
+	// ActionDelete 删除
+	ActionDelete Action = "delete"
+	// ActionAssignRole 修改角色和权限
+	ActionAssignRole Action = "assign_role"
+	// ActionImpersonate 模拟登录（以该用户身份操作）
+	ActionImpersonate Action = "impersonate"
+	// ActionSuspend 停用、重新启用
+	ActionSuspend Action = "suspend"
+)
+
+const (
+	// ResourceUser 用户资源
+	ResourceUser = "user"
+
+	// AttrRole 资源属性：资源所属用户的角色（Role 类型）
+	AttrRole = "role"
+)
+
+// Resource 被访问的资源及其属性
+type Resource struct {
+	Type       string         // 资源类型（如 ResourceUser）
+	ID         int64          // 资源 ID
+	OwnerID    int64          // 资源所属用户 ID（用户资源为用户自身）
+	Attributes map[string]any // 其他属性（如 AttrRole）
+}
+
+// Attr 获取资源属性
+func (r *Resource) Attr(key string) any {
+	if r == nil || r.Attributes == nil {
+		return nil
+	}
+	return r.Attributes[key]
+}
+
+// Condition 规则条件（主体为当前用户的 Claims）
+type Condition func(subject *RBACClaims, resource *Resource) bool
+
+// Rule 访问规则：对某类资源的某些操作，满足条件即允许
+type Rule struct {
+	Resource  string    // 资源类型
+	Actions   []Action  // 适用的操作
+	Condition Condition // 允许条件（nil 表示无条件允许）
+}
+
+// PolicyEngine 基于属性的访问控制（ABAC）引擎
+//
+// 对同一资源类型和操作，任意一条规则的条件满足即允许；没有匹配的规则时拒绝。
+// 角色和权限判断（RBACClaims.HasPermission 等）仍由 RBAC 策略决定，
+// 规则在此基础上增加对具体资源的约束，如“只能修改自己的数据”“只能管理级别更低的用户”。
+type PolicyEngine struct {
+	mu    sync.RWMutex
+	rules map[string]map[Action][]Condition
+}
+
+// NewPolicyEngine 创建 ABAC 引擎
+func NewPolicyEngine(rules ...Rule) *PolicyEngine {
+	e := &PolicyEngine{rules: make(map[string]map[Action][]Condition)}
+	e.AddRules(rules...)
+	return e
+}
+
+// AddRules 添加规则（已有规则保留，可在启动时为新的资源类型注册规则）
+func (e *PolicyEngine) AddRules(rules ...Rule) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	for _, rule := range rules {
+		condition := rule.Condition
+		if condition == nil {
+			condition = func(*RBACClaims, *Resource) bool { return true }
+		}
+
+		actions := e.rules[rule.Resource]
+		if actions == nil {
+			actions = make(map[Action][]Condition)
+			e.rules[rule.Resource] = actions
+		}
+		for _, action := range rule.Actions {
+			actions[action] = append(actions[action], condition)
+		}
+	}
+}
+
+// Allowed 判断主体能否对资源执行操作
+func (e *PolicyEngine) Allowed(subject *RBACClaims, action Action, resource *Resource) bool {
+	if subject == nil || resource == nil {
+		return false
+	}
+
+	e.mu.RLock()
+	conditions := e.rules[resource.Type][action]
+	e.mu.RUnlock()
+
+	for _, condition := range conditions {
+		if condition(subject, resource) {
+			return true
+		}
+	}
+	return false
+}
+
+// Can 判断 context 中的当前用户能否对资源执行操作
+// 返回 ErrNoSubject（未认证）或 ErrAccessDenied（不允许）
+func (e *PolicyEngine) Can(ctx context.Context, action Action, resource *Resource) error {
+	subject := ClaimsFromContext(ctx)
+	if subject == nil {
+		return ErrNoSubject
+	}
+	if !e.Allowed(subject, action, resource) {
+		return ErrAccessDenied
+	}
+	return nil
+}
+
+// DefaultRules 内置规则
+//
+// 用户资源：
+//   - 读取：本人，或拥有 user:read 权限
+//   - 修改：本人，或拥有 user:write 权限且角色级别高于目标用户
+//   - 删除：拥有 user:delete 权限且角色级别高于目标用户（不能删除自己）
+//   - 修改角色：拥有 user:write 权限且角色级别高于目标用户（不能修改自己的角色）
+//   - 停用、重新启用：拥有 user:write 权限且角色级别高于目标用户（不能停用自己）
+//   - 模拟登录：角色级别高于目标用户（不能模拟自己；路由另外限制为超级管理员）
+func DefaultRules() []Rule {
+	return []Rule{
+		{
+			Resource:  ResourceUser,
+			Actions:   []Action{ActionRead},
+			Condition: AnyOf(IsOwner(), HasPermission(PermissionUserRead)),
+		},
+		{
+			Resource:  ResourceUser,
+			Actions:   []Action{ActionUpdate},
+			Condition: AnyOf(IsOwner(), AllOf(HasPermission(PermissionUserWrite), OutranksOwner())),
+		},
+		{
+			Resource:  ResourceUser,
+			Actions:   []Action{ActionDelete},
+			Condition: AllOf(HasPermission(PermissionUserDelete), OutranksOwner()),
+		},
+		{
+			Resource:  ResourceUser,
+			Actions:   []Action{ActionAssignRole, ActionSuspend},
+			Condition: AllOf(HasPermission(PermissionUserWrite), OutranksOwner()),
+		},
+		{
+			Resource:  ResourceUser,
+			Actions:   []Action{ActionImpersonate},
+			Condition: OutranksOwner(),
+		},
+	}
+}
+
+// defaultEngine 全局 ABAC 引擎（包含内置规则，供 Can 和 RequirePolicy 使用）
+var defaultEngine = NewPolicyEngine(DefaultRules()...)
+
+// DefaultPolicyEngine 全局 ABAC 引擎
+func DefaultPolicyEngine() *PolicyEngine {
+	return defaultEngine
+}
+
+// Can 使用全局 ABAC 引擎判断 context 中的当前用户能否对资源执行操作
+//
+// 供 Service 层在加载资源后做资源级校验：
+//
+//	if err := auth.Can(ctx, auth.ActionUpdate, resource); err != nil { ... }
+func Can(ctx context.Context, action Action, resource *Resource) error {
+	return defaultEngine.Can(ctx, action, resource)
+}
+
+// ========================================
+// 条件
+// ========================================
+
+// IsOwner 主体是资源所属用户
+func IsOwner() Condition {
+	return func(subject *RBACClaims, resource *Resource) bool {
+		return resource.OwnerID != 0 && subject.UserID == resource.OwnerID
+	}
+}
+
+// HasPermission 主体拥有指定权限（受 API Key 权限范围限制）
+func HasPermission(permission Permission) Condition {
+	return func(subject *RBACClaims, _ *Resource) bool {
+		return subject.HasPermission(permission)
+	}
+}
+
+// OutranksOwner 主体的角色级别高于资源所属用户（资源属性 AttrRole）
+// 缺少角色属性时不满足；同级（包括两个超级管理员之间）不满足
+func OutranksOwner() Condition {
+	return func(subject *RBACClaims, resource *Resource) bool {
+		role, ok := resource.Attr(AttrRole).(Role)
+		if !ok {
+			return false
+		}
+		return subject.HasHigherRoleThan(role)
+	}
+}
+
+// AllOf 所有条件都满足
+func AllOf(conditions ...Condition) Condition {
+	return func(subject *RBACClaims, resource *Resource) bool {
+		for _, condition := range conditions {
+			if !condition(subject, resource) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// AnyOf 任意条件满足
+func AnyOf(conditions ...Condition) Condition {
+	return func(subject *RBACClaims, resource *Resource) bool {
+		for _, condition := range conditions {
+			if condition(subject, resource) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ========================================
+// Context
+// ========================================
+
+type claimsContextKey struct{}
+
+// WithClaims 将当前用户的 Claims 存入 context（认证中间件调用，供 Can 使用）
+func WithClaims(ctx context.Context, claims *RBACClaims) context.Context {
+	return context.WithValue(ctx, claimsContextKey{}, claims)
+}
+
+// ClaimsFromContext 从 context 中获取当前用户的 Claims
+func ClaimsFromContext(ctx context.Context) *RBACClaims {
+	claims, _ := ctx.Value(claimsContextKey{}).(*RBACClaims)
+	return claims
+}
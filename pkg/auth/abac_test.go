@@ -0,0 +1,98 @@
+package auth
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// userResource 构造用户资源
+func userResource(id int64, role Role) *Resource {
+	return &Resource{Type: ResourceUser, ID: id, OwnerID: id, Attributes: map[string]any{AttrRole: role}}
+}
+
+// TestPolicyEngine_DefaultRules 测试内置的用户资源规则
+func TestPolicyEngine_DefaultRules(t *testing.T) {
+	engine := NewPolicyEngine(DefaultRules()...)
+
+	user := &RBACClaims{UserID: 1, Role: RoleUser}
+	admin := &RBACClaims{UserID: 2, Role: RoleAdmin}
+	superAdmin := &RBACClaims{UserID: 3, Role: RoleSuperAdmin}
+
+	cases := []struct {
+		name     string
+		subject  *RBACClaims
+		action   Action
+		resource *Resource
+		want     bool
+	}{
+		{"普通用户修改自己", user, ActionUpdate, userResource(1, RoleUser), true},
+		{"普通用户修改他人", user, ActionUpdate, userResource(9, RoleUser), false},
+		{"普通用户读取他人", user, ActionRead, userResource(9, RoleUser), true},
+		{"管理员修改普通用户", admin, ActionUpdate, userResource(9, RoleUser), true},
+		{"管理员修改其他管理员", admin, ActionUpdate, userResource(9, RoleAdmin), false},
+		{"管理员修改超级管理员", admin, ActionUpdate, userResource(3, RoleSuperAdmin), false},
+		{"管理员删除普通用户", admin, ActionDelete, userResource(9, RoleUser), true},
+		{"超级管理员删除管理员", superAdmin, ActionDelete, userResource(2, RoleAdmin), true},
+		{"超级管理员删除自己", superAdmin, ActionDelete, userResource(3, RoleSuperAdmin), false},
+		{"超级管理员修改自己的角色", superAdmin, ActionAssignRole, userResource(3, RoleSuperAdmin), false},
+		{"管理员停用普通用户", admin, ActionSuspend, userResource(9, RoleUser), true},
+		{"管理员停用其他管理员", admin, ActionSuspend, userResource(9, RoleAdmin), false},
+		{"普通用户停用自己", user, ActionSuspend, userResource(1, RoleUser), false},
+		{"超级管理员模拟管理员", superAdmin, ActionImpersonate, userResource(2, RoleAdmin), true},
+		{"超级管理员模拟自己", superAdmin, ActionImpersonate, userResource(3, RoleSuperAdmin), false},
+		{"缺少角色属性", superAdmin, ActionDelete, &Resource{Type: ResourceUser, ID: 9, OwnerID: 9}, false},
+		{"未注册的资源类型", superAdmin, ActionRead, &Resource{Type: "order", ID: 1}, false},
+		{"未注册的操作", superAdmin, Action("export"), userResource(9, RoleUser), false},
+	}
+	for _, tc := range cases {
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.want, engine.Allowed(tc.subject, tc.action, tc.resource))
+		})
+	}
+
+	t.Run("API Key 权限范围", func(t *testing.T) {
+		key := &RBACClaims{UserID: 2, Role: RoleAdmin, Scopes: []Permission{PermissionUserRead}}
+		assert.True(t, engine.Allowed(key, ActionRead, userResource(9, RoleUser)))
+		assert.False(t, engine.Allowed(key, ActionUpdate, userResource(9, RoleUser)))
+	})
+}
+
+// TestPolicyEngine_AddRules 测试为新的资源类型注册规则
+func TestPolicyEngine_AddRules(t *testing.T) {
+	engine := NewPolicyEngine()
+	engine.AddRules(Rule{
+		Resource: "article",
+		Actions:  []Action{ActionUpdate, ActionDelete},
+		Condition: AnyOf(
+			IsOwner(),
+			HasPermission(PermissionContentDelete),
+		),
+	}, Rule{Resource: "article", Actions: []Action{ActionRead}})
+
+	article := &Resource{Type: "article", ID: 5, OwnerID: 1}
+	author := &RBACClaims{UserID: 1, Role: RoleUser}
+	other := &RBACClaims{UserID: 2, Role: RoleUser}
+	moderator := &RBACClaims{UserID: 3, Role: RoleAdmin}
+
+	assert.True(t, engine.Allowed(author, ActionDelete, article))
+	assert.False(t, engine.Allowed(other, ActionDelete, article))
+	assert.True(t, engine.Allowed(moderator, ActionUpdate, article))
+	assert.True(t, engine.Allowed(other, ActionRead, article), "无条件规则")
+	assert.False(t, engine.Allowed(nil, ActionRead, article))
+}
+
+// TestCan 测试从 context 中获取当前用户判断
+func TestCan(t *testing.T) {
+	target := userResource(9, RoleUser)
+
+	assert.ErrorIs(t, Can(context.Background(), ActionUpdate, target), ErrNoSubject)
+
+	ctx := WithClaims(context.Background(), &RBACClaims{UserID: 1, Role: RoleUser})
+	assert.ErrorIs(t, Can(ctx, ActionUpdate, target), ErrAccessDenied)
+	assert.NoError(t, Can(ctx, ActionUpdate, userResource(1, RoleUser)))
+
+	ctx = WithClaims(context.Background(), &RBACClaims{UserID: 2, Role: RoleAdmin})
+	assert.NoError(t, Can(ctx, ActionUpdate, target))
+}
@@ -0,0 +1,63 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// APIKeyPrefix API Key 的固定前缀（便于密钥扫描工具识别泄露的 Key）
+const APIKeyPrefix = "gdk"
+
+// ErrAPIKeyMalformed API Key 格式错误
+var ErrAPIKeyMalformed = errors.New("malformed api key")
+
+// GeneratedAPIKey 新生成的 API Key
+type GeneratedAPIKey struct {
+	Key        string // 完整 Key（只在创建时返回给用户一次）
+	Prefix     string // 公开标识（用于查找和展示）
+	SecretHash string // 密钥部分的 SHA-256 哈希（保存到存储）
+}
+
+// GenerateAPIKey 生成 API Key
+//
+// 格式为 "gdk_<prefix>_<secret>"：prefix 是 12 位十六进制的公开标识，
+// secret 是 32 字节随机值。secret 熵足够高，直接保存 SHA-256 哈希即可，不需要慢哈希。
+func GenerateAPIKey() (GeneratedAPIKey, error) {
+	b := make([]byte, 6)
+	if _, err := rand.Read(b); err != nil {
+		return GeneratedAPIKey{}, fmt.Errorf("failed to generate api key prefix: %w", err)
+	}
+	prefix := hex.EncodeToString(b)
+
+	secret, err := randomString(32)
+	if err != nil {
+		return GeneratedAPIKey{}, fmt.Errorf("failed to generate api key secret: %w", err)
+	}
+
+	return GeneratedAPIKey{
+		Key:        APIKeyPrefix + "_" + prefix + "_" + secret,
+		Prefix:     prefix,
+		SecretHash: hashToken(secret),
+	}, nil
+}
+
+// ParseAPIKey 解析 API Key，返回公开标识和密钥部分的哈希
+func ParseAPIKey(key string) (prefix, secretHash string, err error) {
+	parts := strings.SplitN(key, "_", 3)
+	if len(parts) != 3 || parts[0] != APIKeyPrefix || len(parts[1]) != 12 || parts[2] == "" {
+		return "", "", ErrAPIKeyMalformed
+	}
+	if _, err := hex.DecodeString(parts[1]); err != nil {
+		return "", "", ErrAPIKeyMalformed
+	}
+	return parts[1], hashToken(parts[2]), nil
+}
+
+// APIKeySecretMatches 常量时间比较密钥哈希
+func APIKeySecretMatches(secretHash, storedHash string) bool {
+	return subtle.ConstantTimeCompare([]byte(secretHash), []byte(storedHash)) == 1
+}
@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestGenerateAPIKey 测试 API Key 生成与解析
+func TestGenerateAPIKey(t *testing.T) {
+	t.Run("生成后解析", func(t *testing.T) {
+		key, err := GenerateAPIKey()
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(key.Key, "gdk_"+key.Prefix+"_"))
+		assert.NotContains(t, key.SecretHash, key.Key)
+
+		prefix, secretHash, err := ParseAPIKey(key.Key)
+		require.NoError(t, err)
+		assert.Equal(t, key.Prefix, prefix)
+		assert.True(t, APIKeySecretMatches(secretHash, key.SecretHash))
+	})
+
+	t.Run("每次生成不同", func(t *testing.T) {
+		a, err := GenerateAPIKey()
+		require.NoError(t, err)
+		b, err := GenerateAPIKey()
+		require.NoError(t, err)
+		assert.NotEqual(t, a.Prefix, b.Prefix)
+		assert.NotEqual(t, a.SecretHash, b.SecretHash)
+	})
+
+	t.Run("密钥部分被篡改", func(t *testing.T) {
+		key, err := GenerateAPIKey()
+		require.NoError(t, err)
+
+		_, secretHash, err := ParseAPIKey(key.Key + "x")
+		require.NoError(t, err)
+		assert.False(t, APIKeySecretMatches(secretHash, key.SecretHash))
+	})
+
+	t.Run("格式错误", func(t *testing.T) {
+		for _, key := range []string{
+			"",
+			"gdk_",
+			"gdk_0123456789ab_",
+			"xyz_0123456789ab_secret",
+			"gdk_0123_secret",
+			"gdk_zzzzzzzzzzzz_secret",
+			"Bearer eyJhbGciOiJIUzI1NiJ9",
+		} {
+			_, _, err := ParseAPIKey(key)
+			assert.ErrorIs(t, err, ErrAPIKeyMalformed, key)
+		}
+	})
+}
+
+// TestRBACClaims_Scopes 测试权限范围限制
+func TestRBACClaims_Scopes(t *testing.T) {
+	t.Run("范围外的权限被拒绝（即使角色拥有）", func(t *testing.T) {
+		claims := &RBACClaims{Role: RoleSuperAdmin, Scopes: []Permission{PermissionUserRead}}
+		assert.True(t, claims.HasPermission(PermissionUserRead))
+		assert.False(t, claims.HasPermission(PermissionUserDelete))
+		assert.False(t, claims.HasAllPermissions(PermissionUserRead, PermissionUserWrite))
+	})
+
+	t.Run("范围内的权限仍然需要角色拥有", func(t *testing.T) {
+		claims := &RBACClaims{Role: RoleUser, Scopes: []Permission{PermissionUserRead, PermissionUserDelete}}
+		assert.True(t, claims.HasPermission(PermissionUserRead))
+		assert.False(t, claims.HasPermission(PermissionUserDelete))
+	})
+
+	t.Run("没有范围时不受限制", func(t *testing.T) {
+		claims := &RBACClaims{Role: RoleAdmin}
+		assert.True(t, claims.HasPermission(PermissionUserDelete))
+	})
+}
@@ -0,0 +1,149 @@
+package auth
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// BreachedPasswordSource 泄露密码数据源（k-匿名查询）
+//
+// 调用方只提交密码 SHA-1 的前 5 位（大写十六进制），数据源返回该前缀下的所有哈希后缀及出现次数，
+// 由调用方在本地比对，数据源无法得知被查询的密码。
+type BreachedPasswordSource interface {
+	// Range 返回前缀下的哈希后缀（35 位大写十六进制）及出现次数
+	Range(ctx context.Context, prefix string) (map[string]int, error)
+}
+
+// BreachedPasswordFile 本地泄露密码文件
+//
+// 文件格式与 Have I Been Pwned 的 Pwned Passwords（SHA-1，按哈希排序）一致，每行一条：
+//
+//	<40 位大写十六进制 SHA-1>:<出现次数>
+//
+// 出现次数可以省略（视为 1）。文件按哈希升序排列，查询时按前缀二分查找，不需要预先加载到内存。
+type BreachedPasswordFile struct {
+	file *os.File
+	size int64
+}
+
+// OpenBreachedPasswordFile 打开本地泄露密码文件
+func OpenBreachedPasswordFile(path string) (*BreachedPasswordFile, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open breached password file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, fmt.Errorf("failed to stat breached password file: %w", err)
+	}
+	return &BreachedPasswordFile{file: file, size: info.Size()}, nil
+}
+
+// Close 关闭文件
+func (f *BreachedPasswordFile) Close() error {
+	return f.file.Close()
+}
+
+// Range 返回前缀下的哈希后缀及出现次数
+func (f *BreachedPasswordFile) Range(ctx context.Context, prefix string) (map[string]int, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	prefix = strings.ToUpper(prefix)
+
+	// 二分查找第一行哈希前缀 >= prefix 的位置
+	lo, hi := int64(0), f.size
+	for lo < hi {
+		mid := lo + (hi-lo)/2
+		_, line, err := f.lineAt(mid)
+		if err != nil {
+			return nil, err
+		}
+		if line != "" && line[:min(len(line), len(prefix))] < prefix {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+
+	start, _, err := f.lineAt(lo)
+	if err != nil {
+		return nil, err
+	}
+
+	suffixes := make(map[string]int)
+	scanner := bufio.NewScanner(io.NewSectionReader(f.file, start, f.size-start))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if !strings.HasPrefix(line, prefix) {
+			break
+		}
+		hash, countText, _ := strings.Cut(line, ":")
+		count := 1
+		if countText != "" {
+			if count, err = strconv.Atoi(countText); err != nil {
+				return nil, fmt.Errorf("invalid breached password line %q", line)
+			}
+		}
+		suffixes[hash[len(prefix):]] = count
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read breached password file: %w", err)
+	}
+	return suffixes, nil
+}
+
+// lineAt 返回从 offset 开始（含）的第一个完整行的起始位置和内容（到达文件末尾时内容为空）
+func (f *BreachedPasswordFile) lineAt(offset int64) (int64, string, error) {
+	// 不在行首时跳到下一行
+	start := offset
+	if offset > 0 {
+		pos, err := f.indexNewline(offset - 1)
+		if err != nil {
+			return 0, "", err
+		}
+		if pos < 0 {
+			return f.size, "", nil
+		}
+		start = pos + 1
+	}
+
+	end, err := f.indexNewline(start)
+	if err != nil {
+		return 0, "", err
+	}
+	if end < 0 {
+		end = f.size
+	}
+	buf := make([]byte, end-start)
+	if _, err := f.file.ReadAt(buf, start); err != nil && err != io.EOF {
+		return 0, "", fmt.Errorf("failed to read breached password file: %w", err)
+	}
+	return start, strings.TrimSpace(string(buf)), nil
+}
+
+// indexNewline 返回从 offset 开始的第一个换行符的位置（没有时返回 -1）
+func (f *BreachedPasswordFile) indexNewline(offset int64) (int64, error) {
+	buf := make([]byte, 128)
+	for offset < f.size {
+		n, err := f.file.ReadAt(buf, offset)
+		if i := bytes.IndexByte(buf[:n], '\n'); i >= 0 {
+			return offset + int64(i), nil
+		}
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return 0, fmt.Errorf("failed to read breached password file: %w", err)
+		}
+		offset += int64(n)
+	}
+	return -1, nil
+}
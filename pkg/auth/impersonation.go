@@ -0,0 +1,42 @@
+package auth
+
+import (
+	"fmt"
+	"time"
+)
+
+// ImpersonationTokenManager 模拟登录令牌管理器
+//
+// 模拟登录令牌是普通的访问令牌（RBACClaims），UserID、角色和权限为被模拟的用户，
+// ActorID 为实际操作的管理员。有效期通常短于普通访问令牌，且不签发 Refresh Token。
+type ImpersonationTokenManager struct {
+	tokens *RBACJWTManager
+}
+
+// NewImpersonationTokenManager 创建模拟登录令牌管理器（与访问令牌共用密钥集）
+func NewImpersonationTokenManager(keys *KeySet, expiration time.Duration) *ImpersonationTokenManager {
+	return &ImpersonationTokenManager{
+		tokens: NewRBACJWTManagerWithKeys(keys, expiration),
+	}
+}
+
+// Expiration 令牌有效期
+func (m *ImpersonationTokenManager) Expiration() time.Duration {
+	return m.tokens.expiration
+}
+
+// GenerateToken 签发模拟登录令牌（actorID 不能为空，也不能是被模拟的用户本人）
+func (m *ImpersonationTokenManager) GenerateToken(actorID, tenantID, userID, version int64, role Role, permissions ...Permission) (string, error) {
+	if actorID == 0 || actorID == userID {
+		return "", fmt.Errorf("invalid impersonation actor: %d", actorID)
+	}
+
+	return m.tokens.generate(RBACClaims{
+		UserID:      userID,
+		Role:        role,
+		Permissions: permissions,
+		Version:     version,
+		TenantID:    tenantID,
+		ActorID:     actorID,
+	}, m.tokens.expiration)
+}
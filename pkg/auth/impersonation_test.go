@@ -0,0 +1,73 @@
+package auth
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestImpersonationTokenManager 测试模拟登录令牌
+func TestImpersonationTokenManager(t *testing.T) {
+	keys := NewHMACKeySet("test-secret")
+	manager := NewImpersonationTokenManager(keys, 10*time.Minute)
+	rbac := NewRBACJWTManagerWithKeys(keys, time.Hour)
+
+	token, err := manager.GenerateToken(1, 2, 42, 3, RoleUser, PermissionContentAudit)
+	require.NoError(t, err)
+
+	t.Run("作为访问令牌使用", func(t *testing.T) {
+		claims, err := rbac.ValidateToken(token)
+		require.NoError(t, err)
+		assert.True(t, claims.IsImpersonated())
+		assert.Equal(t, int64(42), claims.UserID)
+		assert.Equal(t, int64(1), claims.ActorID)
+		assert.Equal(t, int64(2), claims.TenantID)
+		assert.Equal(t, RoleUser, claims.Role)
+		assert.WithinDuration(t, time.Now().Add(10*time.Minute), claims.ExpiresAt.Time, 5*time.Second)
+
+		// 基础认证同样可以识别
+		basic, err := NewDefaultJWTManagerWithKeys(keys, time.Hour).ValidateToken(token)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1), basic.ActorID)
+	})
+
+	t.Run("不能续期", func(t *testing.T) {
+		_, err := rbac.RefreshToken(token)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+
+	t.Run("不能模拟自己", func(t *testing.T) {
+		_, err := manager.GenerateToken(42, 2, 42, 3, RoleUser)
+		assert.Error(t, err)
+		_, err = manager.GenerateToken(0, 2, 42, 3, RoleUser)
+		assert.Error(t, err)
+	})
+
+	t.Run("普通令牌", func(t *testing.T) {
+		normal, err := rbac.GenerateToken(42, RoleUser)
+		require.NoError(t, err)
+		claims, err := rbac.ValidateToken(normal)
+		require.NoError(t, err)
+		assert.False(t, claims.IsImpersonated())
+	})
+
+	t.Run("过期的普通令牌可以续期", func(t *testing.T) {
+		expired, err := NewRBACJWTManagerWithKeys(keys, -time.Minute).GenerateTenantToken(2, 42, 3, RoleUser)
+		require.NoError(t, err)
+		_, err = rbac.ValidateToken(expired)
+		require.ErrorIs(t, err, ErrExpiredToken)
+
+		refreshed, err := rbac.RefreshToken(expired)
+		require.NoError(t, err)
+		claims, err := rbac.ValidateToken(refreshed)
+		require.NoError(t, err)
+		assert.Equal(t, int64(42), claims.UserID)
+		assert.Equal(t, int64(2), claims.TenantID)
+		assert.Equal(t, int64(3), claims.Version)
+
+		_, err = rbac.RefreshToken(expired + "x")
+		assert.ErrorIs(t, err, ErrInvalidToken, "签名无效")
+	})
+}
@@ -17,7 +17,10 @@ var (
 
 // Claims JWT 声明（泛型版本，支持不同类型的 UserID）
 type Claims[T any] struct {
-	UserID T `json:"user_id"`
+	UserID   T     `json:"user_id"`
+	Version  int64 `json:"ver,omitempty"`      // Token 版本号（服务端递增后旧 Token 失效）
+	TenantID int64 `json:"tid,omitempty"`      // 所属租户（与 RBACClaims 一致，0 表示默认租户）
+	ActorID  int64 `json:"actor_id,omitempty"` // 实际操作的管理员 ID（与 RBACClaims 一致，模拟登录时设置）
 	jwt.RegisteredClaims
 }
 
@@ -26,48 +29,54 @@ type DefaultClaims = Claims[int64]
 
 // JWTManager JWT 管理器（泛型版本）
 type JWTManager[T any] struct {
-	secret     string
+	keys       *KeySet
 	expiration time.Duration
 }
 
-// NewJWTManager 创建 JWT 管理器
+// NewJWTManager 创建 JWT 管理器（HS256 单密钥）
 func NewJWTManager[T any](secret string, expiration time.Duration) *JWTManager[T] {
+	return NewJWTManagerWithKeys[T](NewHMACKeySet(secret), expiration)
+}
+
+// NewJWTManagerWithKeys 使用密钥集创建 JWT 管理器（支持非对称算法和多个验证密钥）
+func NewJWTManagerWithKeys[T any](keys *KeySet, expiration time.Duration) *JWTManager[T] {
 	return &JWTManager[T]{
-		secret:     secret,
+		keys:       keys,
 		expiration: expiration,
 	}
 }
 
 // GenerateToken 生成 JWT Token
 func (m *JWTManager[T]) GenerateToken(userID T) (string, error) {
+	return m.GenerateTokenWithVersion(userID, 0)
+}
+
+// GenerateTokenWithVersion 生成携带 Token 版本号的 JWT Token
+func (m *JWTManager[T]) GenerateTokenWithVersion(userID T, version int64) (string, error) {
+	jti, err := randomString(16)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate token id: %w", err)
+	}
+
 	now := time.Now()
 	claims := Claims[T]{
-		UserID: userID,
+		UserID:  userID,
+		Version: version,
 		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
 			ExpiresAt: jwt.NewNumericDate(now.Add(m.expiration)),
 			IssuedAt:  jwt.NewNumericDate(now),
 			NotBefore: jwt.NewNumericDate(now),
 		},
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(m.secret))
-	if err != nil {
-		return "", fmt.Errorf("failed to sign token: %w", err)
-	}
-
-	return tokenString, nil
+	return m.keys.sign(claims)
 }
 
 // ValidateToken 验证 JWT Token
 func (m *JWTManager[T]) ValidateToken(tokenString string) (*Claims[T], error) {
-	token, err := jwt.ParseWithClaims(tokenString, &Claims[T]{}, func(token *jwt.Token) (interface{}, error) {
-		// 验证签名方法
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(m.secret), nil
-	})
+	// 按 kid 选择验证密钥，并校验签名方法
+	token, err := m.keys.parse(tokenString, &Claims[T]{})
 
 	if err != nil {
 		if errors.Is(err, jwt.ErrTokenExpired) {
@@ -84,17 +93,6 @@ func (m *JWTManager[T]) ValidateToken(tokenString string) (*Claims[T], error) {
 	return claims, nil
 }
 
-// RefreshToken 刷新 Token（验证旧 Token 并生成新 Token）
-func (m *JWTManager[T]) RefreshToken(tokenString string) (string, error) {
-	claims, err := m.ValidateToken(tokenString)
-	if err != nil && !errors.Is(err, ErrExpiredToken) {
-		return "", err
-	}
-
-	// 即使 Token 过期，只要签名有效，就允许刷新
-	return m.GenerateToken(claims.UserID)
-}
-
 // DefaultJWTManager 默认的 JWT 管理器（UserID 为 int64）
 type DefaultJWTManager = JWTManager[int64]
 
@@ -102,3 +100,8 @@ type DefaultJWTManager = JWTManager[int64]
 func NewDefaultJWTManager(secret string, expiration time.Duration) *DefaultJWTManager {
 	return NewJWTManager[int64](secret, expiration)
 }
+
+// NewDefaultJWTManagerWithKeys 使用密钥集创建默认的 JWT 管理器（UserID 为 int64）
+func NewDefaultJWTManagerWithKeys(keys *KeySet, expiration time.Duration) *DefaultJWTManager {
+	return NewJWTManagerWithKeys[int64](keys, expiration)
+}
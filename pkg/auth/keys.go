@@ -0,0 +1,554 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+var (
+	// ErrUnknownKeyID Token 中的 kid 不在密钥集中
+	ErrUnknownKeyID = errors.New("unknown key id")
+	// ErrUnsupportedAlgorithm 不支持的签名算法
+	ErrUnsupportedAlgorithm = errors.New("unsupported signing algorithm")
+	// ErrKeyExpired 验证密钥已超过有效期（已退役）
+	ErrKeyExpired = errors.New("key expired")
+)
+
+// Key JWT 密钥（签名密钥同时包含私钥和公钥，验证密钥只包含公钥）
+type Key struct {
+	ID       string            // 密钥 ID（写入 Token 的 kid 头）
+	Method   jwt.SigningMethod // 签名算法
+	NotAfter time.Time         // 有效期截止时间（零值表示永不过期）
+
+	signKey   any // HMAC: []byte；RSA/ECDSA/EdDSA: 私钥（nil 表示仅用于验证）
+	verifyKey any // HMAC: []byte；RSA/ECDSA/EdDSA: 公钥
+}
+
+// NewHMACKey 创建 HS256 对称密钥（签名和验证使用同一个密钥）
+func NewHMACKey(kid string, secret []byte) *Key {
+	return &Key{
+		ID:        kid,
+		Method:    jwt.SigningMethodHS256,
+		signKey:   secret,
+		verifyKey: secret,
+	}
+}
+
+// NewSigningKey 使用私钥创建签名密钥（公钥从私钥推导）
+//
+// 支持的算法: RS256/RS384/RS512、PS256/PS384/PS512、ES256/ES384/ES512、EdDSA
+func NewSigningKey(kid, alg string, private crypto.Signer) (*Key, error) {
+	method, err := signingMethod(alg)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &Key{
+		ID:        kid,
+		Method:    method,
+		signKey:   private,
+		verifyKey: private.Public(),
+	}
+	if err := key.checkKeyType(); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// NewVerificationKey 使用公钥创建仅用于验证的密钥
+func NewVerificationKey(kid, alg string, public crypto.PublicKey) (*Key, error) {
+	method, err := signingMethod(alg)
+	if err != nil {
+		return nil, err
+	}
+
+	key := &Key{
+		ID:        kid,
+		Method:    method,
+		verifyKey: public,
+	}
+	if err := key.checkKeyType(); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// ParseSigningKeyPEM 从 PEM 数据解析签名密钥（支持 PKCS#1、PKCS#8 和 SEC 1 格式私钥）
+func ParseSigningKeyPEM(kid, alg string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("auth: no PEM block found in private key")
+	}
+
+	var (
+		private any
+		err     error
+	)
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		private, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	case "EC PRIVATE KEY":
+		private, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		private, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("auth: unsupported private key PEM type %q", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("auth: parse private key: %w", err)
+	}
+
+	signer, ok := private.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("auth: private key of type %T cannot sign", private)
+	}
+	return NewSigningKey(kid, alg, signer)
+}
+
+// ParseVerificationKeyPEM 从 PEM 数据解析验证密钥（支持 PKIX、PKCS#1 公钥和 X.509 证书）
+func ParseVerificationKeyPEM(kid, alg string, data []byte) (*Key, error) {
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("auth: no PEM block found in public key")
+	}
+
+	var (
+		public any
+		err    error
+	)
+	switch block.Type {
+	case "PUBLIC KEY":
+		public, err = x509.ParsePKIXPublicKey(block.Bytes)
+	case "RSA PUBLIC KEY":
+		public, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	case "CERTIFICATE":
+		var cert *x509.Certificate
+		cert, err = x509.ParseCertificate(block.Bytes)
+		if err == nil {
+			public = cert.PublicKey
+		}
+	default:
+		return nil, fmt.Errorf("auth: unsupported public key PEM type %q", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("auth: parse public key: %w", err)
+	}
+
+	return NewVerificationKey(kid, alg, public)
+}
+
+// LoadSigningKeyFile 从 PEM 文件加载签名密钥
+func LoadSigningKeyFile(kid, alg, path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("auth: read private key file: %w", err)
+	}
+	return ParseSigningKeyPEM(kid, alg, data)
+}
+
+// LoadVerificationKeyFile 从 PEM 文件加载验证密钥
+func LoadVerificationKeyFile(kid, alg, path string) (*Key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("auth: read public key file: %w", err)
+	}
+	return ParseVerificationKeyPEM(kid, alg, data)
+}
+
+// Expired 在 now 时刻是否已过期
+func (k *Key) Expired(now time.Time) bool {
+	return !k.NotAfter.IsZero() && !now.Before(k.NotAfter)
+}
+
+// CanSign 是否可用于签名
+func (k *Key) CanSign() bool {
+	return k.signKey != nil
+}
+
+// IsSymmetric 是否是对称密钥（对称密钥不会出现在 JWKS 中）
+func (k *Key) IsSymmetric() bool {
+	_, ok := k.Method.(*jwt.SigningMethodHMAC)
+	return ok
+}
+
+// PublicKey 验证用公钥（对称密钥返回 nil）
+func (k *Key) PublicKey() crypto.PublicKey {
+	if k.IsSymmetric() {
+		return nil
+	}
+	return k.verifyKey
+}
+
+// checkKeyType 检查密钥类型与签名算法是否匹配
+func (k *Key) checkKeyType() error {
+	ok := false
+	switch m := k.Method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS:
+		_, ok = k.verifyKey.(*rsa.PublicKey)
+	case *jwt.SigningMethodECDSA:
+		var pub *ecdsa.PublicKey
+		pub, ok = k.verifyKey.(*ecdsa.PublicKey)
+		ok = ok && pub.Curve.Params().BitSize == m.CurveBits
+	case *jwt.SigningMethodEd25519:
+		_, ok = k.verifyKey.(ed25519.PublicKey)
+	}
+	if !ok {
+		return fmt.Errorf("auth: key of type %T does not match algorithm %s", k.verifyKey, k.Method.Alg())
+	}
+	return nil
+}
+
+// signingMethod 获取非对称签名算法
+func signingMethod(alg string) (jwt.SigningMethod, error) {
+	method := jwt.GetSigningMethod(alg)
+	switch method.(type) {
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS, *jwt.SigningMethodECDSA, *jwt.SigningMethodEd25519:
+		return method, nil
+	default:
+		return nil, fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, alg)
+	}
+}
+
+// ============================================================================
+// 密钥集
+// ============================================================================
+
+// KeySet JWT 密钥集（一个当前签名密钥 + 若干仅用于验证的旧密钥）
+//
+// 签发的 Token 带有 kid 头，验证时按 kid 选择密钥；
+// 密钥轮换期间保留旧密钥，已签发的 Token 在过期前仍可验证。
+// 旧密钥超过 NotAfter 后立即拒绝验证，并可通过 Retire 从密钥集中移除。
+type KeySet struct {
+	mu      sync.RWMutex
+	signing *Key
+	keys    map[string]*Key
+	order   []string // 保持配置顺序（用于 JWKS 输出）
+}
+
+// NewKeySet 创建密钥集
+func NewKeySet(signing *Key, verification ...*Key) (*KeySet, error) {
+	if signing == nil || !signing.CanSign() {
+		return nil, fmt.Errorf("auth: signing key is required")
+	}
+
+	s := &KeySet{
+		signing: signing,
+		keys:    make(map[string]*Key, len(verification)+1),
+	}
+	for _, key := range append([]*Key{signing}, verification...) {
+		if _, exists := s.keys[key.ID]; exists {
+			return nil, fmt.Errorf("auth: duplicate key id %q", key.ID)
+		}
+		s.keys[key.ID] = key
+		s.order = append(s.order, key.ID)
+	}
+	return s, nil
+}
+
+// NewHMACKeySet 创建只包含一个 HS256 密钥的密钥集（兼容旧的单密钥配置）
+func NewHMACKeySet(secret string) *KeySet {
+	s, _ := NewKeySet(NewHMACKey("default", []byte(secret)))
+	return s
+}
+
+// SigningKey 当前签名密钥
+func (s *KeySet) SigningKey() *Key {
+	return s.signing
+}
+
+// Key 按 kid 查找密钥
+func (s *KeySet) Key(kid string) (*Key, bool) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	key, ok := s.keys[kid]
+	return key, ok
+}
+
+// Keys 密钥集中的所有密钥（第一个为当前签名密钥）
+func (s *KeySet) Keys() []*Key {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	keys := make([]*Key, 0, len(s.order))
+	for _, kid := range s.order {
+		keys = append(keys, s.keys[kid])
+	}
+	return keys
+}
+
+// Retire 从密钥集中移除验证密钥（当前签名密钥不能移除）
+func (s *KeySet) Retire(kid string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if kid == s.signing.ID {
+		return fmt.Errorf("auth: cannot retire current signing key %q", kid)
+	}
+	if _, ok := s.keys[kid]; !ok {
+		return fmt.Errorf("%w: %s", ErrUnknownKeyID, kid)
+	}
+
+	delete(s.keys, kid)
+	for i, id := range s.order {
+		if id == kid {
+			s.order = append(s.order[:i], s.order[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// Algorithms 密钥集中使用的所有签名算法（用于限制可接受的 alg）
+func (s *KeySet) Algorithms() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	seen := make(map[string]struct{}, len(s.keys))
+	algs := make([]string, 0, len(s.keys))
+	for _, kid := range s.order {
+		alg := s.keys[kid].Method.Alg()
+		if _, ok := seen[alg]; !ok {
+			seen[alg] = struct{}{}
+			algs = append(algs, alg)
+		}
+	}
+	return algs
+}
+
+// Token 类型（typ 头）。访问令牌使用默认的 "JWT"，其他用途的令牌使用独立类型，
+// 避免同一密钥集签发的令牌被挪作他用（例如用 MFA 待验证令牌直接访问接口）。
+const (
+	tokenTypeAccess = "JWT"
+	tokenTypeMFA    = "mfa+jwt"
+)
+
+// sign 使用当前签名密钥签发访问令牌（写入 kid 头）
+func (s *KeySet) sign(claims jwt.Claims) (string, error) {
+	return s.signTyped(claims, tokenTypeAccess)
+}
+
+// signTyped 使用当前签名密钥签名，并写入 kid 和 typ 头
+func (s *KeySet) signTyped(claims jwt.Claims, typ string) (string, error) {
+	token := jwt.NewWithClaims(s.signing.Method, claims)
+	token.Header["kid"] = s.signing.ID
+	token.Header["typ"] = typ
+
+	tokenString, err := token.SignedString(s.signing.signKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to sign token: %w", err)
+	}
+	return tokenString, nil
+}
+
+// keyfunc 按 kid 选择验证密钥（没有 kid 的旧 Token 使用当前签名密钥）
+//
+// 当前签名密钥过期后仍可验证（只告警，避免所有用户被登出）；旧密钥过期后立即拒绝。
+func (s *KeySet) keyfunc(token *jwt.Token) (any, error) {
+	key := s.signing
+	if kid, ok := token.Header["kid"].(string); ok {
+		if key, ok = s.Key(kid); !ok {
+			return nil, fmt.Errorf("%w: %s", ErrUnknownKeyID, kid)
+		}
+	}
+
+	if key != s.signing && key.Expired(time.Now()) {
+		return nil, fmt.Errorf("%w: %s", ErrKeyExpired, key.ID)
+	}
+
+	// 防止算法混淆：Token 的 alg 必须与密钥的算法一致
+	if token.Method.Alg() != key.Method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+	}
+	return key.verifyKey, nil
+}
+
+// parse 解析并验证访问令牌
+func (s *KeySet) parse(tokenString string, claims jwt.Claims) (*jwt.Token, error) {
+	return s.parseTyped(tokenString, claims, tokenTypeAccess)
+}
+
+// parseTyped 解析并验证指定类型的 Token（没有 typ 头的旧 Token 视为访问令牌）
+func (s *KeySet) parseTyped(tokenString string, claims jwt.Claims, typ string) (*jwt.Token, error) {
+	keyfunc := func(token *jwt.Token) (any, error) {
+		actual, _ := token.Header["typ"].(string)
+		if actual == "" {
+			actual = tokenTypeAccess
+		}
+		if actual != typ {
+			return nil, fmt.Errorf("unexpected token type: %s", actual)
+		}
+		return s.keyfunc(token)
+	}
+	return jwt.ParseWithClaims(tokenString, claims, keyfunc, jwt.WithValidMethods(s.Algorithms()))
+}
+
+// ============================================================================
+// JWKS（RFC 7517）
+// ============================================================================
+
+// JWK JSON Web Key（只包含公钥参数）
+type JWK struct {
+	Kty string `json:"kty"`           // 密钥类型: RSA / EC / OKP
+	Kid string `json:"kid"`           // 密钥 ID
+	Use string `json:"use"`           // 用途: sig
+	Alg string `json:"alg"`           // 签名算法
+	N   string `json:"n,omitempty"`   // RSA 模数
+	E   string `json:"e,omitempty"`   // RSA 公开指数
+	Crv string `json:"crv,omitempty"` // 曲线: P-256 / P-384 / P-521 / Ed25519
+	X   string `json:"x,omitempty"`   // EC/OKP 公钥 x 坐标
+	Y   string `json:"y,omitempty"`   // EC 公钥 y 坐标
+}
+
+// JWKS JSON Web Key Set
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+// JWKS 导出密钥集中的所有公钥（对称密钥和已过期的旧密钥不会导出）
+func (s *KeySet) JWKS() JWKS {
+	now := time.Now()
+	keys := s.Keys()
+
+	set := JWKS{Keys: make([]JWK, 0, len(keys))}
+	for _, key := range keys {
+		if key != s.signing && key.Expired(now) {
+			continue
+		}
+		if jwk, ok := key.JWK(); ok {
+			set.Keys = append(set.Keys, jwk)
+		}
+	}
+	return set
+}
+
+// JWK 导出公钥（对称密钥返回 false）
+func (k *Key) JWK() (JWK, bool) {
+	jwk := JWK{
+		Kid: k.ID,
+		Use: "sig",
+		Alg: k.Method.Alg(),
+	}
+
+	switch pub := k.PublicKey().(type) {
+	case *rsa.PublicKey:
+		jwk.Kty = "RSA"
+		jwk.N = encodeBase64URL(pub.N.Bytes())
+		jwk.E = encodeBase64URL(big.NewInt(int64(pub.E)).Bytes())
+	case *ecdsa.PublicKey:
+		size := (pub.Curve.Params().BitSize + 7) / 8
+		jwk.Kty = "EC"
+		jwk.Crv = pub.Curve.Params().Name
+		jwk.X = encodeBase64URL(pub.X.FillBytes(make([]byte, size)))
+		jwk.Y = encodeBase64URL(pub.Y.FillBytes(make([]byte, size)))
+	case ed25519.PublicKey:
+		jwk.Kty = "OKP"
+		jwk.Crv = "Ed25519"
+		jwk.X = encodeBase64URL(pub)
+	default:
+		return JWK{}, false
+	}
+	return jwk, true
+}
+
+// ParseJWK 从 JWK 解析仅用于验证的公钥（用于校验第三方签发的 Token）
+//
+// JWK 没有 alg 参数时按密钥类型推断：RSA 使用 RS256，EC 按曲线选择 ES256/ES384/ES512，OKP 使用 EdDSA。
+func ParseJWK(jwk JWK) (*Key, error) {
+	var (
+		public crypto.PublicKey
+		alg    = jwk.Alg
+	)
+
+	switch jwk.Kty {
+	case "RSA":
+		n, err := decodeBase64URL(jwk.N)
+		if err != nil {
+			return nil, fmt.Errorf("auth: invalid jwk n: %w", err)
+		}
+		e, err := decodeBase64URL(jwk.E)
+		if err != nil {
+			return nil, fmt.Errorf("auth: invalid jwk e: %w", err)
+		}
+		exponent := new(big.Int).SetBytes(e)
+		if len(n) == 0 || !exponent.IsInt64() || exponent.Int64() < 3 || exponent.Int64() > 1<<31-1 {
+			return nil, fmt.Errorf("auth: invalid rsa jwk %q", jwk.Kid)
+		}
+		public = &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(exponent.Int64())}
+		if alg == "" {
+			alg = "RS256"
+		}
+	case "EC":
+		var curve elliptic.Curve
+		switch jwk.Crv {
+		case "P-256":
+			curve, alg = elliptic.P256(), defaultString(alg, "ES256")
+		case "P-384":
+			curve, alg = elliptic.P384(), defaultString(alg, "ES384")
+		case "P-521":
+			curve, alg = elliptic.P521(), defaultString(alg, "ES512")
+		default:
+			return nil, fmt.Errorf("auth: unsupported jwk curve %q", jwk.Crv)
+		}
+		x, err := decodeBase64URL(jwk.X)
+		if err != nil {
+			return nil, fmt.Errorf("auth: invalid jwk x: %w", err)
+		}
+		y, err := decodeBase64URL(jwk.Y)
+		if err != nil {
+			return nil, fmt.Errorf("auth: invalid jwk y: %w", err)
+		}
+		pub := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(pub.X, pub.Y) {
+			return nil, fmt.Errorf("auth: jwk %q point is not on curve %s", jwk.Kid, jwk.Crv)
+		}
+		public = pub
+	case "OKP":
+		if jwk.Crv != "Ed25519" {
+			return nil, fmt.Errorf("auth: unsupported jwk curve %q", jwk.Crv)
+		}
+		x, err := decodeBase64URL(jwk.X)
+		if err != nil || len(x) != ed25519.PublicKeySize {
+			return nil, fmt.Errorf("auth: invalid ed25519 jwk %q", jwk.Kid)
+		}
+		public = ed25519.PublicKey(x)
+		alg = defaultString(alg, "EdDSA")
+	default:
+		return nil, fmt.Errorf("auth: unsupported jwk key type %q", jwk.Kty)
+	}
+
+	return NewVerificationKey(jwk.Kid, alg, public)
+}
+
+// defaultString s 为空时返回 def
+func defaultString(s, def string) string {
+	if s == "" {
+		return def
+	}
+	return s
+}
+
+// decodeBase64URL Base64URL 解码（兼容带填充的输入）
+func decodeBase64URL(s string) ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(strings.TrimRight(s, "="))
+}
+
+// encodeBase64URL Base64URL 编码（无填充）
+func encodeBase64URL(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
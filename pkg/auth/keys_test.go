@@ -0,0 +1,254 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// privateKeyPEM 将私钥编码为 PKCS#8 PEM
+func privateKeyPEM(t *testing.T, key crypto.Signer) []byte {
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+}
+
+// publicKeyPEM 将公钥编码为 PKIX PEM
+func publicKeyPEM(t *testing.T, key crypto.PublicKey) []byte {
+	der, err := x509.MarshalPKIXPublicKey(key)
+	require.NoError(t, err)
+	return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+}
+
+// TestKeySet_AsymmetricAlgorithms 测试非对称算法签发与验证
+func TestKeySet_AsymmetricAlgorithms(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	_, edKey, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	tests := []struct {
+		alg string
+		key crypto.Signer
+		kty string
+	}{
+		{"RS256", rsaKey, "RSA"},
+		{"ES256", ecKey, "EC"},
+		{"EdDSA", edKey, "OKP"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.alg, func(t *testing.T) {
+			signing, err := ParseSigningKeyPEM("k1", tt.alg, privateKeyPEM(t, tt.key))
+			require.NoError(t, err)
+			keys, err := NewKeySet(signing)
+			require.NoError(t, err)
+
+			manager := NewRBACJWTManagerWithKeys(keys, time.Hour)
+			token, err := manager.GenerateToken(7, RoleAdmin)
+			require.NoError(t, err)
+
+			// 头部包含 alg 和 kid
+			parsed, _, err := jwt.NewParser().ParseUnverified(token, &RBACClaims{})
+			require.NoError(t, err)
+			assert.Equal(t, tt.alg, parsed.Header["alg"])
+			assert.Equal(t, "k1", parsed.Header["kid"])
+
+			claims, err := manager.ValidateToken(token)
+			require.NoError(t, err)
+			assert.Equal(t, int64(7), claims.UserID)
+
+			// 只持有公钥的服务也能验证
+			verifier, err := ParseVerificationKeyPEM("k1", tt.alg, publicKeyPEM(t, tt.key.Public()))
+			require.NoError(t, err)
+			verifyKeys, err := NewKeySet(NewHMACKey("local", []byte("other-secret")), verifier)
+			require.NoError(t, err)
+			_, err = NewRBACJWTManagerWithKeys(verifyKeys, time.Hour).ValidateToken(token)
+			assert.NoError(t, err)
+
+			jwks := keys.JWKS()
+			require.Len(t, jwks.Keys, 1)
+			assert.Equal(t, tt.kty, jwks.Keys[0].Kty)
+			assert.Equal(t, "k1", jwks.Keys[0].Kid)
+			assert.Equal(t, tt.alg, jwks.Keys[0].Alg)
+
+			// 从 JWK 解析出的公钥与原密钥一致（没有 alg 时按密钥类型推断）
+			jwk := jwks.Keys[0]
+			jwk.Alg = ""
+			parsedKey, err := ParseJWK(jwk)
+			require.NoError(t, err)
+			assert.Equal(t, tt.alg, parsedKey.Method.Alg())
+			assert.True(t, parsedKey.PublicKey().(interface{ Equal(crypto.PublicKey) bool }).Equal(tt.key.Public()))
+		})
+	}
+}
+
+// TestKeySet_Rotation 测试多个验证密钥（轮换）
+func TestKeySet_Rotation(t *testing.T) {
+	oldKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	newKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	oldSigning, err := NewSigningKey("old", "ES256", oldKey)
+	require.NoError(t, err)
+	oldKeys, err := NewKeySet(oldSigning)
+	require.NoError(t, err)
+	oldToken, err := NewRBACJWTManagerWithKeys(oldKeys, time.Hour).GenerateToken(1, RoleUser)
+	require.NoError(t, err)
+
+	newSigning, err := NewSigningKey("new", "ES256", newKey)
+	require.NoError(t, err)
+	oldVerifier, err := NewVerificationKey("old", "ES256", oldKey.Public())
+	require.NoError(t, err)
+
+	t.Run("轮换后旧 Token 仍可验证", func(t *testing.T) {
+		keys, err := NewKeySet(newSigning, oldVerifier)
+		require.NoError(t, err)
+
+		_, err = NewRBACJWTManagerWithKeys(keys, time.Hour).ValidateToken(oldToken)
+		assert.NoError(t, err)
+		assert.Len(t, keys.JWKS().Keys, 2)
+	})
+
+	t.Run("移除旧公钥后旧 Token 失效", func(t *testing.T) {
+		keys, err := NewKeySet(newSigning)
+		require.NoError(t, err)
+
+		_, err = NewRBACJWTManagerWithKeys(keys, time.Hour).ValidateToken(oldToken)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+}
+
+// TestKeySet_RejectsMismatchedAlgorithm 测试拒绝算法混淆
+func TestKeySet_RejectsMismatchedAlgorithm(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	t.Run("密钥类型与算法不匹配", func(t *testing.T) {
+		_, err := NewSigningKey("k1", "ES256", rsaKey)
+		assert.Error(t, err)
+	})
+
+	t.Run("不支持的算法", func(t *testing.T) {
+		_, err := NewSigningKey("k1", "none", rsaKey)
+		assert.ErrorIs(t, err, ErrUnsupportedAlgorithm)
+	})
+
+	t.Run("用公钥作为 HMAC 密钥伪造的 Token", func(t *testing.T) {
+		signing, err := NewSigningKey("k1", "RS256", rsaKey)
+		require.NoError(t, err)
+		keys, err := NewKeySet(signing)
+		require.NoError(t, err)
+
+		forged := jwt.NewWithClaims(jwt.SigningMethodHS256, RBACClaims{UserID: 1, Role: RoleSuperAdmin})
+		forged.Header["kid"] = "k1"
+		token, err := forged.SignedString(publicKeyPEM(t, rsaKey.Public()))
+		require.NoError(t, err)
+
+		_, err = NewRBACJWTManagerWithKeys(keys, time.Hour).ValidateToken(token)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	})
+}
+
+// TestKeySet_HMACCompatibility 测试 HS256 单密钥兼容
+func TestKeySet_HMACCompatibility(t *testing.T) {
+	manager := NewRBACJWTManager("test-secret", time.Hour)
+
+	// 没有 kid 的旧 Token 使用当前签名密钥验证
+	legacy := jwt.NewWithClaims(jwt.SigningMethodHS256, RBACClaims{
+		UserID: 1,
+		Role:   RoleUser,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	})
+	token, err := legacy.SignedString([]byte("test-secret"))
+	require.NoError(t, err)
+
+	_, err = manager.ValidateToken(token)
+	assert.NoError(t, err)
+
+	// 对称密钥不会出现在 JWKS 中
+	assert.Empty(t, NewHMACKeySet("test-secret").JWKS().Keys)
+
+	// 未知 kid
+	unknown := jwt.NewWithClaims(jwt.SigningMethodHS256, RBACClaims{UserID: 1})
+	unknown.Header["kid"] = "missing"
+	token, err = unknown.SignedString([]byte("test-secret"))
+	require.NoError(t, err)
+
+	_, err = manager.ValidateToken(token)
+	assert.True(t, strings.Contains(err.Error(), ErrUnknownKeyID.Error()))
+}
+
+// TestKeySet_NotAfter 测试旧密钥有效期与退役
+func TestKeySet_NotAfter(t *testing.T) {
+	oldManager := NewRBACJWTManager("old-secret", time.Hour)
+	oldToken, err := oldManager.GenerateToken(1, RoleUser)
+	require.NoError(t, err)
+
+	// 旧 Token 的 kid 为 "default"
+	newKey := NewHMACKey("current", []byte("new-secret"))
+
+	t.Run("有效期内可验证", func(t *testing.T) {
+		previous := NewHMACKey("default", []byte("old-secret"))
+		previous.NotAfter = time.Now().Add(time.Hour)
+		keys, err := NewKeySet(newKey, previous)
+		require.NoError(t, err)
+
+		_, err = NewRBACJWTManagerWithKeys(keys, time.Hour).ValidateToken(oldToken)
+		assert.NoError(t, err)
+	})
+
+	t.Run("超过有效期拒绝验证", func(t *testing.T) {
+		previous := NewHMACKey("default", []byte("old-secret"))
+		previous.NotAfter = time.Now().Add(-time.Minute)
+		keys, err := NewKeySet(newKey, previous)
+		require.NoError(t, err)
+
+		_, err = NewRBACJWTManagerWithKeys(keys, time.Hour).ValidateToken(oldToken)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+		assert.True(t, strings.Contains(err.Error(), ErrKeyExpired.Error()))
+	})
+
+	t.Run("退役后从密钥集移除", func(t *testing.T) {
+		previous := NewHMACKey("default", []byte("old-secret"))
+		keys, err := NewKeySet(newKey, previous)
+		require.NoError(t, err)
+
+		require.NoError(t, keys.Retire("default"))
+		assert.Len(t, keys.Keys(), 1)
+		_, ok := keys.Key("default")
+		assert.False(t, ok)
+
+		// 当前签名密钥不能退役
+		assert.Error(t, keys.Retire("current"))
+	})
+
+	t.Run("JWKS 不导出已过期的公钥", func(t *testing.T) {
+		ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		previous, err := NewVerificationKey("previous", "ES256", ecKey.Public())
+		require.NoError(t, err)
+		previous.NotAfter = time.Now().Add(-time.Minute)
+
+		keys, err := NewKeySet(newKey, previous)
+		require.NoError(t, err)
+		assert.Empty(t, keys.JWKS().Keys)
+	})
+}
@@ -0,0 +1,185 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"gin_demo/pkg/tenant"
+)
+
+// ErrLoginLocked 登录失败次数过多，暂时锁定
+var ErrLoginLocked = errors.New("login temporarily locked")
+
+// 锁定范围
+const (
+	LockoutScopeAccount = "account" // 按账户（租户 + 登录邮箱）锁定
+	LockoutScopeIP      = "ip"      // 按客户端 IP 锁定
+)
+
+// LockoutError 登录锁定错误（errors.Is(err, ErrLoginLocked) 为 true）
+type LockoutError struct {
+	Scope      string        // 锁定范围: account / ip
+	RetryAfter time.Duration // 剩余锁定时长
+}
+
+// Error 实现 error 接口
+func (e *LockoutError) Error() string {
+	return fmt.Sprintf("%s: %s locked for %s", ErrLoginLocked, e.Scope, e.RetryAfter.Round(time.Second))
+}
+
+// Unwrap 支持 errors.Is(err, ErrLoginLocked)
+func (e *LockoutError) Unwrap() error {
+	return ErrLoginLocked
+}
+
+// LockoutPolicy 锁定策略（指数退避）
+//
+// 窗口内失败次数达到 MaxAttempts 后开始锁定，锁定时长为 BaseDelay，
+// 此后每多失败一次翻倍，最长不超过 MaxDelay。Window 内没有新的失败则计数清零。
+type LockoutPolicy struct {
+	MaxAttempts int           // 开始锁定前允许的失败次数（<= 0 表示不限制）
+	BaseDelay   time.Duration // 首次锁定时长
+	MaxDelay    time.Duration // 最长锁定时长
+	Window      time.Duration // 失败计数的保留时长（从最后一次失败算起）
+}
+
+// lockDuration 根据失败次数计算锁定时长（0 表示无需锁定）
+func (p LockoutPolicy) lockDuration(failures int64) time.Duration {
+	if p.MaxAttempts <= 0 || failures < int64(p.MaxAttempts) {
+		return 0
+	}
+
+	d := p.BaseDelay
+	for i := int64(p.MaxAttempts); i < failures; i++ {
+		if p.MaxDelay > 0 && d >= p.MaxDelay {
+			break
+		}
+		d *= 2
+	}
+	if p.MaxDelay > 0 && d > p.MaxDelay {
+		return p.MaxDelay
+	}
+	return d
+}
+
+// LoginAttemptStore 登录失败记录存储接口
+type LoginAttemptStore interface {
+	// IncrFailures 失败次数加一并返回当前次数，计数在 window 内无新失败后过期
+	IncrFailures(ctx context.Context, key string, window time.Duration) (int64, error)
+
+	// Lock 锁定 key，持续 d
+	Lock(ctx context.Context, key string, d time.Duration) error
+
+	// LockedFor 返回剩余锁定时长（0 表示未锁定）
+	LockedFor(ctx context.Context, key string) (time.Duration, error)
+
+	// Reset 清除失败计数和锁定
+	Reset(ctx context.Context, key string) error
+}
+
+// LoginGuard 登录暴力破解防护（按账户和 IP 分别记录失败次数并锁定）
+//
+// 邮箱只在租户内唯一，账户维度按 context 中的租户（tenant.ID）隔离，
+// 一个租户内的失败登录不会锁定其他租户的同名邮箱。
+type LoginGuard struct {
+	store   LoginAttemptStore
+	account LockoutPolicy
+	ip      LockoutPolicy
+}
+
+// NewLoginGuard 创建登录防护
+func NewLoginGuard(store LoginAttemptStore, account, ip LockoutPolicy) *LoginGuard {
+	return &LoginGuard{
+		store:   store,
+		account: account,
+		ip:      ip,
+	}
+}
+
+// Check 登录前检查账户和 IP 是否处于锁定状态，锁定时返回 *LockoutError
+func (g *LoginGuard) Check(ctx context.Context, account, ip string) error {
+	for _, scope := range g.scopes(ctx, account, ip) {
+		d, err := g.store.LockedFor(ctx, scope.key)
+		if err != nil {
+			return fmt.Errorf("check lockout: %w", err)
+		}
+		if d > 0 {
+			return &LockoutError{Scope: scope.name, RetryAfter: d}
+		}
+	}
+	return nil
+}
+
+// RecordFailure 记录一次登录失败，达到阈值时锁定并返回 *LockoutError
+func (g *LoginGuard) RecordFailure(ctx context.Context, account, ip string) error {
+	var locked *LockoutError
+	for _, scope := range g.scopes(ctx, account, ip) {
+		failures, err := g.store.IncrFailures(ctx, scope.key, scope.policy.Window)
+		if err != nil {
+			return fmt.Errorf("record login failure: %w", err)
+		}
+
+		d := scope.policy.lockDuration(failures)
+		if d <= 0 {
+			continue
+		}
+		if err := g.store.Lock(ctx, scope.key, d); err != nil {
+			return fmt.Errorf("lock %s: %w", scope.name, err)
+		}
+		if locked == nil || d > locked.RetryAfter {
+			locked = &LockoutError{Scope: scope.name, RetryAfter: d}
+		}
+	}
+
+	if locked != nil {
+		return locked
+	}
+	return nil
+}
+
+// RecordSuccess 登录成功后清除账户的失败记录（IP 计数保留，由窗口自然过期）
+func (g *LoginGuard) RecordSuccess(ctx context.Context, account string) error {
+	return g.store.Reset(ctx, accountKey(ctx, account))
+}
+
+// UnlockAccount 解除账户锁定（管理员操作）
+func (g *LoginGuard) UnlockAccount(ctx context.Context, account string) error {
+	return g.store.Reset(ctx, accountKey(ctx, account))
+}
+
+// UnlockIP 解除 IP 锁定（管理员操作）
+func (g *LoginGuard) UnlockIP(ctx context.Context, ip string) error {
+	return g.store.Reset(ctx, ipKey(ip))
+}
+
+// lockoutScope 一个锁定维度
+type lockoutScope struct {
+	name   string
+	key    string
+	policy LockoutPolicy
+}
+
+// scopes 返回需要检查的锁定维度（策略未启用或值为空时跳过）
+func (g *LoginGuard) scopes(ctx context.Context, account, ip string) []lockoutScope {
+	scopes := make([]lockoutScope, 0, 2)
+	if account != "" && g.account.MaxAttempts > 0 {
+		scopes = append(scopes, lockoutScope{LockoutScopeAccount, accountKey(ctx, account), g.account})
+	}
+	if ip != "" && g.ip.MaxAttempts > 0 {
+		scopes = append(scopes, lockoutScope{LockoutScopeIP, ipKey(ip), g.ip})
+	}
+	return scopes
+}
+
+// accountKey 账户维度的 key（account:<租户>:<邮箱>，邮箱不区分大小写）
+func accountKey(ctx context.Context, account string) string {
+	return fmt.Sprintf("%s:%d:%s", LockoutScopeAccount, tenant.ID(ctx), strings.ToLower(strings.TrimSpace(account)))
+}
+
+// ipKey IP 维度的 key
+func ipKey(ip string) string {
+	return LockoutScopeIP + ":" + ip
+}
@@ -0,0 +1,77 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryLoginAttemptStore 基于内存的登录失败记录存储（仅用于测试或单实例部署）
+type MemoryLoginAttemptStore struct {
+	mu       sync.Mutex
+	failures map[string]memoryEntry[int64]
+	locks    map[string]memoryEntry[struct{}]
+}
+
+// NewMemoryLoginAttemptStore 创建内存登录失败记录存储
+func NewMemoryLoginAttemptStore() *MemoryLoginAttemptStore {
+	return &MemoryLoginAttemptStore{
+		failures: make(map[string]memoryEntry[int64]),
+		locks:    make(map[string]memoryEntry[struct{}]),
+	}
+}
+
+// IncrFailures 实现 LoginAttemptStore 接口
+func (s *MemoryLoginAttemptStore) IncrFailures(_ context.Context, key string, window time.Duration) (int64, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	entry, ok := s.failures[key]
+	if !ok || entry.expired(now) {
+		entry = memoryEntry[int64]{}
+	}
+	entry.value++
+	entry.expiresAt = now.Add(window)
+	s.failures[key] = entry
+	return entry.value, nil
+}
+
+// Lock 实现 LoginAttemptStore 接口
+func (s *MemoryLoginAttemptStore) Lock(_ context.Context, key string, d time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.locks[key] = memoryEntry[struct{}]{expiresAt: time.Now().Add(d)}
+	return nil
+}
+
+// LockedFor 实现 LoginAttemptStore 接口
+func (s *MemoryLoginAttemptStore) LockedFor(_ context.Context, key string) (time.Duration, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.locks[key]
+	if !ok {
+		return 0, nil
+	}
+	remaining := time.Until(entry.expiresAt)
+	if remaining <= 0 {
+		delete(s.locks, key)
+		return 0, nil
+	}
+	return remaining, nil
+}
+
+// Reset 实现 LoginAttemptStore 接口
+func (s *MemoryLoginAttemptStore) Reset(_ context.Context, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.failures, key)
+	delete(s.locks, key)
+	return nil
+}
+
+// 确保 MemoryLoginAttemptStore 实现了接口
+var _ LoginAttemptStore = (*MemoryLoginAttemptStore)(nil)
@@ -0,0 +1,69 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisLoginAttemptStore 基于 Redis 的登录失败记录存储（多实例共享计数）
+//
+// Key 布局:
+//   - {prefix}failures:<scope>:<value>   失败次数
+//   - {prefix}lock:<scope>:<value>       锁定标记（TTL 即剩余锁定时长）
+type RedisLoginAttemptStore struct {
+	rdb    redis.UniversalClient
+	prefix string
+}
+
+// NewRedisLoginAttemptStore 创建 Redis 登录失败记录存储
+func NewRedisLoginAttemptStore(rdb redis.UniversalClient, prefix string) *RedisLoginAttemptStore {
+	if prefix == "" {
+		prefix = "auth:login:"
+	}
+	return &RedisLoginAttemptStore{
+		rdb:    rdb,
+		prefix: prefix,
+	}
+}
+
+// IncrFailures 实现 LoginAttemptStore 接口（INCR 与 PEXPIRE 在同一事务中执行）
+func (s *RedisLoginAttemptStore) IncrFailures(ctx context.Context, key string, window time.Duration) (int64, error) {
+	var incr *redis.IntCmd
+	_, err := s.rdb.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		incr = pipe.Incr(ctx, s.prefix+"failures:"+key)
+		pipe.PExpire(ctx, s.prefix+"failures:"+key, window)
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	return incr.Val(), nil
+}
+
+// Lock 实现 LoginAttemptStore 接口
+func (s *RedisLoginAttemptStore) Lock(ctx context.Context, key string, d time.Duration) error {
+	return s.rdb.Set(ctx, s.prefix+"lock:"+key, time.Now().Unix(), d).Err()
+}
+
+// LockedFor 实现 LoginAttemptStore 接口
+func (s *RedisLoginAttemptStore) LockedFor(ctx context.Context, key string) (time.Duration, error) {
+	d, err := s.rdb.PTTL(ctx, s.prefix+"lock:"+key).Result()
+	if err != nil {
+		return 0, err
+	}
+	// key 不存在时 PTTL 返回负值
+	if d < 0 {
+		return 0, nil
+	}
+	return d, nil
+}
+
+// Reset 实现 LoginAttemptStore 接口
+func (s *RedisLoginAttemptStore) Reset(ctx context.Context, key string) error {
+	return s.rdb.Del(ctx, s.prefix+"failures:"+key, s.prefix+"lock:"+key).Err()
+}
+
+// 确保 RedisLoginAttemptStore 实现了接口
+var _ LoginAttemptStore = (*RedisLoginAttemptStore)(nil)
@@ -0,0 +1,106 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"gin_demo/pkg/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLockoutPolicy_LockDuration 测试指数退避锁定时长
+func TestLockoutPolicy_LockDuration(t *testing.T) {
+	policy := LockoutPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: 10 * time.Minute}
+
+	assert.Equal(t, time.Duration(0), policy.lockDuration(2))
+	assert.Equal(t, time.Minute, policy.lockDuration(3))
+	assert.Equal(t, 2*time.Minute, policy.lockDuration(4))
+	assert.Equal(t, 8*time.Minute, policy.lockDuration(6))
+	assert.Equal(t, 10*time.Minute, policy.lockDuration(7))
+	assert.Equal(t, 10*time.Minute, policy.lockDuration(1000))
+
+	// 未启用
+	assert.Equal(t, time.Duration(0), LockoutPolicy{}.lockDuration(100))
+}
+
+// TestLoginGuard 测试登录防护
+func TestLoginGuard(t *testing.T) {
+	ctx := context.Background()
+	accountPolicy := LockoutPolicy{MaxAttempts: 3, BaseDelay: time.Minute, MaxDelay: time.Hour, Window: time.Hour}
+	ipPolicy := LockoutPolicy{MaxAttempts: 5, BaseDelay: time.Minute, MaxDelay: time.Hour, Window: time.Hour}
+
+	t.Run("达到阈值后锁定账户", func(t *testing.T) {
+		guard := NewLoginGuard(NewMemoryLoginAttemptStore(), accountPolicy, ipPolicy)
+
+		for i := 0; i < 2; i++ {
+			require.NoError(t, guard.RecordFailure(ctx, "a@example.com", "1.1.1.1"))
+		}
+		require.NoError(t, guard.Check(ctx, "a@example.com", "1.1.1.1"))
+
+		err := guard.RecordFailure(ctx, "a@example.com", "1.1.1.1")
+		var lockErr *LockoutError
+		require.True(t, errors.As(err, &lockErr))
+		assert.Equal(t, LockoutScopeAccount, lockErr.Scope)
+
+		// 邮箱不区分大小写
+		err = guard.Check(ctx, "A@Example.com", "2.2.2.2")
+		assert.ErrorIs(t, err, ErrLoginLocked)
+
+		// 其他账户不受影响
+		assert.NoError(t, guard.Check(ctx, "b@example.com", "1.1.1.1"))
+	})
+
+	t.Run("同一 IP 尝试多个账户后锁定 IP", func(t *testing.T) {
+		guard := NewLoginGuard(NewMemoryLoginAttemptStore(), accountPolicy, ipPolicy)
+
+		accounts := []string{"a@x.com", "b@x.com", "c@x.com", "d@x.com", "e@x.com"}
+		for _, account := range accounts {
+			_ = guard.RecordFailure(ctx, account, "1.1.1.1")
+		}
+
+		err := guard.Check(ctx, "f@x.com", "1.1.1.1")
+		var lockErr *LockoutError
+		require.True(t, errors.As(err, &lockErr))
+		assert.Equal(t, LockoutScopeIP, lockErr.Scope)
+
+		require.NoError(t, guard.UnlockIP(ctx, "1.1.1.1"))
+		assert.NoError(t, guard.Check(ctx, "f@x.com", "1.1.1.1"))
+	})
+
+	t.Run("登录成功和管理员解锁清除账户记录", func(t *testing.T) {
+		guard := NewLoginGuard(NewMemoryLoginAttemptStore(), accountPolicy, LockoutPolicy{})
+
+		_ = guard.RecordFailure(ctx, "a@example.com", "")
+		_ = guard.RecordFailure(ctx, "a@example.com", "")
+		require.NoError(t, guard.RecordSuccess(ctx, "a@example.com"))
+
+		// 计数已清零，需要重新失败 3 次才会锁定
+		_ = guard.RecordFailure(ctx, "a@example.com", "")
+		_ = guard.RecordFailure(ctx, "a@example.com", "")
+		assert.NoError(t, guard.Check(ctx, "a@example.com", ""))
+
+		assert.ErrorIs(t, guard.RecordFailure(ctx, "a@example.com", ""), ErrLoginLocked)
+		require.NoError(t, guard.UnlockAccount(ctx, "a@example.com"))
+		assert.NoError(t, guard.Check(ctx, "a@example.com", ""))
+	})
+
+	t.Run("账户锁定按租户隔离", func(t *testing.T) {
+		guard := NewLoginGuard(NewMemoryLoginAttemptStore(), accountPolicy, LockoutPolicy{})
+		tenantA := tenant.WithID(ctx, 2)
+		tenantB := tenant.WithID(ctx, 3)
+
+		for i := 0; i < 3; i++ {
+			_ = guard.RecordFailure(tenantA, "a@example.com", "")
+		}
+		require.ErrorIs(t, guard.Check(tenantA, "a@example.com", ""), ErrLoginLocked)
+		assert.NoError(t, guard.Check(tenantB, "a@example.com", ""), "其他租户的同名邮箱不受影响")
+
+		// 解锁同样只作用于当前租户
+		require.NoError(t, guard.UnlockAccount(tenantB, "a@example.com"))
+		assert.ErrorIs(t, guard.Check(tenantA, "a@example.com", ""), ErrLoginLocked)
+	})
+}
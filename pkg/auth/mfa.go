@@ -0,0 +1,79 @@
+package auth
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// MFAClaims MFA 待验证令牌声明（密码校验通过、第二步验证尚未完成）
+type MFAClaims struct {
+	UserID   int64 `json:"user_id"`
+	Version  int64 `json:"ver,omitempty"` // 签发时用户的 Token 版本号
+	TenantID int64 `json:"tid,omitempty"` // 用户所属租户（0 表示默认租户），第二步按该租户加载用户
+	jwt.RegisteredClaims
+}
+
+// MFATokenManager MFA 待验证令牌管理器
+//
+// 与访问令牌共用密钥集，但使用独立的 typ 头，不能当作访问令牌使用，反之亦然。
+type MFATokenManager struct {
+	keys       *KeySet
+	expiration time.Duration
+}
+
+// NewMFATokenManager 创建 MFA 待验证令牌管理器
+func NewMFATokenManager(keys *KeySet, expiration time.Duration) *MFATokenManager {
+	return &MFATokenManager{
+		keys:       keys,
+		expiration: expiration,
+	}
+}
+
+// Expiration 令牌有效期
+func (m *MFATokenManager) Expiration() time.Duration {
+	return m.expiration
+}
+
+// GenerateToken 签发 MFA 待验证令牌
+func (m *MFATokenManager) GenerateToken(tenantID, userID, version int64) (string, error) {
+	jti, err := randomString(16)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate token id: %w", err)
+	}
+
+	now := time.Now()
+	claims := MFAClaims{
+		UserID:   userID,
+		Version:  version,
+		TenantID: tenantID,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ID:        jti,
+			ExpiresAt: jwt.NewNumericDate(now.Add(m.expiration)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			NotBefore: jwt.NewNumericDate(now),
+		},
+	}
+
+	return m.keys.signTyped(claims, tokenTypeMFA)
+}
+
+// ValidateToken 验证 MFA 待验证令牌
+func (m *MFATokenManager) ValidateToken(tokenString string) (*MFAClaims, error) {
+	token, err := m.keys.parseTyped(tokenString, &MFAClaims{}, tokenTypeMFA)
+	if err != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
+			return nil, ErrExpiredToken
+		}
+		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+
+	claims, ok := token.Claims.(*MFAClaims)
+	if !ok || !token.Valid {
+		return nil, ErrInvalidToken
+	}
+
+	return claims, nil
+}
@@ -0,0 +1,166 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	"gin_demo/pkg/tenant"
+)
+
+var (
+	// ErrOAuthProviderNotFound 未配置该第三方登录提供方
+	ErrOAuthProviderNotFound = errors.New("oauth provider not found")
+	// ErrOAuthStateInvalid state 无效（不存在、已过期、已使用或与提供方、租户不匹配）
+	ErrOAuthStateInvalid = errors.New("invalid oauth state")
+	// ErrOAuthStateNotFound 存储中不存在该 state
+	ErrOAuthStateNotFound = errors.New("oauth state not found")
+	// ErrOAuthExchangeFailed 授权码换取令牌失败
+	ErrOAuthExchangeFailed = errors.New("oauth code exchange failed")
+	// ErrIDTokenInvalid ID Token 校验失败
+	ErrIDTokenInvalid = errors.New("invalid id token")
+)
+
+// ExternalIdentity 第三方账户信息
+type ExternalIdentity struct {
+	Provider      string // 提供方名称（配置中的 name）
+	Subject       string // 提供方内的用户唯一标识（OIDC sub / GitHub id）
+	Email         string
+	EmailVerified bool   // 提供方是否已验证该邮箱
+	Name          string // 显示名称
+	Username      string // 提供方的用户名（preferred_username / GitHub login），可能为空
+}
+
+// OAuthState 授权请求的上下文（以 state 为 Key 保存，回调时取出并删除）
+type OAuthState struct {
+	TenantID     int64     `json:"tenant_id"` // 发起授权的租户（回调必须属于同一租户）
+	Provider     string    `json:"provider"`
+	Nonce        string    `json:"nonce"`         // 写入 ID Token 的随机数，防止 ID Token 重放
+	CodeVerifier string    `json:"code_verifier"` // PKCE code_verifier（只保存在服务端）
+	LinkUserID   int64     `json:"link_user_id"`  // 非 0 表示为已登录用户关联第三方账户
+	CreatedAt    time.Time `json:"created_at"`
+}
+
+// OAuthStateStore 授权请求上下文存储接口
+type OAuthStateStore interface {
+	// Save 保存授权请求上下文
+	Save(ctx context.Context, state string, record OAuthState, ttl time.Duration) error
+
+	// Consume 取出并删除授权请求上下文（每个 state 只能使用一次），不存在时返回 ErrOAuthStateNotFound
+	Consume(ctx context.Context, state string) (OAuthState, error)
+}
+
+// OAuthManager 第三方登录管理器（授权码 + PKCE 流程）
+type OAuthManager struct {
+	providers map[string]*OAuthProvider
+	store     OAuthStateStore
+	stateTTL  time.Duration
+}
+
+// NewOAuthManager 创建第三方登录管理器
+func NewOAuthManager(store OAuthStateStore, stateTTL time.Duration, providers ...*OAuthProvider) *OAuthManager {
+	m := &OAuthManager{
+		providers: make(map[string]*OAuthProvider, len(providers)),
+		store:     store,
+		stateTTL:  stateTTL,
+	}
+	for _, p := range providers {
+		m.providers[p.Name()] = p
+	}
+	return m
+}
+
+// Providers 已配置的提供方名称（按名称排序）
+func (m *OAuthManager) Providers() []string {
+	names := make([]string, 0, len(m.providers))
+	for name := range m.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Provider 按名称获取提供方
+func (m *OAuthManager) Provider(name string) (*OAuthProvider, bool) {
+	p, ok := m.providers[name]
+	return p, ok
+}
+
+// AuthorizationURL 开始一次授权：生成 state、nonce 和 PKCE 参数并返回提供方的授权地址
+//
+// linkUserID 非 0 表示为该用户关联第三方账户，回调时通过 OAuthState.LinkUserID 区分。
+// context 中的租户（tenant.ID）随 state 保存，回调时校验。
+func (m *OAuthManager) AuthorizationURL(ctx context.Context, provider string, linkUserID int64) (string, error) {
+	p, ok := m.providers[provider]
+	if !ok {
+		return "", fmt.Errorf("%w: %s", ErrOAuthProviderNotFound, provider)
+	}
+
+	state, err := randomString(32)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate oauth state: %w", err)
+	}
+	nonce, err := randomString(32)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate oauth nonce: %w", err)
+	}
+	verifier, err := randomString(32)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate pkce verifier: %w", err)
+	}
+
+	authURL, err := p.AuthCodeURL(ctx, state, nonce, PKCEChallenge(verifier))
+	if err != nil {
+		return "", err
+	}
+
+	record := OAuthState{
+		TenantID:     tenant.ID(ctx),
+		Provider:     provider,
+		Nonce:        nonce,
+		CodeVerifier: verifier,
+		LinkUserID:   linkUserID,
+		CreatedAt:    time.Now(),
+	}
+	if err := m.store.Save(ctx, hashToken(state), record, m.stateTTL); err != nil {
+		return "", fmt.Errorf("failed to save oauth state: %w", err)
+	}
+	return authURL, nil
+}
+
+// Complete 处理回调：校验 state，用授权码换取令牌并返回第三方账户信息
+//
+// state 无论成功与否都只能使用一次；回调与发起授权的租户不一致时 state 无效，
+// 防止在一个租户发起的授权被用来登录或关联另一个租户的用户。
+func (m *OAuthManager) Complete(ctx context.Context, provider, state, code string) (ExternalIdentity, OAuthState, error) {
+	p, ok := m.providers[provider]
+	if !ok {
+		return ExternalIdentity{}, OAuthState{}, fmt.Errorf("%w: %s", ErrOAuthProviderNotFound, provider)
+	}
+
+	record, err := m.store.Consume(ctx, hashToken(state))
+	if err != nil {
+		if errors.Is(err, ErrOAuthStateNotFound) {
+			return ExternalIdentity{}, OAuthState{}, ErrOAuthStateInvalid
+		}
+		return ExternalIdentity{}, OAuthState{}, fmt.Errorf("failed to load oauth state: %w", err)
+	}
+	if record.Provider != provider || record.TenantID != tenant.ID(ctx) {
+		return ExternalIdentity{}, OAuthState{}, ErrOAuthStateInvalid
+	}
+
+	identity, err := p.Identity(ctx, code, record.CodeVerifier, record.Nonce)
+	if err != nil {
+		return ExternalIdentity{}, record, err
+	}
+	return identity, record, nil
+}
+
+// PKCEChallenge 计算 PKCE code_challenge（S256）
+func PKCEChallenge(verifier string) string {
+	sum := sha256.Sum256([]byte(verifier))
+	return encodeBase64URL(sum[:])
+}
@@ -0,0 +1,45 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryOAuthStateStore 基于内存的授权请求上下文存储（仅用于测试或单实例部署）
+type MemoryOAuthStateStore struct {
+	mu     sync.Mutex
+	states map[string]memoryEntry[OAuthState]
+}
+
+// NewMemoryOAuthStateStore 创建内存授权请求上下文存储
+func NewMemoryOAuthStateStore() *MemoryOAuthStateStore {
+	return &MemoryOAuthStateStore{
+		states: make(map[string]memoryEntry[OAuthState]),
+	}
+}
+
+// Save 实现 OAuthStateStore 接口
+func (s *MemoryOAuthStateStore) Save(_ context.Context, state string, record OAuthState, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.states[state] = memoryEntry[OAuthState]{value: record, expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+// Consume 实现 OAuthStateStore 接口
+func (s *MemoryOAuthStateStore) Consume(_ context.Context, state string) (OAuthState, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.states[state]
+	delete(s.states, state)
+	if !ok || entry.expired(time.Now()) {
+		return OAuthState{}, ErrOAuthStateNotFound
+	}
+	return entry.value, nil
+}
+
+// 确保 MemoryOAuthStateStore 实现了接口
+var _ OAuthStateStore = (*MemoryOAuthStateStore)(nil)
@@ -0,0 +1,461 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+const (
+	// OAuthProviderOIDC 标准 OpenID Connect 提供方（Google、Keycloak、Auth0 等）
+	OAuthProviderOIDC = "oidc"
+	// OAuthProviderGitHub GitHub（OAuth2，不签发 ID Token，通过 API 获取用户信息）
+	OAuthProviderGitHub = "github"
+
+	// oauthMaxResponseSize 提供方响应体大小上限
+	oauthMaxResponseSize = 1 << 20
+	// jwksCacheTTL JWKS 缓存有效期
+	jwksCacheTTL = time.Hour
+	// jwksMinRefreshInterval 遇到未知 kid 时重新拉取 JWKS 的最小间隔（防止被伪造 kid 刷爆）
+	jwksMinRefreshInterval = time.Minute
+)
+
+// OAuthProviderConfig 第三方登录提供方配置
+type OAuthProviderConfig struct {
+	Name         string // 提供方名称（出现在 URL 中，如 google）
+	Type         string // oidc / github
+	Issuer       string // OIDC Issuer（用于服务发现和校验 ID Token 的 iss）
+	ClientID     string
+	ClientSecret string
+	RedirectURL  string   // 回调地址（须与提供方后台登记的一致）
+	Scopes       []string // 为空时 oidc 使用 openid email profile，github 使用 read:user user:email
+
+	// 以下端点为空时：oidc 通过 {issuer}/.well-known/openid-configuration 获取，github 使用官方地址
+	AuthURL     string
+	TokenURL    string
+	JWKSURL     string
+	UserInfoURL string
+}
+
+// oidcDiscovery OpenID Provider 元数据
+type oidcDiscovery struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+	UserInfoEndpoint      string `json:"userinfo_endpoint"`
+}
+
+// OAuthToken 令牌端点响应
+type OAuthToken struct {
+	AccessToken      string `json:"access_token"`
+	TokenType        string `json:"token_type"`
+	IDToken          string `json:"id_token"`
+	Error            string `json:"error"`
+	ErrorDescription string `json:"error_description"`
+}
+
+// IDTokenClaims OIDC ID Token 载荷
+type IDTokenClaims struct {
+	Nonce             string    `json:"nonce"`
+	AuthorizedParty   string    `json:"azp,omitempty"`
+	Email             string    `json:"email,omitempty"`
+	EmailVerified     claimBool `json:"email_verified,omitempty"`
+	Name              string    `json:"name,omitempty"`
+	PreferredUsername string    `json:"preferred_username,omitempty"`
+	jwt.RegisteredClaims
+}
+
+// claimBool 兼容布尔值和字符串 "true"/"false" 的声明（部分提供方将 email_verified 编码为字符串）
+type claimBool bool
+
+// UnmarshalJSON 实现 json.Unmarshaler 接口
+func (b *claimBool) UnmarshalJSON(data []byte) error {
+	s := strings.Trim(string(data), `"`)
+	if s == "" || s == "null" {
+		*b = false
+		return nil
+	}
+	v, err := strconv.ParseBool(s)
+	if err != nil {
+		return fmt.Errorf("invalid boolean claim: %s", data)
+	}
+	*b = claimBool(v)
+	return nil
+}
+
+// OAuthProvider 第三方登录提供方客户端
+type OAuthProvider struct {
+	config     OAuthProviderConfig
+	httpClient *http.Client
+
+	mu         sync.Mutex
+	discovered bool            // oidc 端点是否已获取
+	jwks       map[string]*Key // kid -> 验证密钥
+	jwksAt     time.Time       // 最近一次拉取 JWKS 的时间
+}
+
+// NewOAuthProvider 创建第三方登录提供方客户端（httpClient 为 nil 时使用 10 秒超时的默认客户端）
+func NewOAuthProvider(config OAuthProviderConfig, httpClient *http.Client) (*OAuthProvider, error) {
+	if config.Name == "" || config.ClientID == "" || config.RedirectURL == "" {
+		return nil, fmt.Errorf("auth: oauth provider name, client_id and redirect_url are required")
+	}
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
+	switch config.Type {
+	case OAuthProviderOIDC:
+		if config.Issuer == "" {
+			return nil, fmt.Errorf("auth: oauth provider %s: issuer is required", config.Name)
+		}
+		if len(config.Scopes) == 0 {
+			config.Scopes = []string{"openid", "email", "profile"}
+		}
+	case OAuthProviderGitHub:
+		config.AuthURL = defaultString(config.AuthURL, "https://github.com/login/oauth/authorize")
+		config.TokenURL = defaultString(config.TokenURL, "https://github.com/login/oauth/access_token")
+		config.UserInfoURL = defaultString(config.UserInfoURL, "https://api.github.com/user")
+		if len(config.Scopes) == 0 {
+			config.Scopes = []string{"read:user", "user:email"}
+		}
+	default:
+		return nil, fmt.Errorf("auth: oauth provider %s: unsupported type %q", config.Name, config.Type)
+	}
+
+	return &OAuthProvider{
+		config:     config,
+		httpClient: httpClient,
+		// github 的端点都是固定的，不需要服务发现
+		discovered: config.Type == OAuthProviderGitHub,
+	}, nil
+}
+
+// Name 提供方名称
+func (p *OAuthProvider) Name() string {
+	return p.config.Name
+}
+
+// AuthCodeURL 生成授权地址
+func (p *OAuthProvider) AuthCodeURL(ctx context.Context, state, nonce, codeChallenge string) (string, error) {
+	if err := p.discover(ctx); err != nil {
+		return "", err
+	}
+
+	params := url.Values{
+		"response_type":         {"code"},
+		"client_id":             {p.config.ClientID},
+		"redirect_uri":          {p.config.RedirectURL},
+		"scope":                 {strings.Join(p.config.Scopes, " ")},
+		"state":                 {state},
+		"code_challenge":        {codeChallenge},
+		"code_challenge_method": {"S256"},
+	}
+	if p.config.Type == OAuthProviderOIDC {
+		params.Set("nonce", nonce)
+	}
+
+	sep := "?"
+	if strings.Contains(p.config.AuthURL, "?") {
+		sep = "&"
+	}
+	return p.config.AuthURL + sep + params.Encode(), nil
+}
+
+// Exchange 使用授权码和 PKCE code_verifier 换取令牌
+func (p *OAuthProvider) Exchange(ctx context.Context, code, codeVerifier string) (OAuthToken, error) {
+	var token OAuthToken
+	if err := p.discover(ctx); err != nil {
+		return token, err
+	}
+
+	form := url.Values{
+		"grant_type":    {"authorization_code"},
+		"code":          {code},
+		"redirect_uri":  {p.config.RedirectURL},
+		"client_id":     {p.config.ClientID},
+		"client_secret": {p.config.ClientSecret},
+		"code_verifier": {codeVerifier},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.config.TokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return token, fmt.Errorf("%w: %v", ErrOAuthExchangeFailed, err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json") // GitHub 默认返回表单格式
+
+	status, err := p.doJSON(req, &token)
+	if err != nil {
+		return token, fmt.Errorf("%w: %v", ErrOAuthExchangeFailed, err)
+	}
+	// GitHub 出错时同样返回 200，需要检查 error 字段
+	if status != http.StatusOK || token.Error != "" || token.AccessToken == "" {
+		return token, fmt.Errorf("%w: status %d: %s %s", ErrOAuthExchangeFailed, status, token.Error, token.ErrorDescription)
+	}
+	return token, nil
+}
+
+// VerifyIDToken 校验 ID Token 的签名（提供方 JWKS）、iss、aud、exp 和 nonce
+func (p *OAuthProvider) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (*IDTokenClaims, error) {
+	if err := p.discover(ctx); err != nil {
+		return nil, err
+	}
+
+	claims := &IDTokenClaims{}
+	keyfunc := func(token *jwt.Token) (any, error) {
+		kid, _ := token.Header["kid"].(string)
+		key, err := p.jwk(ctx, kid)
+		if err != nil {
+			return nil, err
+		}
+		// 防止算法混淆：Token 的 alg 必须与 JWK 的算法一致
+		if token.Method.Alg() != key.Method.Alg() {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		return key.verifyKey, nil
+	}
+
+	_, err := jwt.ParseWithClaims(rawIDToken, claims, keyfunc,
+		jwt.WithValidMethods([]string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}),
+		jwt.WithIssuer(p.config.Issuer),
+		jwt.WithAudience(p.config.ClientID),
+		jwt.WithExpirationRequired(),
+		jwt.WithLeeway(time.Minute),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrIDTokenInvalid, err)
+	}
+
+	if claims.Subject == "" {
+		return nil, fmt.Errorf("%w: missing sub", ErrIDTokenInvalid)
+	}
+	if nonce == "" || claims.Nonce != nonce {
+		return nil, fmt.Errorf("%w: nonce mismatch", ErrIDTokenInvalid)
+	}
+	// 多个受众时 azp 必须是本客户端（OIDC Core 3.1.3.7）
+	if len(claims.Audience) > 1 && claims.AuthorizedParty != p.config.ClientID {
+		return nil, fmt.Errorf("%w: unexpected authorized party %q", ErrIDTokenInvalid, claims.AuthorizedParty)
+	}
+	return claims, nil
+}
+
+// Identity 用授权码换取令牌并获取第三方账户信息
+//
+// oidc 提供方必须返回 ID Token 并通过校验；github 通过 API 获取用户信息和已验证的邮箱。
+func (p *OAuthProvider) Identity(ctx context.Context, code, codeVerifier, nonce string) (ExternalIdentity, error) {
+	token, err := p.Exchange(ctx, code, codeVerifier)
+	if err != nil {
+		return ExternalIdentity{}, err
+	}
+
+	if p.config.Type == OAuthProviderGitHub {
+		return p.githubIdentity(ctx, token.AccessToken)
+	}
+
+	if token.IDToken == "" {
+		return ExternalIdentity{}, fmt.Errorf("%w: token response has no id_token", ErrIDTokenInvalid)
+	}
+	claims, err := p.VerifyIDToken(ctx, token.IDToken, nonce)
+	if err != nil {
+		return ExternalIdentity{}, err
+	}
+	return ExternalIdentity{
+		Provider:      p.config.Name,
+		Subject:       claims.Subject,
+		Email:         claims.Email,
+		EmailVerified: bool(claims.EmailVerified),
+		Name:          claims.Name,
+		Username:      claims.PreferredUsername,
+	}, nil
+}
+
+// githubIdentity 通过 GitHub API 获取用户信息（邮箱取已验证的主邮箱）
+func (p *OAuthProvider) githubIdentity(ctx context.Context, accessToken string) (ExternalIdentity, error) {
+	var user struct {
+		ID    int64  `json:"id"`
+		Login string `json:"login"`
+		Name  string `json:"name"`
+	}
+	if err := p.getJSON(ctx, p.config.UserInfoURL, accessToken, &user); err != nil {
+		return ExternalIdentity{}, fmt.Errorf("auth: fetch github user: %w", err)
+	}
+	if user.ID == 0 {
+		return ExternalIdentity{}, fmt.Errorf("auth: github user response has no id")
+	}
+
+	var emails []struct {
+		Email    string `json:"email"`
+		Primary  bool   `json:"primary"`
+		Verified bool   `json:"verified"`
+	}
+	if err := p.getJSON(ctx, strings.TrimSuffix(p.config.UserInfoURL, "/")+"/emails", accessToken, &emails); err != nil {
+		return ExternalIdentity{}, fmt.Errorf("auth: fetch github emails: %w", err)
+	}
+
+	identity := ExternalIdentity{
+		Provider: p.config.Name,
+		Subject:  strconv.FormatInt(user.ID, 10),
+		Name:     user.Name,
+		Username: user.Login,
+	}
+	for _, e := range emails {
+		if e.Primary {
+			identity.Email = e.Email
+			identity.EmailVerified = e.Verified
+			break
+		}
+	}
+	return identity, nil
+}
+
+// discover 通过 OIDC 服务发现获取未配置的端点（成功后缓存，失败时下次重试）
+func (p *OAuthProvider) discover(ctx context.Context) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.discovered {
+		return nil
+	}
+	if p.config.AuthURL != "" && p.config.TokenURL != "" && p.config.JWKSURL != "" {
+		p.discovered = true
+		return nil
+	}
+
+	wellKnown := strings.TrimSuffix(p.config.Issuer, "/") + "/.well-known/openid-configuration"
+	var doc oidcDiscovery
+	if err := p.getJSON(ctx, wellKnown, "", &doc); err != nil {
+		return fmt.Errorf("auth: oidc discovery for %s: %w", p.config.Name, err)
+	}
+	// 防止元数据被篡改为其他 Issuer（OIDC Discovery 4.3）
+	if doc.Issuer != p.config.Issuer {
+		return fmt.Errorf("auth: oidc discovery for %s: issuer mismatch: %q", p.config.Name, doc.Issuer)
+	}
+
+	p.config.AuthURL = defaultString(p.config.AuthURL, doc.AuthorizationEndpoint)
+	p.config.TokenURL = defaultString(p.config.TokenURL, doc.TokenEndpoint)
+	p.config.JWKSURL = defaultString(p.config.JWKSURL, doc.JWKSURI)
+	p.config.UserInfoURL = defaultString(p.config.UserInfoURL, doc.UserInfoEndpoint)
+	if p.config.AuthURL == "" || p.config.TokenURL == "" || p.config.JWKSURL == "" {
+		return fmt.Errorf("auth: oidc discovery for %s: incomplete provider metadata", p.config.Name)
+	}
+	p.discovered = true
+	return nil
+}
+
+// jwk 按 kid 获取提供方的验证密钥（缓存过期或遇到未知 kid 时重新拉取 JWKS）
+func (p *OAuthProvider) jwk(ctx context.Context, kid string) (*Key, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	now := time.Now()
+	key, ok := p.lookupJWK(kid)
+	stale := now.Sub(p.jwksAt) > jwksCacheTTL
+	if ok && !stale {
+		return key, nil
+	}
+
+	if stale || now.Sub(p.jwksAt) > jwksMinRefreshInterval {
+		if err := p.refreshJWKS(ctx); err != nil {
+			// 拉取失败时继续使用缓存中的密钥
+			if ok {
+				return key, nil
+			}
+			return nil, err
+		}
+		p.jwksAt = now
+		if key, ok = p.lookupJWK(kid); ok {
+			return key, nil
+		}
+	}
+	return nil, fmt.Errorf("%w: %s", ErrUnknownKeyID, kid)
+}
+
+// lookupJWK 在缓存中查找密钥（没有 kid 时，只有唯一一个密钥才可使用）
+func (p *OAuthProvider) lookupJWK(kid string) (*Key, bool) {
+	if kid == "" {
+		if len(p.jwks) != 1 {
+			return nil, false
+		}
+		for _, key := range p.jwks {
+			return key, true
+		}
+	}
+	key, ok := p.jwks[kid]
+	return key, ok
+}
+
+// refreshJWKS 拉取提供方 JWKS（跳过无法解析或非签名用途的密钥）
+func (p *OAuthProvider) refreshJWKS(ctx context.Context) error {
+	var set JWKS
+	if err := p.getJSON(ctx, p.config.JWKSURL, "", &set); err != nil {
+		return fmt.Errorf("auth: fetch jwks for %s: %w", p.config.Name, err)
+	}
+
+	keys := make(map[string]*Key, len(set.Keys))
+	for _, jwk := range set.Keys {
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		key, err := ParseJWK(jwk)
+		if err != nil {
+			continue
+		}
+		keys[key.ID] = key
+	}
+	p.jwks = keys
+	return nil
+}
+
+// getJSON 发送 GET 请求并解析 JSON 响应（accessToken 非空时使用 Bearer 认证）
+func (p *OAuthProvider) getJSON(ctx context.Context, endpoint, accessToken string, v any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Accept", "application/json")
+	if accessToken != "" {
+		req.Header.Set("Authorization", "Bearer "+accessToken)
+	}
+
+	status, err := p.doJSON(req, v)
+	if err != nil {
+		return err
+	}
+	if status != http.StatusOK {
+		return fmt.Errorf("unexpected status %d from %s", status, endpoint)
+	}
+	return nil
+}
+
+// doJSON 发送请求并解析 JSON 响应，返回 HTTP 状态码
+func (p *OAuthProvider) doJSON(req *http.Request, v any) (int, error) {
+	resp, err := p.httpClient.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(io.LimitReader(resp.Body, oauthMaxResponseSize))
+	if err != nil {
+		return resp.StatusCode, err
+	}
+	if err := json.Unmarshal(body, v); err != nil {
+		var syntaxErr *json.SyntaxError
+		if errors.As(err, &syntaxErr) && resp.StatusCode != http.StatusOK {
+			// 非 JSON 的错误页面，只返回状态码
+			return resp.StatusCode, nil
+		}
+		return resp.StatusCode, fmt.Errorf("decode response from %s: %w", req.URL.Redacted(), err)
+	}
+	return resp.StatusCode, nil
+}
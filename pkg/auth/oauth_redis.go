@@ -0,0 +1,61 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisOAuthStateStore 基于 Redis 的授权请求上下文存储（多实例共享，回调可以落到任意实例）
+//
+// Key 布局:
+//   - {prefix}state:<hash>   授权请求上下文（JSON）
+type RedisOAuthStateStore struct {
+	rdb    redis.UniversalClient
+	prefix string
+}
+
+// NewRedisOAuthStateStore 创建 Redis 授权请求上下文存储
+func NewRedisOAuthStateStore(rdb redis.UniversalClient, prefix string) *RedisOAuthStateStore {
+	if prefix == "" {
+		prefix = "auth:oauth:"
+	}
+	return &RedisOAuthStateStore{
+		rdb:    rdb,
+		prefix: prefix,
+	}
+}
+
+// Save 实现 OAuthStateStore 接口
+func (s *RedisOAuthStateStore) Save(ctx context.Context, state string, record OAuthState, ttl time.Duration) error {
+	bs, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("marshal oauth state: %w", err)
+	}
+	return s.rdb.Set(ctx, s.prefix+"state:"+state, bs, ttl).Err()
+}
+
+// Consume 实现 OAuthStateStore 接口（GETDEL 保证并发回调中只有一次成功）
+func (s *RedisOAuthStateStore) Consume(ctx context.Context, state string) (OAuthState, error) {
+	var record OAuthState
+
+	val, err := s.rdb.GetDel(ctx, s.prefix+"state:"+state).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return record, ErrOAuthStateNotFound
+		}
+		return record, err
+	}
+
+	if err := json.Unmarshal(val, &record); err != nil {
+		return record, fmt.Errorf("unmarshal oauth state: %w", err)
+	}
+	return record, nil
+}
+
+// 确保 RedisOAuthStateStore 实现了接口
+var _ OAuthStateStore = (*RedisOAuthStateStore)(nil)
@@ -0,0 +1,372 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"testing"
+	"time"
+
+	"gin_demo/pkg/tenant"
+
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// stubAuthCode 桩提供方签发的授权码
+type stubAuthCode struct {
+	challenge string
+	nonce     string
+}
+
+// stubOIDCProvider 基于 httptest 的 OIDC 提供方桩（服务发现、令牌端点、JWKS）
+type stubOIDCProvider struct {
+	server    *httptest.Server
+	signing   *Key // 签发 ID Token 使用的密钥
+	published *Key // JWKS 中公布的密钥
+	subject   string
+	email     string
+	audience  string // 为空时使用请求中的 client_id
+
+	mu    sync.Mutex
+	codes map[string]stubAuthCode
+}
+
+// newStubOIDCProvider 创建 OIDC 提供方桩
+func newStubOIDCProvider(t *testing.T) *stubOIDCProvider {
+	t.Helper()
+
+	private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	signing, err := NewSigningKey("stub-key-1", "ES256", private)
+	require.NoError(t, err)
+
+	stub := &stubOIDCProvider{
+		signing:   signing,
+		published: signing,
+		subject:   "10769150350006150715113082367",
+		email:     "alice@example.com",
+		codes:     make(map[string]stubAuthCode),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, oidcDiscovery{
+			Issuer:                stub.server.URL,
+			AuthorizationEndpoint: stub.server.URL + "/authorize",
+			TokenEndpoint:         stub.server.URL + "/token",
+			JWKSURI:               stub.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		jwk, _ := stub.published.JWK()
+		writeJSON(w, http.StatusOK, JWKS{Keys: []JWK{jwk}})
+	})
+	mux.HandleFunc("/token", stub.token)
+
+	stub.server = httptest.NewServer(mux)
+	t.Cleanup(stub.server.Close)
+	return stub
+}
+
+// config 指向桩提供方的配置
+func (s *stubOIDCProvider) config() OAuthProviderConfig {
+	return OAuthProviderConfig{
+		Name:         "stub",
+		Type:         OAuthProviderOIDC,
+		Issuer:       s.server.URL,
+		ClientID:     "client-1",
+		ClientSecret: "client-secret",
+		RedirectURL:  "http://localhost:3000/oauth/callback",
+	}
+}
+
+// authorize 模拟用户在提供方同意授权，返回授权码
+func (s *stubOIDCProvider) authorize(t *testing.T, authURL string) (state, code string) {
+	t.Helper()
+
+	u, err := url.Parse(authURL)
+	require.NoError(t, err)
+	q := u.Query()
+	require.Equal(t, "S256", q.Get("code_challenge_method"))
+
+	code, err = randomString(16)
+	require.NoError(t, err)
+
+	s.mu.Lock()
+	s.codes[code] = stubAuthCode{challenge: q.Get("code_challenge"), nonce: q.Get("nonce")}
+	s.mu.Unlock()
+	return q.Get("state"), code
+}
+
+// token 令牌端点：校验授权码和 PKCE，签发 ID Token
+func (s *stubOIDCProvider) token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil || r.Form.Get("client_secret") != "client-secret" {
+		writeJSON(w, http.StatusUnauthorized, map[string]string{"error": "invalid_client"})
+		return
+	}
+
+	s.mu.Lock()
+	code, ok := s.codes[r.Form.Get("code")]
+	delete(s.codes, r.Form.Get("code"))
+	s.mu.Unlock()
+	if !ok || PKCEChallenge(r.Form.Get("code_verifier")) != code.challenge {
+		writeJSON(w, http.StatusBadRequest, map[string]string{"error": "invalid_grant"})
+		return
+	}
+
+	audience := s.audience
+	if audience == "" {
+		audience = r.Form.Get("client_id")
+	}
+	now := time.Now()
+	idToken := jwt.NewWithClaims(s.signing.Method, IDTokenClaims{
+		Nonce:         code.nonce,
+		Email:         s.email,
+		EmailVerified: true,
+		Name:          "Alice",
+		RegisteredClaims: jwt.RegisteredClaims{
+			Issuer:    s.server.URL,
+			Subject:   s.subject,
+			Audience:  jwt.ClaimStrings{audience},
+			IssuedAt:  jwt.NewNumericDate(now),
+			ExpiresAt: jwt.NewNumericDate(now.Add(time.Hour)),
+		},
+	})
+	idToken.Header["kid"] = s.signing.ID
+	raw, err := idToken.SignedString(s.signing.signKey)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]string{"error": err.Error()})
+		return
+	}
+
+	writeJSON(w, http.StatusOK, OAuthToken{AccessToken: "access-token", TokenType: "Bearer", IDToken: raw})
+}
+
+// writeJSON 输出 JSON 响应
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(v)
+}
+
+// TestOAuthManager_OIDC 测试 OIDC 授权码 + PKCE 流程
+func TestOAuthManager_OIDC(t *testing.T) {
+	ctx := context.Background()
+
+	setup := func(t *testing.T) (*stubOIDCProvider, *OAuthManager) {
+		stub := newStubOIDCProvider(t)
+		provider, err := NewOAuthProvider(stub.config(), stub.server.Client())
+		require.NoError(t, err)
+		return stub, NewOAuthManager(NewMemoryOAuthStateStore(), 10*time.Minute, provider)
+	}
+
+	t.Run("完整授权流程", func(t *testing.T) {
+		stub, manager := setup(t)
+
+		authURL, err := manager.AuthorizationURL(ctx, "stub", 0)
+		require.NoError(t, err)
+		assert.Contains(t, authURL, stub.server.URL+"/authorize?")
+
+		state, code := stub.authorize(t, authURL)
+		identity, record, err := manager.Complete(ctx, "stub", state, code)
+		require.NoError(t, err)
+		assert.Equal(t, ExternalIdentity{
+			Provider:      "stub",
+			Subject:       stub.subject,
+			Email:         "alice@example.com",
+			EmailVerified: true,
+			Name:          "Alice",
+		}, identity)
+		assert.Equal(t, int64(0), record.LinkUserID)
+		assert.Equal(t, tenant.DefaultID, record.TenantID)
+	})
+
+	t.Run("关联账户时返回发起用户", func(t *testing.T) {
+		stub, manager := setup(t)
+
+		authURL, err := manager.AuthorizationURL(ctx, "stub", 42)
+		require.NoError(t, err)
+
+		state, code := stub.authorize(t, authURL)
+		_, record, err := manager.Complete(ctx, "stub", state, code)
+		require.NoError(t, err)
+		assert.Equal(t, int64(42), record.LinkUserID)
+	})
+
+	t.Run("state 只能使用一次", func(t *testing.T) {
+		stub, manager := setup(t)
+
+		authURL, err := manager.AuthorizationURL(ctx, "stub", 0)
+		require.NoError(t, err)
+		state, code := stub.authorize(t, authURL)
+
+		_, _, err = manager.Complete(ctx, "stub", state, code)
+		require.NoError(t, err)
+
+		_, _, err = manager.Complete(ctx, "stub", state, code)
+		assert.ErrorIs(t, err, ErrOAuthStateInvalid)
+	})
+
+	t.Run("回调租户与发起授权的租户不一致", func(t *testing.T) {
+		stub, manager := setup(t)
+
+		authURL, err := manager.AuthorizationURL(tenant.WithID(ctx, 2), "stub", 0)
+		require.NoError(t, err)
+		state, code := stub.authorize(t, authURL)
+
+		_, _, err = manager.Complete(tenant.WithID(ctx, 3), "stub", state, code)
+		assert.ErrorIs(t, err, ErrOAuthStateInvalid)
+	})
+
+	t.Run("伪造的 state", func(t *testing.T) {
+		_, manager := setup(t)
+
+		_, _, err := manager.Complete(ctx, "stub", "forged", "code")
+		assert.ErrorIs(t, err, ErrOAuthStateInvalid)
+	})
+
+	t.Run("未配置的提供方", func(t *testing.T) {
+		_, manager := setup(t)
+
+		_, err := manager.AuthorizationURL(ctx, "unknown", 0)
+		assert.ErrorIs(t, err, ErrOAuthProviderNotFound)
+	})
+
+	t.Run("PKCE 校验失败", func(t *testing.T) {
+		stub, manager := setup(t)
+
+		authURL, err := manager.AuthorizationURL(ctx, "stub", 0)
+		require.NoError(t, err)
+		state, code := stub.authorize(t, authURL)
+
+		// 模拟授权码被截获后由攻击者发起的授权请求使用
+		stub.codes[code] = stubAuthCode{challenge: PKCEChallenge("attacker-verifier")}
+
+		_, _, err = manager.Complete(ctx, "stub", state, code)
+		assert.ErrorIs(t, err, ErrOAuthExchangeFailed)
+	})
+
+	t.Run("nonce 不匹配", func(t *testing.T) {
+		stub, manager := setup(t)
+
+		authURL, err := manager.AuthorizationURL(ctx, "stub", 0)
+		require.NoError(t, err)
+		state, code := stub.authorize(t, authURL)
+		entry := stub.codes[code]
+		entry.nonce = "replayed-nonce"
+		stub.codes[code] = entry
+
+		_, _, err = manager.Complete(ctx, "stub", state, code)
+		assert.ErrorIs(t, err, ErrIDTokenInvalid)
+	})
+
+	t.Run("受众不是本客户端", func(t *testing.T) {
+		stub, manager := setup(t)
+		stub.audience = "another-client"
+
+		authURL, err := manager.AuthorizationURL(ctx, "stub", 0)
+		require.NoError(t, err)
+		state, code := stub.authorize(t, authURL)
+
+		_, _, err = manager.Complete(ctx, "stub", state, code)
+		assert.ErrorIs(t, err, ErrIDTokenInvalid)
+	})
+
+	t.Run("签名密钥不在 JWKS 中", func(t *testing.T) {
+		stub, manager := setup(t)
+
+		authURL, err := manager.AuthorizationURL(ctx, "stub", 0)
+		require.NoError(t, err)
+		state, code := stub.authorize(t, authURL)
+
+		// 使用未公布的密钥签发 ID Token
+		private, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		require.NoError(t, err)
+		stub.signing, err = NewSigningKey("rogue-key", "ES256", private)
+		require.NoError(t, err)
+
+		_, _, err = manager.Complete(ctx, "stub", state, code)
+		assert.ErrorIs(t, err, ErrIDTokenInvalid)
+	})
+}
+
+// TestOAuthManager_GitHub 测试 GitHub 授权流程（无 ID Token，通过 API 获取用户信息）
+func TestOAuthManager_GitHub(t *testing.T) {
+	ctx := context.Background()
+
+	var verifier string
+	mux := http.NewServeMux()
+	mux.HandleFunc("/login/oauth/access_token", func(w http.ResponseWriter, r *http.Request) {
+		require.NoError(t, r.ParseForm())
+		verifier = r.Form.Get("code_verifier")
+		if r.Form.Get("code") != "good-code" {
+			// GitHub 出错时同样返回 200
+			writeJSON(w, http.StatusOK, map[string]string{"error": "bad_verification_code"})
+			return
+		}
+		writeJSON(w, http.StatusOK, OAuthToken{AccessToken: "gho_token", TokenType: "bearer"})
+	})
+	mux.HandleFunc("/user", func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "Bearer gho_token", r.Header.Get("Authorization"))
+		writeJSON(w, http.StatusOK, map[string]any{"id": 583231, "login": "octocat", "name": "The Octocat", "email": nil})
+	})
+	mux.HandleFunc("/user/emails", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, []map[string]any{
+			{"email": "octocat@users.noreply.github.com", "primary": false, "verified": true},
+			{"email": "octocat@github.com", "primary": true, "verified": true},
+		})
+	})
+	server := httptest.NewServer(mux)
+	defer server.Close()
+
+	provider, err := NewOAuthProvider(OAuthProviderConfig{
+		Name:         "github",
+		Type:         OAuthProviderGitHub,
+		ClientID:     "client-1",
+		ClientSecret: "client-secret",
+		RedirectURL:  "http://localhost:3000/oauth/callback",
+		AuthURL:      server.URL + "/login/oauth/authorize",
+		TokenURL:     server.URL + "/login/oauth/access_token",
+		UserInfoURL:  server.URL + "/user",
+	}, server.Client())
+	require.NoError(t, err)
+	manager := NewOAuthManager(NewMemoryOAuthStateStore(), 10*time.Minute, provider)
+
+	t.Run("获取用户信息和主邮箱", func(t *testing.T) {
+		authURL, err := manager.AuthorizationURL(ctx, "github", 0)
+		require.NoError(t, err)
+		u, err := url.Parse(authURL)
+		require.NoError(t, err)
+		assert.Empty(t, u.Query().Get("nonce"))
+
+		identity, _, err := manager.Complete(ctx, "github", u.Query().Get("state"), "good-code")
+		require.NoError(t, err)
+		assert.Equal(t, u.Query().Get("code_challenge"), PKCEChallenge(verifier))
+		assert.Equal(t, ExternalIdentity{
+			Provider:      "github",
+			Subject:       "583231",
+			Email:         "octocat@github.com",
+			EmailVerified: true,
+			Name:          "The Octocat",
+			Username:      "octocat",
+		}, identity)
+	})
+
+	t.Run("授权码无效", func(t *testing.T) {
+		authURL, err := manager.AuthorizationURL(ctx, "github", 0)
+		require.NoError(t, err)
+		u, err := url.Parse(authURL)
+		require.NoError(t, err)
+
+		_, _, err = manager.Complete(ctx, "github", u.Query().Get("state"), "bad-code")
+		assert.ErrorIs(t, err, ErrOAuthExchangeFailed)
+	})
+}
@@ -0,0 +1,60 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// ErrOneTimeTokenInvalid 一次性令牌格式错误或签名不匹配
+var ErrOneTimeTokenInvalid = errors.New("invalid one-time token")
+
+// OneTimeTokenSigner 一次性令牌签名器（邮箱验证、密码重置等邮件链接中的令牌）
+//
+// 令牌格式为 "随机值.签名"，签名绑定令牌用途，验证邮箱的令牌不能用于重置密码。
+// 签名只用于在查库前拒绝伪造的令牌；有效期和"只能使用一次"由调用方保存的令牌哈希保证。
+type OneTimeTokenSigner struct {
+	secret []byte
+}
+
+// NewOneTimeTokenSigner 创建一次性令牌签名器
+func NewOneTimeTokenSigner(secret []byte) *OneTimeTokenSigner {
+	return &OneTimeTokenSigner{secret: secret}
+}
+
+// Issue 签发一次性令牌，返回令牌明文（发给用户）和令牌哈希（保存到存储）
+func (s *OneTimeTokenSigner) Issue(purpose string) (token, tokenHash string, err error) {
+	nonce, err := randomString(32)
+	if err != nil {
+		return "", "", fmt.Errorf("failed to generate token: %w", err)
+	}
+
+	token = nonce + "." + s.sign(purpose, nonce)
+	return token, hashToken(token), nil
+}
+
+// Verify 校验令牌签名，返回令牌哈希（用于查询存储）
+func (s *OneTimeTokenSigner) Verify(purpose, token string) (string, error) {
+	nonce, signature, ok := strings.Cut(token, ".")
+	if !ok || nonce == "" || signature == "" {
+		return "", ErrOneTimeTokenInvalid
+	}
+
+	if !hmac.Equal([]byte(signature), []byte(s.sign(purpose, nonce))) {
+		return "", ErrOneTimeTokenInvalid
+	}
+
+	return hashToken(token), nil
+}
+
+// sign 计算 HMAC-SHA256(purpose + ":" + nonce)
+func (s *OneTimeTokenSigner) sign(purpose, nonce string) string {
+	mac := hmac.New(sha256.New, s.secret)
+	mac.Write([]byte(purpose))
+	mac.Write([]byte{':'})
+	mac.Write([]byte(nonce))
+	return base64.RawURLEncoding.EncodeToString(mac.Sum(nil))
+}
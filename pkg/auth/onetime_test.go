@@ -0,0 +1,46 @@
+package auth
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestOneTimeTokenSigner 测试一次性令牌签名
+func TestOneTimeTokenSigner(t *testing.T) {
+	signer := NewOneTimeTokenSigner([]byte("test-secret"))
+
+	t.Run("签发后校验通过", func(t *testing.T) {
+		token, tokenHash, err := signer.Issue("verify_email")
+		require.NoError(t, err)
+		assert.NotContains(t, tokenHash, token)
+
+		got, err := signer.Verify("verify_email", token)
+		require.NoError(t, err)
+		assert.Equal(t, tokenHash, got)
+	})
+
+	t.Run("用途不匹配", func(t *testing.T) {
+		token, _, err := signer.Issue("verify_email")
+		require.NoError(t, err)
+
+		_, err = signer.Verify("reset_password", token)
+		assert.ErrorIs(t, err, ErrOneTimeTokenInvalid)
+	})
+
+	t.Run("密钥不匹配", func(t *testing.T) {
+		token, _, err := NewOneTimeTokenSigner([]byte("other-secret")).Issue("verify_email")
+		require.NoError(t, err)
+
+		_, err = signer.Verify("verify_email", token)
+		assert.ErrorIs(t, err, ErrOneTimeTokenInvalid)
+	})
+
+	t.Run("格式错误", func(t *testing.T) {
+		for _, token := range []string{"", "abc", "abc.", ".abc", "a.b.c"} {
+			_, err := signer.Verify("verify_email", token)
+			assert.ErrorIs(t, err, ErrOneTimeTokenInvalid, token)
+		}
+	})
+}
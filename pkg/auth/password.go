@@ -0,0 +1,286 @@
+package auth
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// 密码哈希算法
+const (
+	PasswordAlgorithmBcrypt   = "bcrypt"
+	PasswordAlgorithmArgon2id = "argon2id"
+)
+
+// ErrInvalidPasswordHash 密码哈希格式错误（无法识别的算法或参数）
+var ErrInvalidPasswordHash = errors.New("invalid password hash")
+
+// PasswordHasher 密码哈希器
+//
+// 哈希值自带算法和参数（PHC 字符串格式，bcrypt 为兼容已有数据保留其原生的 "$2a$cost$..." 格式），
+// 调整算法或参数后旧哈希仍能校验，并可通过 NeedsRehash 判断是否需要升级。
+type PasswordHasher interface {
+	// Hash 计算密码哈希
+	Hash(password string) (string, error)
+
+	// Verify 校验密码，密码不匹配时返回 false 和 nil；
+	// encoded 为空（无密码账户）时任何密码都不匹配
+	Verify(password, encoded string) (bool, error)
+
+	// NeedsRehash 判断哈希是否使用了过时的算法或参数（应在登录成功后用明文密码重新计算）
+	NeedsRehash(encoded string) bool
+}
+
+// ========================================
+// bcrypt
+// ========================================
+
+// BcryptHasher bcrypt 哈希器
+type BcryptHasher struct {
+	cost int
+}
+
+// NewBcryptHasher 创建 bcrypt 哈希器（cost 为 0 时使用 bcrypt.DefaultCost）
+func NewBcryptHasher(cost int) (*BcryptHasher, error) {
+	if cost == 0 {
+		cost = bcrypt.DefaultCost
+	}
+	if cost < bcrypt.MinCost || cost > bcrypt.MaxCost {
+		return nil, fmt.Errorf("bcrypt cost must be between %d and %d", bcrypt.MinCost, bcrypt.MaxCost)
+	}
+	return &BcryptHasher{cost: cost}, nil
+}
+
+// Hash 计算密码哈希
+func (h *BcryptHasher) Hash(password string) (string, error) {
+	hashed, err := bcrypt.GenerateFromPassword([]byte(password), h.cost)
+	if err != nil {
+		return "", fmt.Errorf("failed to hash password: %w", err)
+	}
+	return string(hashed), nil
+}
+
+// Verify 校验密码
+func (h *BcryptHasher) Verify(password, encoded string) (bool, error) {
+	if encoded == "" {
+		return false, nil
+	}
+	err := bcrypt.CompareHashAndPassword([]byte(encoded), []byte(password))
+	if errors.Is(err, bcrypt.ErrMismatchedHashAndPassword) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("%w: %v", ErrInvalidPasswordHash, err)
+	}
+	return true, nil
+}
+
+// NeedsRehash 判断哈希是否不是 bcrypt 或 cost 与当前配置不同
+func (h *BcryptHasher) NeedsRehash(encoded string) bool {
+	if !isBcryptHash(encoded) {
+		return true
+	}
+	cost, err := bcrypt.Cost([]byte(encoded))
+	return err != nil || cost != h.cost
+}
+
+// isBcryptHash 判断是否为 bcrypt 哈希（$2a$、$2b$、$2y$）
+func isBcryptHash(encoded string) bool {
+	return strings.HasPrefix(encoded, "$2a$") ||
+		strings.HasPrefix(encoded, "$2b$") ||
+		strings.HasPrefix(encoded, "$2y$")
+}
+
+// ========================================
+// Argon2id
+// ========================================
+
+// Argon2idParams Argon2id 参数
+type Argon2idParams struct {
+	Memory      uint32 // 内存（KiB）
+	Iterations  uint32 // 迭代次数
+	Parallelism uint8  // 并行度
+	SaltLength  uint32 // 盐长度（字节）
+	KeyLength   uint32 // 哈希长度（字节）
+}
+
+// DefaultArgon2idParams 默认参数（OWASP 推荐的最低配置：19 MiB 内存、2 次迭代、1 个线程）
+func DefaultArgon2idParams() Argon2idParams {
+	return Argon2idParams{
+		Memory:      19 * 1024,
+		Iterations:  2,
+		Parallelism: 1,
+		SaltLength:  16,
+		KeyLength:   32,
+	}
+}
+
+// Argon2idHasher Argon2id 哈希器
+//
+// 哈希格式：$argon2id$v=19$m=19456,t=2,p=1$<盐>$<哈希>（盐和哈希为无填充的标准 Base64）
+type Argon2idHasher struct {
+	params Argon2idParams
+}
+
+// NewArgon2idHasher 创建 Argon2id 哈希器（盐长度和哈希长度为 0 时使用默认值）
+func NewArgon2idHasher(params Argon2idParams) (*Argon2idHasher, error) {
+	defaults := DefaultArgon2idParams()
+	if params.SaltLength == 0 {
+		params.SaltLength = defaults.SaltLength
+	}
+	if params.KeyLength == 0 {
+		params.KeyLength = defaults.KeyLength
+	}
+	if params.Iterations == 0 || params.Parallelism == 0 {
+		return nil, errors.New("argon2id iterations and parallelism must be positive")
+	}
+	if params.Memory < 8*uint32(params.Parallelism) {
+		return nil, errors.New("argon2id memory must be at least 8 KiB per thread")
+	}
+	return &Argon2idHasher{params: params}, nil
+}
+
+// Hash 计算密码哈希
+func (h *Argon2idHasher) Hash(password string) (string, error) {
+	salt := make([]byte, h.params.SaltLength)
+	if _, err := rand.Read(salt); err != nil {
+		return "", fmt.Errorf("failed to generate salt: %w", err)
+	}
+
+	key := argon2.IDKey([]byte(password), salt, h.params.Iterations, h.params.Memory, h.params.Parallelism, h.params.KeyLength)
+	return fmt.Sprintf("$argon2id$v=%d$m=%d,t=%d,p=%d$%s$%s",
+		argon2.Version, h.params.Memory, h.params.Iterations, h.params.Parallelism,
+		base64.RawStdEncoding.EncodeToString(salt),
+		base64.RawStdEncoding.EncodeToString(key),
+	), nil
+}
+
+// Verify 校验密码（使用哈希中记录的参数）
+func (h *Argon2idHasher) Verify(password, encoded string) (bool, error) {
+	if encoded == "" {
+		return false, nil
+	}
+	params, salt, key, err := decodeArgon2id(encoded)
+	if err != nil {
+		return false, err
+	}
+
+	actual := argon2.IDKey([]byte(password), salt, params.Iterations, params.Memory, params.Parallelism, params.KeyLength)
+	return subtle.ConstantTimeCompare(actual, key) == 1, nil
+}
+
+// NeedsRehash 判断哈希是否不是 Argon2id 或参数与当前配置不同
+func (h *Argon2idHasher) NeedsRehash(encoded string) bool {
+	params, _, _, err := decodeArgon2id(encoded)
+	if err != nil {
+		return true
+	}
+	return params != h.params
+}
+
+// decodeArgon2id 解析 Argon2id PHC 字符串
+func decodeArgon2id(encoded string) (params Argon2idParams, salt, key []byte, err error) {
+	// "", "argon2id", "v=19", "m=...,t=...,p=...", 盐, 哈希
+	parts := strings.Split(encoded, "$")
+	if len(parts) != 6 || parts[0] != "" || parts[1] != PasswordAlgorithmArgon2id {
+		return params, nil, nil, ErrInvalidPasswordHash
+	}
+
+	var version int
+	if _, err := fmt.Sscanf(parts[2], "v=%d", &version); err != nil || version != argon2.Version {
+		return params, nil, nil, fmt.Errorf("%w: unsupported argon2 version", ErrInvalidPasswordHash)
+	}
+	if _, err := fmt.Sscanf(parts[3], "m=%d,t=%d,p=%d", &params.Memory, &params.Iterations, &params.Parallelism); err != nil {
+		return params, nil, nil, fmt.Errorf("%w: %v", ErrInvalidPasswordHash, err)
+	}
+	if params.Iterations == 0 || params.Parallelism == 0 {
+		return params, nil, nil, ErrInvalidPasswordHash
+	}
+
+	salt, err = base64.RawStdEncoding.DecodeString(parts[4])
+	if err != nil || len(salt) == 0 {
+		return params, nil, nil, ErrInvalidPasswordHash
+	}
+	key, err = base64.RawStdEncoding.DecodeString(parts[5])
+	if err != nil || len(key) == 0 {
+		return params, nil, nil, ErrInvalidPasswordHash
+	}
+
+	params.SaltLength = uint32(len(salt))
+	params.KeyLength = uint32(len(key))
+	return params, salt, key, nil
+}
+
+// ========================================
+// 按配置选择算法
+// ========================================
+
+// PasswordHashConfig 密码哈希配置
+type PasswordHashConfig struct {
+	Algorithm  string         // 新密码使用的算法（PasswordAlgorithmBcrypt 或 PasswordAlgorithmArgon2id）
+	BcryptCost int            // bcrypt cost（0 表示 bcrypt.DefaultCost）
+	Argon2id   Argon2idParams // Argon2id 参数
+}
+
+// passwordHasher 用配置的算法计算新哈希，按哈希前缀选择算法校验已有哈希
+type passwordHasher struct {
+	current  PasswordHasher
+	bcrypt   *BcryptHasher
+	argon2id *Argon2idHasher
+}
+
+// NewPasswordHasher 创建密码哈希器
+//
+// 新密码使用配置的算法和参数；两种算法的已有哈希都能校验，
+// 算法或参数与配置不同的哈希 NeedsRehash 返回 true。
+func NewPasswordHasher(config PasswordHashConfig) (PasswordHasher, error) {
+	h := &passwordHasher{}
+
+	var err error
+	if h.bcrypt, err = NewBcryptHasher(config.BcryptCost); err != nil {
+		return nil, err
+	}
+	if h.argon2id, err = NewArgon2idHasher(config.Argon2id); err != nil {
+		return nil, err
+	}
+
+	switch config.Algorithm {
+	case PasswordAlgorithmBcrypt:
+		h.current = h.bcrypt
+	case PasswordAlgorithmArgon2id:
+		h.current = h.argon2id
+	default:
+		return nil, fmt.Errorf("unsupported password hash algorithm %q", config.Algorithm)
+	}
+	return h, nil
+}
+
+// Hash 使用配置的算法计算密码哈希
+func (h *passwordHasher) Hash(password string) (string, error) {
+	return h.current.Hash(password)
+}
+
+// Verify 按哈希前缀选择算法校验密码
+func (h *passwordHasher) Verify(password, encoded string) (bool, error) {
+	switch {
+	case encoded == "":
+		return false, nil
+	case isBcryptHash(encoded):
+		return h.bcrypt.Verify(password, encoded)
+	case strings.HasPrefix(encoded, "$"+PasswordAlgorithmArgon2id+"$"):
+		return h.argon2id.Verify(password, encoded)
+	default:
+		return false, ErrInvalidPasswordHash
+	}
+}
+
+// NeedsRehash 判断哈希的算法或参数是否与配置不同
+func (h *passwordHasher) NeedsRehash(encoded string) bool {
+	return h.current.NeedsRehash(encoded)
+}
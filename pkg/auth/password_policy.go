@@ -0,0 +1,199 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// 密码不符合策略的原因（PasswordViolation.Code）
+const (
+	PasswordTooShort          = "too_short"
+	PasswordTooLong           = "too_long"
+	PasswordMissingUpper      = "missing_upper"
+	PasswordMissingLower      = "missing_lower"
+	PasswordMissingDigit      = "missing_digit"
+	PasswordMissingSymbol     = "missing_symbol"
+	PasswordBannedWord        = "banned_word"
+	PasswordSimilarToUsername = "similar_to_username"
+	PasswordSimilarToEmail    = "similar_to_email"
+	PasswordBreached          = "breached"
+	PasswordReused            = "reused"
+)
+
+// PasswordViolation 密码不符合策略的原因
+type PasswordViolation struct {
+	Code    string // 原因（机器可读）
+	Message string // 提示信息
+}
+
+// PasswordPolicyConfig 密码策略配置
+type PasswordPolicyConfig struct {
+	MinLength     int      // 最短长度（按字符计）
+	MaxLength     int      // 最长长度（0 表示不限制）
+	RequireUpper  bool     // 必须包含大写字母
+	RequireLower  bool     // 必须包含小写字母
+	RequireDigit  bool     // 必须包含数字
+	RequireSymbol bool     // 必须包含符号
+	BannedWords   []string // 禁止包含的词（不区分大小写）
+	MaxSimilarity float64  // 与用户名、邮箱的最大相似度（0-1，0 表示不检查）
+	BreachedMin   int      // 在泄露密码库中出现的次数达到该值时拒绝（0 表示不检查）
+}
+
+// PasswordOwner 密码所属用户（用于相似度检查，字段为空时不检查）
+type PasswordOwner struct {
+	Username string
+	Email    string
+}
+
+// PasswordPolicy 密码策略
+//
+// 检查长度、字符类型、禁用词、与用户名和邮箱的相似度，以及是否出现在泄露密码库中。
+// 禁止重复使用历史密码需要查询存储，由调用方检查（原因为 PasswordReused）。
+type PasswordPolicy struct {
+	config   PasswordPolicyConfig
+	breached BreachedPasswordSource
+}
+
+// NewPasswordPolicy 创建密码策略（breached 为 nil 时不检查泄露密码）
+func NewPasswordPolicy(config PasswordPolicyConfig, breached BreachedPasswordSource) *PasswordPolicy {
+	banned := make([]string, 0, len(config.BannedWords))
+	for _, word := range config.BannedWords {
+		if word = strings.ToLower(strings.TrimSpace(word)); word != "" {
+			banned = append(banned, word)
+		}
+	}
+	config.BannedWords = banned
+
+	return &PasswordPolicy{config: config, breached: breached}
+}
+
+// Check 检查密码，返回所有不符合的原因（符合策略时为空）
+// 只有查询泄露密码库失败时返回 error，此时其他检查的结果仍然有效
+func (p *PasswordPolicy) Check(ctx context.Context, password string, owner PasswordOwner) ([]PasswordViolation, error) {
+	var violations []PasswordViolation
+	add := func(code, message string) {
+		violations = append(violations, PasswordViolation{Code: code, Message: message})
+	}
+
+	length := utf8.RuneCountInString(password)
+	if length < p.config.MinLength {
+		add(PasswordTooShort, fmt.Sprintf("密码长度不能少于 %d 个字符", p.config.MinLength))
+	}
+	if p.config.MaxLength > 0 && length > p.config.MaxLength {
+		add(PasswordTooLong, fmt.Sprintf("密码长度不能超过 %d 个字符", p.config.MaxLength))
+		// 过长的密码不再做后续检查（相似度计算与长度相关）
+		return violations, nil
+	}
+
+	var hasUpper, hasLower, hasDigit, hasSymbol bool
+	for _, r := range password {
+		switch {
+		case unicode.IsUpper(r):
+			hasUpper = true
+		case unicode.IsLower(r):
+			hasLower = true
+		case unicode.IsDigit(r):
+			hasDigit = true
+		case unicode.IsPunct(r) || unicode.IsSymbol(r) || unicode.IsSpace(r):
+			hasSymbol = true
+		}
+	}
+	if p.config.RequireUpper && !hasUpper {
+		add(PasswordMissingUpper, "密码必须包含大写字母")
+	}
+	if p.config.RequireLower && !hasLower {
+		add(PasswordMissingLower, "密码必须包含小写字母")
+	}
+	if p.config.RequireDigit && !hasDigit {
+		add(PasswordMissingDigit, "密码必须包含数字")
+	}
+	if p.config.RequireSymbol && !hasSymbol {
+		add(PasswordMissingSymbol, "密码必须包含符号")
+	}
+
+	lower := strings.ToLower(password)
+	for _, word := range p.config.BannedWords {
+		if strings.Contains(lower, word) {
+			add(PasswordBannedWord, "密码包含常见的弱密码词")
+			break
+		}
+	}
+
+	if p.config.MaxSimilarity > 0 {
+		if similar(lower, owner.Username, p.config.MaxSimilarity) {
+			add(PasswordSimilarToUsername, "密码与用户名过于相似")
+		}
+		local, _, _ := strings.Cut(owner.Email, "@")
+		if similar(lower, local, p.config.MaxSimilarity) {
+			add(PasswordSimilarToEmail, "密码与邮箱过于相似")
+		}
+	}
+
+	if p.breached != nil && p.config.BreachedMin > 0 && password != "" {
+		count, err := BreachedCount(ctx, p.breached, password)
+		if err != nil {
+			return violations, err
+		}
+		if count >= p.config.BreachedMin {
+			add(PasswordBreached, "该密码已在公开的数据泄露中出现，请更换")
+		}
+	}
+
+	return violations, nil
+}
+
+// BreachedCount 查询密码在泄露密码库中出现的次数（k-匿名：只用 SHA-1 的前 5 位查询）
+func BreachedCount(ctx context.Context, source BreachedPasswordSource, password string) (int, error) {
+	sum := sha1.Sum([]byte(password))
+	hash := strings.ToUpper(hex.EncodeToString(sum[:]))
+
+	suffixes, err := source.Range(ctx, hash[:5])
+	if err != nil {
+		return 0, fmt.Errorf("failed to query breached passwords: %w", err)
+	}
+	return suffixes[hash[5:]], nil
+}
+
+// similar 判断密码与用户信息是否过于相似（已转为小写的密码；少于 3 个字符的用户信息不检查）
+//
+// 互相包含视为相似；否则按编辑距离计算相似度 1 - distance / max(len)。
+func similar(password, identity string, threshold float64) bool {
+	identity = strings.ToLower(identity)
+	if utf8.RuneCountInString(identity) < 3 || password == "" {
+		return false
+	}
+	if strings.Contains(password, identity) || strings.Contains(identity, password) {
+		return true
+	}
+
+	a, b := []rune(password), []rune(identity)
+	longest := max(len(a), len(b))
+	return 1-float64(levenshtein(a, b))/float64(longest) >= threshold
+}
+
+// levenshtein 计算编辑距离
+func levenshtein(a, b []rune) int {
+	prev := make([]int, len(b)+1)
+	curr := make([]int, len(b)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+
+	for i := 1; i <= len(a); i++ {
+		curr[0] = i
+		for j := 1; j <= len(b); j++ {
+			cost := 1
+			if a[i-1] == b[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(b)]
+}
@@ -0,0 +1,176 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha1"
+	"encoding/hex"
+	"errors"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// violationCodes 提取违规原因
+func violationCodes(violations []PasswordViolation) []string {
+	codes := make([]string, 0, len(violations))
+	for _, v := range violations {
+		codes = append(codes, v.Code)
+	}
+	return codes
+}
+
+// sha1Hex 计算大写十六进制 SHA-1
+func sha1Hex(s string) string {
+	sum := sha1.Sum([]byte(s))
+	return strings.ToUpper(hex.EncodeToString(sum[:]))
+}
+
+// writeBreachedFile 写入按哈希排序的泄露密码文件
+func writeBreachedFile(t *testing.T, passwords map[string]string) string {
+	t.Helper()
+
+	lines := make([]string, 0, len(passwords))
+	for password, count := range passwords {
+		lines = append(lines, sha1Hex(password)+count)
+	}
+	// 补充一些其他哈希，确保二分查找跨越多行
+	for i := range 200 {
+		lines = append(lines, sha1Hex(strings.Repeat("x", i+1))+":1")
+	}
+	sort.Strings(lines)
+
+	path := filepath.Join(t.TempDir(), "pwned-passwords.txt")
+	require.NoError(t, os.WriteFile(path, []byte(strings.Join(lines, "\r\n")), 0o600))
+	return path
+}
+
+// TestPasswordPolicy 测试密码策略
+func TestPasswordPolicy(t *testing.T) {
+	ctx := context.Background()
+	policy := NewPasswordPolicy(PasswordPolicyConfig{
+		MinLength:     8,
+		MaxLength:     64,
+		RequireUpper:  true,
+		RequireLower:  true,
+		RequireDigit:  true,
+		RequireSymbol: true,
+		BannedWords:   []string{" Password ", "qwerty", ""},
+		MaxSimilarity: 0.7,
+	}, nil)
+
+	t.Run("符合策略", func(t *testing.T) {
+		violations, err := policy.Check(ctx, "Correct-Horse-7", PasswordOwner{Username: "alice", Email: "alice@example.com"})
+		require.NoError(t, err)
+		assert.Empty(t, violations)
+	})
+
+	t.Run("长度和字符类型", func(t *testing.T) {
+		violations, err := policy.Check(ctx, "abc", PasswordOwner{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{PasswordTooShort, PasswordMissingUpper, PasswordMissingDigit, PasswordMissingSymbol}, violationCodes(violations))
+
+		violations, err = policy.Check(ctx, strings.Repeat("Aa1!", 20), PasswordOwner{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{PasswordTooLong}, violationCodes(violations))
+
+		// 按字符计算长度
+		violations, err = policy.Check(ctx, "密Aa1!码", PasswordOwner{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{PasswordTooShort}, violationCodes(violations))
+	})
+
+	t.Run("禁用词不区分大小写", func(t *testing.T) {
+		violations, err := policy.Check(ctx, "MyPASSWORD-2026", PasswordOwner{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{PasswordBannedWord}, violationCodes(violations))
+	})
+
+	t.Run("与用户名和邮箱相似", func(t *testing.T) {
+		owner := PasswordOwner{Username: "JohnSmith", Email: "jsmith@example.com"}
+
+		violations, err := policy.Check(ctx, "johnsmith-2026A", owner)
+		require.NoError(t, err)
+		assert.Equal(t, []string{PasswordSimilarToUsername}, violationCodes(violations))
+
+		// 编辑距离相近
+		owner = PasswordOwner{Username: "alice", Email: "john.smith@example.com"}
+		violations, err = policy.Check(ctx, "J0hn.Smith!", owner)
+		require.NoError(t, err)
+		assert.Equal(t, []string{PasswordSimilarToEmail}, violationCodes(violations))
+
+		// 过短的用户名不检查
+		violations, err = policy.Check(ctx, "Correct-Horse-7", PasswordOwner{Username: "co"})
+		require.NoError(t, err)
+		assert.Empty(t, violations)
+	})
+}
+
+// TestBreachedPasswordFile 测试本地泄露密码文件
+func TestBreachedPasswordFile(t *testing.T) {
+	ctx := context.Background()
+	path := writeBreachedFile(t, map[string]string{
+		"P@ssw0rd-2026": ":42",
+		"Summer2026!":   "",
+	})
+
+	file, err := OpenBreachedPasswordFile(path)
+	require.NoError(t, err)
+	t.Cleanup(func() { file.Close() })
+
+	count, err := BreachedCount(ctx, file, "P@ssw0rd-2026")
+	require.NoError(t, err)
+	assert.Equal(t, 42, count)
+
+	count, err = BreachedCount(ctx, file, "Summer2026!")
+	require.NoError(t, err)
+	assert.Equal(t, 1, count, "省略次数时视为 1")
+
+	count, err = BreachedCount(ctx, file, "Correct-Horse-7")
+	require.NoError(t, err)
+	assert.Zero(t, count)
+
+	// 每个测试哈希都能查到（覆盖文件开头和结尾）
+	for i := range 200 {
+		count, err := BreachedCount(ctx, file, strings.Repeat("x", i+1))
+		require.NoError(t, err)
+		assert.Equal(t, 1, count, i)
+	}
+
+	t.Run("策略拒绝泄露的密码", func(t *testing.T) {
+		policy := NewPasswordPolicy(PasswordPolicyConfig{MinLength: 8, BreachedMin: 10}, file)
+
+		violations, err := policy.Check(ctx, "P@ssw0rd-2026", PasswordOwner{})
+		require.NoError(t, err)
+		assert.Equal(t, []string{PasswordBreached}, violationCodes(violations))
+
+		violations, err = policy.Check(ctx, "Summer2026!", PasswordOwner{})
+		require.NoError(t, err)
+		assert.Empty(t, violations, "出现次数低于阈值")
+	})
+
+	t.Run("文件不存在", func(t *testing.T) {
+		_, err := OpenBreachedPasswordFile(filepath.Join(t.TempDir(), "missing.txt"))
+		assert.Error(t, err)
+	})
+}
+
+// failingSource 查询失败的数据源
+type failingSource struct{}
+
+func (failingSource) Range(context.Context, string) (map[string]int, error) {
+	return nil, errors.New("disk error")
+}
+
+// TestPasswordPolicy_BreachedSourceError 测试泄露密码库查询失败
+func TestPasswordPolicy_BreachedSourceError(t *testing.T) {
+	policy := NewPasswordPolicy(PasswordPolicyConfig{MinLength: 8, BreachedMin: 1}, failingSource{})
+
+	violations, err := policy.Check(context.Background(), "short", PasswordOwner{})
+	assert.Error(t, err)
+	assert.Equal(t, []string{PasswordTooShort}, violationCodes(violations), "其他检查的结果仍然有效")
+}
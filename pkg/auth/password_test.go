@@ -0,0 +1,147 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/crypto/bcrypt"
+)
+
+// testArgon2idParams 测试用的低成本参数
+var testArgon2idParams = Argon2idParams{Memory: 64, Iterations: 1, Parallelism: 1}
+
+// TestArgon2idHasher 测试 Argon2id 哈希
+func TestArgon2idHasher(t *testing.T) {
+	h, err := NewArgon2idHasher(testArgon2idParams)
+	require.NoError(t, err)
+
+	encoded, err := h.Hash("password123")
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(encoded, "$argon2id$v=19$m=64,t=1,p=1$"), encoded)
+
+	other, err := h.Hash("password123")
+	require.NoError(t, err)
+	assert.NotEqual(t, encoded, other, "每次使用不同的盐")
+
+	ok, err := h.Verify("password123", encoded)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = h.Verify("wrong", encoded)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.False(t, h.NeedsRehash(encoded))
+
+	t.Run("用哈希中的参数校验", func(t *testing.T) {
+		stronger, err := NewArgon2idHasher(Argon2idParams{Memory: 128, Iterations: 2, Parallelism: 1})
+		require.NoError(t, err)
+
+		ok, err := stronger.Verify("password123", encoded)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.True(t, stronger.NeedsRehash(encoded))
+	})
+
+	t.Run("格式错误", func(t *testing.T) {
+		for _, encoded := range []string{
+			"$argon2id$v=19$m=64,t=1,p=1$c2FsdA",
+			"$argon2id$v=18$m=64,t=1,p=1$c2FsdA$a2V5",
+			"$argon2id$v=19$m=64,t=0,p=1$c2FsdA$a2V5",
+			"$argon2id$v=19$m=64,t=1,p=1$!!!$a2V5",
+			"$argon2i$v=19$m=64,t=1,p=1$c2FsdA$a2V5",
+		} {
+			_, err := h.Verify("password123", encoded)
+			assert.ErrorIs(t, err, ErrInvalidPasswordHash, encoded)
+			assert.True(t, h.NeedsRehash(encoded), encoded)
+		}
+	})
+
+	t.Run("无效参数", func(t *testing.T) {
+		_, err := NewArgon2idHasher(Argon2idParams{Memory: 64, Parallelism: 1})
+		assert.Error(t, err)
+		_, err = NewArgon2idHasher(Argon2idParams{Memory: 4, Iterations: 1, Parallelism: 1})
+		assert.Error(t, err)
+	})
+}
+
+// TestBcryptHasher 测试 bcrypt 哈希
+func TestBcryptHasher(t *testing.T) {
+	h, err := NewBcryptHasher(bcrypt.MinCost)
+	require.NoError(t, err)
+
+	encoded, err := h.Hash("password123")
+	require.NoError(t, err)
+
+	ok, err := h.Verify("password123", encoded)
+	require.NoError(t, err)
+	assert.True(t, ok)
+
+	ok, err = h.Verify("wrong", encoded)
+	require.NoError(t, err)
+	assert.False(t, ok)
+
+	assert.False(t, h.NeedsRehash(encoded))
+
+	higher, err := NewBcryptHasher(bcrypt.MinCost + 1)
+	require.NoError(t, err)
+	assert.True(t, higher.NeedsRehash(encoded), "cost 不同")
+
+	_, err = NewBcryptHasher(bcrypt.MaxCost + 1)
+	assert.Error(t, err)
+}
+
+// TestPasswordHasher 测试按配置选择算法
+func TestPasswordHasher(t *testing.T) {
+	legacy, err := NewBcryptHasher(bcrypt.MinCost)
+	require.NoError(t, err)
+	bcryptHash, err := legacy.Hash("password123")
+	require.NoError(t, err)
+
+	h, err := NewPasswordHasher(PasswordHashConfig{
+		Algorithm:  PasswordAlgorithmArgon2id,
+		BcryptCost: bcrypt.MinCost,
+		Argon2id:   testArgon2idParams,
+	})
+	require.NoError(t, err)
+
+	t.Run("新密码使用配置的算法", func(t *testing.T) {
+		encoded, err := h.Hash("password123")
+		require.NoError(t, err)
+		assert.True(t, strings.HasPrefix(encoded, "$argon2id$"))
+		assert.False(t, h.NeedsRehash(encoded))
+
+		ok, err := h.Verify("password123", encoded)
+		require.NoError(t, err)
+		assert.True(t, ok)
+	})
+
+	t.Run("旧算法的哈希仍能校验并需要升级", func(t *testing.T) {
+		ok, err := h.Verify("password123", bcryptHash)
+		require.NoError(t, err)
+		assert.True(t, ok)
+		assert.True(t, h.NeedsRehash(bcryptHash))
+
+		ok, err = h.Verify("wrong", bcryptHash)
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("无密码账户", func(t *testing.T) {
+		ok, err := h.Verify("", "")
+		require.NoError(t, err)
+		assert.False(t, ok)
+	})
+
+	t.Run("未知格式", func(t *testing.T) {
+		_, err := h.Verify("password123", "plaintext")
+		assert.ErrorIs(t, err, ErrInvalidPasswordHash)
+	})
+
+	t.Run("未知算法", func(t *testing.T) {
+		_, err := NewPasswordHasher(PasswordHashConfig{Algorithm: "md5", Argon2id: testArgon2idParams})
+		assert.Error(t, err)
+	})
+}
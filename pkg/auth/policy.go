@@ -0,0 +1,257 @@
+package auth
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// SuperAdminLevel 超级管理员的角色级别（其他角色必须低于该级别）
+const SuperAdminLevel = 100
+
+// RoleDefinition 角色定义
+type RoleDefinition struct {
+	Name        Role
+	Level       int          // 角色级别（用于角色高低比较）
+	Permissions []Permission // 角色拥有的权限（超级管理员隐式拥有所有权限，无需列出）
+}
+
+// Policy 角色权限策略（创建后不可修改，热更新时整体替换）
+type Policy struct {
+	roles       map[Role]policyRole
+	permissions map[Permission]struct{}
+}
+
+// policyRole 策略中的角色
+type policyRole struct {
+	level       int
+	permissions map[Permission]struct{}
+}
+
+// NewPolicy 创建角色权限策略
+//
+// 角色引用的权限必须在 permissions 中声明；超级管理员必须存在，且拥有所有权限。
+func NewPolicy(permissions []Permission, roles []RoleDefinition) (*Policy, error) {
+	p := &Policy{
+		roles:       make(map[Role]policyRole, len(roles)),
+		permissions: make(map[Permission]struct{}, len(permissions)),
+	}
+	for _, perm := range permissions {
+		p.permissions[perm] = struct{}{}
+	}
+
+	for _, def := range roles {
+		if _, ok := p.roles[def.Name]; ok {
+			return nil, fmt.Errorf("auth: duplicate role %q", def.Name)
+		}
+		if def.Name != RoleSuperAdmin && def.Level >= SuperAdminLevel {
+			return nil, fmt.Errorf("auth: role %q level must be below %d", def.Name, SuperAdminLevel)
+		}
+
+		role := policyRole{
+			level:       def.Level,
+			permissions: make(map[Permission]struct{}, len(def.Permissions)),
+		}
+		for _, perm := range def.Permissions {
+			if _, ok := p.permissions[perm]; !ok {
+				return nil, fmt.Errorf("auth: role %q references unknown permission %q", def.Name, perm)
+			}
+			role.permissions[perm] = struct{}{}
+		}
+		p.roles[def.Name] = role
+	}
+
+	if _, ok := p.roles[RoleSuperAdmin]; !ok {
+		return nil, fmt.Errorf("auth: role %q is required", RoleSuperAdmin)
+	}
+	p.roles[RoleSuperAdmin] = policyRole{level: SuperAdminLevel}
+
+	return p, nil
+}
+
+// DefaultPolicy 内置策略（数据库中的策略加载之前，或加载失败时使用）
+func DefaultPolicy() *Policy {
+	permissions := make([]Permission, 0, len(allPermissions))
+	adminPermissions := make([]Permission, 0, len(allPermissions))
+	for perm := range allPermissions {
+		permissions = append(permissions, perm)
+		// 管理员拥有大部分权限（除了系统配置）
+		if perm != PermissionSystemConfig {
+			adminPermissions = append(adminPermissions, perm)
+		}
+	}
+
+	p, err := NewPolicy(permissions, []RoleDefinition{
+		{Name: RoleSuperAdmin, Level: SuperAdminLevel},
+		{Name: RoleAdmin, Level: 80, Permissions: adminPermissions},
+		// 版主拥有内容审核权限
+		{Name: RoleModerator, Level: 60, Permissions: []Permission{PermissionContentRead, PermissionContentAudit, PermissionUserRead}},
+		// 普通用户只能读取和写入内容
+		{Name: RoleUser, Level: 40, Permissions: []Permission{PermissionContentRead, PermissionContentWrite, PermissionUserRead}},
+		// 游客只能读取内容
+		{Name: RoleGuest, Level: 0, Permissions: []Permission{PermissionContentRead}},
+	})
+	if err != nil {
+		panic(err) // 内置策略是常量，出错说明代码有误
+	}
+	return p
+}
+
+// HasRole 是否是已定义的角色
+func (p *Policy) HasRole(role Role) bool {
+	_, ok := p.roles[role]
+	return ok
+}
+
+// HasPermission 是否是已定义的权限
+func (p *Policy) HasPermission(permission Permission) bool {
+	_, ok := p.permissions[permission]
+	return ok
+}
+
+// RoleLevel 角色级别（未定义的角色返回 -1）
+func (p *Policy) RoleLevel(role Role) int {
+	r, ok := p.roles[role]
+	if !ok {
+		return -1
+	}
+	return r.level
+}
+
+// RoleHasPermission 角色是否拥有指定权限（超级管理员拥有所有已定义的权限）
+func (p *Policy) RoleHasPermission(role Role, permission Permission) bool {
+	r, ok := p.roles[role]
+	if !ok {
+		return false
+	}
+	if role == RoleSuperAdmin {
+		return p.HasPermission(permission)
+	}
+	_, ok = r.permissions[permission]
+	return ok
+}
+
+// Roles 所有角色（按级别从高到低）
+func (p *Policy) Roles() []RoleDefinition {
+	roles := make([]RoleDefinition, 0, len(p.roles))
+	for name, r := range p.roles {
+		perms := make([]Permission, 0, len(r.permissions))
+		for perm := range r.permissions {
+			perms = append(perms, perm)
+		}
+		sort.Slice(perms, func(i, j int) bool { return perms[i] < perms[j] })
+		roles = append(roles, RoleDefinition{Name: name, Level: r.level, Permissions: perms})
+	}
+	sort.Slice(roles, func(i, j int) bool {
+		if roles[i].Level != roles[j].Level {
+			return roles[i].Level > roles[j].Level
+		}
+		return roles[i].Name < roles[j].Name
+	})
+	return roles
+}
+
+// activePolicy 当前生效的策略（RBACClaims 的权限判断基于它）
+var activePolicy atomic.Pointer[Policy]
+
+func init() {
+	activePolicy.Store(DefaultPolicy())
+}
+
+// ActivePolicy 当前生效的策略
+func ActivePolicy() *Policy {
+	return activePolicy.Load()
+}
+
+// SetActivePolicy 替换当前生效的策略（并发安全，正在进行的权限判断使用旧策略）
+func SetActivePolicy(p *Policy) {
+	if p == nil {
+		p = DefaultPolicy()
+	}
+	activePolicy.Store(p)
+}
+
+// PolicyLoader 从持久化存储加载策略
+type PolicyLoader func(ctx context.Context) (*Policy, error)
+
+// PolicyManager 策略热更新管理器
+//
+// 定期从存储重新加载策略并替换当前生效的策略。每个实例各自加载，
+// 修改角色的实例调用 Reload 立即生效，其他实例在下一个周期内生效。
+type PolicyManager struct {
+	load     PolicyLoader
+	interval time.Duration
+
+	mu     sync.Mutex
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewPolicyManager 创建策略热更新管理器（interval 为 0 时不定期刷新）
+func NewPolicyManager(load PolicyLoader, interval time.Duration) *PolicyManager {
+	return &PolicyManager{
+		load:     load,
+		interval: interval,
+	}
+}
+
+// Reload 重新加载策略（加载失败时保留当前策略）
+func (m *PolicyManager) Reload(ctx context.Context) error {
+	p, err := m.load(ctx)
+	if err != nil {
+		return fmt.Errorf("auth: load policy: %w", err)
+	}
+	SetActivePolicy(p)
+	return nil
+}
+
+// Start 启动定期刷新（重复调用无效）
+func (m *PolicyManager) Start() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.cancel != nil || m.interval <= 0 {
+		return
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	m.cancel = cancel
+	m.done = make(chan struct{})
+
+	go func() {
+		defer close(m.done)
+
+		ticker := time.NewTicker(m.interval)
+		defer ticker.Stop()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				reloadCtx, cancel := context.WithTimeout(ctx, m.interval)
+				if err := m.Reload(reloadCtx); err != nil {
+					slog.WarnContext(ctx, "Policy reload failed, keeping current policy", "error", err)
+				}
+				cancel()
+			}
+		}
+	}()
+}
+
+// Stop 停止定期刷新
+func (m *PolicyManager) Stop() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.cancel == nil {
+		return
+	}
+	m.cancel()
+	<-m.done
+	m.cancel = nil
+}
@@ -0,0 +1,153 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestDefaultPolicy 测试内置策略与原先写死的角色权限一致
+func TestDefaultPolicy(t *testing.T) {
+	p := DefaultPolicy()
+
+	cases := []struct {
+		role       Role
+		permission Permission
+		want       bool
+	}{
+		{RoleSuperAdmin, PermissionSystemConfig, true},
+		{RoleAdmin, PermissionUserDelete, true},
+		{RoleAdmin, PermissionSystemMonitor, true},
+		{RoleAdmin, PermissionSystemConfig, false},
+		{RoleModerator, PermissionContentAudit, true},
+		{RoleModerator, PermissionUserRead, true},
+		{RoleModerator, PermissionContentWrite, false},
+		{RoleUser, PermissionContentWrite, true},
+		{RoleUser, PermissionUserWrite, false},
+		{RoleGuest, PermissionContentRead, true},
+		{RoleGuest, PermissionUserRead, false},
+		{Role("unknown"), PermissionContentRead, false},
+	}
+	for _, tc := range cases {
+		assert.Equal(t, tc.want, p.RoleHasPermission(tc.role, tc.permission), "%s %s", tc.role, tc.permission)
+	}
+
+	assert.Equal(t, 100, p.RoleLevel(RoleSuperAdmin))
+	assert.Equal(t, 40, p.RoleLevel(RoleUser))
+	assert.Equal(t, -1, p.RoleLevel(Role("unknown")))
+	assert.Equal(t, RoleSuperAdmin, p.Roles()[0].Name)
+}
+
+// TestNewPolicy 测试策略校验
+func TestNewPolicy(t *testing.T) {
+	perms := []Permission{"report:export", PermissionUserRead}
+
+	t.Run("引用未声明的权限", func(t *testing.T) {
+		_, err := NewPolicy(perms, []RoleDefinition{
+			{Name: RoleSuperAdmin, Level: SuperAdminLevel},
+			{Name: "auditor", Level: 50, Permissions: []Permission{"report:delete"}},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("缺少超级管理员", func(t *testing.T) {
+		_, err := NewPolicy(perms, []RoleDefinition{{Name: "auditor", Level: 50}})
+		assert.Error(t, err)
+	})
+
+	t.Run("其他角色级别不能达到超级管理员", func(t *testing.T) {
+		_, err := NewPolicy(perms, []RoleDefinition{
+			{Name: RoleSuperAdmin, Level: SuperAdminLevel},
+			{Name: "auditor", Level: SuperAdminLevel},
+		})
+		assert.Error(t, err)
+	})
+
+	t.Run("超级管理员拥有所有已声明的权限", func(t *testing.T) {
+		p, err := NewPolicy(perms, []RoleDefinition{{Name: RoleSuperAdmin, Level: 1}})
+		require.NoError(t, err)
+		assert.True(t, p.RoleHasPermission(RoleSuperAdmin, "report:export"))
+		assert.False(t, p.RoleHasPermission(RoleSuperAdmin, "report:delete"))
+		assert.Equal(t, SuperAdminLevel, p.RoleLevel(RoleSuperAdmin))
+	})
+}
+
+// TestActivePolicy 测试替换策略后权限判断立即生效
+func TestActivePolicy(t *testing.T) {
+	t.Cleanup(func() { SetActivePolicy(nil) })
+
+	p, err := NewPolicy([]Permission{"report:export", PermissionContentRead}, []RoleDefinition{
+		{Name: RoleSuperAdmin, Level: SuperAdminLevel},
+		{Name: "auditor", Level: 50, Permissions: []Permission{"report:export"}},
+		{Name: RoleUser, Level: 40},
+	})
+	require.NoError(t, err)
+	SetActivePolicy(p)
+
+	auditor := &RBACClaims{UserID: 1, Role: "auditor"}
+	assert.True(t, Role("auditor").IsValid())
+	assert.True(t, Permission("report:export").IsValid())
+	assert.True(t, auditor.HasPermission("report:export"))
+	assert.True(t, auditor.HasHigherRoleThan(RoleUser))
+
+	// 内置角色的权限也来自策略
+	user := &RBACClaims{UserID: 2, Role: RoleUser}
+	assert.False(t, user.HasPermission(PermissionContentRead))
+	assert.False(t, RoleModerator.IsValid())
+
+	// 显式授予的权限和 API Key 范围仍然生效
+	user.Permissions = []Permission{PermissionContentRead}
+	assert.True(t, user.HasPermission(PermissionContentRead))
+	auditor.Scopes = []Permission{PermissionContentRead}
+	assert.False(t, auditor.HasPermission("report:export"))
+
+	// 恢复内置策略
+	SetActivePolicy(nil)
+	assert.False(t, Role("auditor").IsValid())
+	assert.True(t, RoleModerator.IsValid())
+}
+
+// TestPolicyManager 测试策略热更新
+func TestPolicyManager(t *testing.T) {
+	t.Cleanup(func() { SetActivePolicy(nil) })
+
+	custom, err := NewPolicy([]Permission{"report:export"}, []RoleDefinition{
+		{Name: RoleSuperAdmin, Level: SuperAdminLevel},
+		{Name: "auditor", Level: 50, Permissions: []Permission{"report:export"}},
+	})
+	require.NoError(t, err)
+
+	t.Run("加载失败时保留当前策略", func(t *testing.T) {
+		SetActivePolicy(custom)
+		manager := NewPolicyManager(func(context.Context) (*Policy, error) {
+			return nil, errors.New("database down")
+		}, 0)
+
+		assert.Error(t, manager.Reload(context.Background()))
+		assert.Same(t, custom, ActivePolicy())
+	})
+
+	t.Run("定期加载", func(t *testing.T) {
+		SetActivePolicy(nil)
+		var loads atomic.Int32
+		manager := NewPolicyManager(func(context.Context) (*Policy, error) {
+			loads.Add(1)
+			return custom, nil
+		}, 10*time.Millisecond)
+
+		manager.Start()
+		manager.Start() // 重复调用无效
+		assert.Eventually(t, func() bool { return ActivePolicy() == custom }, time.Second, 5*time.Millisecond)
+		manager.Stop()
+
+		stopped := loads.Load()
+		time.Sleep(30 * time.Millisecond)
+		assert.Equal(t, stopped, loads.Load(), "停止后不再加载")
+		manager.Stop() // 重复调用无效
+	})
+}
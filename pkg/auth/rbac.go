@@ -1,6 +1,7 @@
 package auth
 
 import (
+	"errors"
 	"fmt"
 	"time"
 
@@ -43,63 +44,111 @@ const (
 	PermissionSystemMonitor Permission = "system:monitor"
 )
 
+// allPermissions 内置权限（内置策略使用，运行时以 ActivePolicy 中的权限为准）
+var allPermissions = map[Permission]struct{}{
+	PermissionUserRead:      {},
+	PermissionUserWrite:     {},
+	PermissionUserDelete:    {},
+	PermissionContentRead:   {},
+	PermissionContentWrite:  {},
+	PermissionContentDelete: {},
+	PermissionContentAudit:  {},
+	PermissionSystemConfig:  {},
+	PermissionSystemMonitor: {},
+}
+
+// IsValid 是否是已定义的角色
+func (r Role) IsValid() bool {
+	return ActivePolicy().HasRole(r)
+}
+
+// IsValid 是否是已定义的权限
+func (p Permission) IsValid() bool {
+	return ActivePolicy().HasPermission(p)
+}
+
 // RBACClaims JWT 声明（包含角色和权限）
 type RBACClaims struct {
 	UserID      int64        `json:"user_id"`
 	Role        Role         `json:"role"`                   // 用户角色
 	Permissions []Permission `json:"permissions,omitempty"`  // 细粒度权限（可选）
+	Version     int64        `json:"ver,omitempty"`          // Token 版本号（服务端递增后旧 Token 失效）
+	Scopes      []Permission `json:"scopes,omitempty"`       // 权限范围（API Key 认证时设置，非空时只允许范围内的权限）
+	TenantID    int64        `json:"tid,omitempty"`          // 所属租户（0 表示默认租户，兼容多租户之前签发的 Token）
+	ActorID     int64        `json:"actor_id,omitempty"`     // 实际操作的管理员 ID（模拟登录时设置，UserID 为被模拟的用户）
 	jwt.RegisteredClaims
 }
 
 // RBACJWTManager RBAC JWT 管理器
 type RBACJWTManager struct {
-	secret     string
+	keys       *KeySet
 	expiration time.Duration
 }
 
-// NewRBACJWTManager 创建 RBAC JWT 管理器
+// NewRBACJWTManager 创建 RBAC JWT 管理器（HS256 单密钥）
 func NewRBACJWTManager(secret string, expiration time.Duration) *RBACJWTManager {
+	return NewRBACJWTManagerWithKeys(NewHMACKeySet(secret), expiration)
+}
+
+// NewRBACJWTManagerWithKeys 使用密钥集创建 RBAC JWT 管理器（支持非对称算法和多个验证密钥）
+func NewRBACJWTManagerWithKeys(keys *KeySet, expiration time.Duration) *RBACJWTManager {
 	return &RBACJWTManager{
-		secret:     secret,
+		keys:       keys,
 		expiration: expiration,
 	}
 }
 
+// Expiration Token 有效期
+func (m *RBACJWTManager) Expiration() time.Duration {
+	return m.expiration
+}
+
 // GenerateToken 生成包含角色信息的 JWT Token
 func (m *RBACJWTManager) GenerateToken(userID int64, role Role, permissions ...Permission) (string, error) {
-	now := time.Now()
-	claims := RBACClaims{
+	return m.GenerateTokenWithVersion(userID, 0, role, permissions...)
+}
+
+// GenerateTokenWithVersion 生成包含角色信息和 Token 版本号的 JWT Token（默认租户）
+func (m *RBACJWTManager) GenerateTokenWithVersion(userID, version int64, role Role, permissions ...Permission) (string, error) {
+	return m.GenerateTenantToken(0, userID, version, role, permissions...)
+}
+
+// GenerateTenantToken 生成包含租户、角色信息和 Token 版本号的 JWT Token
+func (m *RBACJWTManager) GenerateTenantToken(tenantID, userID, version int64, role Role, permissions ...Permission) (string, error) {
+	return m.generate(RBACClaims{
 		UserID:      userID,
 		Role:        role,
 		Permissions: permissions,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(now.Add(m.expiration)),
-			IssuedAt:  jwt.NewNumericDate(now),
-			NotBefore: jwt.NewNumericDate(now),
-		},
-	}
+		Version:     version,
+		TenantID:    tenantID,
+	}, m.expiration)
+}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
-	tokenString, err := token.SignedString([]byte(m.secret))
+// generate 填充标准声明并签名
+func (m *RBACJWTManager) generate(claims RBACClaims, expiration time.Duration) (string, error) {
+	jti, err := randomString(16)
 	if err != nil {
-		return "", fmt.Errorf("failed to sign token: %w", err)
+		return "", fmt.Errorf("failed to generate token id: %w", err)
 	}
 
-	return tokenString, nil
+	now := time.Now()
+	claims.RegisteredClaims = jwt.RegisteredClaims{
+		ID:        jti,
+		ExpiresAt: jwt.NewNumericDate(now.Add(expiration)),
+		IssuedAt:  jwt.NewNumericDate(now),
+		NotBefore: jwt.NewNumericDate(now),
+	}
+
+	return m.keys.sign(claims)
 }
 
 // ValidateToken 验证 JWT Token
 func (m *RBACJWTManager) ValidateToken(tokenString string) (*RBACClaims, error) {
-	token, err := jwt.ParseWithClaims(tokenString, &RBACClaims{}, func(token *jwt.Token) (interface{}, error) {
-		// 验证签名方法
-		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		return []byte(m.secret), nil
-	})
+	// 按 kid 选择验证密钥，并校验签名方法
+	token, err := m.keys.parse(tokenString, &RBACClaims{})
 
 	if err != nil {
-		if jwt.ErrTokenExpired != nil {
+		if errors.Is(err, jwt.ErrTokenExpired) {
 			return nil, ErrExpiredToken
 		}
 		return nil, fmt.Errorf("%w: %v", ErrInvalidToken, err)
@@ -114,14 +163,32 @@ func (m *RBACJWTManager) ValidateToken(tokenString string) (*RBACClaims, error)
 }
 
 // RefreshToken 刷新 Token（保持原有角色和权限）
+//
+// Deprecated: 只要签名有效，过期多久的 Token 都能换新，无法吊销，且不会同步角色变更。
+// 请使用 RefreshTokenManager 签发可轮换、可吊销的刷新令牌。
 func (m *RBACJWTManager) RefreshToken(tokenString string) (string, error) {
-	claims, err := m.ValidateToken(tokenString)
-	if err != nil && err != ErrExpiredToken {
-		return "", err
+	// 不使用 ValidateToken：Token 过期时它不返回声明，这里只要求签名有效
+	token, err := m.keys.parse(tokenString, &RBACClaims{})
+	if err != nil && !errors.Is(err, jwt.ErrTokenExpired) {
+		return "", fmt.Errorf("%w: %v", ErrInvalidToken, err)
+	}
+	claims, ok := token.Claims.(*RBACClaims)
+	if !ok {
+		return "", ErrInvalidToken
+	}
+
+	// 模拟登录 Token 不能续期
+	if claims.IsImpersonated() {
+		return "", ErrInvalidToken
 	}
 
 	// 即使 Token 过期，只要签名有效，就允许刷新
-	return m.GenerateToken(claims.UserID, claims.Role, claims.Permissions...)
+	return m.GenerateTenantToken(claims.TenantID, claims.UserID, claims.Version, claims.Role, claims.Permissions...)
+}
+
+// IsImpersonated 是否为模拟登录 Token（ActorID 为实际操作的管理员）
+func (c *RBACClaims) IsImpersonated() bool {
+	return c.ActorID != 0
 }
 
 // HasRole 检查是否拥有指定角色
@@ -141,6 +208,11 @@ func (c *RBACClaims) HasAnyRole(roles ...Role) bool {
 
 // HasPermission 检查是否拥有指定权限
 func (c *RBACClaims) HasPermission(permission Permission) bool {
+	// 权限范围优先于角色：API Key 只能使用创建时授予的权限
+	if len(c.Scopes) > 0 && !containsPermission(c.Scopes, permission) {
+		return false
+	}
+
 	// 超级管理员拥有所有权限
 	if c.Role == RoleSuperAdmin {
 		return true
@@ -177,35 +249,19 @@ func (c *RBACClaims) HasAnyPermission(permissions ...Permission) bool {
 	return false
 }
 
-// hasImplicitPermission 基于角色的隐式权限（权限继承）
-func (c *RBACClaims) hasImplicitPermission(permission Permission) bool {
-	switch c.Role {
-	case RoleSuperAdmin:
-		return true // 超级管理员拥有所有权限
-
-	case RoleAdmin:
-		// 管理员拥有大部分权限（除了系统配置）
-		return permission != PermissionSystemConfig
-
-	case RoleModerator:
-		// 版主拥有内容审核权限
-		return permission == PermissionContentRead ||
-			permission == PermissionContentAudit ||
-			permission == PermissionUserRead
-
-	case RoleUser:
-		// 普通用户只能读取和写入内容
-		return permission == PermissionContentRead ||
-			permission == PermissionContentWrite ||
-			permission == PermissionUserRead
-
-	case RoleGuest:
-		// 游客只能读取内容
-		return permission == PermissionContentRead
-
-	default:
-		return false
+// containsPermission 权限列表中是否包含指定权限
+func containsPermission(permissions []Permission, permission Permission) bool {
+	for _, p := range permissions {
+		if p == permission {
+			return true
+		}
 	}
+	return false
+}
+
+// hasImplicitPermission 基于角色的隐式权限（由当前生效的策略决定，见 ActivePolicy）
+func (c *RBACClaims) hasImplicitPermission(permission Permission) bool {
+	return ActivePolicy().RoleHasPermission(c.Role, permission)
 }
 
 // IsAdmin 是否是管理员（admin 或 super_admin）
@@ -220,20 +276,7 @@ func (c *RBACClaims) IsSuperAdmin() bool {
 
 // GetRoleLevel 获取角色级别（用于权限比较）
 func (c *RBACClaims) GetRoleLevel() int {
-	switch c.Role {
-	case RoleSuperAdmin:
-		return 100
-	case RoleAdmin:
-		return 80
-	case RoleModerator:
-		return 60
-	case RoleUser:
-		return 40
-	case RoleGuest:
-		return 0
-	default:
-		return -1
-	}
+	return roleLevel(c.Role)
 }
 
 // HasHigherRoleThan 是否拥有比指定角色更高的权限
@@ -244,18 +287,5 @@ func (c *RBACClaims) HasHigherRoleThan(role Role) bool {
 
 // roleLevel 获取角色级别（辅助函数）
 func roleLevel(role Role) int {
-	switch role {
-	case RoleSuperAdmin:
-		return 100
-	case RoleAdmin:
-		return 80
-	case RoleModerator:
-		return 60
-	case RoleUser:
-		return 40
-	case RoleGuest:
-		return 0
-	default:
-		return -1
-	}
+	return ActivePolicy().RoleLevel(role)
 }
@@ -0,0 +1,202 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"gin_demo/pkg/tenant"
+)
+
+var (
+	// ErrRefreshTokenInvalid 刷新令牌无效（不存在、已过期或已吊销）
+	ErrRefreshTokenInvalid = errors.New("invalid refresh token")
+	// ErrRefreshTokenReused 刷新令牌被重复使用（疑似泄露，整个令牌家族已吊销）
+	ErrRefreshTokenReused = errors.New("refresh token reused")
+	// ErrRefreshTokenNotFound 存储中不存在该刷新令牌
+	ErrRefreshTokenNotFound = errors.New("refresh token not found")
+)
+
+// RefreshTokenRecord 刷新令牌记录（存储中只保存令牌哈希，不保存明文）
+type RefreshTokenRecord struct {
+	TenantID     int64     `json:"tenant_id"` // 签发时用户所属的租户（0 表示多租户之前签发，视为默认租户）
+	UserID       int64     `json:"user_id"`
+	FamilyID     string    `json:"family_id"`     // 同一次登录产生的令牌链共享一个家族 ID
+	TokenVersion int64     `json:"token_version"` // 签发时用户的 Token 版本号（版本号变化后应拒绝刷新）
+	IssuedAt     time.Time `json:"issued_at"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+// RefreshTokenStore 刷新令牌存储接口
+type RefreshTokenStore interface {
+	// Save 保存新签发的刷新令牌
+	Save(ctx context.Context, tokenHash string, record RefreshTokenRecord, ttl time.Duration) error
+
+	// Get 查询刷新令牌，不存在时返回 ErrRefreshTokenNotFound
+	Get(ctx context.Context, tokenHash string) (RefreshTokenRecord, error)
+
+	// MarkUsed 原子地标记令牌已使用，返回 false 表示此前已被使用过
+	MarkUsed(ctx context.Context, tokenHash string, ttl time.Duration) (bool, error)
+
+	// RevokeFamily 吊销整个令牌家族
+	RevokeFamily(ctx context.Context, familyID string, ttl time.Duration) error
+
+	// IsFamilyRevoked 令牌家族是否已吊销
+	IsFamilyRevoked(ctx context.Context, familyID string) (bool, error)
+}
+
+// RefreshTokenManager 刷新令牌管理器（不透明令牌 + 每次使用轮换 + 重用检测）
+type RefreshTokenManager struct {
+	store      RefreshTokenStore
+	expiration time.Duration
+}
+
+// NewRefreshTokenManager 创建刷新令牌管理器
+func NewRefreshTokenManager(store RefreshTokenStore, expiration time.Duration) *RefreshTokenManager {
+	return &RefreshTokenManager{
+		store:      store,
+		expiration: expiration,
+	}
+}
+
+// Expiration 刷新令牌有效期
+func (m *RefreshTokenManager) Expiration() time.Duration {
+	return m.expiration
+}
+
+// Issue 为一次新登录签发刷新令牌（开启新的令牌家族）
+//
+// tokenVersion 为签发时用户的 Token 版本号，轮换时原样继承，
+// 调用方应在 Rotate 后与用户当前版本号比较，以拒绝"登出所有设备"之前签发的令牌。
+// 令牌记录 context 中的租户（tenant.ID），调用方应在 Rotate 后按记录中的租户加载用户。
+func (m *RefreshTokenManager) Issue(ctx context.Context, userID, tokenVersion int64) (string, error) {
+	familyID, err := randomString(16)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate family id: %w", err)
+	}
+	return m.issue(ctx, RefreshTokenRecord{
+		TenantID:     tenant.ID(ctx),
+		UserID:       userID,
+		FamilyID:     familyID,
+		TokenVersion: tokenVersion,
+	})
+}
+
+// Rotate 使用刷新令牌换取新令牌（旧令牌立即失效）
+//
+// 如果令牌已被使用过，视为泄露：吊销整个家族并返回 ErrRefreshTokenReused，
+// 此后该家族中任何令牌（包括攻击者或用户手中最新的那个）都无法再使用。
+//
+// 返回的记录为旧令牌的记录（新令牌继承其家族和 Token 版本号）。
+func (m *RefreshTokenManager) Rotate(ctx context.Context, token string) (string, RefreshTokenRecord, error) {
+	hash := hashToken(token)
+
+	record, err := m.lookup(ctx, hash)
+	if err != nil {
+		return "", RefreshTokenRecord{}, err
+	}
+
+	first, err := m.store.MarkUsed(ctx, hash, m.expiration)
+	if err != nil {
+		return "", RefreshTokenRecord{}, fmt.Errorf("failed to mark refresh token used: %w", err)
+	}
+	if !first {
+		if err := m.store.RevokeFamily(ctx, record.FamilyID, m.expiration); err != nil {
+			return "", RefreshTokenRecord{}, fmt.Errorf("failed to revoke token family: %w", err)
+		}
+		return "", record, ErrRefreshTokenReused
+	}
+
+	newToken, err := m.issue(ctx, record)
+	if err != nil {
+		return "", RefreshTokenRecord{}, err
+	}
+	return newToken, record, nil
+}
+
+// Lookup 查询刷新令牌的记录（不消耗令牌，用于轮换前的校验）
+//
+// 令牌不存在、已过期或家族已吊销时返回 ErrRefreshTokenInvalid；是否已使用过由 Rotate 检测。
+func (m *RefreshTokenManager) Lookup(ctx context.Context, token string) (RefreshTokenRecord, error) {
+	return m.lookup(ctx, hashToken(token))
+}
+
+// Revoke 吊销刷新令牌所在的整个家族（用于登出）
+func (m *RefreshTokenManager) Revoke(ctx context.Context, token string) (int64, error) {
+	record, err := m.lookup(ctx, hashToken(token))
+	if err != nil {
+		return 0, err
+	}
+	if err := m.store.RevokeFamily(ctx, record.FamilyID, m.expiration); err != nil {
+		return 0, fmt.Errorf("failed to revoke token family: %w", err)
+	}
+	return record.UserID, nil
+}
+
+// lookup 查询令牌记录并检查过期和吊销状态
+func (m *RefreshTokenManager) lookup(ctx context.Context, hash string) (RefreshTokenRecord, error) {
+	record, err := m.store.Get(ctx, hash)
+	if err != nil {
+		if errors.Is(err, ErrRefreshTokenNotFound) {
+			return record, ErrRefreshTokenInvalid
+		}
+		return record, fmt.Errorf("failed to get refresh token: %w", err)
+	}
+
+	if time.Now().After(record.ExpiresAt) {
+		return record, ErrRefreshTokenInvalid
+	}
+
+	revoked, err := m.store.IsFamilyRevoked(ctx, record.FamilyID)
+	if err != nil {
+		return record, fmt.Errorf("failed to check token family: %w", err)
+	}
+	if revoked {
+		return record, ErrRefreshTokenInvalid
+	}
+
+	return record, nil
+}
+
+// issue 在 base 所属家族中签发新令牌（继承租户、用户 ID、家族 ID 和 Token 版本号）
+func (m *RefreshTokenManager) issue(ctx context.Context, base RefreshTokenRecord) (string, error) {
+	token, err := randomString(32)
+	if err != nil {
+		return "", fmt.Errorf("failed to generate refresh token: %w", err)
+	}
+
+	now := time.Now()
+	record := RefreshTokenRecord{
+		TenantID:     base.TenantID,
+		UserID:       base.UserID,
+		FamilyID:     base.FamilyID,
+		TokenVersion: base.TokenVersion,
+		IssuedAt:     now,
+		ExpiresAt:    now.Add(m.expiration),
+	}
+	if err := m.store.Save(ctx, hashToken(token), record, m.expiration); err != nil {
+		return "", fmt.Errorf("failed to save refresh token: %w", err)
+	}
+
+	return token, nil
+}
+
+// hashToken 计算令牌哈希（存储中只保存哈希）
+func hashToken(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomString 生成 URL 安全的随机字符串
+func randomString(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(b), nil
+}
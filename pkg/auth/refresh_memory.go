@@ -0,0 +1,91 @@
+package auth
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryRefreshTokenStore 基于内存的刷新令牌存储（仅用于测试或单实例部署）
+type MemoryRefreshTokenStore struct {
+	mu       sync.Mutex
+	tokens   map[string]memoryEntry[RefreshTokenRecord]
+	used     map[string]memoryEntry[struct{}]
+	families map[string]memoryEntry[struct{}]
+}
+
+// memoryEntry 带过期时间的内存条目
+type memoryEntry[T any] struct {
+	value     T
+	expiresAt time.Time
+}
+
+// expired 条目是否已过期
+func (e memoryEntry[T]) expired(now time.Time) bool {
+	return now.After(e.expiresAt)
+}
+
+// NewMemoryRefreshTokenStore 创建内存刷新令牌存储
+func NewMemoryRefreshTokenStore() *MemoryRefreshTokenStore {
+	return &MemoryRefreshTokenStore{
+		tokens:   make(map[string]memoryEntry[RefreshTokenRecord]),
+		used:     make(map[string]memoryEntry[struct{}]),
+		families: make(map[string]memoryEntry[struct{}]),
+	}
+}
+
+// Save 实现 RefreshTokenStore 接口
+func (s *MemoryRefreshTokenStore) Save(_ context.Context, tokenHash string, record RefreshTokenRecord, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.tokens[tokenHash] = memoryEntry[RefreshTokenRecord]{value: record, expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+// Get 实现 RefreshTokenStore 接口
+func (s *MemoryRefreshTokenStore) Get(_ context.Context, tokenHash string) (RefreshTokenRecord, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.tokens[tokenHash]
+	if !ok || entry.expired(time.Now()) {
+		delete(s.tokens, tokenHash)
+		return RefreshTokenRecord{}, ErrRefreshTokenNotFound
+	}
+	return entry.value, nil
+}
+
+// MarkUsed 实现 RefreshTokenStore 接口
+func (s *MemoryRefreshTokenStore) MarkUsed(_ context.Context, tokenHash string, ttl time.Duration) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	if entry, ok := s.used[tokenHash]; ok && !entry.expired(now) {
+		return false, nil
+	}
+	s.used[tokenHash] = memoryEntry[struct{}]{expiresAt: now.Add(ttl)}
+	return true, nil
+}
+
+// RevokeFamily 实现 RefreshTokenStore 接口
+func (s *MemoryRefreshTokenStore) RevokeFamily(_ context.Context, familyID string, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.families[familyID] = memoryEntry[struct{}]{expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+// IsFamilyRevoked 实现 RefreshTokenStore 接口
+func (s *MemoryRefreshTokenStore) IsFamilyRevoked(_ context.Context, familyID string) (bool, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.families[familyID]
+	return ok && !entry.expired(time.Now()), nil
+}
+
+// 确保 MemoryRefreshTokenStore 实现了接口
+var _ RefreshTokenStore = (*MemoryRefreshTokenStore)(nil)
@@ -0,0 +1,82 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// RedisRefreshTokenStore 基于 Redis 的刷新令牌存储
+//
+// Key 布局:
+//   - {prefix}token:<hash>   令牌记录（JSON）
+//   - {prefix}used:<hash>    令牌已使用标记
+//   - {prefix}family:<id>    令牌家族吊销标记
+type RedisRefreshTokenStore struct {
+	rdb    redis.UniversalClient
+	prefix string
+}
+
+// NewRedisRefreshTokenStore 创建 Redis 刷新令牌存储
+func NewRedisRefreshTokenStore(rdb redis.UniversalClient, prefix string) *RedisRefreshTokenStore {
+	if prefix == "" {
+		prefix = "auth:refresh:"
+	}
+	return &RedisRefreshTokenStore{
+		rdb:    rdb,
+		prefix: prefix,
+	}
+}
+
+// Save 实现 RefreshTokenStore 接口
+func (s *RedisRefreshTokenStore) Save(ctx context.Context, tokenHash string, record RefreshTokenRecord, ttl time.Duration) error {
+	bs, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("marshal refresh token: %w", err)
+	}
+	return s.rdb.Set(ctx, s.prefix+"token:"+tokenHash, bs, ttl).Err()
+}
+
+// Get 实现 RefreshTokenStore 接口
+func (s *RedisRefreshTokenStore) Get(ctx context.Context, tokenHash string) (RefreshTokenRecord, error) {
+	var record RefreshTokenRecord
+
+	val, err := s.rdb.Get(ctx, s.prefix+"token:"+tokenHash).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return record, ErrRefreshTokenNotFound
+		}
+		return record, err
+	}
+
+	if err := json.Unmarshal(val, &record); err != nil {
+		return record, fmt.Errorf("unmarshal refresh token: %w", err)
+	}
+	return record, nil
+}
+
+// MarkUsed 实现 RefreshTokenStore 接口（SET NX 保证并发下只有一次成功）
+func (s *RedisRefreshTokenStore) MarkUsed(ctx context.Context, tokenHash string, ttl time.Duration) (bool, error) {
+	return s.rdb.SetNX(ctx, s.prefix+"used:"+tokenHash, time.Now().Unix(), ttl).Result()
+}
+
+// RevokeFamily 实现 RefreshTokenStore 接口
+func (s *RedisRefreshTokenStore) RevokeFamily(ctx context.Context, familyID string, ttl time.Duration) error {
+	return s.rdb.Set(ctx, s.prefix+"family:"+familyID, time.Now().Unix(), ttl).Err()
+}
+
+// IsFamilyRevoked 实现 RefreshTokenStore 接口
+func (s *RedisRefreshTokenStore) IsFamilyRevoked(ctx context.Context, familyID string) (bool, error) {
+	n, err := s.rdb.Exists(ctx, s.prefix+"family:"+familyID).Result()
+	if err != nil {
+		return false, err
+	}
+	return n > 0, nil
+}
+
+// 确保 RedisRefreshTokenStore 实现了接口
+var _ RefreshTokenStore = (*RedisRefreshTokenStore)(nil)
@@ -0,0 +1,122 @@
+package auth
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"gin_demo/pkg/tenant"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestRefreshTokenManager_Rotate 测试刷新令牌轮换
+func TestRefreshTokenManager_Rotate(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("轮换后旧令牌失效", func(t *testing.T) {
+		manager := NewRefreshTokenManager(NewMemoryRefreshTokenStore(), time.Hour)
+
+		token, err := manager.Issue(ctx, 42, 0)
+		require.NoError(t, err)
+
+		newToken, record, err := manager.Rotate(ctx, token)
+		require.NoError(t, err)
+		assert.Equal(t, int64(42), record.UserID)
+		assert.NotEqual(t, token, newToken)
+
+		// 新令牌可以继续轮换
+		_, _, err = manager.Rotate(ctx, newToken)
+		assert.NoError(t, err)
+	})
+
+	t.Run("重用检测吊销整个家族", func(t *testing.T) {
+		manager := NewRefreshTokenManager(NewMemoryRefreshTokenStore(), time.Hour)
+
+		token, err := manager.Issue(ctx, 42, 0)
+		require.NoError(t, err)
+
+		newToken, _, err := manager.Rotate(ctx, token)
+		require.NoError(t, err)
+
+		// 旧令牌被再次使用
+		_, _, err = manager.Rotate(ctx, token)
+		assert.ErrorIs(t, err, ErrRefreshTokenReused)
+
+		// 同一家族中最新的令牌也随之失效
+		_, _, err = manager.Rotate(ctx, newToken)
+		assert.ErrorIs(t, err, ErrRefreshTokenInvalid)
+	})
+
+	t.Run("轮换继承 Token 版本号", func(t *testing.T) {
+		manager := NewRefreshTokenManager(NewMemoryRefreshTokenStore(), time.Hour)
+
+		token, err := manager.Issue(ctx, 42, 3)
+		require.NoError(t, err)
+
+		newToken, record, err := manager.Rotate(ctx, token)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), record.TokenVersion)
+
+		_, record, err = manager.Rotate(ctx, newToken)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), record.TokenVersion)
+	})
+
+	t.Run("记录并继承签发时的租户", func(t *testing.T) {
+		manager := NewRefreshTokenManager(NewMemoryRefreshTokenStore(), time.Hour)
+
+		token, err := manager.Issue(tenant.WithID(ctx, 7), 42, 0)
+		require.NoError(t, err)
+
+		newToken, record, err := manager.Rotate(ctx, token)
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), record.TenantID)
+
+		_, record, err = manager.Rotate(ctx, newToken)
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), record.TenantID)
+	})
+
+	t.Run("查询记录不消耗令牌", func(t *testing.T) {
+		manager := NewRefreshTokenManager(NewMemoryRefreshTokenStore(), time.Hour)
+
+		token, err := manager.Issue(tenant.WithID(ctx, 7), 42, 0)
+		require.NoError(t, err)
+
+		record, err := manager.Lookup(ctx, token)
+		require.NoError(t, err)
+		assert.Equal(t, int64(7), record.TenantID)
+
+		_, _, err = manager.Rotate(ctx, token)
+		require.NoError(t, err)
+
+		_, err = manager.Lookup(ctx, "not-a-token")
+		assert.ErrorIs(t, err, ErrRefreshTokenInvalid)
+	})
+
+	t.Run("未知令牌", func(t *testing.T) {
+		manager := NewRefreshTokenManager(NewMemoryRefreshTokenStore(), time.Hour)
+
+		_, _, err := manager.Rotate(ctx, "not-a-token")
+		assert.ErrorIs(t, err, ErrRefreshTokenInvalid)
+	})
+
+	t.Run("不同登录互不影响", func(t *testing.T) {
+		manager := NewRefreshTokenManager(NewMemoryRefreshTokenStore(), time.Hour)
+
+		first, err := manager.Issue(ctx, 42, 0)
+		require.NoError(t, err)
+		second, err := manager.Issue(ctx, 42, 0)
+		require.NoError(t, err)
+
+		_, err = manager.Revoke(ctx, first)
+		require.NoError(t, err)
+
+		_, _, err = manager.Rotate(ctx, first)
+		assert.ErrorIs(t, err, ErrRefreshTokenInvalid)
+		_, _, err = manager.Rotate(ctx, second)
+		assert.NoError(t, err)
+	})
+}
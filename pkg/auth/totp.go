@@ -0,0 +1,182 @@
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha1"
+	"crypto/subtle"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+// ErrInvalidTOTPSecret TOTP 密钥格式错误
+var ErrInvalidTOTPSecret = errors.New("invalid totp secret")
+
+// totpEncoding TOTP 密钥编码（Base32 无填充，兼容 Google Authenticator 等客户端）
+var totpEncoding = base32.StdEncoding.WithPadding(base32.NoPadding)
+
+// TOTP 基于时间的一次性密码（RFC 6238，HMAC-SHA1）
+//
+// 纯本地计算，不依赖任何外部服务。
+type TOTP struct {
+	Issuer string        // 签发方（显示在验证器 App 中）
+	Digits int           // 验证码位数
+	Period time.Duration // 时间步长
+	Skew   int           // 允许前后偏移的时间步数（容忍客户端时钟误差）
+}
+
+// NewTOTP 创建 TOTP（6 位验证码，30 秒步长，允许前后各 1 个步长）
+func NewTOTP(issuer string) *TOTP {
+	return &TOTP{
+		Issuer: issuer,
+		Digits: 6,
+		Period: 30 * time.Second,
+		Skew:   1,
+	}
+}
+
+// GenerateSecret 生成随机密钥（160 位，Base32 编码）
+func (t *TOTP) GenerateSecret() (string, error) {
+	b := make([]byte, 20)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate totp secret: %w", err)
+	}
+	return totpEncoding.EncodeToString(b), nil
+}
+
+// URI 生成 otpauth:// URI（用于生成二维码）
+func (t *TOTP) URI(account, secret string) string {
+	label := url.PathEscape(t.Issuer) + ":" + url.PathEscape(account)
+
+	params := url.Values{}
+	params.Set("secret", secret)
+	params.Set("issuer", t.Issuer)
+	params.Set("algorithm", "SHA1")
+	params.Set("digits", fmt.Sprint(t.Digits))
+	params.Set("period", fmt.Sprint(int(t.Period.Seconds())))
+
+	return "otpauth://totp/" + label + "?" + params.Encode()
+}
+
+// Step 返回时间 at 所在的时间步
+func (t *TOTP) Step(at time.Time) int64 {
+	return at.Unix() / int64(t.Period.Seconds())
+}
+
+// Code 计算时间 at 的验证码
+func (t *TOTP) Code(secret string, at time.Time) (string, error) {
+	key, err := decodeTOTPSecret(secret)
+	if err != nil {
+		return "", err
+	}
+	return t.code(key, t.Step(at)), nil
+}
+
+// Validate 校验验证码，成功时返回匹配的时间步
+//
+// 调用方应记录已使用的时间步，拒绝小于等于该值的验证码，防止同一验证码被重放。
+func (t *TOTP) Validate(secret, code string, at time.Time) (int64, bool) {
+	code = strings.ReplaceAll(strings.TrimSpace(code), " ", "")
+	if len(code) != t.Digits {
+		return 0, false
+	}
+
+	key, err := decodeTOTPSecret(secret)
+	if err != nil {
+		return 0, false
+	}
+
+	current := t.Step(at)
+	for i := -t.Skew; i <= t.Skew; i++ {
+		step := current + int64(i)
+		if subtle.ConstantTimeCompare([]byte(t.code(key, step)), []byte(code)) == 1 {
+			return step, true
+		}
+	}
+	return 0, false
+}
+
+// code 计算指定时间步的验证码（RFC 4226 动态截断）
+func (t *TOTP) code(key []byte, step int64) string {
+	var msg [8]byte
+	binary.BigEndian.PutUint64(msg[:], uint64(step))
+
+	mac := hmac.New(sha1.New, key)
+	mac.Write(msg[:])
+	sum := mac.Sum(nil)
+
+	offset := sum[len(sum)-1] & 0x0f
+	value := binary.BigEndian.Uint32(sum[offset:offset+4]) & 0x7fffffff
+
+	mod := uint32(1)
+	for i := 0; i < t.Digits; i++ {
+		mod *= 10
+	}
+	return fmt.Sprintf("%0*d", t.Digits, value%mod)
+}
+
+// decodeTOTPSecret 解码 Base32 密钥（忽略大小写、空格和填充）
+func decodeTOTPSecret(secret string) ([]byte, error) {
+	secret = strings.ToUpper(strings.ReplaceAll(secret, " ", ""))
+	secret = strings.TrimRight(secret, "=")
+
+	key, err := totpEncoding.DecodeString(secret)
+	if err != nil || len(key) == 0 {
+		return nil, ErrInvalidTOTPSecret
+	}
+	return key, nil
+}
+
+// ============================================================================
+// 恢复码
+// ============================================================================
+
+// recoveryCodeAlphabet 恢复码字符集（去掉易混淆的 0/1/i/l/o）
+const recoveryCodeAlphabet = "23456789abcdefghjkmnpqrstuvwxyz"
+
+// GenerateRecoveryCodes 生成 n 个一次性恢复码（格式 xxxxx-xxxxx）
+func GenerateRecoveryCodes(n int) ([]string, error) {
+	codes := make([]string, 0, n)
+	for range n {
+		var sb strings.Builder
+		for i := range 10 {
+			if i == 5 {
+				sb.WriteByte('-')
+			}
+			c, err := randomRecoveryCodeChar()
+			if err != nil {
+				return nil, fmt.Errorf("failed to generate recovery code: %w", err)
+			}
+			sb.WriteByte(c)
+		}
+		codes = append(codes, sb.String())
+	}
+	return codes, nil
+}
+
+// randomRecoveryCodeChar 从字符集中均匀地随机取一个字符
+//
+// 丢弃大于等于 len(alphabet) 最大整数倍的字节（拒绝采样），直接取模会使前 256%31 个字符的概率偏高。
+func randomRecoveryCodeChar() (byte, error) {
+	limit := 256 - 256%len(recoveryCodeAlphabet)
+	var b [1]byte
+	for {
+		if _, err := rand.Read(b[:]); err != nil {
+			return 0, err
+		}
+		if int(b[0]) < limit {
+			return recoveryCodeAlphabet[int(b[0])%len(recoveryCodeAlphabet)], nil
+		}
+	}
+}
+
+// HashRecoveryCode 计算恢复码哈希（存储中只保存哈希，比较前统一格式）
+func HashRecoveryCode(code string) string {
+	normalized := strings.ToLower(strings.NewReplacer("-", "", " ", "").Replace(strings.TrimSpace(code)))
+	return hashToken(normalized)
+}
@@ -0,0 +1,128 @@
+package auth
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestTOTP_RFC6238 使用 RFC 6238 附录 B 的测试向量（SHA1，8 位）
+func TestTOTP_RFC6238(t *testing.T) {
+	secret := totpEncoding.EncodeToString([]byte("12345678901234567890"))
+	totp := &TOTP{Digits: 8, Period: 30 * time.Second}
+
+	tests := []struct {
+		unix int64
+		code string
+	}{
+		{59, "94287082"},
+		{1111111109, "07081804"},
+		{1111111111, "14050471"},
+		{1234567890, "89005924"},
+		{2000000000, "69279037"},
+		{20000000000, "65353130"},
+	}
+
+	for _, tt := range tests {
+		code, err := totp.Code(secret, time.Unix(tt.unix, 0))
+		require.NoError(t, err)
+		assert.Equal(t, tt.code, code, "unix=%d", tt.unix)
+	}
+}
+
+// TestTOTP_Validate 测试验证码校验
+func TestTOTP_Validate(t *testing.T) {
+	totp := NewTOTP("gin_demo")
+	secret, err := totp.GenerateSecret()
+	require.NoError(t, err)
+
+	now := time.Now()
+	code, err := totp.Code(secret, now)
+	require.NoError(t, err)
+
+	t.Run("当前验证码", func(t *testing.T) {
+		step, ok := totp.Validate(secret, code, now)
+		assert.True(t, ok)
+		assert.Equal(t, totp.Step(now), step)
+	})
+
+	t.Run("容忍一个步长的时钟误差", func(t *testing.T) {
+		_, ok := totp.Validate(secret, code, now.Add(30*time.Second))
+		assert.True(t, ok)
+	})
+
+	t.Run("超出误差范围", func(t *testing.T) {
+		_, ok := totp.Validate(secret, code, now.Add(2*time.Minute))
+		assert.False(t, ok)
+	})
+
+	t.Run("格式错误", func(t *testing.T) {
+		_, ok := totp.Validate(secret, "12345", now)
+		assert.False(t, ok)
+		_, ok = totp.Validate("not base32!", code, now)
+		assert.False(t, ok)
+	})
+}
+
+// TestTOTP_URI 测试 otpauth URI
+func TestTOTP_URI(t *testing.T) {
+	uri := NewTOTP("gin demo").URI("alice@example.com", "JBSWY3DPEHPK3PXP")
+
+	assert.True(t, strings.HasPrefix(uri, "otpauth://totp/gin%20demo:alice@example.com?"))
+	assert.Contains(t, uri, "secret=JBSWY3DPEHPK3PXP")
+	assert.Contains(t, uri, "issuer=gin+demo")
+	assert.Contains(t, uri, "digits=6")
+	assert.Contains(t, uri, "period=30")
+}
+
+// TestRecoveryCodes 测试恢复码
+func TestRecoveryCodes(t *testing.T) {
+	codes, err := GenerateRecoveryCodes(10)
+	require.NoError(t, err)
+	require.Len(t, codes, 10)
+
+	seen := make(map[string]bool)
+	for _, code := range codes {
+		assert.Len(t, code, 11)
+		assert.Equal(t, byte('-'), code[5])
+		for _, c := range strings.Replace(code, "-", "", 1) {
+			assert.True(t, strings.ContainsRune(recoveryCodeAlphabet, c), code)
+		}
+		seen[code] = true
+	}
+	assert.Len(t, seen, 10)
+
+	// 哈希忽略大小写和分隔符
+	assert.Equal(t, HashRecoveryCode(codes[0]), HashRecoveryCode(strings.ToUpper(strings.ReplaceAll(codes[0], "-", ""))))
+}
+
+// TestMFATokenManager 测试 MFA 待验证令牌不能与访问令牌混用
+func TestMFATokenManager(t *testing.T) {
+	keys := NewHMACKeySet("test-secret")
+	mfaManager := NewMFATokenManager(keys, 5*time.Minute)
+	rbacManager := NewRBACJWTManagerWithKeys(keys, time.Hour)
+
+	mfaToken, err := mfaManager.GenerateToken(3, 7, 2)
+	require.NoError(t, err)
+
+	claims, err := mfaManager.ValidateToken(mfaToken)
+	require.NoError(t, err)
+	assert.Equal(t, int64(7), claims.UserID)
+	assert.Equal(t, int64(2), claims.Version)
+	assert.Equal(t, int64(3), claims.TenantID)
+
+	// MFA 令牌不能作为访问令牌
+	_, err = rbacManager.ValidateToken(mfaToken)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	_, err = NewDefaultJWTManagerWithKeys(keys, time.Hour).ValidateToken(mfaToken)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	// 访问令牌不能作为 MFA 令牌
+	accessToken, err := rbacManager.GenerateToken(7, RoleUser)
+	require.NoError(t, err)
+	_, err = mfaManager.ValidateToken(accessToken)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
@@ -10,6 +10,7 @@ import (
 	"time"
 
 	"gin_demo/pkg/metrics"
+	"gin_demo/pkg/tenant"
 
 	"github.com/redis/go-redis/v9"
 	"golang.org/x/sync/singleflight"
@@ -34,14 +35,15 @@ func NewManager(rdb redis.UniversalClient) *Manager {
 // Key 构造工具
 // ----------------------------------------------------------------------------
 
-// BuildKey 构造主键缓存 Key: cache:user:1
-func (m *Manager) BuildKey(entity string, id any) string {
-	return fmt.Sprintf("cache:%s:%v", entity, id)
+// BuildKey 构造主键缓存 Key: cache:t1:user:1
+// Key 包含 context 中的租户 ID（见 tenant.ID），不同租户的数据不会共用缓存
+func (m *Manager) BuildKey(ctx context.Context, entity string, id any) string {
+	return fmt.Sprintf("cache:t%d:%s:%v", tenant.ID(ctx), entity, id)
 }
 
-// BuildIndexKey 构造索引缓存 Key: cache:user:email:abc@example.com
-func (m *Manager) BuildIndexKey(entity, field string, value any) string {
-	return fmt.Sprintf("cache:%s:%s:%v", entity, field, value)
+// BuildIndexKey 构造索引缓存 Key: cache:t1:user:email:abc@example.com
+func (m *Manager) BuildIndexKey(ctx context.Context, entity, field string, value any) string {
+	return fmt.Sprintf("cache:t%d:%s:%s:%v", tenant.ID(ctx), entity, field, value)
 }
 
 // getJitterTTL 在基础时间上增加随机扰动，防止缓存雪崩
@@ -60,7 +62,7 @@ func (m *Manager) getJitterTTL(baseTTL time.Duration) time.Duration {
 // ----------------------------------------------------------------------------
 
 func TakeByID[T any](ctx context.Context, m *Manager, entity string, id any, baseTTL time.Duration, queryFn func(context.Context) (T, error)) (T, error) {
-	key := m.BuildKey(entity, id)
+	key := m.BuildKey(ctx, entity, id)
 	var data T
 
 	// 1. 查缓存
@@ -128,7 +130,7 @@ func TakeByIndex[T any, ID any](ctx context.Context, m *Manager, entity, field s
 	dataQueryFn func(context.Context, ID) (T, error),
 	idConverter func(string) (ID, error)) (T, error) {
 
-	indexKey := m.BuildIndexKey(entity, field, value)
+	indexKey := m.BuildIndexKey(ctx, entity, field, value)
 	var data T
 
 	// 1. 尝试获取 ID 映射
@@ -179,7 +181,7 @@ func (m *Manager) ExecByID(ctx context.Context, entity string, id any, execFn fu
 	if err := execFn(ctx); err != nil {
 		return err
 	}
-	err := m.rdb.Del(ctx, m.BuildKey(entity, id)).Err()
+	err := m.rdb.Del(ctx, m.BuildKey(ctx, entity, id)).Err()
 	if err == nil {
 		metrics.RecordCacheDelete(entity)
 	} else {
@@ -193,7 +195,7 @@ func (m *Manager) ExecByIDWithIndexes(ctx context.Context, entity string, id any
 	if err := execFn(ctx); err != nil {
 		return err
 	}
-	keys := append([]string{m.BuildKey(entity, id)}, indexes...)
+	keys := append([]string{m.BuildKey(ctx, entity, id)}, indexes...)
 	err := m.rdb.Del(ctx, keys...).Err()
 	if err == nil {
 		// 记录批量删除
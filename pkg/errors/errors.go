@@ -10,9 +10,19 @@ type Code int
 
 // Error 业务错误（通用结构）
 type Error struct {
-	Code    Code   // 错误码
-	Message string // 错误消息
-	Err     error  // 原始错误
+	Code    Code         // 错误码
+	Message string       // 错误消息
+	Err     error        // 原始错误
+	Fields  []FieldError // 字段错误（参数校验失败时说明具体字段和原因）
+
+	origin *Error // WithFields 副本对应的原错误（用于 errors.Is）
+}
+
+// FieldError 字段错误
+type FieldError struct {
+	Field   string `json:"field"`   // 字段名（与请求 JSON 字段一致）
+	Code    string `json:"code"`    // 原因（机器可读，如 too_short）
+	Message string `json:"message"` // 提示信息
 }
 
 // Error 实现 error 接口
@@ -28,6 +38,22 @@ func (e *Error) Unwrap() error {
 	return e.Err
 }
 
+// Is 实现 errors.Is（WithFields 返回的副本与原错误匹配）
+func (e *Error) Is(target error) bool {
+	t, ok := target.(*Error)
+	return ok && e.origin != nil && e.origin == t
+}
+
+// WithFields 返回附带字段错误的副本（预定义错误是共享变量，不修改原错误）
+func (e *Error) WithFields(fields ...FieldError) *Error {
+	clone := *e
+	clone.Fields = append([]FieldError(nil), fields...)
+	if clone.origin == nil {
+		clone.origin = e
+	}
+	return &clone
+}
+
 // New 创建新错误
 func New(code Code, message string) *Error {
 	return &Error{
@@ -0,0 +1,100 @@
+// Package imaging 图片处理（按内容识别格式、缩放为正方形缩略图、重新编码）
+//
+// 只使用标准库：支持 JPEG、PNG 和 GIF（动图取第一帧），输出 JPEG。
+// 重新编码会丢弃原图中的元数据（EXIF 中的位置信息等），JPEG 的 EXIF 方向在缩放时应用。
+package imaging
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/jpeg"
+	"io"
+	"net/http"
+
+	_ "image/gif" // 注册 GIF 解码器
+	_ "image/png" // 注册 PNG 解码器
+)
+
+var (
+	// ErrUnsupportedFormat 内容不是支持的图片格式（与文件名和声明的 Content-Type 无关）
+	ErrUnsupportedFormat = errors.New("imaging: unsupported image format")
+	// ErrTooLarge 图片像素数超过限制（在解码前根据图片头判断，防止解压炸弹）
+	ErrTooLarge = errors.New("imaging: image dimensions too large")
+)
+
+// supportedTypes 支持的格式（http.DetectContentType 识别的类型）
+var supportedTypes = map[string]bool{
+	"image/jpeg": true,
+	"image/png":  true,
+	"image/gif":  true,
+}
+
+// Image 解码后的图片
+type Image struct {
+	img         image.Image
+	contentType string
+	orientation int // EXIF 方向（1-8，1 为正常）
+}
+
+// Decode 识别并解码图片（宽 × 高超过 maxPixels 时返回 ErrTooLarge）
+func Decode(data []byte, maxPixels int) (*Image, error) {
+	contentType := http.DetectContentType(data)
+	if !supportedTypes[contentType] {
+		return nil, ErrUnsupportedFormat
+	}
+
+	cfg, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+	if cfg.Width <= 0 || cfg.Height <= 0 {
+		return nil, ErrUnsupportedFormat
+	}
+	if cfg.Width > maxPixels/cfg.Height {
+		return nil, ErrTooLarge
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrUnsupportedFormat, err)
+	}
+
+	orientation := 1
+	if contentType == "image/jpeg" {
+		orientation = jpegOrientation(data)
+	}
+	return &Image{img: img, contentType: contentType, orientation: orientation}, nil
+}
+
+// ContentType 原图的格式（如 image/png）
+func (m *Image) ContentType() string {
+	return m.contentType
+}
+
+// Bounds 原图的尺寸（未应用 EXIF 方向）
+func (m *Image) Bounds() image.Rectangle {
+	return m.img.Bounds()
+}
+
+// Square 从中间裁剪出最大的正方形并缩放为 size × size（小图放大），再按 EXIF 方向旋转
+func (m *Image) Square(size int) *image.RGBA {
+	b := m.img.Bounds()
+	side := min(b.Dx(), b.Dy())
+	crop := image.Rect(0, 0, side, side).Add(b.Min).Add(image.Pt((b.Dx()-side)/2, (b.Dy()-side)/2))
+
+	// 正方形旋转或翻转后裁剪区域不变，在缩放后的小图上处理方向
+	return orient(resize(m.img, crop, size, size), m.orientation)
+}
+
+// EncodeJPEG 编码为 JPEG（透明部分使用白色背景）
+func EncodeJPEG(w io.Writer, img image.Image, quality int) error {
+	b := img.Bounds()
+	flat := image.NewRGBA(b)
+	draw.Draw(flat, b, image.NewUniform(color.White), image.Point{}, draw.Src)
+	draw.Draw(flat, b, img, b.Min, draw.Over)
+	return jpeg.Encode(w, flat, &jpeg.Options{Quality: quality})
+}
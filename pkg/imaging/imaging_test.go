@@ -0,0 +1,193 @@
+package imaging
+
+import (
+	"bytes"
+	"encoding/binary"
+	"image"
+	"image/color"
+	"image/gif"
+	"image/jpeg"
+	"image/png"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	red    = color.RGBA{R: 255, A: 255}
+	green  = color.RGBA{G: 255, A: 255}
+	blue   = color.RGBA{B: 255, A: 255}
+	yellow = color.RGBA{R: 255, G: 255, A: 255}
+)
+
+// quadrants 四个象限颜色不同的正方形图片（左上红、右上绿、左下蓝、右下黄）
+func quadrants(n int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, n, n))
+	for y := 0; y < n; y++ {
+		for x := 0; x < n; x++ {
+			c := red
+			switch {
+			case x >= n/2 && y < n/2:
+				c = green
+			case x < n/2 && y >= n/2:
+				c = blue
+			case x >= n/2 && y >= n/2:
+				c = yellow
+			}
+			img.SetRGBA(x, y, c)
+		}
+	}
+	return img
+}
+
+func encodeJPEG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, jpeg.Encode(&buf, img, &jpeg.Options{Quality: 95}))
+	return buf.Bytes()
+}
+
+func encodePNG(t *testing.T, img image.Image) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	require.NoError(t, png.Encode(&buf, img))
+	return buf.Bytes()
+}
+
+// withOrientation 在 SOI 之后插入只包含 Orientation 标签的 EXIF 段
+func withOrientation(data []byte, orientation uint16, order binary.ByteOrder) []byte {
+	tiff := make([]byte, 26)
+	if order == binary.LittleEndian {
+		copy(tiff, "II")
+	} else {
+		copy(tiff, "MM")
+	}
+	order.PutUint16(tiff[2:], 42)
+	order.PutUint32(tiff[4:], 8)       // IFD0 偏移
+	order.PutUint16(tiff[8:], 1)       // 1 个条目
+	order.PutUint16(tiff[10:], 0x0112) // Orientation
+	order.PutUint16(tiff[12:], 3)      // SHORT
+	order.PutUint32(tiff[14:], 1)      // 数量
+	order.PutUint16(tiff[18:], orientation)
+
+	segment := append([]byte("Exif\x00\x00"), tiff...)
+	app1 := []byte{0xFF, 0xE1, 0, 0}
+	binary.BigEndian.PutUint16(app1[2:], uint16(len(segment)+2))
+	app1 = append(app1, segment...)
+
+	out := append([]byte{}, data[:2]...)
+	out = append(out, app1...)
+	return append(out, data[2:]...)
+}
+
+// assertColor 颜色在 JPEG 有损压缩的误差范围内
+func assertColor(t *testing.T, want color.RGBA, got color.Color, msgAndArgs ...any) {
+	t.Helper()
+	r, g, b, _ := got.RGBA()
+	diff := func(a uint8, b uint32) int { return max(int(a)-int(b>>8), int(b>>8)-int(a)) }
+	assert.True(t, diff(want.R, r) < 40 && diff(want.G, g) < 40 && diff(want.B, b) < 40,
+		append([]any{"want %v, got %v %v %v"}, append([]any{want, r >> 8, g >> 8, b >> 8}, msgAndArgs...)...)...)
+}
+
+// TestDecode 测试按内容识别格式和尺寸限制
+func TestDecode(t *testing.T) {
+	var gifData bytes.Buffer
+	require.NoError(t, gif.Encode(&gifData, quadrants(8), nil))
+
+	for contentType, data := range map[string][]byte{
+		"image/jpeg": encodeJPEG(t, quadrants(8)),
+		"image/png":  encodePNG(t, quadrants(8)),
+		"image/gif":  gifData.Bytes(),
+	} {
+		img, err := Decode(data, 1000)
+		require.NoError(t, err, contentType)
+		assert.Equal(t, contentType, img.ContentType())
+		assert.Equal(t, image.Rect(0, 0, 8, 8), img.Bounds())
+	}
+
+	t.Run("不支持的内容", func(t *testing.T) {
+		_, err := Decode([]byte("<svg xmlns=\"http://www.w3.org/2000/svg\"></svg>"), 1000)
+		assert.ErrorIs(t, err, ErrUnsupportedFormat)
+
+		data := encodePNG(t, quadrants(8))
+		_, err = Decode(data[:len(data)/2], 1000)
+		assert.ErrorIs(t, err, ErrUnsupportedFormat, "截断的图片")
+	})
+
+	t.Run("像素数超过限制", func(t *testing.T) {
+		_, err := Decode(encodePNG(t, image.NewGray(image.Rect(0, 0, 100, 100))), 9999)
+		assert.ErrorIs(t, err, ErrTooLarge)
+	})
+}
+
+// TestSquare 测试裁剪缩放和透明背景
+func TestSquare(t *testing.T) {
+	t.Run("从中间裁剪", func(t *testing.T) {
+		// 三条竖带：红、绿、蓝，中间的正方形只有绿色
+		src := image.NewRGBA(image.Rect(0, 0, 300, 100))
+		for y := 0; y < 100; y++ {
+			for x := 0; x < 300; x++ {
+				src.SetRGBA(x, y, []color.RGBA{red, green, blue}[x/100])
+			}
+		}
+		img, err := Decode(encodePNG(t, src), 1_000_000)
+		require.NoError(t, err)
+
+		out := img.Square(64)
+		assert.Equal(t, image.Rect(0, 0, 64, 64), out.Bounds())
+		for _, p := range []image.Point{{0, 0}, {63, 0}, {0, 63}, {63, 63}, {32, 32}} {
+			assertColor(t, green, out.At(p.X, p.Y), p)
+		}
+	})
+
+	t.Run("小图放大", func(t *testing.T) {
+		img, err := Decode(encodePNG(t, quadrants(4)), 1000)
+		require.NoError(t, err)
+
+		out := img.Square(64)
+		assertColor(t, red, out.At(10, 10))
+		assertColor(t, yellow, out.At(50, 50))
+	})
+
+	t.Run("透明部分为白色背景", func(t *testing.T) {
+		img, err := Decode(encodePNG(t, image.NewNRGBA(image.Rect(0, 0, 16, 16))), 1000)
+		require.NoError(t, err)
+
+		var buf bytes.Buffer
+		require.NoError(t, EncodeJPEG(&buf, img.Square(8), 85))
+		out, err := jpeg.Decode(&buf)
+		require.NoError(t, err)
+		assertColor(t, color.RGBA{R: 255, G: 255, B: 255, A: 255}, out.At(4, 4))
+	})
+}
+
+// TestSquare_Orientation 测试按 EXIF 方向旋转
+func TestSquare_Orientation(t *testing.T) {
+	// 旋转后左上角和右上角的颜色
+	expected := map[uint16][2]color.RGBA{
+		1: {red, green},
+		2: {green, red},
+		3: {yellow, blue},
+		4: {blue, yellow},
+		5: {red, blue},
+		6: {blue, red},
+		7: {yellow, green},
+		8: {green, yellow},
+	}
+
+	data := encodeJPEG(t, quadrants(64))
+	for orientation, corners := range expected {
+		for _, order := range []binary.ByteOrder{binary.LittleEndian, binary.BigEndian} {
+			img, err := Decode(withOrientation(data, orientation, order), 1_000_000)
+			require.NoError(t, err)
+
+			out := img.Square(32)
+			assertColor(t, corners[0], out.At(4, 4), "orientation", orientation, order)
+			assertColor(t, corners[1], out.At(27, 4), "orientation", orientation, order)
+		}
+	}
+
+	assert.Equal(t, 1, jpegOrientation(data), "没有 EXIF")
+	assert.Equal(t, 1, jpegOrientation(withOrientation(data, 9, binary.BigEndian)), "无效的方向")
+}
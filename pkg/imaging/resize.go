@@ -0,0 +1,123 @@
+package imaging
+
+import (
+	"image"
+	"image/draw"
+)
+
+// contrib 一个源像素对目标像素的权重
+type contrib struct {
+	index  int
+	weight float64
+}
+
+// areaWeights 按面积计算一维缩放的权重：目标像素 i 覆盖源区间 [i*scale, (i+1)*scale)，
+// 每个重叠的源像素按重叠长度加权（缩小时为区域平均，放大时接近最近邻）
+func areaWeights(srcLen, dstLen int) [][]contrib {
+	scale := float64(srcLen) / float64(dstLen)
+	weights := make([][]contrib, dstLen)
+	for i := range weights {
+		start, end := float64(i)*scale, float64(i+1)*scale
+		for j := int(start); j < srcLen && float64(j) < end; j++ {
+			overlap := min(end, float64(j+1)) - max(start, float64(j))
+			if overlap > 0 {
+				weights[i] = append(weights[i], contrib{index: j, weight: overlap / scale})
+			}
+		}
+	}
+	return weights
+}
+
+// resize 将 src 中的 r 区域缩放为 dw × dh
+//
+// 逐行读取源图（每次只转换一行为 RGBA），先水平缩放，再按权重累加到目标行，
+// 内存占用与目标大小和源图宽度成正比，不需要转换整张原图。
+// 使用预乘 alpha 的 RGBA 计算，透明像素的颜色不会混入。
+func resize(src image.Image, r image.Rectangle, dw, dh int) *image.RGBA {
+	xWeights := areaWeights(r.Dx(), dw)
+
+	// 按源行索引：每个源行贡献到哪些目标行
+	rows := make([][]contrib, r.Dy())
+	for dy, cs := range areaWeights(r.Dy(), dh) {
+		for _, c := range cs {
+			rows[c.index] = append(rows[c.index], contrib{index: dy, weight: c.weight})
+		}
+	}
+
+	acc := make([]float64, dw*dh*4)
+	line := image.NewRGBA(image.Rect(0, 0, r.Dx(), 1))
+	scaled := make([]float64, dw*4)
+	for sy, targets := range rows {
+		if len(targets) == 0 {
+			continue
+		}
+		draw.Draw(line, line.Bounds(), src, image.Pt(r.Min.X, r.Min.Y+sy), draw.Src)
+
+		for dx, cs := range xWeights {
+			var p [4]float64
+			for _, c := range cs {
+				o := c.index * 4
+				p[0] += float64(line.Pix[o]) * c.weight
+				p[1] += float64(line.Pix[o+1]) * c.weight
+				p[2] += float64(line.Pix[o+2]) * c.weight
+				p[3] += float64(line.Pix[o+3]) * c.weight
+			}
+			copy(scaled[dx*4:], p[:])
+		}
+
+		for _, t := range targets {
+			row := acc[t.index*dw*4 : (t.index+1)*dw*4]
+			for i, v := range scaled {
+				row[i] += v * t.weight
+			}
+		}
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for i, v := range acc {
+		dst.Pix[i] = uint8(min(max(v+0.5, 0), 255))
+	}
+	return dst
+}
+
+// orient 按 EXIF 方向将图片转换为正常显示的方向
+//
+//	2 水平翻转  3 旋转 180°  4 垂直翻转  5 沿主对角线翻转
+//	6 顺时针旋转 90°  7 沿副对角线翻转  8 逆时针旋转 90°
+func orient(src *image.RGBA, orientation int) *image.RGBA {
+	if orientation < 2 || orientation > 8 {
+		return src
+	}
+
+	w, h := src.Bounds().Dx(), src.Bounds().Dy()
+	dw, dh := w, h
+	if orientation >= 5 {
+		dw, dh = h, w
+	}
+
+	dst := image.NewRGBA(image.Rect(0, 0, dw, dh))
+	for y := 0; y < dh; y++ {
+		for x := 0; x < dw; x++ {
+			// 目标像素 (x, y) 对应的源像素
+			var sx, sy int
+			switch orientation {
+			case 2:
+				sx, sy = w-1-x, y
+			case 3:
+				sx, sy = w-1-x, h-1-y
+			case 4:
+				sx, sy = x, h-1-y
+			case 5:
+				sx, sy = y, x
+			case 6:
+				sx, sy = y, h-1-x
+			case 7:
+				sx, sy = w-1-y, h-1-x
+			case 8:
+				sx, sy = w-1-y, x
+			}
+			copy(dst.Pix[dst.PixOffset(x, y):dst.PixOffset(x, y)+4], src.Pix[src.PixOffset(sx, sy):src.PixOffset(sx, sy)+4])
+		}
+	}
+	return dst
+}
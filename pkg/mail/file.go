@@ -0,0 +1,67 @@
+package mail
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// FileMailer 将邮件写入目录（每封邮件一个 .eml 文件，用于开发和测试）
+type FileMailer struct {
+	dir  string
+	from string
+}
+
+// NewFileMailer 创建文件邮件发送器
+func NewFileMailer(dir, from string) *FileMailer {
+	return &FileMailer{dir: dir, from: from}
+}
+
+// Send 将邮件写入 <dir>/<时间戳>-<随机串>.eml
+func (m *FileMailer) Send(ctx context.Context, msg Message) error {
+	now := time.Now()
+	data, _, err := build(m.from, msg, now)
+	if err != nil {
+		return err
+	}
+
+	if err := os.MkdirAll(m.dir, 0o755); err != nil {
+		return fmt.Errorf("mail: create dir: %w", err)
+	}
+
+	suffix := make([]byte, 4)
+	if _, err := rand.Read(suffix); err != nil {
+		return fmt.Errorf("mail: generate file name: %w", err)
+	}
+	name := filepath.Join(m.dir, now.Format("20060102T150405.000000000")+"-"+hex.EncodeToString(suffix)+".eml")
+
+	if err := os.WriteFile(name, data, 0o600); err != nil {
+		return fmt.Errorf("mail: write file: %w", err)
+	}
+
+	slog.DebugContext(ctx, "Mail written to file", "to", msg.To, "subject", msg.Subject, "file", name)
+	return nil
+}
+
+// LogMailer 将邮件输出到日志（仅用于开发环境，正文中的链接会出现在日志里）
+type LogMailer struct{}
+
+// NewLogMailer 创建日志邮件发送器
+func NewLogMailer() *LogMailer {
+	return &LogMailer{}
+}
+
+// Send 以 Info 级别记录邮件内容
+func (m *LogMailer) Send(ctx context.Context, msg Message) error {
+	if len(msg.To) == 0 {
+		return fmt.Errorf("%w: no recipients", ErrInvalidMessage)
+	}
+
+	slog.InfoContext(ctx, "Mail (log driver)", "to", msg.To, "subject", msg.Subject, "body", msg.Body)
+	return nil
+}
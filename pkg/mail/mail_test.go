@@ -0,0 +1,178 @@
+package mail
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"io"
+	"mime"
+	"net"
+	"net/mail"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeSMTPServer 最简 SMTP 服务器（不支持 STARTTLS 和认证），记录收到的信封和正文
+type fakeSMTPServer struct {
+	listener net.Listener
+	from     string
+	rcpts    []string
+	data     string
+	done     chan struct{}
+}
+
+func newFakeSMTPServer(t *testing.T) *fakeSMTPServer {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = l.Close() })
+
+	s := &fakeSMTPServer{listener: l, done: make(chan struct{})}
+	go s.serve()
+	return s
+}
+
+func (s *fakeSMTPServer) serve() {
+	defer close(s.done)
+
+	conn, err := s.listener.Accept()
+	if err != nil {
+		return
+	}
+	defer conn.Close()
+
+	r := bufio.NewReader(conn)
+	reply := func(line string) { _, _ = conn.Write([]byte(line + "\r\n")) }
+
+	reply("220 fake ESMTP")
+	for {
+		line, err := r.ReadString('\n')
+		if err != nil {
+			return
+		}
+		cmd := strings.TrimRight(line, "\r\n")
+		upper := strings.ToUpper(cmd)
+
+		switch {
+		case strings.HasPrefix(upper, "EHLO"), strings.HasPrefix(upper, "HELO"):
+			reply("250 fake")
+		case strings.HasPrefix(upper, "MAIL FROM:"):
+			s.from = strings.Trim(cmd[len("MAIL FROM:"):], "<> ")
+			reply("250 OK")
+		case strings.HasPrefix(upper, "RCPT TO:"):
+			s.rcpts = append(s.rcpts, strings.Trim(cmd[len("RCPT TO:"):], "<> "))
+			reply("250 OK")
+		case upper == "DATA":
+			reply("354 go ahead")
+			var data strings.Builder
+			for {
+				l, err := r.ReadString('\n')
+				if err != nil {
+					return
+				}
+				if l == ".\r\n" {
+					break
+				}
+				data.WriteString(l)
+			}
+			s.data = data.String()
+			reply("250 OK")
+		case upper == "QUIT":
+			reply("221 bye")
+			return
+		default:
+			reply("502 not implemented")
+		}
+	}
+}
+
+// decodeBody 解析邮件并解码 base64 正文
+func decodeBody(t *testing.T, raw string) (*mail.Message, string) {
+	msg, err := mail.ReadMessage(strings.NewReader(raw))
+	require.NoError(t, err)
+
+	body, err := io.ReadAll(base64.NewDecoder(base64.StdEncoding, msg.Body))
+	require.NoError(t, err)
+	return msg, string(body)
+}
+
+// TestSMTPMailer_Send 测试通过 SMTP 发送邮件
+func TestSMTPMailer_Send(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	host, port, _ := net.SplitHostPort(server.listener.Addr().String())
+	portNum, _ := strconv.Atoi(port)
+
+	mailer := NewSMTPMailer(SMTPConfig{
+		Host:    host,
+		Port:    portNum,
+		From:    "gin_demo <noreply@example.com>",
+		TLSMode: TLSModeNone,
+	})
+
+	err := mailer.Send(context.Background(), Message{
+		To:      []string{"Alice <alice@example.com>"},
+		Subject: "验证邮箱",
+		Body:    "请点击链接完成验证",
+	})
+	require.NoError(t, err)
+	<-server.done
+
+	assert.Equal(t, "noreply@example.com", server.from)
+	assert.Equal(t, []string{"alice@example.com"}, server.rcpts)
+
+	msg, body := decodeBody(t, server.data)
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	require.NoError(t, err)
+	assert.Equal(t, "验证邮箱", subject)
+	assert.Equal(t, "请点击链接完成验证", body)
+}
+
+// TestSMTPMailer_RequireStartTLS 测试服务器不支持 STARTTLS 时拒绝明文发送
+func TestSMTPMailer_RequireStartTLS(t *testing.T) {
+	server := newFakeSMTPServer(t)
+	host, port, _ := net.SplitHostPort(server.listener.Addr().String())
+	portNum, _ := strconv.Atoi(port)
+
+	mailer := NewSMTPMailer(SMTPConfig{Host: host, Port: portNum, From: "noreply@example.com"})
+
+	err := mailer.Send(context.Background(), Message{To: []string{"alice@example.com"}, Subject: "hi", Body: "hi"})
+	assert.ErrorContains(t, err, "STARTTLS")
+}
+
+// TestFileMailer_Send 测试写入文件
+func TestFileMailer_Send(t *testing.T) {
+	dir := t.TempDir()
+	mailer := NewFileMailer(dir, "noreply@example.com")
+
+	require.NoError(t, mailer.Send(context.Background(), Message{
+		To:      []string{"alice@example.com"},
+		Subject: "Reset\r\nBcc: evil@example.com",
+		Body:    "body",
+	}))
+
+	files, err := filepath.Glob(filepath.Join(dir, "*.eml"))
+	require.NoError(t, err)
+	require.Len(t, files, 1)
+
+	raw, err := os.ReadFile(files[0])
+	require.NoError(t, err)
+	msg, body := decodeBody(t, string(raw))
+	assert.Empty(t, msg.Header.Get("Bcc"), "主题中的换行不能注入邮件头")
+	assert.Equal(t, "body", body)
+}
+
+// TestBuild_InvalidAddress 测试非法地址
+func TestBuild_InvalidAddress(t *testing.T) {
+	mailer := NewFileMailer(t.TempDir(), "noreply@example.com")
+
+	err := mailer.Send(context.Background(), Message{To: []string{"not-an-address"}})
+	assert.ErrorIs(t, err, ErrInvalidMessage)
+
+	err = mailer.Send(context.Background(), Message{})
+	assert.ErrorIs(t, err, ErrInvalidMessage)
+}
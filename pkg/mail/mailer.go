@@ -0,0 +1,78 @@
+package mail
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"mime"
+	"net/mail"
+	"strings"
+	"time"
+)
+
+// ErrInvalidMessage 邮件内容不合法（收件人为空或地址格式错误）
+var ErrInvalidMessage = errors.New("invalid mail message")
+
+// Message 邮件（纯文本）
+type Message struct {
+	To      []string
+	Subject string
+	Body    string
+}
+
+// Mailer 邮件发送接口
+//
+// 生产环境使用 SMTPMailer，开发和测试环境使用 FileMailer 或 LogMailer。
+type Mailer interface {
+	// Send 发送邮件
+	Send(ctx context.Context, msg Message) error
+}
+
+// build 构建 RFC 5322 格式的邮件内容
+//
+// 主题使用 RFC 2047 编码，正文使用 base64 编码，避免非 ASCII 字符和超长行。
+func build(from string, msg Message, now time.Time) ([]byte, []string, error) {
+	sender, err := mail.ParseAddress(from)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w: from: %v", ErrInvalidMessage, err)
+	}
+	if len(msg.To) == 0 {
+		return nil, nil, fmt.Errorf("%w: no recipients", ErrInvalidMessage)
+	}
+
+	recipients := make([]string, 0, len(msg.To))
+	headerTo := make([]string, 0, len(msg.To))
+	for _, to := range msg.To {
+		addr, err := mail.ParseAddress(to)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%w: to %q: %v", ErrInvalidMessage, to, err)
+		}
+		recipients = append(recipients, addr.Address)
+		headerTo = append(headerTo, addr.String())
+	}
+
+	// 去掉换行，防止邮件头注入
+	subject := strings.NewReplacer("\r", "", "\n", "").Replace(msg.Subject)
+
+	var buf bytes.Buffer
+	buf.WriteString("From: " + sender.String() + "\r\n")
+	buf.WriteString("To: " + strings.Join(headerTo, ", ") + "\r\n")
+	buf.WriteString("Subject: " + mime.BEncoding.Encode("UTF-8", subject) + "\r\n")
+	buf.WriteString("Date: " + now.Format(time.RFC1123Z) + "\r\n")
+	buf.WriteString("MIME-Version: 1.0\r\n")
+	buf.WriteString("Content-Type: text/plain; charset=UTF-8\r\n")
+	buf.WriteString("Content-Transfer-Encoding: base64\r\n")
+	buf.WriteString("\r\n")
+
+	// 每行 76 个字符（RFC 2045）
+	encoded := base64.StdEncoding.EncodeToString([]byte(msg.Body))
+	for len(encoded) > 76 {
+		buf.WriteString(encoded[:76] + "\r\n")
+		encoded = encoded[76:]
+	}
+	buf.WriteString(encoded + "\r\n")
+
+	return buf.Bytes(), recipients, nil
+}
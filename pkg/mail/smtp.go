@@ -0,0 +1,139 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"strconv"
+	"time"
+)
+
+// SMTP 连接加密方式
+const (
+	// TLSModeStartTLS 明文连接后升级为 TLS（默认，服务器不支持时拒绝发送）
+	TLSModeStartTLS = "starttls"
+	// TLSModeImplicit 直接建立 TLS 连接（通常是 465 端口）
+	TLSModeImplicit = "tls"
+	// TLSModeNone 不加密（仅用于本地邮件调试工具，如 MailHog）
+	TLSModeNone = "none"
+)
+
+// SMTPConfig SMTP 配置
+type SMTPConfig struct {
+	Host     string
+	Port     int
+	Username string // 为空时不认证
+	Password string
+	From     string        // 发件人，如 "gin_demo <noreply@example.com>"
+	TLSMode  string        // starttls（默认）/ tls / none
+	Timeout  time.Duration // 单封邮件的发送超时（ctx 没有截止时间时使用）
+}
+
+// SMTPMailer SMTP 邮件发送器（每封邮件一个连接）
+type SMTPMailer struct {
+	config SMTPConfig
+}
+
+// NewSMTPMailer 创建 SMTP 邮件发送器
+func NewSMTPMailer(config SMTPConfig) *SMTPMailer {
+	if config.TLSMode == "" {
+		config.TLSMode = TLSModeStartTLS
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 10 * time.Second
+	}
+	return &SMTPMailer{config: config}
+}
+
+// Send 发送邮件
+func (m *SMTPMailer) Send(ctx context.Context, msg Message) error {
+	data, recipients, err := build(m.config.From, msg, time.Now())
+	if err != nil {
+		return err
+	}
+	sender, _ := mail.ParseAddress(m.config.From) // build 中已校验
+
+	conn, err := m.dial(ctx)
+	if err != nil {
+		return fmt.Errorf("mail: dial smtp: %w", err)
+	}
+
+	client, err := smtp.NewClient(conn, m.config.Host)
+	if err != nil {
+		_ = conn.Close()
+		return fmt.Errorf("mail: smtp handshake: %w", err)
+	}
+	defer client.Close()
+
+	if m.config.TLSMode == TLSModeStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("mail: smtp server %s does not support STARTTLS", m.config.Host)
+		}
+		if err := client.StartTLS(&tls.Config{ServerName: m.config.Host, MinVersion: tls.VersionTLS12}); err != nil {
+			return fmt.Errorf("mail: starttls: %w", err)
+		}
+	}
+
+	if m.config.Username != "" {
+		auth := smtp.PlainAuth("", m.config.Username, m.config.Password, m.config.Host)
+		if err := client.Auth(auth); err != nil {
+			return fmt.Errorf("mail: smtp auth: %w", err)
+		}
+	}
+
+	if err := client.Mail(sender.Address); err != nil {
+		return fmt.Errorf("mail: smtp MAIL FROM: %w", err)
+	}
+	for _, rcpt := range recipients {
+		if err := client.Rcpt(rcpt); err != nil {
+			return fmt.Errorf("mail: smtp RCPT TO %s: %w", rcpt, err)
+		}
+	}
+
+	w, err := client.Data()
+	if err != nil {
+		return fmt.Errorf("mail: smtp DATA: %w", err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return fmt.Errorf("mail: write message: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return fmt.Errorf("mail: smtp DATA: %w", err)
+	}
+
+	return client.Quit()
+}
+
+// dial 建立连接（超时取 ctx 截止时间和配置超时中较早的一个）
+func (m *SMTPMailer) dial(ctx context.Context) (net.Conn, error) {
+	ctx, cancel := context.WithTimeout(ctx, m.config.Timeout)
+	defer cancel()
+
+	addr := net.JoinHostPort(m.config.Host, strconv.Itoa(m.config.Port))
+
+	var (
+		conn net.Conn
+		err  error
+	)
+	if m.config.TLSMode == TLSModeImplicit {
+		dialer := &tls.Dialer{Config: &tls.Config{ServerName: m.config.Host, MinVersion: tls.VersionTLS12}}
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	} else {
+		var dialer net.Dialer
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	// 整个 SMTP 会话共享同一个截止时间
+	deadline, _ := ctx.Deadline()
+	if err := conn.SetDeadline(deadline); err != nil {
+		_ = conn.Close()
+		return nil, err
+	}
+	return conn, nil
+}
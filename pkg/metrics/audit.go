@@ -0,0 +1,23 @@
+package metrics
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+// ============================================================================
+// 审计日志指标
+// ============================================================================
+
+var (
+	// 审计事件计数
+	AuditEvents = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "audit_events_total",
+		Help: "Total number of audit events",
+	}, []string{"result"}) // result: written, dropped, failed
+)
+
+// RecordAuditEvents 记录审计事件写入结果
+func RecordAuditEvents(result string, count int) {
+	AuditEvents.WithLabelValues(result).Add(float64(count))
+}
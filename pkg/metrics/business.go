@@ -80,7 +80,7 @@ var (
 	AuthFailures = promauto.NewCounterVec(prometheus.CounterOpts{
 		Name: "auth_failures_total",
 		Help: "Total number of authentication failures",
-	}, []string{"reason"}) // reason: invalid_credentials, expired_token, invalid_token
+	}, []string{"reason"}) // reason: invalid_credentials, expired_token, invalid_token, token_revoked, locked
 )
 
 // ============================================================================
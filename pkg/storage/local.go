@@ -0,0 +1,141 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// LocalStore 本地文件系统存储（对象键对应 dir 下的相对路径，由应用通过 Handler 提供访问）
+//
+// 多实例部署时 dir 需要使用共享存储，否则使用 S3Store。
+type LocalStore struct {
+	dir     string
+	baseURL string
+}
+
+// NewLocalStore 创建本地文件系统存储
+//
+// baseURL 为访问地址前缀，可以是路径（如 /uploads）或完整地址（如 https://example.com/uploads），
+// 其路径部分就是 Handler 处理的路由前缀。
+func NewLocalStore(dir, baseURL string) *LocalStore {
+	return &LocalStore{dir: dir, baseURL: strings.TrimSuffix(baseURL, "/")}
+}
+
+// Put 写入文件（先写临时文件再重命名，读取时不会读到不完整的文件）
+func (s *LocalStore) Put(_ context.Context, key string, r io.Reader, size int64, _ PutOptions) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+
+	name := s.filePath(key)
+	if err := os.MkdirAll(filepath.Dir(name), 0o755); err != nil {
+		return fmt.Errorf("storage: create dir: %w", err)
+	}
+
+	tmp, err := os.CreateTemp(filepath.Dir(name), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("storage: create temp file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) // 重命名成功后删除失败，忽略
+
+	n, err := io.Copy(tmp, r)
+	if closeErr := tmp.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("storage: write %s: %w", key, err)
+	}
+	if n != size {
+		return fmt.Errorf("storage: write %s: wrote %d bytes, expected %d", key, n, size)
+	}
+
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return fmt.Errorf("storage: chmod %s: %w", key, err)
+	}
+	if err := os.Rename(tmp.Name(), name); err != nil {
+		return fmt.Errorf("storage: rename %s: %w", key, err)
+	}
+	return nil
+}
+
+// Delete 删除文件，并删除因此变空的上级目录
+func (s *LocalStore) Delete(_ context.Context, key string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+
+	if err := os.Remove(s.filePath(key)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("storage: delete %s: %w", key, err)
+	}
+
+	// 目录不为空时 Remove 失败，停止向上删除
+	for dir := path.Dir(key); dir != "."; dir = path.Dir(dir) {
+		if os.Remove(s.filePath(dir)) != nil {
+			break
+		}
+	}
+	return nil
+}
+
+// URL 文件的访问地址
+func (s *LocalStore) URL(key string) string {
+	return s.baseURL + "/" + key
+}
+
+// KeyFromURL 从访问地址解析对象键
+func (s *LocalStore) KeyFromURL(url string) (string, bool) {
+	return keyFromURL(s.baseURL, url)
+}
+
+// Path 访问地址的路径部分（Handler 处理的路由前缀，如 /uploads）
+func (s *LocalStore) Path() string {
+	if u, err := url.Parse(s.baseURL); err == nil {
+		return u.Path
+	}
+	return s.baseURL
+}
+
+// Handler 提供文件访问的 HTTP 处理器（GET / HEAD {Path}/{key}）
+//
+// 不列出目录，不提供写入过程中的临时文件；cacheControl 为响应的 Cache-Control 头。
+func (s *LocalStore) Handler(cacheControl string) http.Handler {
+	prefix := s.Path() + "/"
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		key, ok := strings.CutPrefix(r.URL.Path, prefix)
+		if !ok || validateKey(key) != nil || strings.Contains("/"+key, "/.") {
+			http.NotFound(w, r)
+			return
+		}
+
+		file, err := os.Open(s.filePath(key))
+		if err != nil {
+			http.NotFound(w, r)
+			return
+		}
+		defer file.Close()
+
+		info, err := file.Stat()
+		if err != nil || info.IsDir() {
+			http.NotFound(w, r)
+			return
+		}
+
+		w.Header().Set("Cache-Control", cacheControl)
+		w.Header().Set("X-Content-Type-Options", "nosniff")
+		http.ServeContent(w, r, info.Name(), info.ModTime(), file)
+	})
+}
+
+// filePath 对象键对应的文件路径
+func (s *LocalStore) filePath(key string) string {
+	return filepath.Join(s.dir, filepath.FromSlash(key))
+}
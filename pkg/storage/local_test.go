@@ -0,0 +1,77 @@
+package storage
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestLocalStore 测试本地文件系统存储
+func TestLocalStore(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	store := NewLocalStore(dir, "https://example.com/uploads/")
+
+	t.Run("写入、访问和删除", func(t *testing.T) {
+		key := "avatars/1/42/abc/256.jpg"
+		require.NoError(t, store.Put(ctx, key, strings.NewReader("jpeg data"), 9, PutOptions{ContentType: "image/jpeg"}))
+
+		data, err := os.ReadFile(filepath.Join(dir, "avatars", "1", "42", "abc", "256.jpg"))
+		require.NoError(t, err)
+		assert.Equal(t, "jpeg data", string(data))
+
+		url := store.URL(key)
+		assert.Equal(t, "https://example.com/uploads/"+key, url)
+		got, ok := store.KeyFromURL(url)
+		assert.True(t, ok)
+		assert.Equal(t, key, got)
+
+		assert.Equal(t, "/uploads", store.Path())
+		w := httptest.NewRecorder()
+		store.Handler("public, max-age=60").ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/uploads/"+key, nil))
+		assert.Equal(t, http.StatusOK, w.Code)
+		assert.Equal(t, "image/jpeg", w.Header().Get("Content-Type"))
+		assert.Equal(t, "public, max-age=60", w.Header().Get("Cache-Control"))
+		assert.Equal(t, "jpeg data", w.Body.String())
+
+		require.NoError(t, store.Delete(ctx, key))
+		_, err = os.Stat(filepath.Join(dir, "avatars"))
+		assert.True(t, os.IsNotExist(err), "删除后空的上级目录同样删除")
+		assert.NoError(t, store.Delete(ctx, key), "文件不存在时删除成功")
+	})
+
+	t.Run("长度不一致时不写入", func(t *testing.T) {
+		err := store.Put(ctx, "a.jpg", strings.NewReader("short"), 10, PutOptions{})
+		assert.Error(t, err)
+		entries, _ := os.ReadDir(dir)
+		assert.Empty(t, entries, "不留下临时文件")
+	})
+
+	t.Run("不提供目录和临时文件", func(t *testing.T) {
+		require.NoError(t, store.Put(ctx, "avatars/a.jpg", strings.NewReader("x"), 1, PutOptions{}))
+		require.NoError(t, os.WriteFile(filepath.Join(dir, "avatars", ".tmp-1"), []byte("x"), 0o644))
+
+		for _, path := range []string{"/uploads/avatars", "/uploads/avatars/", "/uploads/avatars/.tmp-1", "/uploads/../secret", "/other/avatars/a.jpg"} {
+			w := httptest.NewRecorder()
+			store.Handler("").ServeHTTP(w, httptest.NewRequest(http.MethodGet, "http://example.com"+path, nil))
+			assert.Equal(t, http.StatusNotFound, w.Code, path)
+		}
+	})
+
+	t.Run("非法的对象键", func(t *testing.T) {
+		for _, key := range []string{"", "/a.jpg", "a/../../b.jpg", "a//b.jpg", `a\b.jpg`} {
+			assert.ErrorIs(t, store.Put(ctx, key, strings.NewReader("x"), 1, PutOptions{}), ErrInvalidKey, key)
+		}
+		_, ok := store.KeyFromURL("https://example.com/uploads/../etc/passwd")
+		assert.False(t, ok)
+		_, ok = store.KeyFromURL("https://evil.example.com/uploads/a.jpg")
+		assert.False(t, ok)
+	})
+}
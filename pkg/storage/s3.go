@@ -0,0 +1,280 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// S3Config S3 兼容存储配置
+type S3Config struct {
+	// 服务地址，如 https://s3.us-east-1.amazonaws.com、http://localhost:9000（MinIO）
+	Endpoint string
+
+	// 区域（MinIO 默认 us-east-1）
+	Region string
+
+	// 存储桶
+	Bucket string
+
+	// 访问密钥
+	AccessKeyID     string
+	SecretAccessKey string
+
+	// 使用路径风格地址（{endpoint}/{bucket}/{key}，MinIO 等需要开启），否则使用虚拟主机风格（{bucket}.{host}/{key}）
+	PathStyle bool
+
+	// 对象访问地址前缀（如 CDN 地址），默认为存储桶地址；存储桶需要允许公开读取
+	PublicURL string
+
+	// HTTP 客户端（默认使用 30 秒超时的客户端）
+	Client *http.Client
+}
+
+// S3Store S3 兼容存储（使用 AWS Signature Version 4 签名请求）
+type S3Store struct {
+	config    S3Config
+	bucketURL *url.URL
+	publicURL string
+	client    *http.Client
+	now       func() time.Time
+}
+
+// NewS3Store 创建 S3 兼容存储
+func NewS3Store(config S3Config) (*S3Store, error) {
+	endpoint, err := url.Parse(strings.TrimSuffix(config.Endpoint, "/"))
+	if err != nil || endpoint.Scheme == "" || endpoint.Host == "" {
+		return nil, fmt.Errorf("storage: invalid s3 endpoint %q", config.Endpoint)
+	}
+	if config.Region == "" || config.Bucket == "" || config.AccessKeyID == "" || config.SecretAccessKey == "" {
+		return nil, errors.New("storage: s3 region, bucket and credentials are required")
+	}
+
+	bucketURL := *endpoint
+	if config.PathStyle {
+		bucketURL.Path += "/" + config.Bucket
+	} else {
+		bucketURL.Host = config.Bucket + "." + endpoint.Host
+	}
+
+	publicURL := strings.TrimSuffix(config.PublicURL, "/")
+	if publicURL == "" {
+		publicURL = bucketURL.String()
+	}
+
+	client := config.Client
+	if client == nil {
+		client = &http.Client{Timeout: 30 * time.Second}
+	}
+
+	return &S3Store{
+		config:    config,
+		bucketURL: &bucketURL,
+		publicURL: publicURL,
+		client:    client,
+		now:       time.Now,
+	}, nil
+}
+
+// Put 上传对象（PutObject）
+//
+// 内容不参与签名（UNSIGNED-PAYLOAD），上传时不需要先读取整个内容计算哈希；生产环境应使用 HTTPS。
+func (s *S3Store) Put(ctx context.Context, key string, r io.Reader, size int64, opts PutOptions) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, s.objectURL(key), r)
+	if err != nil {
+		return fmt.Errorf("storage: s3 put %s: %w", key, err)
+	}
+	req.ContentLength = size
+	if opts.ContentType != "" {
+		req.Header.Set("Content-Type", opts.ContentType)
+	}
+	if opts.CacheControl != "" {
+		req.Header.Set("Cache-Control", opts.CacheControl)
+	}
+
+	resp, err := s.do(req, unsignedPayload)
+	if err != nil {
+		return fmt.Errorf("storage: s3 put %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("storage: s3 put %s: %w", key, responseError(resp))
+	}
+	return nil
+}
+
+// Delete 删除对象（DeleteObject，对象不存在时 S3 同样返回 204；404 表示存储桶不存在）
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	if err := validateKey(key); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodDelete, s.objectURL(key), nil)
+	if err != nil {
+		return fmt.Errorf("storage: s3 delete %s: %w", key, err)
+	}
+
+	resp, err := s.do(req, emptyPayloadHash)
+	if err != nil {
+		return fmt.Errorf("storage: s3 delete %s: %w", key, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNoContent && resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("storage: s3 delete %s: %w", key, responseError(resp))
+	}
+	return nil
+}
+
+// URL 对象的访问地址
+func (s *S3Store) URL(key string) string {
+	return s.publicURL + "/" + key
+}
+
+// KeyFromURL 从访问地址解析对象键
+func (s *S3Store) KeyFromURL(url string) (string, bool) {
+	return keyFromURL(s.publicURL, url)
+}
+
+// objectURL 对象的 API 地址
+func (s *S3Store) objectURL(key string) string {
+	u := *s.bucketURL
+	u.Path += "/" + key
+	u.RawPath = ""
+	return u.String()
+}
+
+// do 签名并发送请求
+func (s *S3Store) do(req *http.Request, payloadHash string) (*http.Response, error) {
+	s.sign(req, payloadHash, s.now())
+	return s.client.Do(req)
+}
+
+// ========================================
+// AWS Signature Version 4
+// ========================================
+
+const (
+	signAlgorithm    = "AWS4-HMAC-SHA256"
+	unsignedPayload  = "UNSIGNED-PAYLOAD"
+	emptyPayloadHash = "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855" // 空内容的 SHA-256
+)
+
+// sign 为请求添加 x-amz-date、x-amz-content-sha256 和 Authorization 头
+//
+// 签名包含 Host 和请求中已有的所有头（发送时由 Transport 添加的 User-Agent、Content-Length 等除外）。
+func (s *S3Store) sign(req *http.Request, payloadHash string, now time.Time) {
+	now = now.UTC()
+	amzDate := now.Format("20060102T150405Z")
+	date := now.Format("20060102")
+	req.Header.Set("X-Amz-Date", amzDate)
+	req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+
+	headers := map[string]string{"host": req.URL.Host}
+	for name, values := range req.Header {
+		headers[strings.ToLower(name)] = strings.TrimSpace(strings.Join(values, ","))
+	}
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonicalHeaders strings.Builder
+	for _, name := range names {
+		canonicalHeaders.WriteString(name + ":" + headers[name] + "\n")
+	}
+	signedHeaders := strings.Join(names, ";")
+
+	canonicalRequest := strings.Join([]string{
+		req.Method,
+		uriEncode(req.URL.Path, false),
+		canonicalQuery(req.URL.Query()),
+		canonicalHeaders.String(),
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+
+	scope := date + "/" + s.config.Region + "/s3/aws4_request"
+	stringToSign := strings.Join([]string{signAlgorithm, amzDate, scope, sha256Hex(canonicalRequest)}, "\n")
+
+	key := hmacSHA256([]byte("AWS4"+s.config.SecretAccessKey), date)
+	key = hmacSHA256(key, s.config.Region)
+	key = hmacSHA256(key, "s3")
+	key = hmacSHA256(key, "aws4_request")
+	signature := hex.EncodeToString(hmacSHA256(key, stringToSign))
+
+	req.Header.Set("Authorization", fmt.Sprintf("%s Credential=%s/%s, SignedHeaders=%s, Signature=%s",
+		signAlgorithm, s.config.AccessKeyID, scope, signedHeaders, signature))
+}
+
+// canonicalQuery 按参数名排序并编码的查询字符串
+func canonicalQuery(query url.Values) string {
+	pairs := make([]string, 0, len(query))
+	for name, values := range query {
+		for _, value := range values {
+			pairs = append(pairs, uriEncode(name, true)+"="+uriEncode(value, true))
+		}
+	}
+	sort.Strings(pairs)
+	return strings.Join(pairs, "&")
+}
+
+// uriEncode 按 SigV4 的规则编码（只保留 A-Z a-z 0-9 - _ . ~，路径中的 / 不编码）
+func uriEncode(s string, encodeSlash bool) string {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		switch {
+		case 'A' <= c && c <= 'Z', 'a' <= c && c <= 'z', '0' <= c && c <= '9', c == '-', c == '_', c == '.', c == '~':
+			b.WriteByte(c)
+		case c == '/' && !encodeSlash:
+			b.WriteByte(c)
+		default:
+			fmt.Fprintf(&b, "%%%02X", c)
+		}
+	}
+	return b.String()
+}
+
+func sha256Hex(s string) string {
+	sum := sha256.Sum256([]byte(s))
+	return hex.EncodeToString(sum[:])
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// s3Error S3 错误响应
+type s3Error struct {
+	Code    string `xml:"Code"`
+	Message string `xml:"Message"`
+}
+
+// responseError 将错误响应转换为 error（包含 S3 错误码，便于排查权限和配置问题）
+func responseError(resp *http.Response) error {
+	body, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	var e s3Error
+	if xml.Unmarshal(body, &e) == nil && e.Code != "" {
+		return fmt.Errorf("status %d: %s: %s", resp.StatusCode, e.Code, e.Message)
+	}
+	return fmt.Errorf("status %d", resp.StatusCode)
+}
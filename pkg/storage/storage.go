@@ -0,0 +1,60 @@
+// Package storage 对象存储（用户上传的文件，如头像）
+//
+// 提供本地文件系统（LocalStore）和 S3 兼容服务（S3Store，包括 AWS S3、MinIO 等）两种实现，
+// 业务代码只依赖 BlobStore 接口。
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"strings"
+)
+
+// ErrInvalidKey 对象键不合法（为空、以 / 开头或包含 . / .. 路径段）
+var ErrInvalidKey = errors.New("storage: invalid key")
+
+// PutOptions 写入对象的元数据
+type PutOptions struct {
+	ContentType  string // 如 image/jpeg
+	CacheControl string // 如 public, max-age=31536000, immutable（LocalStore 忽略，由 Handler 统一设置）
+}
+
+// BlobStore 对象存储接口
+//
+// 对象键使用 / 分隔的路径（如 avatars/1/42/3f2a/256.jpg），写入同一个键会覆盖原对象。
+type BlobStore interface {
+	// Put 写入对象（size 为内容长度）
+	Put(ctx context.Context, key string, r io.Reader, size int64, opts PutOptions) error
+
+	// Delete 删除对象（对象不存在时不返回错误）
+	Delete(ctx context.Context, key string) error
+
+	// URL 对象的访问地址
+	URL(key string) string
+
+	// KeyFromURL 从访问地址解析对象键（不是该存储的地址时返回 false）
+	KeyFromURL(url string) (string, bool)
+}
+
+// validateKey 校验对象键（防止路径穿越，两种实现使用相同的规则）
+func validateKey(key string) error {
+	if key == "" || strings.HasPrefix(key, "/") || strings.Contains(key, "\\") {
+		return ErrInvalidKey
+	}
+	for _, segment := range strings.Split(key, "/") {
+		if segment == "" || segment == "." || segment == ".." {
+			return ErrInvalidKey
+		}
+	}
+	return nil
+}
+
+// keyFromURL 去掉地址前缀得到对象键
+func keyFromURL(baseURL, url string) (string, bool) {
+	key, ok := strings.CutPrefix(url, baseURL+"/")
+	if !ok || validateKey(key) != nil {
+		return "", false
+	}
+	return key, true
+}
@@ -0,0 +1,372 @@
+package task
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+var (
+	// ErrJobNotFound 作业不存在（或记录已过期）
+	ErrJobNotFound = errors.New("job not found")
+	// ErrUnknownJobType 没有注册该类型的作业处理函数
+	ErrUnknownJobType = errors.New("unknown job type")
+)
+
+// JobStatus 后台作业状态
+type JobStatus string
+
+const (
+	JobPending   JobStatus = "pending"   // 等待执行
+	JobRunning   JobStatus = "running"   // 执行中
+	JobSucceeded JobStatus = "succeeded" // 执行成功
+	JobFailed    JobStatus = "failed"    // 执行失败
+)
+
+// Finished 作业是否已结束
+func (s JobStatus) Finished() bool {
+	return s == JobSucceeded || s == JobFailed
+}
+
+// JobProgress 作业进度（Total 为 0 表示总量未知）
+type JobProgress struct {
+	Done  int64 `json:"done"`
+	Total int64 `json:"total"`
+}
+
+// Job 后台作业
+type Job struct {
+	ID         string          `json:"id"`
+	Type       string          `json:"type"`
+	Owner      string          `json:"owner"`                // 提交者标识（由调用方定义，用于查询时校验归属）
+	UniqueKey  string          `json:"unique_key,omitempty"` // 唯一键（见 SubmitOptions）
+	Status     JobStatus       `json:"status"`
+	Payload    json.RawMessage `json:"payload,omitempty"`
+	Progress   JobProgress     `json:"progress"`
+	Result     json.RawMessage `json:"result,omitempty"` // 处理函数的返回值（JSON）
+	Error      string          `json:"error,omitempty"`
+	CreatedAt  time.Time       `json:"created_at"`
+	StartedAt  *time.Time      `json:"started_at,omitempty"`
+	FinishedAt *time.Time      `json:"finished_at,omitempty"`
+}
+
+// JobStore 后台作业存储接口（作业记录 + 待执行队列）
+type JobStore interface {
+	// Save 保存作业记录（新建或更新），ttl 后自动删除
+	Save(ctx context.Context, job Job, ttl time.Duration) error
+
+	// Get 查询作业记录，不存在时返回 ErrJobNotFound
+	Get(ctx context.Context, id string) (Job, error)
+
+	// Push 将作业加入待执行队列
+	Push(ctx context.Context, id string) error
+
+	// Pop 取出一个待执行的作业，最多等待 timeout（没有作业时返回空字符串）
+	Pop(ctx context.Context, timeout time.Duration) (string, error)
+
+	// Claim 占用唯一键，返回占用该键的作业 ID（键已被其他作业占用时返回该作业的 ID）
+	Claim(ctx context.Context, key, id string, ttl time.Duration) (string, error)
+
+	// Release 释放唯一键（只释放由 id 占用的键）
+	Release(ctx context.Context, key, id string) error
+}
+
+// ProgressFunc 报告作业进度
+type ProgressFunc func(done, total int64)
+
+// JobHandler 作业处理函数，返回值序列化为 JSON 保存在 Job.Result 中
+type JobHandler func(ctx context.Context, job Job, progress ProgressFunc) (any, error)
+
+// JobQueueConfig 作业队列配置
+type JobQueueConfig struct {
+	// 作业存储
+	Store JobStore
+
+	// 并发执行的作业数（默认 2）
+	Workers int
+
+	// 单个作业的超时时间（默认 30 分钟）
+	Timeout time.Duration
+
+	// 作业记录的保留时间（从最后一次更新开始计算，默认 24 小时）
+	Retention time.Duration
+}
+
+// SubmitOptions 提交作业的选项
+type SubmitOptions struct {
+	// 唯一键（可选）：同一个键同时只能有一个未结束的作业，重复提交时返回已有的作业
+	UniqueKey string
+}
+
+// JobQueue 后台作业队列
+//
+// 与 Scheduler 的定时任务不同，作业由业务代码按需提交（如数据导出），
+// 作业记录和队列保存在 JobStore 中，任意实例的 worker 都可以取出执行，提交者通过作业 ID 查询状态和结果。
+// 进程退出时正在执行的作业会被取消并标记为失败，不会自动重试。
+type JobQueue struct {
+	store     JobStore
+	workers   int
+	timeout   time.Duration
+	retention time.Duration
+
+	mu       sync.RWMutex
+	handlers map[string]JobHandler
+
+	cancel context.CancelFunc
+	wg     sync.WaitGroup
+}
+
+// NewJobQueue 创建作业队列
+func NewJobQueue(config JobQueueConfig) *JobQueue {
+	if config.Workers <= 0 {
+		config.Workers = 2
+	}
+	if config.Timeout <= 0 {
+		config.Timeout = 30 * time.Minute
+	}
+	if config.Retention <= 0 {
+		config.Retention = 24 * time.Hour
+	}
+
+	return &JobQueue{
+		store:     config.Store,
+		workers:   config.Workers,
+		timeout:   config.Timeout,
+		retention: config.Retention,
+		handlers:  make(map[string]JobHandler),
+	}
+}
+
+// Handle 注册作业处理函数
+func (q *JobQueue) Handle(jobType string, handler JobHandler) error {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if _, exists := q.handlers[jobType]; exists {
+		return fmt.Errorf("job type %s already registered", jobType)
+	}
+	q.handlers[jobType] = handler
+	return nil
+}
+
+// Submit 提交作业
+func (q *JobQueue) Submit(ctx context.Context, jobType, owner string, payload any, opts SubmitOptions) (Job, error) {
+	if _, ok := q.handler(jobType); !ok {
+		return Job{}, fmt.Errorf("%w: %s", ErrUnknownJobType, jobType)
+	}
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return Job{}, fmt.Errorf("failed to encode job payload: %w", err)
+	}
+
+	id, err := newJobID()
+	if err != nil {
+		return Job{}, err
+	}
+
+	job := Job{
+		ID:        id,
+		Type:      jobType,
+		Owner:     owner,
+		UniqueKey: opts.UniqueKey,
+		Status:    JobPending,
+		Payload:   data,
+		CreatedAt: time.Now(),
+	}
+
+	if opts.UniqueKey != "" {
+		holder, err := q.store.Claim(ctx, opts.UniqueKey, id, q.timeout+q.retention)
+		if err != nil {
+			return Job{}, fmt.Errorf("failed to claim job key: %w", err)
+		}
+		if holder != id {
+			existing, err := q.store.Get(ctx, holder)
+			if err == nil && !existing.Status.Finished() {
+				return existing, nil
+			}
+			// 占用键的作业已结束或记录已过期（进程异常退出时未释放），重新占用
+			if err := q.store.Release(ctx, opts.UniqueKey, holder); err != nil {
+				return Job{}, fmt.Errorf("failed to release job key: %w", err)
+			}
+			return q.Submit(ctx, jobType, owner, payload, opts)
+		}
+	}
+
+	if err := q.store.Save(ctx, job, q.retention); err != nil {
+		return Job{}, fmt.Errorf("failed to save job: %w", err)
+	}
+	if err := q.store.Push(ctx, job.ID); err != nil {
+		return Job{}, fmt.Errorf("failed to enqueue job: %w", err)
+	}
+
+	slog.InfoContext(ctx, "Job submitted", "job_id", job.ID, "type", jobType)
+	return job, nil
+}
+
+// Get 查询作业
+func (q *JobQueue) Get(ctx context.Context, id string) (Job, error) {
+	return q.store.Get(ctx, id)
+}
+
+// Start 启动 worker
+func (q *JobQueue) Start() {
+	ctx, cancel := context.WithCancel(context.Background())
+	q.cancel = cancel
+
+	for range q.workers {
+		q.wg.Add(1)
+		go func() {
+			defer q.wg.Done()
+			q.work(ctx)
+		}()
+	}
+	slog.Info("Job queue started", "workers", q.workers)
+}
+
+// Stop 停止 worker（取消正在执行的作业并等待其返回）
+func (q *JobQueue) Stop() {
+	if q.cancel == nil {
+		return
+	}
+	q.cancel()
+	q.wg.Wait()
+	slog.Info("Job queue stopped")
+}
+
+// work 循环取出并执行作业
+func (q *JobQueue) work(ctx context.Context) {
+	for ctx.Err() == nil {
+		id, err := q.store.Pop(ctx, time.Second)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			slog.Error("Failed to pop job", "error", err)
+			// 存储不可用时稍后重试，避免空转
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(time.Second):
+			}
+			continue
+		}
+		if id != "" {
+			q.run(ctx, id)
+		}
+	}
+}
+
+// run 执行作业并保存结果
+func (q *JobQueue) run(ctx context.Context, id string) {
+	// 保存状态不使用作业的 context（作业超时或被取消后仍需保存结果）
+	saveCtx := context.WithoutCancel(ctx)
+
+	job, err := q.store.Get(saveCtx, id)
+	if err != nil {
+		slog.Error("Failed to load job", "job_id", id, "error", err)
+		return
+	}
+
+	handler, ok := q.handler(job.Type)
+	if !ok {
+		q.finish(saveCtx, job, nil, fmt.Errorf("%w: %s", ErrUnknownJobType, job.Type))
+		return
+	}
+
+	now := time.Now()
+	job.Status = JobRunning
+	job.StartedAt = &now
+	if err := q.store.Save(saveCtx, job, q.retention); err != nil {
+		slog.Error("Failed to save job", "job_id", id, "error", err)
+	}
+
+	jobCtx, cancel := context.WithTimeout(ctx, q.timeout)
+	defer cancel()
+
+	var (
+		mu       sync.Mutex
+		finished bool
+	)
+	progress := func(done, total int64) {
+		mu.Lock()
+		defer mu.Unlock()
+		if finished {
+			// 处理函数返回后（如其启动的协程）报告的进度不再保存，避免覆盖结果
+			return
+		}
+		job.Progress = JobProgress{Done: done, Total: total}
+		if err := q.store.Save(saveCtx, job, q.retention); err != nil {
+			slog.Warn("Failed to save job progress", "job_id", id, "error", err)
+		}
+	}
+
+	slog.Info("Job started", "job_id", id, "type", job.Type)
+	result, err := q.call(jobCtx, handler, job, progress)
+
+	mu.Lock()
+	defer mu.Unlock()
+	finished = true
+	q.finish(saveCtx, job, result, err)
+}
+
+// call 调用处理函数（处理函数 panic 时视为失败）
+func (q *JobQueue) call(ctx context.Context, handler JobHandler, job Job, progress ProgressFunc) (result any, err error) {
+	defer func() {
+		if p := recover(); p != nil {
+			err = fmt.Errorf("job panicked: %v", p)
+		}
+	}()
+	return handler(ctx, job, progress)
+}
+
+// finish 保存作业结果并释放唯一键
+func (q *JobQueue) finish(ctx context.Context, job Job, result any, err error) {
+	now := time.Now()
+	job.FinishedAt = &now
+
+	if err == nil && result != nil {
+		job.Result, err = json.Marshal(result)
+	}
+	if err != nil {
+		job.Status = JobFailed
+		job.Error = err.Error()
+		slog.Error("Job failed", "job_id", job.ID, "type", job.Type, "error", err)
+	} else {
+		job.Status = JobSucceeded
+		slog.Info("Job completed", "job_id", job.ID, "type", job.Type)
+	}
+
+	if err := q.store.Save(ctx, job, q.retention); err != nil {
+		slog.Error("Failed to save job", "job_id", job.ID, "error", err)
+	}
+	if job.UniqueKey != "" {
+		if err := q.store.Release(ctx, job.UniqueKey, job.ID); err != nil {
+			slog.Error("Failed to release job key", "job_id", job.ID, "error", err)
+		}
+	}
+}
+
+// handler 获取作业处理函数
+func (q *JobQueue) handler(jobType string) (JobHandler, bool) {
+	q.mu.RLock()
+	defer q.mu.RUnlock()
+
+	handler, ok := q.handlers[jobType]
+	return handler, ok
+}
+
+// newJobID 生成作业 ID（32 位十六进制）
+func newJobID() (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate job id: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
@@ -0,0 +1,103 @@
+package task
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// MemoryJobStore 基于内存的作业存储（仅用于测试或单实例部署）
+type MemoryJobStore struct {
+	mu    sync.Mutex
+	jobs  map[string]memoryJob
+	keys  map[string]string
+	queue chan string
+}
+
+// memoryJob 作业记录及过期时间
+type memoryJob struct {
+	job       Job
+	expiresAt time.Time
+}
+
+// NewMemoryJobStore 创建内存作业存储（size 为待执行队列的容量）
+func NewMemoryJobStore(size int) *MemoryJobStore {
+	return &MemoryJobStore{
+		jobs:  make(map[string]memoryJob),
+		keys:  make(map[string]string),
+		queue: make(chan string, size),
+	}
+}
+
+// Save 实现 JobStore 接口
+func (s *MemoryJobStore) Save(_ context.Context, job Job, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.jobs[job.ID] = memoryJob{job: job, expiresAt: time.Now().Add(ttl)}
+	return nil
+}
+
+// Get 实现 JobStore 接口
+func (s *MemoryJobStore) Get(_ context.Context, id string) (Job, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.jobs[id]
+	if !ok || time.Now().After(entry.expiresAt) {
+		delete(s.jobs, id)
+		return Job{}, ErrJobNotFound
+	}
+	return entry.job, nil
+}
+
+// Push 实现 JobStore 接口（队列已满时阻塞，直到 context 取消）
+func (s *MemoryJobStore) Push(ctx context.Context, id string) error {
+	select {
+	case s.queue <- id:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Pop 实现 JobStore 接口
+func (s *MemoryJobStore) Pop(ctx context.Context, timeout time.Duration) (string, error) {
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+
+	select {
+	case id := <-s.queue:
+		return id, nil
+	case <-timer.C:
+		return "", nil
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+// Claim 实现 JobStore 接口（内存存储中唯一键不过期，作业结束时释放）
+func (s *MemoryJobStore) Claim(_ context.Context, key, id string, _ time.Duration) (string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if holder, ok := s.keys[key]; ok {
+		return holder, nil
+	}
+	s.keys[key] = id
+	return id, nil
+}
+
+// Release 实现 JobStore 接口
+func (s *MemoryJobStore) Release(_ context.Context, key, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.keys[key] == id {
+		delete(s.keys, key)
+	}
+	return nil
+}
+
+// 确保 MemoryJobStore 实现了接口
+var _ JobStore = (*MemoryJobStore)(nil)
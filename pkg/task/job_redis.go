@@ -0,0 +1,112 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// releaseScript 只有值等于作业 ID 时才删除唯一键（避免释放其他作业重新占用的键）
+var releaseScript = redis.NewScript(`
+if redis.call("GET", KEYS[1]) == ARGV[1] then
+	return redis.call("DEL", KEYS[1])
+end
+return 0
+`)
+
+// RedisJobStore 基于 Redis 的作业存储（多实例共享队列，作业可以由任意实例执行）
+//
+// Key 布局:
+//   - {prefix}job:<id>    作业记录（JSON）
+//   - {prefix}queue       待执行的作业 ID（List，LPUSH 入队，BRPOP 出队）
+//   - {prefix}key:<key>   唯一键（值为占用该键的作业 ID）
+type RedisJobStore struct {
+	rdb    redis.UniversalClient
+	prefix string
+}
+
+// NewRedisJobStore 创建 Redis 作业存储
+func NewRedisJobStore(rdb redis.UniversalClient, prefix string) *RedisJobStore {
+	if prefix == "" {
+		prefix = "task:job:"
+	}
+	return &RedisJobStore{
+		rdb:    rdb,
+		prefix: prefix,
+	}
+}
+
+// Save 实现 JobStore 接口
+func (s *RedisJobStore) Save(ctx context.Context, job Job, ttl time.Duration) error {
+	bs, err := json.Marshal(job)
+	if err != nil {
+		return fmt.Errorf("marshal job: %w", err)
+	}
+	return s.rdb.Set(ctx, s.prefix+"job:"+job.ID, bs, ttl).Err()
+}
+
+// Get 实现 JobStore 接口
+func (s *RedisJobStore) Get(ctx context.Context, id string) (Job, error) {
+	var job Job
+
+	val, err := s.rdb.Get(ctx, s.prefix+"job:"+id).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return job, ErrJobNotFound
+		}
+		return job, err
+	}
+
+	if err := json.Unmarshal(val, &job); err != nil {
+		return job, fmt.Errorf("unmarshal job: %w", err)
+	}
+	return job, nil
+}
+
+// Push 实现 JobStore 接口
+func (s *RedisJobStore) Push(ctx context.Context, id string) error {
+	return s.rdb.LPush(ctx, s.prefix+"queue", id).Err()
+}
+
+// Pop 实现 JobStore 接口
+func (s *RedisJobStore) Pop(ctx context.Context, timeout time.Duration) (string, error) {
+	result, err := s.rdb.BRPop(ctx, timeout, s.prefix+"queue").Result()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return "", nil
+		}
+		return "", err
+	}
+	// BRPOP 返回 [key, value]
+	return result[1], nil
+}
+
+// Claim 实现 JobStore 接口（SET NX 占用，已被占用时返回当前值）
+func (s *RedisJobStore) Claim(ctx context.Context, key, id string, ttl time.Duration) (string, error) {
+	ok, err := s.rdb.SetNX(ctx, s.prefix+"key:"+key, id, ttl).Result()
+	if err != nil {
+		return "", err
+	}
+	if ok {
+		return id, nil
+	}
+
+	holder, err := s.rdb.Get(ctx, s.prefix+"key:"+key).Result()
+	if errors.Is(err, redis.Nil) {
+		// 键恰好在两次操作之间被释放，重新占用
+		return s.Claim(ctx, key, id, ttl)
+	}
+	return holder, err
+}
+
+// Release 实现 JobStore 接口
+func (s *RedisJobStore) Release(ctx context.Context, key, id string) error {
+	return releaseScript.Run(ctx, s.rdb, []string{s.prefix + "key:" + key}, id).Err()
+}
+
+// 确保 RedisJobStore 实现了接口
+var _ JobStore = (*RedisJobStore)(nil)
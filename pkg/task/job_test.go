@@ -0,0 +1,119 @@
+package task
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// waitJob 等待作业结束
+func waitJob(t *testing.T, q *JobQueue, id string) Job {
+	t.Helper()
+
+	var job Job
+	require.Eventually(t, func() bool {
+		var err error
+		job, err = q.Get(context.Background(), id)
+		require.NoError(t, err)
+		return job.Status.Finished()
+	}, 5*time.Second, 10*time.Millisecond)
+	return job
+}
+
+// TestJobQueue 测试后台作业队列
+func TestJobQueue(t *testing.T) {
+	ctx := context.Background()
+	q := NewJobQueue(JobQueueConfig{Store: NewMemoryJobStore(16), Workers: 2, Timeout: time.Second})
+
+	release := make(chan struct{})
+	require.NoError(t, q.Handle("sum", func(ctx context.Context, job Job, progress ProgressFunc) (any, error) {
+		var numbers []int
+		if err := json.Unmarshal(job.Payload, &numbers); err != nil {
+			return nil, err
+		}
+		<-release
+
+		sum := 0
+		for i, n := range numbers {
+			sum += n
+			progress(int64(i+1), int64(len(numbers)))
+		}
+		return map[string]int{"sum": sum}, nil
+	}))
+	require.NoError(t, q.Handle("fail", func(context.Context, Job, ProgressFunc) (any, error) {
+		return nil, errors.New("boom")
+	}))
+	require.NoError(t, q.Handle("panic", func(context.Context, Job, ProgressFunc) (any, error) {
+		panic("oops")
+	}))
+	require.NoError(t, q.Handle("slow", func(ctx context.Context, _ Job, _ ProgressFunc) (any, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}))
+	assert.Error(t, q.Handle("sum", nil), "重复注册")
+
+	q.Start()
+	t.Cleanup(q.Stop)
+
+	t.Run("执行成功并报告进度", func(t *testing.T) {
+		job, err := q.Submit(ctx, "sum", "1:2", []int{1, 2, 3}, SubmitOptions{})
+		require.NoError(t, err)
+		assert.Equal(t, JobPending, job.Status)
+		assert.Equal(t, "1:2", job.Owner)
+		release <- struct{}{}
+
+		job = waitJob(t, q, job.ID)
+		assert.Equal(t, JobSucceeded, job.Status)
+		assert.JSONEq(t, `{"sum":6}`, string(job.Result))
+		assert.Equal(t, JobProgress{Done: 3, Total: 3}, job.Progress)
+		assert.NotNil(t, job.StartedAt)
+		assert.NotNil(t, job.FinishedAt)
+	})
+
+	t.Run("唯一键", func(t *testing.T) {
+		first, err := q.Submit(ctx, "sum", "1:2", []int{1}, SubmitOptions{UniqueKey: "sum:2"})
+		require.NoError(t, err)
+		second, err := q.Submit(ctx, "sum", "1:2", []int{2}, SubmitOptions{UniqueKey: "sum:2"})
+		require.NoError(t, err)
+		assert.Equal(t, first.ID, second.ID, "未结束时返回已有的作业")
+
+		release <- struct{}{}
+		waitJob(t, q, first.ID)
+
+		third, err := q.Submit(ctx, "sum", "1:2", []int{3}, SubmitOptions{UniqueKey: "sum:2"})
+		require.NoError(t, err)
+		assert.NotEqual(t, first.ID, third.ID, "结束后可以重新提交")
+		release <- struct{}{}
+		waitJob(t, q, third.ID)
+	})
+
+	t.Run("执行失败", func(t *testing.T) {
+		for jobType, message := range map[string]string{
+			"fail":  "boom",
+			"panic": "job panicked: oops",
+			"slow":  context.DeadlineExceeded.Error(),
+		} {
+			job, err := q.Submit(ctx, jobType, "1:2", nil, SubmitOptions{})
+			require.NoError(t, err)
+
+			job = waitJob(t, q, job.ID)
+			assert.Equal(t, JobFailed, job.Status, jobType)
+			assert.Equal(t, message, job.Error, jobType)
+		}
+	})
+
+	t.Run("未注册的类型", func(t *testing.T) {
+		_, err := q.Submit(ctx, "unknown", "1:2", nil, SubmitOptions{})
+		assert.ErrorIs(t, err, ErrUnknownJobType)
+	})
+
+	t.Run("作业不存在", func(t *testing.T) {
+		_, err := q.Get(ctx, "missing")
+		assert.ErrorIs(t, err, ErrJobNotFound)
+	})
+}
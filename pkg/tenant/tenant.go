@@ -0,0 +1,32 @@
+// Package tenant 多租户上下文
+//
+// 租户 ID 由租户解析中间件（子域名、请求头或 Token）写入 context.Context，
+// 仓库层按 context 中的租户过滤数据，缓存 Key 也按租户隔离（见 cache.Manager.BuildKey）。
+// context 中没有租户时使用默认租户（单租户部署无需任何配置）。
+package tenant
+
+import "context"
+
+// DefaultID 默认租户 ID（迁移脚本创建，单租户部署的所有数据都属于该租户）
+const DefaultID int64 = 1
+
+type contextKey struct{}
+
+// WithID 将租户 ID 存入 context
+func WithID(ctx context.Context, id int64) context.Context {
+	return context.WithValue(ctx, contextKey{}, id)
+}
+
+// FromContext 从 context 中获取租户 ID，第二个返回值表示是否已解析出租户
+func FromContext(ctx context.Context) (int64, bool) {
+	id, ok := ctx.Value(contextKey{}).(int64)
+	return id, ok && id > 0
+}
+
+// ID 获取 context 中的租户 ID（没有时返回 DefaultID）
+func ID(ctx context.Context) int64 {
+	if id, ok := FromContext(ctx); ok {
+		return id
+	}
+	return DefaultID
+}
@@ -0,0 +1,25 @@
+package tenant
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// TestContext 测试租户上下文
+func TestContext(t *testing.T) {
+	ctx := context.Background()
+
+	_, ok := FromContext(ctx)
+	assert.False(t, ok)
+	assert.Equal(t, DefaultID, ID(ctx), "未解析租户时使用默认租户")
+
+	ctx = WithID(ctx, 7)
+	id, ok := FromContext(ctx)
+	assert.True(t, ok)
+	assert.Equal(t, int64(7), id)
+	assert.Equal(t, int64(7), ID(ctx))
+
+	assert.Equal(t, DefaultID, ID(WithID(context.Background(), 0)), "无效的租户 ID")
+}